@@ -0,0 +1,89 @@
+// Code generated by clientgen. DO NOT EDIT.
+// source: user.proto
+
+package userclient
+
+import (
+	context "context"
+	user "github.com/ZampoRen/go-server-comon/api/model/user"
+	rpcclient "github.com/ZampoRen/go-server-comon/internal/middleware/rpcclient"
+	grpc "google.golang.org/grpc"
+)
+
+// UserIdempotentMethods User 服务中可安全重试的方法，
+// 即 idempotency_level 为 NO_SIDE_EFFECTS 或 IDEMPOTENT 的方法
+var UserIdempotentMethods = []string{
+	user.User_GetUser_FullMethodName,
+	user.User_ListUsers_FullMethodName,
+}
+
+// UserClient User 服务的类型化客户端
+type UserClient struct {
+	conn *grpc.ClientConn
+	stub user.UserClient
+}
+
+// NewUserClient 基于已有连接创建客户端，连接的生命周期由调用方管理
+func NewUserClient(conn *grpc.ClientConn) *UserClient {
+	return &UserClient{conn: conn, stub: user.NewUserClient(conn)}
+}
+
+// DialUser 通过 rpcclient.Dial 连接 target 并创建客户端，target 的格式与默认选项见 rpcclient.Dial，
+// 只重试 UserIdempotentMethods 中的方法；opts 追加在默认选项之后
+func DialUser(target string, opts ...rpcclient.Option) (*UserClient, error) {
+	conn, err := rpcclient.Dial(target, append([]rpcclient.Option{rpcclient.WithIdempotentMethods(UserIdempotentMethods...)}, opts...)...)
+	if err != nil {
+		return nil, err
+	}
+	return NewUserClient(conn), nil
+}
+
+// Close 关闭底层连接
+func (c *UserClient) Close() error {
+	return c.conn.Close()
+}
+
+// GetUser 获取用户信息
+func (c *UserClient) GetUser(ctx context.Context, req *user.GetUserRequest, opts ...grpc.CallOption) (*user.GetUserResponse, error) {
+	return c.stub.GetUser(ctx, req, opts...)
+}
+
+// CreateUser 创建用户
+func (c *UserClient) CreateUser(ctx context.Context, req *user.CreateUserRequest, opts ...grpc.CallOption) (*user.CreateUserResponse, error) {
+	return c.stub.CreateUser(ctx, req, opts...)
+}
+
+// ListUsers 用户列表
+func (c *UserClient) ListUsers(ctx context.Context, req *user.ListUsersRequest, opts ...grpc.CallOption) (*user.ListUsersResponse, error) {
+	return c.stub.ListUsers(ctx, req, opts...)
+}
+
+// ExportUsers 导出用户到对象存储（管理端）
+func (c *UserClient) ExportUsers(ctx context.Context, req *user.ExportUsersRequest, opts ...grpc.CallOption) (*user.ExportUsersResponse, error) {
+	return c.stub.ExportUsers(ctx, req, opts...)
+}
+
+// ImportUsers 从对象存储导入用户（管理端）
+func (c *UserClient) ImportUsers(ctx context.Context, req *user.ImportUsersRequest, opts ...grpc.CallOption) (*user.ImportUsersResponse, error) {
+	return c.stub.ImportUsers(ctx, req, opts...)
+}
+
+// Register 注册并登录
+func (c *UserClient) Register(ctx context.Context, req *user.RegisterRequest, opts ...grpc.CallOption) (*user.AuthResponse, error) {
+	return c.stub.Register(ctx, req, opts...)
+}
+
+// Login 使用用户名或邮箱登录
+func (c *UserClient) Login(ctx context.Context, req *user.LoginRequest, opts ...grpc.CallOption) (*user.AuthResponse, error) {
+	return c.stub.Login(ctx, req, opts...)
+}
+
+// ChangePassword 修改当前登录用户的密码
+func (c *UserClient) ChangePassword(ctx context.Context, req *user.ChangePasswordRequest, opts ...grpc.CallOption) (*user.ChangePasswordResponse, error) {
+	return c.stub.ChangePassword(ctx, req, opts...)
+}
+
+// WatchUsers 订阅用户变更事件（仅 gRPC），供下游服务失效缓存
+func (c *UserClient) WatchUsers(ctx context.Context, req *user.WatchUsersRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[user.UserEvent], error) {
+	return c.stub.WatchUsers(ctx, req, opts...)
+}
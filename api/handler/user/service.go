@@ -0,0 +1,20 @@
+package user
+
+import (
+	"sync/atomic"
+
+	user "github.com/ZampoRen/go-server-comon/api/model/user"
+)
+
+var service atomic.Value // user.UserServer
+
+// SetService 注入 HTTP 接口使用的用户服务实现
+// 导入导出、注册登录等逻辑在 gRPC 服务中实现，HTTP 接口直接复用
+func SetService(svc user.UserServer) {
+	service.Store(svc)
+}
+
+func userService() user.UserServer {
+	svc, _ := service.Load().(user.UserServer)
+	return svc
+}
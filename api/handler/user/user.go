@@ -6,8 +6,11 @@ import (
 	"context"
 
 	user "github.com/ZampoRen/go-server-comon/api/model/user"
+	"github.com/ZampoRen/go-server-comon/internal/errno"
+	"github.com/ZampoRen/go-server-comon/internal/response"
+	"github.com/ZampoRen/go-server-comon/internal/validate"
+	"github.com/ZampoRen/go-server-comon/pkg/errorx"
 	"github.com/cloudwego/hertz/pkg/app"
-	"github.com/cloudwego/hertz/pkg/protocol/consts"
 )
 
 // GetUser .
@@ -17,7 +20,7 @@ func GetUser(ctx context.Context, c *app.RequestContext) {
 	var req user.GetUserRequest
 	err = c.BindAndValidate(&req)
 	if err != nil {
-		c.String(consts.StatusBadRequest, err.Error())
+		response.Error(ctx, c, validate.WrapBindError(err))
 		return
 	}
 
@@ -29,7 +32,7 @@ func GetUser(ctx context.Context, c *app.RequestContext) {
 		CreatedAt: "2024-01-01 00:00:00",
 	}
 
-	c.JSON(consts.StatusOK, resp)
+	response.Success(c, resp)
 }
 
 // CreateUser .
@@ -39,7 +42,7 @@ func CreateUser(ctx context.Context, c *app.RequestContext) {
 	var req user.CreateUserRequest
 	err = c.BindAndValidate(&req)
 	if err != nil {
-		c.String(consts.StatusBadRequest, err.Error())
+		response.Error(ctx, c, validate.WrapBindError(err))
 		return
 	}
 
@@ -51,7 +54,7 @@ func CreateUser(ctx context.Context, c *app.RequestContext) {
 		Success:  true,
 	}
 
-	c.JSON(consts.StatusOK, resp)
+	response.Success(c, resp)
 }
 
 // ListUsers .
@@ -61,35 +64,151 @@ func ListUsers(ctx context.Context, c *app.RequestContext) {
 	var req user.ListUsersRequest
 	err = c.BindAndValidate(&req)
 	if err != nil {
-		c.String(consts.StatusBadRequest, err.Error())
+		response.Error(ctx, c, validate.WrapBindError(err))
 		return
 	}
 
-	// 设置默认值
-	if req.Page <= 0 {
-		req.Page = 1
+	svc := userService()
+	if svc == nil {
+		response.Error(ctx, c, errorx.New(errno.ErrUnavailable))
+		return
 	}
-	if req.PageSize <= 0 {
-		req.PageSize = 10
+
+	resp, err := svc.ListUsers(ctx, &req)
+	if err != nil {
+		response.Error(ctx, c, err)
+		return
 	}
 
-	// TODO: 实现业务逻辑
-	resp := &user.ListUsersResponse{
-		Users: []*user.UserInfo{
-			{
-				UserId:   1,
-				Username: "user1",
-				Email:    "user1@example.com",
-			},
-			{
-				UserId:   2,
-				Username: "user2",
-				Email:    "user2@example.com",
-			},
-		},
-		Total: 2,
-		Page:  req.Page,
-	}
-
-	c.JSON(consts.StatusOK, resp)
+	response.Success(c, resp)
+}
+
+// ExportUsers .
+// @router /api/admin/users/export [POST]
+func ExportUsers(ctx context.Context, c *app.RequestContext) {
+	var err error
+	var req user.ExportUsersRequest
+	err = c.BindAndValidate(&req)
+	if err != nil {
+		response.Error(ctx, c, validate.WrapBindError(err))
+		return
+	}
+
+	svc := userService()
+	if svc == nil {
+		response.Error(ctx, c, errorx.New(errno.ErrUnavailable))
+		return
+	}
+
+	resp, err := svc.ExportUsers(ctx, &req)
+	if err != nil {
+		response.Error(ctx, c, err)
+		return
+	}
+
+	response.Success(c, resp)
+}
+
+// ImportUsers .
+// @router /api/admin/users/import [POST]
+func ImportUsers(ctx context.Context, c *app.RequestContext) {
+	var err error
+	var req user.ImportUsersRequest
+	err = c.BindAndValidate(&req)
+	if err != nil {
+		response.Error(ctx, c, validate.WrapBindError(err))
+		return
+	}
+
+	svc := userService()
+	if svc == nil {
+		response.Error(ctx, c, errorx.New(errno.ErrUnavailable))
+		return
+	}
+
+	resp, err := svc.ImportUsers(ctx, &req)
+	if err != nil {
+		response.Error(ctx, c, err)
+		return
+	}
+
+	response.Success(c, resp)
+}
+
+// Register .
+// @router /api/auth/register [POST]
+func Register(ctx context.Context, c *app.RequestContext) {
+	var err error
+	var req user.RegisterRequest
+	err = c.BindAndValidate(&req)
+	if err != nil {
+		response.Error(ctx, c, validate.WrapBindError(err))
+		return
+	}
+
+	svc := userService()
+	if svc == nil {
+		response.Error(ctx, c, errorx.New(errno.ErrUnavailable))
+		return
+	}
+
+	resp, err := svc.Register(ctx, &req)
+	if err != nil {
+		response.Error(ctx, c, err)
+		return
+	}
+
+	response.Success(c, resp)
+}
+
+// Login .
+// @router /api/auth/login [POST]
+func Login(ctx context.Context, c *app.RequestContext) {
+	var err error
+	var req user.LoginRequest
+	err = c.BindAndValidate(&req)
+	if err != nil {
+		response.Error(ctx, c, validate.WrapBindError(err))
+		return
+	}
+
+	svc := userService()
+	if svc == nil {
+		response.Error(ctx, c, errorx.New(errno.ErrUnavailable))
+		return
+	}
+
+	resp, err := svc.Login(ctx, &req)
+	if err != nil {
+		response.Error(ctx, c, err)
+		return
+	}
+
+	response.Success(c, resp)
+}
+
+// ChangePassword .
+// @router /api/user/password [PUT]
+func ChangePassword(ctx context.Context, c *app.RequestContext) {
+	var err error
+	var req user.ChangePasswordRequest
+	err = c.BindAndValidate(&req)
+	if err != nil {
+		response.Error(ctx, c, validate.WrapBindError(err))
+		return
+	}
+
+	svc := userService()
+	if svc == nil {
+		response.Error(ctx, c, errorx.New(errno.ErrUnavailable))
+		return
+	}
+
+	resp, err := svc.ChangePassword(ctx, &req)
+	if err != nil {
+		response.Error(ctx, c, err)
+		return
+	}
+
+	response.Success(c, resp)
 }
@@ -3,9 +3,42 @@
 package user
 
 import (
+	"context"
+
 	"github.com/cloudwego/hertz/pkg/app"
+
+	"github.com/ZampoRen/go-server-comon/internal/errno"
+	"github.com/ZampoRen/go-server-comon/internal/response"
+	"github.com/ZampoRen/go-server-comon/pkg/errorx"
 )
 
+// authMw 需要登录的接口使用的认证中间件
+var authMw []app.HandlerFunc
+
+// SetAuthMiddleware 设置需要登录的接口使用的认证中间件，如 httpmw.JWTAuth(sessions)，需在注册路由之前调用
+func SetAuthMiddleware(mw ...app.HandlerFunc) {
+	authMw = mw
+}
+
+// adminMw /admin 分组使用的认证与鉴权中间件，未设置时拒绝所有请求
+var adminMw = []app.HandlerFunc{func(ctx context.Context, c *app.RequestContext) {
+	response.Abort(ctx, c, errorx.New(errno.ErrUnavailable))
+}}
+
+// SetAdminMiddleware 设置 /admin 分组使用的中间件，如 httpmw.JWTAuth(sessions)、httpmw.RequireRole("admin")，
+// 需在注册路由之前调用；未调用时 /admin 下的接口返回 errno.ErrUnavailable
+func SetAdminMiddleware(mw ...app.HandlerFunc) {
+	adminMw = mw
+}
+
+// idempotencyMw 创建类接口使用的幂等中间件
+var idempotencyMw []app.HandlerFunc
+
+// SetIdempotencyMiddleware 设置创建类接口使用的幂等中间件，如 httpmw.Idempotency(store)，需在注册路由之前调用
+func SetIdempotencyMiddleware(mw ...app.HandlerFunc) {
+	idempotencyMw = mw
+}
+
 func rootMw() []app.HandlerFunc {
 	// your code...
 	return nil
@@ -17,8 +50,7 @@ func _apiMw() []app.HandlerFunc {
 }
 
 func _createuserMw() []app.HandlerFunc {
-	// your code...
-	return nil
+	return idempotencyMw
 }
 
 func _listusersMw() []app.HandlerFunc {
@@ -35,3 +67,41 @@ func _getuserMw() []app.HandlerFunc {
 	// your code...
 	return nil
 }
+
+func _adminMw() []app.HandlerFunc {
+	return adminMw
+}
+
+func _usersMw() []app.HandlerFunc {
+	// your code...
+	return nil
+}
+
+func _exportusersMw() []app.HandlerFunc {
+	// your code...
+	return nil
+}
+
+func _importusersMw() []app.HandlerFunc {
+	// your code...
+	return nil
+}
+
+func _authMw() []app.HandlerFunc {
+	// your code...
+	return nil
+}
+
+func _loginMw() []app.HandlerFunc {
+	// your code...
+	return nil
+}
+
+func _registerMw() []app.HandlerFunc {
+	// your code...
+	return nil
+}
+
+func _changepasswordMw() []app.HandlerFunc {
+	return authMw
+}
@@ -19,11 +19,25 @@ func Register(r *server.Hertz) {
 	root := r.Group("/", rootMw()...)
 	{
 		_api := root.Group("/api", _apiMw()...)
+		{
+			_admin := _api.Group("/admin", _adminMw()...)
+			{
+				_users := _admin.Group("/users", _usersMw()...)
+				_users.POST("/export", append(_exportusersMw(), user.ExportUsers)...)
+				_users.POST("/import", append(_importusersMw(), user.ImportUsers)...)
+			}
+		}
+		{
+			_auth := _api.Group("/auth", _authMw()...)
+			_auth.POST("/login", append(_loginMw(), user.Login)...)
+			_auth.POST("/register", append(_registerMw(), user.Register)...)
+		}
 		_api.POST("/user", append(_createuserMw(), user.CreateUser)...)
 		_api.GET("/users", append(_listusersMw(), user.ListUsers)...)
 		{
 			_user := _api.Group("/user", _userMw()...)
 			_user.GET("/:user_id", append(_getuserMw(), user.GetUser)...)
+			_user.PUT("/password", append(_changepasswordMw(), user.ChangePassword)...)
 		}
 	}
 }
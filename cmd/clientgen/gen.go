@@ -0,0 +1,145 @@
+package main
+
+import (
+	"path"
+	"strings"
+
+	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+const (
+	contextPackage = protogen.GoImportPath("context")
+	grpcPackage    = protogen.GoImportPath("google.golang.org/grpc")
+)
+
+// generate 为请求中每个带服务定义的 proto 文件生成客户端代码
+func generate(gen *protogen.Plugin, out string, rpc protogen.GoImportPath) {
+	for _, f := range gen.Files {
+		if !f.Generate || len(f.Services) == 0 {
+			continue
+		}
+		generateFile(gen, f, out, rpc)
+	}
+}
+
+// generateFile 为一个 proto 文件生成客户端代码
+// 输出到 <out>/<base>client/<base>.client.go，包名为 <base>client
+func generateFile(gen *protogen.Plugin, f *protogen.File, out string, rpc protogen.GoImportPath) {
+	base := strings.TrimSuffix(path.Base(f.Desc.Path()), ".proto")
+	pkgName := strings.ToLower(strings.ReplaceAll(base, "_", "")) + "client"
+	importPath := path.Join(out, pkgName)
+
+	g := gen.NewGeneratedFile(path.Join(importPath, base+".client.go"), protogen.GoImportPath(importPath))
+
+	g.P("// Code generated by clientgen. DO NOT EDIT.")
+	g.P("// source: ", f.Desc.Path())
+	g.P()
+	g.P("package ", pkgName)
+	g.P()
+
+	for _, svc := range f.Services {
+		generateService(g, f, svc, rpc)
+	}
+}
+
+func generateService(g *protogen.GeneratedFile, f *protogen.File, svc *protogen.Service, rpc protogen.GoImportPath) {
+	name := svc.GoName + "Client"
+	stub := f.GoImportPath.Ident(svc.GoName + "Client")
+	newStub := f.GoImportPath.Ident("New" + svc.GoName + "Client")
+
+	g.P("// ", svc.GoName, "IdempotentMethods ", svc.GoName, " 服务中可安全重试的方法，")
+	g.P("// 即 idempotency_level 为 NO_SIDE_EFFECTS 或 IDEMPOTENT 的方法")
+	g.P("var ", svc.GoName, "IdempotentMethods = []string{")
+	for _, m := range svc.Methods {
+		if isIdempotent(m) {
+			g.P(f.GoImportPath.Ident(svc.GoName+"_"+m.GoName+"_FullMethodName"), ",")
+		}
+	}
+	g.P("}")
+	g.P()
+
+	g.P("// ", name, " ", svc.GoName, " 服务的类型化客户端")
+	g.P("type ", name, " struct {")
+	g.P("conn *", grpcPackage.Ident("ClientConn"))
+	g.P("stub ", stub)
+	g.P("}")
+	g.P()
+
+	g.P("// New", name, " 基于已有连接创建客户端，连接的生命周期由调用方管理")
+	g.P("func New", name, "(conn *", grpcPackage.Ident("ClientConn"), ") *", name, " {")
+	g.P("return &", name, "{conn: conn, stub: ", newStub, "(conn)}")
+	g.P("}")
+	g.P()
+
+	g.P("// Dial", svc.GoName, " 通过 rpcclient.Dial 连接 target 并创建客户端，target 的格式与默认选项见 rpcclient.Dial，")
+	g.P("// 只重试 ", svc.GoName, "IdempotentMethods 中的方法；opts 追加在默认选项之后")
+	g.P("func Dial", svc.GoName, "(target string, opts ...", rpc.Ident("Option"), ") (*", name, ", error) {")
+	g.P("conn, err := ", rpc.Ident("Dial"), "(target, append([]", rpc.Ident("Option"), "{",
+		rpc.Ident("WithIdempotentMethods"), "(", svc.GoName, "IdempotentMethods...)}, opts...)...)")
+	g.P("if err != nil {")
+	g.P("return nil, err")
+	g.P("}")
+	g.P("return New", name, "(conn), nil")
+	g.P("}")
+	g.P()
+
+	g.P("// Close 关闭底层连接")
+	g.P("func (c *", name, ") Close() error {")
+	g.P("return c.conn.Close()")
+	g.P("}")
+	g.P()
+
+	for _, m := range svc.Methods {
+		generateMethod(g, name, m)
+	}
+}
+
+// isIdempotent 判断方法是否声明为无副作用或幂等，流式方法不重试
+func isIdempotent(m *protogen.Method) bool {
+	if m.Desc.IsStreamingClient() || m.Desc.IsStreamingServer() {
+		return false
+	}
+	opts, _ := m.Desc.Options().(*descriptorpb.MethodOptions)
+	switch opts.GetIdempotencyLevel() {
+	case descriptorpb.MethodOptions_NO_SIDE_EFFECTS, descriptorpb.MethodOptions_IDEMPOTENT:
+		return true
+	}
+	return false
+}
+
+func generateMethod(g *protogen.GeneratedFile, name string, m *protogen.Method) {
+	comment := strings.TrimSpace(string(m.Comments.Leading))
+	switch {
+	case strings.HasPrefix(comment, m.GoName+" "):
+		g.P("// ", strings.ReplaceAll(comment, "\n", "\n// "))
+	case comment != "":
+		g.P("// ", m.GoName, " ", strings.ReplaceAll(comment, "\n", "\n// "))
+	default:
+		g.P("// ", m.GoName, " 调用 ", m.Desc.FullName())
+	}
+
+	in, outIdent := g.QualifiedGoIdent(m.Input.GoIdent), g.QualifiedGoIdent(m.Output.GoIdent)
+	ctx := g.QualifiedGoIdent(contextPackage.Ident("Context"))
+	callOpt := g.QualifiedGoIdent(grpcPackage.Ident("CallOption"))
+
+	switch {
+	case !m.Desc.IsStreamingClient() && !m.Desc.IsStreamingServer():
+		g.P("func (c *", name, ") ", m.GoName, "(ctx ", ctx, ", req *", in, ", opts ...", callOpt, ") (*", outIdent, ", error) {")
+		g.P("return c.stub.", m.GoName, "(ctx, req, opts...)")
+	case !m.Desc.IsStreamingClient():
+		g.P("func (c *", name, ") ", m.GoName, "(ctx ", ctx, ", req *", in, ", opts ...", callOpt, ") (",
+			grpcPackage.Ident("ServerStreamingClient"), "[", outIdent, "], error) {")
+		g.P("return c.stub.", m.GoName, "(ctx, req, opts...)")
+	case !m.Desc.IsStreamingServer():
+		g.P("func (c *", name, ") ", m.GoName, "(ctx ", ctx, ", opts ...", callOpt, ") (",
+			grpcPackage.Ident("ClientStreamingClient"), "[", in, ", ", outIdent, "], error) {")
+		g.P("return c.stub.", m.GoName, "(ctx, opts...)")
+	default:
+		g.P("func (c *", name, ") ", m.GoName, "(ctx ", ctx, ", opts ...", callOpt, ") (",
+			grpcPackage.Ident("BidiStreamingClient"), "[", in, ", ", outIdent, "], error) {")
+		g.P("return c.stub.", m.GoName, "(ctx, opts...)")
+	}
+	g.P("}")
+	g.P()
+}
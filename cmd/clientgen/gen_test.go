@@ -0,0 +1,85 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"os"
+	"testing"
+
+	"github.com/bufbuild/protocompile"
+	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/pluginpb"
+)
+
+var update = flag.Bool("update", false, "update golden files")
+
+const goldenFile = "../../api/client/userclient/user.client.go"
+
+// TestGenerateGolden 编译 api/proto/user/user.proto 生成客户端并与仓库中的 userclient 比对，
+// 生成逻辑变更后运行 go test ./cmd/clientgen -update 更新
+func TestGenerateGolden(t *testing.T) {
+	compiler := protocompile.Compiler{
+		Resolver:       protocompile.WithStandardImports(&protocompile.SourceResolver{ImportPaths: []string{"../../api/proto/user", "../../api/proto"}}),
+		SourceInfoMode: protocompile.SourceInfoStandard,
+	}
+	files, err := compiler.Compile(context.Background(), "user.proto")
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := &pluginpb.CodeGeneratorRequest{
+		FileToGenerate: []string{"user.proto"},
+		Parameter: proto.String("module=github.com/ZampoRen/go-server-comon," +
+			"Muser.proto=github.com/ZampoRen/go-server-comon/api/model/user," +
+			"Mapi.proto=github.com/ZampoRen/go-server-comon/api/model/api"),
+	}
+	// 依赖在前，与 protoc 传给插件的顺序一致
+	seen := make(map[string]bool)
+	var add func(fd protoreflect.FileDescriptor)
+	add = func(fd protoreflect.FileDescriptor) {
+		if seen[fd.Path()] {
+			return
+		}
+		seen[fd.Path()] = true
+		for i := 0; i < fd.Imports().Len(); i++ {
+			add(fd.Imports().Get(i).FileDescriptor)
+		}
+		req.ProtoFile = append(req.ProtoFile, protodesc.ToFileDescriptorProto(fd))
+	}
+	add(files[0])
+
+	var flags flag.FlagSet
+	out := flags.String("out", "github.com/ZampoRen/go-server-comon/api/client", "")
+	rpc := flags.String("rpcclient", "github.com/ZampoRen/go-server-comon/internal/middleware/rpcclient", "")
+	gen, err := protogen.Options{ParamFunc: flags.Set}.New(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	generate(gen, *out, protogen.GoImportPath(*rpc))
+
+	resp := gen.Response()
+	if resp.Error != nil {
+		t.Fatal(resp.GetError())
+	}
+	if len(resp.File) != 1 || resp.File[0].GetName() != "api/client/userclient/user.client.go" {
+		t.Fatalf("generated files = %v", resp.File)
+	}
+	got := []byte(resp.File[0].GetContent())
+
+	if *update {
+		if err := os.WriteFile(goldenFile, got, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	want, err := os.ReadFile(goldenFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Fatalf("generated client differs from %s, run go test ./cmd/clientgen -update\n%s", goldenFile, got)
+	}
+}
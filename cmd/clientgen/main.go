@@ -0,0 +1,43 @@
+// clientgen 是一个 protoc 插件，为 proto 中定义的 gRPC 服务生成类型化客户端。
+//
+// 生成的 DialXxx 通过 internal/middleware/rpcclient 建立连接（keepalive、round_robin、errorx 解码与重试），
+// 只有 idempotency_level 为 NO_SIDE_EFFECTS 或 IDEMPOTENT 的方法会重试，
+// 服务间调用不再需要手写 dial 与 stub 样板代码。
+//
+// 用法:
+//
+//	go build -o bin/protoc-gen-client ./cmd/clientgen
+//	protoc -I api/proto/user -I api/proto \
+//		--plugin=protoc-gen-client=bin/protoc-gen-client \
+//		--client_out=. \
+//		--client_opt=module=github.com/ZampoRen/go-server-comon \
+//		--client_opt=Muser.proto=github.com/ZampoRen/go-server-comon/api/model/user \
+//		--client_opt=Mapi.proto=github.com/ZampoRen/go-server-comon/api/model/api \
+//		user.proto
+//
+// 参数:
+//   - out: 客户端代码的 Go 包路径前缀，默认 github.com/ZampoRen/go-server-comon/api/client，
+//     每个 proto 文件生成到 <out>/<name>client 包
+//   - rpcclient: 建立连接的包，默认 github.com/ZampoRen/go-server-comon/internal/middleware/rpcclient
+//   - module: 与 protoc-gen-go 相同，输出路径去掉该模块前缀
+//   - M<file>=<import path>: 与 protoc-gen-go 相同，指定 proto 文件对应的 Go 包
+package main
+
+import (
+	"flag"
+
+	"google.golang.org/protobuf/compiler/protogen"
+)
+
+func main() {
+	var flags flag.FlagSet
+	out := flags.String("out", "github.com/ZampoRen/go-server-comon/api/client", "client package path prefix")
+	rpc := flags.String("rpcclient", "github.com/ZampoRen/go-server-comon/internal/middleware/rpcclient", "client dial package")
+
+	protogen.Options{
+		ParamFunc: flags.Set,
+	}.Run(func(gen *protogen.Plugin) error {
+		generate(gen, *out, protogen.GoImportPath(*rpc))
+		return nil
+	})
+}
@@ -0,0 +1,56 @@
+// configdump 打印最终生效的配置，敏感字段脱敏，用于排查配置文件、环境配置与环境变量的叠加结果
+//
+// 用法:
+//
+//	go run ./cmd/configdump -config configs/user.yaml -env prod
+//
+// 参数:
+//   - config: 配置文件路径，为空时读取环境变量 CONFIG_PATH
+//   - env: 运行环境，覆盖环境变量 APP_ENV，决定叠加的环境配置文件，如 configs/user.prod.yaml
+//   - remote: 是否叠加 CONFIG_REMOTE 指定的配置中心的配置，默认 false
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/ZampoRen/go-server-comon/internal/config"
+	"github.com/ZampoRen/go-server-comon/internal/config/remote"
+)
+
+func main() {
+	path := flag.String("config", "", "config file path")
+	env := flag.String("env", "", "profile, overrides APP_ENV")
+	withRemote := flag.Bool("remote", false, "overlay remote config selected by CONFIG_REMOTE")
+	flag.Parse()
+
+	if *env != "" {
+		if err := os.Setenv(config.EnvProfile, *env); err != nil {
+			fatal(err)
+		}
+	}
+
+	var (
+		cfg *config.Config
+		err error
+	)
+	if *withRemote {
+		cfg, err = remote.Load(context.Background(), *path)
+	} else {
+		cfg, err = config.Load(*path)
+	}
+	if err != nil {
+		fatal(err)
+	}
+
+	if err := config.Dump(os.Stdout, cfg); err != nil {
+		fatal(err)
+	}
+}
+
+func fatal(err error) {
+	fmt.Fprintln(os.Stderr, "configdump:", err)
+	os.Exit(1)
+}
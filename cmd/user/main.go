@@ -2,46 +2,291 @@ package main
 
 import (
 	"context"
-	"log"
-	"os"
-	"os/signal"
-	"syscall"
+	"net"
 	"time"
 
 	"github.com/cloudwego/hertz/pkg/app/server"
 	"github.com/cloudwego/hertz/pkg/common/hlog"
+	"google.golang.org/grpc"
 
+	userhandler "github.com/ZampoRen/go-server-comon/api/handler/user"
+	pb "github.com/ZampoRen/go-server-comon/api/model/user"
 	"github.com/ZampoRen/go-server-comon/api/router"
+	userrouter "github.com/ZampoRen/go-server-comon/api/router/user"
+	"github.com/ZampoRen/go-server-comon/internal/featureflag"
+	"github.com/ZampoRen/go-server-comon/internal/idempotency"
+	"github.com/ZampoRen/go-server-comon/internal/infra/cache"
+	"github.com/ZampoRen/go-server-comon/internal/infra/orm"
+	"github.com/ZampoRen/go-server-comon/internal/infra/provider"
+	"github.com/ZampoRen/go-server-comon/internal/infra/ratelimit"
+	"github.com/ZampoRen/go-server-comon/internal/infra/storage"
+	"github.com/ZampoRen/go-server-comon/internal/middleware"
+	"github.com/ZampoRen/go-server-comon/internal/middleware/httpmw"
+	"github.com/ZampoRen/go-server-comon/internal/server/admin"
+	userserver "github.com/ZampoRen/go-server-comon/internal/server/user"
+	"github.com/ZampoRen/go-server-comon/internal/validate"
+	"github.com/ZampoRen/go-server-comon/pkg/di"
+	"github.com/ZampoRen/go-server-comon/pkg/discovery"
+	"github.com/ZampoRen/go-server-comon/pkg/envkey"
+	"github.com/ZampoRen/go-server-comon/pkg/eventbus"
+	"github.com/ZampoRen/go-server-comon/pkg/health"
+	"github.com/ZampoRen/go-server-comon/pkg/health/healthhttp"
+	"github.com/ZampoRen/go-server-comon/pkg/lifecycle"
+	"github.com/ZampoRen/go-server-comon/pkg/localcache"
+	logger "github.com/ZampoRen/go-server-comon/pkg/logs"
+	"github.com/ZampoRen/go-server-comon/pkg/trace"
 )
 
 func main() {
+	// 链路追踪与指标导出，未设置 OTEL_EXPORTER_OTLP_ENDPOINT 时不导出
+	shutdownTrace, err := trace.Init(context.Background(), trace.WithServiceName(envkey.GetStringD(trace.EnvServiceName, "user")))
+	if err != nil {
+		hlog.Fatalf("init trace failed: %v", err)
+	}
+
 	// 创建 Hertz 服务器
+	httpAddr := envkey.GetStringD("LISTEN_ADDR", ":8888")
+	grpcAddr := envkey.GetStringD("GRPC_ADDR", ":9090")
 	h := server.Default(
-		server.WithHostPorts(":8888"),
+		server.WithHostPorts(httpAddr),
 		server.WithHandleMethodNotAllowed(true),
+		server.WithCustomValidator(validate.Default()),
+	)
+
+	// 基础设施由容器按需创建，退出时按依赖的相反顺序释放
+	container := di.New()
+	if err := provider.Register(container); err != nil {
+		hlog.Fatalf("register providers failed: %v", err)
+	}
+
+	// 用户服务：MySQL 存储，按 ID 查询经过本地缓存，写操作通过 Redis 发布订阅通知其他实例删除本地缓存
+	db, err := di.Invoke[*orm.DB](context.Background(), container)
+	if err != nil {
+		hlog.Fatalf("init mysql failed: %v", err)
+	}
+	if envkey.GetBoolD("USER_AUTO_MIGRATE", true) {
+		if err := db.AutoMigrate(&userserver.User{}); err != nil {
+			hlog.Fatalf("migrate users failed: %v", err)
+		}
+	}
+	rdb, err := di.Invoke[cache.Cmdable](context.Background(), container)
+	if err != nil {
+		hlog.Fatalf("init redis failed: %v", err)
+	}
+	health.Register("mysql", health.CheckerFunc(func(ctx context.Context) error {
+		_, err := orm.HealthCheck(ctx, db)
+		return err
+	}))
+	health.Register("redis", health.CheckerFunc(cache.NewHealthChecker(rdb).Ready))
+
+	ps, ok := rdb.(cache.PubSub)
+	if !ok {
+		hlog.Fatalf("redis client does not support pub/sub")
+	}
+	invalidator := cache.NewInvalidator(ps, "user:invalidate")
+	userCache := localcache.New[*userserver.User](
+		localcache.WithLocalSuccessTTL(envkey.GetDurationD("USER_CACHE_TTL", time.Minute)),
+		localcache.WithDeleteKeyBefore(invalidator.Publish),
+		localcache.WithStats("user"),
 	)
 
-	// 注册路由（使用 hz 生成的路由注册函数）
+	// 用户变更事件通过进程内的 eventbus 发布，WatchHub 订阅后推送给 WatchUsers，
+	// 配置了 USER_EVENT_STREAM 时通过 Redis Stream 在实例间共享
+	var hubOpts []userserver.WatchHubOption
+	if stream := envkey.GetString("USER_EVENT_STREAM"); stream != "" {
+		streams, ok := rdb.(cache.Streams)
+		if !ok {
+			hlog.Fatalf("redis client does not support streams")
+		}
+		hubOpts = append(hubOpts, userserver.WithRedisStream(streams, stream, int64(envkey.GetIntD("USER_EVENT_STREAM_MAXLEN", 10000))))
+	}
+	userEvents := eventbus.New()
+	userWatchers := userserver.NewWatchHub(hubOpts...)
+	eventbus.Subscribe(userEvents, userWatchers.Publish, eventbus.WithName("user-watchers"))
+
+	userRepo := userserver.NewRepository(db,
+		userserver.WithCache(userCache),
+		userserver.WithEventBus(userEvents),
+	)
+
+	// 配置了 STORAGE_TYPE 时开启用户导入导出
+	var userOpts []userserver.Option
+	if envkey.GetStringD("STORAGE_TYPE", "") != "" {
+		store, err := di.Invoke[storage.Storage](context.Background(), container)
+		if err != nil {
+			hlog.Fatalf("init storage failed: %v", err)
+		}
+		userOpts = append(userOpts, userserver.WithStorage(store))
+		health.Register("storage", health.CheckerFunc(func(ctx context.Context) error {
+			return storage.Ready(ctx, store, "")
+		}), health.WithOptional())
+	}
+
+	// 配置了 JWT_SECRET 时开启注册登录，签发的 token 由 gRPC 认证拦截器与 HTTP JWTAuth 校验，
+	// 导入导出接口在 gRPC 与 HTTP 上均要求管理员角色，未配置时不可用；
+	// 登录失败次数通过 Redis 限流器在实例间共享
+	interceptors := []grpc.UnaryServerInterceptor{
+		middleware.UnaryServerTracingInterceptor(),
+		middleware.UnaryServerRecoveryInterceptor(),
+		middleware.UnaryServerLoggingInterceptor(),
+	}
+	streamInterceptors := []grpc.StreamServerInterceptor{
+		middleware.StreamServerTracingInterceptor(),
+		middleware.StreamServerRecoveryInterceptor(),
+		middleware.StreamServerLoggingInterceptor(),
+	}
+	if envkey.GetString("JWT_SECRET") != "" {
+		// 刷新 token 时按用户记录重新加载角色
+		sessions, err := middleware.NewSessionManager(rdb, middleware.WithSessionRoles(userRepo.Roles))
+		if err != nil {
+			hlog.Fatalf("init session manager failed: %v", err)
+		}
+		userOpts = append(userOpts,
+			userserver.WithSessions(sessions),
+			userserver.WithLoginLimit(rdb, ratelimit.NewRedis(rdb), userserver.DefaultLoginLimit),
+		)
+		userrouter.SetAuthMiddleware(httpmw.JWTAuth(sessions))
+		userrouter.SetAdminMiddleware(httpmw.JWTAuth(sessions), httpmw.RequireRole(userserver.RoleAdmin))
+		authOpts := []middleware.AuthOption{middleware.WithSessionManager(sessions), middleware.WithPublicMethods(
+			"/grpc.health.v1.Health/*",
+			pb.User_GetUser_FullMethodName,
+			pb.User_CreateUser_FullMethodName,
+			pb.User_ListUsers_FullMethodName,
+			pb.User_Register_FullMethodName,
+			pb.User_Login_FullMethodName,
+		)}
+		interceptors = append(interceptors, middleware.UnaryServerAuthInterceptor(authOpts...))
+		streamInterceptors = append(streamInterceptors, middleware.StreamServerAuthInterceptor(authOpts...))
+	}
+	// 创建用户的请求携带 Idempotency-Key 时只执行一次，客户端超时重试不会重复创建
+	idempotencyStore := idempotency.New(rdb)
+	userrouter.SetIdempotencyMiddleware(httpmw.Idempotency(idempotencyStore))
+	interceptors = append(interceptors,
+		middleware.UnaryServerErrorxInterceptor(),
+		middleware.UnaryServerValidationInterceptor(validate.Default()),
+		middleware.UnaryServerIdempotencyInterceptor(idempotencyStore, pb.User_CreateUser_FullMethodName),
+	)
+	streamInterceptors = append(streamInterceptors, middleware.StreamServerErrorxInterceptor())
+
+	userSvc := userserver.NewServer(userRepo, append(userOpts, userserver.WithWatchHub(userWatchers))...)
+	userhandler.SetService(userSvc)
+
+	// 注册路由（使用 hz 生成的路由注册函数）与探针
 	router.GeneratedRegister(h)
+	healthhttp.Register(h, health.Default())
 
-	// 启动服务器（在 goroutine 中）
-	go func() {
-		h.Spin()
-	}()
+	// gRPC 服务：用户服务与标准健康服务
+	grpcServer := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(interceptors...),
+		grpc.ChainStreamInterceptor(streamInterceptors...),
+	)
+	pb.RegisterUserServer(grpcServer, userSvc)
+	health.RegisterGRPC(grpcServer, health.Default())
+
+	// 按依赖顺序注册组件，退出时按相反顺序停止：先摘除流量，再停止服务，最后释放基础设施与日志
+	lc := lifecycle.New()
+	lc.Append(lifecycle.Hook{Name: "logger", OnStop: func(context.Context) error {
+		return logger.Default().Sync()
+	}})
+	lc.Append(lifecycle.Hook{Name: "trace", OnStop: shutdownTrace})
+	lc.Append(lifecycle.Hook{Name: "components", OnStart: container.Start, OnStop: container.Stop})
 
-	// 等待中断信号
-	quit := make(chan os.Signal, 1)
-	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
-	<-quit
+	var invalidation cache.Subscription
+	lc.Append(lifecycle.Hook{
+		Name: "user-cache",
+		OnStart: func(ctx context.Context) (err error) {
+			invalidation, err = invalidator.Subscribe(ctx, userCache.DelLocal)
+			return err
+		},
+		OnStop: func(context.Context) error {
+			defer userCache.Stop()
+			return invalidation.Close()
+		},
+	})
 
-	hlog.Info("Shutting down server...")
+	lc.Append(lifecycle.Hook{Name: "user-events", OnStop: userEvents.Close})
+	lc.Append(lifecycle.Hook{Name: "user-watch-hub", OnStart: userWatchers.Start, OnStop: userWatchers.Stop})
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
+	// 功能开关存储在 Redis 中，各服务的实例通过发布订阅同步，灰度按 JWT 声明中的用户计算
+	featureflag.SetDefault(featureflag.New(featureflag.WithUserFunc(func(ctx context.Context) string {
+		if claims, ok := middleware.ClaimsFromContext(ctx); ok {
+			return claims.Subject
+		}
+		return featureflag.UserFromContext(ctx)
+	})))
+	flagStore := featureflag.NewRedisStore(rdb, featureflag.WithKey(envkey.GetStringD("FEATURE_FLAG_KEY", featureflag.DefaultRedisKey)))
+	lc.Append(lifecycle.Hook{Name: "featureflag", OnStart: flagStore.Start, OnStop: flagStore.Stop})
 
-	if err := h.Shutdown(ctx); err != nil {
-		hlog.Fatalf("Server forced to shutdown: %v", err)
+	var adminServer *admin.Server
+	lc.Append(lifecycle.Hook{
+		Name: "admin",
+		OnStart: func(context.Context) (err error) {
+			adminServer, err = admin.Start()
+			return err
+		},
+		OnStop: func(ctx context.Context) error { return adminServer.Shutdown(ctx) },
+	})
+	lc.AppendServer("grpc", func() error {
+		lis, err := net.Listen("tcp", grpcAddr)
+		if err != nil {
+			return err
+		}
+		return grpcServer.Serve(lis)
+	}, func(ctx context.Context) error { return stopGRPC(ctx, grpcServer) })
+	// 先结束 WatchUsers 的订阅，gRPC 服务才能优雅停止
+	lc.Append(lifecycle.Hook{Name: "user-watchers", OnStop: func(context.Context) error {
+		userWatchers.Shutdown()
+		return nil
+	}})
+	lc.AppendServer("http", h.Run, h.Shutdown)
+	lc.Append(lifecycle.Hook{Name: "readiness", OnStop: func(context.Context) error {
+		health.Default().Shutdown()
+		return nil
+	}})
+
+	// 配置了 DISCOVERY_BACKEND 时按就绪状态注册 gRPC 与 HTTP 服务，退出时最先注销，调用方不再把新请求发往本实例
+	backend, err := discovery.NewFromEnv()
+	if err != nil {
+		hlog.Fatalf("init discovery failed: %v", err)
+	}
+	if backend != nil {
+		lc.Append(lifecycle.Hook{Name: "discovery", OnStop: func(context.Context) error { return backend.Close() }})
+		serviceName := envkey.GetStringD("APP_NAME", "user-service")
+		for _, ins := range []struct{ service, protocol, listen string }{
+			{serviceName, "grpc", grpcAddr},
+			{serviceName + "-http", "http", httpAddr},
+		} {
+			addr, err := discovery.HostAddr(ins.listen)
+			if err != nil {
+				hlog.Fatalf("resolve %s address failed: %v", ins.protocol, err)
+			}
+			registrar := discovery.NewRegistrar(backend, discovery.Instance{
+				Service:  ins.service,
+				Addr:     addr,
+				Metadata: map[string]string{"protocol": ins.protocol},
+			})
+			lc.Append(lifecycle.Hook{Name: ins.protocol + "-registrar", OnStart: registrar.Start, OnStop: registrar.Stop})
+		}
+	}
+
+	if err := lc.Run(context.Background()); err != nil {
+		hlog.Fatalf("server exited: %v", err)
 	}
+	hlog.Info("server exited")
+}
 
-	log.Println("Server exited")
+// stopGRPC 优雅停止 gRPC 服务，ctx 结束时强制关闭剩余连接
+func stopGRPC(ctx context.Context, s *grpc.Server) error {
+	done := make(chan struct{})
+	go func() {
+		s.GracefulStop()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		s.Stop()
+		return ctx.Err()
+	}
 }
@@ -0,0 +1,255 @@
+// Package bootstrap 根据 config.Config 统一初始化日志、Redis、MySQL、Elasticsearch、对象存储、本地缓存与功能开关
+//
+//	cfg, err := config.Load("")
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	infra, err := bootstrap.Init(ctx, cfg)
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	defer infra.Close(context.Background())
+//
+// 各组件只在配置了对应的地址或类型时创建，未配置的组件在 Container 中为 nil。
+// 与 provider 包不同，组件的配置全部来自 Config，不再由各基础设施包各自读取环境变量
+package bootstrap
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+
+	goredis "github.com/redis/go-redis/v9"
+
+	"github.com/ZampoRen/go-server-comon/internal/config"
+	"github.com/ZampoRen/go-server-comon/internal/featureflag"
+	"github.com/ZampoRen/go-server-comon/internal/infra/cache"
+	"github.com/ZampoRen/go-server-comon/internal/infra/cache/impl/redis"
+	"github.com/ZampoRen/go-server-comon/internal/infra/es"
+	esimpl "github.com/ZampoRen/go-server-comon/internal/infra/es/impl/es"
+	"github.com/ZampoRen/go-server-comon/internal/infra/orm"
+	"github.com/ZampoRen/go-server-comon/internal/infra/orm/impl/mysql"
+	"github.com/ZampoRen/go-server-comon/internal/infra/storage"
+	storageimpl "github.com/ZampoRen/go-server-comon/internal/infra/storage/impl"
+	"github.com/ZampoRen/go-server-comon/internal/infra/storage/impl/gcs"
+	"github.com/ZampoRen/go-server-comon/internal/infra/storage/impl/memory"
+	"github.com/ZampoRen/go-server-comon/pkg/localcache"
+	logger "github.com/ZampoRen/go-server-comon/pkg/logs"
+)
+
+// Container 基础设施实例
+type Container struct {
+	// Config 创建实例使用的配置
+	Config *config.Config
+	// Redis 未配置 redis.addr 时为 nil
+	Redis cache.Cmdable
+	// DB 未配置 mysql.dsn 时为 nil
+	DB *orm.DB
+	// ES 未配置 es.addr 时为 nil
+	ES es.Client
+	// Storage 未配置 storage.type 时为 nil
+	Storage storage.Storage
+
+	localCaches map[string]localcache.Cache[any]
+
+	closers   []closer
+	closeOnce sync.Once
+	closeErr  error
+}
+
+type closer struct {
+	name  string
+	close func(ctx context.Context) error
+}
+
+// Option 初始化选项
+type Option func(o *option)
+
+type option struct {
+	skipLogger   bool
+	redisOptions []redis.Option
+	esOptions    []esimpl.Option
+}
+
+// WithoutLogger 不根据 log 配置初始化全局日志，适用于已自行初始化日志的场景
+func WithoutLogger() Option {
+	return func(o *option) {
+		o.skipLogger = true
+	}
+}
+
+// WithRedisOptions 追加创建 Redis 客户端的选项，如 redis.WithTracing
+func WithRedisOptions(opts ...redis.Option) Option {
+	return func(o *option) {
+		o.redisOptions = append(o.redisOptions, opts...)
+	}
+}
+
+// WithESOptions 追加创建 Elasticsearch 客户端的选项，如 es.WithTracing
+func WithESOptions(opts ...esimpl.Option) Option {
+	return func(o *option) {
+		o.esOptions = append(o.esOptions, opts...)
+	}
+}
+
+// Init 根据 cfg 依次初始化日志、Redis、MySQL、Elasticsearch、对象存储与本地缓存，
+// 配置了 featureFlags 时以其替换 featureflag 默认集合中的开关
+// 任一组件初始化失败时关闭已创建的组件并返回错误
+func Init(ctx context.Context, cfg *config.Config, opts ...Option) (*Container, error) {
+	o := &option{}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	c := &Container{Config: cfg, localCaches: make(map[string]localcache.Cache[any])}
+	if err := c.init(ctx, o); err != nil {
+		if closeErr := c.Close(ctx); closeErr != nil {
+			return nil, errors.Join(err, closeErr)
+		}
+		return nil, err
+	}
+	return c, nil
+}
+
+func (c *Container) init(ctx context.Context, o *option) error {
+	cfg := c.Config
+
+	if !o.skipLogger {
+		if err := logger.Init(cfg.Log.Level, cfg.Log.OutputPaths); err != nil {
+			return fmt.Errorf("init logger failed: %w", err)
+		}
+	}
+
+	if cfg.Redis.Addr != "" {
+		rdb := redis.NewWithClientOptions(&goredis.Options{
+			Addr:            cfg.Redis.Addr,
+			Password:        cfg.Redis.Password,
+			DB:              cfg.Redis.DB,
+			PoolSize:        cfg.Redis.PoolSize,
+			MinIdleConns:    cfg.Redis.MinIdleConns,
+			MaxIdleConns:    cfg.Redis.MaxIdleConns,
+			ConnMaxIdleTime: cfg.Redis.ConnMaxIdleTime,
+			DialTimeout:     cfg.Redis.DialTimeout,
+			ReadTimeout:     cfg.Redis.ReadTimeout,
+			WriteTimeout:    cfg.Redis.WriteTimeout,
+		}, o.redisOptions...)
+		c.Redis = rdb
+		c.addCloser("redis", func(context.Context) error {
+			if closer, ok := rdb.(io.Closer); ok {
+				return closer.Close()
+			}
+			return nil
+		})
+		if err := rdb.Ping(ctx).Err(); err != nil {
+			return fmt.Errorf("init redis failed: %w", err)
+		}
+	}
+
+	if cfg.MySQL.DSN != "" {
+		db, err := mysql.NewWithOptions(&mysql.Config{
+			DSN:         cfg.MySQL.DSN,
+			ReplicaDSNs: cfg.MySQL.ReplicaDSNs,
+			Pool: &orm.PoolConfig{
+				MaxOpenConns:    cfg.MySQL.MaxOpenConns,
+				MaxIdleConns:    cfg.MySQL.MaxIdleConns,
+				ConnMaxLifetime: cfg.MySQL.ConnMaxLifetime,
+				ConnMaxIdleTime: cfg.MySQL.ConnMaxIdleTime,
+			},
+			LogLevel:                  cfg.MySQL.LogLevel,
+			SlowThreshold:             cfg.MySQL.SlowThreshold,
+			IgnoreRecordNotFoundError: true,
+		})
+		if err != nil {
+			return fmt.Errorf("init mysql failed: %w", err)
+		}
+		c.DB = db
+		c.addCloser("mysql", func(context.Context) error {
+			sqlDB, err := db.DB()
+			if err != nil {
+				return err
+			}
+			return sqlDB.Close()
+		})
+	}
+
+	if cfg.ES.Addr != "" {
+		esOpts := append([]esimpl.Option{
+			esimpl.WithVersion(cfg.ES.Version),
+			esimpl.WithAddr(cfg.ES.Addr),
+			esimpl.WithBasicAuth(cfg.ES.Username, cfg.ES.Password),
+		}, o.esOptions...)
+		client, err := esimpl.New(esOpts...)
+		if err != nil {
+			return fmt.Errorf("init es failed: %w", err)
+		}
+		c.ES = client
+	}
+
+	if cfg.Storage.Type != "" {
+		s, err := newStorage(ctx, cfg.Storage)
+		if err != nil {
+			return fmt.Errorf("init storage failed: %w", err)
+		}
+		c.Storage = s
+	}
+
+	for name, lc := range map[string]config.CacheConfig{"user": cfg.LocalCache.User} {
+		if !lc.Enable() {
+			continue
+		}
+		lcache := localcache.New[any](
+			localcache.WithLocalSlotNum(lc.SlotNum),
+			localcache.WithLocalSlotSize(lc.SlotSize),
+			localcache.WithLocalSuccessTTL(lc.Success()),
+			localcache.WithLocalFailedTTL(lc.Failed()),
+		)
+		c.localCaches[name] = lcache
+		c.addCloser("localcache."+name, func(context.Context) error {
+			lcache.Stop()
+			return nil
+		})
+	}
+
+	if len(cfg.FeatureFlags) > 0 {
+		featureflag.Update(cfg.FeatureFlags)
+	}
+	return nil
+}
+
+// newStorage 根据存储配置创建存储客户端
+func newStorage(ctx context.Context, cfg config.StorageConfig) (storage.Storage, error) {
+	switch cfg.Type {
+	case "memory":
+		return memory.New(memory.WithBucket(cfg.Bucket)), nil
+	case "gcs":
+		// 凭证使用 Application Default Credentials，需要服务账号密钥时请使用 gcs.NewWithConfig
+		return gcs.NewWithConfig(ctx, &gcs.Config{Bucket: cfg.Bucket, Endpoint: cfg.Endpoint})
+	default:
+		return storageimpl.NewWithType(ctx, cfg.Type, cfg.AccessKey, cfg.SecretKey, cfg.Bucket, cfg.Endpoint, cfg.Region)
+	}
+}
+
+// LocalCache 返回 localCache 配置中对应名称（如 user）的本地缓存，未启用时返回 nil
+func (c *Container) LocalCache(name string) localcache.Cache[any] {
+	return c.localCaches[name]
+}
+
+// Close 按初始化的相反顺序关闭所有组件，返回所有关闭失败的错误，多次调用只关闭一次
+func (c *Container) Close(ctx context.Context) error {
+	c.closeOnce.Do(func() {
+		var errs []error
+		for i := len(c.closers) - 1; i >= 0; i-- {
+			if err := c.closers[i].close(ctx); err != nil {
+				errs = append(errs, fmt.Errorf("close %s failed: %w", c.closers[i].name, err))
+			}
+		}
+		c.closeErr = errors.Join(errs...)
+	})
+	return c.closeErr
+}
+
+func (c *Container) addCloser(name string, fn func(ctx context.Context) error) {
+	c.closers = append(c.closers, closer{name: name, close: fn})
+}
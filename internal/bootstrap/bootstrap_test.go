@@ -0,0 +1,34 @@
+package bootstrap
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ZampoRen/go-server-comon/internal/config"
+)
+
+func TestInit(t *testing.T) {
+	cfg := config.Default()
+	cfg.Storage.Type = "memory"
+	cfg.Storage.Bucket = "test"
+	cfg.LocalCache.User = config.CacheConfig{Topic: "user", SlotNum: 4, SlotSize: 100, SuccessExpire: 60, FailedExpire: 5}
+
+	ctx := context.Background()
+	infra, err := Init(ctx, cfg, WithoutLogger())
+	if err != nil {
+		t.Fatalf("Init() error = %v", err)
+	}
+	if infra.Redis != nil || infra.DB != nil || infra.ES != nil {
+		t.Error("unconfigured components should be nil")
+	}
+	if infra.Storage == nil || infra.LocalCache("user") == nil {
+		t.Error("configured components should be created")
+	}
+
+	if err := infra.Close(ctx); err != nil {
+		t.Errorf("Close() error = %v", err)
+	}
+	if err := infra.Close(ctx); err != nil {
+		t.Errorf("second Close() error = %v", err)
+	}
+}
@@ -1,24 +1,94 @@
 package config
 
-// Config holds application configuration
+import (
+	"time"
+
+	"github.com/ZampoRen/go-server-comon/internal/featureflag"
+	"github.com/ZampoRen/go-server-comon/pkg/envkey"
+)
+
+// Config 应用配置
+// 字段的 env 标签为对应的环境变量，设置时覆盖配置文件中的值，变量名与各基础设施包读取的环境变量保持一致
+// default 标签为默认值，见 envkey.ApplyDefaults；validate 标签为加载后的校验规则，见 Validate
+// secret 标签标记敏感字段，Dump 输出时脱敏，见 Redact
 type Config struct {
-	// TODO: Add configuration fields
-	Server ServerConfig
+	Server     ServerConfig  `yaml:"server" toml:"server"`
+	Log        LogConfig     `yaml:"log" toml:"log"`
+	Redis      RedisConfig   `yaml:"redis" toml:"redis"`
+	MySQL      MySQLConfig   `yaml:"mysql" toml:"mysql"`
+	ES         ESConfig      `yaml:"es" toml:"es"`
+	Storage    StorageConfig `yaml:"storage" toml:"storage"`
+	LocalCache LocalCache    `yaml:"localCache" toml:"localCache"`
+	// FeatureFlags 功能开关，键为开关名
+	FeatureFlags map[string]featureflag.Flag `yaml:"featureFlags" toml:"featureFlags"`
 }
 
-// ServerConfig holds server configuration
+// ServerConfig 服务配置
 type ServerConfig struct {
-	Host string
-	Port int
-}
-
-// Load loads configuration from file or environment
-func Load(path string) (*Config, error) {
-	// TODO: Implement configuration loading
-	return &Config{
-		Server: ServerConfig{
-			Host: "0.0.0.0",
-			Port: 50051,
-		},
-	}, nil
+	Host string `yaml:"host" toml:"host" env:"SERVER_HOST" default:"0.0.0.0"`
+	Port int    `yaml:"port" toml:"port" env:"SERVER_PORT" default:"50051" validate:"min=1,max=65535"`
+}
+
+// LogConfig 日志配置
+type LogConfig struct {
+	// Level 日志级别，可选值: debug, info, warn, error
+	Level string `yaml:"level" toml:"level" env:"LOG_LEVEL" default:"info" validate:"oneof=debug info warn error"`
+	// OutputPaths 日志输出路径，如 stdout、/var/log/app.log
+	OutputPaths []string `yaml:"outputPaths" toml:"outputPaths" env:"LOG_OUTPUT_PATHS" default:"stdout"`
+}
+
+// RedisConfig Redis 配置
+type RedisConfig struct {
+	Addr            string        `yaml:"addr" toml:"addr" env:"REDIS_ADDR"`
+	Password        string        `yaml:"password" toml:"password" env:"REDIS_PASSWORD" secret:"true"`
+	DB              int           `yaml:"db" toml:"db" env:"REDIS_DB" validate:"min=0,max=15"`
+	PoolSize        int           `yaml:"poolSize" toml:"poolSize" env:"REDIS_POOL_SIZE" default:"100" validate:"min=1"`
+	MinIdleConns    int           `yaml:"minIdleConns" toml:"minIdleConns" env:"REDIS_MIN_IDLE_CONNS" default:"10"`
+	MaxIdleConns    int           `yaml:"maxIdleConns" toml:"maxIdleConns" env:"REDIS_MAX_IDLE_CONNS" default:"30"`
+	ConnMaxIdleTime time.Duration `yaml:"connMaxIdleTime" toml:"connMaxIdleTime" env:"REDIS_CONN_MAX_IDLE_TIME" default:"5m"`
+	DialTimeout     time.Duration `yaml:"dialTimeout" toml:"dialTimeout" env:"REDIS_DIAL_TIMEOUT" default:"5s" validate:"min=1ms"`
+	ReadTimeout     time.Duration `yaml:"readTimeout" toml:"readTimeout" env:"REDIS_READ_TIMEOUT" default:"3s" validate:"min=1ms"`
+	WriteTimeout    time.Duration `yaml:"writeTimeout" toml:"writeTimeout" env:"REDIS_WRITE_TIMEOUT" default:"3s" validate:"min=1ms"`
+}
+
+// MySQLConfig MySQL 配置
+type MySQLConfig struct {
+	DSN             string        `yaml:"dsn" toml:"dsn" env:"MYSQL_DSN" secret:"dsn"`
+	ReplicaDSNs     []string      `yaml:"replicaDSNs" toml:"replicaDSNs" env:"MYSQL_REPLICA_DSNS" secret:"dsn"`
+	MaxOpenConns    int           `yaml:"maxOpenConns" toml:"maxOpenConns" env:"MYSQL_MAX_OPEN_CONNS" default:"100" validate:"min=1"`
+	MaxIdleConns    int           `yaml:"maxIdleConns" toml:"maxIdleConns" env:"MYSQL_MAX_IDLE_CONNS" default:"10"`
+	ConnMaxLifetime time.Duration `yaml:"connMaxLifetime" toml:"connMaxLifetime" env:"MYSQL_CONN_MAX_LIFETIME" default:"1h"`
+	ConnMaxIdleTime time.Duration `yaml:"connMaxIdleTime" toml:"connMaxIdleTime" env:"MYSQL_CONN_MAX_IDLE_TIME" default:"10m"`
+	// LogLevel 日志级别，可选值: silent, error, warn, info
+	LogLevel      string        `yaml:"logLevel" toml:"logLevel" env:"MYSQL_LOG_LEVEL" default:"info" validate:"oneof=silent error warn info"`
+	SlowThreshold time.Duration `yaml:"slowThreshold" toml:"slowThreshold" env:"MYSQL_SLOW_THRESHOLD" default:"200ms"`
+}
+
+// ESConfig Elasticsearch 配置
+type ESConfig struct {
+	// Version 版本，可选值: v7, v8
+	Version  string `yaml:"version" toml:"version" env:"ES_VERSION" validate:"omitempty,oneof=v7 v8"`
+	Addr     string `yaml:"addr" toml:"addr" env:"ES_ADDR"`
+	Username string `yaml:"username" toml:"username" env:"ES_USERNAME"`
+	Password string `yaml:"password" toml:"password" env:"ES_PASSWORD" secret:"true"`
+}
+
+// StorageConfig 对象存储配置
+type StorageConfig struct {
+	// Type 厂商，可选值: tos, aliyun, tencent, gcs, memory
+	Type      string `yaml:"type" toml:"type" env:"STORAGE_TYPE" validate:"omitempty,oneof=tos aliyun tencent gcs memory"`
+	Bucket    string `yaml:"bucket" toml:"bucket" env:"STORAGE_BUCKET"`
+	AccessKey string `yaml:"accessKey" toml:"accessKey" env:"STORAGE_ACCESS_KEY"`
+	SecretKey string `yaml:"secretKey" toml:"secretKey" env:"STORAGE_SECRET_KEY" secret:"true"`
+	Endpoint  string `yaml:"endpoint" toml:"endpoint" env:"STORAGE_ENDPOINT"`
+	Region    string `yaml:"region" toml:"region" env:"STORAGE_REGION"`
+}
+
+// Default 返回默认配置，即各字段 default 标签的值，与各基础设施包未设置环境变量时的默认值一致
+func Default() *Config {
+	cfg := &Config{}
+	if err := envkey.ApplyDefaults(cfg); err != nil {
+		panic(err)
+	}
+	return cfg
 }
@@ -0,0 +1,34 @@
+package config
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestDump(t *testing.T) {
+	cfg := Default()
+	cfg.Redis.Password = "redis-pass"
+	cfg.MySQL.DSN = "root:p@ss@tcp(db:3306)/app?parseTime=true"
+	cfg.MySQL.ReplicaDSNs = []string{"reader:pass@tcp(replica:3306)/app", "reader@tcp(replica:3306)/app"}
+
+	var buf bytes.Buffer
+	if err := Dump(&buf, cfg); err != nil {
+		t.Fatalf("Dump() error = %v", err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		"password: '******'",
+		"dsn: root:******@tcp(db:3306)/app?parseTime=true",
+		"- reader:******@tcp(replica:3306)/app",
+		"- reader@tcp(replica:3306)/app",
+		"dialTimeout: 5s",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Dump() output missing %q:\n%s", want, out)
+		}
+	}
+	if cfg.Redis.Password != "redis-pass" || cfg.MySQL.ReplicaDSNs[0] != "reader:pass@tcp(replica:3306)/app" {
+		t.Error("Dump() modified the original config")
+	}
+}
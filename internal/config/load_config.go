@@ -1 +1,160 @@
 package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"go.yaml.in/yaml/v3"
+
+	"github.com/ZampoRen/go-server-comon/pkg/envkey"
+)
+
+const (
+	// EnvConfigPath 配置文件路径的环境变量，Load 的 path 为空时使用
+	EnvConfigPath = "CONFIG_PATH"
+	// EnvProfile 运行环境的环境变量，如 dev、staging、prod，用于选择叠加的环境配置文件
+	EnvProfile = "APP_ENV"
+)
+
+// envPattern 匹配 ${VAR} 与 ${VAR:-default}
+var envPattern = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)(?::-([^}]*))?\}`)
+
+// Load 加载配置，优先级：环境变量 > 配置文件 > Default，加载后按 validate 标签校验，不合法时返回汇总所有字段的错误
+// path 为空时读取环境变量 CONFIG_PATH，仍为空时只使用默认值与环境变量
+// 根据扩展名解析 YAML（.yaml、.yml）、JSON（.json）或 TOML（.toml），
+// 文件中的 ${VAR}、${VAR:-default} 会在解析前替换为环境变量，替换后的值可能包含特殊字符时应加引号
+//
+// 设置了环境变量 APP_ENV 时，在 path 之后叠加同目录下的环境配置文件，如 config.yaml 与 APP_ENV=prod
+// 对应 config.prod.yaml，见 ProfilePath；环境配置文件不存在时忽略
+func Load(path string) (*Config, error) {
+	return LoadWithOverlays(path)
+}
+
+// Overlay 叠加在配置文件之上的配置内容，如配置中心下发的配置
+type Overlay struct {
+	Name   string // 来源名称，用于错误信息
+	Format string // 格式，可选值: yaml, json, toml
+	Data   []byte // 配置内容
+}
+
+// LoadWithOverlays 同 Load，在配置文件之后依次叠加 overlays，后面的覆盖前面的，环境变量仍然优先
+// 叠加时对象按字段深度合并，未出现的字段保留之前的值，数组整体替换
+func LoadWithOverlays(path string, overlays ...Overlay) (*Config, error) {
+	if path == "" {
+		path = envkey.GetString(EnvConfigPath)
+	}
+
+	cfg := Default()
+	if path != "" {
+		if err := DecodeFile(path, cfg); err != nil {
+			return nil, err
+		}
+
+		if profile := envkey.GetString(EnvProfile); profile != "" {
+			if err := DecodeFile(ProfilePath(path, profile), cfg); err != nil && !errors.Is(err, fs.ErrNotExist) {
+				return nil, err
+			}
+		}
+	}
+
+	for _, o := range overlays {
+		if err := Decode(ExpandEnv(o.Data), o.Format, cfg); err != nil {
+			return nil, fmt.Errorf("parse config %s failed: %w", o.Name, err)
+		}
+	}
+
+	if err := envkey.Bind(cfg); err != nil {
+		return nil, err
+	}
+	if err := Validate(cfg); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// DecodeFile 解析配置文件到 v，v 中已有的值作为默认值，文件中出现未知字段时返回错误
+// 服务自定义的配置结构也可以使用该函数加载
+func DecodeFile(path string, v any) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("read config %s failed: %w", path, err)
+	}
+
+	if err := Decode(ExpandEnv(data), FormatOf(path), v); err != nil {
+		return fmt.Errorf("parse config %s failed: %w", path, err)
+	}
+	return nil
+}
+
+// ProfilePath 返回 path 对应 profile 环境的配置文件路径，如 configs/config.yaml 与 prod 返回 configs/config.prod.yaml
+func ProfilePath(path, profile string) string {
+	ext := filepath.Ext(path)
+	return strings.TrimSuffix(path, ext) + "." + profile + ext
+}
+
+// FormatOf 根据扩展名返回配置格式，未知扩展名返回去掉点的扩展名
+func FormatOf(path string) string {
+	switch ext := strings.ToLower(strings.TrimPrefix(filepath.Ext(path), ".")); ext {
+	case "yml":
+		return "yaml"
+	default:
+		return ext
+	}
+}
+
+// Decode 按 format 解析 data 到 v，v 中已有的值作为默认值，出现未知字段时返回错误
+func Decode(data []byte, format string, v any) error {
+	switch format {
+	case "yaml", "json":
+		// JSON 是 YAML 的子集，统一使用 YAML 解析，时长字段可以写作 "5s"
+		return decodeYAML(data, v)
+	case "toml":
+		return decodeTOML(data, v)
+	default:
+		return fmt.Errorf("unsupported config format %q", format)
+	}
+}
+
+func decodeYAML(data []byte, v any) error {
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(v); err != nil && !errors.Is(err, io.EOF) {
+		return err
+	}
+	return nil
+}
+
+func decodeTOML(data []byte, v any) error {
+	md, err := toml.Decode(string(data), v)
+	if err != nil {
+		return err
+	}
+	if undecoded := md.Undecoded(); len(undecoded) > 0 {
+		keys := make([]string, 0, len(undecoded))
+		for _, k := range undecoded {
+			keys = append(keys, k.String())
+		}
+		return fmt.Errorf("unknown fields: %s", strings.Join(keys, ", "))
+	}
+	return nil
+}
+
+// ExpandEnv 将 data 中的 ${VAR} 替换为环境变量的值，未设置时替换为空；
+// ${VAR:-default} 在未设置或为空时替换为 default。不处理 $VAR 形式，避免误替换密码等值中的 $
+func ExpandEnv(data []byte) []byte {
+	return envPattern.ReplaceAllFunc(data, func(m []byte) []byte {
+		sub := envPattern.FindSubmatch(m)
+		if v := os.Getenv(string(sub[1])); v != "" {
+			return []byte(v)
+		}
+		return sub[2]
+	})
+}
@@ -0,0 +1,134 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func writeConfig(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoad(t *testing.T) {
+	files := map[string]string{
+		"config.yaml": `
+server:
+  port: 8080
+redis:
+  addr: ${TEST_REDIS_HOST:-localhost}:6379
+  dialTimeout: 2s
+mysql:
+  dsn: "${TEST_MYSQL_DSN}"
+`,
+		"config.json": `{
+  "server": {"port": 8080},
+  "redis": {"addr": "${TEST_REDIS_HOST:-localhost}:6379", "dialTimeout": "2s"},
+  "mysql": {"dsn": "${TEST_MYSQL_DSN}"}
+}`,
+		"config.toml": `
+[server]
+port = 8080
+[redis]
+addr = "${TEST_REDIS_HOST:-localhost}:6379"
+dialTimeout = "2s"
+[mysql]
+dsn = "${TEST_MYSQL_DSN}"
+`,
+	}
+
+	for name, content := range files {
+		t.Run(name, func(t *testing.T) {
+			t.Setenv("TEST_MYSQL_DSN", "root@tcp(db:3306)/app")
+			t.Setenv("REDIS_PASSWORD", "secret")
+
+			cfg, err := Load(writeConfig(t, name, content))
+			if err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+			if cfg.Server.Port != 8080 || cfg.Server.Host != "0.0.0.0" {
+				t.Errorf("server = %+v", cfg.Server)
+			}
+			if cfg.Redis.Addr != "localhost:6379" || cfg.Redis.DialTimeout != 2*time.Second || cfg.Redis.ReadTimeout != 3*time.Second {
+				t.Errorf("redis = %+v", cfg.Redis)
+			}
+			if cfg.Redis.Password != "secret" {
+				t.Errorf("redis password = %q, want env override", cfg.Redis.Password)
+			}
+			if cfg.MySQL.DSN != "root@tcp(db:3306)/app" {
+				t.Errorf("mysql dsn = %q", cfg.MySQL.DSN)
+			}
+		})
+	}
+}
+
+func TestLoadFeatureFlags(t *testing.T) {
+	files := map[string]string{
+		"config.yaml": "featureFlags:\n  new_ranking:\n    enabled: true\n    percentage: 0\n    users: [\"1\"]\n  dark_mode:\n    enabled: true\n",
+		"config.json": `{"featureFlags": {"new_ranking": {"enabled": true, "percentage": 0, "users": ["1"]}, "dark_mode": {"enabled": true}}}`,
+		"config.toml": "[featureFlags.new_ranking]\nenabled = true\npercentage = 0\nusers = [\"1\"]\n[featureFlags.dark_mode]\nenabled = true\n",
+	}
+	for name, content := range files {
+		t.Run(name, func(t *testing.T) {
+			cfg, err := Load(writeConfig(t, name, content))
+			if err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+			rollout := cfg.FeatureFlags["new_ranking"]
+			if !rollout.Enabled || rollout.Percentage == nil || *rollout.Percentage != 0 || len(rollout.Users) != 1 {
+				t.Errorf("new_ranking = %+v", rollout)
+			}
+			if rollout.EnabledFor("new_ranking", "2") || !rollout.EnabledFor("new_ranking", "1") {
+				t.Error("percentage 0 should only enable allowlisted users")
+			}
+			if flag := cfg.FeatureFlags["dark_mode"]; flag.Percentage != nil || !flag.EnabledFor("dark_mode", "2") {
+				t.Errorf("dark_mode = %+v", flag)
+			}
+		})
+	}
+}
+
+func TestLoadUnknownField(t *testing.T) {
+	path := writeConfig(t, "config.yaml", "redis:\n  adr: localhost:6379\n")
+	if _, err := Load(path); err == nil {
+		t.Error("Load() error = nil, want unknown field error")
+	}
+}
+
+func TestLoadProfile(t *testing.T) {
+	path := writeConfig(t, "config.yaml", "redis:\n  addr: localhost:6379\n  db: 1\nmysql:\n  replicaDSNs: [a, b]\n")
+	if err := os.WriteFile(ProfilePath(path, "prod"), []byte("redis:\n  addr: redis-prod:6379\nmysql:\n  replicaDSNs: [c]\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("叠加环境配置", func(t *testing.T) {
+		t.Setenv(EnvProfile, "prod")
+		cfg, err := Load(path)
+		if err != nil {
+			t.Fatalf("Load() error = %v", err)
+		}
+		if cfg.Redis.Addr != "redis-prod:6379" || cfg.Redis.DB != 1 {
+			t.Errorf("redis = %+v", cfg.Redis)
+		}
+		if len(cfg.MySQL.ReplicaDSNs) != 1 || cfg.MySQL.ReplicaDSNs[0] != "c" {
+			t.Errorf("replicaDSNs = %v", cfg.MySQL.ReplicaDSNs)
+		}
+	})
+
+	t.Run("环境配置不存在", func(t *testing.T) {
+		t.Setenv(EnvProfile, "dev")
+		cfg, err := Load(path)
+		if err != nil {
+			t.Fatalf("Load() error = %v", err)
+		}
+		if cfg.Redis.Addr != "localhost:6379" {
+			t.Errorf("redis addr = %s", cfg.Redis.Addr)
+		}
+	})
+}
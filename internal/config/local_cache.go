@@ -3,15 +3,15 @@ package config
 import "time"
 
 type LocalCache struct {
-	User CacheConfig `yaml:"user"`
+	User CacheConfig `yaml:"user" toml:"user"`
 }
 
 type CacheConfig struct {
-	Topic         string `yaml:"topic"`
-	SlotNum       int    `yaml:"slotNum"`
-	SlotSize      int    `yaml:"slotSize"`
-	SuccessExpire int    `yaml:"successExpire"`
-	FailedExpire  int    `yaml:"failedExpire"`
+	Topic         string `yaml:"topic" toml:"topic"`
+	SlotNum       int    `yaml:"slotNum" toml:"slotNum"`
+	SlotSize      int    `yaml:"slotSize" toml:"slotSize"`
+	SuccessExpire int    `yaml:"successExpire" toml:"successExpire"`
+	FailedExpire  int    `yaml:"failedExpire" toml:"failedExpire"`
 }
 
 func (l *CacheConfig) Failed() time.Duration {
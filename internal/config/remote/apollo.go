@@ -0,0 +1,229 @@
+package remote
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.yaml.in/yaml/v3"
+
+	"github.com/ZampoRen/go-server-comon/internal/config"
+	"github.com/ZampoRen/go-server-comon/pkg/envkey"
+)
+
+const (
+	// apolloLongPollTimeout 通知请求在服务端最长挂起 60s，客户端超时需大于该值
+	apolloLongPollTimeout = 90 * time.Second
+	// apolloDefaultCluster 默认集群
+	apolloDefaultCluster = "default"
+	// apolloDefaultNamespace 默认命名空间
+	apolloDefaultNamespace = "application"
+)
+
+// ApolloConfig Apollo 配置
+type ApolloConfig struct {
+	// Addr Config Service 地址，如 http://apollo-config:8080
+	Addr string
+	// AppID 应用 ID
+	AppID string
+	// Cluster 集群，默认 default
+	Cluster string
+	// Namespace 命名空间，默认 application
+	// 带扩展名的命名空间（如 user.yaml、user.json）按对应格式解析 content，
+	// 不带扩展名的 properties 命名空间将 redis.addr 形式的键转换为嵌套对象
+	Namespace string
+	// Secret 开启访问密钥时的密钥
+	Secret string
+	// HTTPClient 自定义 HTTP 客户端，默认 http.DefaultClient
+	HTTPClient *http.Client
+}
+
+// apolloSource Apollo 配置中心，使用 HTTP 接口拉取配置并通过通知接口长轮询监听变更
+type apolloSource struct {
+	cfg    ApolloConfig
+	client *http.Client
+
+	mu             sync.Mutex
+	notificationID int64
+}
+
+// NewApolloFromEnv 从环境变量 APOLLO_ADDR、APOLLO_APP_ID、APOLLO_CLUSTER、APOLLO_NAMESPACE、APOLLO_SECRET
+// 创建 Apollo 配置中心
+func NewApolloFromEnv() (Source, error) {
+	return NewApollo(ApolloConfig{
+		Addr:      envkey.GetString("APOLLO_ADDR"),
+		AppID:     envkey.GetString("APOLLO_APP_ID"),
+		Cluster:   envkey.GetString("APOLLO_CLUSTER"),
+		Namespace: envkey.GetString("APOLLO_NAMESPACE"),
+		Secret:    envkey.GetString("APOLLO_SECRET"),
+	})
+}
+
+// NewApollo 创建 Apollo 配置中心
+func NewApollo(cfg ApolloConfig) (Source, error) {
+	if cfg.Addr == "" || cfg.AppID == "" {
+		return nil, fmt.Errorf("apollo addr and app id are required")
+	}
+	if cfg.Cluster == "" {
+		cfg.Cluster = apolloDefaultCluster
+	}
+	if cfg.Namespace == "" {
+		cfg.Namespace = apolloDefaultNamespace
+	}
+	cfg.Addr = strings.TrimRight(cfg.Addr, "/")
+
+	client := cfg.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	// 通知 ID 从 -1 开始，首次请求立即返回当前的通知 ID
+	return &apolloSource{cfg: cfg, client: client, notificationID: -1}, nil
+}
+
+func (s *apolloSource) Name() string {
+	return "apollo:" + s.cfg.AppID + "/" + s.cfg.Namespace
+}
+
+func (s *apolloSource) Fetch(ctx context.Context) (config.Overlay, error) {
+	path := fmt.Sprintf("/configs/%s/%s/%s", url.PathEscape(s.cfg.AppID), url.PathEscape(s.cfg.Cluster), url.PathEscape(s.cfg.Namespace))
+	resp, body, err := s.get(ctx, path)
+	if err != nil {
+		return config.Overlay{}, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return config.Overlay{}, statusError(s.Name(), resp, body)
+	}
+
+	var res struct {
+		Configurations map[string]string `json:"configurations"`
+	}
+	if err := json.Unmarshal(body, &res); err != nil {
+		return config.Overlay{}, fmt.Errorf("parse apollo response failed: %w", err)
+	}
+
+	if format := config.FormatOf(s.cfg.Namespace); format != "" && format != "properties" {
+		return config.Overlay{Name: s.Name(), Format: format, Data: []byte(res.Configurations["content"])}, nil
+	}
+
+	data, err := propertiesToYAML(res.Configurations)
+	if err != nil {
+		return config.Overlay{}, err
+	}
+	return config.Overlay{Name: s.Name(), Format: "yaml", Data: data}, nil
+}
+
+func (s *apolloSource) WaitChange(ctx context.Context) error {
+	for {
+		changed, err := s.poll(ctx)
+		if err != nil || changed {
+			return err
+		}
+	}
+}
+
+// poll 发起一次通知长轮询，304 表示未变更；首次请求只记录通知 ID，不视为变更
+func (s *apolloSource) poll(ctx context.Context) (bool, error) {
+	s.mu.Lock()
+	id := s.notificationID
+	s.mu.Unlock()
+
+	notifications, err := json.Marshal([]map[string]any{{"namespaceName": s.cfg.Namespace, "notificationId": id}})
+	if err != nil {
+		return false, err
+	}
+	q := url.Values{
+		"appId":         {s.cfg.AppID},
+		"cluster":       {s.cfg.Cluster},
+		"notifications": {string(notifications)},
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, apolloLongPollTimeout)
+	defer cancel()
+	resp, body, err := s.get(ctx, "/notifications/v2?"+q.Encode())
+	if err != nil {
+		return false, err
+	}
+	switch resp.StatusCode {
+	case http.StatusNotModified:
+		return false, nil
+	case http.StatusOK:
+	default:
+		return false, statusError(s.Name(), resp, body)
+	}
+
+	var res []struct {
+		NamespaceName  string `json:"namespaceName"`
+		NotificationID int64  `json:"notificationId"`
+	}
+	if err := json.Unmarshal(body, &res); err != nil {
+		return false, fmt.Errorf("parse apollo notifications failed: %w", err)
+	}
+	for _, n := range res {
+		if n.NamespaceName != s.cfg.Namespace {
+			continue
+		}
+		s.mu.Lock()
+		s.notificationID = n.NotificationID
+		s.mu.Unlock()
+		return id != -1, nil
+	}
+	return false, nil
+}
+
+// get 发送 GET 请求，配置了密钥时按 Apollo 的规则签名
+func (s *apolloSource) get(ctx context.Context, pathWithQuery string) (*http.Response, []byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.cfg.Addr+pathWithQuery, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	if s.cfg.Secret != "" {
+		timestamp := strconv.FormatInt(time.Now().UnixMilli(), 10)
+		mac := hmac.New(sha1.New, []byte(s.cfg.Secret))
+		mac.Write([]byte(timestamp + "\n" + req.URL.RequestURI()))
+		req.Header.Set("Authorization", "Apollo "+s.cfg.AppID+":"+base64.StdEncoding.EncodeToString(mac.Sum(nil)))
+		req.Header.Set("Timestamp", timestamp)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	return resp, body, err
+}
+
+// propertiesToYAML 将 redis.addr=... 形式的键值转换为嵌套的 YAML
+// 值按 YAML 标量解析，使 100、true、5s 分别得到整数、布尔值与字符串
+func propertiesToYAML(props map[string]string) ([]byte, error) {
+	root := make(map[string]any)
+	for key, value := range props {
+		var v any
+		if err := yaml.Unmarshal([]byte(value), &v); err != nil {
+			v = value
+		}
+
+		parts := strings.Split(key, ".")
+		m := root
+		for _, part := range parts[:len(parts)-1] {
+			child, ok := m[part].(map[string]any)
+			if !ok {
+				child = make(map[string]any)
+				m[part] = child
+			}
+			m = child
+		}
+		m[parts[len(parts)-1]] = v
+	}
+	return yaml.Marshal(root)
+}
@@ -0,0 +1,210 @@
+package remote
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ZampoRen/go-server-comon/internal/config"
+	"github.com/ZampoRen/go-server-comon/pkg/envkey"
+)
+
+const (
+	// nacosLongPollTimeout 监听请求在服务端挂起的时长
+	nacosLongPollTimeout = 30 * time.Second
+	// nacosDefaultGroup 默认分组
+	nacosDefaultGroup = "DEFAULT_GROUP"
+)
+
+// NacosConfig Nacos 配置
+type NacosConfig struct {
+	// Addr 服务地址，如 http://nacos:8848
+	Addr string
+	// Namespace 命名空间 ID，为空时使用 public
+	Namespace string
+	// Group 分组，默认 DEFAULT_GROUP
+	Group string
+	// DataID 配置 ID，扩展名决定配置格式，如 user.yaml
+	DataID string
+	// Username、Password 开启鉴权时的账号
+	Username string
+	Password string
+	// HTTPClient 自定义 HTTP 客户端，默认 http.DefaultClient
+	HTTPClient *http.Client
+}
+
+// nacosSource Nacos 配置中心，使用 Open API 拉取配置并通过长轮询监听变更
+type nacosSource struct {
+	cfg    NacosConfig
+	client *http.Client
+
+	mu          sync.Mutex
+	md5         string
+	token       string
+	tokenExpire time.Time
+}
+
+// NewNacosFromEnv 从环境变量 NACOS_ADDR、NACOS_NAMESPACE、NACOS_GROUP、NACOS_DATA_ID、
+// NACOS_USERNAME、NACOS_PASSWORD 创建 Nacos 配置中心
+func NewNacosFromEnv() (Source, error) {
+	return NewNacos(NacosConfig{
+		Addr:      envkey.GetString("NACOS_ADDR"),
+		Namespace: envkey.GetString("NACOS_NAMESPACE"),
+		Group:     envkey.GetString("NACOS_GROUP"),
+		DataID:    envkey.GetString("NACOS_DATA_ID"),
+		Username:  envkey.GetString("NACOS_USERNAME"),
+		Password:  envkey.GetString("NACOS_PASSWORD"),
+	})
+}
+
+// NewNacos 创建 Nacos 配置中心
+func NewNacos(cfg NacosConfig) (Source, error) {
+	if cfg.Addr == "" || cfg.DataID == "" {
+		return nil, fmt.Errorf("nacos addr and data id are required")
+	}
+	if cfg.Group == "" {
+		cfg.Group = nacosDefaultGroup
+	}
+	cfg.Addr = strings.TrimRight(cfg.Addr, "/")
+
+	client := cfg.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &nacosSource{cfg: cfg, client: client}, nil
+}
+
+func (s *nacosSource) Name() string {
+	return "nacos:" + s.cfg.DataID
+}
+
+func (s *nacosSource) Fetch(ctx context.Context) (config.Overlay, error) {
+	q := url.Values{"dataId": {s.cfg.DataID}, "group": {s.cfg.Group}}
+	if s.cfg.Namespace != "" {
+		q.Set("tenant", s.cfg.Namespace)
+	}
+	if err := s.auth(ctx, q); err != nil {
+		return config.Overlay{}, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.cfg.Addr+"/nacos/v1/cs/configs?"+q.Encode(), nil)
+	if err != nil {
+		return config.Overlay{}, err
+	}
+	body, err := s.do(req)
+	if err != nil {
+		return config.Overlay{}, err
+	}
+
+	sum := md5.Sum(body)
+	s.mu.Lock()
+	s.md5 = hex.EncodeToString(sum[:])
+	s.mu.Unlock()
+
+	return config.Overlay{Name: s.Name(), Format: config.FormatOf(s.cfg.DataID), Data: body}, nil
+}
+
+func (s *nacosSource) WaitChange(ctx context.Context) error {
+	for {
+		changed, err := s.listen(ctx)
+		if err != nil || changed {
+			return err
+		}
+	}
+}
+
+// listen 发起一次长轮询，服务端在配置变更或超时后返回，变更时响应体为变更的配置列表
+func (s *nacosSource) listen(ctx context.Context) (bool, error) {
+	s.mu.Lock()
+	sum := s.md5
+	s.mu.Unlock()
+
+	// 格式为 dataId^2group^2md5^2tenant^1，其中 ^1、^2 为分隔字符
+	listening := s.cfg.DataID + "\x02" + s.cfg.Group + "\x02" + sum
+	if s.cfg.Namespace != "" {
+		listening += "\x02" + s.cfg.Namespace
+	}
+	listening += "\x01"
+
+	q := url.Values{}
+	if err := s.auth(ctx, q); err != nil {
+		return false, err
+	}
+	form := url.Values{"Listening-Configs": {listening}}
+
+	ctx, cancel := context.WithTimeout(ctx, nacosLongPollTimeout+10*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.cfg.Addr+"/nacos/v1/cs/configs/listener?"+q.Encode(), strings.NewReader(form.Encode()))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Long-Pulling-Timeout", strconv.FormatInt(nacosLongPollTimeout.Milliseconds(), 10))
+
+	body, err := s.do(req)
+	if err != nil {
+		return false, err
+	}
+	return strings.TrimSpace(string(body)) != "", nil
+}
+
+// auth 开启鉴权时登录并在查询参数中追加 accessToken，token 过期前复用
+func (s *nacosSource) auth(ctx context.Context, q url.Values) error {
+	if s.cfg.Username == "" {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.token == "" || time.Now().After(s.tokenExpire) {
+		form := url.Values{"username": {s.cfg.Username}, "password": {s.cfg.Password}}
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.cfg.Addr+"/nacos/v1/auth/login", strings.NewReader(form.Encode()))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		body, err := s.do(req)
+		if err != nil {
+			return fmt.Errorf("nacos login failed: %w", err)
+		}
+
+		var res struct {
+			AccessToken string `json:"accessToken"`
+			TokenTTL    int64  `json:"tokenTtl"`
+		}
+		if err := json.Unmarshal(body, &res); err != nil {
+			return fmt.Errorf("parse nacos login response failed: %w", err)
+		}
+		s.token = res.AccessToken
+		// 提前 10% 刷新，避免请求途中过期
+		s.tokenExpire = time.Now().Add(time.Duration(res.TokenTTL) * time.Second * 9 / 10)
+	}
+	q.Set("accessToken", s.token)
+	return nil
+}
+
+func (s *nacosSource) do(req *http.Request) ([]byte, error) {
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, statusError(s.Name(), resp, body)
+	}
+	return body, nil
+}
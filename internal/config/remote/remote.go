@@ -0,0 +1,136 @@
+// Package remote 从配置中心（Nacos、Apollo）拉取与监听配置，叠加在本地配置文件之上
+//
+//	cfg, err := remote.Load(ctx, "")
+//	go remote.Watch(ctx, src, "", func(cfg *config.Config) { ... })
+//
+// 配置中心通过环境变量 CONFIG_REMOTE 选择，可选值为 nacos、apollo，为空时只使用本地配置
+// 优先级：环境变量 > 配置中心 > 配置文件 > config.Default
+package remote
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/common/hlog"
+
+	"github.com/ZampoRen/go-server-comon/internal/config"
+	"github.com/ZampoRen/go-server-comon/pkg/envkey"
+)
+
+const (
+	// EnvRemote 配置中心类型的环境变量
+	EnvRemote = "CONFIG_REMOTE"
+	// TypeNacos Nacos 配置中心
+	TypeNacos = "nacos"
+	// TypeApollo Apollo 配置中心
+	TypeApollo = "apollo"
+
+	// retryInterval 监听失败后的重试间隔
+	retryInterval = 5 * time.Second
+)
+
+var (
+	// ErrConfigNotFound 配置中心中不存在对应的配置
+	ErrConfigNotFound = errors.New("remote config not found")
+)
+
+// Source 配置中心
+type Source interface {
+	// Name 返回配置来源的描述，如 nacos:user.yaml
+	Name() string
+	// Fetch 拉取当前配置
+	Fetch(ctx context.Context) (config.Overlay, error)
+	// WaitChange 长轮询等待配置变更，返回 nil 表示配置已变更需要重新拉取
+	// 未变更时在内部继续等待，直到变更或 ctx 结束
+	WaitChange(ctx context.Context) error
+}
+
+// NewFromEnv 根据环境变量 CONFIG_REMOTE 创建配置中心，未设置时返回 nil
+func NewFromEnv() (Source, error) {
+	switch t := envkey.GetString(EnvRemote); t {
+	case "":
+		return nil, nil
+	case TypeNacos:
+		return NewNacosFromEnv()
+	case TypeApollo:
+		return NewApolloFromEnv()
+	default:
+		return nil, fmt.Errorf("unsupported remote config type %q", t)
+	}
+}
+
+// Load 加载本地配置文件并叠加 CONFIG_REMOTE 指定的配置中心的配置，path 的含义同 config.Load
+func Load(ctx context.Context, path string) (*config.Config, error) {
+	src, err := NewFromEnv()
+	if err != nil {
+		return nil, err
+	}
+	return LoadFrom(ctx, src, path)
+}
+
+// LoadFrom 同 Load，使用指定的配置中心，src 为 nil 时只加载本地配置
+func LoadFrom(ctx context.Context, src Source, path string) (*config.Config, error) {
+	if src == nil {
+		return config.Load(path)
+	}
+
+	overlay, err := src.Fetch(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("fetch config from %s failed: %w", src.Name(), err)
+	}
+	return config.LoadWithOverlays(path, overlay)
+}
+
+// Watch 监听配置中心的变更，每次变更后重新加载完整配置并调用 onChange，阻塞直到 ctx 结束
+// 拉取或解析失败时保留旧配置，打印告警并等待重试间隔后继续监听，避免配置中心持续出错时空转
+func Watch(ctx context.Context, src Source, path string, onChange func(cfg *config.Config)) {
+	for {
+		if err := src.WaitChange(ctx); err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			hlog.CtxWarnf(ctx, "[RemoteConfig] watch %s failed, retry in %v: %v", src.Name(), retryInterval, err)
+			if !sleep(ctx, retryInterval) {
+				return
+			}
+			continue
+		}
+
+		cfg, err := LoadFrom(ctx, src, path)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			hlog.CtxWarnf(ctx, "[RemoteConfig] reload %s failed, keep current config, retry in %v: %v", src.Name(), retryInterval, err)
+			if !sleep(ctx, retryInterval) {
+				return
+			}
+			continue
+		}
+		hlog.CtxInfof(ctx, "[RemoteConfig] config %s changed", src.Name())
+		onChange(cfg)
+	}
+}
+
+// sleep 等待 d，ctx 结束时返回 false
+func sleep(ctx context.Context, d time.Duration) bool {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-t.C:
+		return true
+	}
+}
+
+// statusError 配置中心返回的非预期状态码
+func statusError(name string, resp *http.Response, body []byte) error {
+	if resp.StatusCode == http.StatusNotFound {
+		return fmt.Errorf("%w: %s", ErrConfigNotFound, name)
+	}
+	return fmt.Errorf("%s: unexpected status %d: %s", name, resp.StatusCode, body)
+}
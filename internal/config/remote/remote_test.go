@@ -0,0 +1,138 @@
+package remote
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ZampoRen/go-server-comon/internal/config"
+)
+
+func TestNacos(t *testing.T) {
+	var version atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/nacos/v1/cs/configs":
+			if r.URL.Query().Get("dataId") != "user.yaml" || r.URL.Query().Get("group") != "DEFAULT_GROUP" {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			if version.Load() == 0 {
+				_, _ = w.Write([]byte("redis:\n  addr: nacos-redis:6379\n"))
+			} else {
+				_, _ = w.Write([]byte("redis:\n  addr: nacos-redis-2:6379\n"))
+			}
+		case "/nacos/v1/cs/configs/listener":
+			_ = r.ParseForm()
+			if !strings.HasPrefix(r.PostForm.Get("Listening-Configs"), "user.yaml\x02DEFAULT_GROUP\x02") {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			version.Store(1)
+			_, _ = w.Write([]byte("user.yaml%02DEFAULT_GROUP%01"))
+		}
+	}))
+	defer srv.Close()
+
+	src, err := NewNacos(NacosConfig{Addr: srv.URL, DataID: "user.yaml"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	cfg, err := LoadFrom(ctx, src, "")
+	if err != nil {
+		t.Fatalf("LoadFrom() error = %v", err)
+	}
+	if cfg.Redis.Addr != "nacos-redis:6379" || cfg.Redis.PoolSize != 100 {
+		t.Errorf("redis = %+v", cfg.Redis)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	changed := make(chan *config.Config, 1)
+	go Watch(ctx, src, "", func(cfg *config.Config) {
+		select {
+		case changed <- cfg:
+		default:
+		}
+	})
+	select {
+	case cfg := <-changed:
+		if cfg.Redis.Addr != "nacos-redis-2:6379" {
+			t.Errorf("changed redis addr = %s", cfg.Redis.Addr)
+		}
+	case <-ctx.Done():
+		t.Fatal("Watch() did not report change")
+	}
+}
+
+func TestApolloProperties(t *testing.T) {
+	var polls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/configs/user/default/application":
+			_, _ = w.Write([]byte(`{"configurations":{"redis.addr":"apollo-redis:6379","redis.poolSize":"20","redis.dialTimeout":"1s"}}`))
+		case r.URL.Path == "/notifications/v2":
+			// 首次请求返回当前通知 ID，第二次返回变更
+			n := polls.Add(1)
+			_, _ = w.Write([]byte(`[{"namespaceName":"application","notificationId":` + strconv.Itoa(int(n)) + `}]`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	src, err := NewApollo(ApolloConfig{Addr: srv.URL, AppID: "user", Secret: "s"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := LoadFrom(context.Background(), src, "")
+	if err != nil {
+		t.Fatalf("LoadFrom() error = %v", err)
+	}
+	if cfg.Redis.Addr != "apollo-redis:6379" || cfg.Redis.PoolSize != 20 || cfg.Redis.DialTimeout != time.Second {
+		t.Errorf("redis = %+v", cfg.Redis)
+	}
+
+	if err := src.WaitChange(context.Background()); err != nil {
+		t.Fatalf("WaitChange() error = %v", err)
+	}
+	if got := polls.Load(); got != 2 {
+		t.Errorf("polls = %d, want 2", got)
+	}
+}
+
+// failingSource 每次都报告变更，但拉取总是失败
+type failingSource struct {
+	fetches atomic.Int32
+}
+
+func (s *failingSource) Name() string { return "failing" }
+
+func (s *failingSource) Fetch(context.Context) (config.Overlay, error) {
+	s.fetches.Add(1)
+	return config.Overlay{}, errors.New("unavailable")
+}
+
+func (s *failingSource) WaitChange(context.Context) error { return nil }
+
+func TestWatchReloadFailure(t *testing.T) {
+	src := &failingSource{}
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	Watch(ctx, src, "", func(*config.Config) {
+		t.Error("onChange should not be called")
+	})
+	// 拉取失败后等待 retryInterval，不会立即再次拉取
+	if got := src.fetches.Load(); got != 1 {
+		t.Errorf("fetches = %d, want 1", got)
+	}
+}
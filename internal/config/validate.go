@@ -0,0 +1,36 @@
+package config
+
+import (
+	"context"
+	"sync"
+
+	"github.com/ZampoRen/go-server-comon/internal/validate"
+	"github.com/ZampoRen/go-server-comon/pkg/errorx/code"
+)
+
+// ErrCodeInvalidConfig 配置校验失败
+const ErrCodeInvalidConfig int32 = 100301
+
+func init() {
+	code.Register(ErrCodeInvalidConfig, "配置错误: {details}")
+}
+
+var (
+	validatorOnce sync.Once
+	validator     *validate.Validator
+)
+
+// Validate 按 validate 标签校验 v，返回汇总了所有不合法字段的 errorx 错误（错误码 ErrCodeInvalidConfig）
+// 规则与请求参数相同，使用 internal/validate（go-playground/validator）的语法，如 required、min=1、oneof=a b，
+// 可选字段需加 omitempty；时长的限制值写作 5s 等格式。
+// 字段名取 yaml 标签，不合法的字段路径记录在 Extra 的 fields 中，如 redis.poolSize
+func Validate(v any) error {
+	validatorOnce.Do(func() {
+		validator = validate.New(
+			validate.WithDefaultLocale("en"),
+			validate.WithFieldNameTags("yaml"),
+			validate.WithErrorCode(ErrCodeInvalidConfig),
+		)
+	})
+	return validator.Struct(context.Background(), v)
+}
@@ -0,0 +1,60 @@
+package config
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ZampoRen/go-server-comon/pkg/errorx"
+)
+
+func TestDefault(t *testing.T) {
+	cfg := Default()
+	if cfg.Server.Port != 50051 || cfg.Redis.ConnMaxIdleTime != 5*time.Minute || cfg.MySQL.LogLevel != "info" {
+		t.Errorf("Default() = %+v", cfg)
+	}
+	if err := Validate(cfg); err != nil {
+		t.Errorf("Validate(Default()) error = %v", err)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	t.Run("汇总所有不合法字段", func(t *testing.T) {
+		cfg := Default()
+		cfg.Server.Port = 70000
+		cfg.Redis.ReadTimeout = time.Microsecond
+		cfg.ES.Version = "v6"
+
+		err := Validate(cfg)
+		var statusErr errorx.StatusError
+		if !errors.As(err, &statusErr) || statusErr.Code() != ErrCodeInvalidConfig {
+			t.Fatalf("Validate() error = %v", err)
+		}
+		if got := statusErr.Extra()["fields"]; got != "server.port,redis.readTimeout,es.version" {
+			t.Errorf("fields = %s", got)
+		}
+		if !strings.Contains(err.Error(), "version must be one of [v7 v8]") {
+			t.Errorf("error = %v", err)
+		}
+	})
+
+	t.Run("required 与 omitempty", func(t *testing.T) {
+		var v struct {
+			Name  string `yaml:"name" validate:"required"`
+			Level string `yaml:"level" validate:"omitempty,oneof=a b"`
+		}
+		err := Validate(&v)
+		var statusErr errorx.StatusError
+		if !errors.As(err, &statusErr) || statusErr.Extra()["fields"] != "name" {
+			t.Errorf("Validate() error = %v", err)
+		}
+	})
+
+	t.Run("加载时校验", func(t *testing.T) {
+		t.Setenv("REDIS_POOL_SIZE", "-1")
+		if _, err := Load(writeConfig(t, "config.yaml", "server:\n  port: 8080\n")); err == nil {
+			t.Error("Load() error = nil, want validation error")
+		}
+	})
+}
@@ -0,0 +1,418 @@
+// Package cron 分布式定时任务调度，同一任务在集群中每个执行时间点只由一个实例执行
+//
+//	c := cron.New(redisClient, cron.WithKeyPrefix("order-service:cron:"))
+//	_ = c.Add("close-expired-orders", "*/5 * * * *", closeExpiredOrders,
+//		cron.WithTimeout(time.Minute), cron.WithJitter(10*time.Second))
+//	_ = c.Add("daily-report", "@daily", sendDailyReport, cron.WithOverlap(cron.OverlapWait))
+//	lc.Append(lifecycle.Hook{Name: "cron", OnStart: c.Start, OnStop: c.Stop})
+//
+// 每个执行时间点以 "前缀 + 任务名 + 毫秒时间戳" 为键通过 SET NX 抢占，抢到的实例执行，键在 WithLockTTL 后过期，
+// 不主动释放，避免各实例时钟偏差导致同一时间点执行两次。client 为 nil 时不做集群互斥，只在本进程内调度。
+// 任务 panic 时记录堆栈并按失败统计，不影响其他任务与后续调度
+package cron
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"runtime/debug"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/common/hlog"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/ZampoRen/go-server-comon/internal/infra/cache"
+	"github.com/ZampoRen/go-server-comon/internal/infra/cache/lock"
+)
+
+var (
+	// ErrStarted 调度器已启动
+	ErrStarted = errors.New("cron: scheduler already started")
+	// ErrDuplicateJob 任务名已存在
+	ErrDuplicateJob = errors.New("cron: duplicate job name")
+)
+
+// Job 定时任务，ctx 在超时或调度器停止等待超时后取消
+type Job func(ctx context.Context) error
+
+// Overlap 上一次执行尚未结束时再次到达执行时间的处理策略，启用集群互斥时在集群范围内生效
+type Overlap int
+
+const (
+	// OverlapSkip 跳过本次执行，默认策略
+	OverlapSkip Overlap = iota
+	// OverlapAllow 允许并发执行
+	OverlapAllow
+	// OverlapWait 等待上一次执行结束后再执行
+	OverlapWait
+)
+
+// Option 调度器选项
+type Option func(o *option)
+
+type option struct {
+	keyPrefix  string
+	lockTTL    time.Duration
+	loc        *time.Location
+	registerer prometheus.Registerer
+}
+
+// WithKeyPrefix 设置锁的键前缀，默认 cron:，多个服务共用 Redis 时应区分
+func WithKeyPrefix(prefix string) Option {
+	return func(o *option) {
+		o.keyPrefix = prefix
+	}
+}
+
+// WithLockTTL 设置执行时间点锁的过期时间，默认 1 分钟，应大于各实例间的最大时钟偏差与 jitter
+func WithLockTTL(d time.Duration) Option {
+	return func(o *option) {
+		if d > 0 {
+			o.lockTTL = d
+		}
+	}
+}
+
+// WithLocation 设置解析 cron 表达式使用的时区，默认 time.Local
+func WithLocation(loc *time.Location) Option {
+	return func(o *option) {
+		if loc != nil {
+			o.loc = loc
+		}
+	}
+}
+
+// WithRegisterer 设置指标注册器，默认 prometheus.DefaultRegisterer
+func WithRegisterer(reg prometheus.Registerer) Option {
+	return func(o *option) {
+		if reg != nil {
+			o.registerer = reg
+		}
+	}
+}
+
+// JobOption 任务选项
+type JobOption func(o *jobOption)
+
+type jobOption struct {
+	overlap Overlap
+	timeout time.Duration
+	jitter  time.Duration
+}
+
+// WithOverlap 设置重叠执行策略，默认 OverlapSkip
+func WithOverlap(p Overlap) JobOption {
+	return func(o *jobOption) {
+		o.overlap = p
+	}
+}
+
+// WithTimeout 设置单次执行的超时时间，默认不限制
+func WithTimeout(d time.Duration) JobOption {
+	return func(o *jobOption) {
+		if d > 0 {
+			o.timeout = d
+		}
+	}
+}
+
+// WithJitter 每次执行前随机等待 [0, d)，分散同一时间点触发的任务对下游的压力
+func WithJitter(d time.Duration) JobOption {
+	return func(o *jobOption) {
+		if d > 0 {
+			o.jitter = d
+		}
+	}
+}
+
+type entry struct {
+	name     string
+	schedule Schedule
+	job      Job
+	o        *jobOption
+
+	// running 本进程内正在执行的次数，用于本进程内的重叠控制
+	mu      sync.Mutex
+	running int
+	idle    *sync.Cond
+}
+
+// Scheduler 定时任务调度器
+type Scheduler struct {
+	client cache.Cmdable
+	o      *option
+
+	runs     *prometheus.CounterVec
+	duration *prometheus.HistogramVec
+	success  *prometheus.GaugeVec
+
+	mu      sync.Mutex
+	entries []*entry
+	names   map[string]struct{}
+	cancel  context.CancelFunc
+	jobCtx  context.Context
+	stopJob context.CancelFunc
+	wg      sync.WaitGroup
+}
+
+// New 创建调度器，client 用于集群互斥，为 nil 时只在本进程内调度
+// 导出的指标：
+//   - cron_job_runs_total{job, result}: 执行次数，result 为 ok、error、panic、skipped
+//   - cron_job_duration_seconds{job}: 执行耗时
+//   - cron_job_last_success_timestamp_seconds{job}: 最近一次成功执行的结束时间
+func New(client cache.Cmdable, opts ...Option) *Scheduler {
+	o := &option{
+		keyPrefix:  "cron:",
+		lockTTL:    time.Minute,
+		loc:        time.Local,
+		registerer: prometheus.DefaultRegisterer,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return &Scheduler{
+		client: client,
+		o:      o,
+		names:  make(map[string]struct{}),
+		runs: register(o.registerer, prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "cron",
+			Subsystem: "job",
+			Name:      "runs_total",
+			Help:      "Number of cron job runs by result.",
+		}, []string{"job", "result"})),
+		duration: register(o.registerer, prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: "cron",
+			Subsystem: "job",
+			Name:      "duration_seconds",
+			Help:      "Duration of cron job runs in seconds.",
+			Buckets:   prometheus.ExponentialBuckets(.01, 4, 10),
+		}, []string{"job"})),
+		success: register(o.registerer, prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: "cron",
+			Subsystem: "job",
+			Name:      "last_success_timestamp_seconds",
+			Help:      "Unix time of the last successful cron job run.",
+		}, []string{"job"})),
+	}
+}
+
+// Add 添加任务，name 在集群内唯一并作为锁的键，spec 的格式见 Parse，须在 Start 之前调用
+func (s *Scheduler) Add(name, spec string, job Job, opts ...JobOption) error {
+	schedule, err := Parse(spec, s.o.loc)
+	if err != nil {
+		return err
+	}
+	return s.AddSchedule(name, schedule, job, opts...)
+}
+
+// AddSchedule 使用自定义的执行计划添加任务
+func (s *Scheduler) AddSchedule(name string, schedule Schedule, job Job, opts ...JobOption) error {
+	o := &jobOption{}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.cancel != nil {
+		return ErrStarted
+	}
+	if _, ok := s.names[name]; ok {
+		return fmt.Errorf("%w: %s", ErrDuplicateJob, name)
+	}
+	s.names[name] = struct{}{}
+	e := &entry{name: name, schedule: schedule, job: job, o: o}
+	e.idle = sync.NewCond(&e.mu)
+	s.entries = append(s.entries, e)
+	return nil
+}
+
+// Start 为每个任务启动调度协程
+func (s *Scheduler) Start(context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.cancel != nil {
+		return ErrStarted
+	}
+	var ctx context.Context
+	ctx, s.cancel = context.WithCancel(context.Background())
+	s.jobCtx, s.stopJob = context.WithCancel(context.Background())
+	for _, e := range s.entries {
+		s.wg.Go(func() { s.loop(ctx, e) })
+	}
+	return nil
+}
+
+// Stop 停止调度并等待执行中的任务结束，ctx 结束时取消任务的 ctx 并返回 ctx.Err()
+func (s *Scheduler) Stop(ctx context.Context) error {
+	s.mu.Lock()
+	cancel, stopJob := s.cancel, s.stopJob
+	s.mu.Unlock()
+	if cancel == nil {
+		return nil
+	}
+	cancel()
+
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		stopJob()
+		return nil
+	case <-ctx.Done():
+		stopJob()
+		return ctx.Err()
+	}
+}
+
+// loop 等待任务的下一个执行时间并触发执行
+func (s *Scheduler) loop(ctx context.Context, e *entry) {
+	next := e.schedule.Next(time.Now())
+	for !next.IsZero() {
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+		at := next
+		s.wg.Go(func() { s.fire(ctx, e, at) })
+		next = e.schedule.Next(at)
+	}
+	hlog.Warnf("[Cron] job %s has no next run time, stop scheduling", e.name)
+}
+
+// fire 处理一个执行时间点：等待 jitter、抢占执行时间点、按重叠策略执行，scheduling 结束后不再开始执行
+func (s *Scheduler) fire(scheduling context.Context, e *entry, at time.Time) {
+	if e.o.jitter > 0 {
+		select {
+		case <-time.After(rand.N(e.o.jitter)):
+		case <-scheduling.Done():
+			return
+		}
+	}
+
+	ctx := s.jobCtx
+	if s.client != nil {
+		key := s.o.keyPrefix + e.name + ":" + strconv.FormatInt(at.UnixMilli(), 10)
+		ok, err := s.client.SetNX(ctx, key, 1, s.o.lockTTL).Result()
+		if err != nil {
+			hlog.CtxWarnf(ctx, "[Cron] acquire %s failed: %v", key, err)
+			return
+		}
+		if !ok {
+			// 其他实例已执行该时间点
+			return
+		}
+	}
+
+	release, ok := s.acquire(ctx, e)
+	if !ok {
+		s.runs.WithLabelValues(e.name, "skipped").Inc()
+		hlog.CtxInfof(ctx, "[Cron] skip job %s at %s: previous run is still running", e.name, at.Format(time.RFC3339))
+		return
+	}
+	defer release()
+	s.run(ctx, e)
+}
+
+// acquire 按重叠策略获取执行权，返回释放函数
+func (s *Scheduler) acquire(ctx context.Context, e *entry) (func(), bool) {
+	if e.o.overlap == OverlapAllow {
+		return func() {}, true
+	}
+
+	// 本进程内的重叠控制
+	e.mu.Lock()
+	for e.running > 0 && e.o.overlap == OverlapWait {
+		e.idle.Wait()
+	}
+	if e.running > 0 {
+		e.mu.Unlock()
+		return nil, false
+	}
+	e.running++
+	e.mu.Unlock()
+	local := func() {
+		e.mu.Lock()
+		e.running--
+		e.idle.Broadcast()
+		e.mu.Unlock()
+	}
+	if s.client == nil {
+		return local, true
+	}
+
+	// 集群内的重叠控制，持有期间由看门狗续期
+	mu := lock.NewMutex(s.client, s.o.keyPrefix+e.name+":running", lock.WithTTL(s.o.lockTTL), lock.WithWatchdog())
+	var err error
+	if e.o.overlap == OverlapWait {
+		err = mu.Lock(ctx)
+	} else {
+		err = mu.TryLock(ctx)
+	}
+	if err != nil {
+		local()
+		if !errors.Is(err, lock.ErrNotObtained) {
+			hlog.CtxWarnf(ctx, "[Cron] acquire running lock of %s failed: %v", e.name, err)
+		}
+		return nil, false
+	}
+	return func() {
+		_ = mu.Unlock(context.WithoutCancel(ctx))
+		local()
+	}, true
+}
+
+// run 执行任务，记录耗时与结果，panic 时记录堆栈
+func (s *Scheduler) run(ctx context.Context, e *entry) {
+	if e.o.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, e.o.timeout)
+		defer cancel()
+	}
+
+	start := time.Now()
+	result := "ok"
+	defer func() {
+		if r := recover(); r != nil {
+			result = "panic"
+			hlog.CtxErrorf(ctx, "[Cron] job %s panic: %v\n%s", e.name, r, debug.Stack())
+		}
+		s.duration.WithLabelValues(e.name).Observe(time.Since(start).Seconds())
+		s.runs.WithLabelValues(e.name, result).Inc()
+		if result == "ok" {
+			s.success.WithLabelValues(e.name).SetToCurrentTime()
+		}
+	}()
+
+	if err := e.job(ctx); err != nil {
+		result = "error"
+		hlog.CtxErrorf(ctx, "[Cron] job %s failed after %v: %v", e.name, time.Since(start), err)
+		return
+	}
+	hlog.CtxDebugf(ctx, "[Cron] job %s finished in %v", e.name, time.Since(start))
+}
+
+// register 注册指标，已注册时复用已有的指标
+func register[C prometheus.Collector](reg prometheus.Registerer, c C) C {
+	err := reg.Register(c)
+	if err == nil {
+		return c
+	}
+	var are prometheus.AlreadyRegisteredError
+	if errors.As(err, &are) {
+		if existing, ok := are.ExistingCollector.(C); ok {
+			return existing
+		}
+	}
+	hlog.CtxWarnf(context.Background(), "[Cron] register metrics failed: %v", err)
+	return c
+}
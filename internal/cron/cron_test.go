@@ -0,0 +1,128 @@
+package cron
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	goredis "github.com/redis/go-redis/v9"
+
+	cacheredis "github.com/ZampoRen/go-server-comon/internal/infra/cache/impl/redis"
+)
+
+func run(t *testing.T, s *Scheduler, d time.Duration) {
+	t.Helper()
+	if err := s.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(d)
+	if err := s.Stop(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestClusterExclusive 测试多个实例共用 Redis 时每个执行时间点只执行一次
+func TestClusterExclusive(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := cacheredis.NewWithClientOptions(&goredis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = client.(interface{ Close() error }).Close() })
+
+	var runs atomic.Int64
+	job := func(context.Context) error {
+		runs.Add(1)
+		return nil
+	}
+	instances := make([]*Scheduler, 3)
+	for i := range instances {
+		instances[i] = New(client, WithRegisterer(prometheus.NewRegistry()))
+		if err := instances[i].AddSchedule("sync", every(50*time.Millisecond), job); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	start := time.Now()
+	for _, s := range instances {
+		if err := s.Start(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	time.Sleep(500 * time.Millisecond)
+	for _, s := range instances {
+		_ = s.Stop(context.Background())
+	}
+	ticks := int64(time.Since(start)/(50*time.Millisecond)) + 1
+	if n := runs.Load(); n < 3 || n > ticks {
+		t.Fatalf("runs = %d, want between 3 and %d", n, ticks)
+	}
+}
+
+// TestOverlapAndRecovery 测试跳过重叠执行与 panic 恢复
+func TestOverlapAndRecovery(t *testing.T) {
+	s := New(nil, WithRegisterer(prometheus.NewRegistry()))
+	var slow atomic.Int64
+	_ = s.AddSchedule("slow", every(20*time.Millisecond), func(ctx context.Context) error {
+		slow.Add(1)
+		time.Sleep(70 * time.Millisecond)
+		return nil
+	})
+	var panics atomic.Int64
+	_ = s.AddSchedule("panic", every(20*time.Millisecond), func(context.Context) error {
+		panics.Add(1)
+		panic("boom")
+	}, WithOverlap(OverlapAllow))
+	if err := s.Add("slow", "@hourly", nil); err == nil {
+		t.Fatal("Add() duplicate name error = nil")
+	}
+
+	run(t, s, 200*time.Millisecond)
+
+	if skipped := testutil.ToFloat64(s.runs.WithLabelValues("slow", "skipped")); skipped == 0 {
+		t.Errorf("slow job skipped = 0, want > 0")
+	}
+	if ok := testutil.ToFloat64(s.runs.WithLabelValues("slow", "ok")); ok != float64(slow.Load()) {
+		t.Errorf("slow job ok = %v, want %d", ok, slow.Load())
+	}
+	if n := testutil.ToFloat64(s.runs.WithLabelValues("panic", "panic")); n < 3 || n != float64(panics.Load()) {
+		t.Errorf("panic runs = %v, calls = %d", n, panics.Load())
+	}
+	if err := s.Start(context.Background()); err == nil {
+		t.Error("Start() after Stop error = nil")
+	}
+}
+
+// TestStopWaitsForRunningJobs 测试 Stop 等待执行中的任务，超时后取消任务的 ctx
+func TestStopWaitsForRunningJobs(t *testing.T) {
+	s := New(nil, WithRegisterer(prometheus.NewRegistry()))
+	started := make(chan struct{}, 1)
+	canceled := make(chan struct{})
+	var once sync.Once
+	_ = s.AddSchedule("long", every(10*time.Millisecond), func(ctx context.Context) error {
+		select {
+		case started <- struct{}{}:
+		default:
+		}
+		<-ctx.Done()
+		once.Do(func() { close(canceled) })
+		return ctx.Err()
+	})
+	if err := s.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Millisecond)
+	defer cancel()
+	if err := s.Stop(ctx); err != context.DeadlineExceeded {
+		t.Fatalf("Stop() error = %v, want DeadlineExceeded", err)
+	}
+	select {
+	case <-canceled:
+	case <-time.After(time.Second):
+		t.Fatal("job ctx was not canceled")
+	}
+}
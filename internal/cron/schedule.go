@@ -0,0 +1,234 @@
+package cron
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schedule 计算任务的下次执行时间
+type Schedule interface {
+	// Next 返回晚于 t 的下一个执行时间，没有时返回零值
+	Next(t time.Time) time.Time
+}
+
+// field 一个字段的取值范围与别名
+type field struct {
+	name     string
+	min, max uint
+	names    map[string]uint
+}
+
+var (
+	secondField = field{name: "second", min: 0, max: 59}
+	minuteField = field{name: "minute", min: 0, max: 59}
+	hourField   = field{name: "hour", min: 0, max: 23}
+	domField    = field{name: "day of month", min: 1, max: 31}
+	monthField  = field{name: "month", min: 1, max: 12, names: map[string]uint{
+		"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+		"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+	}}
+	// 星期的 7 与 0 都表示周日
+	dowField = field{name: "day of week", min: 0, max: 7, names: map[string]uint{
+		"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+	}}
+)
+
+var descriptors = map[string]string{
+	"@yearly":   "0 0 0 1 1 *",
+	"@annually": "0 0 0 1 1 *",
+	"@monthly":  "0 0 0 1 * *",
+	"@weekly":   "0 0 0 * * 0",
+	"@daily":    "0 0 0 * * *",
+	"@midnight": "0 0 0 * * *",
+	"@hourly":   "0 0 * * * *",
+}
+
+// Parse 解析 cron 表达式，时间按 loc 计算，loc 为 nil 时使用 time.Local
+// 支持：
+//   - 标准 5 段表达式（分 时 日 月 周）与带秒的 6 段表达式（秒 分 时 日 月 周）
+//   - 每段支持 *、?、列表 1,2、范围 1-5、步长 */10 与 1-30/5，月与周支持英文缩写（JAN、MON）
+//   - 预定义表达式 @yearly、@monthly、@weekly、@daily、@hourly 以及固定间隔 @every 30s
+//
+// 日与周同时受限时满足其一即可，与标准 cron 一致
+func Parse(spec string, loc *time.Location) (Schedule, error) {
+	if loc == nil {
+		loc = time.Local
+	}
+	spec = strings.TrimSpace(spec)
+	if rest, ok := strings.CutPrefix(spec, "@every "); ok {
+		d, err := time.ParseDuration(strings.TrimSpace(rest))
+		if err != nil || d <= 0 {
+			return nil, fmt.Errorf("cron: invalid interval %q", rest)
+		}
+		return every(d), nil
+	}
+	if expanded, ok := descriptors[strings.ToLower(spec)]; ok {
+		spec = expanded
+	}
+
+	fields := strings.Fields(spec)
+	switch len(fields) {
+	case 5:
+		fields = append([]string{"0"}, fields...)
+	case 6:
+	default:
+		return nil, fmt.Errorf("cron: expected 5 or 6 fields, got %d in %q", len(fields), spec)
+	}
+
+	s := &specSchedule{loc: loc}
+	var err error
+	for i, f := range []struct {
+		bits *uint64
+		def  field
+	}{
+		{&s.second, secondField},
+		{&s.minute, minuteField},
+		{&s.hour, hourField},
+		{&s.dom, domField},
+		{&s.month, monthField},
+		{&s.dow, dowField},
+	} {
+		if *f.bits, err = parseField(fields[i], f.def); err != nil {
+			return nil, err
+		}
+	}
+	// 周日统一用 0 表示
+	if s.dow&(1<<7) != 0 {
+		s.dow = s.dow&^(1<<7) | 1
+	}
+	s.domStar = isStar(fields[3])
+	s.dowStar = isStar(fields[5])
+	return s, nil
+}
+
+func isStar(expr string) bool {
+	return expr == "*" || expr == "?"
+}
+
+// parseField 将一段表达式解析为位图，第 n 位表示取值 n
+func parseField(expr string, f field) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(expr, ",") {
+		rangeExpr, stepExpr, hasStep := strings.Cut(part, "/")
+		step := uint(1)
+		if hasStep {
+			n, err := strconv.ParseUint(stepExpr, 10, 8)
+			if err != nil || n == 0 {
+				return 0, fmt.Errorf("cron: invalid step %q in %s", stepExpr, f.name)
+			}
+			step = uint(n)
+		}
+
+		var lo, hi uint
+		switch {
+		case isStar(rangeExpr):
+			lo, hi = f.min, f.max
+			if f.name == dowField.name {
+				hi = 6
+			}
+		default:
+			loExpr, hiExpr, isRange := strings.Cut(rangeExpr, "-")
+			var err error
+			if lo, err = f.value(loExpr); err != nil {
+				return 0, err
+			}
+			hi = lo
+			if isRange {
+				if hi, err = f.value(hiExpr); err != nil {
+					return 0, err
+				}
+			} else if hasStep {
+				// 5/15 表示从 5 开始每 15 个单位
+				hi = f.max
+			}
+			if lo > hi {
+				return 0, fmt.Errorf("cron: invalid range %q in %s", rangeExpr, f.name)
+			}
+		}
+		for v := lo; v <= hi; v += step {
+			bits |= 1 << v
+		}
+	}
+	return bits, nil
+}
+
+// value 解析单个取值，支持数字与英文缩写
+func (f field) value(s string) (uint, error) {
+	if v, ok := f.names[strings.ToLower(s)]; ok {
+		return v, nil
+	}
+	n, err := strconv.ParseUint(s, 10, 8)
+	if err != nil || uint(n) < f.min || uint(n) > f.max {
+		return 0, fmt.Errorf("cron: invalid value %q in %s, expected %d-%d", s, f.name, f.min, f.max)
+	}
+	return uint(n), nil
+}
+
+// specSchedule cron 表达式对应的执行计划，各字段为取值位图
+type specSchedule struct {
+	second, minute, hour, dom, month, dow uint64
+	domStar, dowStar                      bool
+	loc                                   *time.Location
+}
+
+// Next 从 t 的下一秒开始逐级查找满足条件的时间，最多向后查找 5 年
+func (s *specSchedule) Next(t time.Time) time.Time {
+	t = t.In(s.loc).Add(time.Second - time.Duration(t.Nanosecond())).Truncate(time.Second)
+	limit := t.Year() + 5
+
+wrap:
+	for t.Year() <= limit {
+		for s.month&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, s.loc)
+			if t.Month() == time.January {
+				continue wrap
+			}
+		}
+		for !s.dayMatches(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, s.loc)
+			if t.Day() == 1 {
+				continue wrap
+			}
+		}
+		for s.hour&(1<<uint(t.Hour())) == 0 {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, s.loc)
+			if t.Hour() == 0 {
+				continue wrap
+			}
+		}
+		for s.minute&(1<<uint(t.Minute())) == 0 {
+			t = t.Truncate(time.Minute).Add(time.Minute)
+			if t.Minute() == 0 {
+				continue wrap
+			}
+		}
+		for s.second&(1<<uint(t.Second())) == 0 {
+			t = t.Add(time.Second)
+			if t.Second() == 0 {
+				continue wrap
+			}
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+// dayMatches 日与周都受限时满足其一即可，只有一个受限时以受限的为准
+func (s *specSchedule) dayMatches(t time.Time) bool {
+	domMatch := s.dom&(1<<uint(t.Day())) != 0
+	dowMatch := s.dow&(1<<uint(t.Weekday())) != 0
+	if s.domStar || s.dowStar {
+		return domMatch && dowMatch
+	}
+	return domMatch || dowMatch
+}
+
+// every 固定间隔的执行计划，执行时间按间隔对齐
+type every time.Duration
+
+func (e every) Next(t time.Time) time.Time {
+	d := time.Duration(e)
+	return t.Truncate(d).Add(d)
+}
@@ -0,0 +1,49 @@
+package cron
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	base := time.Date(2026, 3, 14, 10, 7, 30, 0, time.UTC) // 周六
+	tests := []struct {
+		spec string
+		want []string
+	}{
+		{"*/5 * * * *", []string{"2026-03-14T10:10:00Z", "2026-03-14T10:15:00Z"}},
+		{"30 9 * * MON-FRI", []string{"2026-03-16T09:30:00Z", "2026-03-17T09:30:00Z"}},
+		{"0 0 1,15 * *", []string{"2026-03-15T00:00:00Z", "2026-04-01T00:00:00Z"}},
+		{"*/20 * * * * *", []string{"2026-03-14T10:07:40Z", "2026-03-14T10:08:00Z"}},
+		{"0 12 * * 7", []string{"2026-03-15T12:00:00Z", "2026-03-22T12:00:00Z"}},
+		{"0 0 29 2 *", []string{"2028-02-29T00:00:00Z", "2032-02-29T00:00:00Z"}},
+		// 日与周都受限时满足其一即可
+		{"0 0 13 * FRI", []string{"2026-03-20T00:00:00Z", "2026-03-27T00:00:00Z"}},
+		{"5/20 8 * * *", []string{"2026-03-15T08:05:00Z", "2026-03-15T08:25:00Z"}},
+		{"@hourly", []string{"2026-03-14T11:00:00Z", "2026-03-14T12:00:00Z"}},
+		{"@every 90s", []string{"2026-03-14T10:09:00Z", "2026-03-14T10:10:30Z"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			s, err := Parse(tt.spec, time.UTC)
+			if err != nil {
+				t.Fatal(err)
+			}
+			next := base
+			for _, want := range tt.want {
+				next = s.Next(next)
+				if got := next.Format(time.RFC3339); got != want {
+					t.Fatalf("Next() = %s, want %s", got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	for _, spec := range []string{"", "* * *", "60 * * * *", "* 24 * * *", "*/0 * * * *", "5-1 * * * *", "* * * FOO *", "@every -1s"} {
+		if _, err := Parse(spec, time.UTC); err == nil {
+			t.Errorf("Parse(%q) error = nil", spec)
+		}
+	}
+}
@@ -0,0 +1,128 @@
+// Package errno 定义服务间通用的 errorx 错误码及其 HTTP 状态码映射
+package errno
+
+import (
+	"net/http"
+	"sync"
+
+	"github.com/ZampoRen/go-server-comon/pkg/errorx/code"
+)
+
+// 通用错误码
+const (
+	// ErrInternal 服务内部错误
+	ErrInternal int32 = 100000
+	// ErrInvalidParam 参数错误
+	ErrInvalidParam int32 = 100001
+	// ErrUnauthenticated 未登录或登录凭证无效
+	ErrUnauthenticated int32 = 100002
+	// ErrDeadlineExceeded 请求处理超时
+	ErrDeadlineExceeded int32 = 100003
+	// ErrNotFound 资源不存在
+	ErrNotFound int32 = 100004
+	// ErrPermissionDenied 无权访问
+	ErrPermissionDenied int32 = 100005
+	// ErrTooManyRequests 请求过多
+	ErrTooManyRequests int32 = 100006
+	// ErrConflict 资源已存在或状态冲突
+	ErrConflict int32 = 100007
+	// ErrUnavailable 服务暂不可用
+	ErrUnavailable int32 = 100008
+	// ErrValidation 参数校验失败，消息包含各字段的校验错误，见 internal/validate
+	ErrValidation int32 = 100009
+	// ErrIdempotencyInProgress 相同幂等键的请求正在处理，见 internal/idempotency
+	ErrIdempotencyInProgress int32 = 100010
+	// ErrIdempotencyKeyReused 幂等键已被参数不同的请求使用
+	ErrIdempotencyKeyReused int32 = 100011
+)
+
+// 基础设施错误码
+const (
+	// ErrDBUnavailable 数据库连接不可用
+	ErrDBUnavailable int32 = 100100
+	// ErrDBTimeout 数据库操作超时
+	ErrDBTimeout int32 = 100101
+	// ErrVersionConflict 乐观锁版本冲突，数据已被其他请求修改
+	ErrVersionConflict int32 = 100102
+	// ErrObjectNotFound 对象存储中对象不存在
+	ErrObjectNotFound int32 = 100200
+	// ErrStorageAccessDenied 对象存储拒绝访问
+	ErrStorageAccessDenied int32 = 100201
+	// ErrStorageThrottled 对象存储请求被限流
+	ErrStorageThrottled int32 = 100202
+)
+
+// 配置错误码 1003xx 由 pkg/envkey 与 internal/config 注册，见 envkey.ErrCodeInvalidEnv、config.ErrCodeInvalidConfig
+
+var (
+	mu           sync.RWMutex
+	httpStatuses = make(map[int32]int)
+)
+
+func init() {
+	code.SetDefaultErrorCode(ErrInternal)
+
+	Register(ErrInternal, "服务内部错误", http.StatusInternalServerError)
+	Register(ErrInvalidParam, "参数错误", http.StatusBadRequest, code.WithAffectStability(false))
+	Register(ErrUnauthenticated, "未登录或登录已过期", http.StatusUnauthorized, code.WithAffectStability(false))
+	Register(ErrDeadlineExceeded, "请求超时，请稍后重试", http.StatusGatewayTimeout, code.WithRetryable(true))
+	Register(ErrNotFound, "资源不存在", http.StatusNotFound, code.WithAffectStability(false))
+	Register(ErrPermissionDenied, "无权访问", http.StatusForbidden, code.WithAffectStability(false))
+	Register(ErrTooManyRequests, "请求过多，请稍后重试", http.StatusTooManyRequests, code.WithAffectStability(false), code.WithRetryable(true))
+	Register(ErrConflict, "资源已存在或已被修改", http.StatusConflict, code.WithAffectStability(false))
+	Register(ErrUnavailable, "服务暂不可用，请稍后重试", http.StatusServiceUnavailable, code.WithRetryable(true))
+	Register(ErrValidation, "参数校验失败: {details}", http.StatusBadRequest, code.WithAffectStability(false))
+	Register(ErrIdempotencyInProgress, "请求正在处理，请稍后重试", http.StatusConflict, code.WithAffectStability(false), code.WithRetryable(true))
+	Register(ErrIdempotencyKeyReused, "幂等键已被其他请求使用", http.StatusUnprocessableEntity, code.WithAffectStability(false))
+	Register(ErrDBUnavailable, "数据库暂不可用", http.StatusServiceUnavailable, code.WithRetryable(true))
+	Register(ErrDBTimeout, "数据库操作超时", http.StatusGatewayTimeout, code.WithRetryable(true))
+	Register(ErrVersionConflict, "数据已被修改，请刷新后重试", http.StatusConflict, code.WithAffectStability(false))
+	Register(ErrObjectNotFound, "文件不存在", http.StatusNotFound, code.WithAffectStability(false))
+	Register(ErrStorageAccessDenied, "存储访问被拒绝", http.StatusForbidden)
+	Register(ErrStorageThrottled, "存储服务繁忙，请稍后重试", http.StatusTooManyRequests, code.WithRetryable(true))
+}
+
+// Register 注册错误码及其对应的 HTTP 状态码
+func Register(c int32, msg string, httpStatus int, opts ...code.RegisterOptionFn) {
+	code.Register(c, msg, opts...)
+
+	mu.Lock()
+	httpStatuses[c] = httpStatus
+	mu.Unlock()
+}
+
+// HTTPStatus 返回错误码对应的 HTTP 状态码，未注册的错误码返回 500
+func HTTPStatus(c int32) int {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	if s, ok := httpStatuses[c]; ok {
+		return s
+	}
+	return http.StatusInternalServerError
+}
+
+// CodeInfo 错误码信息，用于导出错误码列表
+type CodeInfo struct {
+	Code            int32  `json:"code"`
+	Message         string `json:"message"`
+	HTTPStatus      int    `json:"http_status"`
+	AffectStability bool   `json:"affect_stability"`
+	Retryable       bool   `json:"retryable"`
+}
+
+// Codes 返回所有已注册的错误码（包括其它包通过 code.Register 注册的），按错误码升序排列
+func Codes() []CodeInfo {
+	defs := code.Definitions()
+	infos := make([]CodeInfo, 0, len(defs))
+	for _, def := range defs {
+		infos = append(infos, CodeInfo{
+			Code:            def.Code,
+			Message:         def.Message,
+			HTTPStatus:      HTTPStatus(def.Code),
+			AffectStability: def.IsAffectStability,
+			Retryable:       def.IsRetryable,
+		})
+	}
+	return infos
+}
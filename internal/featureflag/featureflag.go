@@ -0,0 +1,169 @@
+// Package featureflag 运行时功能开关，支持布尔开关、按用户比例灰度与用户白名单，开关变化无需重启服务
+//
+//	ctx = featureflag.WithUserID(ctx, uid)
+//	if featureflag.Enabled(ctx, "new_ranking") {
+//		return newRanking(ctx)
+//	}
+//
+// 开关可以来自配置文件的 featureFlags（config.Config.FeatureFlags，配置中心变更时调用 Update），
+// 也可以存储在 Redis 中（RedisStore），修改后通过发布订阅通知各服务的所有实例重新加载。
+// 同一用户在各服务、各实例上的灰度结果一致，比例调大时已开启的用户保持开启
+package featureflag
+
+import (
+	"context"
+	"hash/fnv"
+	"maps"
+	"slices"
+	"strconv"
+	"sync/atomic"
+)
+
+// Flag 功能开关
+type Flag struct {
+	// Enabled 总开关，为 false 时对所有用户关闭
+	Enabled bool `json:"enabled" yaml:"enabled" toml:"enabled"`
+	// Percentage 开启的用户比例，0 到 100，按开关名与用户 ID 的哈希分桶，0 表示只对白名单开启；
+	// 未设置且 Users 为空时对所有用户开启，即普通的布尔开关
+	Percentage *int `json:"percentage,omitempty" yaml:"percentage" toml:"percentage"`
+	// Users 白名单，名单中的用户始终开启
+	Users []string `json:"users,omitempty" yaml:"users" toml:"users"`
+}
+
+// EnabledFor 判断开关对 user 是否开启，user 为空时只有全量开启的开关返回 true
+func (f Flag) EnabledFor(name, user string) bool {
+	if !f.Enabled {
+		return false
+	}
+	if f.Percentage == nil && len(f.Users) == 0 || f.Percentage != nil && *f.Percentage >= 100 {
+		return true
+	}
+	if user == "" {
+		return false
+	}
+	if slices.Contains(f.Users, user) {
+		return true
+	}
+	return f.Percentage != nil && bucket(name, user) < *f.Percentage
+}
+
+// Percent 返回指向 n 的指针，用于在代码中设置 Flag.Percentage
+func Percent(n int) *int {
+	return &n
+}
+
+// bucket 将用户稳定地映射到 [0, 100)，加入开关名使不同开关灰度到不同的用户
+func bucket(name, user string) int {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(name))
+	_, _ = h.Write([]byte{':'})
+	_, _ = h.Write([]byte(user))
+	return int(h.Sum32() % 100)
+}
+
+// Option 开关集合选项
+type Option func(o *option)
+
+type option struct {
+	userFn func(ctx context.Context) string
+}
+
+// WithUserFunc 设置从 ctx 获取用户 ID 的函数，默认 UserFromContext，
+// 如从认证拦截器写入的 JWT 声明中获取
+func WithUserFunc(fn func(ctx context.Context) string) Option {
+	return func(o *option) {
+		if fn != nil {
+			o.userFn = fn
+		}
+	}
+}
+
+// Flags 开关集合，并发安全，Update 整体替换全部开关
+type Flags struct {
+	o     *option
+	flags atomic.Pointer[map[string]Flag]
+}
+
+// New 创建空的开关集合，未定义的开关视为关闭
+func New(opts ...Option) *Flags {
+	o := &option{userFn: UserFromContext}
+	for _, opt := range opts {
+		opt(o)
+	}
+	f := &Flags{o: o}
+	f.Update(nil)
+	return f
+}
+
+// Update 以 flags 替换全部开关
+func (f *Flags) Update(flags map[string]Flag) {
+	m := maps.Clone(flags)
+	if m == nil {
+		m = make(map[string]Flag)
+	}
+	f.flags.Store(&m)
+}
+
+// Get 返回开关的定义
+func (f *Flags) Get(name string) (Flag, bool) {
+	flag, ok := (*f.flags.Load())[name]
+	return flag, ok
+}
+
+// All 返回全部开关的副本
+func (f *Flags) All() map[string]Flag {
+	return maps.Clone(*f.flags.Load())
+}
+
+// Enabled 判断开关对 ctx 中的用户是否开启，未定义的开关返回 false
+func (f *Flags) Enabled(ctx context.Context, name string) bool {
+	flag, ok := f.Get(name)
+	if !ok {
+		return false
+	}
+	return flag.EnabledFor(name, f.o.userFn(ctx))
+}
+
+var defaultFlags atomic.Pointer[Flags]
+
+func init() {
+	defaultFlags.Store(New())
+}
+
+// Default 返回默认的开关集合
+func Default() *Flags {
+	return defaultFlags.Load()
+}
+
+// SetDefault 替换默认的开关集合，如设置了 WithUserFunc 的集合，应在初始化时调用
+func SetDefault(f *Flags) {
+	defaultFlags.Store(f)
+}
+
+// Enabled 使用默认的开关集合判断开关是否开启
+func Enabled(ctx context.Context, name string) bool {
+	return Default().Enabled(ctx, name)
+}
+
+// Update 替换默认开关集合中的全部开关
+func Update(flags map[string]Flag) {
+	Default().Update(flags)
+}
+
+type userKey struct{}
+
+// WithUser 在 ctx 中记录用于灰度与白名单判断的用户 ID
+func WithUser(ctx context.Context, user string) context.Context {
+	return context.WithValue(ctx, userKey{}, user)
+}
+
+// WithUserID 同 WithUser，用户 ID 为整数
+func WithUserID(ctx context.Context, id int64) context.Context {
+	return WithUser(ctx, strconv.FormatInt(id, 10))
+}
+
+// UserFromContext 返回 WithUser 记录的用户 ID
+func UserFromContext(ctx context.Context) string {
+	user, _ := ctx.Value(userKey{}).(string)
+	return user
+}
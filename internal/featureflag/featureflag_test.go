@@ -0,0 +1,139 @@
+package featureflag
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/ZampoRen/go-server-comon/internal/infra/cache/impl/memory"
+)
+
+func TestFlagEnabledFor(t *testing.T) {
+	tests := []struct {
+		name string
+		flag Flag
+		user string
+		want bool
+	}{
+		{"关闭", Flag{}, "1", false},
+		{"布尔开关", Flag{Enabled: true}, "", true},
+		{"全量", Flag{Enabled: true, Percentage: Percent(100)}, "", true},
+		{"比例为 0 时关闭", Flag{Enabled: true, Percentage: Percent(0)}, "1", false},
+		{"比例为 0 时白名单开启", Flag{Enabled: true, Percentage: Percent(0), Users: []string{"1"}}, "1", true},
+		{"白名单命中", Flag{Enabled: true, Users: []string{"1", "2"}}, "2", true},
+		{"白名单未命中", Flag{Enabled: true, Users: []string{"1", "2"}}, "3", false},
+		{"总开关关闭时白名单无效", Flag{Users: []string{"1"}}, "1", false},
+		{"灰度时匿名用户关闭", Flag{Enabled: true, Percentage: Percent(50)}, "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.flag.EnabledFor("new_ranking", tt.user); got != tt.want {
+				t.Errorf("EnabledFor() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFlagPercentage(t *testing.T) {
+	const users = 10000
+	enabled := func(percentage int) map[string]bool {
+		m := make(map[string]bool)
+		flag := Flag{Enabled: true, Percentage: Percent(percentage)}
+		for i := range users {
+			user := strconv.Itoa(i)
+			if flag.EnabledFor("new_ranking", user) {
+				m[user] = true
+			}
+		}
+		return m
+	}
+
+	ten, thirty := enabled(10), enabled(30)
+	if n := len(ten); n < users*8/100 || n > users*12/100 {
+		t.Errorf("10%% rollout enabled %d of %d users", n, users)
+	}
+	// 比例调大时已开启的用户保持开启
+	for user := range ten {
+		if !thirty[user] {
+			t.Fatalf("user %s enabled at 10%% but not at 30%%", user)
+		}
+	}
+}
+
+func TestFlags(t *testing.T) {
+	f := New()
+	f.Update(map[string]Flag{
+		"new_ranking": {Enabled: true, Users: []string{"42"}},
+		"dark_mode":   {Enabled: true},
+	})
+
+	ctx := context.Background()
+	if f.Enabled(ctx, "new_ranking") {
+		t.Error("new_ranking enabled without user")
+	}
+	if !f.Enabled(WithUserID(ctx, 42), "new_ranking") {
+		t.Error("new_ranking disabled for allowlisted user")
+	}
+	if !f.Enabled(ctx, "dark_mode") {
+		t.Error("dark_mode disabled")
+	}
+	if f.Enabled(ctx, "undefined") {
+		t.Error("undefined flag enabled")
+	}
+
+	t.Run("WithUserFunc", func(t *testing.T) {
+		f := New(WithUserFunc(func(context.Context) string { return "42" }))
+		f.Update(map[string]Flag{"new_ranking": {Enabled: true, Users: []string{"42"}}})
+		if !f.Enabled(ctx, "new_ranking") {
+			t.Error("new_ranking disabled for user from WithUserFunc")
+		}
+	})
+}
+
+func TestRedisStore(t *testing.T) {
+	ctx := context.Background()
+	rdb := memory.New()
+
+	// 两个实例共享同一个 hash，a 的修改通过发布订阅同步到 b
+	a := NewRedisStore(rdb, WithFlags(New()))
+	bFlags := New()
+	b := NewRedisStore(rdb, WithFlags(bFlags), WithReloadInterval(time.Hour))
+	if err := a.Set(ctx, "dark_mode", Flag{Enabled: true}); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+	defer b.Stop(ctx)
+	if !bFlags.Enabled(ctx, "dark_mode") {
+		t.Fatal("dark_mode not loaded on start")
+	}
+
+	waitFor := func(name string, want bool) {
+		t.Helper()
+		deadline := time.Now().Add(time.Second)
+		for bFlags.Enabled(ctx, name) != want {
+			if time.Now().After(deadline) {
+				t.Fatalf("Enabled(%s) = %v, want %v", name, !want, want)
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+	}
+	if err := a.Set(ctx, "new_ranking", Flag{Enabled: true}); err != nil {
+		t.Fatal(err)
+	}
+	waitFor("new_ranking", true)
+	if err := a.Delete(ctx, "dark_mode"); err != nil {
+		t.Fatal(err)
+	}
+	waitFor("dark_mode", false)
+
+	flags, err := a.Load(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(flags) != 1 || !flags["new_ranking"].Enabled {
+		t.Errorf("Load() = %v", flags)
+	}
+}
@@ -0,0 +1,204 @@
+package featureflag
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/common/hlog"
+
+	"github.com/ZampoRen/go-server-comon/internal/infra/cache"
+)
+
+// DefaultRedisKey 存储开关的 Redis hash，字段为开关名，值为 Flag 的 JSON
+const DefaultRedisKey = "featureflag"
+
+// RedisOption Redis 存储选项
+type RedisOption func(o *redisOption)
+
+type redisOption struct {
+	key      string
+	flags    *Flags
+	interval time.Duration
+}
+
+// WithKey 设置存储开关的 hash 键，各服务使用相同的键即可共享开关，默认 featureflag
+func WithKey(key string) RedisOption {
+	return func(o *redisOption) {
+		if key != "" {
+			o.key = key
+		}
+	}
+}
+
+// WithFlags 设置同步的开关集合，默认 Default()
+func WithFlags(f *Flags) RedisOption {
+	return func(o *redisOption) {
+		if f != nil {
+			o.flags = f
+		}
+	}
+}
+
+// WithReloadInterval 设置定时重新加载的间隔，兜底发布订阅丢失的通知，默认 30s
+func WithReloadInterval(d time.Duration) RedisOption {
+	return func(o *redisOption) {
+		if d > 0 {
+			o.interval = d
+		}
+	}
+}
+
+// RedisStore 将开关存储在 Redis hash 中并同步到 Flags。Set、Delete 修改后发布通知，
+// 订阅到通知或到达重新加载间隔时重新加载全部开关，客户端不支持发布订阅时只定时加载。
+// Start、Stop 可直接作为 lifecycle.Hook 的 OnStart、OnStop
+type RedisStore struct {
+	rdb cache.Cmdable
+	o   *redisOption
+
+	cancel context.CancelFunc
+	done   chan struct{}
+	sub    cache.Subscription
+	mu     sync.Mutex // 串行化 reload，避免旧的结果覆盖新的结果
+}
+
+// NewRedisStore 创建 Redis 存储
+func NewRedisStore(rdb cache.Cmdable, opts ...RedisOption) *RedisStore {
+	o := &redisOption{key: DefaultRedisKey, flags: Default(), interval: 30 * time.Second}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return &RedisStore{rdb: rdb, o: o}
+}
+
+func (s *RedisStore) channel() string {
+	return s.o.key + ":changed"
+}
+
+// Load 读取全部开关，无法解析的开关打印告警后跳过
+func (s *RedisStore) Load(ctx context.Context) (map[string]Flag, error) {
+	values, err := s.rdb.HGetAll(ctx, s.o.key).Result()
+	if err != nil {
+		return nil, fmt.Errorf("load feature flags from %s failed: %w", s.o.key, err)
+	}
+	flags := make(map[string]Flag, len(values))
+	for name, value := range values {
+		var flag Flag
+		if err := json.Unmarshal([]byte(value), &flag); err != nil {
+			hlog.CtxWarnf(ctx, "[FeatureFlag] invalid flag %s: %v", name, err)
+			continue
+		}
+		flags[name] = flag
+	}
+	return flags, nil
+}
+
+// Set 保存开关并通知各实例重新加载
+func (s *RedisStore) Set(ctx context.Context, name string, flag Flag) error {
+	value, err := json.Marshal(flag)
+	if err != nil {
+		return err
+	}
+	if err := s.rdb.HSet(ctx, s.o.key, name, value).Err(); err != nil {
+		return fmt.Errorf("save feature flag %s failed: %w", name, err)
+	}
+	s.publish(ctx, name)
+	return nil
+}
+
+// Delete 删除开关并通知各实例重新加载
+func (s *RedisStore) Delete(ctx context.Context, name string) error {
+	if err := s.rdb.HDel(ctx, s.o.key, name).Err(); err != nil {
+		return fmt.Errorf("delete feature flag %s failed: %w", name, err)
+	}
+	s.publish(ctx, name)
+	return nil
+}
+
+// publish 通知失败只打印日志，各实例在重新加载间隔内仍会读取到新的开关
+func (s *RedisStore) publish(ctx context.Context, name string) {
+	ps, ok := s.rdb.(cache.PubSub)
+	if !ok {
+		return
+	}
+	if _, err := ps.Publish(ctx, s.channel(), name); err != nil {
+		hlog.CtxWarnf(ctx, "[FeatureFlag] publish change of %s failed: %v", name, err)
+	}
+}
+
+// Start 加载全部开关，之后在后台订阅变更通知并定时重新加载，首次加载失败时返回错误
+func (s *RedisStore) Start(ctx context.Context) error {
+	if ps, ok := s.rdb.(cache.PubSub); ok {
+		sub, err := ps.Subscribe(ctx, s.channel())
+		if err != nil {
+			return fmt.Errorf("subscribe %s failed: %w", s.channel(), err)
+		}
+		s.sub = sub
+	}
+	// 先订阅再加载，加载期间的修改不会丢失
+	if err := s.reload(ctx); err != nil {
+		if s.sub != nil {
+			_ = s.sub.Close()
+		}
+		return err
+	}
+
+	loopCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	s.cancel = cancel
+	s.done = make(chan struct{})
+	go s.loop(loopCtx)
+	return nil
+}
+
+// Stop 停止同步，已加载的开关保持不变
+func (s *RedisStore) Stop(context.Context) error {
+	if s.cancel == nil {
+		return nil
+	}
+	s.cancel()
+	var err error
+	if s.sub != nil {
+		err = s.sub.Close()
+	}
+	<-s.done
+	return err
+}
+
+func (s *RedisStore) loop(ctx context.Context) {
+	defer close(s.done)
+	ticker := time.NewTicker(s.o.interval)
+	defer ticker.Stop()
+
+	var changes <-chan *cache.Message
+	if s.sub != nil {
+		changes = s.sub.Channel()
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case _, ok := <-changes:
+			if !ok {
+				changes = nil
+				continue
+			}
+		case <-ticker.C:
+		}
+		if err := s.reload(ctx); err != nil && ctx.Err() == nil {
+			hlog.CtxWarnf(ctx, "[FeatureFlag] reload failed, keep current flags: %v", err)
+		}
+	}
+}
+
+func (s *RedisStore) reload(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	flags, err := s.Load(ctx)
+	if err != nil {
+		return err
+	}
+	s.o.flags.Update(flags)
+	return nil
+}
@@ -0,0 +1,204 @@
+// Package idempotency 基于 Redis 的请求幂等。客户端为可能重试的写请求（如支付、下单）携带 Idempotency-Key，
+// 服务端记录首次请求的指纹与响应，之后相同幂等键的请求直接返回记录的响应，不再执行业务逻辑
+//
+//	store := idempotency.New(rdb)
+//	h.POST("/charges", httpmw.Idempotency(store), handler.CreateCharge)
+//	grpc.ChainUnaryInterceptor(..., middleware.UnaryServerIdempotencyInterceptor(store, pb.Payment_Charge_FullMethodName))
+//
+// 幂等键在作用域（路由或 gRPC 方法，以及调用方）内唯一，不同接口、不同用户的幂等键互不影响。
+// 相同幂等键的请求正在处理时返回 errno.ErrIdempotencyInProgress（HTTP 409），客户端应稍后重试；
+// 幂等键已被参数不同的请求使用时返回 errno.ErrIdempotencyKeyReused（HTTP 422）。
+// 处理失败（返回错误、服务端错误或 panic）时不记录响应并释放幂等键，客户端可以使用同一个键重试
+package idempotency
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/common/hlog"
+
+	"github.com/ZampoRen/go-server-comon/internal/errno"
+	"github.com/ZampoRen/go-server-comon/internal/infra/cache"
+	"github.com/ZampoRen/go-server-comon/pkg/errorx"
+)
+
+const (
+	// Header 携带幂等键的请求头，gRPC 中为同名（小写）的元数据
+	Header = "Idempotency-Key"
+	// ReplayedHeader 重放记录的响应时设置的响应头，值为 true
+	ReplayedHeader = "Idempotent-Replayed"
+	// MaxKeyLength 幂等键的最大长度
+	MaxKeyLength = 255
+)
+
+// releaseScript 仅当仍为本次请求的处理中记录时删除，避免删除超时后其他请求写入的记录
+const releaseScript = `if redis.call("GET", KEYS[1]) == ARGV[1] then return redis.call("DEL", KEYS[1]) else return 0 end`
+
+// Option 存储选项
+type Option func(o *option)
+
+type option struct {
+	prefix  string
+	ttl     time.Duration
+	lockTTL time.Duration
+}
+
+// WithKeyPrefix 设置 Redis 键前缀，默认 idempotency:
+func WithKeyPrefix(prefix string) Option {
+	return func(o *option) {
+		o.prefix = prefix
+	}
+}
+
+// WithTTL 设置响应的保留时长，超过后相同幂等键的请求会重新执行，默认 24h
+func WithTTL(d time.Duration) Option {
+	return func(o *option) {
+		if d > 0 {
+			o.ttl = d
+		}
+	}
+}
+
+// WithLockTTL 设置请求处理的最长时间，进程在处理中退出时幂等键在此之后释放，应大于接口的超时时间，默认 1m
+func WithLockTTL(d time.Duration) Option {
+	return func(o *option) {
+		if d > 0 {
+			o.lockTTL = d
+		}
+	}
+}
+
+// Response 记录的响应
+type Response struct {
+	// Status HTTP 状态码，gRPC 为 0
+	Status int `json:"status,omitempty"`
+	// Header 重放时需要设置的响应头，如 Content-Type
+	Header map[string]string `json:"header,omitempty"`
+	// Body 响应体
+	Body []byte `json:"body,omitempty"`
+}
+
+// record 存储在 Redis 中的记录，Response 为 nil 表示处理中
+type record struct {
+	Fingerprint string    `json:"fingerprint"`
+	Token       string    `json:"token,omitempty"`
+	Response    *Response `json:"response,omitempty"`
+}
+
+// Store 幂等记录存储
+type Store struct {
+	rdb cache.Cmdable
+	o   *option
+}
+
+// New 创建幂等记录存储
+func New(rdb cache.Cmdable, opts ...Option) *Store {
+	o := &option{prefix: "idempotency:", ttl: 24 * time.Hour, lockTTL: time.Minute}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return &Store{rdb: rdb, o: o}
+}
+
+// Do 保证作用域 scope 内幂等键 key 对应的 fn 只成功执行一次：首次请求执行 fn 并记录其响应，
+// 之后指纹相同的请求返回记录的响应且 replayed 为 true，指纹不同时返回 errno.ErrIdempotencyKeyReused。
+// fn 返回错误或 nil 响应时不记录并释放幂等键，Do 原样返回 fn 的结果
+func (s *Store) Do(ctx context.Context, scope, key, fingerprint string, fn func(ctx context.Context) (*Response, error)) (resp *Response, replayed bool, err error) {
+	if key == "" || len(key) > MaxKeyLength {
+		return nil, false, errorx.WrapByCode(fmt.Errorf("%s must be 1 to %d characters", Header, MaxKeyLength), errno.ErrInvalidParam)
+	}
+	rkey := s.o.prefix + scope + ":" + key
+
+	token, err := randomToken()
+	if err != nil {
+		return nil, false, err
+	}
+	processing, err := json.Marshal(record{Fingerprint: fingerprint, Token: token})
+	if err != nil {
+		return nil, false, err
+	}
+	acquired, err := s.rdb.SetNX(ctx, rkey, processing, s.o.lockTTL).Result()
+	if err != nil {
+		return nil, false, errorx.WrapByCode(fmt.Errorf("acquire idempotency key failed: %w", err), errno.ErrUnavailable)
+	}
+	if !acquired {
+		resp, err := s.load(ctx, rkey, fingerprint)
+		return resp, err == nil, err
+	}
+
+	// fn 失败或 panic 时释放幂等键，请求的 ctx 可能已取消，使用不随之取消的 ctx
+	completed := false
+	defer func() {
+		if !completed {
+			s.release(context.WithoutCancel(ctx), rkey, string(processing))
+		}
+	}()
+	resp, err = fn(ctx)
+	if err != nil || resp == nil {
+		return resp, false, err
+	}
+
+	done, err := json.Marshal(record{Fingerprint: fingerprint, Response: resp})
+	if err != nil {
+		return resp, false, nil
+	}
+	if err := s.rdb.Set(context.WithoutCancel(ctx), rkey, done, s.o.ttl).Err(); err != nil {
+		hlog.CtxWarnf(ctx, "[Idempotency] save response of %s failed, key released: %v", rkey, err)
+		return resp, false, nil
+	}
+	completed = true
+	return resp, false, nil
+}
+
+// load 读取已有的记录
+func (s *Store) load(ctx context.Context, rkey, fingerprint string) (*Response, error) {
+	value, err := s.rdb.Get(ctx, rkey).Result()
+	if errors.Is(err, cache.Nil) {
+		// 记录在 SETNX 与 GET 之间过期或被释放，由客户端重试
+		return nil, errorx.New(errno.ErrIdempotencyInProgress)
+	}
+	if err != nil {
+		return nil, errorx.WrapByCode(fmt.Errorf("load idempotency key failed: %w", err), errno.ErrUnavailable)
+	}
+	var rec record
+	if err := json.Unmarshal([]byte(value), &rec); err != nil {
+		return nil, fmt.Errorf("parse idempotency record %s failed: %w", rkey, err)
+	}
+	if rec.Fingerprint != fingerprint {
+		return nil, errorx.New(errno.ErrIdempotencyKeyReused)
+	}
+	if rec.Response == nil {
+		return nil, errorx.New(errno.ErrIdempotencyInProgress)
+	}
+	return rec.Response, nil
+}
+
+func (s *Store) release(ctx context.Context, rkey, processing string) {
+	if err := s.rdb.Eval(ctx, releaseScript, []string{rkey}, processing).Err(); err != nil && !errors.Is(err, cache.Nil) {
+		hlog.CtxWarnf(ctx, "[Idempotency] release %s failed: %v", rkey, err)
+	}
+}
+
+// Fingerprint 返回请求内容的指纹，相同幂等键的请求指纹不同时视为误用
+func Fingerprint(parts ...[]byte) string {
+	h := sha256.New()
+	for _, p := range parts {
+		_, _ = fmt.Fprintf(h, "%d:", len(p))
+		_, _ = h.Write(p)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func randomToken() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
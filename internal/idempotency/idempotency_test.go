@@ -0,0 +1,142 @@
+package idempotency
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	goredis "github.com/redis/go-redis/v9"
+
+	"github.com/ZampoRen/go-server-comon/internal/errno"
+	cacheredis "github.com/ZampoRen/go-server-comon/internal/infra/cache/impl/redis"
+	"github.com/ZampoRen/go-server-comon/pkg/errorx"
+)
+
+func newTestStore(t *testing.T, opts ...Option) (*Store, *miniredis.Miniredis) {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	client := cacheredis.NewWithClientOptions(&goredis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = client.(interface{ Close() error }).Close() })
+	return New(client, opts...), mr
+}
+
+func errCode(err error) int32 {
+	var se errorx.StatusError
+	if errors.As(err, &se) {
+		return se.Code()
+	}
+	return 0
+}
+
+func TestDo(t *testing.T) {
+	store, mr := newTestStore(t, WithTTL(time.Hour))
+	ctx := context.Background()
+	fp := Fingerprint([]byte(`{"amount":100}`))
+
+	var calls int
+	charge := func(context.Context) (*Response, error) {
+		calls++
+		return &Response{Status: 201, Body: []byte(`{"id":1}`)}, nil
+	}
+
+	resp, replayed, err := store.Do(ctx, "charges", "key-1", fp, charge)
+	if err != nil || replayed || resp.Status != 201 {
+		t.Fatalf("Do() = %+v, %v, %v", resp, replayed, err)
+	}
+
+	t.Run("重复请求返回记录的响应", func(t *testing.T) {
+		resp, replayed, err := store.Do(ctx, "charges", "key-1", fp, charge)
+		if err != nil || !replayed || resp.Status != 201 || string(resp.Body) != `{"id":1}` {
+			t.Fatalf("Do() = %+v, %v, %v", resp, replayed, err)
+		}
+		if calls != 1 {
+			t.Errorf("calls = %d, want 1", calls)
+		}
+		if ttl := mr.TTL("idempotency:charges:key-1"); ttl != time.Hour {
+			t.Errorf("ttl = %v, want 1h", ttl)
+		}
+	})
+
+	t.Run("参数不同时拒绝", func(t *testing.T) {
+		_, _, err := store.Do(ctx, "charges", "key-1", Fingerprint([]byte(`{"amount":200}`)), charge)
+		if errCode(err) != errno.ErrIdempotencyKeyReused {
+			t.Fatalf("err = %v, want ErrIdempotencyKeyReused", err)
+		}
+	})
+
+	t.Run("作用域隔离", func(t *testing.T) {
+		if _, replayed, err := store.Do(ctx, "refunds", "key-1", fp, charge); err != nil || replayed {
+			t.Fatalf("Do() replayed = %v, err = %v", replayed, err)
+		}
+		if calls != 2 {
+			t.Errorf("calls = %d, want 2", calls)
+		}
+	})
+
+	t.Run("幂等键不合法", func(t *testing.T) {
+		if _, _, err := store.Do(ctx, "charges", "", fp, charge); errCode(err) != errno.ErrInvalidParam {
+			t.Fatalf("err = %v, want ErrInvalidParam", err)
+		}
+	})
+}
+
+func TestDoInProgress(t *testing.T) {
+	store, _ := newTestStore(t)
+	ctx := context.Background()
+	fp := Fingerprint([]byte("req"))
+
+	started, release := make(chan struct{}), make(chan struct{})
+	go func() {
+		_, _, _ = store.Do(ctx, "charges", "key-1", fp, func(context.Context) (*Response, error) {
+			close(started)
+			<-release
+			return &Response{Status: 200}, nil
+		})
+	}()
+	<-started
+	defer close(release)
+
+	_, _, err := store.Do(ctx, "charges", "key-1", fp, func(context.Context) (*Response, error) {
+		t.Fatal("fn called while the first request is in progress")
+		return nil, nil
+	})
+	if errCode(err) != errno.ErrIdempotencyInProgress {
+		t.Fatalf("err = %v, want ErrIdempotencyInProgress", err)
+	}
+}
+
+func TestDoFailureReleasesKey(t *testing.T) {
+	store, mr := newTestStore(t)
+	ctx := context.Background()
+	fp := Fingerprint([]byte("req"))
+
+	errFailed := errors.New("failed")
+	if _, _, err := store.Do(ctx, "charges", "key-1", fp, func(context.Context) (*Response, error) {
+		return nil, errFailed
+	}); !errors.Is(err, errFailed) {
+		t.Fatalf("err = %v, want %v", err, errFailed)
+	}
+	if mr.Exists("idempotency:charges:key-1") {
+		t.Fatal("key not released after failure")
+	}
+
+	func() {
+		defer func() { _ = recover() }()
+		_, _, _ = store.Do(ctx, "charges", "key-1", fp, func(context.Context) (*Response, error) {
+			panic("boom")
+		})
+	}()
+	if mr.Exists("idempotency:charges:key-1") {
+		t.Fatal("key not released after panic")
+	}
+
+	// 失败后可以使用同一个键重试
+	resp, replayed, err := store.Do(ctx, "charges", "key-1", fp, func(context.Context) (*Response, error) {
+		return &Response{Status: 200}, nil
+	})
+	if err != nil || replayed || resp.Status != 200 {
+		t.Fatalf("Do() = %+v, %v, %v", resp, replayed, err)
+	}
+}
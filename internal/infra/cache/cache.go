@@ -19,6 +19,11 @@ type Cmdable interface {
 	HashCmdable
 	GenericCmdable
 	ListCmdable
+	BitmapCmdable
+	HyperLogLogCmdable
+	ScriptingCmdable
+	// Ping 检查与服务端的连通性
+	Ping(ctx context.Context) StatusCmd
 	Pipeline() Pipeliner
 }
 
@@ -28,12 +33,47 @@ type StringCmdable interface {
 	Get(ctx context.Context, key string) StringCmd
 	IncrBy(ctx context.Context, key string, value int64) IntCmd
 	Incr(ctx context.Context, key string) IntCmd
+	SetNX(ctx context.Context, key string, value interface{}, expiration time.Duration) BoolCmd
+	SetXX(ctx context.Context, key string, value interface{}, expiration time.Duration) BoolCmd
+	SetArgs(ctx context.Context, key string, value interface{}, a SetArgs) StatusCmd
+	GetDel(ctx context.Context, key string) StringCmd
+	GetEx(ctx context.Context, key string, expiration time.Duration) StringCmd
+	MGet(ctx context.Context, keys ...string) SliceCmd
+	MSet(ctx context.Context, values ...interface{}) StatusCmd
 }
 
+// SetArgs SET 命令的完整参数
+type SetArgs struct {
+	// Mode 写入模式，可选 NX（键不存在时写入）、XX（键存在时写入）或为空
+	Mode string
+	// TTL 过期时间，为 0 表示不过期
+	TTL time.Duration
+	// ExpireAt 过期时间点，为零值表示不设置
+	ExpireAt time.Time
+	// Get 是否返回旧值
+	Get bool
+	// KeepTTL 是否保留原有的过期时间（需要 Redis >= 6.0）
+	KeepTTL bool
+}
+
+const (
+	// SetModeNX 键不存在时写入
+	SetModeNX = "NX"
+	// SetModeXX 键存在时写入
+	SetModeXX = "XX"
+)
+
 // HashCmdable 哈希命令接口
 type HashCmdable interface {
 	HSet(ctx context.Context, key string, values ...interface{}) IntCmd
 	HGetAll(ctx context.Context, key string) MapStringStringCmd
+	HGet(ctx context.Context, key, field string) StringCmd
+	HMGet(ctx context.Context, key string, fields ...string) SliceCmd
+	HDel(ctx context.Context, key string, fields ...string) IntCmd
+	HExists(ctx context.Context, key, field string) BoolCmd
+	HIncrBy(ctx context.Context, key, field string, incr int64) IntCmd
+	HLen(ctx context.Context, key string) IntCmd
+	HSetNX(ctx context.Context, key, field string, value interface{}) BoolCmd
 }
 
 // GenericCmdable 通用命令接口
@@ -41,6 +81,33 @@ type GenericCmdable interface {
 	Del(ctx context.Context, keys ...string) IntCmd
 	Exists(ctx context.Context, keys ...string) IntCmd
 	Expire(ctx context.Context, key string, expiration time.Duration) BoolCmd
+	// ExpireNX 仅在键没有过期时间时设置过期时间
+	ExpireNX(ctx context.Context, key string, expiration time.Duration) BoolCmd
+	// ExpireXX 仅在键已有过期时间时设置过期时间
+	ExpireXX(ctx context.Context, key string, expiration time.Duration) BoolCmd
+	// ExpireGT 仅在新过期时间大于当前过期时间时设置
+	ExpireGT(ctx context.Context, key string, expiration time.Duration) BoolCmd
+	// ExpireLT 仅在新过期时间小于当前过期时间时设置
+	ExpireLT(ctx context.Context, key string, expiration time.Duration) BoolCmd
+	// TTL 返回键的剩余过期时间（秒精度）
+	// 键不存在时返回 TTLKeyNotExist，键没有过期时间时返回 TTLNoExpire
+	TTL(ctx context.Context, key string) DurationCmd
+	// PTTL 返回键的剩余过期时间（毫秒精度），特殊返回值同 TTL
+	PTTL(ctx context.Context, key string) DurationCmd
+	// Persist 移除键的过期时间
+	Persist(ctx context.Context, key string) BoolCmd
+}
+
+const (
+	// TTLKeyNotExist TTL/PTTL 在键不存在时的返回值
+	TTLKeyNotExist time.Duration = -2
+	// TTLNoExpire TTL/PTTL 在键没有过期时间时的返回值
+	TTLNoExpire time.Duration = -1
+)
+
+// ScriptingCmdable 脚本命令接口
+type ScriptingCmdable interface {
+	Eval(ctx context.Context, script string, keys []string, args ...interface{}) Cmd
 }
 
 // Pipeliner 管道接口
@@ -49,6 +116,8 @@ type Pipeliner interface {
 	HashCmdable
 	GenericCmdable
 	ListCmdable
+	BitmapCmdable
+	HyperLogLogCmdable
 	Exec(ctx context.Context) ([]Cmder, error)
 }
 
@@ -62,6 +131,31 @@ type ListCmdable interface {
 	LRange(ctx context.Context, key string, start, stop int64) StringSliceCmd
 }
 
+// BitmapCmdable 位图命令接口
+type BitmapCmdable interface {
+	// SetBit 设置 offset 处的位（0 或 1），返回该位原来的值
+	SetBit(ctx context.Context, key string, offset int64, value int) IntCmd
+	// GetBit 返回 offset 处的位，键不存在或越界时返回 0
+	GetBit(ctx context.Context, key string, offset int64) IntCmd
+	// BitCount 统计值为 1 的位数，bitCount 为 nil 时统计整个字符串
+	BitCount(ctx context.Context, key string, bitCount *BitCount) IntCmd
+}
+
+// BitCount BITCOUNT 的字节区间，两端均为闭区间，支持负数下标
+type BitCount struct {
+	Start, End int64
+}
+
+// HyperLogLogCmdable HyperLogLog 命令接口
+type HyperLogLogCmdable interface {
+	// PFAdd 添加元素，基数估计值发生变化时返回 1
+	PFAdd(ctx context.Context, key string, els ...interface{}) IntCmd
+	// PFCount 返回一个或多个键合并后的基数估计值
+	PFCount(ctx context.Context, keys ...string) IntCmd
+	// PFMerge 将 keys 合并到 dest
+	PFMerge(ctx context.Context, dest string, keys ...string) StatusCmd
+}
+
 // Cmder 命令接口
 type Cmder interface {
 	Err() error
@@ -72,12 +166,26 @@ type baseCmd interface {
 	Err() error
 }
 
+// Cmd 通用命令接口，用于返回值类型不固定的命令（如 EVAL）
+type Cmd interface {
+	baseCmd
+	Result() (interface{}, error)
+	Int64() (int64, error)
+	Text() (string, error)
+}
+
 // IntCmd 整数命令接口
 type IntCmd interface {
 	baseCmd
 	Result() (int64, error)
 }
 
+// DurationCmd 时长命令接口
+type DurationCmd interface {
+	baseCmd
+	Result() (time.Duration, error)
+}
+
 // MapStringStringCmd 字符串映射命令接口
 type MapStringStringCmd interface {
 	baseCmd
@@ -105,6 +213,12 @@ type StringCmd interface {
 	Bytes() ([]byte, error)
 }
 
+// SliceCmd 通用切片命令接口
+type SliceCmd interface {
+	baseCmd
+	Result() ([]interface{}, error)
+}
+
 // StringSliceCmd 字符串切片命令接口
 type StringSliceCmd interface {
 	baseCmd
@@ -0,0 +1,56 @@
+package cachex
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/common/hlog"
+
+	"github.com/ZampoRen/go-server-comon/internal/infra/cache"
+	"github.com/ZampoRen/go-server-comon/pkg/sonic"
+)
+
+// GetJSON 读取 key 并反序列化为 T
+// 键不存在时返回的错误满足 errors.Is(err, cache.Nil)
+func GetJSON[T any](ctx context.Context, c cache.StringCmdable, key string) (T, error) {
+	var v T
+	data, err := c.Get(ctx, key).Bytes()
+	if err != nil {
+		return v, err
+	}
+	if err = sonic.Unmarshal(data, &v); err != nil {
+		return v, err
+	}
+	return v, nil
+}
+
+// SetJSON 将 v 序列化为 JSON 写入 key，ttl 为 0 表示不过期
+func SetJSON[T any](ctx context.Context, c cache.StringCmdable, key string, v T, ttl time.Duration) error {
+	data, err := sonic.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return c.Set(ctx, key, data, ttl).Err()
+}
+
+// FetchJSON 读穿缓存：命中时直接返回，未命中时调用 fetch 并回写缓存
+// 缓存读写失败或数据无法反序列化时只打印告警并以 fetch 的结果为准，不影响调用方
+func FetchJSON[T any](ctx context.Context, c cache.StringCmdable, key string, ttl time.Duration, fetch func(ctx context.Context) (T, error)) (T, error) {
+	v, err := GetJSON[T](ctx, c, key)
+	if err == nil {
+		return v, nil
+	}
+	if !errors.Is(err, cache.Nil) {
+		hlog.CtxWarnf(ctx, "[Cache] get json %s failed, fallback to fetch: %v", key, err)
+	}
+
+	v, err = fetch(ctx)
+	if err != nil {
+		return v, err
+	}
+	if err = SetJSON(ctx, c, key, v, ttl); err != nil {
+		hlog.CtxWarnf(ctx, "[Cache] set json %s failed: %v", key, err)
+	}
+	return v, nil
+}
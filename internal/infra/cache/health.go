@@ -0,0 +1,111 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// PoolStats 连接池使用情况
+type PoolStats struct {
+	TotalConns int   // 当前连接总数
+	IdleConns  int   // 空闲连接数
+	MaxConns   int   // 连接池上限，0 表示未知
+	Timeouts   int64 // 等待连接超时的累计次数
+}
+
+// Saturation 返回连接池饱和度（使用中的连接 / 连接池上限），上限未知时返回 0
+func (s PoolStats) Saturation() float64 {
+	if s.MaxConns <= 0 {
+		return 0
+	}
+	return float64(s.TotalConns-s.IdleConns) / float64(s.MaxConns)
+}
+
+// PoolStatser 可以报告连接池使用情况的 Cmdable
+type PoolStatser interface {
+	PoolStats() PoolStats
+}
+
+// HealthReport 健康检查结果
+type HealthReport struct {
+	Healthy    bool          `json:"healthy"`
+	Latency    time.Duration `json:"latency"`
+	Saturation float64       `json:"saturation"`
+	Pool       *PoolStats    `json:"pool,omitempty"`
+	Error      string        `json:"error,omitempty"`
+}
+
+// HealthChecker 缓存健康检查，供就绪探针（/readyz）调用
+type HealthChecker struct {
+	c         Cmdable
+	timeout   time.Duration
+	threshold float64
+}
+
+// HealthOption 健康检查选项
+type HealthOption func(h *HealthChecker)
+
+// WithHealthTimeout 设置 Ping 的超时时间，默认 1s
+func WithHealthTimeout(d time.Duration) HealthOption {
+	return func(h *HealthChecker) {
+		if d > 0 {
+			h.timeout = d
+		}
+	}
+}
+
+// WithSaturationThreshold 设置连接池饱和度阈值，达到阈值时视为不健康，默认 0.95
+func WithSaturationThreshold(t float64) HealthOption {
+	return func(h *HealthChecker) {
+		if t > 0 {
+			h.threshold = t
+		}
+	}
+}
+
+// NewHealthChecker 创建健康检查
+func NewHealthChecker(c Cmdable, opts ...HealthOption) *HealthChecker {
+	h := &HealthChecker{
+		c:         c,
+		timeout:   time.Second,
+		threshold: 0.95,
+	}
+	for _, opt := range opts {
+		opt(h)
+	}
+	return h
+}
+
+// Check 执行一次 Ping 并读取连接池使用情况
+// 返回的 error 与 HealthReport.Error 一致，便于直接作为探针结果
+func (h *HealthChecker) Check(ctx context.Context) (*HealthReport, error) {
+	ctx, cancel := context.WithTimeout(ctx, h.timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := h.c.Ping(ctx).Err()
+	report := &HealthReport{Latency: time.Since(start)}
+
+	if ps, ok := h.c.(PoolStatser); ok {
+		stats := ps.PoolStats()
+		report.Pool = &stats
+		report.Saturation = stats.Saturation()
+		if err == nil && report.Saturation >= h.threshold {
+			err = fmt.Errorf("cache pool saturated: %.2f >= %.2f", report.Saturation, h.threshold)
+		}
+	}
+
+	if err != nil {
+		report.Error = err.Error()
+		return report, err
+	}
+	report.Healthy = true
+	return report, nil
+}
+
+// Ready 供就绪探针调用，等价于 Check 只返回 error
+func (h *HealthChecker) Ready(ctx context.Context) error {
+	_, err := h.Check(ctx)
+	return err
+}
@@ -0,0 +1,101 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"math/bits"
+
+	"github.com/ZampoRen/go-server-comon/internal/infra/cache"
+)
+
+var errBitValue = errors.New("ERR bit is not an integer or out of range")
+
+// SetBit 设置 offset 处的位，位序与 Redis 一致（每个字节的最高位为第 0 位）
+func (m *memoryImpl) SetBit(ctx context.Context, key string, offset int64, value int) cache.IntCmd {
+	if value != 0 && value != 1 {
+		return &intCmd{baseCmd: baseCmd{err: errBitValue}}
+	}
+	if offset < 0 {
+		return &intCmd{baseCmd: baseCmd{err: errors.New("ERR bit offset is not an integer or out of range")}}
+	}
+
+	defer m.lock()()
+	e, err := m.lookupKind(key, kindString)
+	if err != nil {
+		return &intCmd{baseCmd: baseCmd{err: err}}
+	}
+	if e == nil {
+		e = &entry{kind: kindString}
+		m.db.data[key] = e
+	}
+
+	buf := []byte(e.str)
+	idx := int(offset / 8)
+	if idx >= len(buf) {
+		buf = append(buf, make([]byte, idx-len(buf)+1)...)
+	}
+	mask := byte(1 << (7 - uint(offset%8)))
+	var old int64
+	if buf[idx]&mask != 0 {
+		old = 1
+	}
+	if value == 1 {
+		buf[idx] |= mask
+	} else {
+		buf[idx] &^= mask
+	}
+	e.str = string(buf)
+	return &intCmd{val: old}
+}
+
+// GetBit 获取 offset 处的位
+func (m *memoryImpl) GetBit(ctx context.Context, key string, offset int64) cache.IntCmd {
+	defer m.lock()()
+	e, err := m.lookupKind(key, kindString)
+	if err != nil {
+		return &intCmd{baseCmd: baseCmd{err: err}}
+	}
+	if e == nil || offset < 0 || offset/8 >= int64(len(e.str)) {
+		return &intCmd{}
+	}
+	if e.str[offset/8]&byte(1<<(7-uint(offset%8))) != 0 {
+		return &intCmd{val: 1}
+	}
+	return &intCmd{}
+}
+
+// BitCount 统计值为 1 的位数
+func (m *memoryImpl) BitCount(ctx context.Context, key string, bitCount *cache.BitCount) cache.IntCmd {
+	defer m.lock()()
+	e, err := m.lookupKind(key, kindString)
+	if err != nil {
+		return &intCmd{baseCmd: baseCmd{err: err}}
+	}
+	if e == nil {
+		return &intCmd{}
+	}
+
+	n := int64(len(e.str))
+	start, end := int64(0), n-1
+	if bitCount != nil {
+		start, end = bitCount.Start, bitCount.End
+		if start < 0 {
+			start += n
+		}
+		if end < 0 {
+			end += n
+		}
+		if start < 0 {
+			start = 0
+		}
+		if end >= n {
+			end = n - 1
+		}
+	}
+
+	var count int64
+	for i := start; i <= end; i++ {
+		count += int64(bits.OnesCount8(e.str[i]))
+	}
+	return &intCmd{val: count}
+}
@@ -0,0 +1,149 @@
+package memory
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// baseCmd 命令结果的公共部分
+type baseCmd struct {
+	err error
+}
+
+func (c *baseCmd) Err() error {
+	return c.err
+}
+
+// statusCmd 实现 cache.StatusCmd
+type statusCmd struct {
+	baseCmd
+	val string
+}
+
+func (c *statusCmd) Result() (string, error) {
+	return c.val, c.err
+}
+
+// stringCmd 实现 cache.StringCmd
+type stringCmd struct {
+	baseCmd
+	val string
+}
+
+func (c *stringCmd) Result() (string, error) {
+	return c.val, c.err
+}
+
+func (c *stringCmd) Val() string {
+	return c.val
+}
+
+func (c *stringCmd) Int64() (int64, error) {
+	if c.err != nil {
+		return 0, c.err
+	}
+	return strconv.ParseInt(c.val, 10, 64)
+}
+
+func (c *stringCmd) Bytes() ([]byte, error) {
+	return []byte(c.val), c.err
+}
+
+// intCmd 实现 cache.IntCmd
+type intCmd struct {
+	baseCmd
+	val int64
+}
+
+func (c *intCmd) Result() (int64, error) {
+	return c.val, c.err
+}
+
+// boolCmd 实现 cache.BoolCmd
+type boolCmd struct {
+	baseCmd
+	val bool
+}
+
+func (c *boolCmd) Result() (bool, error) {
+	return c.val, c.err
+}
+
+// durationCmd 实现 cache.DurationCmd
+type durationCmd struct {
+	baseCmd
+	val time.Duration
+}
+
+func (c *durationCmd) Result() (time.Duration, error) {
+	return c.val, c.err
+}
+
+// sliceCmd 实现 cache.SliceCmd，不存在的元素为 nil
+type sliceCmd struct {
+	baseCmd
+	val []interface{}
+}
+
+func (c *sliceCmd) Result() ([]interface{}, error) {
+	return c.val, c.err
+}
+
+// stringSliceCmd 实现 cache.StringSliceCmd
+type stringSliceCmd struct {
+	baseCmd
+	val []string
+}
+
+func (c *stringSliceCmd) Result() ([]string, error) {
+	return c.val, c.err
+}
+
+// mapStringStringCmd 实现 cache.MapStringStringCmd
+type mapStringStringCmd struct {
+	baseCmd
+	val map[string]string
+}
+
+func (c *mapStringStringCmd) Result() (map[string]string, error) {
+	return c.val, c.err
+}
+
+// cmd 实现 cache.Cmd
+type cmd struct {
+	baseCmd
+	val interface{}
+}
+
+func (c *cmd) Result() (interface{}, error) {
+	return c.val, c.err
+}
+
+func (c *cmd) Int64() (int64, error) {
+	if c.err != nil {
+		return 0, c.err
+	}
+	switch v := c.val.(type) {
+	case int64:
+		return v, nil
+	case int:
+		return int64(v), nil
+	case string:
+		return strconv.ParseInt(v, 10, 64)
+	default:
+		return 0, fmt.Errorf("memory: unexpected type=%T for Int64", v)
+	}
+}
+
+func (c *cmd) Text() (string, error) {
+	if c.err != nil {
+		return "", c.err
+	}
+	switch v := c.val.(type) {
+	case string:
+		return v, nil
+	default:
+		return "", fmt.Errorf("memory: unexpected type=%T for String", v)
+	}
+}
@@ -0,0 +1,171 @@
+package memory
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/ZampoRen/go-server-comon/internal/infra/cache"
+)
+
+// hash 返回哈希键，create 为 true 且键不存在时创建
+func (m *memoryImpl) hash(key string, create bool) (*entry, error) {
+	e, err := m.lookupKind(key, kindHash)
+	if err != nil || e != nil || !create {
+		return e, err
+	}
+	e = &entry{kind: kindHash, hash: make(map[string]string)}
+	m.db.data[key] = e
+	return e, nil
+}
+
+// HSet 设置哈希字段，返回新增字段的数量
+func (m *memoryImpl) HSet(ctx context.Context, key string, values ...interface{}) cache.IntCmd {
+	kvs, err := pairs(values)
+	if err != nil {
+		return &intCmd{baseCmd: baseCmd{err: err}}
+	}
+
+	defer m.lock()()
+	e, err := m.hash(key, true)
+	if err != nil {
+		return &intCmd{baseCmd: baseCmd{err: err}}
+	}
+	var n int64
+	for _, kv := range kvs {
+		if _, ok := e.hash[kv[0]]; !ok {
+			n++
+		}
+		e.hash[kv[0]] = kv[1]
+	}
+	return &intCmd{val: n}
+}
+
+// HSetNX 仅在字段不存在时设置
+func (m *memoryImpl) HSetNX(ctx context.Context, key, field string, value interface{}) cache.BoolCmd {
+	s, err := toString(value)
+	if err != nil {
+		return &boolCmd{baseCmd: baseCmd{err: err}}
+	}
+
+	defer m.lock()()
+	e, err := m.hash(key, true)
+	if err != nil {
+		return &boolCmd{baseCmd: baseCmd{err: err}}
+	}
+	if _, ok := e.hash[field]; ok {
+		return &boolCmd{}
+	}
+	e.hash[field] = s
+	return &boolCmd{val: true}
+}
+
+// HGetAll 获取哈希的所有字段
+func (m *memoryImpl) HGetAll(ctx context.Context, key string) cache.MapStringStringCmd {
+	defer m.lock()()
+	e, err := m.hash(key, false)
+	if err != nil {
+		return &mapStringStringCmd{baseCmd: baseCmd{err: err}}
+	}
+	out := make(map[string]string)
+	if e != nil {
+		for k, v := range e.hash {
+			out[k] = v
+		}
+	}
+	return &mapStringStringCmd{val: out}
+}
+
+// HGet 获取哈希字段
+func (m *memoryImpl) HGet(ctx context.Context, key, field string) cache.StringCmd {
+	defer m.lock()()
+	e, err := m.hash(key, false)
+	if err != nil {
+		return &stringCmd{baseCmd: baseCmd{err: err}}
+	}
+	if e == nil {
+		return &stringCmd{baseCmd: baseCmd{err: nilErr()}}
+	}
+	v, ok := e.hash[field]
+	if !ok {
+		return &stringCmd{baseCmd: baseCmd{err: nilErr()}}
+	}
+	return &stringCmd{val: v}
+}
+
+// HMGet 批量获取哈希字段，不存在的字段对应 nil
+func (m *memoryImpl) HMGet(ctx context.Context, key string, fields ...string) cache.SliceCmd {
+	defer m.lock()()
+	e, err := m.hash(key, false)
+	if err != nil {
+		return &sliceCmd{baseCmd: baseCmd{err: err}}
+	}
+	vals := make([]interface{}, len(fields))
+	if e != nil {
+		for i, f := range fields {
+			if v, ok := e.hash[f]; ok {
+				vals[i] = v
+			}
+		}
+	}
+	return &sliceCmd{val: vals}
+}
+
+// HDel 删除哈希字段，返回删除的数量
+func (m *memoryImpl) HDel(ctx context.Context, key string, fields ...string) cache.IntCmd {
+	defer m.lock()()
+	e, err := m.hash(key, false)
+	if err != nil || e == nil {
+		return &intCmd{baseCmd: baseCmd{err: err}}
+	}
+	var n int64
+	for _, f := range fields {
+		if _, ok := e.hash[f]; ok {
+			delete(e.hash, f)
+			n++
+		}
+	}
+	if len(e.hash) == 0 {
+		delete(m.db.data, key)
+	}
+	return &intCmd{val: n}
+}
+
+// HExists 判断哈希字段是否存在
+func (m *memoryImpl) HExists(ctx context.Context, key, field string) cache.BoolCmd {
+	defer m.lock()()
+	e, err := m.hash(key, false)
+	if err != nil || e == nil {
+		return &boolCmd{baseCmd: baseCmd{err: err}}
+	}
+	_, ok := e.hash[field]
+	return &boolCmd{val: ok}
+}
+
+// HIncrBy 将哈希字段的值加上指定增量
+func (m *memoryImpl) HIncrBy(ctx context.Context, key, field string, incr int64) cache.IntCmd {
+	defer m.lock()()
+	e, err := m.hash(key, true)
+	if err != nil {
+		return &intCmd{baseCmd: baseCmd{err: err}}
+	}
+	var n int64
+	if v, ok := e.hash[field]; ok {
+		n, err = strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return &intCmd{baseCmd: baseCmd{err: ErrNotInteger}}
+		}
+	}
+	n += incr
+	e.hash[field] = strconv.FormatInt(n, 10)
+	return &intCmd{val: n}
+}
+
+// HLen 返回哈希字段数量
+func (m *memoryImpl) HLen(ctx context.Context, key string) cache.IntCmd {
+	defer m.lock()()
+	e, err := m.hash(key, false)
+	if err != nil || e == nil {
+		return &intCmd{baseCmd: baseCmd{err: err}}
+	}
+	return &intCmd{val: int64(len(e.hash))}
+}
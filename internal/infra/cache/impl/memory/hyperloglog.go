@@ -0,0 +1,95 @@
+package memory
+
+import (
+	"context"
+
+	"github.com/ZampoRen/go-server-comon/internal/infra/cache"
+)
+
+// HyperLogLog 在进程内用精确集合实现，PFCount 返回的是精确基数
+
+// hll 返回 HyperLogLog 键，create 为 true 且键不存在时创建
+func (m *memoryImpl) hll(key string, create bool) (*entry, error) {
+	e, err := m.lookupKind(key, kindHLL)
+	if err != nil || e != nil || !create {
+		return e, err
+	}
+	e = &entry{kind: kindHLL, set: make(map[string]struct{})}
+	m.db.data[key] = e
+	return e, nil
+}
+
+// PFAdd 添加元素，集合发生变化或新建键时返回 1
+func (m *memoryImpl) PFAdd(ctx context.Context, key string, els ...interface{}) cache.IntCmd {
+	strs := make([]string, len(els))
+	for i, el := range els {
+		s, err := toString(el)
+		if err != nil {
+			return &intCmd{baseCmd: baseCmd{err: err}}
+		}
+		strs[i] = s
+	}
+
+	defer m.lock()()
+	existed, err := m.hll(key, false)
+	if err != nil {
+		return &intCmd{baseCmd: baseCmd{err: err}}
+	}
+	e, _ := m.hll(key, true)
+	changed := existed == nil
+	for _, s := range strs {
+		if _, ok := e.set[s]; !ok {
+			e.set[s] = struct{}{}
+			changed = true
+		}
+	}
+	if changed {
+		return &intCmd{val: 1}
+	}
+	return &intCmd{}
+}
+
+// PFCount 返回多个键合并后的基数
+func (m *memoryImpl) PFCount(ctx context.Context, keys ...string) cache.IntCmd {
+	defer m.lock()()
+	union := make(map[string]struct{})
+	for _, key := range keys {
+		e, err := m.hll(key, false)
+		if err != nil {
+			return &intCmd{baseCmd: baseCmd{err: err}}
+		}
+		if e == nil {
+			continue
+		}
+		for s := range e.set {
+			union[s] = struct{}{}
+		}
+	}
+	return &intCmd{val: int64(len(union))}
+}
+
+// PFMerge 将 keys 合并到 dest，dest 原有的元素会保留
+func (m *memoryImpl) PFMerge(ctx context.Context, dest string, keys ...string) cache.StatusCmd {
+	defer m.lock()()
+	sources := make([]*entry, 0, len(keys))
+	for _, key := range keys {
+		e, err := m.hll(key, false)
+		if err != nil {
+			return &statusCmd{baseCmd: baseCmd{err: err}}
+		}
+		if e != nil {
+			sources = append(sources, e)
+		}
+	}
+
+	d, err := m.hll(dest, true)
+	if err != nil {
+		return &statusCmd{baseCmd: baseCmd{err: err}}
+	}
+	for _, e := range sources {
+		for s := range e.set {
+			d.set[s] = struct{}{}
+		}
+	}
+	return &statusCmd{val: "OK"}
+}
@@ -0,0 +1,155 @@
+package memory
+
+import (
+	"context"
+
+	"github.com/ZampoRen/go-server-comon/internal/infra/cache"
+)
+
+// list 返回列表键，create 为 true 且键不存在时创建
+func (m *memoryImpl) list(key string, create bool) (*entry, error) {
+	e, err := m.lookupKind(key, kindList)
+	if err != nil || e != nil || !create {
+		return e, err
+	}
+	e = &entry{kind: kindList}
+	m.db.data[key] = e
+	return e, nil
+}
+
+// index 将可能为负数的下标转换为正向下标，越界时返回 false
+func index(i int64, n int) (int, bool) {
+	if i < 0 {
+		i += int64(n)
+	}
+	if i < 0 || i >= int64(n) {
+		return 0, false
+	}
+	return int(i), true
+}
+
+func (m *memoryImpl) push(key string, values []interface{}, left bool) cache.IntCmd {
+	strs := make([]string, len(values))
+	for i, v := range values {
+		s, err := toString(v)
+		if err != nil {
+			return &intCmd{baseCmd: baseCmd{err: err}}
+		}
+		strs[i] = s
+	}
+
+	defer m.lock()()
+	e, err := m.list(key, true)
+	if err != nil {
+		return &intCmd{baseCmd: baseCmd{err: err}}
+	}
+	if left {
+		// LPUSH a b c 的结果为 c b a
+		head := make([]string, 0, len(strs)+len(e.list))
+		for i := len(strs) - 1; i >= 0; i-- {
+			head = append(head, strs[i])
+		}
+		e.list = append(head, e.list...)
+	} else {
+		e.list = append(e.list, strs...)
+	}
+	return &intCmd{val: int64(len(e.list))}
+}
+
+// LPush 从列表头部插入元素，返回列表长度
+func (m *memoryImpl) LPush(ctx context.Context, key string, values ...interface{}) cache.IntCmd {
+	return m.push(key, values, true)
+}
+
+// RPush 从列表尾部插入元素，返回列表长度
+func (m *memoryImpl) RPush(ctx context.Context, key string, values ...interface{}) cache.IntCmd {
+	return m.push(key, values, false)
+}
+
+// LIndex 获取列表指定下标的元素，支持负数下标
+func (m *memoryImpl) LIndex(ctx context.Context, key string, idx int64) cache.StringCmd {
+	defer m.lock()()
+	e, err := m.list(key, false)
+	if err != nil {
+		return &stringCmd{baseCmd: baseCmd{err: err}}
+	}
+	if e == nil {
+		return &stringCmd{baseCmd: baseCmd{err: nilErr()}}
+	}
+	i, ok := index(idx, len(e.list))
+	if !ok {
+		return &stringCmd{baseCmd: baseCmd{err: nilErr()}}
+	}
+	return &stringCmd{val: e.list[i]}
+}
+
+// LSet 设置列表指定下标的元素
+func (m *memoryImpl) LSet(ctx context.Context, key string, idx int64, value interface{}) cache.StatusCmd {
+	s, err := toString(value)
+	if err != nil {
+		return &statusCmd{baseCmd: baseCmd{err: err}}
+	}
+
+	defer m.lock()()
+	e, err := m.list(key, false)
+	if err != nil {
+		return &statusCmd{baseCmd: baseCmd{err: err}}
+	}
+	if e == nil {
+		return &statusCmd{baseCmd: baseCmd{err: ErrNoSuchKey}}
+	}
+	i, ok := index(idx, len(e.list))
+	if !ok {
+		return &statusCmd{baseCmd: baseCmd{err: ErrIndexOutOfRange}}
+	}
+	e.list[i] = s
+	return &statusCmd{val: "OK"}
+}
+
+// LPop 弹出列表头部元素，列表为空时删除键
+func (m *memoryImpl) LPop(ctx context.Context, key string) cache.StringCmd {
+	defer m.lock()()
+	e, err := m.list(key, false)
+	if err != nil {
+		return &stringCmd{baseCmd: baseCmd{err: err}}
+	}
+	if e == nil || len(e.list) == 0 {
+		return &stringCmd{baseCmd: baseCmd{err: nilErr()}}
+	}
+	v := e.list[0]
+	e.list = e.list[1:]
+	if len(e.list) == 0 {
+		delete(m.db.data, key)
+	}
+	return &stringCmd{val: v}
+}
+
+// LRange 获取列表指定区间的元素，区间两端均为闭区间，支持负数下标
+func (m *memoryImpl) LRange(ctx context.Context, key string, start, stop int64) cache.StringSliceCmd {
+	defer m.lock()()
+	e, err := m.list(key, false)
+	if err != nil {
+		return &stringSliceCmd{baseCmd: baseCmd{err: err}}
+	}
+	if e == nil {
+		return &stringSliceCmd{val: []string{}}
+	}
+
+	n := int64(len(e.list))
+	if start < 0 {
+		start += n
+	}
+	if stop < 0 {
+		stop += n
+	}
+	if start < 0 {
+		start = 0
+	}
+	if stop >= n {
+		stop = n - 1
+	}
+	if start > stop {
+		return &stringSliceCmd{val: []string{}}
+	}
+	return &stringSliceCmd{val: append([]string(nil), e.list[start:stop+1]...)}
+}
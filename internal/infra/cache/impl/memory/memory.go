@@ -0,0 +1,569 @@
+package memory
+
+import (
+	"context"
+	"encoding"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/ZampoRen/go-server-comon/internal/infra/cache"
+)
+
+var (
+	// ErrNil 键不存在，未设置 cache.Nil 时作为默认的 nil 错误
+	ErrNil = errors.New("memory: nil")
+	// ErrWrongType 对键执行了与其类型不符的命令
+	ErrWrongType = errors.New("WRONGTYPE Operation against a key holding the wrong kind of value")
+	// ErrNotInteger 值不是整数
+	ErrNotInteger = errors.New("ERR value is not an integer or out of range")
+	// ErrNoSuchKey 键不存在（LSET）
+	ErrNoSuchKey = errors.New("ERR no such key")
+	// ErrIndexOutOfRange 下标越界（LSET）
+	ErrIndexOutOfRange = errors.New("ERR index out of range")
+	// ErrScriptNotRegistered EVAL 的脚本没有通过 WithScript 注册
+	ErrScriptNotRegistered = errors.New("memory: script not registered")
+)
+
+// ScriptFunc 用 Go 实现的脚本，替代 EVAL 中的 Lua 脚本
+// c 在脚本执行期间独占存储，脚本内的多条命令整体是原子的
+type ScriptFunc func(ctx context.Context, c cache.Cmdable, keys []string, args ...interface{}) (interface{}, error)
+
+// Option 构造选项
+type Option func(o *option)
+
+type option struct {
+	now     func() time.Time
+	scripts map[string]ScriptFunc
+}
+
+// WithClock 设置时钟，便于在测试中控制过期
+func WithClock(now func() time.Time) Option {
+	return func(o *option) {
+		if now != nil {
+			o.now = now
+		}
+	}
+}
+
+// WithScript 注册 EVAL 脚本的 Go 实现，script 需与调用方传入的脚本文本完全一致
+func WithScript(script string, fn ScriptFunc) Option {
+	return func(o *option) {
+		o.scripts[script] = fn
+	}
+}
+
+type kind int
+
+const (
+	kindString kind = iota + 1
+	kindHash
+	kindList
+	kindHLL
+)
+
+// entry 存储的值
+type entry struct {
+	kind     kind
+	str      string
+	hash     map[string]string
+	list     []string
+	set      map[string]struct{}
+	expireAt time.Time // 零值表示不过期
+}
+
+// db 所有视图共享的存储
+type db struct {
+	mu   sync.Mutex
+	data map[string]*entry
+	opt  *option
+	// subs 发布订阅不受脚本独占影响，使用单独的锁
+	subs subscribers
+}
+
+// memoryImpl 进程内的 cache.Cmdable 实现，语义与 Redis 保持一致
+type memoryImpl struct {
+	db *db
+	// locked 为 true 时调用方已持有 db.mu（脚本执行期间），命令不再加锁
+	locked bool
+}
+
+// New 创建进程内缓存，适用于单元测试
+// 如果 cache.Nil 尚未设置，会将其设置为 ErrNil
+func New(opts ...Option) cache.Cmdable {
+	o := &option{
+		now:     time.Now,
+		scripts: make(map[string]ScriptFunc),
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	if cache.Nil == nil {
+		cache.SetDefaultNilError(ErrNil)
+	}
+
+	return &memoryImpl{
+		db: &db{
+			data: make(map[string]*entry),
+			opt:  o,
+		},
+	}
+}
+
+func nilErr() error {
+	if cache.Nil != nil {
+		return cache.Nil
+	}
+	return ErrNil
+}
+
+func (m *memoryImpl) lock() func() {
+	if m.locked {
+		return func() {}
+	}
+	m.db.mu.Lock()
+	return m.db.mu.Unlock
+}
+
+func (m *memoryImpl) now() time.Time {
+	return m.db.opt.now()
+}
+
+// lookup 返回未过期的键，过期的键会被惰性删除
+func (m *memoryImpl) lookup(key string) *entry {
+	e, ok := m.db.data[key]
+	if !ok {
+		return nil
+	}
+	if !e.expireAt.IsZero() && !m.now().Before(e.expireAt) {
+		delete(m.db.data, key)
+		return nil
+	}
+	return e
+}
+
+// lookupKind 返回指定类型的键，类型不符时返回 ErrWrongType
+func (m *memoryImpl) lookupKind(key string, k kind) (*entry, error) {
+	e := m.lookup(key)
+	if e == nil {
+		return nil, nil
+	}
+	if e.kind != k {
+		return nil, ErrWrongType
+	}
+	return e, nil
+}
+
+func (m *memoryImpl) expireAt(d time.Duration) time.Time {
+	if d <= 0 {
+		return time.Time{}
+	}
+	return m.now().Add(d)
+}
+
+// toString 按 go-redis 的规则将参数格式化为字符串
+func toString(v interface{}) (string, error) {
+	switch v := v.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	case []byte:
+		return string(v), nil
+	case int:
+		return strconv.FormatInt(int64(v), 10), nil
+	case int8:
+		return strconv.FormatInt(int64(v), 10), nil
+	case int16:
+		return strconv.FormatInt(int64(v), 10), nil
+	case int32:
+		return strconv.FormatInt(int64(v), 10), nil
+	case int64:
+		return strconv.FormatInt(v, 10), nil
+	case uint:
+		return strconv.FormatUint(uint64(v), 10), nil
+	case uint8:
+		return strconv.FormatUint(uint64(v), 10), nil
+	case uint16:
+		return strconv.FormatUint(uint64(v), 10), nil
+	case uint32:
+		return strconv.FormatUint(uint64(v), 10), nil
+	case uint64:
+		return strconv.FormatUint(v, 10), nil
+	case float32:
+		return strconv.FormatFloat(float64(v), 'f', -1, 32), nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	case bool:
+		if v {
+			return "1", nil
+		}
+		return "0", nil
+	case time.Time:
+		return v.Format(time.RFC3339Nano), nil
+	case time.Duration:
+		return strconv.FormatInt(v.Nanoseconds(), 10), nil
+	case encoding.BinaryMarshaler:
+		b, err := v.MarshalBinary()
+		if err != nil {
+			return "", err
+		}
+		return string(b), nil
+	default:
+		return "", fmt.Errorf("memory: can't marshal %T (implement encoding.BinaryMarshaler)", v)
+	}
+}
+
+// pairs 展开 MSet/HSet 的参数，支持 k1, v1, k2, v2、[]string、map[string]interface{} 与 map[string]string
+func pairs(values []interface{}) ([][2]string, error) {
+	if len(values) == 1 {
+		switch v := values[0].(type) {
+		case []string:
+			args := make([]interface{}, len(v))
+			for i := range v {
+				args[i] = v[i]
+			}
+			values = args
+		case []interface{}:
+			values = v
+		case map[string]interface{}:
+			out := make([][2]string, 0, len(v))
+			for k, val := range v {
+				s, err := toString(val)
+				if err != nil {
+					return nil, err
+				}
+				out = append(out, [2]string{k, s})
+			}
+			return out, nil
+		case map[string]string:
+			out := make([][2]string, 0, len(v))
+			for k, val := range v {
+				out = append(out, [2]string{k, val})
+			}
+			return out, nil
+		}
+	}
+
+	if len(values) == 0 || len(values)%2 != 0 {
+		return nil, errors.New("ERR wrong number of arguments")
+	}
+	out := make([][2]string, 0, len(values)/2)
+	for i := 0; i < len(values); i += 2 {
+		k, err := toString(values[i])
+		if err != nil {
+			return nil, err
+		}
+		v, err := toString(values[i+1])
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, [2]string{k, v})
+	}
+	return out, nil
+}
+
+// Set 设置键值
+func (m *memoryImpl) Set(ctx context.Context, key string, value interface{}, expiration time.Duration) cache.StatusCmd {
+	return m.SetArgs(ctx, key, value, cache.SetArgs{TTL: expiration})
+}
+
+// SetNX 仅在键不存在时设置
+func (m *memoryImpl) SetNX(ctx context.Context, key string, value interface{}, expiration time.Duration) cache.BoolCmd {
+	res := m.SetArgs(ctx, key, value, cache.SetArgs{Mode: cache.SetModeNX, TTL: expiration}).(*statusCmd)
+	return setResultToBool(res)
+}
+
+// SetXX 仅在键存在时设置
+func (m *memoryImpl) SetXX(ctx context.Context, key string, value interface{}, expiration time.Duration) cache.BoolCmd {
+	res := m.SetArgs(ctx, key, value, cache.SetArgs{Mode: cache.SetModeXX, TTL: expiration}).(*statusCmd)
+	return setResultToBool(res)
+}
+
+func setResultToBool(res *statusCmd) *boolCmd {
+	if errors.Is(res.err, nilErr()) {
+		return &boolCmd{}
+	}
+	return &boolCmd{baseCmd: res.baseCmd, val: res.err == nil}
+}
+
+// SetArgs 使用完整参数设置键值
+// 条件不满足时返回 cache.Nil；Get 为 true 时结果为旧值，旧值不存在时返回 cache.Nil
+func (m *memoryImpl) SetArgs(ctx context.Context, key string, value interface{}, a cache.SetArgs) cache.StatusCmd {
+	s, err := toString(value)
+	if err != nil {
+		return &statusCmd{baseCmd: baseCmd{err: err}}
+	}
+
+	defer m.lock()()
+	old := m.lookup(key)
+	if a.Get && old != nil && old.kind != kindString {
+		return &statusCmd{baseCmd: baseCmd{err: ErrWrongType}}
+	}
+	switch a.Mode {
+	case cache.SetModeNX:
+		if old != nil {
+			return &statusCmd{baseCmd: baseCmd{err: nilErr()}}
+		}
+	case cache.SetModeXX:
+		if old == nil {
+			return &statusCmd{baseCmd: baseCmd{err: nilErr()}}
+		}
+	}
+
+	e := &entry{kind: kindString, str: s}
+	switch {
+	case a.KeepTTL && old != nil:
+		e.expireAt = old.expireAt
+	case !a.ExpireAt.IsZero():
+		e.expireAt = a.ExpireAt
+	default:
+		e.expireAt = m.expireAt(a.TTL)
+	}
+	m.db.data[key] = e
+
+	if a.Get {
+		if old == nil {
+			return &statusCmd{baseCmd: baseCmd{err: nilErr()}}
+		}
+		return &statusCmd{val: old.str}
+	}
+	return &statusCmd{val: "OK"}
+}
+
+// Get 获取键值
+func (m *memoryImpl) Get(ctx context.Context, key string) cache.StringCmd {
+	defer m.lock()()
+	return m.get(key)
+}
+
+func (m *memoryImpl) get(key string) *stringCmd {
+	e, err := m.lookupKind(key, kindString)
+	if err != nil {
+		return &stringCmd{baseCmd: baseCmd{err: err}}
+	}
+	if e == nil {
+		return &stringCmd{baseCmd: baseCmd{err: nilErr()}}
+	}
+	return &stringCmd{val: e.str}
+}
+
+// GetDel 获取键值并删除
+func (m *memoryImpl) GetDel(ctx context.Context, key string) cache.StringCmd {
+	defer m.lock()()
+	res := m.get(key)
+	if res.err == nil {
+		delete(m.db.data, key)
+	}
+	return res
+}
+
+// GetEx 获取键值并设置过期时间，与 go-redis 一致：expiration 为 0 时移除过期时间（PERSIST），为负数时保持原过期时间不变
+func (m *memoryImpl) GetEx(ctx context.Context, key string, expiration time.Duration) cache.StringCmd {
+	defer m.lock()()
+	res := m.get(key)
+	if res.err == nil && expiration >= 0 {
+		m.db.data[key].expireAt = m.expireAt(expiration)
+	}
+	return res
+}
+
+// MGet 批量获取键值，不存在或类型不是字符串的键对应 nil
+func (m *memoryImpl) MGet(ctx context.Context, keys ...string) cache.SliceCmd {
+	defer m.lock()()
+	vals := make([]interface{}, len(keys))
+	for i, key := range keys {
+		if e := m.lookup(key); e != nil && e.kind == kindString {
+			vals[i] = e.str
+		}
+	}
+	return &sliceCmd{val: vals}
+}
+
+// MSet 批量设置键值
+func (m *memoryImpl) MSet(ctx context.Context, values ...interface{}) cache.StatusCmd {
+	kvs, err := pairs(values)
+	if err != nil {
+		return &statusCmd{baseCmd: baseCmd{err: err}}
+	}
+
+	defer m.lock()()
+	for _, kv := range kvs {
+		m.db.data[kv[0]] = &entry{kind: kindString, str: kv[1]}
+	}
+	return &statusCmd{val: "OK"}
+}
+
+// Incr 将键的值加 1
+func (m *memoryImpl) Incr(ctx context.Context, key string) cache.IntCmd {
+	return m.IncrBy(ctx, key, 1)
+}
+
+// IncrBy 将键的值加上指定增量
+func (m *memoryImpl) IncrBy(ctx context.Context, key string, value int64) cache.IntCmd {
+	defer m.lock()()
+	e, err := m.lookupKind(key, kindString)
+	if err != nil {
+		return &intCmd{baseCmd: baseCmd{err: err}}
+	}
+	if e == nil {
+		e = &entry{kind: kindString, str: "0"}
+		m.db.data[key] = e
+	}
+	n, err := strconv.ParseInt(e.str, 10, 64)
+	if err != nil {
+		return &intCmd{baseCmd: baseCmd{err: ErrNotInteger}}
+	}
+	n += value
+	e.str = strconv.FormatInt(n, 10)
+	return &intCmd{val: n}
+}
+
+// Del 删除键，返回删除的数量
+func (m *memoryImpl) Del(ctx context.Context, keys ...string) cache.IntCmd {
+	defer m.lock()()
+	var n int64
+	for _, key := range keys {
+		if m.lookup(key) != nil {
+			delete(m.db.data, key)
+			n++
+		}
+	}
+	return &intCmd{val: n}
+}
+
+// Exists 返回存在的键数量，重复的键重复计数
+func (m *memoryImpl) Exists(ctx context.Context, keys ...string) cache.IntCmd {
+	defer m.lock()()
+	var n int64
+	for _, key := range keys {
+		if m.lookup(key) != nil {
+			n++
+		}
+	}
+	return &intCmd{val: n}
+}
+
+// expire 按条件设置过期时间，expiration <= 0 时直接删除键（与 Redis 一致）
+func (m *memoryImpl) expire(key string, expiration time.Duration, cond string) cache.BoolCmd {
+	defer m.lock()()
+	e := m.lookup(key)
+	if e == nil {
+		return &boolCmd{}
+	}
+
+	at := m.now().Add(expiration)
+	switch cond {
+	case "NX":
+		if !e.expireAt.IsZero() {
+			return &boolCmd{}
+		}
+	case "XX":
+		if e.expireAt.IsZero() {
+			return &boolCmd{}
+		}
+	case "GT":
+		// 没有过期时间视为无穷大
+		if e.expireAt.IsZero() || !at.After(e.expireAt) {
+			return &boolCmd{}
+		}
+	case "LT":
+		if !e.expireAt.IsZero() && !at.Before(e.expireAt) {
+			return &boolCmd{}
+		}
+	}
+
+	if expiration <= 0 {
+		delete(m.db.data, key)
+		return &boolCmd{val: true}
+	}
+	e.expireAt = at
+	return &boolCmd{val: true}
+}
+
+// Expire 设置键的过期时间
+func (m *memoryImpl) Expire(ctx context.Context, key string, expiration time.Duration) cache.BoolCmd {
+	return m.expire(key, expiration, "")
+}
+
+// ExpireNX 仅在键没有过期时间时设置过期时间
+func (m *memoryImpl) ExpireNX(ctx context.Context, key string, expiration time.Duration) cache.BoolCmd {
+	return m.expire(key, expiration, "NX")
+}
+
+// ExpireXX 仅在键已有过期时间时设置过期时间
+func (m *memoryImpl) ExpireXX(ctx context.Context, key string, expiration time.Duration) cache.BoolCmd {
+	return m.expire(key, expiration, "XX")
+}
+
+// ExpireGT 仅在新过期时间大于当前过期时间时设置
+func (m *memoryImpl) ExpireGT(ctx context.Context, key string, expiration time.Duration) cache.BoolCmd {
+	return m.expire(key, expiration, "GT")
+}
+
+// ExpireLT 仅在新过期时间小于当前过期时间时设置
+func (m *memoryImpl) ExpireLT(ctx context.Context, key string, expiration time.Duration) cache.BoolCmd {
+	return m.expire(key, expiration, "LT")
+}
+
+// ttl 返回剩余过期时间并按 precision 向下取整
+func (m *memoryImpl) ttl(key string, precision time.Duration) cache.DurationCmd {
+	defer m.lock()()
+	e := m.lookup(key)
+	if e == nil {
+		return &durationCmd{val: cache.TTLKeyNotExist}
+	}
+	if e.expireAt.IsZero() {
+		return &durationCmd{val: cache.TTLNoExpire}
+	}
+	return &durationCmd{val: e.expireAt.Sub(m.now()).Truncate(precision)}
+}
+
+// TTL 返回键的剩余过期时间
+func (m *memoryImpl) TTL(ctx context.Context, key string) cache.DurationCmd {
+	return m.ttl(key, time.Second)
+}
+
+// PTTL 返回键的剩余过期时间（毫秒精度）
+func (m *memoryImpl) PTTL(ctx context.Context, key string) cache.DurationCmd {
+	return m.ttl(key, time.Millisecond)
+}
+
+// Persist 移除键的过期时间
+func (m *memoryImpl) Persist(ctx context.Context, key string) cache.BoolCmd {
+	defer m.lock()()
+	e := m.lookup(key)
+	if e == nil || e.expireAt.IsZero() {
+		return &boolCmd{}
+	}
+	e.expireAt = time.Time{}
+	return &boolCmd{val: true}
+}
+
+// Eval 执行通过 WithScript 注册的脚本，脚本执行期间独占存储
+func (m *memoryImpl) Eval(ctx context.Context, script string, keys []string, args ...interface{}) cache.Cmd {
+	fn, ok := m.db.opt.scripts[script]
+	if !ok {
+		return &cmd{baseCmd: baseCmd{err: ErrScriptNotRegistered}}
+	}
+
+	defer m.lock()()
+	val, err := fn(ctx, &memoryImpl{db: m.db, locked: true}, keys, args...)
+	return &cmd{baseCmd: baseCmd{err: err}, val: val}
+}
+
+// Ping 检查连通性，进程内实现总是返回 PONG
+func (m *memoryImpl) Ping(ctx context.Context) cache.StatusCmd {
+	return &statusCmd{val: "PONG"}
+}
+
+// Pipeline 创建管道，命令在 Exec 时依次执行
+func (m *memoryImpl) Pipeline() cache.Pipeliner {
+	return &pipelineImpl{m: m}
+}
+
+var _ cache.Cmdable = (*memoryImpl)(nil)
+var _ cache.Pipeliner = (*pipelineImpl)(nil)
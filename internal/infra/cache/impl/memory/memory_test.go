@@ -0,0 +1,199 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ZampoRen/go-server-comon/internal/infra/cache"
+)
+
+// TestString 测试字符串命令与过期
+func TestString(t *testing.T) {
+	ctx := context.Background()
+	now := time.Unix(1700000000, 0)
+	c := New(WithClock(func() time.Time { return now }))
+
+	if err := c.Set(ctx, "k", 1, time.Minute).Err(); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	if ok, _ := c.SetNX(ctx, "k", 2, 0).Result(); ok {
+		t.Errorf("SetNX() on existing key = true, want false")
+	}
+	if n, err := c.Incr(ctx, "k").Result(); err != nil || n != 2 {
+		t.Errorf("Incr() = %d, %v, want 2", n, err)
+	}
+	if ttl, _ := c.TTL(ctx, "k").Result(); ttl != time.Minute {
+		t.Errorf("TTL() = %v, want %v", ttl, time.Minute)
+	}
+
+	now = now.Add(time.Minute)
+	if _, err := c.Get(ctx, "k").Result(); !errors.Is(err, cache.Nil) {
+		t.Errorf("Get() after expiry error = %v, want cache.Nil", err)
+	}
+	if ttl, _ := c.TTL(ctx, "k").Result(); ttl != cache.TTLKeyNotExist {
+		t.Errorf("TTL() after expiry = %v, want %v", ttl, cache.TTLKeyNotExist)
+	}
+
+	c.HSet(ctx, "h", "f", "v")
+	if _, err := c.Get(ctx, "h").Result(); !errors.Is(err, ErrWrongType) {
+		t.Errorf("Get() on hash error = %v, want ErrWrongType", err)
+	}
+}
+
+// TestGetEx 测试 GetEx 设置、移除与保持过期时间
+func TestGetEx(t *testing.T) {
+	ctx := context.Background()
+	now := time.Unix(1700000000, 0)
+	c := New(WithClock(func() time.Time { return now }))
+	c.Set(ctx, "k", "v", time.Minute)
+
+	tests := []struct {
+		name       string
+		expiration time.Duration
+		want       time.Duration
+	}{
+		{"设置过期时间", time.Hour, time.Hour},
+		{"负数保持原过期时间", -1, time.Hour},
+		{"0 移除过期时间", 0, cache.TTLNoExpire},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if v, err := c.GetEx(ctx, "k", tt.expiration).Result(); err != nil || v != "v" {
+				t.Fatalf("GetEx() = %q, %v, want v", v, err)
+			}
+			if ttl, _ := c.TTL(ctx, "k").Result(); ttl != tt.want {
+				t.Errorf("TTL() = %v, want %v", ttl, tt.want)
+			}
+		})
+	}
+}
+
+// TestExpireCondition 测试 ExpireNX/XX/GT/LT 的条件
+func TestExpireCondition(t *testing.T) {
+	ctx := context.Background()
+	c := New()
+	c.Set(ctx, "k", "v", 0)
+
+	tests := []struct {
+		name string
+		fn   func() cache.BoolCmd
+		want bool
+	}{
+		{"XX 无过期时间", func() cache.BoolCmd { return c.ExpireXX(ctx, "k", time.Minute) }, false},
+		{"GT 无过期时间", func() cache.BoolCmd { return c.ExpireGT(ctx, "k", time.Minute) }, false},
+		{"NX 无过期时间", func() cache.BoolCmd { return c.ExpireNX(ctx, "k", time.Minute) }, true},
+		{"NX 已有过期时间", func() cache.BoolCmd { return c.ExpireNX(ctx, "k", time.Hour) }, false},
+		{"GT 更长", func() cache.BoolCmd { return c.ExpireGT(ctx, "k", time.Hour) }, true},
+		{"LT 更长", func() cache.BoolCmd { return c.ExpireLT(ctx, "k", 2*time.Hour) }, false},
+		{"LT 更短", func() cache.BoolCmd { return c.ExpireLT(ctx, "k", time.Second) }, true},
+		{"Persist", func() cache.BoolCmd { return c.Persist(ctx, "k") }, true},
+		{"键不存在", func() cache.BoolCmd { return c.Expire(ctx, "missing", time.Minute) }, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.fn().Result()
+			if err != nil || got != tt.want {
+				t.Errorf("got %v, %v, want %v", got, err, tt.want)
+			}
+		})
+	}
+}
+
+// TestList 测试列表命令
+func TestList(t *testing.T) {
+	ctx := context.Background()
+	c := New()
+
+	c.LPush(ctx, "l", "a", "b")
+	c.RPush(ctx, "l", "c")
+	got, _ := c.LRange(ctx, "l", 0, -1).Result()
+	if want := []string{"b", "a", "c"}; len(got) != len(want) || got[0] != want[0] || got[1] != want[1] || got[2] != want[2] {
+		t.Errorf("LRange() = %v, want %v", got, want)
+	}
+	if v, _ := c.LIndex(ctx, "l", -1).Result(); v != "c" {
+		t.Errorf("LIndex(-1) = %q, want c", v)
+	}
+	if err := c.LSet(ctx, "l", 5, "x").Err(); !errors.Is(err, ErrIndexOutOfRange) {
+		t.Errorf("LSet() out of range error = %v", err)
+	}
+}
+
+// TestPipelineAndEval 测试管道结果回填与脚本执行
+func TestPipelineAndEval(t *testing.T) {
+	ctx := context.Background()
+	const script = "release"
+	c := New(WithScript(script, func(ctx context.Context, c cache.Cmdable, keys []string, args ...interface{}) (interface{}, error) {
+		if v, _ := c.Get(ctx, keys[0]).Result(); v != args[0] {
+			return int64(0), nil
+		}
+		return c.Del(ctx, keys[0]).Result()
+	}))
+
+	p := c.Pipeline()
+	set := p.Set(ctx, "k", "token", 0)
+	get := p.Get(ctx, "k")
+	miss := p.Get(ctx, "missing")
+	cmds, err := p.Exec(ctx)
+	if len(cmds) != 3 || !errors.Is(err, cache.Nil) {
+		t.Fatalf("Exec() = %d cmds, %v", len(cmds), err)
+	}
+	if v, _ := set.Result(); v != "OK" {
+		t.Errorf("Set result = %q", v)
+	}
+	if v, _ := get.Result(); v != "token" {
+		t.Errorf("Get result = %q", v)
+	}
+	if !errors.Is(miss.Err(), cache.Nil) {
+		t.Errorf("Get missing error = %v", miss.Err())
+	}
+
+	if n, _ := c.Eval(ctx, script, []string{"k"}, "other").Int64(); n != 0 {
+		t.Errorf("Eval() with wrong token = %d, want 0", n)
+	}
+	if n, _ := c.Eval(ctx, script, []string{"k"}, "token").Int64(); n != 1 {
+		t.Errorf("Eval() = %d, want 1", n)
+	}
+	if err := c.Eval(ctx, "unknown", nil).Err(); !errors.Is(err, ErrScriptNotRegistered) {
+		t.Errorf("Eval() unknown script error = %v", err)
+	}
+}
+
+// TestBitmapAndHyperLogLog 测试位图与 HyperLogLog 命令
+func TestBitmapAndHyperLogLog(t *testing.T) {
+	ctx := context.Background()
+	c := New()
+
+	if old, _ := c.SetBit(ctx, "b", 9, 1).Result(); old != 0 {
+		t.Errorf("SetBit() old = %d, want 0", old)
+	}
+	c.SetBit(ctx, "b", 0, 1)
+	if v, _ := c.Get(ctx, "b").Result(); v != "\x80\x40" {
+		t.Errorf("Get() = %q, want %q", v, "\x80\x40")
+	}
+	if bit, _ := c.GetBit(ctx, "b", 9).Result(); bit != 1 {
+		t.Errorf("GetBit() = %d, want 1", bit)
+	}
+	if n, _ := c.BitCount(ctx, "b", nil).Result(); n != 2 {
+		t.Errorf("BitCount() = %d, want 2", n)
+	}
+	if n, _ := c.BitCount(ctx, "b", &cache.BitCount{Start: -1, End: -1}).Result(); n != 1 {
+		t.Errorf("BitCount(-1, -1) = %d, want 1", n)
+	}
+
+	c.PFAdd(ctx, "h1", "a", "b")
+	if changed, _ := c.PFAdd(ctx, "h1", "a").Result(); changed != 0 {
+		t.Errorf("PFAdd() existing element = %d, want 0", changed)
+	}
+	c.PFAdd(ctx, "h2", "b", "c")
+	if err := c.PFMerge(ctx, "h3", "h1", "h2").Err(); err != nil {
+		t.Fatalf("PFMerge() error = %v", err)
+	}
+	if n, _ := c.PFCount(ctx, "h3").Result(); n != 3 {
+		t.Errorf("PFCount() = %d, want 3", n)
+	}
+	if _, err := c.PFCount(ctx, "b").Result(); !errors.Is(err, ErrWrongType) {
+		t.Errorf("PFCount() on string error = %v, want ErrWrongType", err)
+	}
+}
@@ -0,0 +1,252 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"github.com/ZampoRen/go-server-comon/internal/infra/cache"
+)
+
+// pipelineImpl 管道实现，命令先入队，Exec 时依次执行并回填结果
+// 与 Redis 管道一致，管道内的命令整体不是原子的
+type pipelineImpl struct {
+	m    *memoryImpl
+	cmds []cache.Cmder
+	runs []func()
+}
+
+// queue 入队一条命令，返回的结果对象在 Exec 之后才有值
+func queue[C any, PC interface {
+	*C
+	cache.Cmder
+}](p *pipelineImpl, run func() PC) PC {
+	res := PC(new(C))
+	p.cmds = append(p.cmds, res)
+	p.runs = append(p.runs, func() { *res = *run() })
+	return res
+}
+
+// Exec 执行管道中的所有命令，返回全部结果与第一个错误
+func (p *pipelineImpl) Exec(ctx context.Context) ([]cache.Cmder, error) {
+	cmds, runs := p.cmds, p.runs
+	p.cmds, p.runs = nil, nil
+
+	var firstErr error
+	for i, run := range runs {
+		run()
+		if err := cmds[i].Err(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return cmds, firstErr
+}
+
+// Set 入队 Set 命令
+func (p *pipelineImpl) Set(ctx context.Context, key string, value interface{}, expiration time.Duration) cache.StatusCmd {
+	return queue(p, func() *statusCmd { return p.m.Set(ctx, key, value, expiration).(*statusCmd) })
+}
+
+// Get 入队 Get 命令
+func (p *pipelineImpl) Get(ctx context.Context, key string) cache.StringCmd {
+	return queue(p, func() *stringCmd { return p.m.Get(ctx, key).(*stringCmd) })
+}
+
+// IncrBy 入队 IncrBy 命令
+func (p *pipelineImpl) IncrBy(ctx context.Context, key string, value int64) cache.IntCmd {
+	return queue(p, func() *intCmd { return p.m.IncrBy(ctx, key, value).(*intCmd) })
+}
+
+// Incr 入队 Incr 命令
+func (p *pipelineImpl) Incr(ctx context.Context, key string) cache.IntCmd {
+	return queue(p, func() *intCmd { return p.m.Incr(ctx, key).(*intCmd) })
+}
+
+// SetNX 入队 SetNX 命令
+func (p *pipelineImpl) SetNX(ctx context.Context, key string, value interface{}, expiration time.Duration) cache.BoolCmd {
+	return queue(p, func() *boolCmd { return p.m.SetNX(ctx, key, value, expiration).(*boolCmd) })
+}
+
+// SetXX 入队 SetXX 命令
+func (p *pipelineImpl) SetXX(ctx context.Context, key string, value interface{}, expiration time.Duration) cache.BoolCmd {
+	return queue(p, func() *boolCmd { return p.m.SetXX(ctx, key, value, expiration).(*boolCmd) })
+}
+
+// SetArgs 入队 SetArgs 命令
+func (p *pipelineImpl) SetArgs(ctx context.Context, key string, value interface{}, a cache.SetArgs) cache.StatusCmd {
+	return queue(p, func() *statusCmd { return p.m.SetArgs(ctx, key, value, a).(*statusCmd) })
+}
+
+// GetDel 入队 GetDel 命令
+func (p *pipelineImpl) GetDel(ctx context.Context, key string) cache.StringCmd {
+	return queue(p, func() *stringCmd { return p.m.GetDel(ctx, key).(*stringCmd) })
+}
+
+// GetEx 入队 GetEx 命令
+func (p *pipelineImpl) GetEx(ctx context.Context, key string, expiration time.Duration) cache.StringCmd {
+	return queue(p, func() *stringCmd { return p.m.GetEx(ctx, key, expiration).(*stringCmd) })
+}
+
+// MGet 入队 MGet 命令
+func (p *pipelineImpl) MGet(ctx context.Context, keys ...string) cache.SliceCmd {
+	return queue(p, func() *sliceCmd { return p.m.MGet(ctx, keys...).(*sliceCmd) })
+}
+
+// MSet 入队 MSet 命令
+func (p *pipelineImpl) MSet(ctx context.Context, values ...interface{}) cache.StatusCmd {
+	return queue(p, func() *statusCmd { return p.m.MSet(ctx, values...).(*statusCmd) })
+}
+
+// HSet 入队 HSet 命令
+func (p *pipelineImpl) HSet(ctx context.Context, key string, values ...interface{}) cache.IntCmd {
+	return queue(p, func() *intCmd { return p.m.HSet(ctx, key, values...).(*intCmd) })
+}
+
+// HGetAll 入队 HGetAll 命令
+func (p *pipelineImpl) HGetAll(ctx context.Context, key string) cache.MapStringStringCmd {
+	return queue(p, func() *mapStringStringCmd { return p.m.HGetAll(ctx, key).(*mapStringStringCmd) })
+}
+
+// HGet 入队 HGet 命令
+func (p *pipelineImpl) HGet(ctx context.Context, key, field string) cache.StringCmd {
+	return queue(p, func() *stringCmd { return p.m.HGet(ctx, key, field).(*stringCmd) })
+}
+
+// HMGet 入队 HMGet 命令
+func (p *pipelineImpl) HMGet(ctx context.Context, key string, fields ...string) cache.SliceCmd {
+	return queue(p, func() *sliceCmd { return p.m.HMGet(ctx, key, fields...).(*sliceCmd) })
+}
+
+// HDel 入队 HDel 命令
+func (p *pipelineImpl) HDel(ctx context.Context, key string, fields ...string) cache.IntCmd {
+	return queue(p, func() *intCmd { return p.m.HDel(ctx, key, fields...).(*intCmd) })
+}
+
+// HExists 入队 HExists 命令
+func (p *pipelineImpl) HExists(ctx context.Context, key, field string) cache.BoolCmd {
+	return queue(p, func() *boolCmd { return p.m.HExists(ctx, key, field).(*boolCmd) })
+}
+
+// HIncrBy 入队 HIncrBy 命令
+func (p *pipelineImpl) HIncrBy(ctx context.Context, key, field string, incr int64) cache.IntCmd {
+	return queue(p, func() *intCmd { return p.m.HIncrBy(ctx, key, field, incr).(*intCmd) })
+}
+
+// HLen 入队 HLen 命令
+func (p *pipelineImpl) HLen(ctx context.Context, key string) cache.IntCmd {
+	return queue(p, func() *intCmd { return p.m.HLen(ctx, key).(*intCmd) })
+}
+
+// HSetNX 入队 HSetNX 命令
+func (p *pipelineImpl) HSetNX(ctx context.Context, key, field string, value interface{}) cache.BoolCmd {
+	return queue(p, func() *boolCmd { return p.m.HSetNX(ctx, key, field, value).(*boolCmd) })
+}
+
+// Del 入队 Del 命令
+func (p *pipelineImpl) Del(ctx context.Context, keys ...string) cache.IntCmd {
+	return queue(p, func() *intCmd { return p.m.Del(ctx, keys...).(*intCmd) })
+}
+
+// Exists 入队 Exists 命令
+func (p *pipelineImpl) Exists(ctx context.Context, keys ...string) cache.IntCmd {
+	return queue(p, func() *intCmd { return p.m.Exists(ctx, keys...).(*intCmd) })
+}
+
+// Expire 入队 Expire 命令
+func (p *pipelineImpl) Expire(ctx context.Context, key string, expiration time.Duration) cache.BoolCmd {
+	return queue(p, func() *boolCmd { return p.m.Expire(ctx, key, expiration).(*boolCmd) })
+}
+
+// ExpireNX 入队 ExpireNX 命令
+func (p *pipelineImpl) ExpireNX(ctx context.Context, key string, expiration time.Duration) cache.BoolCmd {
+	return queue(p, func() *boolCmd { return p.m.ExpireNX(ctx, key, expiration).(*boolCmd) })
+}
+
+// ExpireXX 入队 ExpireXX 命令
+func (p *pipelineImpl) ExpireXX(ctx context.Context, key string, expiration time.Duration) cache.BoolCmd {
+	return queue(p, func() *boolCmd { return p.m.ExpireXX(ctx, key, expiration).(*boolCmd) })
+}
+
+// ExpireGT 入队 ExpireGT 命令
+func (p *pipelineImpl) ExpireGT(ctx context.Context, key string, expiration time.Duration) cache.BoolCmd {
+	return queue(p, func() *boolCmd { return p.m.ExpireGT(ctx, key, expiration).(*boolCmd) })
+}
+
+// ExpireLT 入队 ExpireLT 命令
+func (p *pipelineImpl) ExpireLT(ctx context.Context, key string, expiration time.Duration) cache.BoolCmd {
+	return queue(p, func() *boolCmd { return p.m.ExpireLT(ctx, key, expiration).(*boolCmd) })
+}
+
+// TTL 入队 TTL 命令
+func (p *pipelineImpl) TTL(ctx context.Context, key string) cache.DurationCmd {
+	return queue(p, func() *durationCmd { return p.m.TTL(ctx, key).(*durationCmd) })
+}
+
+// PTTL 入队 PTTL 命令
+func (p *pipelineImpl) PTTL(ctx context.Context, key string) cache.DurationCmd {
+	return queue(p, func() *durationCmd { return p.m.PTTL(ctx, key).(*durationCmd) })
+}
+
+// Persist 入队 Persist 命令
+func (p *pipelineImpl) Persist(ctx context.Context, key string) cache.BoolCmd {
+	return queue(p, func() *boolCmd { return p.m.Persist(ctx, key).(*boolCmd) })
+}
+
+// LIndex 入队 LIndex 命令
+func (p *pipelineImpl) LIndex(ctx context.Context, key string, index int64) cache.StringCmd {
+	return queue(p, func() *stringCmd { return p.m.LIndex(ctx, key, index).(*stringCmd) })
+}
+
+// LPush 入队 LPush 命令
+func (p *pipelineImpl) LPush(ctx context.Context, key string, values ...interface{}) cache.IntCmd {
+	return queue(p, func() *intCmd { return p.m.LPush(ctx, key, values...).(*intCmd) })
+}
+
+// RPush 入队 RPush 命令
+func (p *pipelineImpl) RPush(ctx context.Context, key string, values ...interface{}) cache.IntCmd {
+	return queue(p, func() *intCmd { return p.m.RPush(ctx, key, values...).(*intCmd) })
+}
+
+// LSet 入队 LSet 命令
+func (p *pipelineImpl) LSet(ctx context.Context, key string, index int64, value interface{}) cache.StatusCmd {
+	return queue(p, func() *statusCmd { return p.m.LSet(ctx, key, index, value).(*statusCmd) })
+}
+
+// LPop 入队 LPop 命令
+func (p *pipelineImpl) LPop(ctx context.Context, key string) cache.StringCmd {
+	return queue(p, func() *stringCmd { return p.m.LPop(ctx, key).(*stringCmd) })
+}
+
+// LRange 入队 LRange 命令
+func (p *pipelineImpl) LRange(ctx context.Context, key string, start, stop int64) cache.StringSliceCmd {
+	return queue(p, func() *stringSliceCmd { return p.m.LRange(ctx, key, start, stop).(*stringSliceCmd) })
+}
+
+// SetBit 入队 SetBit 命令
+func (p *pipelineImpl) SetBit(ctx context.Context, key string, offset int64, value int) cache.IntCmd {
+	return queue(p, func() *intCmd { return p.m.SetBit(ctx, key, offset, value).(*intCmd) })
+}
+
+// GetBit 入队 GetBit 命令
+func (p *pipelineImpl) GetBit(ctx context.Context, key string, offset int64) cache.IntCmd {
+	return queue(p, func() *intCmd { return p.m.GetBit(ctx, key, offset).(*intCmd) })
+}
+
+// BitCount 入队 BitCount 命令
+func (p *pipelineImpl) BitCount(ctx context.Context, key string, bitCount *cache.BitCount) cache.IntCmd {
+	return queue(p, func() *intCmd { return p.m.BitCount(ctx, key, bitCount).(*intCmd) })
+}
+
+// PFAdd 入队 PFAdd 命令
+func (p *pipelineImpl) PFAdd(ctx context.Context, key string, els ...interface{}) cache.IntCmd {
+	return queue(p, func() *intCmd { return p.m.PFAdd(ctx, key, els...).(*intCmd) })
+}
+
+// PFCount 入队 PFCount 命令
+func (p *pipelineImpl) PFCount(ctx context.Context, keys ...string) cache.IntCmd {
+	return queue(p, func() *intCmd { return p.m.PFCount(ctx, keys...).(*intCmd) })
+}
+
+// PFMerge 入队 PFMerge 命令
+func (p *pipelineImpl) PFMerge(ctx context.Context, dest string, keys ...string) cache.StatusCmd {
+	return queue(p, func() *statusCmd { return p.m.PFMerge(ctx, dest, keys...).(*statusCmd) })
+}
@@ -0,0 +1,86 @@
+package memory
+
+import (
+	"context"
+	"sync"
+
+	"github.com/ZampoRen/go-server-comon/internal/infra/cache"
+)
+
+// subscriberBuffer 每个订阅的消息缓冲，写满后新消息被丢弃，与 Redis 对慢消费者断开连接的效果类似
+const subscriberBuffer = 100
+
+// subscribers 频道到订阅的映射
+type subscribers struct {
+	mu       sync.Mutex
+	channels map[string]map[*subscription]struct{}
+}
+
+// Publish 向频道发布消息，同步投递给当前进程内的订阅
+func (m *memoryImpl) Publish(_ context.Context, channel string, message interface{}) (int64, error) {
+	payload, err := toString(message)
+	if err != nil {
+		return 0, err
+	}
+
+	subs := &m.db.subs
+	subs.mu.Lock()
+	defer subs.mu.Unlock()
+	var n int64
+	for s := range subs.channels[channel] {
+		select {
+		case s.ch <- &cache.Message{Channel: channel, Payload: payload}:
+		default:
+		}
+		n++
+	}
+	return n, nil
+}
+
+// Subscribe 订阅频道
+func (m *memoryImpl) Subscribe(_ context.Context, channels ...string) (cache.Subscription, error) {
+	s := &subscription{subs: &m.db.subs, channels: channels, ch: make(chan *cache.Message, subscriberBuffer)}
+
+	subs := &m.db.subs
+	subs.mu.Lock()
+	defer subs.mu.Unlock()
+	if subs.channels == nil {
+		subs.channels = make(map[string]map[*subscription]struct{})
+	}
+	for _, channel := range channels {
+		if subs.channels[channel] == nil {
+			subs.channels[channel] = make(map[*subscription]struct{})
+		}
+		subs.channels[channel][s] = struct{}{}
+	}
+	return s, nil
+}
+
+// subscription 进程内订阅
+type subscription struct {
+	subs     *subscribers
+	channels []string
+	ch       chan *cache.Message
+	once     sync.Once
+}
+
+// Channel 返回接收消息的通道
+func (s *subscription) Channel() <-chan *cache.Message {
+	return s.ch
+}
+
+// Close 取消订阅并关闭通道
+func (s *subscription) Close() error {
+	s.once.Do(func() {
+		s.subs.mu.Lock()
+		defer s.subs.mu.Unlock()
+		for _, channel := range s.channels {
+			delete(s.subs.channels[channel], s)
+			if len(s.subs.channels[channel]) == 0 {
+				delete(s.subs.channels, channel)
+			}
+		}
+		close(s.ch)
+	})
+	return nil
+}
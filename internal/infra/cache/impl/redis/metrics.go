@@ -0,0 +1,102 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"expvar"
+
+	"github.com/cloudwego/hertz/pkg/common/hlog"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/redis/go-redis/v9"
+
+	"github.com/ZampoRen/go-server-comon/internal/infra/cache"
+)
+
+// PoolStats 连接池统计
+type PoolStats = redis.PoolStats
+
+// GetPoolStats 返回 c 的连接池统计，c 不是由本包创建时返回 false
+func GetPoolStats(c cache.Cmdable) (*PoolStats, bool) {
+	r, ok := c.(*redisImpl)
+	if !ok {
+		return nil, false
+	}
+	return r.client.PoolStats(), true
+}
+
+// poolCollector 以 Prometheus 指标导出连接池统计
+type poolCollector struct {
+	client *redis.Client
+	name   string
+
+	hits       *prometheus.Desc
+	misses     *prometheus.Desc
+	timeouts   *prometheus.Desc
+	totalConns *prometheus.Desc
+	idleConns  *prometheus.Desc
+	staleConns *prometheus.Desc
+}
+
+var _ prometheus.Collector = (*poolCollector)(nil)
+
+func newPoolCollector(client *redis.Client, name string) *poolCollector {
+	labels := prometheus.Labels{"instance": name}
+	desc := func(metric, help string) *prometheus.Desc {
+		return prometheus.NewDesc(prometheus.BuildFQName("redis", "pool", metric), help, nil, labels)
+	}
+	return &poolCollector{
+		client:     client,
+		name:       name,
+		hits:       desc("hits_total", "Number of times a free connection was found in the pool."),
+		misses:     desc("misses_total", "Number of times a free connection was not found in the pool."),
+		timeouts:   desc("timeouts_total", "Number of times a wait timeout occurred."),
+		totalConns: desc("total_conns", "Number of total connections in the pool."),
+		idleConns:  desc("idle_conns", "Number of idle connections in the pool."),
+		staleConns: desc("stale_conns_total", "Number of stale connections removed from the pool."),
+	}
+}
+
+// Describe 实现 prometheus.Collector
+func (c *poolCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.hits
+	ch <- c.misses
+	ch <- c.timeouts
+	ch <- c.totalConns
+	ch <- c.idleConns
+	ch <- c.staleConns
+}
+
+// Collect 实现 prometheus.Collector，每次抓取时读取一次连接池统计
+func (c *poolCollector) Collect(ch chan<- prometheus.Metric) {
+	stats := c.client.PoolStats()
+	ch <- prometheus.MustNewConstMetric(c.hits, prometheus.CounterValue, float64(stats.Hits))
+	ch <- prometheus.MustNewConstMetric(c.misses, prometheus.CounterValue, float64(stats.Misses))
+	ch <- prometheus.MustNewConstMetric(c.timeouts, prometheus.CounterValue, float64(stats.Timeouts))
+	ch <- prometheus.MustNewConstMetric(c.totalConns, prometheus.GaugeValue, float64(stats.TotalConns))
+	ch <- prometheus.MustNewConstMetric(c.idleConns, prometheus.GaugeValue, float64(stats.IdleConns))
+	ch <- prometheus.MustNewConstMetric(c.staleConns, prometheus.CounterValue, float64(stats.StaleConns))
+}
+
+// registerMetrics 按选项注册 Prometheus 与 expvar 指标
+// 重复注册只打印告警，不影响客户端创建
+func registerMetrics(client *redis.Client, o *option) {
+	ctx := context.Background()
+
+	if o.registerer != nil {
+		err := o.registerer.Register(newPoolCollector(client, o.name))
+		var are prometheus.AlreadyRegisteredError
+		if err != nil && !errors.As(err, &are) {
+			hlog.CtxWarnf(ctx, "[Redis] register pool metrics for %s failed: %v", o.name, err)
+		}
+	}
+
+	if o.expvarName != "" {
+		if expvar.Get(o.expvarName) != nil {
+			hlog.CtxWarnf(ctx, "[Redis] expvar %s already published", o.expvarName)
+			return
+		}
+		expvar.Publish(o.expvarName, expvar.Func(func() any {
+			return client.PoolStats()
+		}))
+	}
+}
@@ -0,0 +1,55 @@
+package redis
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Option 客户端构造选项
+type Option func(o *option)
+
+type option struct {
+	name string
+
+	tracing        bool
+	tracerProvider trace.TracerProvider
+
+	registerer prometheus.Registerer
+	expvarName string
+}
+
+// WithName 设置实例名称，作为指标的 instance 标签与 span 属性，默认 default
+func WithName(name string) Option {
+	return func(o *option) {
+		if name != "" {
+			o.name = name
+		}
+	}
+}
+
+// WithTracing 为每条命令和每个管道创建 OpenTelemetry span
+// tp 为 nil 时使用全局 TracerProvider
+func WithTracing(tp trace.TracerProvider) Option {
+	return func(o *option) {
+		o.tracing = true
+		o.tracerProvider = tp
+	}
+}
+
+// WithPrometheus 将连接池统计注册为 Prometheus 指标
+// reg 为 nil 时注册到 prometheus.DefaultRegisterer
+func WithPrometheus(reg prometheus.Registerer) Option {
+	return func(o *option) {
+		if reg == nil {
+			reg = prometheus.DefaultRegisterer
+		}
+		o.registerer = reg
+	}
+}
+
+// WithExpvar 将连接池统计以 name 发布到 expvar（/debug/vars）
+func WithExpvar(name string) Option {
+	return func(o *option) {
+		o.expvarName = name
+	}
+}
@@ -0,0 +1,62 @@
+package redis
+
+import (
+	"context"
+	"sync"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/ZampoRen/go-server-comon/internal/infra/cache"
+)
+
+// Publish 向频道发布消息
+func (r *redisImpl) Publish(ctx context.Context, channel string, message interface{}) (int64, error) {
+	return r.client.Publish(ctx, channel, message).Result()
+}
+
+// Subscribe 订阅频道，等待服务端确认后返回；连接断开时 go-redis 会自动重连并重新订阅
+func (r *redisImpl) Subscribe(ctx context.Context, channels ...string) (cache.Subscription, error) {
+	ps := r.client.Subscribe(ctx, channels...)
+	if _, err := ps.Receive(ctx); err != nil {
+		_ = ps.Close()
+		return nil, err
+	}
+
+	s := &subscription{ps: ps, ch: make(chan *cache.Message, 100), done: make(chan struct{})}
+	go s.forward()
+	return s, nil
+}
+
+// subscription 将 go-redis 的消息转换为 cache.Message
+type subscription struct {
+	ps   *redis.PubSub
+	ch   chan *cache.Message
+	done chan struct{}
+	once sync.Once
+}
+
+func (s *subscription) forward() {
+	defer close(s.ch)
+	for msg := range s.ps.Channel() {
+		select {
+		case s.ch <- &cache.Message{Channel: msg.Channel, Payload: msg.Payload}:
+		case <-s.done:
+			return
+		}
+	}
+}
+
+// Channel 返回接收消息的通道
+func (s *subscription) Channel() <-chan *cache.Message {
+	return s.ch
+}
+
+// Close 取消订阅并关闭连接
+func (s *subscription) Close() error {
+	var err error
+	s.once.Do(func() {
+		close(s.done)
+		err = s.ps.Close()
+	})
+	return err
+}
@@ -26,18 +26,18 @@ type Cmdable = cache.Cmdable
 //   - REDIS_DIAL_TIMEOUT: 连接建立超时（默认 5s，格式如 "5s", "10s"）
 //   - REDIS_READ_TIMEOUT: 读操作超时（默认 3s，格式如 "3s", "5s"）
 //   - REDIS_WRITE_TIMEOUT: 写操作超时（默认 3s，格式如 "3s", "5s"）
-func New() cache.Cmdable {
+//
+// 链路追踪与连接池指标通过 opts 开启，见 WithTracing、WithPrometheus、WithExpvar
+func New(opts ...Option) cache.Cmdable {
 	addr := os.Getenv("REDIS_ADDR")
 	password := os.Getenv("REDIS_PASSWORD")
 
-	return NewWithAddrAndPassword(addr, password)
+	return NewWithAddrAndPassword(addr, password, opts...)
 }
 
 // NewWithAddrAndPassword 使用指定的地址和密码创建 Redis 客户端
 // 连接池和超时配置从环境变量读取，如果没有设置则使用默认值
-func NewWithAddrAndPassword(addr, password string) cache.Cmdable {
-	cache.SetDefaultNilError(redis.Nil)
-
+func NewWithAddrAndPassword(addr, password string, opts ...Option) cache.Cmdable {
 	// 从环境变量读取数据库编号（默认 0）
 	db := envkey.GetIntD("REDIS_DB", 0)
 
@@ -47,33 +47,14 @@ func NewWithAddrAndPassword(addr, password string) cache.Cmdable {
 	maxIdleConns := envkey.GetIntD("REDIS_MAX_IDLE_CONNS", 30)
 
 	// 从环境变量读取连接最大空闲时间（默认 5 分钟）
-	connMaxIdleTimeStr := envkey.GetStringD("REDIS_CONN_MAX_IDLE_TIME", "5m")
-	connMaxIdleTime, err := time.ParseDuration(connMaxIdleTimeStr)
-	if err != nil {
-		// 如果解析失败，使用默认值 5 分钟
-		connMaxIdleTime = 5 * time.Minute
-	}
+	connMaxIdleTime := envkey.GetDurationD("REDIS_CONN_MAX_IDLE_TIME", 5*time.Minute)
 
 	// 从环境变量读取超时配置
-	dialTimeoutStr := envkey.GetStringD("REDIS_DIAL_TIMEOUT", "5s")
-	dialTimeout, err := time.ParseDuration(dialTimeoutStr)
-	if err != nil {
-		dialTimeout = 5 * time.Second
-	}
-
-	readTimeoutStr := envkey.GetStringD("REDIS_READ_TIMEOUT", "3s")
-	readTimeout, err := time.ParseDuration(readTimeoutStr)
-	if err != nil {
-		readTimeout = 3 * time.Second
-	}
+	dialTimeout := envkey.GetDurationD("REDIS_DIAL_TIMEOUT", 5*time.Second)
+	readTimeout := envkey.GetDurationD("REDIS_READ_TIMEOUT", 3*time.Second)
+	writeTimeout := envkey.GetDurationD("REDIS_WRITE_TIMEOUT", 3*time.Second)
 
-	writeTimeoutStr := envkey.GetStringD("REDIS_WRITE_TIMEOUT", "3s")
-	writeTimeout, err := time.ParseDuration(writeTimeoutStr)
-	if err != nil {
-		writeTimeout = 3 * time.Second
-	}
-
-	rdb := redis.NewClient(&redis.Options{
+	return NewWithClientOptions(&redis.Options{
 		Addr:     addr,     // Redis 地址
 		DB:       db,       // 数据库编号
 		Password: password, // Redis 密码
@@ -87,7 +68,24 @@ func NewWithAddrAndPassword(addr, password string) cache.Cmdable {
 		DialTimeout:  dialTimeout,  // 连接建立超时
 		ReadTimeout:  readTimeout,  // 读操作超时
 		WriteTimeout: writeTimeout, // 写操作超时
-	})
+	}, opts...)
+}
+
+// NewWithClientOptions 使用 go-redis 的连接配置创建 Redis 客户端，不读取环境变量
+// 适用于配置来自配置文件等环境变量以外来源的场景
+func NewWithClientOptions(ro *redis.Options, opts ...Option) cache.Cmdable {
+	o := &option{name: "default"}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	cache.SetDefaultNilError(redis.Nil)
+
+	rdb := redis.NewClient(ro)
+	if o.tracing {
+		rdb.AddHook(newTracingHook(o.tracerProvider, o.name, rdb.Options()))
+	}
+	registerMetrics(rdb, o)
 
 	return &redisImpl{client: rdb}
 }
@@ -97,6 +95,11 @@ type redisImpl struct {
 	client *redis.Client
 }
 
+// Close 关闭客户端连接池
+func (r *redisImpl) Close() error {
+	return r.client.Close()
+}
+
 // Del 删除指定的键
 func (r *redisImpl) Del(ctx context.Context, keys ...string) cache.IntCmd {
 	return r.client.Del(ctx, keys...)
@@ -112,6 +115,41 @@ func (r *redisImpl) Expire(ctx context.Context, key string, expiration time.Dura
 	return r.client.Expire(ctx, key, expiration)
 }
 
+// ExpireNX 仅在键没有过期时间时设置过期时间
+func (r *redisImpl) ExpireNX(ctx context.Context, key string, expiration time.Duration) cache.BoolCmd {
+	return r.client.ExpireNX(ctx, key, expiration)
+}
+
+// ExpireXX 仅在键已有过期时间时设置过期时间
+func (r *redisImpl) ExpireXX(ctx context.Context, key string, expiration time.Duration) cache.BoolCmd {
+	return r.client.ExpireXX(ctx, key, expiration)
+}
+
+// ExpireGT 仅在新过期时间大于当前过期时间时设置
+func (r *redisImpl) ExpireGT(ctx context.Context, key string, expiration time.Duration) cache.BoolCmd {
+	return r.client.ExpireGT(ctx, key, expiration)
+}
+
+// ExpireLT 仅在新过期时间小于当前过期时间时设置
+func (r *redisImpl) ExpireLT(ctx context.Context, key string, expiration time.Duration) cache.BoolCmd {
+	return r.client.ExpireLT(ctx, key, expiration)
+}
+
+// TTL 返回键的剩余过期时间
+func (r *redisImpl) TTL(ctx context.Context, key string) cache.DurationCmd {
+	return r.client.TTL(ctx, key)
+}
+
+// PTTL 返回键的剩余过期时间（毫秒精度）
+func (r *redisImpl) PTTL(ctx context.Context, key string) cache.DurationCmd {
+	return r.client.PTTL(ctx, key)
+}
+
+// Persist 移除键的过期时间
+func (r *redisImpl) Persist(ctx context.Context, key string) cache.BoolCmd {
+	return r.client.Persist(ctx, key)
+}
+
 // Get 获取指定键的值
 func (r *redisImpl) Get(ctx context.Context, key string) cache.StringCmd {
 	return r.client.Get(ctx, key)
@@ -122,6 +160,41 @@ func (r *redisImpl) HGetAll(ctx context.Context, key string) cache.MapStringStri
 	return r.client.HGetAll(ctx, key)
 }
 
+// HGet 获取哈希表中指定字段的值
+func (r *redisImpl) HGet(ctx context.Context, key, field string) cache.StringCmd {
+	return r.client.HGet(ctx, key, field)
+}
+
+// HMGet 批量获取哈希表中多个字段的值
+func (r *redisImpl) HMGet(ctx context.Context, key string, fields ...string) cache.SliceCmd {
+	return r.client.HMGet(ctx, key, fields...)
+}
+
+// HDel 删除哈希表中的字段
+func (r *redisImpl) HDel(ctx context.Context, key string, fields ...string) cache.IntCmd {
+	return r.client.HDel(ctx, key, fields...)
+}
+
+// HExists 检查哈希表中字段是否存在
+func (r *redisImpl) HExists(ctx context.Context, key, field string) cache.BoolCmd {
+	return r.client.HExists(ctx, key, field)
+}
+
+// HIncrBy 将哈希表中字段的值增加指定的整数
+func (r *redisImpl) HIncrBy(ctx context.Context, key, field string, incr int64) cache.IntCmd {
+	return r.client.HIncrBy(ctx, key, field, incr)
+}
+
+// HLen 获取哈希表中字段的数量
+func (r *redisImpl) HLen(ctx context.Context, key string) cache.IntCmd {
+	return r.client.HLen(ctx, key)
+}
+
+// HSetNX 仅当字段不存在时设置哈希表字段的值
+func (r *redisImpl) HSetNX(ctx context.Context, key, field string, value interface{}) cache.BoolCmd {
+	return r.client.HSetNX(ctx, key, field, value)
+}
+
 // HSet 设置哈希表的字段值
 func (r *redisImpl) HSet(ctx context.Context, key string, values ...interface{}) cache.IntCmd {
 	return r.client.HSet(ctx, key, values...)
@@ -162,6 +235,27 @@ func (r *redisImpl) LSet(ctx context.Context, key string, index int64, value int
 	return r.client.LSet(ctx, key, index, value)
 }
 
+// Eval 执行 Lua 脚本
+func (r *redisImpl) Eval(ctx context.Context, script string, keys []string, args ...interface{}) cache.Cmd {
+	return r.client.Eval(ctx, script, keys, args...)
+}
+
+// Ping 检查与服务端的连通性
+func (r *redisImpl) Ping(ctx context.Context) cache.StatusCmd {
+	return r.client.Ping(ctx)
+}
+
+// PoolStats 返回连接池使用情况
+func (r *redisImpl) PoolStats() cache.PoolStats {
+	stats := r.client.PoolStats()
+	return cache.PoolStats{
+		TotalConns: int(stats.TotalConns),
+		IdleConns:  int(stats.IdleConns),
+		MaxConns:   r.client.Options().PoolSize,
+		Timeouts:   int64(stats.Timeouts),
+	}
+}
+
 // Pipeline 创建管道
 func (r *redisImpl) Pipeline() cache.Pipeliner {
 	p := r.client.Pipeline()
@@ -178,6 +272,41 @@ func (r *redisImpl) Set(ctx context.Context, key string, value interface{}, expi
 	return r.client.Set(ctx, key, value, expiration)
 }
 
+// SetNX 仅当键不存在时设置键的值
+func (r *redisImpl) SetNX(ctx context.Context, key string, value interface{}, expiration time.Duration) cache.BoolCmd {
+	return r.client.SetNX(ctx, key, value, expiration)
+}
+
+// SetXX 仅当键存在时设置键的值
+func (r *redisImpl) SetXX(ctx context.Context, key string, value interface{}, expiration time.Duration) cache.BoolCmd {
+	return r.client.SetXX(ctx, key, value, expiration)
+}
+
+// SetArgs 使用完整参数设置键的值
+func (r *redisImpl) SetArgs(ctx context.Context, key string, value interface{}, a cache.SetArgs) cache.StatusCmd {
+	return r.client.SetArgs(ctx, key, value, toRedisSetArgs(a))
+}
+
+// GetDel 获取键的值并删除该键
+func (r *redisImpl) GetDel(ctx context.Context, key string) cache.StringCmd {
+	return r.client.GetDel(ctx, key)
+}
+
+// GetEx 获取键的值并重新设置过期时间，expiration 为 0 时移除过期时间
+func (r *redisImpl) GetEx(ctx context.Context, key string, expiration time.Duration) cache.StringCmd {
+	return r.client.GetEx(ctx, key, expiration)
+}
+
+// MGet 批量获取多个键的值
+func (r *redisImpl) MGet(ctx context.Context, keys ...string) cache.SliceCmd {
+	return r.client.MGet(ctx, keys...)
+}
+
+// MSet 批量设置多个键的值
+func (r *redisImpl) MSet(ctx context.Context, values ...interface{}) cache.StatusCmd {
+	return r.client.MSet(ctx, values...)
+}
+
 // pipelineImpl 管道实现
 type pipelineImpl struct {
 	p redis.Pipeliner
@@ -226,6 +355,41 @@ func (p *pipelineImpl) Expire(ctx context.Context, key string, expiration time.D
 	return p.p.Expire(ctx, key, expiration)
 }
 
+// ExpireNX 仅在键没有过期时间时设置过期时间
+func (p *pipelineImpl) ExpireNX(ctx context.Context, key string, expiration time.Duration) cache.BoolCmd {
+	return p.p.ExpireNX(ctx, key, expiration)
+}
+
+// ExpireXX 仅在键已有过期时间时设置过期时间
+func (p *pipelineImpl) ExpireXX(ctx context.Context, key string, expiration time.Duration) cache.BoolCmd {
+	return p.p.ExpireXX(ctx, key, expiration)
+}
+
+// ExpireGT 仅在新过期时间大于当前过期时间时设置
+func (p *pipelineImpl) ExpireGT(ctx context.Context, key string, expiration time.Duration) cache.BoolCmd {
+	return p.p.ExpireGT(ctx, key, expiration)
+}
+
+// ExpireLT 仅在新过期时间小于当前过期时间时设置
+func (p *pipelineImpl) ExpireLT(ctx context.Context, key string, expiration time.Duration) cache.BoolCmd {
+	return p.p.ExpireLT(ctx, key, expiration)
+}
+
+// TTL 返回键的剩余过期时间
+func (p *pipelineImpl) TTL(ctx context.Context, key string) cache.DurationCmd {
+	return p.p.TTL(ctx, key)
+}
+
+// PTTL 返回键的剩余过期时间（毫秒精度）
+func (p *pipelineImpl) PTTL(ctx context.Context, key string) cache.DurationCmd {
+	return p.p.PTTL(ctx, key)
+}
+
+// Persist 移除键的过期时间
+func (p *pipelineImpl) Persist(ctx context.Context, key string) cache.BoolCmd {
+	return p.p.Persist(ctx, key)
+}
+
 // Get 获取指定键的值
 func (p *pipelineImpl) Get(ctx context.Context, key string) cache.StringCmd {
 	return p.p.Get(ctx, key)
@@ -236,6 +400,41 @@ func (p *pipelineImpl) HGetAll(ctx context.Context, key string) cache.MapStringS
 	return p.p.HGetAll(ctx, key)
 }
 
+// HGet 获取哈希表中指定字段的值
+func (p *pipelineImpl) HGet(ctx context.Context, key, field string) cache.StringCmd {
+	return p.p.HGet(ctx, key, field)
+}
+
+// HMGet 批量获取哈希表中多个字段的值
+func (p *pipelineImpl) HMGet(ctx context.Context, key string, fields ...string) cache.SliceCmd {
+	return p.p.HMGet(ctx, key, fields...)
+}
+
+// HDel 删除哈希表中的字段
+func (p *pipelineImpl) HDel(ctx context.Context, key string, fields ...string) cache.IntCmd {
+	return p.p.HDel(ctx, key, fields...)
+}
+
+// HExists 检查哈希表中字段是否存在
+func (p *pipelineImpl) HExists(ctx context.Context, key, field string) cache.BoolCmd {
+	return p.p.HExists(ctx, key, field)
+}
+
+// HIncrBy 将哈希表中字段的值增加指定的整数
+func (p *pipelineImpl) HIncrBy(ctx context.Context, key, field string, incr int64) cache.IntCmd {
+	return p.p.HIncrBy(ctx, key, field, incr)
+}
+
+// HLen 获取哈希表中字段的数量
+func (p *pipelineImpl) HLen(ctx context.Context, key string) cache.IntCmd {
+	return p.p.HLen(ctx, key)
+}
+
+// HSetNX 仅当字段不存在时设置哈希表字段的值
+func (p *pipelineImpl) HSetNX(ctx context.Context, key, field string, value interface{}) cache.BoolCmd {
+	return p.p.HSetNX(ctx, key, field, value)
+}
+
 // HSet 设置哈希表的字段值
 func (p *pipelineImpl) HSet(ctx context.Context, key string, values ...interface{}) cache.IntCmd {
 	return p.p.HSet(ctx, key, values...)
@@ -290,3 +489,116 @@ func (p *pipelineImpl) RPush(ctx context.Context, key string, values ...interfac
 func (p *pipelineImpl) Set(ctx context.Context, key string, value interface{}, expiration time.Duration) cache.StatusCmd {
 	return p.p.Set(ctx, key, value, expiration)
 }
+
+// SetNX 仅当键不存在时设置键的值
+func (p *pipelineImpl) SetNX(ctx context.Context, key string, value interface{}, expiration time.Duration) cache.BoolCmd {
+	return p.p.SetNX(ctx, key, value, expiration)
+}
+
+// SetXX 仅当键存在时设置键的值
+func (p *pipelineImpl) SetXX(ctx context.Context, key string, value interface{}, expiration time.Duration) cache.BoolCmd {
+	return p.p.SetXX(ctx, key, value, expiration)
+}
+
+// SetArgs 使用完整参数设置键的值
+func (p *pipelineImpl) SetArgs(ctx context.Context, key string, value interface{}, a cache.SetArgs) cache.StatusCmd {
+	return p.p.SetArgs(ctx, key, value, toRedisSetArgs(a))
+}
+
+// GetDel 获取键的值并删除该键
+func (p *pipelineImpl) GetDel(ctx context.Context, key string) cache.StringCmd {
+	return p.p.GetDel(ctx, key)
+}
+
+// GetEx 获取键的值并重新设置过期时间，expiration 为 0 时移除过期时间
+func (p *pipelineImpl) GetEx(ctx context.Context, key string, expiration time.Duration) cache.StringCmd {
+	return p.p.GetEx(ctx, key, expiration)
+}
+
+// MGet 批量获取多个键的值
+func (p *pipelineImpl) MGet(ctx context.Context, keys ...string) cache.SliceCmd {
+	return p.p.MGet(ctx, keys...)
+}
+
+// MSet 批量设置多个键的值
+func (p *pipelineImpl) MSet(ctx context.Context, values ...interface{}) cache.StatusCmd {
+	return p.p.MSet(ctx, values...)
+}
+
+// toRedisSetArgs 转换 SET 命令参数
+func toRedisSetArgs(a cache.SetArgs) redis.SetArgs {
+	return redis.SetArgs{
+		Mode:     a.Mode,
+		TTL:      a.TTL,
+		ExpireAt: a.ExpireAt,
+		Get:      a.Get,
+		KeepTTL:  a.KeepTTL,
+	}
+}
+
+// SetBit 设置位
+func (r *redisImpl) SetBit(ctx context.Context, key string, offset int64, value int) cache.IntCmd {
+	return r.client.SetBit(ctx, key, offset, value)
+}
+
+// GetBit 获取位
+func (r *redisImpl) GetBit(ctx context.Context, key string, offset int64) cache.IntCmd {
+	return r.client.GetBit(ctx, key, offset)
+}
+
+// BitCount 统计值为 1 的位数
+func (r *redisImpl) BitCount(ctx context.Context, key string, bitCount *cache.BitCount) cache.IntCmd {
+	return r.client.BitCount(ctx, key, toRedisBitCount(bitCount))
+}
+
+// PFAdd 向 HyperLogLog 添加元素
+func (r *redisImpl) PFAdd(ctx context.Context, key string, els ...interface{}) cache.IntCmd {
+	return r.client.PFAdd(ctx, key, els...)
+}
+
+// PFCount 返回 HyperLogLog 的基数估计值
+func (r *redisImpl) PFCount(ctx context.Context, keys ...string) cache.IntCmd {
+	return r.client.PFCount(ctx, keys...)
+}
+
+// PFMerge 合并 HyperLogLog
+func (r *redisImpl) PFMerge(ctx context.Context, dest string, keys ...string) cache.StatusCmd {
+	return r.client.PFMerge(ctx, dest, keys...)
+}
+
+// SetBit 设置位
+func (p *pipelineImpl) SetBit(ctx context.Context, key string, offset int64, value int) cache.IntCmd {
+	return p.p.SetBit(ctx, key, offset, value)
+}
+
+// GetBit 获取位
+func (p *pipelineImpl) GetBit(ctx context.Context, key string, offset int64) cache.IntCmd {
+	return p.p.GetBit(ctx, key, offset)
+}
+
+// BitCount 统计值为 1 的位数
+func (p *pipelineImpl) BitCount(ctx context.Context, key string, bitCount *cache.BitCount) cache.IntCmd {
+	return p.p.BitCount(ctx, key, toRedisBitCount(bitCount))
+}
+
+// PFAdd 向 HyperLogLog 添加元素
+func (p *pipelineImpl) PFAdd(ctx context.Context, key string, els ...interface{}) cache.IntCmd {
+	return p.p.PFAdd(ctx, key, els...)
+}
+
+// PFCount 返回 HyperLogLog 的基数估计值
+func (p *pipelineImpl) PFCount(ctx context.Context, keys ...string) cache.IntCmd {
+	return p.p.PFCount(ctx, keys...)
+}
+
+// PFMerge 合并 HyperLogLog
+func (p *pipelineImpl) PFMerge(ctx context.Context, dest string, keys ...string) cache.StatusCmd {
+	return p.p.PFMerge(ctx, dest, keys...)
+}
+
+func toRedisBitCount(b *cache.BitCount) *redis.BitCount {
+	if b == nil {
+		return nil
+	}
+	return &redis.BitCount{Start: b.Start, End: b.End}
+}
@@ -0,0 +1,122 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/ZampoRen/go-server-comon/internal/infra/cache"
+)
+
+// XAdd 向流追加消息，maxLen > 0 时使用 MAXLEN ~ 近似裁剪
+func (r *redisImpl) XAdd(ctx context.Context, stream string, maxLen int64, values map[string]interface{}) (string, error) {
+	return r.client.XAdd(ctx, &redis.XAddArgs{
+		Stream: stream,
+		MaxLen: maxLen,
+		Approx: maxLen > 0,
+		Values: values,
+	}).Result()
+}
+
+// XRead 阻塞读取流中 lastID 之后的消息
+func (r *redisImpl) XRead(ctx context.Context, stream, lastID string, count int64, block time.Duration) ([]cache.StreamMessage, error) {
+	res, err := r.client.XRead(ctx, &redis.XReadArgs{
+		Streams: []string{stream, lastID},
+		Count:   count,
+		Block:   block,
+	}).Result()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return fromXStreams(res), nil
+}
+
+// XGroupCreate 创建消费组，忽略消费组已存在的 BUSYGROUP 错误
+func (r *redisImpl) XGroupCreate(ctx context.Context, stream, group, startID string) error {
+	err := r.client.XGroupCreateMkStream(ctx, stream, group, startID).Err()
+	if err != nil && strings.HasPrefix(err.Error(), "BUSYGROUP") {
+		return nil
+	}
+	return err
+}
+
+// XReadGroup 阻塞读取消费组中尚未投递的消息
+func (r *redisImpl) XReadGroup(ctx context.Context, stream, group, consumer string, count int64, block time.Duration) ([]cache.StreamMessage, error) {
+	res, err := r.client.XReadGroup(ctx, &redis.XReadGroupArgs{
+		Group:    group,
+		Consumer: consumer,
+		Streams:  []string{stream, ">"},
+		Count:    count,
+		Block:    block,
+	}).Result()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return fromXStreams(res), nil
+}
+
+// XAck 确认消息
+func (r *redisImpl) XAck(ctx context.Context, stream, group string, ids ...string) error {
+	return r.client.XAck(ctx, stream, group, ids...).Err()
+}
+
+// XPending 返回空闲时间不少于 minIdle 的待确认消息
+func (r *redisImpl) XPending(ctx context.Context, stream, group string, minIdle time.Duration, count int64) ([]cache.PendingMessage, error) {
+	res, err := r.client.XPendingExt(ctx, &redis.XPendingExtArgs{
+		Stream: stream,
+		Group:  group,
+		Idle:   minIdle,
+		Start:  "-",
+		End:    "+",
+		Count:  count,
+	}).Result()
+	if err != nil {
+		return nil, err
+	}
+	pending := make([]cache.PendingMessage, 0, len(res))
+	for _, p := range res {
+		pending = append(pending, cache.PendingMessage{ID: p.ID, Consumer: p.Consumer, Idle: p.Idle, RetryCount: p.RetryCount})
+	}
+	return pending, nil
+}
+
+// XClaim 认领待确认消息
+func (r *redisImpl) XClaim(ctx context.Context, stream, group, consumer string, minIdle time.Duration, ids ...string) ([]cache.StreamMessage, error) {
+	res, err := r.client.XClaim(ctx, &redis.XClaimArgs{
+		Stream:   stream,
+		Group:    group,
+		Consumer: consumer,
+		MinIdle:  minIdle,
+		Messages: ids,
+	}).Result()
+	if err != nil {
+		return nil, err
+	}
+	return fromXMessages(res), nil
+}
+
+func fromXStreams(streams []redis.XStream) []cache.StreamMessage {
+	var msgs []cache.StreamMessage
+	for _, s := range streams {
+		msgs = append(msgs, fromXMessages(s.Messages)...)
+	}
+	return msgs
+}
+
+func fromXMessages(messages []redis.XMessage) []cache.StreamMessage {
+	msgs := make([]cache.StreamMessage, 0, len(messages))
+	for _, m := range messages {
+		msgs = append(msgs, cache.StreamMessage{ID: m.ID, Values: m.Values})
+	}
+	return msgs
+}
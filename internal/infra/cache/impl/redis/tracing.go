@@ -0,0 +1,105 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+
+	"github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	tracerName = "github.com/ZampoRen/go-server-comon/internal/infra/cache/impl/redis"
+	// maxPipelineOps span 中最多记录的管道命令名数量
+	maxPipelineOps = 10
+)
+
+// tracingHook 为命令与管道创建 span
+// 只记录命令名，不记录参数，避免把缓存内容写入链路数据
+type tracingHook struct {
+	tracer trace.Tracer
+	attrs  []attribute.KeyValue
+}
+
+var _ redis.Hook = (*tracingHook)(nil)
+
+func newTracingHook(tp trace.TracerProvider, name string, opt *redis.Options) *tracingHook {
+	if tp == nil {
+		tp = otel.GetTracerProvider()
+	}
+
+	attrs := []attribute.KeyValue{
+		attribute.String("db.system", "redis"),
+		attribute.Int("db.redis.database_index", opt.DB),
+		attribute.String("db.instance", name),
+	}
+	if host, port, err := net.SplitHostPort(opt.Addr); err == nil {
+		attrs = append(attrs, attribute.String("server.address", host), attribute.String("server.port", port))
+	}
+
+	return &tracingHook{
+		tracer: tp.Tracer(tracerName),
+		attrs:  attrs,
+	}
+}
+
+// DialHook 连接建立不单独创建 span
+func (h *tracingHook) DialHook(next redis.DialHook) redis.DialHook {
+	return next
+}
+
+// ProcessHook 为单条命令创建 span
+func (h *tracingHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		name := cmd.Name()
+		ctx, span := h.tracer.Start(ctx, "redis."+name,
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(h.attrs...),
+			trace.WithAttributes(attribute.String("db.operation", name)),
+		)
+		defer span.End()
+
+		err := next(ctx, cmd)
+		recordError(span, err)
+		return err
+	}
+}
+
+// ProcessPipelineHook 为整个管道创建一个 span，记录命令数与前若干个命令名
+func (h *tracingHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		ops := make([]string, 0, min(len(cmds), maxPipelineOps))
+		for i := 0; i < len(cmds) && i < maxPipelineOps; i++ {
+			ops = append(ops, cmds[i].Name())
+		}
+
+		ctx, span := h.tracer.Start(ctx, "redis.pipeline",
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(h.attrs...),
+			trace.WithAttributes(
+				attribute.String("db.operation", "pipeline"),
+				attribute.Int("db.redis.num_cmd", len(cmds)),
+				attribute.String("db.redis.cmds", strings.Join(ops, " ")),
+			),
+		)
+		defer span.End()
+
+		err := next(ctx, cmds)
+		recordError(span, err)
+		return err
+	}
+}
+
+// recordError 记录错误，redis.Nil 表示键不存在，不视为错误
+func recordError(span trace.Span, err error) {
+	if err == nil || errors.Is(err, redis.Nil) {
+		return
+	}
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+}
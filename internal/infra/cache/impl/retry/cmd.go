@@ -0,0 +1,439 @@
+package retry
+
+import (
+	"context"
+	"time"
+
+	"github.com/ZampoRen/go-server-comon/internal/infra/cache"
+)
+
+// Set 执行 SET 命令
+func (r *retryImpl) Set(ctx context.Context, key string, value interface{}, expiration time.Duration) cache.StatusCmd {
+	var res cache.StatusCmd
+	r.do(ctx, "set", true, func(ctx context.Context) error {
+		res = r.c.Set(ctx, key, value, expiration)
+		return res.Err()
+	})
+	return res
+}
+
+// Get 执行 GET 命令
+func (r *retryImpl) Get(ctx context.Context, key string) cache.StringCmd {
+	var res cache.StringCmd
+	r.do(ctx, "get", true, func(ctx context.Context) error {
+		res = r.c.Get(ctx, key)
+		return res.Err()
+	})
+	return res
+}
+
+// IncrBy 执行 INCRBY 命令
+func (r *retryImpl) IncrBy(ctx context.Context, key string, value int64) cache.IntCmd {
+	var res cache.IntCmd
+	r.do(ctx, "incrby", false, func(ctx context.Context) error {
+		res = r.c.IncrBy(ctx, key, value)
+		return res.Err()
+	})
+	return res
+}
+
+// Incr 执行 INCR 命令
+func (r *retryImpl) Incr(ctx context.Context, key string) cache.IntCmd {
+	var res cache.IntCmd
+	r.do(ctx, "incr", false, func(ctx context.Context) error {
+		res = r.c.Incr(ctx, key)
+		return res.Err()
+	})
+	return res
+}
+
+// SetNX 执行 SETNX 命令
+// 结果取决于执行前的状态，第一次已写入但响应丢失时重试会返回 false，因此按非幂等命令处理
+func (r *retryImpl) SetNX(ctx context.Context, key string, value interface{}, expiration time.Duration) cache.BoolCmd {
+	var res cache.BoolCmd
+	r.do(ctx, "setnx", false, func(ctx context.Context) error {
+		res = r.c.SetNX(ctx, key, value, expiration)
+		return res.Err()
+	})
+	return res
+}
+
+// SetXX 执行 SETXX 命令，同 SetNX 按非幂等命令处理
+func (r *retryImpl) SetXX(ctx context.Context, key string, value interface{}, expiration time.Duration) cache.BoolCmd {
+	var res cache.BoolCmd
+	r.do(ctx, "setxx", false, func(ctx context.Context) error {
+		res = r.c.SetXX(ctx, key, value, expiration)
+		return res.Err()
+	})
+	return res
+}
+
+// SetArgs 执行 SET 命令，带 NX、XX 或 GET 时结果取决于执行前的状态，按非幂等命令处理
+func (r *retryImpl) SetArgs(ctx context.Context, key string, value interface{}, a cache.SetArgs) cache.StatusCmd {
+	var res cache.StatusCmd
+	r.do(ctx, "set", a.Mode == "" && !a.Get, func(ctx context.Context) error {
+		res = r.c.SetArgs(ctx, key, value, a)
+		return res.Err()
+	})
+	return res
+}
+
+// GetDel 执行 GETDEL 命令
+func (r *retryImpl) GetDel(ctx context.Context, key string) cache.StringCmd {
+	var res cache.StringCmd
+	r.do(ctx, "getdel", false, func(ctx context.Context) error {
+		res = r.c.GetDel(ctx, key)
+		return res.Err()
+	})
+	return res
+}
+
+// GetEx 执行 GETEX 命令
+func (r *retryImpl) GetEx(ctx context.Context, key string, expiration time.Duration) cache.StringCmd {
+	var res cache.StringCmd
+	r.do(ctx, "getex", true, func(ctx context.Context) error {
+		res = r.c.GetEx(ctx, key, expiration)
+		return res.Err()
+	})
+	return res
+}
+
+// MGet 执行 MGET 命令
+func (r *retryImpl) MGet(ctx context.Context, keys ...string) cache.SliceCmd {
+	var res cache.SliceCmd
+	r.do(ctx, "mget", true, func(ctx context.Context) error {
+		res = r.c.MGet(ctx, keys...)
+		return res.Err()
+	})
+	return res
+}
+
+// MSet 执行 MSET 命令
+func (r *retryImpl) MSet(ctx context.Context, values ...interface{}) cache.StatusCmd {
+	var res cache.StatusCmd
+	r.do(ctx, "mset", true, func(ctx context.Context) error {
+		res = r.c.MSet(ctx, values...)
+		return res.Err()
+	})
+	return res
+}
+
+// HSet 执行 HSET 命令
+func (r *retryImpl) HSet(ctx context.Context, key string, values ...interface{}) cache.IntCmd {
+	var res cache.IntCmd
+	r.do(ctx, "hset", true, func(ctx context.Context) error {
+		res = r.c.HSet(ctx, key, values...)
+		return res.Err()
+	})
+	return res
+}
+
+// HGetAll 执行 HGETALL 命令
+func (r *retryImpl) HGetAll(ctx context.Context, key string) cache.MapStringStringCmd {
+	var res cache.MapStringStringCmd
+	r.do(ctx, "hgetall", true, func(ctx context.Context) error {
+		res = r.c.HGetAll(ctx, key)
+		return res.Err()
+	})
+	return res
+}
+
+// HGet 执行 HGET 命令
+func (r *retryImpl) HGet(ctx context.Context, key, field string) cache.StringCmd {
+	var res cache.StringCmd
+	r.do(ctx, "hget", true, func(ctx context.Context) error {
+		res = r.c.HGet(ctx, key, field)
+		return res.Err()
+	})
+	return res
+}
+
+// HMGet 执行 HMGET 命令
+func (r *retryImpl) HMGet(ctx context.Context, key string, fields ...string) cache.SliceCmd {
+	var res cache.SliceCmd
+	r.do(ctx, "hmget", true, func(ctx context.Context) error {
+		res = r.c.HMGet(ctx, key, fields...)
+		return res.Err()
+	})
+	return res
+}
+
+// HDel 执行 HDEL 命令
+func (r *retryImpl) HDel(ctx context.Context, key string, fields ...string) cache.IntCmd {
+	var res cache.IntCmd
+	r.do(ctx, "hdel", true, func(ctx context.Context) error {
+		res = r.c.HDel(ctx, key, fields...)
+		return res.Err()
+	})
+	return res
+}
+
+// HExists 执行 HEXISTS 命令
+func (r *retryImpl) HExists(ctx context.Context, key, field string) cache.BoolCmd {
+	var res cache.BoolCmd
+	r.do(ctx, "hexists", true, func(ctx context.Context) error {
+		res = r.c.HExists(ctx, key, field)
+		return res.Err()
+	})
+	return res
+}
+
+// HIncrBy 执行 HINCRBY 命令
+func (r *retryImpl) HIncrBy(ctx context.Context, key, field string, incr int64) cache.IntCmd {
+	var res cache.IntCmd
+	r.do(ctx, "hincrby", false, func(ctx context.Context) error {
+		res = r.c.HIncrBy(ctx, key, field, incr)
+		return res.Err()
+	})
+	return res
+}
+
+// HLen 执行 HLEN 命令
+func (r *retryImpl) HLen(ctx context.Context, key string) cache.IntCmd {
+	var res cache.IntCmd
+	r.do(ctx, "hlen", true, func(ctx context.Context) error {
+		res = r.c.HLen(ctx, key)
+		return res.Err()
+	})
+	return res
+}
+
+// HSetNX 执行 HSETNX 命令，同 SetNX 按非幂等命令处理
+func (r *retryImpl) HSetNX(ctx context.Context, key, field string, value interface{}) cache.BoolCmd {
+	var res cache.BoolCmd
+	r.do(ctx, "hsetnx", false, func(ctx context.Context) error {
+		res = r.c.HSetNX(ctx, key, field, value)
+		return res.Err()
+	})
+	return res
+}
+
+// Del 执行 DEL 命令
+func (r *retryImpl) Del(ctx context.Context, keys ...string) cache.IntCmd {
+	var res cache.IntCmd
+	r.do(ctx, "del", true, func(ctx context.Context) error {
+		res = r.c.Del(ctx, keys...)
+		return res.Err()
+	})
+	return res
+}
+
+// Exists 执行 EXISTS 命令
+func (r *retryImpl) Exists(ctx context.Context, keys ...string) cache.IntCmd {
+	var res cache.IntCmd
+	r.do(ctx, "exists", true, func(ctx context.Context) error {
+		res = r.c.Exists(ctx, keys...)
+		return res.Err()
+	})
+	return res
+}
+
+// Expire 执行 EXPIRE 命令
+func (r *retryImpl) Expire(ctx context.Context, key string, expiration time.Duration) cache.BoolCmd {
+	var res cache.BoolCmd
+	r.do(ctx, "expire", true, func(ctx context.Context) error {
+		res = r.c.Expire(ctx, key, expiration)
+		return res.Err()
+	})
+	return res
+}
+
+// ExpireNX 执行 EXPIRENX 命令
+func (r *retryImpl) ExpireNX(ctx context.Context, key string, expiration time.Duration) cache.BoolCmd {
+	var res cache.BoolCmd
+	r.do(ctx, "expirenx", true, func(ctx context.Context) error {
+		res = r.c.ExpireNX(ctx, key, expiration)
+		return res.Err()
+	})
+	return res
+}
+
+// ExpireXX 执行 EXPIREXX 命令
+func (r *retryImpl) ExpireXX(ctx context.Context, key string, expiration time.Duration) cache.BoolCmd {
+	var res cache.BoolCmd
+	r.do(ctx, "expirexx", true, func(ctx context.Context) error {
+		res = r.c.ExpireXX(ctx, key, expiration)
+		return res.Err()
+	})
+	return res
+}
+
+// ExpireGT 执行 EXPIREGT 命令
+func (r *retryImpl) ExpireGT(ctx context.Context, key string, expiration time.Duration) cache.BoolCmd {
+	var res cache.BoolCmd
+	r.do(ctx, "expiregt", true, func(ctx context.Context) error {
+		res = r.c.ExpireGT(ctx, key, expiration)
+		return res.Err()
+	})
+	return res
+}
+
+// ExpireLT 执行 EXPIRELT 命令
+func (r *retryImpl) ExpireLT(ctx context.Context, key string, expiration time.Duration) cache.BoolCmd {
+	var res cache.BoolCmd
+	r.do(ctx, "expirelt", true, func(ctx context.Context) error {
+		res = r.c.ExpireLT(ctx, key, expiration)
+		return res.Err()
+	})
+	return res
+}
+
+// TTL 执行 TTL 命令
+func (r *retryImpl) TTL(ctx context.Context, key string) cache.DurationCmd {
+	var res cache.DurationCmd
+	r.do(ctx, "ttl", true, func(ctx context.Context) error {
+		res = r.c.TTL(ctx, key)
+		return res.Err()
+	})
+	return res
+}
+
+// PTTL 执行 PTTL 命令
+func (r *retryImpl) PTTL(ctx context.Context, key string) cache.DurationCmd {
+	var res cache.DurationCmd
+	r.do(ctx, "pttl", true, func(ctx context.Context) error {
+		res = r.c.PTTL(ctx, key)
+		return res.Err()
+	})
+	return res
+}
+
+// Persist 执行 PERSIST 命令
+func (r *retryImpl) Persist(ctx context.Context, key string) cache.BoolCmd {
+	var res cache.BoolCmd
+	r.do(ctx, "persist", true, func(ctx context.Context) error {
+		res = r.c.Persist(ctx, key)
+		return res.Err()
+	})
+	return res
+}
+
+// LIndex 执行 LINDEX 命令
+func (r *retryImpl) LIndex(ctx context.Context, key string, index int64) cache.StringCmd {
+	var res cache.StringCmd
+	r.do(ctx, "lindex", true, func(ctx context.Context) error {
+		res = r.c.LIndex(ctx, key, index)
+		return res.Err()
+	})
+	return res
+}
+
+// LPush 执行 LPUSH 命令
+func (r *retryImpl) LPush(ctx context.Context, key string, values ...interface{}) cache.IntCmd {
+	var res cache.IntCmd
+	r.do(ctx, "lpush", false, func(ctx context.Context) error {
+		res = r.c.LPush(ctx, key, values...)
+		return res.Err()
+	})
+	return res
+}
+
+// RPush 执行 RPUSH 命令
+func (r *retryImpl) RPush(ctx context.Context, key string, values ...interface{}) cache.IntCmd {
+	var res cache.IntCmd
+	r.do(ctx, "rpush", false, func(ctx context.Context) error {
+		res = r.c.RPush(ctx, key, values...)
+		return res.Err()
+	})
+	return res
+}
+
+// LSet 执行 LSET 命令
+func (r *retryImpl) LSet(ctx context.Context, key string, index int64, value interface{}) cache.StatusCmd {
+	var res cache.StatusCmd
+	r.do(ctx, "lset", true, func(ctx context.Context) error {
+		res = r.c.LSet(ctx, key, index, value)
+		return res.Err()
+	})
+	return res
+}
+
+// LPop 执行 LPOP 命令
+func (r *retryImpl) LPop(ctx context.Context, key string) cache.StringCmd {
+	var res cache.StringCmd
+	r.do(ctx, "lpop", false, func(ctx context.Context) error {
+		res = r.c.LPop(ctx, key)
+		return res.Err()
+	})
+	return res
+}
+
+// LRange 执行 LRANGE 命令
+func (r *retryImpl) LRange(ctx context.Context, key string, start, stop int64) cache.StringSliceCmd {
+	var res cache.StringSliceCmd
+	r.do(ctx, "lrange", true, func(ctx context.Context) error {
+		res = r.c.LRange(ctx, key, start, stop)
+		return res.Err()
+	})
+	return res
+}
+
+// Eval 执行 EVAL 命令
+func (r *retryImpl) Eval(ctx context.Context, script string, keys []string, args ...interface{}) cache.Cmd {
+	var res cache.Cmd
+	r.do(ctx, "eval", false, func(ctx context.Context) error {
+		res = r.c.Eval(ctx, script, keys, args...)
+		return res.Err()
+	})
+	return res
+}
+
+// SetBit 执行 SETBIT 命令
+func (r *retryImpl) SetBit(ctx context.Context, key string, offset int64, value int) cache.IntCmd {
+	var res cache.IntCmd
+	r.do(ctx, "setbit", true, func(ctx context.Context) error {
+		res = r.c.SetBit(ctx, key, offset, value)
+		return res.Err()
+	})
+	return res
+}
+
+// GetBit 执行 GETBIT 命令
+func (r *retryImpl) GetBit(ctx context.Context, key string, offset int64) cache.IntCmd {
+	var res cache.IntCmd
+	r.do(ctx, "getbit", true, func(ctx context.Context) error {
+		res = r.c.GetBit(ctx, key, offset)
+		return res.Err()
+	})
+	return res
+}
+
+// BitCount 执行 BITCOUNT 命令
+func (r *retryImpl) BitCount(ctx context.Context, key string, bitCount *cache.BitCount) cache.IntCmd {
+	var res cache.IntCmd
+	r.do(ctx, "bitcount", true, func(ctx context.Context) error {
+		res = r.c.BitCount(ctx, key, bitCount)
+		return res.Err()
+	})
+	return res
+}
+
+// PFAdd 执行 PFADD 命令
+func (r *retryImpl) PFAdd(ctx context.Context, key string, els ...interface{}) cache.IntCmd {
+	var res cache.IntCmd
+	r.do(ctx, "pfadd", true, func(ctx context.Context) error {
+		res = r.c.PFAdd(ctx, key, els...)
+		return res.Err()
+	})
+	return res
+}
+
+// PFCount 执行 PFCOUNT 命令
+func (r *retryImpl) PFCount(ctx context.Context, keys ...string) cache.IntCmd {
+	var res cache.IntCmd
+	r.do(ctx, "pfcount", true, func(ctx context.Context) error {
+		res = r.c.PFCount(ctx, keys...)
+		return res.Err()
+	})
+	return res
+}
+
+// PFMerge 执行 PFMERGE 命令
+func (r *retryImpl) PFMerge(ctx context.Context, dest string, keys ...string) cache.StatusCmd {
+	var res cache.StatusCmd
+	r.do(ctx, "pfmerge", true, func(ctx context.Context) error {
+		res = r.c.PFMerge(ctx, dest, keys...)
+		return res.Err()
+	})
+	return res
+}
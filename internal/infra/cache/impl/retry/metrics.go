@@ -0,0 +1,54 @@
+package retry
+
+import (
+	"errors"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// metrics 重试相关指标，为 nil 时所有方法都是空操作
+type metrics struct {
+	retries  *prometheus.CounterVec
+	exhausts *prometheus.CounterVec
+}
+
+func newMetrics(reg prometheus.Registerer) *metrics {
+	return &metrics{
+		retries: registerCounterVec(reg, prometheus.CounterOpts{
+			Namespace: "cache",
+			Name:      "retries_total",
+			Help:      "Number of cache command retries.",
+		}),
+		exhausts: registerCounterVec(reg, prometheus.CounterOpts{
+			Namespace: "cache",
+			Name:      "retry_exhausted_total",
+			Help:      "Number of cache commands that still failed after all retries.",
+		}),
+	}
+}
+
+// registerCounterVec 注册计数器，已注册时复用已有的计数器，便于多个装饰器共享指标
+func registerCounterVec(reg prometheus.Registerer, opts prometheus.CounterOpts) *prometheus.CounterVec {
+	cv := prometheus.NewCounterVec(opts, []string{"command"})
+	if err := reg.Register(cv); err != nil {
+		var are prometheus.AlreadyRegisteredError
+		if errors.As(err, &are) {
+			if existing, ok := are.ExistingCollector.(*prometheus.CounterVec); ok {
+				return existing
+			}
+		}
+	}
+	return cv
+}
+
+func (m *metrics) retry(command string) {
+	if m != nil {
+		m.retries.WithLabelValues(command).Inc()
+	}
+}
+
+func (m *metrics) exhausted(command string) {
+	if m != nil {
+		m.exhausts.WithLabelValues(command).Inc()
+	}
+}
@@ -0,0 +1,299 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/ZampoRen/go-server-comon/internal/infra/cache"
+	"github.com/ZampoRen/go-server-comon/pkg/retry"
+)
+
+// Option 重试策略选项
+type Option func(o *option)
+
+type option struct {
+	maxRetries      int
+	minBackoff      time.Duration
+	maxBackoff      time.Duration
+	timeout         time.Duration
+	retryNonIdem    bool
+	retryable       func(err error) bool
+	registerer      prometheus.Registerer
+	pipelineTimeout time.Duration
+}
+
+// WithMaxRetries 设置最大重试次数（不含首次执行），默认 2，为 0 时不重试
+func WithMaxRetries(n int) Option {
+	return func(o *option) {
+		if n >= 0 {
+			o.maxRetries = n
+		}
+	}
+}
+
+// WithBackoff 设置指数退避的初始间隔与最大间隔，默认 8ms 与 512ms
+func WithBackoff(min, max time.Duration) Option {
+	return func(o *option) {
+		if min > 0 {
+			o.minBackoff = min
+		}
+		if max > 0 {
+			o.maxBackoff = max
+		}
+	}
+}
+
+// WithTimeout 设置单次命令执行的超时时间（每次重试单独计时），为 0 时不额外限制
+func WithTimeout(d time.Duration) Option {
+	return func(o *option) {
+		o.timeout = d
+	}
+}
+
+// WithPipelineTimeout 设置管道 Exec 的超时时间，默认与 WithTimeout 相同
+// 管道内可能包含非幂等命令且 Exec 之后命令队列已清空，因此管道不重试
+func WithPipelineTimeout(d time.Duration) Option {
+	return func(o *option) {
+		o.pipelineTimeout = d
+	}
+}
+
+// WithRetryNonIdempotent 是否重试非幂等命令（INCR、LPUSH、EVAL 以及 SETNX 等条件写入），默认 false
+// 网络错误时无法确定命令是否已在服务端执行，重试可能导致重复执行
+func WithRetryNonIdempotent(enable bool) Option {
+	return func(o *option) {
+		o.retryNonIdem = enable
+	}
+}
+
+// WithRetryable 自定义可重试错误的判断，默认为 IsTransient
+func WithRetryable(fn func(err error) bool) Option {
+	return func(o *option) {
+		if fn != nil {
+			o.retryable = fn
+		}
+	}
+}
+
+// WithMetrics 将重试次数注册为 Prometheus 指标 cache_retries_total 与 cache_retry_exhausted_total
+// reg 为 nil 时注册到 prometheus.DefaultRegisterer
+func WithMetrics(reg prometheus.Registerer) Option {
+	return func(o *option) {
+		if reg == nil {
+			reg = prometheus.DefaultRegisterer
+		}
+		o.registerer = reg
+	}
+}
+
+// IsTransient 判断错误是否为可重试的瞬时错误：网络错误、连接中断、
+// 单次命令超时以及 Redis 的 LOADING/READONLY/TRYAGAIN/CLUSTERDOWN/MASTERDOWN 错误
+// 调用方 ctx 的取消或超时不视为瞬时错误
+func IsTransient(err error) bool {
+	if err == nil || errors.Is(err, cache.Nil) || errors.Is(err, context.Canceled) {
+		return false
+	}
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, syscall.EPIPE) {
+		return true
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+
+	msg := err.Error()
+	for _, prefix := range []string{"LOADING ", "READONLY ", "TRYAGAIN ", "CLUSTERDOWN ", "MASTERDOWN "} {
+		if strings.HasPrefix(msg, prefix) {
+			return true
+		}
+	}
+	return strings.Contains(msg, "connection pool timeout") || strings.Contains(msg, "use of closed network connection")
+}
+
+// retryImpl 为每条命令增加超时与重试的 cache.Cmdable 装饰器
+type retryImpl struct {
+	c       cache.Cmdable
+	o       *option
+	metrics *metrics
+}
+
+// New 用重试与超时策略包装 c
+// go-redis 自身的重试与超时只能在创建连接时配置，且对所有命令一视同仁；
+// 该装饰器按命令生效，非幂等命令默认只执行一次
+func New(c cache.Cmdable, opts ...Option) cache.Cmdable {
+	o := &option{
+		maxRetries: 2,
+		minBackoff: 8 * time.Millisecond,
+		maxBackoff: 512 * time.Millisecond,
+		retryable:  IsTransient,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	if o.pipelineTimeout == 0 {
+		o.pipelineTimeout = o.timeout
+	}
+
+	r := &retryImpl{c: c, o: o}
+	if o.registerer != nil {
+		r.metrics = newMetrics(o.registerer)
+	}
+	return r
+}
+
+// do 执行命令，命令失败且错误可重试时按指数退避重试
+// 调用方 ctx 结束时立即返回最后一次的结果
+func (r *retryImpl) do(ctx context.Context, name string, idempotent bool, run func(ctx context.Context) error) {
+	maxRetries := r.o.maxRetries
+	if !idempotent && !r.o.retryNonIdem {
+		maxRetries = 0
+	}
+
+	retries := 0
+	err := retry.Do(ctx, run,
+		retry.WithMaxAttempts(maxRetries+1),
+		retry.WithBackoff(r.o.minBackoff, r.o.maxBackoff),
+		retry.WithAttemptTimeout(r.o.timeout),
+		retry.WithRetryIf(r.o.retryable),
+		retry.WithOnRetry(func(int, error, time.Duration) {
+			retries++
+			r.metrics.retry(name)
+		}),
+	)
+	if err != nil && retries > 0 && retries == maxRetries && ctx.Err() == nil && r.o.retryable(err) {
+		r.metrics.exhausted(name)
+	}
+}
+
+// Ping 检查连通性，不重试，以便健康检查如实反映当前状态
+func (r *retryImpl) Ping(ctx context.Context) cache.StatusCmd {
+	var res cache.StatusCmd
+	r.do(ctx, "ping", false, func(ctx context.Context) error {
+		res = r.c.Ping(ctx)
+		return res.Err()
+	})
+	return res
+}
+
+// PoolStats 透传被包装实例的连接池使用情况
+func (r *retryImpl) PoolStats() cache.PoolStats {
+	if ps, ok := r.c.(cache.PoolStatser); ok {
+		return ps.PoolStats()
+	}
+	return cache.PoolStats{}
+}
+
+// Publish 透传被包装实例的发布，不重试，避免订阅者重复收到消息
+// 被包装实例不支持发布订阅时返回 cache.ErrPubSubNotSupported
+func (r *retryImpl) Publish(ctx context.Context, channel string, message interface{}) (int64, error) {
+	ps, ok := r.c.(cache.PubSub)
+	if !ok {
+		return 0, cache.ErrPubSubNotSupported
+	}
+	return ps.Publish(ctx, channel, message)
+}
+
+// Subscribe 透传被包装实例的订阅
+func (r *retryImpl) Subscribe(ctx context.Context, channels ...string) (cache.Subscription, error) {
+	ps, ok := r.c.(cache.PubSub)
+	if !ok {
+		return nil, cache.ErrPubSubNotSupported
+	}
+	return ps.Subscribe(ctx, channels...)
+}
+
+// XAdd 透传被包装实例的追加，不重试，避免流中出现重复消息
+// 被包装实例不支持 Redis Streams 时返回 cache.ErrStreamsNotSupported
+func (r *retryImpl) XAdd(ctx context.Context, stream string, maxLen int64, values map[string]interface{}) (string, error) {
+	s, ok := r.c.(cache.Streams)
+	if !ok {
+		return "", cache.ErrStreamsNotSupported
+	}
+	return s.XAdd(ctx, stream, maxLen, values)
+}
+
+// XRead 透传被包装实例的读取，阻塞读取由调用方循环重试
+func (r *retryImpl) XRead(ctx context.Context, stream, lastID string, count int64, block time.Duration) ([]cache.StreamMessage, error) {
+	s, ok := r.c.(cache.Streams)
+	if !ok {
+		return nil, cache.ErrStreamsNotSupported
+	}
+	return s.XRead(ctx, stream, lastID, count, block)
+}
+
+// XGroupCreate 透传被包装实例的消费组创建
+func (r *retryImpl) XGroupCreate(ctx context.Context, stream, group, startID string) error {
+	s, ok := r.c.(cache.Streams)
+	if !ok {
+		return cache.ErrStreamsNotSupported
+	}
+	return s.XGroupCreate(ctx, stream, group, startID)
+}
+
+// XReadGroup 透传被包装实例的读取，读取会改变消费组状态，不重试
+func (r *retryImpl) XReadGroup(ctx context.Context, stream, group, consumer string, count int64, block time.Duration) ([]cache.StreamMessage, error) {
+	s, ok := r.c.(cache.Streams)
+	if !ok {
+		return nil, cache.ErrStreamsNotSupported
+	}
+	return s.XReadGroup(ctx, stream, group, consumer, count, block)
+}
+
+// XAck 透传被包装实例的确认
+func (r *retryImpl) XAck(ctx context.Context, stream, group string, ids ...string) error {
+	s, ok := r.c.(cache.Streams)
+	if !ok {
+		return cache.ErrStreamsNotSupported
+	}
+	return s.XAck(ctx, stream, group, ids...)
+}
+
+// XPending 透传被包装实例的待确认消息查询
+func (r *retryImpl) XPending(ctx context.Context, stream, group string, minIdle time.Duration, count int64) ([]cache.PendingMessage, error) {
+	s, ok := r.c.(cache.Streams)
+	if !ok {
+		return nil, cache.ErrStreamsNotSupported
+	}
+	return s.XPending(ctx, stream, group, minIdle, count)
+}
+
+// XClaim 透传被包装实例的认领
+func (r *retryImpl) XClaim(ctx context.Context, stream, group, consumer string, minIdle time.Duration, ids ...string) ([]cache.StreamMessage, error) {
+	s, ok := r.c.(cache.Streams)
+	if !ok {
+		return nil, cache.ErrStreamsNotSupported
+	}
+	return s.XClaim(ctx, stream, group, consumer, minIdle, ids...)
+}
+
+// Pipeline 返回带超时的管道，管道不重试
+func (r *retryImpl) Pipeline() cache.Pipeliner {
+	return &pipelineImpl{Pipeliner: r.c.Pipeline(), timeout: r.o.pipelineTimeout}
+}
+
+// pipelineImpl 仅为 Exec 增加超时
+type pipelineImpl struct {
+	cache.Pipeliner
+	timeout time.Duration
+}
+
+// Exec 执行管道
+func (p *pipelineImpl) Exec(ctx context.Context) ([]cache.Cmder, error) {
+	if p.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, p.timeout)
+		defer cancel()
+	}
+	return p.Pipeliner.Exec(ctx)
+}
@@ -0,0 +1,129 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/ZampoRen/go-server-comon/internal/infra/cache"
+	"github.com/ZampoRen/go-server-comon/internal/infra/cache/impl/memory"
+)
+
+// flakyCmdable 前 failures 次 Get/SetNX/Incr 返回 io.EOF
+type flakyCmdable struct {
+	cache.Cmdable
+	failures int
+	calls    int
+}
+
+type errCmd struct {
+	cache.StringCmd
+	err error
+}
+
+func (c errCmd) Err() error { return c.err }
+
+type errBoolCmd struct {
+	cache.BoolCmd
+	err error
+}
+
+func (c errBoolCmd) Err() error { return c.err }
+
+type errIntCmd struct {
+	cache.IntCmd
+	err error
+}
+
+func (c errIntCmd) Err() error { return c.err }
+
+func (f *flakyCmdable) Get(ctx context.Context, key string) cache.StringCmd {
+	f.calls++
+	if f.calls <= f.failures {
+		return errCmd{err: io.EOF}
+	}
+	return f.Cmdable.Get(ctx, key)
+}
+
+func (f *flakyCmdable) SetNX(ctx context.Context, key string, value interface{}, expiration time.Duration) cache.BoolCmd {
+	f.calls++
+	if f.calls <= f.failures {
+		return errBoolCmd{err: io.EOF}
+	}
+	return f.Cmdable.SetNX(ctx, key, value, expiration)
+}
+
+func (f *flakyCmdable) Incr(ctx context.Context, key string) cache.IntCmd {
+	f.calls++
+	if f.calls <= f.failures {
+		return errIntCmd{err: io.EOF}
+	}
+	return f.Cmdable.Incr(ctx, key)
+}
+
+// TestRetry 测试瞬时错误重试与非幂等命令不重试
+func TestRetry(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		name      string
+		failures  int
+		opts      []Option
+		incr      bool
+		setnx     bool
+		wantErr   bool
+		wantCalls int
+	}{
+		{name: "重试后成功", failures: 2, wantCalls: 3},
+		{name: "重试耗尽", failures: 5, wantErr: true, wantCalls: 3},
+		{name: "关闭重试", failures: 1, opts: []Option{WithMaxRetries(0)}, wantErr: true, wantCalls: 1},
+		{name: "非幂等命令不重试", failures: 1, incr: true, wantErr: true, wantCalls: 1},
+		{name: "条件写入不重试", failures: 1, setnx: true, wantErr: true, wantCalls: 1},
+		{name: "显式允许非幂等重试", failures: 1, incr: true, opts: []Option{WithRetryNonIdempotent(true)}, wantCalls: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mem := memory.New()
+			mem.Set(ctx, "k", "1", 0)
+			f := &flakyCmdable{Cmdable: mem, failures: tt.failures}
+			c := New(f, append([]Option{WithBackoff(time.Millisecond, time.Millisecond)}, tt.opts...)...)
+
+			var err error
+			switch {
+			case tt.incr:
+				err = c.Incr(ctx, "k").Err()
+			case tt.setnx:
+				err = c.SetNX(ctx, "lock", "1", time.Minute).Err()
+			default:
+				err = c.Get(ctx, "k").Err()
+			}
+			if (err != nil) != tt.wantErr {
+				t.Errorf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if f.calls != tt.wantCalls {
+				t.Errorf("calls = %d, want %d", f.calls, tt.wantCalls)
+			}
+		})
+	}
+}
+
+// TestIsTransient 测试瞬时错误判断
+func TestIsTransient(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{io.EOF, true},
+		{errors.New("LOADING Redis is loading the dataset in memory"), true},
+		{context.DeadlineExceeded, true},
+		{context.Canceled, false},
+		{errors.New("WRONGTYPE Operation against a key holding the wrong kind of value"), false},
+		{nil, false},
+	}
+	for _, tt := range tests {
+		if got := IsTransient(tt.err); got != tt.want {
+			t.Errorf("IsTransient(%v) = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package cache
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+
+	"github.com/cloudwego/hertz/pkg/common/hlog"
+)
+
+// Invalidator 通过发布订阅在多个实例之间同步本地缓存的失效
+//
+//	inv := cache.NewInvalidator(client.(cache.PubSub), "user:invalidate")
+//	users := localcache.New[*User](localcache.WithDeleteKeyBefore(inv.Publish))
+//	sub, err := inv.Subscribe(ctx, users.DelLocal)
+//	defer sub.Close()
+//
+// 本实例发布的消息不会回调本实例，本地缓存由 Del 自身删除；消息可能丢失，本地缓存仍需设置较短的 TTL 兜底
+type Invalidator struct {
+	ps      PubSub
+	channel string
+	id      string
+}
+
+// invalidation 失效消息
+type invalidation struct {
+	From string   `json:"from"`
+	Keys []string `json:"keys"`
+}
+
+// NewInvalidator 创建失效通知，channel 为各实例共享的频道名
+func NewInvalidator(ps PubSub, channel string) *Invalidator {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return &Invalidator{ps: ps, channel: channel, id: hex.EncodeToString(b)}
+}
+
+// Publish 通知其他实例删除 keys，发布失败只打印日志，签名与 localcache.WithDeleteKeyBefore 一致
+func (i *Invalidator) Publish(ctx context.Context, keys ...string) {
+	if len(keys) == 0 {
+		return
+	}
+	payload, err := json.Marshal(invalidation{From: i.id, Keys: keys})
+	if err != nil {
+		hlog.CtxErrorf(ctx, "[Invalidator] marshal keys failed: %v", err)
+		return
+	}
+	if _, err := i.ps.Publish(ctx, i.channel, payload); err != nil {
+		hlog.CtxWarnf(ctx, "[Invalidator] publish to %s failed, keys: %v, err: %v", i.channel, keys, err)
+	}
+}
+
+// Subscribe 订阅其他实例的失效通知，收到后调用 fn（通常为 localcache.Cache 的 DelLocal），
+// 关闭返回的订阅后停止
+func (i *Invalidator) Subscribe(ctx context.Context, fn func(ctx context.Context, keys ...string)) (Subscription, error) {
+	sub, err := i.ps.Subscribe(ctx, i.channel)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx = context.WithoutCancel(ctx)
+	go func() {
+		for msg := range sub.Channel() {
+			var inv invalidation
+			if err := json.Unmarshal([]byte(msg.Payload), &inv); err != nil {
+				hlog.CtxWarnf(ctx, "[Invalidator] invalid message on %s: %v", i.channel, err)
+				continue
+			}
+			if inv.From == i.id || len(inv.Keys) == 0 {
+				continue
+			}
+			fn(ctx, inv.Keys...)
+		}
+	}()
+	return sub, nil
+}
@@ -0,0 +1,42 @@
+package cache_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ZampoRen/go-server-comon/internal/infra/cache"
+	"github.com/ZampoRen/go-server-comon/internal/infra/cache/impl/memory"
+)
+
+// TestInvalidator 测试失效通知只回调其他实例
+func TestInvalidator(t *testing.T) {
+	ctx := context.Background()
+	ps := memory.New().(cache.PubSub)
+	a := cache.NewInvalidator(ps, "invalidate")
+	b := cache.NewInvalidator(ps, "invalidate")
+
+	got := make(chan []string, 2)
+	for _, inv := range []*cache.Invalidator{a, b} {
+		sub, err := inv.Subscribe(ctx, func(_ context.Context, keys ...string) { got <- keys })
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer sub.Close()
+	}
+
+	a.Publish(ctx, "user:1", "user:2")
+	select {
+	case keys := <-got:
+		if len(keys) != 2 || keys[0] != "user:1" {
+			t.Fatalf("keys = %v", keys)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("no invalidation received")
+	}
+	select {
+	case keys := <-got:
+		t.Fatalf("publisher received its own invalidation: %v", keys)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
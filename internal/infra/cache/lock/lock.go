@@ -0,0 +1,341 @@
+// Package lock 基于 cache.Cmdable 实现的分布式锁
+//
+// 加锁使用 SET NX PX 写入随机值，释放和续期通过 Lua 脚本校验持有者后执行，
+// 支持看门狗自动续期和单调递增的 fencing token。
+// 使用 memory.New 创建的进程内 Cmdable 时需要注册脚本的 Go 实现：
+//
+//	client := memory.New(lock.MemoryScripts()...)
+//
+// 基本使用：
+//
+//	mu := lock.NewMutex(client, "order:123", lock.WithTTL(10*time.Second), lock.WithWatchdog())
+//	if err := mu.Lock(ctx); err != nil {
+//		return err
+//	}
+//	defer mu.Unlock(ctx)
+//
+//	// 写入下游存储时携带 fencing token，拒绝过期持有者的写入
+//	token := mu.Token()
+package lock
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/common/hlog"
+
+	"github.com/ZampoRen/go-server-comon/internal/infra/cache"
+	"github.com/ZampoRen/go-server-comon/internal/infra/cache/impl/memory"
+)
+
+var (
+	// ErrNotObtained 锁已被其他持有者占用
+	ErrNotObtained = errors.New("lock not obtained")
+	// ErrNotHeld 当前未持有锁或锁已过期
+	ErrNotHeld = errors.New("lock not held")
+)
+
+const (
+	// AcquireScript 键不存在时写入值并设置过期时间（毫秒），同时递增 fencing 计数器，
+	// 返回递增后的 token，键已存在时返回 -1；KEYS 为锁与计数器，ARGV 为值与过期时间
+	AcquireScript = `if redis.call("SET", KEYS[1], ARGV[1], "NX", "PX", ARGV[2]) then return redis.call("INCR", KEYS[2]) else return -1 end`
+	// ReleaseScript 仅当值匹配时删除键
+	ReleaseScript = `if redis.call("GET", KEYS[1]) == ARGV[1] then return redis.call("DEL", KEYS[1]) else return 0 end`
+	// ExtendScript 仅当值匹配时重新设置过期时间（毫秒）
+	ExtendScript = `if redis.call("GET", KEYS[1]) == ARGV[1] then return redis.call("PEXPIRE", KEYS[1], ARGV[2]) else return 0 end`
+)
+
+// MemoryScripts 返回上述脚本的 Go 实现，用于 memory.New，使锁可以在单实例部署与测试中使用
+func MemoryScripts() []memory.Option {
+	return []memory.Option{
+		memory.WithScript(AcquireScript, func(ctx context.Context, c cache.Cmdable, keys []string, args ...interface{}) (interface{}, error) {
+			ttl, err := strconv.ParseInt(fmt.Sprint(args[1]), 10, 64)
+			if err != nil {
+				return nil, err
+			}
+			ok, err := c.SetNX(ctx, keys[0], args[0], time.Duration(ttl)*time.Millisecond).Result()
+			if err != nil || !ok {
+				return int64(-1), err
+			}
+			return c.Incr(ctx, keys[1]).Result()
+		}),
+		memory.WithScript(ReleaseScript, func(ctx context.Context, c cache.Cmdable, keys []string, args ...interface{}) (interface{}, error) {
+			if v, err := c.Get(ctx, keys[0]).Result(); err != nil || v != fmt.Sprint(args[0]) {
+				return int64(0), nil
+			}
+			return c.Del(ctx, keys[0]).Result()
+		}),
+		memory.WithScript(ExtendScript, func(ctx context.Context, c cache.Cmdable, keys []string, args ...interface{}) (interface{}, error) {
+			if v, err := c.Get(ctx, keys[0]).Result(); err != nil || v != fmt.Sprint(args[0]) {
+				return int64(0), nil
+			}
+			ttl, err := strconv.ParseInt(fmt.Sprint(args[1]), 10, 64)
+			if err != nil {
+				return nil, err
+			}
+			ok, err := c.Expire(ctx, keys[0], time.Duration(ttl)*time.Millisecond).Result()
+			if err != nil || !ok {
+				return int64(0), err
+			}
+			return int64(1), nil
+		}),
+	}
+}
+
+// Option 锁配置选项
+type Option func(o *option)
+
+type option struct {
+	ttl           time.Duration
+	retryInterval time.Duration
+	watchdog      bool
+	fencing       bool
+}
+
+// WithTTL 设置锁的过期时间（默认 30s）
+func WithTTL(ttl time.Duration) Option {
+	return func(o *option) {
+		if ttl > 0 {
+			o.ttl = ttl
+		}
+	}
+}
+
+// WithRetryInterval 设置 Lock 阻塞等待时的重试间隔（默认 50ms）
+func WithRetryInterval(d time.Duration) Option {
+	return func(o *option) {
+		if d > 0 {
+			o.retryInterval = d
+		}
+	}
+}
+
+// WithWatchdog 开启看门狗，持有锁期间每 TTL/3 自动续期
+func WithWatchdog() Option {
+	return func(o *option) {
+		o.watchdog = true
+	}
+}
+
+// WithFencing 开启 fencing token，加锁与递增 {key}:fencing 计数器在同一个脚本中原子执行，
+// 计数器与锁使用相同的 hash tag，在 Redis Cluster 中位于同一个槽
+func WithFencing() Option {
+	return func(o *option) {
+		o.fencing = true
+	}
+}
+
+// Mutex 分布式互斥锁
+// 同一个 Mutex 实例不可重入，不同实例之间通过 key 互斥
+type Mutex struct {
+	client cache.Cmdable
+	key    string
+	opt    *option
+
+	mu       sync.Mutex
+	value    string
+	token    int64
+	stopDog  context.CancelFunc
+	dogGroup sync.WaitGroup
+}
+
+// NewMutex 创建分布式锁
+func NewMutex(client cache.Cmdable, key string, opts ...Option) *Mutex {
+	opt := &option{
+		ttl:           30 * time.Second,
+		retryInterval: 50 * time.Millisecond,
+	}
+	for _, o := range opts {
+		o(opt)
+	}
+
+	return &Mutex{
+		client: client,
+		key:    key,
+		opt:    opt,
+	}
+}
+
+// Key 返回锁的键
+func (m *Mutex) Key() string {
+	return m.key
+}
+
+// Token 返回最近一次加锁获得的 fencing token，未开启 fencing 时为 0
+func (m *Mutex) Token() int64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.token
+}
+
+// Lock 阻塞加锁，直到成功或 ctx 结束
+func (m *Mutex) Lock(ctx context.Context) error {
+	ticker := time.NewTicker(m.opt.retryInterval)
+	defer ticker.Stop()
+
+	for {
+		err := m.TryLock(ctx)
+		if !errors.Is(err, ErrNotObtained) {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// TryLock 尝试加锁一次，锁被占用时返回 ErrNotObtained
+func (m *Mutex) TryLock(ctx context.Context) error {
+	value, err := randomValue()
+	if err != nil {
+		return err
+	}
+
+	var token int64
+	if m.opt.fencing {
+		token, err = m.client.Eval(ctx, AcquireScript, []string{m.key, fencingKey(m.key)}, value, m.opt.ttl.Milliseconds()).Int64()
+		if err != nil {
+			return err
+		}
+		if token < 0 {
+			return ErrNotObtained
+		}
+	} else {
+		ok, err := m.client.SetNX(ctx, m.key, value, m.opt.ttl).Result()
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return ErrNotObtained
+		}
+	}
+
+	m.mu.Lock()
+	m.value = value
+	m.token = token
+	m.mu.Unlock()
+
+	if m.opt.watchdog {
+		m.startWatchdog(ctx)
+	}
+	return nil
+}
+
+// Unlock 释放锁，锁已过期或被他人持有时返回 ErrNotHeld
+func (m *Mutex) Unlock(ctx context.Context) error {
+	m.stopWatchdog()
+
+	m.mu.Lock()
+	value := m.value
+	m.value = ""
+	m.mu.Unlock()
+
+	if value == "" {
+		return ErrNotHeld
+	}
+
+	n, err := m.client.Eval(ctx, ReleaseScript, []string{m.key}, value).Int64()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrNotHeld
+	}
+	return nil
+}
+
+// Extend 将锁的过期时间重置为 TTL，锁已过期或被他人持有时返回 ErrNotHeld
+func (m *Mutex) Extend(ctx context.Context) error {
+	m.mu.Lock()
+	value := m.value
+	m.mu.Unlock()
+
+	if value == "" {
+		return ErrNotHeld
+	}
+
+	n, err := m.client.Eval(ctx, ExtendScript, []string{m.key}, value, m.opt.ttl.Milliseconds()).Int64()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrNotHeld
+	}
+	return nil
+}
+
+// startWatchdog 启动看门狗协程，持有锁期间定期续期
+func (m *Mutex) startWatchdog(ctx context.Context) {
+	dogCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	m.mu.Lock()
+	m.stopDog = cancel
+	m.mu.Unlock()
+
+	interval := m.opt.ttl / 3
+	m.dogGroup.Add(1)
+	go func() {
+		defer m.dogGroup.Done()
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-dogCtx.Done():
+				return
+			case <-ticker.C:
+				if err := m.Extend(dogCtx); err != nil {
+					if dogCtx.Err() == nil {
+						hlog.CtxWarnf(dogCtx, "[Lock] watchdog extend failed, key: %s, err: %v", m.key, err)
+					}
+					if errors.Is(err, ErrNotHeld) {
+						return
+					}
+				}
+			}
+		}
+	}()
+}
+
+// stopWatchdog 停止看门狗协程并等待其退出
+func (m *Mutex) stopWatchdog() {
+	m.mu.Lock()
+	cancel := m.stopDog
+	m.stopDog = nil
+	m.mu.Unlock()
+
+	if cancel != nil {
+		cancel()
+		m.dogGroup.Wait()
+	}
+}
+
+// fencingKey 返回 fencing 计数器的键，与锁使用相同的 hash tag：
+// key 中已有 hash tag 时直接追加后缀，否则以整个 key 作为 hash tag
+func fencingKey(key string) string {
+	if start := strings.IndexByte(key, '{'); start >= 0 {
+		if end := strings.IndexByte(key[start+1:], '}'); end > 0 {
+			return key + ":fencing"
+		}
+	}
+	return "{" + key + "}:fencing"
+}
+
+// randomValue 生成锁的随机值，用于标识持有者
+func randomValue() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,138 @@
+package lock
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	goredis "github.com/redis/go-redis/v9"
+
+	"github.com/ZampoRen/go-server-comon/internal/infra/cache"
+	"github.com/ZampoRen/go-server-comon/internal/infra/cache/impl/memory"
+	cacheredis "github.com/ZampoRen/go-server-comon/internal/infra/cache/impl/redis"
+)
+
+// TestMutex 分别在 Redis 与进程内实现上测试加锁、释放、续期与 fencing token
+func TestMutex(t *testing.T) {
+	clients := map[string]func(t *testing.T) cache.Cmdable{
+		"redis": func(t *testing.T) cache.Cmdable {
+			mr := miniredis.RunT(t)
+			return cacheredis.NewWithClientOptions(&goredis.Options{Addr: mr.Addr()})
+		},
+		"memory": func(t *testing.T) cache.Cmdable {
+			return memory.New(MemoryScripts()...)
+		},
+	}
+
+	for name, newClient := range clients {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+
+			t.Run("互斥与释放", func(t *testing.T) {
+				client := newClient(t)
+				a := NewMutex(client, "order:1")
+				b := NewMutex(client, "order:1")
+				if err := a.TryLock(ctx); err != nil {
+					t.Fatal(err)
+				}
+				if err := b.TryLock(ctx); !errors.Is(err, ErrNotObtained) {
+					t.Fatalf("TryLock() error = %v, want ErrNotObtained", err)
+				}
+				if err := b.Unlock(ctx); !errors.Is(err, ErrNotHeld) {
+					t.Fatalf("Unlock() without lock error = %v, want ErrNotHeld", err)
+				}
+				if err := a.Extend(ctx); err != nil {
+					t.Fatalf("Extend() error = %v", err)
+				}
+				if err := a.Unlock(ctx); err != nil {
+					t.Fatalf("Unlock() error = %v", err)
+				}
+				if err := b.TryLock(ctx); err != nil {
+					t.Fatalf("TryLock() after unlock error = %v", err)
+				}
+			})
+
+			t.Run("不能释放他人的锁", func(t *testing.T) {
+				client := newClient(t)
+				a := NewMutex(client, "order:2", WithTTL(time.Minute))
+				if err := a.TryLock(ctx); err != nil {
+					t.Fatal(err)
+				}
+				// 模拟锁过期后被他人持有
+				client.Set(ctx, "order:2", "other", time.Minute)
+				if err := a.Extend(ctx); !errors.Is(err, ErrNotHeld) {
+					t.Fatalf("Extend() error = %v, want ErrNotHeld", err)
+				}
+				if err := a.Unlock(ctx); !errors.Is(err, ErrNotHeld) {
+					t.Fatalf("Unlock() error = %v, want ErrNotHeld", err)
+				}
+				if v, _ := client.Get(ctx, "order:2").Result(); v != "other" {
+					t.Fatalf("lock value = %q, want other", v)
+				}
+			})
+
+			t.Run("fencing token 递增", func(t *testing.T) {
+				client := newClient(t)
+				a := NewMutex(client, "order:3", WithFencing())
+				b := NewMutex(client, "order:3", WithFencing())
+				if err := a.TryLock(ctx); err != nil || a.Token() != 1 {
+					t.Fatalf("TryLock() = %v, token = %d", err, a.Token())
+				}
+				if err := b.TryLock(ctx); !errors.Is(err, ErrNotObtained) {
+					t.Fatalf("TryLock() error = %v, want ErrNotObtained", err)
+				}
+				_ = a.Unlock(ctx)
+				if err := b.TryLock(ctx); err != nil || b.Token() != 2 {
+					t.Fatalf("TryLock() = %v, token = %d, want 2", err, b.Token())
+				}
+				if n, _ := client.Get(ctx, "{order:3}:fencing").Int64(); n != 2 {
+					t.Fatalf("fencing counter = %d, want 2", n)
+				}
+			})
+
+			t.Run("阻塞加锁", func(t *testing.T) {
+				client := newClient(t)
+				a := NewMutex(client, "order:4")
+				b := NewMutex(client, "order:4", WithRetryInterval(10*time.Millisecond))
+				_ = a.TryLock(ctx)
+				time.AfterFunc(50*time.Millisecond, func() { _ = a.Unlock(ctx) })
+				lockCtx, cancel := context.WithTimeout(ctx, 2*time.Second)
+				defer cancel()
+				if err := b.Lock(lockCtx); err != nil {
+					t.Fatalf("Lock() error = %v", err)
+				}
+			})
+		})
+	}
+}
+
+// TestWatchdog 测试看门狗在持有期间续期
+func TestWatchdog(t *testing.T) {
+	ctx := context.Background()
+	client := memory.New(MemoryScripts()...)
+	m := NewMutex(client, "job", WithTTL(150*time.Millisecond), WithWatchdog())
+	if err := m.TryLock(ctx); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(400 * time.Millisecond)
+	if err := NewMutex(client, "job").TryLock(ctx); !errors.Is(err, ErrNotObtained) {
+		t.Fatalf("TryLock() error = %v, want ErrNotObtained while watchdog is running", err)
+	}
+	if err := m.Unlock(ctx); err != nil {
+		t.Fatalf("Unlock() error = %v", err)
+	}
+}
+
+func TestFencingKey(t *testing.T) {
+	tests := map[string]string{
+		"order:1":        "{order:1}:fencing",
+		"{tenant}:order": "{tenant}:order:fencing",
+	}
+	for key, want := range tests {
+		if got := fencingKey(key); got != want {
+			t.Errorf("fencingKey(%q) = %q, want %q", key, got, want)
+		}
+	}
+}
@@ -0,0 +1,36 @@
+package cache
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrPubSubNotSupported 底层实例不支持发布订阅
+var ErrPubSubNotSupported = errors.New("cache: pub/sub not supported")
+
+// Message 订阅收到的消息
+type Message struct {
+	Channel string
+	Payload string
+}
+
+// Subscription 订阅，Close 后 Channel 返回的通道会被关闭
+type Subscription interface {
+	// Channel 返回接收消息的通道，消费过慢时消息可能被丢弃
+	Channel() <-chan *Message
+	Close() error
+}
+
+// PubSub 支持发布订阅的 Cmdable，通过类型断言获取：
+//
+//	if ps, ok := client.(cache.PubSub); ok {
+//		sub, err := ps.Subscribe(ctx, "channel")
+//	}
+//
+// 发布订阅不保证送达，订阅断开期间发布的消息会丢失，只适用于缓存失效通知等可容忍丢失的场景
+type PubSub interface {
+	// Publish 向频道发布消息，返回收到消息的订阅者数量
+	Publish(ctx context.Context, channel string, message interface{}) (int64, error)
+	// Subscribe 订阅频道
+	Subscribe(ctx context.Context, channels ...string) (Subscription, error)
+}
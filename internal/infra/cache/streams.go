@@ -0,0 +1,49 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrStreamsNotSupported 底层实例不支持 Redis Streams
+var ErrStreamsNotSupported = errors.New("cache: streams not supported")
+
+// StreamMessage 流中的一条消息
+type StreamMessage struct {
+	ID     string
+	Values map[string]interface{}
+}
+
+// PendingMessage 消费组中已投递但未确认的消息
+type PendingMessage struct {
+	ID       string
+	Consumer string
+	// Idle 距上次投递的时间
+	Idle time.Duration
+	// RetryCount 已投递的次数
+	RetryCount int64
+}
+
+// Streams 支持 Redis Streams 的 Cmdable，与 PubSub 一样通过类型断言获取
+// 与发布订阅不同，消息保存在流中，读取方断开后可以从上次的 ID 继续读取
+type Streams interface {
+	// XAdd 向流追加消息并返回消息 ID，maxLen > 0 时近似裁剪流的长度
+	XAdd(ctx context.Context, stream string, maxLen int64, values map[string]interface{}) (string, error)
+	// XRead 读取 ID 大于 lastID 的至多 count 条消息，lastID 为 "$" 时只读取新消息
+	// 没有消息时最多阻塞 block，超时返回空结果与 nil
+	XRead(ctx context.Context, stream, lastID string, count int64, block time.Duration) ([]StreamMessage, error)
+
+	// XGroupCreate 创建消费组，流不存在时一并创建，消费组已存在时返回 nil
+	// startID 为 "$" 时只消费之后写入的消息，为 "0" 时从头消费
+	XGroupCreate(ctx context.Context, stream, group, startID string) error
+	// XReadGroup 以 consumer 的身份读取消费组中尚未投递的消息，语义同 XRead
+	XReadGroup(ctx context.Context, stream, group, consumer string, count int64, block time.Duration) ([]StreamMessage, error)
+	// XAck 确认消息，确认后消息不再出现在待确认列表中
+	XAck(ctx context.Context, stream, group string, ids ...string) error
+	// XPending 返回空闲时间不少于 minIdle 的至多 count 条待确认消息
+	XPending(ctx context.Context, stream, group string, minIdle time.Duration, count int64) ([]PendingMessage, error)
+	// XClaim 将空闲时间不少于 minIdle 的待确认消息转移给 consumer 并返回消息内容，投递次数加一
+	// 已被删除或被其他消费者抢先认领的消息不会返回
+	XClaim(ctx context.Context, stream, group, consumer string, minIdle time.Duration, ids ...string) ([]StreamMessage, error)
+}
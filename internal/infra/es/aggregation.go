@@ -0,0 +1,168 @@
+package es
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+const (
+	// AggTypeTerms 词项分桶聚合
+	AggTypeTerms = "terms"
+	// AggTypeDateHistogram 日期直方图聚合
+	AggTypeDateHistogram = "date_histogram"
+	// AggTypeSum 求和聚合
+	AggTypeSum = "sum"
+	// AggTypeAvg 平均值聚合
+	AggTypeAvg = "avg"
+	// AggTypeMin 最小值聚合
+	AggTypeMin = "min"
+	// AggTypeMax 最大值聚合
+	AggTypeMax = "max"
+)
+
+var (
+	// ErrAggregationNotFound 响应中没有指定名称的聚合结果
+	ErrAggregationNotFound = errors.New("aggregation not found")
+)
+
+// AggType 聚合类型
+type AggType string
+
+// Aggregation 聚合
+type Aggregation struct {
+	Type  AggType // 聚合类型
+	Field string  // 字段名
+
+	Size             *int   // 返回的桶数量（terms）
+	MinDocCount      *int   // 桶的最小文档数（terms、date_histogram）
+	CalendarInterval string // 日历间隔，如 day、month（date_histogram）
+	FixedInterval    string // 固定间隔，如 30m、1h（date_histogram）
+	Format           string // 日期格式（date_histogram）
+
+	Aggs map[string]Aggregation // 子聚合，仅分桶聚合支持
+}
+
+// NewTermsAgg 创建词项分桶聚合，size 为 0 时使用服务端默认值 10
+func NewTermsAgg(field string, size int) Aggregation {
+	a := Aggregation{Type: AggTypeTerms, Field: field}
+	if size > 0 {
+		a.Size = &size
+	}
+	return a
+}
+
+// NewDateHistogramAgg 创建日期直方图聚合
+// interval 为 minute、hour、day、week、month、quarter、year 等日历间隔时按日历分桶，否则视为固定间隔（如 30m）
+func NewDateHistogramAgg(field, interval string) Aggregation {
+	a := Aggregation{Type: AggTypeDateHistogram, Field: field}
+	switch interval {
+	case "minute", "1m", "hour", "1h", "day", "1d", "week", "1w", "month", "1M", "quarter", "1q", "year", "1y":
+		a.CalendarInterval = interval
+	default:
+		a.FixedInterval = interval
+	}
+	return a
+}
+
+// NewSumAgg 创建求和聚合
+func NewSumAgg(field string) Aggregation {
+	return Aggregation{Type: AggTypeSum, Field: field}
+}
+
+// NewAvgAgg 创建平均值聚合
+func NewAvgAgg(field string) Aggregation {
+	return Aggregation{Type: AggTypeAvg, Field: field}
+}
+
+// NewMinAgg 创建最小值聚合
+func NewMinAgg(field string) Aggregation {
+	return Aggregation{Type: AggTypeMin, Field: field}
+}
+
+// NewMaxAgg 创建最大值聚合
+func NewMaxAgg(field string) Aggregation {
+	return Aggregation{Type: AggTypeMax, Field: field}
+}
+
+// WithSubAgg 返回添加了子聚合的副本
+func (a Aggregation) WithSubAgg(name string, sub Aggregation) Aggregation {
+	aggs := make(map[string]Aggregation, len(a.Aggs)+1)
+	for k, v := range a.Aggs {
+		aggs[k] = v
+	}
+	aggs[name] = sub
+	a.Aggs = aggs
+	return a
+}
+
+// AggregationResults 聚合结果，键为聚合名称
+type AggregationResults map[string]json.RawMessage
+
+// Buckets 解析分桶聚合（terms、date_histogram）的结果
+func (r AggregationResults) Buckets(name string) ([]Bucket, error) {
+	raw, ok := r[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrAggregationNotFound, name)
+	}
+	var agg struct {
+		Buckets []Bucket `json:"buckets"`
+	}
+	if err := json.Unmarshal(raw, &agg); err != nil {
+		return nil, fmt.Errorf("parse aggregation %s failed: %w", name, err)
+	}
+	return agg.Buckets, nil
+}
+
+// Metric 解析指标聚合（sum、avg、min、max）的结果，没有匹配文档时 avg、min、max 返回 nil
+func (r AggregationResults) Metric(name string) (*float64, error) {
+	raw, ok := r[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrAggregationNotFound, name)
+	}
+	var agg struct {
+		Value *float64 `json:"value"`
+	}
+	if err := json.Unmarshal(raw, &agg); err != nil {
+		return nil, fmt.Errorf("parse aggregation %s failed: %w", name, err)
+	}
+	return agg.Value, nil
+}
+
+// Bucket 分桶聚合的桶
+type Bucket struct {
+	Key          any                // 桶的键，terms 为字段值，date_histogram 为毫秒时间戳
+	KeyAsString  string             // 格式化后的键，仅在服务端返回时存在
+	DocCount     int64              // 文档数
+	Aggregations AggregationResults // 子聚合结果
+}
+
+// UnmarshalJSON 子聚合结果与 key、doc_count 位于同一层级，解析时拆分出来
+func (b *Bucket) UnmarshalJSON(data []byte) error {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+
+	for k, v := range fields {
+		var err error
+		switch k {
+		case "key":
+			err = json.Unmarshal(v, &b.Key)
+		case "key_as_string":
+			err = json.Unmarshal(v, &b.KeyAsString)
+		case "doc_count":
+			err = json.Unmarshal(v, &b.DocCount)
+		case "doc_count_error_upper_bound":
+		default:
+			if b.Aggregations == nil {
+				b.Aggregations = make(AggregationResults)
+			}
+			b.Aggregations[k] = v
+		}
+		if err != nil {
+			return fmt.Errorf("parse bucket field %s failed: %w", k, err)
+		}
+	}
+	return nil
+}
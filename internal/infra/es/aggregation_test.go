@@ -0,0 +1,57 @@
+package es
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestAggregationResults(t *testing.T) {
+	data := `{"hits":{"hits":[]},"aggregations":{
+		"by_status":{"buckets":[
+			{"key":"ok","doc_count":3,"total":{"value":12.5}},
+			{"key":"failed","doc_count":1,"total":{"value":2}}
+		]},
+		"by_day":{"buckets":[{"key":1704067200000,"key_as_string":"2024-01-01","doc_count":4}]},
+		"max_cost":{"value":null}
+	}}`
+
+	var resp Response
+	if err := json.Unmarshal([]byte(data), &resp); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("分桶与子聚合", func(t *testing.T) {
+		buckets, err := resp.Aggregations.Buckets("by_status")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(buckets) != 2 || buckets[0].Key != "ok" || buckets[0].DocCount != 3 {
+			t.Fatalf("Buckets() = %+v", buckets)
+		}
+		total, err := buckets[0].Aggregations.Metric("total")
+		if err != nil || total == nil || *total != 12.5 {
+			t.Errorf("Metric(total) = %v, %v", total, err)
+		}
+	})
+
+	t.Run("日期直方图", func(t *testing.T) {
+		buckets, err := resp.Aggregations.Buckets("by_day")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(buckets) != 1 || buckets[0].KeyAsString != "2024-01-01" || buckets[0].Key != float64(1704067200000) {
+			t.Errorf("Buckets() = %+v", buckets)
+		}
+	})
+
+	t.Run("空指标与不存在的聚合", func(t *testing.T) {
+		v, err := resp.Aggregations.Metric("max_cost")
+		if err != nil || v != nil {
+			t.Errorf("Metric(max_cost) = %v, %v", v, err)
+		}
+		if _, err := resp.Aggregations.Metric("missing"); !errors.Is(err, ErrAggregationNotFound) {
+			t.Errorf("Metric(missing) error = %v", err)
+		}
+	})
+}
@@ -0,0 +1,84 @@
+package es
+
+import (
+	"context"
+	"time"
+)
+
+// BulkIndexerOptFn NewBulkIndexer 选项函数
+type BulkIndexerOptFn func(o *BulkIndexerOption)
+
+// BulkIndexerOption NewBulkIndexer 选项，未设置的字段使用 esutil 的默认值
+type BulkIndexerOption struct {
+	// NumWorkers 并发 worker 数，默认 CPU 核数
+	NumWorkers int
+	// FlushBytes 单个 worker 缓冲达到该字节数时刷新，默认 5MB
+	FlushBytes int
+	// FlushInterval 定期刷新的间隔，默认 30s
+	FlushInterval time.Duration
+	// MaxRetries 单个文档因 429 或 5xx 失败时的重试次数，默认不重试
+	MaxRetries int
+	// RetryBackoff 第一次重试前的等待时间，之后每次翻倍，默认 100ms
+	RetryBackoff time.Duration
+	// OnFailure 文档最终失败（不可重试或重试耗尽）时的回调，status 为 0 表示请求本身失败
+	// 回调在 worker 中同步执行，不应阻塞
+	OnFailure func(ctx context.Context, item BulkIndexerItem, status int, err error)
+}
+
+// WithNumWorkers 设置并发 worker 数
+func WithNumWorkers(n int) BulkIndexerOptFn {
+	return func(o *BulkIndexerOption) {
+		o.NumWorkers = n
+	}
+}
+
+// WithFlushBytes 设置触发刷新的缓冲字节数
+func WithFlushBytes(n int) BulkIndexerOptFn {
+	return func(o *BulkIndexerOption) {
+		o.FlushBytes = n
+	}
+}
+
+// WithFlushInterval 设置定期刷新的间隔
+func WithFlushInterval(d time.Duration) BulkIndexerOptFn {
+	return func(o *BulkIndexerOption) {
+		o.FlushInterval = d
+	}
+}
+
+// WithItemRetries 设置单个文档因 429 或 5xx 失败时的重试次数与初始退避时间
+func WithItemRetries(maxRetries int, backoff time.Duration) BulkIndexerOptFn {
+	return func(o *BulkIndexerOption) {
+		o.MaxRetries = maxRetries
+		o.RetryBackoff = backoff
+	}
+}
+
+// WithOnFailure 设置文档最终失败时的回调
+func WithOnFailure(fn func(ctx context.Context, item BulkIndexerItem, status int, err error)) BulkIndexerOptFn {
+	return func(o *BulkIndexerOption) {
+		o.OnFailure = fn
+	}
+}
+
+// NewBulkIndexerOption 应用选项并填充默认值，供各实现使用
+func NewBulkIndexerOption(opts ...BulkIndexerOptFn) *BulkIndexerOption {
+	o := &BulkIndexerOption{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	if o.RetryBackoff <= 0 {
+		o.RetryBackoff = 100 * time.Millisecond
+	}
+	return o
+}
+
+// BulkIndexerStats 批量索引器统计
+type BulkIndexerStats struct {
+	Added    uint64 // 添加的文档数
+	Indexed  uint64 // 成功写入（索引、创建、更新、删除）的文档数，包含重试后成功的文档
+	Failed   uint64 // 最终失败的文档数
+	Retried  uint64 // 重试次数
+	Flushed  uint64 // 已刷新的文档数
+	Requests uint64 // 发出的 bulk 请求数
+}
@@ -0,0 +1,98 @@
+package es
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+const (
+	// DefaultTaskPollInterval 等待 by-query 任务完成时的默认轮询间隔
+	DefaultTaskPollInterval = time.Second
+)
+
+var (
+	// ErrByQueryFailed by-query 任务执行完成但部分文档处理失败
+	ErrByQueryFailed = errors.New("by query has failures")
+	// ErrQueryRequired 未指定查询条件，避免误操作整个索引
+	ErrQueryRequired = errors.New("query is required")
+)
+
+// Script 脚本
+type Script struct {
+	Source string         // 脚本内容
+	Lang   string         // 脚本语言，默认 painless
+	Params map[string]any // 脚本参数，在脚本中通过 params.xxx 访问
+}
+
+// NewScript 创建 painless 脚本
+func NewScript(source string, params map[string]any) *Script {
+	return &Script{Source: source, Params: params}
+}
+
+// ByQueryOptFn UpdateByQuery、DeleteByQuery、Reindex 选项函数
+type ByQueryOptFn func(o *ByQueryOption)
+
+// ByQueryOption UpdateByQuery、DeleteByQuery、Reindex 选项
+type ByQueryOption struct {
+	// ProceedOnConflicts 遇到版本冲突时继续处理（conflicts=proceed），默认中止
+	ProceedOnConflicts bool
+	// Refresh 完成后刷新索引，使修改立即可见
+	Refresh bool
+	// Async 提交任务后立即返回，响应中只有 TaskID，默认等待任务完成
+	Async bool
+	// PollInterval 等待任务完成时的轮询间隔，默认 DefaultTaskPollInterval
+	PollInterval time.Duration
+}
+
+// WithProceedOnConflicts 遇到版本冲突时继续处理，冲突数记录在 VersionConflicts 中
+func WithProceedOnConflicts() ByQueryOptFn {
+	return func(o *ByQueryOption) {
+		o.ProceedOnConflicts = true
+	}
+}
+
+// WithRefresh 完成后刷新索引
+func WithRefresh() ByQueryOptFn {
+	return func(o *ByQueryOption) {
+		o.Refresh = true
+	}
+}
+
+// WithAsync 提交任务后立即返回，不等待完成
+func WithAsync() ByQueryOptFn {
+	return func(o *ByQueryOption) {
+		o.Async = true
+	}
+}
+
+// WithPollInterval 设置等待任务完成时的轮询间隔
+func WithPollInterval(d time.Duration) ByQueryOptFn {
+	return func(o *ByQueryOption) {
+		o.PollInterval = d
+	}
+}
+
+// NewByQueryOption 应用选项并填充默认值，供各实现使用
+func NewByQueryOption(opts ...ByQueryOptFn) *ByQueryOption {
+	o := &ByQueryOption{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	if o.PollInterval <= 0 {
+		o.PollInterval = DefaultTaskPollInterval
+	}
+	return o
+}
+
+// ByQueryResponse UpdateByQuery、DeleteByQuery、Reindex 的执行结果
+type ByQueryResponse struct {
+	TaskID           string            `json:"-"`                 // 任务 ID
+	Total            int64             `json:"total"`             // 匹配的文档数
+	Created          int64             `json:"created"`           // 创建的文档数（Reindex）
+	Updated          int64             `json:"updated"`           // 更新的文档数
+	Deleted          int64             `json:"deleted"`           // 删除的文档数
+	VersionConflicts int64             `json:"version_conflicts"` // 版本冲突数
+	TimedOut         bool              `json:"timed_out"`         // 是否有请求超时
+	Failures         []json.RawMessage `json:"failures"`          // 失败详情
+}
@@ -2,6 +2,7 @@ package es
 
 import (
 	"context"
+	"time"
 )
 
 // Client Elasticsearch 客户端接口
@@ -14,16 +15,43 @@ type Client interface {
 	Delete(ctx context.Context, index, id string) error
 	// Search 搜索文档
 	Search(ctx context.Context, index string, req *Request) (*Response, error)
+	// SearchAll 遍历所有匹配的文档，每批命中调用一次 fn，fn 返回错误时停止遍历并返回该错误
+	// ES8 使用 PIT + search_after，ES7 使用 scroll，req 中的 Size、From、SearchAfter 会被忽略
+	SearchAll(ctx context.Context, index string, req *Request, fn func(hits []Hit) error, opts ...SearchAllOptFn) error
+	// Count 返回匹配查询的文档数，query 为 nil 时返回索引的文档总数
+	Count(ctx context.Context, index string, query *Query) (int64, error)
+	// MGet 按 ID 批量获取文档，按 ids 的顺序返回存在的文档
+	MGet(ctx context.Context, index string, ids []string) ([]Hit, error)
+	// UpdateByQuery 使用脚本更新所有匹配的文档，默认等待任务完成
+	// 部分文档失败时同时返回结果与 ErrByQueryFailed
+	UpdateByQuery(ctx context.Context, index string, query *Query, script *Script, opts ...ByQueryOptFn) (*ByQueryResponse, error)
+	// DeleteByQuery 删除所有匹配的文档，默认等待任务完成，query 不能为空
+	// 部分文档失败时同时返回结果与 ErrByQueryFailed
+	DeleteByQuery(ctx context.Context, index string, query *Query, opts ...ByQueryOptFn) (*ByQueryResponse, error)
 	// Exists 检查索引是否存在
 	Exists(ctx context.Context, index string) (bool, error)
 	// CreateIndex 创建索引
 	CreateIndex(ctx context.Context, index string, properties map[string]any) error
 	// DeleteIndex 删除索引
 	DeleteIndex(ctx context.Context, index string) error
+	// CreateAlias 为索引添加别名
+	CreateAlias(ctx context.Context, index, alias string) error
+	// GetAlias 返回别名指向的索引，别名不存在时返回空
+	GetAlias(ctx context.Context, alias string) ([]string, error)
+	// SwapAlias 原子地将别名从当前指向的所有索引切换到 newIndex
+	SwapAlias(ctx context.Context, alias, newIndex string) error
+	// Reindex 将 srcIndex 的文档复制到 dstIndex，默认等待任务完成
+	Reindex(ctx context.Context, srcIndex, dstIndex string, opts ...ByQueryOptFn) (*ByQueryResponse, error)
+	// PutIndexSettings 修改索引的动态设置，键为 SettingXxx 等完整设置名，值为 nil 时恢复默认值
+	PutIndexSettings(ctx context.Context, index string, settings map[string]any) error
+	// SetRefreshInterval 设置索引刷新间隔，取值见 RefreshIntervalValue
+	SetRefreshInterval(ctx context.Context, index string, interval time.Duration) error
+	// PutILMPolicy 创建或更新 ILM 策略
+	PutILMPolicy(ctx context.Context, name string, policy *ILMPolicy) error
 	// Types 返回类型工具
 	Types() Types
 	// NewBulkIndexer 创建批量索引器
-	NewBulkIndexer(index string) (BulkIndexer, error)
+	NewBulkIndexer(index string, opts ...BulkIndexerOptFn) (BulkIndexer, error)
 }
 
 // Types 类型工具接口
@@ -34,12 +62,17 @@ type Types interface {
 	NewTextProperty() any
 	// NewUnsignedLongNumberProperty 创建无符号长整型数字属性
 	NewUnsignedLongNumberProperty() any
+	// NewDenseVectorProperty 创建稠密向量属性，similarity 为 Similarity 常量，为空时使用服务端默认值
+	// ES7 的 dense_vector 不支持索引，similarity 会被忽略，只能用于脚本打分
+	NewDenseVectorProperty(dims int, similarity string) any
 }
 
 // BulkIndexer 批量索引器接口
 type BulkIndexer interface {
 	// Add 添加索引项
 	Add(ctx context.Context, item BulkIndexerItem) error
-	// Close 关闭批量索引器
+	// Close 刷新剩余文档并关闭批量索引器
 	Close(ctx context.Context) error
+	// Stats 返回统计信息，可用于监控文档丢失
+	Stats() BulkIndexerStats
 }
@@ -0,0 +1,18 @@
+package es
+
+import (
+	"context"
+	"fmt"
+)
+
+// Ready 供就绪探针调用，检查集群是否可访问以及 index 是否存在，index 可以是别名
+func Ready(ctx context.Context, c Client, index string) error {
+	exists, err := c.Exists(ctx, index)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return fmt.Errorf("index %s not found", index)
+	}
+	return nil
+}
@@ -0,0 +1,171 @@
+package es
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/ZampoRen/go-server-comon/internal/infra/es"
+)
+
+// bulkRetrier 处理批量索引中失败的文档：可重试的失败以单文档 bulk 请求重试，最终失败时回调并计数
+// esutil 的回调在 worker 中同步执行，重试同步进行，不能重新 Add 到索引器（队列满时会死锁）
+type bulkRetrier struct {
+	index   string
+	opt     *es.BulkIndexerOption
+	perform func(req *http.Request) (*http.Response, error)
+
+	recovered atomic.Uint64
+	failed    atomic.Uint64
+	retried   atomic.Uint64
+}
+
+// readBody 读取文档内容，重试时需要重新发送
+func readBody(body io.ReadSeeker) ([]byte, error) {
+	if body == nil {
+		return nil, nil
+	}
+	if _, err := body.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	return io.ReadAll(body)
+}
+
+// bulkRetryable 429 与 5xx 可以重试
+func bulkRetryable(status int) bool {
+	return status == http.StatusTooManyRequests || status >= http.StatusInternalServerError
+}
+
+// onFailure 处理失败的文档，status 与 reason 来自 bulk 响应项
+func (r *bulkRetrier) onFailure(ctx context.Context, item es.BulkIndexerItem, body []byte, status int, err error) {
+	for attempt := 0; attempt < r.opt.MaxRetries && (err != nil && status == 0 || bulkRetryable(status)); attempt++ {
+		select {
+		case <-ctx.Done():
+			err = ctx.Err()
+			r.fail(ctx, item, status, err)
+			return
+		case <-time.After(r.opt.RetryBackoff << attempt):
+		}
+
+		r.retried.Add(1)
+		status, err = r.do(ctx, item, body)
+		if err == nil {
+			r.recovered.Add(1)
+			return
+		}
+	}
+	r.fail(ctx, item, status, err)
+}
+
+func (r *bulkRetrier) fail(ctx context.Context, item es.BulkIndexerItem, status int, err error) {
+	r.failed.Add(1)
+	if r.opt.OnFailure != nil {
+		r.opt.OnFailure(ctx, item, status, err)
+	}
+}
+
+// do 发送单文档 bulk 请求，返回响应项的状态码
+func (r *bulkRetrier) do(ctx context.Context, item es.BulkIndexerItem, body []byte) (int, error) {
+	index := item.Index
+	if index == "" {
+		index = r.index
+	}
+	meta := map[string]any{"_index": index}
+	if item.DocumentID != "" {
+		meta["_id"] = item.DocumentID
+	}
+	if item.Routing != "" {
+		meta["routing"] = item.Routing
+	}
+	if item.Version != nil {
+		meta["version"] = *item.Version
+	}
+	if item.VersionType != "" {
+		meta["version_type"] = item.VersionType
+	}
+	if item.RetryOnConflict != nil {
+		meta["retry_on_conflict"] = *item.RetryOnConflict
+	}
+	action := item.Action
+	if action == "" {
+		action = "index"
+	}
+	line, err := json.Marshal(map[string]any{action: meta})
+	if err != nil {
+		return 0, err
+	}
+
+	var buf bytes.Buffer
+	buf.Write(line)
+	buf.WriteByte('\n')
+	if action != "delete" {
+		buf.Write(bytes.TrimSpace(body))
+		buf.WriteByte('\n')
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, "/_bulk", &buf)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/x-ndjson")
+
+	res, err := r.perform(req)
+	if err != nil {
+		return 0, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode > 299 {
+		return res.StatusCode, fmt.Errorf("bulk request failed, status: %d", res.StatusCode)
+	}
+
+	var result struct {
+		Items []map[string]struct {
+			Status int `json:"status"`
+			Error  *struct {
+				Type   string `json:"type"`
+				Reason string `json:"reason"`
+			} `json:"error"`
+		} `json:"items"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&result); err != nil {
+		return 0, err
+	}
+	for _, item := range result.Items {
+		for _, info := range item {
+			if info.Error != nil || info.Status > 299 {
+				reason := ""
+				if info.Error != nil {
+					reason = info.Error.Type + ": " + info.Error.Reason
+				}
+				return info.Status, fmt.Errorf("bulk item failed, status: %d, %s", info.Status, reason)
+			}
+			return info.Status, nil
+		}
+	}
+	return 0, fmt.Errorf("bulk response has no items")
+}
+
+// stats 合并 esutil 的统计与重试统计
+func (r *bulkRetrier) stats(added, flushed, requests, indexed uint64) es.BulkIndexerStats {
+	return es.BulkIndexerStats{
+		Added:    added,
+		Indexed:  indexed + r.recovered.Load(),
+		Failed:   r.failed.Load(),
+		Retried:  r.retried.Load(),
+		Flushed:  flushed,
+		Requests: requests,
+	}
+}
+
+// itemError 将 bulk 响应项的错误转换为 error
+func itemError(status int, errType, reason string, err error) error {
+	if err != nil {
+		return err
+	}
+	return fmt.Errorf("bulk item failed, status: %d, %s: %s", status, errType, reason)
+}
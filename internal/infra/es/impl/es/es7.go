@@ -6,7 +6,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
-	"os"
+	"time"
 
 	"github.com/elastic/go-elasticsearch/v7"
 	"github.com/elastic/go-elasticsearch/v7/esapi"
@@ -22,17 +22,20 @@ type es7Client struct {
 	esClient *elasticsearch.Client
 }
 
-func newES7() (Client, error) {
-	addresses, err := parseClusterEndpoints(os.Getenv("ES_ADDR"))
+func newES7(o *option) (Client, error) {
+	addresses, err := parseClusterEndpoints(o.addr)
+	if err != nil {
+		return nil, err
+	}
+	transport, err := newTransport(o)
 	if err != nil {
 		return nil, err
 	}
-	esUsername := os.Getenv("ES_USERNAME")
-	esPassword := os.Getenv("ES_PASSWORD")
 	esClient, err := elasticsearch.NewClient(elasticsearch.Config{
 		Addresses: addresses,
-		Username:  esUsername,
-		Password:  esPassword,
+		Username:  o.username,
+		Password:  o.password,
+		Transport: transport,
 	})
 	if err != nil {
 		return nil, err
@@ -139,6 +142,41 @@ func (c *es7Client) DeleteIndex(ctx context.Context, index string) error {
 }
 
 func (c *es7Client) Search(ctx context.Context, index string, req *Request) (*Response, error) {
+	if req.Knn != nil {
+		return nil, es.ErrKnnNotSupported
+	}
+
+	body, err := json.Marshal(c.searchBody(req))
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := c.esClient.Search(
+		c.esClient.Search.WithContext(ctx),
+		c.esClient.Search.WithIndex(index),
+		c.esClient.Search.WithBody(bytes.NewReader(body)),
+	)
+
+	hlog.CtxDebugf(ctx, "[Search] req : %s", string(body))
+
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	respBytes, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	var esResp Response
+	if err := json.Unmarshal(respBytes, &esResp); err != nil {
+		return nil, err
+	}
+	return &esResp, nil
+}
+
+func (c *es7Client) searchBody(req *Request) map[string]any {
 	queryBody := map[string]any{}
 	if q := c.query2ESQuery(req.Query); q != nil {
 		queryBody["query"] = q
@@ -163,6 +201,10 @@ func (c *es7Client) Search(ctx context.Context, index string, req *Request) (*Re
 		queryBody["sort"] = sorts
 	}
 
+	if len(req.Aggs) > 0 {
+		queryBody["aggs"] = c.aggs2ESAggs(req.Aggs)
+	}
+
 	if req.From != nil {
 		queryBody["from"] = *req.From
 	} else {
@@ -171,34 +213,110 @@ func (c *es7Client) Search(ctx context.Context, index string, req *Request) (*Re
 		}
 	}
 
+	return queryBody
+}
+
+// scrollResponse 带 scroll ID 的搜索响应
+type scrollResponse struct {
+	Response
+	ScrollID string `json:"_scroll_id"`
+}
+
+// SearchAll 使用 scroll 遍历，scroll 保证遍历期间看到一致的快照
+func (c *es7Client) SearchAll(ctx context.Context, index string, req *Request, fn func(hits []Hit) error, opts ...es.SearchAllOptFn) error {
+	o := es.NewSearchAllOption(opts...)
+
+	r := &Request{}
+	if req != nil {
+		*r = *req
+	}
+	if r.Knn != nil {
+		return es.ErrKnnNotSupported
+	}
+	r.Size = ptr.Of(o.BatchSize)
+	r.From = nil
+	r.SearchAfter = nil
+	queryBody := c.searchBody(r)
+	if len(r.Sort) == 0 {
+		// _doc 是 scroll 下最高效的排序
+		queryBody["sort"] = []string{"_doc"}
+	}
+
 	body, err := json.Marshal(queryBody)
 	if err != nil {
-		return nil, err
+		return err
 	}
+	hlog.CtxDebugf(ctx, "[SearchAll] req : %s", string(body))
 
 	res, err := c.esClient.Search(
 		c.esClient.Search.WithContext(ctx),
 		c.esClient.Search.WithIndex(index),
 		c.esClient.Search.WithBody(bytes.NewReader(body)),
+		c.esClient.Search.WithScroll(o.KeepAlive),
 	)
 
-	hlog.CtxDebugf(ctx, "[Search] req : %s", string(body))
+	var scrollID string
+	defer func() {
+		if scrollID == "" {
+			return
+		}
+		res, err := c.esClient.ClearScroll(
+			c.esClient.ClearScroll.WithContext(context.WithoutCancel(ctx)),
+			c.esClient.ClearScroll.WithScrollID(scrollID),
+		)
+		if err != nil {
+			hlog.CtxWarnf(ctx, "[SearchAll] clear scroll failed, err: %v", err)
+			return
+		}
+		res.Body.Close()
+	}()
+
+	var seen int64
+	for {
+		page, err := decodeScrollResponse(res, err)
+		if err != nil {
+			return err
+		}
+		scrollID = page.ScrollID
+
+		hits := page.Hits.Hits
+		if len(hits) == 0 {
+			return nil
+		}
+
+		hits, done := o.Limit(hits, seen)
+		seen += int64(len(hits))
+		if err := fn(hits); err != nil {
+			return err
+		}
+		if done {
+			hlog.CtxWarnf(ctx, "[SearchAll] max docs reached, index: %s, max docs: %d", index, o.MaxDocs)
+			return nil
+		}
 
+		res, err = c.esClient.Scroll(
+			c.esClient.Scroll.WithContext(ctx),
+			c.esClient.Scroll.WithScrollID(scrollID),
+			c.esClient.Scroll.WithScroll(o.KeepAlive),
+		)
+	}
+}
+
+func decodeScrollResponse(res *esapi.Response, err error) (*scrollResponse, error) {
 	if err != nil {
 		return nil, err
 	}
 	defer res.Body.Close()
 
-	respBytes, err := io.ReadAll(res.Body)
-	if err != nil {
-		return nil, err
+	if res.IsError() {
+		return nil, fmt.Errorf("scroll search failed: %s", res.String())
 	}
 
-	var esResp Response
-	if err := json.Unmarshal(respBytes, &esResp); err != nil {
+	var page scrollResponse
+	if err := json.NewDecoder(res.Body).Decode(&page); err != nil {
 		return nil, err
 	}
-	return &esResp, nil
+	return &page, nil
 }
 
 func (c *es7Client) query2ESQuery(q *Query) map[string]any {
@@ -253,6 +371,43 @@ func (c *es7Client) query2ESQuery(q *Query) map[string]any {
 				q.KV.Key: q.KV.Value,
 			},
 		}
+	case es.QueryTypeRange:
+		r := map[string]any{}
+		if q.RangeQuery.GTE != nil {
+			r["gte"] = q.RangeQuery.GTE
+		}
+		if q.RangeQuery.LTE != nil {
+			r["lte"] = q.RangeQuery.LTE
+		}
+		base = map[string]any{
+			"range": map[string]any{
+				q.KV.Key: r,
+			},
+		}
+	case es.QueryTypeExists:
+		base = map[string]any{
+			"exists": map[string]any{"field": q.KV.Key},
+		}
+	case es.QueryTypePrefix:
+		base = map[string]any{
+			"prefix": map[string]any{
+				q.KV.Key: map[string]any{"value": fmt.Sprint(q.KV.Value)},
+			},
+		}
+	case es.QueryTypeWildcard:
+		base = map[string]any{
+			"wildcard": map[string]any{
+				q.KV.Key: map[string]any{"value": fmt.Sprint(q.KV.Value)},
+			},
+		}
+	case es.QueryTypeRaw:
+		// NewRawQuery 已校验为单个查询子句
+		var clause map[string]json.RawMessage
+		_ = json.Unmarshal(q.RawQuery, &clause)
+		base = make(map[string]any, len(clause))
+		for k, v := range clause {
+			base[k] = v
+		}
 	default:
 		base = map[string]any{}
 	}
@@ -301,47 +456,98 @@ func (c *es7Client) query2ESQuery(q *Query) map[string]any {
 	return map[string]any{"bool": boolQuery}
 }
 
-func (c *es7Client) NewBulkIndexer(index string) (BulkIndexer, error) {
+func (c *es7Client) aggs2ESAggs(aggs map[string]es.Aggregation) map[string]any {
+	res := make(map[string]any, len(aggs))
+	for name, a := range aggs {
+		body := map[string]any{"field": a.Field}
+		switch a.Type {
+		case es.AggTypeTerms:
+			if a.Size != nil {
+				body["size"] = *a.Size
+			}
+			if a.MinDocCount != nil {
+				body["min_doc_count"] = *a.MinDocCount
+			}
+		case es.AggTypeDateHistogram:
+			if a.CalendarInterval != "" {
+				body["calendar_interval"] = a.CalendarInterval
+			}
+			if a.FixedInterval != "" {
+				body["fixed_interval"] = a.FixedInterval
+			}
+			if a.Format != "" {
+				body["format"] = a.Format
+			}
+			if a.MinDocCount != nil {
+				body["min_doc_count"] = *a.MinDocCount
+			}
+		}
+
+		agg := map[string]any{string(a.Type): body}
+		if len(a.Aggs) > 0 {
+			agg["aggs"] = c.aggs2ESAggs(a.Aggs)
+		}
+		res[name] = agg
+	}
+	return res
+}
+
+func (c *es7Client) NewBulkIndexer(index string, opts ...es.BulkIndexerOptFn) (BulkIndexer, error) {
+	o := es.NewBulkIndexerOption(opts...)
 	bi, err := esutil.NewBulkIndexer(esutil.BulkIndexerConfig{
-		Client: c.esClient,
-		Index:  index,
+		Client:        c.esClient,
+		Index:         index,
+		NumWorkers:    o.NumWorkers,
+		FlushBytes:    o.FlushBytes,
+		FlushInterval: o.FlushInterval,
 	})
 	if err != nil {
 		return nil, err
 	}
-	return &es7BulkIndexer{bi: bi}, nil
+	return &es7BulkIndexer{
+		bi:      bi,
+		retrier: &bulkRetrier{index: index, opt: o, perform: c.esClient.Perform},
+	}, nil
 }
 
 type es7BulkIndexer struct {
-	bi esutil.BulkIndexer
+	bi      esutil.BulkIndexer
+	retrier *bulkRetrier
 }
 
 func (b *es7BulkIndexer) Add(ctx context.Context, item BulkIndexerItem) error {
-	var buf bytes.Buffer
-	if item.Body != nil {
-		data, err := json.Marshal(item.Body)
-		if err != nil {
-			return err
-		}
-		buf.Write(data)
+	body, err := readBody(item.Body)
+	if err != nil {
+		return err
 	}
 
-	return b.bi.Add(ctx, esutil.BulkIndexerItem{
+	esItem := esutil.BulkIndexerItem{
+		Index:           item.Index,
 		Action:          item.Action,
 		DocumentID:      item.DocumentID,
-		Body:            &buf,
 		Routing:         item.Routing,
 		Version:         item.Version,
 		VersionType:     item.VersionType,
 		RetryOnConflict: item.RetryOnConflict,
-	},
-	)
+		OnFailure: func(ctx context.Context, _ esutil.BulkIndexerItem, res esutil.BulkIndexerResponseItem, err error) {
+			b.retrier.onFailure(ctx, item, body, res.Status, itemError(res.Status, res.Error.Type, res.Error.Reason, err))
+		},
+	}
+	if body != nil {
+		esItem.Body = bytes.NewReader(body)
+	}
+	return b.bi.Add(ctx, esItem)
 }
 
 func (b *es7BulkIndexer) Close(ctx context.Context) error {
 	return b.bi.Close(ctx)
 }
 
+func (b *es7BulkIndexer) Stats() es.BulkIndexerStats {
+	s := b.bi.Stats()
+	return b.retrier.stats(s.NumAdded, s.NumFlushed, s.NumRequests, s.NumIndexed+s.NumCreated+s.NumUpdated+s.NumDeleted)
+}
+
 func (c *es7Client) Types() Types {
 	return &es7Types{}
 }
@@ -359,3 +565,306 @@ func (t *es7Types) NewTextProperty() any {
 func (t *es7Types) NewUnsignedLongNumberProperty() any {
 	return map[string]string{"type": "unsigned_long"}
 }
+
+func (t *es7Types) NewDenseVectorProperty(dims int, similarity string) any {
+	return map[string]any{"type": "dense_vector", "dims": dims}
+}
+
+func (c *es7Client) UpdateByQuery(ctx context.Context, index string, query *Query, script *es.Script, opts ...es.ByQueryOptFn) (*es.ByQueryResponse, error) {
+	o := es.NewByQueryOption(opts...)
+
+	body := map[string]any{}
+	if q := c.query2ESQuery(query); q != nil {
+		body["query"] = q
+	}
+	if script != nil {
+		s := map[string]any{"source": script.Source}
+		if script.Lang != "" {
+			s["lang"] = script.Lang
+		}
+		if len(script.Params) > 0 {
+			s["params"] = script.Params
+		}
+		body["script"] = s
+	}
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+
+	hlog.CtxDebugf(ctx, "[UpdateByQuery] req : %s", string(data))
+
+	res, err := c.esClient.UpdateByQuery(
+		[]string{index},
+		c.esClient.UpdateByQuery.WithContext(ctx),
+		c.esClient.UpdateByQuery.WithBody(bytes.NewReader(data)),
+		c.esClient.UpdateByQuery.WithConflicts(conflictsParam(o)),
+		c.esClient.UpdateByQuery.WithRefresh(o.Refresh),
+		c.esClient.UpdateByQuery.WithWaitForCompletion(false),
+	)
+	return c.waitByQuery(ctx, res, err, o)
+}
+
+func (c *es7Client) DeleteByQuery(ctx context.Context, index string, query *Query, opts ...es.ByQueryOptFn) (*es.ByQueryResponse, error) {
+	if query == nil {
+		return nil, es.ErrQueryRequired
+	}
+	o := es.NewByQueryOption(opts...)
+
+	data, err := json.Marshal(map[string]any{"query": c.query2ESQuery(query)})
+	if err != nil {
+		return nil, err
+	}
+
+	hlog.CtxDebugf(ctx, "[DeleteByQuery] req : %s", string(data))
+
+	res, err := c.esClient.DeleteByQuery(
+		[]string{index},
+		bytes.NewReader(data),
+		c.esClient.DeleteByQuery.WithContext(ctx),
+		c.esClient.DeleteByQuery.WithConflicts(conflictsParam(o)),
+		c.esClient.DeleteByQuery.WithRefresh(o.Refresh),
+		c.esClient.DeleteByQuery.WithWaitForCompletion(false),
+	)
+	return c.waitByQuery(ctx, res, err, o)
+}
+
+// conflictsParam 返回 conflicts 参数
+func conflictsParam(o *es.ByQueryOption) string {
+	if o.ProceedOnConflicts {
+		return "proceed"
+	}
+	return "abort"
+}
+
+// waitByQuery 解析提交任务的响应，并在非异步模式下轮询任务直到完成
+func (c *es7Client) waitByQuery(ctx context.Context, res *esapi.Response, err error, o *es.ByQueryOption) (*es.ByQueryResponse, error) {
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.IsError() {
+		return nil, fmt.Errorf("submit by query task failed: %s", res.String())
+	}
+
+	var submitted struct {
+		Task string `json:"task"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&submitted); err != nil {
+		return nil, err
+	}
+	if o.Async {
+		return &es.ByQueryResponse{TaskID: submitted.Task}, nil
+	}
+
+	ticker := time.NewTicker(o.PollInterval)
+	defer ticker.Stop()
+	for {
+		task, err := c.getTask(ctx, submitted.Task)
+		if err != nil {
+			return nil, err
+		}
+		if task.Completed {
+			return parseTaskResult(submitted.Task, task.Error, task.Response)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// taskResult 任务 API 的响应
+type taskResult struct {
+	Completed bool            `json:"completed"`
+	Error     json.RawMessage `json:"error"`
+	Response  json.RawMessage `json:"response"`
+}
+
+func (c *es7Client) getTask(ctx context.Context, taskID string) (*taskResult, error) {
+	res, err := c.esClient.Tasks.Get(taskID, c.esClient.Tasks.Get.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.IsError() {
+		return nil, fmt.Errorf("get task %s failed: %s", taskID, res.String())
+	}
+
+	var task taskResult
+	if err := json.NewDecoder(res.Body).Decode(&task); err != nil {
+		return nil, err
+	}
+	return &task, nil
+}
+
+func (c *es7Client) Count(ctx context.Context, index string, query *Query) (int64, error) {
+	opts := []func(*esapi.CountRequest){
+		c.esClient.Count.WithContext(ctx),
+		c.esClient.Count.WithIndex(index),
+	}
+	if q := c.query2ESQuery(query); q != nil {
+		body, err := json.Marshal(map[string]any{"query": q})
+		if err != nil {
+			return 0, err
+		}
+		hlog.CtxDebugf(ctx, "[Count] req : %s", string(body))
+		opts = append(opts, c.esClient.Count.WithBody(bytes.NewReader(body)))
+	}
+
+	res, err := c.esClient.Count(opts...)
+	if err != nil {
+		return 0, err
+	}
+	defer res.Body.Close()
+	if res.IsError() {
+		return 0, fmt.Errorf("count failed: %s", res.String())
+	}
+
+	var result struct {
+		Count int64 `json:"count"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&result); err != nil {
+		return 0, err
+	}
+	return result.Count, nil
+}
+
+func (c *es7Client) MGet(ctx context.Context, index string, ids []string) ([]Hit, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	body, err := json.Marshal(map[string]any{"ids": ids})
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := c.esClient.Mget(
+		bytes.NewReader(body),
+		c.esClient.Mget.WithContext(ctx),
+		c.esClient.Mget.WithIndex(index),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.IsError() {
+		return nil, fmt.Errorf("mget failed: %s", res.String())
+	}
+
+	var result mgetResponse
+	if err := json.NewDecoder(res.Body).Decode(&result); err != nil {
+		return nil, err
+	}
+	return result.hits(), nil
+}
+
+func (c *es7Client) CreateAlias(ctx context.Context, index, alias string) error {
+	res, err := c.esClient.Indices.PutAlias([]string{index}, alias, c.esClient.Indices.PutAlias.WithContext(ctx))
+	return checkResponse(res, err, "create alias")
+}
+
+func (c *es7Client) GetAlias(ctx context.Context, alias string) ([]string, error) {
+	res, err := c.esClient.Indices.GetAlias(
+		c.esClient.Indices.GetAlias.WithContext(ctx),
+		c.esClient.Indices.GetAlias.WithName(alias),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode == 404 {
+		return nil, nil
+	}
+	if res.IsError() {
+		return nil, fmt.Errorf("get alias failed: %s", res.String())
+	}
+
+	var result map[string]any
+	if err := json.NewDecoder(res.Body).Decode(&result); err != nil {
+		return nil, err
+	}
+	return sortedKeys(result), nil
+}
+
+func (c *es7Client) SwapAlias(ctx context.Context, alias, newIndex string) error {
+	old, err := c.GetAlias(ctx, alias)
+	if err != nil {
+		return err
+	}
+
+	body, err := json.Marshal(map[string]any{"actions": aliasActions(alias, old, newIndex)})
+	if err != nil {
+		return err
+	}
+	hlog.CtxDebugf(ctx, "[SwapAlias] req : %s", string(body))
+
+	res, err := c.esClient.Indices.UpdateAliases(bytes.NewReader(body), c.esClient.Indices.UpdateAliases.WithContext(ctx))
+	return checkResponse(res, err, "swap alias")
+}
+
+func (c *es7Client) Reindex(ctx context.Context, srcIndex, dstIndex string, opts ...es.ByQueryOptFn) (*es.ByQueryResponse, error) {
+	o := es.NewByQueryOption(opts...)
+
+	body, err := json.Marshal(map[string]any{
+		"conflicts": conflictsParam(o),
+		"source":    map[string]any{"index": srcIndex},
+		"dest":      map[string]any{"index": dstIndex},
+	})
+	if err != nil {
+		return nil, err
+	}
+	hlog.CtxDebugf(ctx, "[Reindex] req : %s", string(body))
+
+	res, err := c.esClient.Reindex(
+		bytes.NewReader(body),
+		c.esClient.Reindex.WithContext(ctx),
+		c.esClient.Reindex.WithRefresh(o.Refresh),
+		c.esClient.Reindex.WithWaitForCompletion(false),
+	)
+	return c.waitByQuery(ctx, res, err, o)
+}
+
+// checkResponse 检查响应状态并关闭响应
+func checkResponse(res *esapi.Response, err error, op string) error {
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.IsError() {
+		return fmt.Errorf("%s failed: %s", op, res.String())
+	}
+	return nil
+}
+
+func (c *es7Client) PutIndexSettings(ctx context.Context, index string, settings map[string]any) error {
+	body, err := json.Marshal(settings)
+	if err != nil {
+		return err
+	}
+	res, err := c.esClient.Indices.PutSettings(
+		bytes.NewReader(body),
+		c.esClient.Indices.PutSettings.WithContext(ctx),
+		c.esClient.Indices.PutSettings.WithIndex(index),
+	)
+	return checkResponse(res, err, "put index settings")
+}
+
+func (c *es7Client) SetRefreshInterval(ctx context.Context, index string, interval time.Duration) error {
+	return c.PutIndexSettings(ctx, index, map[string]any{es.SettingRefreshInterval: es.RefreshIntervalValue(interval)})
+}
+
+func (c *es7Client) PutILMPolicy(ctx context.Context, name string, policy *es.ILMPolicy) error {
+	body, err := json.Marshal(policy.Body())
+	if err != nil {
+		return err
+	}
+	res, err := c.esClient.ILM.PutLifecycle(
+		name,
+		c.esClient.ILM.PutLifecycle.WithContext(ctx),
+		c.esClient.ILM.PutLifecycle.WithBody(bytes.NewReader(body)),
+	)
+	return checkResponse(res, err, "put ilm policy")
+}
@@ -1,9 +1,11 @@
 package es
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
-	"os"
+	"time"
 
 	"github.com/elastic/go-elasticsearch/v8"
 	"github.com/elastic/go-elasticsearch/v8/esutil"
@@ -12,7 +14,11 @@ import (
 	"github.com/elastic/go-elasticsearch/v8/typedapi/indices/delete"
 	"github.com/elastic/go-elasticsearch/v8/typedapi/indices/exists"
 	"github.com/elastic/go-elasticsearch/v8/typedapi/types"
+	"github.com/elastic/go-elasticsearch/v8/typedapi/types/enums/calendarinterval"
+	"github.com/elastic/go-elasticsearch/v8/typedapi/types/enums/conflicts"
+	"github.com/elastic/go-elasticsearch/v8/typedapi/types/enums/densevectorsimilarity"
 	"github.com/elastic/go-elasticsearch/v8/typedapi/types/enums/operator"
+	"github.com/elastic/go-elasticsearch/v8/typedapi/types/enums/scriptlanguage"
 	"github.com/elastic/go-elasticsearch/v8/typedapi/types/enums/sortorder"
 	"github.com/elastic/go-elasticsearch/v8/typedapi/types/enums/textquerytype"
 
@@ -29,22 +35,26 @@ type es8Client struct {
 }
 
 type es8BulkIndexer struct {
-	bi esutil.BulkIndexer
+	bi      esutil.BulkIndexer
+	retrier *bulkRetrier
 }
 
 type es8Types struct{}
 
-func newES8() (Client, error) {
-	addresses, err := parseClusterEndpoints(os.Getenv("ES_ADDR"))
+func newES8(o *option) (Client, error) {
+	addresses, err := parseClusterEndpoints(o.addr)
+	if err != nil {
+		return nil, err
+	}
+	transport, err := newTransport(o)
 	if err != nil {
 		return nil, err
 	}
-	esUsername := os.Getenv("ES_USERNAME")
-	esPassword := os.Getenv("ES_PASSWORD")
 	esClient, err := elasticsearch.NewTypedClient(elasticsearch.Config{
 		Addresses: addresses,
-		Username:  esUsername,
-		Password:  esPassword,
+		Username:  o.username,
+		Password:  o.password,
+		Transport: transport,
 	})
 	if err != nil {
 		return nil, err
@@ -131,6 +141,40 @@ func (c *es8Client) query2ESQuery(q *Query) *types.Query {
 				},
 			},
 		}
+	case es.QueryTypeRange:
+		// types.RangeQuery 为 any，字段类型（数字、日期、字符串）由服务端根据 mapping 解析
+		r := map[string]any{}
+		if q.RangeQuery.GTE != nil {
+			r["gte"] = q.RangeQuery.GTE
+		}
+		if q.RangeQuery.LTE != nil {
+			r["lte"] = q.RangeQuery.LTE
+		}
+		typesQ = &types.Query{
+			Range: map[string]types.RangeQuery{
+				q.KV.Key: r,
+			},
+		}
+	case es.QueryTypeExists:
+		typesQ = &types.Query{
+			Exists: &types.ExistsQuery{Field: q.KV.Key},
+		}
+	case es.QueryTypePrefix:
+		typesQ = &types.Query{
+			Prefix: map[string]types.PrefixQuery{
+				q.KV.Key: {Value: fmt.Sprint(q.KV.Value)},
+			},
+		}
+	case es.QueryTypeWildcard:
+		typesQ = &types.Query{
+			Wildcard: map[string]types.WildcardQuery{
+				q.KV.Key: {Value: ptr.Of(fmt.Sprint(q.KV.Value))},
+			},
+		}
+	case es.QueryTypeRaw:
+		// 通过 AdditionalQueryProperty 原样传递，不受类型化结构限制
+		typesQ = &types.Query{}
+		_ = json.Unmarshal(q.RawQuery, &typesQ.AdditionalQueryProperty)
 	default:
 		typesQ = &types.Query{}
 	}
@@ -167,13 +211,77 @@ func (c *es8Client) query2ESQuery(q *Query) *types.Query {
 	return typesQ
 }
 
+func (c *es8Client) aggs2ESAggs(aggs map[string]es.Aggregation) map[string]types.Aggregations {
+	res := make(map[string]types.Aggregations, len(aggs))
+	for name, a := range aggs {
+		var agg types.Aggregations
+		switch a.Type {
+		case es.AggTypeTerms:
+			agg.Terms = &types.TermsAggregation{
+				Field:       ptr.Of(a.Field),
+				Size:        a.Size,
+				MinDocCount: a.MinDocCount,
+			}
+		case es.AggTypeDateHistogram:
+			h := &types.DateHistogramAggregation{
+				Field:       ptr.Of(a.Field),
+				MinDocCount: a.MinDocCount,
+			}
+			if a.CalendarInterval != "" {
+				h.CalendarInterval = &calendarinterval.CalendarInterval{Name: a.CalendarInterval}
+			}
+			if a.FixedInterval != "" {
+				h.FixedInterval = a.FixedInterval
+			}
+			if a.Format != "" {
+				h.Format = ptr.Of(a.Format)
+			}
+			agg.DateHistogram = h
+		case es.AggTypeSum:
+			agg.Sum = &types.SumAggregation{Field: ptr.Of(a.Field)}
+		case es.AggTypeAvg:
+			agg.Avg = &types.AverageAggregation{Field: ptr.Of(a.Field)}
+		case es.AggTypeMin:
+			agg.Min = &types.MinAggregation{Field: ptr.Of(a.Field)}
+		case es.AggTypeMax:
+			agg.Max = &types.MaxAggregation{Field: ptr.Of(a.Field)}
+		}
+		if len(a.Aggs) > 0 {
+			agg.Aggregations = c.aggs2ESAggs(a.Aggs)
+		}
+		res[name] = agg
+	}
+	return res
+}
+
 func (c *es8Client) Search(ctx context.Context, index string, req *Request) (*Response, error) {
+	esReq := c.searchRequest(req)
+
+	hlog.CtxDebugf(ctx, "Elasticsearch Request: %s\n", conv.DebugJsonToStr(esReq))
+
+	resp, err := c.esClient.Search().Request(esReq).Index(index).Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return toResponse(resp)
+}
+
+func (c *es8Client) searchRequest(req *Request) *search.Request {
 	esReq := &search.Request{
 		Query:    c.query2ESQuery(req.Query),
 		Size:     req.Size,
 		MinScore: (*types.Float64)(req.MinScore),
 	}
 
+	if len(req.Aggs) > 0 {
+		esReq.Aggregations = c.aggs2ESAggs(req.Aggs)
+	}
+
+	if req.Knn != nil {
+		esReq.Knn = []types.KnnSearch{c.knn2ESKnn(req.Knn)}
+	}
+
 	for _, sort := range req.Sort {
 		order := sortorder.Asc
 		if !sort.Asc {
@@ -196,13 +304,24 @@ func (c *es8Client) Search(ctx context.Context, index string, req *Request) (*Re
 		}
 	}
 
-	hlog.CtxDebugf(ctx, "Elasticsearch Request: %s\n", conv.DebugJsonToStr(esReq))
+	return esReq
+}
 
-	resp, err := c.esClient.Search().Request(esReq).Index(index).Do(ctx)
-	if err != nil {
-		return nil, err
+func (c *es8Client) knn2ESKnn(knn *es.KnnQuery) types.KnnSearch {
+	esKnn := types.KnnSearch{
+		Field:         knn.Field,
+		QueryVector:   knn.QueryVector,
+		K:             ptr.Of(knn.K),
+		NumCandidates: ptr.Of(knn.NumCandidates),
+		Similarity:    knn.Similarity,
+	}
+	if filter := c.query2ESQuery(knn.Filter); filter != nil {
+		esKnn.Filter = []types.Query{*filter}
 	}
+	return esKnn
+}
 
+func toResponse(resp *search.Response) (*Response, error) {
 	respJson, err := sonic.MarshalString(resp)
 	if err != nil {
 		return nil, err
@@ -216,6 +335,69 @@ func (c *es8Client) Search(ctx context.Context, index string, req *Request) (*Re
 	return &esResp, nil
 }
 
+// SearchAll 使用 PIT + search_after 遍历，PIT 保证遍历期间看到一致的快照
+func (c *es8Client) SearchAll(ctx context.Context, index string, req *Request, fn func(hits []Hit) error, opts ...es.SearchAllOptFn) error {
+	o := es.NewSearchAllOption(opts...)
+
+	pit, err := c.esClient.OpenPointInTime(index).KeepAlive(o.KeepAliveString()).Do(ctx)
+	if err != nil {
+		return fmt.Errorf("open point in time failed: %w", err)
+	}
+	pitID := pit.Id
+	defer func() {
+		if _, err := c.esClient.ClosePointInTime().Id(pitID).Do(context.WithoutCancel(ctx)); err != nil {
+			hlog.CtxWarnf(ctx, "[SearchAll] close point in time failed, err: %v", err)
+		}
+	}()
+
+	r := &Request{}
+	if req != nil {
+		*r = *req
+	}
+	r.Size = ptr.Of(o.BatchSize)
+	r.From = nil
+	r.SearchAfter = nil
+	if len(r.Sort) == 0 {
+		// _shard_doc 是 PIT 下最高效的排序，同时保证 search_after 游标唯一
+		r.Sort = []es.SortFiled{{Field: "_shard_doc", Asc: true}}
+	}
+
+	var seen int64
+	for {
+		esReq := c.searchRequest(r)
+		esReq.Pit = &types.PointInTimeReference{Id: pitID, KeepAlive: o.KeepAliveString()}
+
+		resp, err := c.esClient.Search().Request(esReq).Do(ctx)
+		if err != nil {
+			return err
+		}
+		if resp.PitId != nil {
+			pitID = *resp.PitId
+		}
+
+		esResp, err := toResponse(resp)
+		if err != nil {
+			return err
+		}
+
+		hits := esResp.Hits.Hits
+		if len(hits) == 0 {
+			return nil
+		}
+		r.SearchAfter = hits[len(hits)-1].Sort
+
+		hits, done := o.Limit(hits, seen)
+		seen += int64(len(hits))
+		if err := fn(hits); err != nil {
+			return err
+		}
+		if done {
+			hlog.CtxWarnf(ctx, "[SearchAll] max docs reached, index: %s, max docs: %d", index, o.MaxDocs)
+			return nil
+		}
+	}
+}
+
 func (c *es8Client) CreateIndex(ctx context.Context, index string, properties map[string]any) error {
 	propertiesMap := make(map[string]types.Property)
 	for k, v := range properties {
@@ -242,16 +424,23 @@ func (c *es8Client) DeleteIndex(ctx context.Context, index string) error {
 	return err
 }
 
-func (c *es8Client) NewBulkIndexer(index string) (BulkIndexer, error) {
+func (c *es8Client) NewBulkIndexer(index string, opts ...es.BulkIndexerOptFn) (BulkIndexer, error) {
+	o := es.NewBulkIndexerOption(opts...)
 	bi, err := esutil.NewBulkIndexer(esutil.BulkIndexerConfig{
-		Client: c.esClient,
-		Index:  index,
+		Client:        c.esClient,
+		Index:         index,
+		NumWorkers:    o.NumWorkers,
+		FlushBytes:    o.FlushBytes,
+		FlushInterval: o.FlushInterval,
 	})
 	if err != nil {
 		return nil, err
 	}
 
-	return &es8BulkIndexer{bi}, nil
+	return &es8BulkIndexer{
+		bi:      bi,
+		retrier: &bulkRetrier{index: index, opt: o, perform: c.esClient.Perform},
+	}, nil
 }
 
 func (c *es8Client) Types() Types {
@@ -270,23 +459,256 @@ func (t *es8Types) NewUnsignedLongNumberProperty() any {
 	return types.NewUnsignedLongNumberProperty()
 }
 
+func (t *es8Types) NewDenseVectorProperty(dims int, similarity string) any {
+	p := types.NewDenseVectorProperty()
+	p.Dims = ptr.Of(dims)
+	p.Index = ptr.Of(true)
+	if similarity != "" {
+		p.Similarity = &densevectorsimilarity.DenseVectorSimilarity{Name: similarity}
+	}
+	return p
+}
+
 func (b *es8BulkIndexer) Add(ctx context.Context, item BulkIndexerItem) error {
-	return b.bi.Add(ctx, esutil.BulkIndexerItem{
+	body, err := readBody(item.Body)
+	if err != nil {
+		return err
+	}
+
+	esItem := esutil.BulkIndexerItem{
 		Index:           item.Index,
 		Action:          item.Action,
 		DocumentID:      item.DocumentID,
 		Routing:         item.Routing,
 		Version:         item.Version,
 		VersionType:     item.VersionType,
-		Body:            item.Body,
 		RetryOnConflict: item.RetryOnConflict,
 		// es7 不支持
 		// RequireAlias:    item.RequireAlias,
 		// IfSeqNo:         item.IfSeqNo,
 		// IfPrimaryTerm:   item.IfPrimaryTerm,
-	})
+		OnFailure: func(ctx context.Context, _ esutil.BulkIndexerItem, res esutil.BulkIndexerResponseItem, err error) {
+			b.retrier.onFailure(ctx, item, body, res.Status, itemError(res.Status, res.Error.Type, res.Error.Reason, err))
+		},
+	}
+	if body != nil {
+		esItem.Body = bytes.NewReader(body)
+	}
+	return b.bi.Add(ctx, esItem)
 }
 
 func (b *es8BulkIndexer) Close(ctx context.Context) error {
 	return b.bi.Close(ctx)
 }
+
+func (b *es8BulkIndexer) Stats() es.BulkIndexerStats {
+	s := b.bi.Stats()
+	return b.retrier.stats(s.NumAdded, s.NumFlushed, s.NumRequests, s.NumIndexed+s.NumCreated+s.NumUpdated+s.NumDeleted)
+}
+
+func (c *es8Client) UpdateByQuery(ctx context.Context, index string, query *Query, script *es.Script, opts ...es.ByQueryOptFn) (*es.ByQueryResponse, error) {
+	o := es.NewByQueryOption(opts...)
+
+	req := c.esClient.UpdateByQuery(index).
+		Conflicts(conflictsOf(o)).
+		Refresh(o.Refresh).
+		WaitForCompletion(false)
+	if q := c.query2ESQuery(query); q != nil {
+		req = req.Query(q)
+	}
+	if script != nil {
+		s := &types.Script{Source: ptr.Of(script.Source)}
+		if script.Lang != "" {
+			s.Lang = &scriptlanguage.ScriptLanguage{Name: script.Lang}
+		}
+		if len(script.Params) > 0 {
+			s.Params = make(map[string]json.RawMessage, len(script.Params))
+			for k, v := range script.Params {
+				data, err := json.Marshal(v)
+				if err != nil {
+					return nil, fmt.Errorf("marshal script param %s failed: %w", k, err)
+				}
+				s.Params[k] = data
+			}
+		}
+		req = req.Script(s)
+	}
+
+	resp, err := req.Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return c.waitByQuery(ctx, fmt.Sprint(resp.Task), o)
+}
+
+func (c *es8Client) DeleteByQuery(ctx context.Context, index string, query *Query, opts ...es.ByQueryOptFn) (*es.ByQueryResponse, error) {
+	if query == nil {
+		return nil, es.ErrQueryRequired
+	}
+	o := es.NewByQueryOption(opts...)
+
+	resp, err := c.esClient.DeleteByQuery(index).
+		Query(c.query2ESQuery(query)).
+		Conflicts(conflictsOf(o)).
+		Refresh(o.Refresh).
+		WaitForCompletion(false).
+		Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return c.waitByQuery(ctx, fmt.Sprint(resp.Task), o)
+}
+
+// conflictsOf 返回 conflicts 参数
+func conflictsOf(o *es.ByQueryOption) conflicts.Conflicts {
+	if o.ProceedOnConflicts {
+		return conflicts.Proceed
+	}
+	return conflicts.Abort
+}
+
+// waitByQuery 非异步模式下轮询任务直到完成
+func (c *es8Client) waitByQuery(ctx context.Context, taskID string, o *es.ByQueryOption) (*es.ByQueryResponse, error) {
+	if o.Async {
+		return &es.ByQueryResponse{TaskID: taskID}, nil
+	}
+
+	ticker := time.NewTicker(o.PollInterval)
+	defer ticker.Stop()
+	for {
+		task, err := c.esClient.Tasks.Get(taskID).Do(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("get task %s failed: %w", taskID, err)
+		}
+		if task.Completed {
+			var taskErr []byte
+			if task.Error != nil {
+				if taskErr, err = json.Marshal(task.Error); err != nil {
+					return nil, err
+				}
+			}
+			return parseTaskResult(taskID, taskErr, task.Response)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+func (c *es8Client) Count(ctx context.Context, index string, query *Query) (int64, error) {
+	req := c.esClient.Count().Index(index)
+	if q := c.query2ESQuery(query); q != nil {
+		req = req.Query(q)
+	}
+	resp, err := req.Do(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return resp.Count, nil
+}
+
+func (c *es8Client) MGet(ctx context.Context, index string, ids []string) ([]Hit, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	resp, err := c.esClient.Mget().Index(index).Ids(ids...).Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Docs 中的元素为 GetResult 或 MultiGetError 的联合类型，统一转换为 JSON 后解析
+	respJson, err := sonic.MarshalString(resp)
+	if err != nil {
+		return nil, err
+	}
+	var result mgetResponse
+	if err := sonic.UnmarshalString(respJson, &result); err != nil {
+		return nil, err
+	}
+	return result.hits(), nil
+}
+
+func (c *es8Client) CreateAlias(ctx context.Context, index, alias string) error {
+	_, err := c.esClient.Indices.PutAlias(index, alias).Do(ctx)
+	return err
+}
+
+func (c *es8Client) GetAlias(ctx context.Context, alias string) ([]string, error) {
+	exist, err := c.esClient.Indices.ExistsAlias(alias).Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !exist {
+		return nil, nil
+	}
+
+	resp, err := c.esClient.Indices.GetAlias().Name(alias).Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return sortedKeys(resp), nil
+}
+
+func (c *es8Client) SwapAlias(ctx context.Context, alias, newIndex string) error {
+	old, err := c.GetAlias(ctx, alias)
+	if err != nil {
+		return err
+	}
+
+	var actions []types.IndicesAction
+	for _, index := range old {
+		if index == newIndex {
+			continue
+		}
+		actions = append(actions, types.IndicesAction{
+			Remove: &types.RemoveAction{Index: ptr.Of(index), Alias: ptr.Of(alias)},
+		})
+	}
+	actions = append(actions, types.IndicesAction{
+		Add: &types.AddAction{Index: ptr.Of(newIndex), Alias: ptr.Of(alias)},
+	})
+
+	_, err = c.esClient.Indices.UpdateAliases().Actions(actions...).Do(ctx)
+	return err
+}
+
+func (c *es8Client) Reindex(ctx context.Context, srcIndex, dstIndex string, opts ...es.ByQueryOptFn) (*es.ByQueryResponse, error) {
+	o := es.NewByQueryOption(opts...)
+
+	resp, err := c.esClient.Reindex().
+		Source(&types.ReindexSource{Index: []string{srcIndex}}).
+		Dest(&types.ReindexDestination{Index: dstIndex}).
+		Conflicts(conflictsOf(o)).
+		Refresh(o.Refresh).
+		WaitForCompletion(false).
+		Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return c.waitByQuery(ctx, fmt.Sprint(resp.Task), o)
+}
+
+func (c *es8Client) PutIndexSettings(ctx context.Context, index string, settings map[string]any) error {
+	body, err := json.Marshal(settings)
+	if err != nil {
+		return err
+	}
+	_, err = c.esClient.Indices.PutSettings().Indices(index).Raw(bytes.NewReader(body)).Do(ctx)
+	return err
+}
+
+func (c *es8Client) SetRefreshInterval(ctx context.Context, index string, interval time.Duration) error {
+	return c.PutIndexSettings(ctx, index, map[string]any{es.SettingRefreshInterval: es.RefreshIntervalValue(interval)})
+}
+
+func (c *es8Client) PutILMPolicy(ctx context.Context, name string, policy *es.ILMPolicy) error {
+	body, err := json.Marshal(policy.Body())
+	if err != nil {
+		return err
+	}
+	_, err = c.esClient.Ilm.PutLifecycle(name).Raw(bytes.NewReader(body)).Do(ctx)
+	return err
+}
@@ -1,8 +1,9 @@
 package es
 
 import (
+	"encoding/json"
 	"fmt"
-	"os"
+	"sort"
 
 	"github.com/ZampoRen/go-server-comon/internal/infra/es"
 )
@@ -16,19 +17,88 @@ type (
 	BoolQuery       = es.BoolQuery
 	Query           = es.Query
 	Response        = es.Response
+	Hit             = es.Hit
 	Request         = es.Request
 )
 
 // New 创建 Elasticsearch 客户端
 // 根据环境变量 ES_VERSION 决定创建 ES7 或 ES8 客户端
 // 支持的值: v7, v8
-func New() (Client, error) {
-	v := os.Getenv("ES_VERSION")
+// 地址与账号读取环境变量 ES_ADDR、ES_USERNAME、ES_PASSWORD，可通过 WithVersion、WithAddr、WithBasicAuth 覆盖
+// 默认记录错误与慢请求日志，可通过 WithLogger、WithTracing、WithPrometheus 调整
+func New(opts ...Option) (Client, error) {
+	o := newOption(opts...)
+	v := o.version
 	if v == "v8" {
-		return newES8()
+		return newES8(o)
 	} else if v == "v7" {
-		return newES7()
+		return newES7(o)
 	}
 
 	return nil, fmt.Errorf("unsupported es version %s", v)
 }
+
+// parseTaskResult 解析已完成的 by-query 任务结果，部分文档失败时同时返回结果与 es.ErrByQueryFailed
+func parseTaskResult(taskID string, taskErr, response []byte) (*es.ByQueryResponse, error) {
+	if len(taskErr) > 0 && string(taskErr) != "null" {
+		return nil, fmt.Errorf("task %s failed: %s", taskID, taskErr)
+	}
+
+	result := &es.ByQueryResponse{TaskID: taskID}
+	if len(response) > 0 {
+		if err := json.Unmarshal(response, result); err != nil {
+			return nil, fmt.Errorf("parse task %s result failed: %w", taskID, err)
+		}
+	}
+	if len(result.Failures) > 0 {
+		return result, fmt.Errorf("%w: task: %s, failures: %d", es.ErrByQueryFailed, taskID, len(result.Failures))
+	}
+	return result, nil
+}
+
+// mgetResponse MGet 的响应
+type mgetResponse struct {
+	Docs []struct {
+		Id_     string          `json:"_id"`
+		Found   bool            `json:"found"`
+		Source_ json.RawMessage `json:"_source,omitempty"`
+	} `json:"docs"`
+}
+
+// hits 返回存在的文档，顺序与请求的 ids 一致
+func (r *mgetResponse) hits() []Hit {
+	hits := make([]Hit, 0, len(r.Docs))
+	for _, doc := range r.Docs {
+		if !doc.Found {
+			continue
+		}
+		hits = append(hits, Hit{Id_: &doc.Id_, Source_: doc.Source_})
+	}
+	return hits
+}
+
+// aliasActions 返回将别名从 old 切换到 newIndex 的操作，在一次请求中执行保证原子性
+func aliasActions(alias string, old []string, newIndex string) []map[string]any {
+	actions := make([]map[string]any, 0, len(old)+1)
+	for _, index := range old {
+		if index == newIndex {
+			continue
+		}
+		actions = append(actions, map[string]any{
+			"remove": map[string]any{"index": index, "alias": alias},
+		})
+	}
+	return append(actions, map[string]any{
+		"add": map[string]any{"index": newIndex, "alias": alias},
+	})
+}
+
+// sortedKeys 返回排序后的键
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,126 @@
+package es
+
+import (
+	"net/http"
+	"os"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel/trace"
+
+	logger "github.com/ZampoRen/go-server-comon/pkg/logs"
+)
+
+// Option 客户端构造选项
+type Option func(o *option)
+
+type option struct {
+	name   string
+	logger *logger.ESLogger
+
+	version  string
+	addr     string
+	username string
+	password string
+
+	transport http.RoundTripper
+	caCert    []byte
+
+	tracing        bool
+	tracerProvider trace.TracerProvider
+
+	registerer prometheus.Registerer
+}
+
+func newOption(opts ...Option) *option {
+	o := &option{name: "default", logger: logger.DefaultESLogger()}
+	for _, opt := range opts {
+		opt(o)
+	}
+	if o.version == "" {
+		o.version = os.Getenv("ES_VERSION")
+	}
+	if o.addr == "" {
+		o.addr = os.Getenv("ES_ADDR")
+	}
+	if o.username == "" {
+		o.username = os.Getenv("ES_USERNAME")
+		o.password = os.Getenv("ES_PASSWORD")
+	}
+	return o
+}
+
+// WithName 设置实例名称，作为指标的 instance 标签与 span 属性，默认 default
+func WithName(name string) Option {
+	return func(o *option) {
+		if name != "" {
+			o.name = name
+		}
+	}
+}
+
+// WithVersion 设置版本，可选值: v7, v8，为空时读取环境变量 ES_VERSION
+func WithVersion(version string) Option {
+	return func(o *option) {
+		o.version = version
+	}
+}
+
+// WithAddr 设置集群地址，多个地址以逗号分隔，为空时读取环境变量 ES_ADDR
+func WithAddr(addr string) Option {
+	return func(o *option) {
+		o.addr = addr
+	}
+}
+
+// WithBasicAuth 设置用户名与密码，username 为空时读取环境变量 ES_USERNAME、ES_PASSWORD
+func WithBasicAuth(username, password string) Option {
+	return func(o *option) {
+		o.username = username
+		o.password = password
+	}
+}
+
+// WithTransport 设置底层 transport，如自定义 TLS、代理或连接池配置，默认 http.DefaultTransport
+// 日志、链路与指标在其外层记录，不会替换该 transport
+func WithTransport(rt http.RoundTripper) Option {
+	return func(o *option) {
+		o.transport = rt
+	}
+}
+
+// WithCACert 设置校验集群证书的 PEM 格式 CA 证书，写入底层 transport 的 TLS 配置
+// 与 WithTransport 同时使用时，transport 必须是 *http.Transport
+func WithCACert(pem []byte) Option {
+	return func(o *option) {
+		o.caCert = pem
+	}
+}
+
+// WithLogger 设置请求日志记录器，默认 logger.DefaultESLogger，记录错误与慢请求
+// l 为 nil 时不记录请求日志
+func WithLogger(l *logger.ESLogger) Option {
+	return func(o *option) {
+		o.logger = l
+	}
+}
+
+// WithTracing 为每个请求创建 OpenTelemetry span
+// tp 为 nil 时使用全局 TracerProvider
+func WithTracing(tp trace.TracerProvider) Option {
+	return func(o *option) {
+		o.tracing = true
+		o.tracerProvider = tp
+	}
+}
+
+// WithPrometheus 按索引与操作导出请求耗时与失败次数指标
+// 索引标签去掉了日期、序号等包含数字的后缀，如 logs-2024.01.02 记为 logs-*，避免按日期滚动的索引使标签基数无限增长
+// reg 为 nil 时注册到 prometheus.DefaultRegisterer
+func WithPrometheus(reg prometheus.Registerer) Option {
+	return func(o *option) {
+		if reg == nil {
+			reg = prometheus.DefaultRegisterer
+		}
+		o.registerer = reg
+	}
+}
@@ -0,0 +1,261 @@
+package es
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/cloudwego/hertz/pkg/common/hlog"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	logger "github.com/ZampoRen/go-server-comon/pkg/logs"
+)
+
+const tracerName = "github.com/ZampoRen/go-server-comon/internal/infra/es/impl/es"
+
+// DefaultDurationBuckets 请求耗时直方图的默认分桶（秒）
+var DefaultDurationBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// instrumentedTransport 记录请求日志、span 与耗时指标的 http.RoundTripper
+// 只记录请求路径，请求体仅在搜索类请求需要打印日志时读取，不写入 span，避免把文档内容写入链路数据
+type instrumentedTransport struct {
+	next   http.RoundTripper
+	name   string
+	logger *logger.ESLogger
+	tracer trace.Tracer
+
+	duration *prometheus.HistogramVec
+	errors   *prometheus.CounterVec
+}
+
+// newTransport 按选项创建包装底层 transport 的 instrumentedTransport，
+// 没有启用任何功能时返回底层 transport，两者都没有配置时返回 nil，使用客户端默认的 transport
+func newTransport(o *option) (http.RoundTripper, error) {
+	next, err := baseTransport(o)
+	if err != nil {
+		return nil, err
+	}
+	if o.logger == nil && !o.tracing && o.registerer == nil {
+		return next, nil
+	}
+	if next == nil {
+		next = http.DefaultTransport
+	}
+
+	t := &instrumentedTransport{
+		next:   next,
+		name:   o.name,
+		logger: o.logger,
+	}
+	if o.tracing {
+		tp := o.tracerProvider
+		if tp == nil {
+			tp = otel.GetTracerProvider()
+		}
+		t.tracer = tp.Tracer(tracerName)
+	}
+	if o.registerer != nil {
+		t.duration = register(o.registerer, prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: "es",
+			Name:      "request_duration_seconds",
+			Help:      "Duration of Elasticsearch requests in seconds.",
+			Buckets:   DefaultDurationBuckets,
+		}, []string{"instance", "index", "operation"}))
+		t.errors = register(o.registerer, prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "es",
+			Name:      "request_errors_total",
+			Help:      "Number of failed Elasticsearch requests, including 5xx responses.",
+		}, []string{"instance", "index", "operation"}))
+	}
+	return t, nil
+}
+
+// baseTransport 返回 WithTransport 设置的 transport，配置了 CA 证书时复制一份并写入 TLS 配置
+// CA 证书不交给客户端处理，因为客户端只能为 *http.Transport 设置证书，包装后的 transport 会导致创建失败
+func baseTransport(o *option) (http.RoundTripper, error) {
+	if len(o.caCert) == 0 {
+		return o.transport, nil
+	}
+
+	base, ok := o.transport.(*http.Transport)
+	if o.transport == nil {
+		base, ok = http.DefaultTransport.(*http.Transport)
+	}
+	if !ok {
+		return nil, fmt.Errorf("unable to set CA certificate for transport of type %T", o.transport)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(o.caCert) {
+		return nil, errors.New("unable to add CA certificate")
+	}
+	tr := base.Clone()
+	if tr.TLSClientConfig == nil {
+		tr.TLSClientConfig = &tls.Config{}
+	}
+	tr.TLSClientConfig.RootCAs = pool
+	return tr, nil
+}
+
+// RoundTrip 实现 http.RoundTripper
+func (t *instrumentedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	index, operation := parseRequestPath(req.Method, req.URL.Path)
+
+	var body []byte
+	if t.logger != nil && t.logger.NeedBody() && isSearchOperation(operation) {
+		body = peekBody(req)
+	}
+
+	var span trace.Span
+	if t.tracer != nil {
+		ctx, span = t.tracer.Start(ctx, "es."+operation,
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(
+				attribute.String("db.system", "elasticsearch"),
+				attribute.String("db.instance", t.name),
+				attribute.String("db.operation", operation),
+				attribute.String("db.elasticsearch.index", index),
+				attribute.String("http.request.method", req.Method),
+				attribute.String("url.path", req.URL.Path),
+				attribute.String("server.address", req.URL.Host),
+			),
+		)
+		defer span.End()
+		req = req.WithContext(ctx)
+	}
+
+	start := time.Now()
+	resp, err := t.next.RoundTrip(req)
+	elapsed := time.Since(start)
+
+	status := 0
+	if resp != nil {
+		status = resp.StatusCode
+	}
+	failed := err != nil || status >= http.StatusInternalServerError
+
+	if span != nil {
+		span.SetAttributes(attribute.Int("http.response.status_code", status))
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		} else if failed {
+			span.SetStatus(codes.Error, http.StatusText(status))
+		}
+	}
+
+	if t.duration != nil {
+		label := indexLabel(index)
+		t.duration.WithLabelValues(t.name, label, operation).Observe(elapsed.Seconds())
+		if failed {
+			t.errors.WithLabelValues(t.name, label, operation).Inc()
+		}
+	}
+
+	if t.logger != nil {
+		t.logger.LogRequest(ctx, req.Method, req.URL.Path, body, status, elapsed, err)
+	}
+
+	return resp, err
+}
+
+// parseRequestPath 从请求路径解析索引与操作名
+// /{index}/_search => index, search；/_bulk => "", bulk；/{index}/_doc/{id} => index, doc；
+// 只有索引名的请求按方法区分，如 PUT /{index} => index, create_index
+func parseRequestPath(method, path string) (index, operation string) {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	if segments[0] == "" {
+		return "", "info"
+	}
+
+	if !strings.HasPrefix(segments[0], "_") {
+		index = segments[0]
+		segments = segments[1:]
+	}
+	if len(segments) > 0 && strings.HasPrefix(segments[0], "_") {
+		operation = strings.TrimPrefix(segments[0], "_")
+		// 如 /_search/scroll、/_pit
+		if len(segments) > 1 && !strings.HasPrefix(segments[1], "_") && operation == "search" {
+			operation += "_" + segments[1]
+		}
+		return index, operation
+	}
+
+	switch method {
+	case http.MethodPut:
+		return index, "create_index"
+	case http.MethodDelete:
+		return index, "delete_index"
+	case http.MethodHead:
+		return index, "exists"
+	default:
+		return index, "get_index"
+	}
+}
+
+// indexLabel 将索引名归一化为指标标签，去掉从第一个包含数字的段开始的后缀
+// logs-2024.01.02 => logs-*；users-000001 => users-*；多个索引逐个归一化并去重
+func indexLabel(index string) string {
+	if index == "" {
+		return "none"
+	}
+	parts := strings.Split(index, ",")
+	for i, p := range parts {
+		if j := strings.IndexFunc(p, unicode.IsDigit); j >= 0 {
+			parts[i] = p[:strings.LastIndexAny(p[:j], "-_.")+1] + "*"
+		}
+	}
+	slices.Sort(parts)
+	return strings.Join(slices.Compact(parts), ",")
+}
+
+// isSearchOperation 是否为搜索类请求，只有这类请求的请求体会出现在日志中
+func isSearchOperation(operation string) bool {
+	switch operation {
+	case "search", "count", "update_by_query", "delete_by_query":
+		return true
+	}
+	return false
+}
+
+// peekBody 通过 GetBody 读取请求体副本，不影响实际发送的请求体
+func peekBody(req *http.Request) []byte {
+	if req.GetBody == nil {
+		return nil
+	}
+	rc, err := req.GetBody()
+	if err != nil {
+		return nil
+	}
+	defer rc.Close()
+	body, _ := io.ReadAll(rc)
+	return body
+}
+
+// register 注册指标，已注册时复用已有的指标，使多个客户端实例共享同一组指标
+func register[C prometheus.Collector](reg prometheus.Registerer, c C) C {
+	err := reg.Register(c)
+	if err == nil {
+		return c
+	}
+	var are prometheus.AlreadyRegisteredError
+	if errors.As(err, &are) {
+		if existing, ok := are.ExistingCollector.(C); ok {
+			return existing
+		}
+	}
+	hlog.CtxWarnf(context.Background(), "[ES] register metrics failed: %v", err)
+	return c
+}
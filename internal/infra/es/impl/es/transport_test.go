@@ -0,0 +1,126 @@
+package es
+
+import (
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func TestParseRequestPath(t *testing.T) {
+	tests := []struct {
+		method, path     string
+		index, operation string
+	}{
+		{http.MethodGet, "/", "", "info"},
+		{http.MethodPost, "/docs/_search", "docs", "search"},
+		{http.MethodPost, "/_search/scroll", "", "search_scroll"},
+		{http.MethodPost, "/_bulk", "", "bulk"},
+		{http.MethodPut, "/docs/_doc/1", "docs", "doc"},
+		{http.MethodPost, "/docs/_update/1", "docs", "update"},
+		{http.MethodPost, "/a,b/_count", "a,b", "count"},
+		{http.MethodPut, "/docs", "docs", "create_index"},
+		{http.MethodDelete, "/docs/", "docs", "delete_index"},
+		{http.MethodHead, "/docs", "docs", "exists"},
+		{http.MethodGet, "/docs", "docs", "get_index"},
+	}
+	for _, tt := range tests {
+		index, operation := parseRequestPath(tt.method, tt.path)
+		if index != tt.index || operation != tt.operation {
+			t.Errorf("parseRequestPath(%s %s) = %q, %q, want %q, %q", tt.method, tt.path, index, operation, tt.index, tt.operation)
+		}
+	}
+}
+
+func TestIndexLabel(t *testing.T) {
+	tests := map[string]string{
+		"":                                "none",
+		"docs":                            "docs",
+		"logs-2024.01.02":                 "logs-*",
+		"users-000001":                    "users-*",
+		"2024-logs":                       "*",
+		"logs-2024.01.01,logs-2024.01.02": "logs-*",
+		"b,a":                             "a,b",
+	}
+	for index, want := range tests {
+		if got := indexLabel(index); got != want {
+			t.Errorf("indexLabel(%q) = %q, want %q", index, got, want)
+		}
+	}
+}
+
+// countTransport 记录经过的请求数
+type countTransport struct {
+	next  http.RoundTripper
+	count int
+}
+
+func (c *countTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	c.count++
+	return c.next.RoundTrip(req)
+}
+
+func TestTransport(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+	caCert := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw})
+
+	t.Run("包装自定义 transport", func(t *testing.T) {
+		base := &countTransport{next: srv.Client().Transport}
+		reg := prometheus.NewRegistry()
+		rt, err := newTransport(newOption(WithTransport(base), WithPrometheus(reg)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		req, _ := http.NewRequest(http.MethodPost, srv.URL+"/logs-2024.01.02/_search", nil)
+		resp, err := rt.RoundTrip(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_ = resp.Body.Close()
+		if base.count != 1 {
+			t.Fatalf("base transport called %d times, want 1", base.count)
+		}
+		mfs, err := reg.Gather()
+		if err != nil || len(mfs) != 1 {
+			t.Fatalf("Gather() = %v, %v", mfs, err)
+		}
+		for _, l := range mfs[0].GetMetric()[0].GetLabel() {
+			if l.GetName() == "index" && l.GetValue() != "logs-*" {
+				t.Fatalf("index label = %q, want logs-*", l.GetValue())
+			}
+		}
+	})
+
+	t.Run("CA 证书写入底层 transport", func(t *testing.T) {
+		rt, err := newTransport(newOption(WithCACert(caCert)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		req, _ := http.NewRequest(http.MethodGet, srv.URL+"/", nil)
+		resp, err := rt.RoundTrip(req)
+		if err != nil {
+			t.Fatalf("RoundTrip() error = %v", err)
+		}
+		_ = resp.Body.Close()
+	})
+
+	t.Run("未配置 CA 证书时校验失败", func(t *testing.T) {
+		rt, err := newTransport(newOption())
+		if err != nil {
+			t.Fatal(err)
+		}
+		req, _ := http.NewRequest(http.MethodGet, srv.URL+"/", nil)
+		if _, err := rt.RoundTrip(req); err == nil {
+			t.Fatal("RoundTrip() should fail with unknown certificate authority")
+		}
+	})
+
+	t.Run("无法为非 *http.Transport 设置证书", func(t *testing.T) {
+		if _, err := newTransport(newOption(WithTransport(&countTransport{}), WithCACert(caCert))); err == nil {
+			t.Fatal("newTransport() should fail")
+		}
+	})
+}
@@ -0,0 +1,38 @@
+package es
+
+import "errors"
+
+const (
+	// SimilarityCosine 余弦相似度
+	SimilarityCosine = "cosine"
+	// SimilarityDotProduct 点积，要求向量已归一化
+	SimilarityDotProduct = "dot_product"
+	// SimilarityL2Norm 欧氏距离
+	SimilarityL2Norm = "l2_norm"
+)
+
+var (
+	// ErrKnnNotSupported 当前版本不支持 kNN 搜索
+	ErrKnnNotSupported = errors.New("knn search is not supported")
+)
+
+// KnnQuery 近似 kNN 向量搜索，字段需要使用 Types.NewDenseVectorProperty 创建的 dense_vector 属性
+// 与 Request.Query 同时使用时，两者的命中按分数合并
+type KnnQuery struct {
+	Field         string    // 向量字段名
+	QueryVector   []float32 // 查询向量，维度需与字段一致
+	K             int       // 返回的最近邻数量
+	NumCandidates int       // 每个分片的候选数量，越大召回越高但越慢，需不小于 K
+	Filter        *Query    // 过滤条件，在近邻搜索过程中生效，保证返回 K 个满足条件的结果
+	Similarity    *float32  // 最小相似度，低于该值的文档不返回
+}
+
+// NewKnnQuery 创建 kNN 向量搜索，仅 ES8 支持
+func NewKnnQuery(field string, vector []float32, k, numCandidates int) *KnnQuery {
+	return &KnnQuery{
+		Field:         field,
+		QueryVector:   vector,
+		K:             k,
+		NumCandidates: numCandidates,
+	}
+}
@@ -0,0 +1,120 @@
+package es
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+const (
+	// SettingRefreshInterval 索引刷新间隔
+	SettingRefreshInterval = "index.refresh_interval"
+	// SettingNumberOfReplicas 副本数
+	SettingNumberOfReplicas = "index.number_of_replicas"
+	// SettingLifecycleName 索引使用的 ILM 策略名
+	SettingLifecycleName = "index.lifecycle.name"
+	// SettingLifecycleRolloverAlias rollover 使用的写别名
+	SettingLifecycleRolloverAlias = "index.lifecycle.rollover_alias"
+)
+
+const (
+	// ILMPhaseHot 热阶段，正在写入和频繁查询
+	ILMPhaseHot = "hot"
+	// ILMPhaseWarm 温阶段，不再写入但仍需查询
+	ILMPhaseWarm = "warm"
+	// ILMPhaseCold 冷阶段，很少查询
+	ILMPhaseCold = "cold"
+	// ILMPhaseDelete 删除阶段
+	ILMPhaseDelete = "delete"
+)
+
+// ILMPolicy 索引生命周期策略
+type ILMPolicy struct {
+	Phases map[string]ILMPhase // 阶段，键为 ILMPhaseXxx
+	Meta   map[string]any      // 元数据，如策略的维护者与用途
+}
+
+// ILMPhase 生命周期阶段
+type ILMPhase struct {
+	MinAge  time.Duration  // 进入该阶段的最小索引年龄，rollover 后从 rollover 时刻开始计算
+	Actions map[string]any // 阶段动作，如 {"rollover": {"max_age": "1d"}}、{"delete": {}}
+}
+
+// NewRetentionILMPolicy 创建保留期策略，索引年龄达到 retention 后删除
+// rollover 大于 0 时在热阶段按该间隔或 maxSize（如 50gb，为空时不限制）滚动，需要通过写别名写入
+func NewRetentionILMPolicy(retention, rollover time.Duration, maxSize string) *ILMPolicy {
+	policy := &ILMPolicy{Phases: map[string]ILMPhase{
+		ILMPhaseDelete: {
+			MinAge:  retention,
+			Actions: map[string]any{"delete": map[string]any{}},
+		},
+	}}
+	if rollover > 0 {
+		conditions := map[string]any{"max_age": TimeValue(rollover)}
+		if maxSize != "" {
+			conditions["max_primary_shard_size"] = maxSize
+		}
+		policy.Phases[ILMPhaseHot] = ILMPhase{
+			Actions: map[string]any{"rollover": conditions},
+		}
+	}
+	return policy
+}
+
+// Body 返回 PUT _ilm/policy 的请求体
+func (p *ILMPolicy) Body() map[string]any {
+	phases := make(map[string]any, len(p.Phases))
+	for name, phase := range p.Phases {
+		actions := phase.Actions
+		if actions == nil {
+			actions = map[string]any{}
+		}
+		phases[name] = map[string]any{
+			"min_age": TimeValue(phase.MinAge),
+			"actions": actions,
+		}
+	}
+
+	policy := map[string]any{"phases": phases}
+	if len(p.Meta) > 0 {
+		policy["_meta"] = p.Meta
+	}
+	return map[string]any{"policy": policy}
+}
+
+// TimeValue 将 d 转换为 ES 的时间单位字符串，如 30d、12h、500ms
+func TimeValue(d time.Duration) string {
+	switch {
+	case d == 0:
+		return "0ms"
+	case d%(24*time.Hour) == 0:
+		return fmt.Sprintf("%dd", d/(24*time.Hour))
+	case d%time.Hour == 0:
+		return fmt.Sprintf("%dh", d/time.Hour)
+	case d%time.Minute == 0:
+		return fmt.Sprintf("%dm", d/time.Minute)
+	case d%time.Second == 0:
+		return fmt.Sprintf("%ds", d/time.Second)
+	default:
+		return fmt.Sprintf("%dms", d.Milliseconds())
+	}
+}
+
+// RefreshIntervalValue 返回刷新间隔的设置值
+// d 小于 0 时返回 -1，关闭自动刷新，适用于大批量导入；d 为 0 时返回 nil，恢复默认值
+func RefreshIntervalValue(d time.Duration) any {
+	switch {
+	case d < 0:
+		return "-1"
+	case d == 0:
+		return nil
+	default:
+		return TimeValue(d)
+	}
+}
+
+// ApplyILMPolicy 为已存在的索引设置 ILM 策略
+// 对按日期创建的日志、指标索引，建议在索引模板中设置 SettingLifecycleName，避免每次创建后单独设置
+func ApplyILMPolicy(ctx context.Context, c Client, index, policy string) error {
+	return c.PutIndexSettings(ctx, index, map[string]any{SettingLifecycleName: policy})
+}
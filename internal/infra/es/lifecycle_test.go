@@ -0,0 +1,43 @@
+package es
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTimeValue(t *testing.T) {
+	tests := []struct {
+		name string
+		d    time.Duration
+		want string
+	}{
+		{name: "零", d: 0, want: "0ms"},
+		{name: "天", d: 30 * 24 * time.Hour, want: "30d"},
+		{name: "小时", d: 36 * time.Hour, want: "36h"},
+		{name: "分钟", d: 90 * time.Minute, want: "90m"},
+		{name: "秒", d: 30 * time.Second, want: "30s"},
+		{name: "毫秒", d: 1500 * time.Millisecond, want: "1500ms"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := TimeValue(tt.d); got != tt.want {
+				t.Errorf("TimeValue(%v) = %s, want %s", tt.d, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewRetentionILMPolicy(t *testing.T) {
+	body := NewRetentionILMPolicy(7*24*time.Hour, 24*time.Hour, "50gb").Body()
+	phases := body["policy"].(map[string]any)["phases"].(map[string]any)
+
+	del := phases[ILMPhaseDelete].(map[string]any)
+	if del["min_age"] != "7d" {
+		t.Errorf("delete min_age = %v, want 7d", del["min_age"])
+	}
+	hot := phases[ILMPhaseHot].(map[string]any)
+	rollover := hot["actions"].(map[string]any)["rollover"].(map[string]any)
+	if rollover["max_age"] != "1d" || rollover["max_primary_shard_size"] != "50gb" {
+		t.Errorf("rollover = %v", rollover)
+	}
+}
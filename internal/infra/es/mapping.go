@@ -0,0 +1,164 @@
+package es
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/cloudwego/hertz/pkg/common/hlog"
+)
+
+// PropertiesFromStruct 根据结构体字段的 es 标签生成索引 mapping 的 properties
+// 字段名取 json 标签，没有 json 标签时使用字段名；没有 es 标签或标签为 - 的字段会被忽略
+// 标签格式为 `es:"type,key=value,..."`，如 `es:"text,analyzer=ik_max_word"`、`es:"date,format=epoch_millis"`，
+// true、false 与整数参数会转换为对应类型；类型为 object 或 nested 时递归解析结构体（或结构体切片）字段
+func PropertiesFromStruct(v any) (map[string]any, error) {
+	t := reflect.TypeOf(v)
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("properties from struct: want struct, got %T", v)
+	}
+	return structProperties(t)
+}
+
+func structProperties(t reflect.Type) (map[string]any, error) {
+	properties := make(map[string]any)
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		tag, ok := f.Tag.Lookup("es")
+		if !ok || tag == "-" {
+			continue
+		}
+
+		name := f.Name
+		if jsonName, _, _ := strings.Cut(f.Tag.Get("json"), ","); jsonName != "" && jsonName != "-" {
+			name = jsonName
+		}
+
+		parts := strings.Split(tag, ",")
+		prop := map[string]any{"type": parts[0]}
+		for _, p := range parts[1:] {
+			k, val, found := strings.Cut(p, "=")
+			if !found {
+				return nil, fmt.Errorf("field %s: invalid es tag option %q", f.Name, p)
+			}
+			prop[k] = tagValue(val)
+		}
+
+		if parts[0] == "object" || parts[0] == "nested" {
+			ft := f.Type
+			for ft.Kind() == reflect.Pointer || ft.Kind() == reflect.Slice || ft.Kind() == reflect.Array {
+				ft = ft.Elem()
+			}
+			if ft.Kind() != reflect.Struct {
+				return nil, fmt.Errorf("field %s: %s type requires struct, got %s", f.Name, parts[0], f.Type)
+			}
+			sub, err := structProperties(ft)
+			if err != nil {
+				return nil, err
+			}
+			prop["properties"] = sub
+		}
+
+		properties[name] = prop
+	}
+	return properties, nil
+}
+
+// tagValue 将标签参数转换为布尔值或整数，其余保持字符串
+func tagValue(s string) any {
+	if b, err := strconv.ParseBool(s); err == nil && (s == "true" || s == "false") {
+		return b
+	}
+	if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return n
+	}
+	return s
+}
+
+// CreateIndexForStruct 根据结构体的 es 标签创建索引，见 PropertiesFromStruct
+func CreateIndexForStruct(ctx context.Context, c Client, index string, v any) error {
+	properties, err := PropertiesFromStruct(v)
+	if err != nil {
+		return err
+	}
+	return c.CreateIndex(ctx, index, properties)
+}
+
+// ReindexOptFn ReindexWithAlias 选项函数
+type ReindexOptFn func(o *ReindexOption)
+
+// ReindexOption ReindexWithAlias 选项
+type ReindexOption struct {
+	// DeleteOldIndex 切换别名后删除旧索引，默认保留以便回滚
+	DeleteOldIndex bool
+	// ByQuery 复制文档时的选项，如 WithProceedOnConflicts
+	ByQuery []ByQueryOptFn
+}
+
+// WithDeleteOldIndex 切换别名后删除旧索引
+func WithDeleteOldIndex() ReindexOptFn {
+	return func(o *ReindexOption) {
+		o.DeleteOldIndex = true
+	}
+}
+
+// WithReindexByQuery 设置复制文档时的选项
+func WithReindexByQuery(opts ...ByQueryOptFn) ReindexOptFn {
+	return func(o *ReindexOption) {
+		o.ByQuery = append(o.ByQuery, opts...)
+	}
+}
+
+// ReindexWithAlias 零停机重建别名对应的索引，适用于修改 mapping 等需要重建索引的场景
+//  1. 使用 properties 创建新索引 newIndex
+//  2. 将别名当前指向的索引的文档复制到 newIndex，别名不存在时跳过，可用于首次创建
+//  3. 原子地将别名切换到 newIndex
+//  4. 可选地删除旧索引
+//
+// 读请求通过别名访问时全程不中断；复制期间写入旧索引的文档不会出现在新索引中，
+// 调用方需要在重建期间暂停写入或同时写入新索引
+func ReindexWithAlias(ctx context.Context, c Client, alias, newIndex string, properties map[string]any, opts ...ReindexOptFn) error {
+	o := &ReindexOption{}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	oldIndices, err := c.GetAlias(ctx, alias)
+	if err != nil {
+		return fmt.Errorf("get alias %s failed: %w", alias, err)
+	}
+
+	if err := c.CreateIndex(ctx, newIndex, properties); err != nil {
+		return fmt.Errorf("create index %s failed: %w", newIndex, err)
+	}
+
+	for _, old := range oldIndices {
+		res, err := c.Reindex(ctx, old, newIndex, append(o.ByQuery, WithRefresh())...)
+		if err != nil {
+			return fmt.Errorf("reindex %s to %s failed: %w", old, newIndex, err)
+		}
+		hlog.CtxInfof(ctx, "[ReindexWithAlias] reindex %s to %s, total: %d, created: %d, updated: %d",
+			old, newIndex, res.Total, res.Created, res.Updated)
+	}
+
+	if err := c.SwapAlias(ctx, alias, newIndex); err != nil {
+		return fmt.Errorf("swap alias %s to %s failed: %w", alias, newIndex, err)
+	}
+
+	if o.DeleteOldIndex {
+		for _, old := range oldIndices {
+			if err := c.DeleteIndex(ctx, old); err != nil {
+				return fmt.Errorf("delete old index %s failed: %w", old, err)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,42 @@
+package es
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPropertiesFromStruct(t *testing.T) {
+	type tag struct {
+		Name string `json:"name" es:"keyword"`
+	}
+	type doc struct {
+		ID        int64  `json:"id" es:"long"`
+		Title     string `json:"title,omitempty" es:"text,analyzer=ik_max_word"`
+		Hidden    string `json:"hidden" es:"keyword,index=false"`
+		CreatedAt int64  `es:"date,format=epoch_millis"`
+		Tags      []tag  `json:"tags" es:"nested"`
+		Ignored   string `json:"ignored"`
+		Skipped   string `json:"skipped" es:"-"`
+	}
+
+	got, err := PropertiesFromStruct(&doc{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]any{
+		"id":        map[string]any{"type": "long"},
+		"title":     map[string]any{"type": "text", "analyzer": "ik_max_word"},
+		"hidden":    map[string]any{"type": "keyword", "index": false},
+		"CreatedAt": map[string]any{"type": "date", "format": "epoch_millis"},
+		"tags": map[string]any{"type": "nested", "properties": map[string]any{
+			"name": map[string]any{"type": "keyword"},
+		}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("PropertiesFromStruct() = %v, want %v", got, want)
+	}
+
+	if _, err := PropertiesFromStruct("not a struct"); err == nil {
+		t.Error("PropertiesFromStruct(string) expected error")
+	}
+}
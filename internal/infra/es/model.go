@@ -25,6 +25,10 @@ type Request struct {
 	Sort        []SortFiled // 排序字段
 	SearchAfter []any       // 搜索后游标
 	From        *int        // 起始位置
+	// Aggs 聚合，键为聚合名称，结果通过 Response.Aggregations 按名称读取
+	Aggs map[string]Aggregation
+	// Knn kNN 向量搜索，仅 ES8 支持
+	Knn *KnnQuery
 }
 
 // SortFiled 排序字段
@@ -37,6 +41,8 @@ type SortFiled struct {
 type Response struct {
 	Hits     HitsMetadata `json:"hits"`                // 命中结果
 	MaxScore *float64     `json:"max_score,omitempty"` // 最大分数
+	// Aggregations 聚合结果，使用 Buckets、Metric 解析
+	Aggregations AggregationResults `json:"aggregations,omitempty"`
 }
 
 // HitsMetadata 命中结果元数据
@@ -52,6 +58,7 @@ type Hit struct {
 	Id_     *string         `json:"_id,omitempty"`     // 文档 ID
 	Score_  *float64        `json:"_score,omitempty"`  // 分数
 	Source_ json.RawMessage `json:"_source,omitempty"` // 源文档
+	Sort    []any           `json:"sort,omitempty"`    // 排序值，可作为下一页的 SearchAfter
 }
 
 // TotalHits 总命中数
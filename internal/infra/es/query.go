@@ -1,5 +1,11 @@
 package es
 
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
 const (
 	// QueryTypeEqual 等值查询
 	QueryTypeEqual = "equal"
@@ -13,6 +19,21 @@ const (
 	QueryTypeContains = "contains"
 	// QueryTypeIn 包含在查询
 	QueryTypeIn = "in"
+	// QueryTypeRange 范围查询
+	QueryTypeRange = "range"
+	// QueryTypeExists 存在查询
+	QueryTypeExists = "exists"
+	// QueryTypePrefix 前缀查询
+	QueryTypePrefix = "prefix"
+	// QueryTypeWildcard 通配符查询
+	QueryTypeWildcard = "wildcard"
+	// QueryTypeRaw 原始 DSL 查询
+	QueryTypeRaw = "raw"
+)
+
+var (
+	// ErrInvalidRawQuery 原始 DSL 查询不是单个查询子句
+	ErrInvalidRawQuery = errors.New("invalid raw query")
 )
 
 // KV 键值对
@@ -29,6 +50,8 @@ type Query struct {
 	KV              KV              // 键值对
 	Type            QueryType       // 查询类型
 	MultiMatchQuery MultiMatchQuery // 多字段匹配查询
+	RangeQuery      RangeQuery      // 范围查询
+	RawQuery        json.RawMessage // 原始 DSL 查询，通过 NewRawQuery 创建
 	Bool            *BoolQuery      // 布尔查询
 }
 
@@ -49,6 +72,12 @@ type MultiMatchQuery struct {
 	Operator string   // 操作符
 }
 
+// RangeQuery 范围查询，边界为 nil 时表示不限制
+type RangeQuery struct {
+	GTE any // 大于等于
+	LTE any // 小于等于
+}
+
 const (
 	// Or 或操作
 	Or = "or"
@@ -112,3 +141,56 @@ func NewInQuery[T any](k string, v []T) Query {
 		Type: QueryTypeIn,
 	}
 }
+
+// NewRangeQuery 创建范围查询，匹配 gte <= k <= lte，gte 或 lte 为 nil 时对应一侧不限制
+func NewRangeQuery(k string, gte, lte any) Query {
+	return Query{
+		KV:         KV{Key: k},
+		Type:       QueryTypeRange,
+		RangeQuery: RangeQuery{GTE: gte, LTE: lte},
+	}
+}
+
+// NewExistsQuery 创建存在查询
+func NewExistsQuery(k string) Query {
+	return Query{
+		KV:   KV{Key: k},
+		Type: QueryTypeExists,
+	}
+}
+
+// NewPrefixQuery 创建前缀查询，适用于 keyword 字段
+func NewPrefixQuery(k string, prefix string) Query {
+	return Query{
+		KV:   KV{Key: k, Value: prefix},
+		Type: QueryTypePrefix,
+	}
+}
+
+// NewWildcardQuery 创建通配符查询，pattern 支持 * 与 ?，以通配符开头的模式性能较差
+func NewWildcardQuery(k string, pattern string) Query {
+	return Query{
+		KV:   KV{Key: k, Value: pattern},
+		Type: QueryTypeWildcard,
+	}
+}
+
+// NewRawQuery 创建原始 DSL 查询，用于尚未建模的查询类型，原样传给服务端
+// raw 必须是只有一个键的 JSON 对象（即单个查询子句），如 {"geo_distance":{...}}，
+// 不能是包含 "query" 的完整请求体；可与其他查询一起放入 BoolQuery
+func NewRawQuery(raw []byte) (Query, error) {
+	var clause map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &clause); err != nil {
+		return Query{}, fmt.Errorf("%w: %w", ErrInvalidRawQuery, err)
+	}
+	if len(clause) != 1 {
+		return Query{}, fmt.Errorf("%w: want exactly one query clause, got %d", ErrInvalidRawQuery, len(clause))
+	}
+	if _, ok := clause["query"]; ok {
+		return Query{}, fmt.Errorf("%w: pass the query clause itself, not the request body", ErrInvalidRawQuery)
+	}
+	return Query{
+		Type:     QueryTypeRaw,
+		RawQuery: json.RawMessage(raw),
+	}, nil
+}
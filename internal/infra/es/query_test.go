@@ -0,0 +1,34 @@
+package es
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestNewRawQuery(t *testing.T) {
+	tests := []struct {
+		name    string
+		raw     string
+		wantErr bool
+	}{
+		{name: "单个查询子句", raw: `{"geo_distance":{"distance":"10km","location":{"lat":1,"lon":2}}}`},
+		{name: "非法 JSON", raw: `{"term":`, wantErr: true},
+		{name: "多个子句", raw: `{"term":{"a":1},"match":{"b":"c"}}`, wantErr: true},
+		{name: "完整请求体", raw: `{"query":{"match_all":{}}}`, wantErr: true},
+		{name: "非对象", raw: `[1,2]`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q, err := NewRawQuery([]byte(tt.raw))
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidRawQuery) {
+					t.Errorf("NewRawQuery() error = %v, want ErrInvalidRawQuery", err)
+				}
+				return
+			}
+			if err != nil || q.Type != QueryTypeRaw || string(q.RawQuery) != tt.raw {
+				t.Errorf("NewRawQuery() = %+v, %v", q, err)
+			}
+		})
+	}
+}
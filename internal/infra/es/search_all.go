@@ -0,0 +1,78 @@
+package es
+
+import (
+	"strconv"
+	"time"
+)
+
+const (
+	// DefaultSearchAllBatchSize SearchAll 每批返回的默认文档数
+	DefaultSearchAllBatchSize = 1000
+	// DefaultSearchAllKeepAlive SearchAll 保持搜索上下文（PIT 或 scroll）的默认时长
+	DefaultSearchAllKeepAlive = time.Minute
+)
+
+// SearchAllOptFn SearchAll 选项函数
+type SearchAllOptFn func(o *SearchAllOption)
+
+// SearchAllOption SearchAll 选项
+type SearchAllOption struct {
+	// BatchSize 每批返回的文档数，默认 DefaultSearchAllBatchSize
+	BatchSize int
+	// MaxDocs 最多返回的文档数，达到后停止遍历，0 表示不限制
+	MaxDocs int64
+	// KeepAlive 两批之间搜索上下文的保持时长，默认 DefaultSearchAllKeepAlive，回调耗时较长时需要调大
+	KeepAlive time.Duration
+}
+
+// WithBatchSize 设置每批返回的文档数
+func WithBatchSize(size int) SearchAllOptFn {
+	return func(o *SearchAllOption) {
+		o.BatchSize = size
+	}
+}
+
+// WithMaxDocs 设置最多返回的文档数
+func WithMaxDocs(n int64) SearchAllOptFn {
+	return func(o *SearchAllOption) {
+		o.MaxDocs = n
+	}
+}
+
+// WithKeepAlive 设置搜索上下文的保持时长
+func WithKeepAlive(d time.Duration) SearchAllOptFn {
+	return func(o *SearchAllOption) {
+		o.KeepAlive = d
+	}
+}
+
+// NewSearchAllOption 应用选项并填充默认值，供各实现使用
+func NewSearchAllOption(opts ...SearchAllOptFn) *SearchAllOption {
+	o := &SearchAllOption{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	if o.BatchSize <= 0 {
+		o.BatchSize = DefaultSearchAllBatchSize
+	}
+	if o.KeepAlive <= 0 {
+		o.KeepAlive = DefaultSearchAllKeepAlive
+	}
+	return o
+}
+
+// Limit 截断超出 MaxDocs 的命中，seen 为此前已返回的文档数，done 表示已达到上限
+func (o *SearchAllOption) Limit(hits []Hit, seen int64) (limited []Hit, done bool) {
+	if o.MaxDocs <= 0 {
+		return hits, false
+	}
+	if remain := o.MaxDocs - seen; int64(len(hits)) >= remain {
+		return hits[:remain], true
+	}
+	return hits, false
+}
+
+// KeepAliveString 返回服务端识别的时长格式，如 60s
+func (o *SearchAllOption) KeepAliveString() string {
+	return strconv.FormatInt(int64(max(o.KeepAlive/time.Second, 1)), 10) + "s"
+}
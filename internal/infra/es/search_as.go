@@ -0,0 +1,42 @@
+package es
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ZampoRen/go-server-comon/pkg/sonic"
+)
+
+// SearchAs 搜索并将命中文档的 _source 解析为 T，返回顺序与命中顺序一致
+// TotalHits 仅在服务端返回总数时不为 nil
+func SearchAs[T any](ctx context.Context, c Client, index string, req *Request) ([]T, *TotalHits, error) {
+	resp, err := c.Search(ctx, index, req)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	docs, err := HitsAs[T](resp.Hits.Hits)
+	if err != nil {
+		return nil, nil, err
+	}
+	return docs, resp.Hits.Total, nil
+}
+
+// HitsAs 将命中文档的 _source 解析为 T，可用于 SearchAll 的回调
+func HitsAs[T any](hits []Hit) ([]T, error) {
+	docs := make([]T, 0, len(hits))
+	for _, hit := range hits {
+		var doc T
+		if len(hit.Source_) > 0 {
+			if err := sonic.Unmarshal(hit.Source_, &doc); err != nil {
+				id := ""
+				if hit.Id_ != nil {
+					id = *hit.Id_
+				}
+				return nil, fmt.Errorf("unmarshal hit %s failed: %w", id, err)
+			}
+		}
+		docs = append(docs, doc)
+	}
+	return docs, nil
+}
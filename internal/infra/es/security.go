@@ -0,0 +1,183 @@
+package es
+
+import (
+	"context"
+	"errors"
+
+	"github.com/ZampoRen/go-server-comon/pkg/lang/ptr"
+)
+
+var (
+	// ErrTenantNotFound 上下文中缺少租户信息
+	ErrTenantNotFound = errors.New("tenant not found in context")
+)
+
+type tenantKey struct{}
+
+// WithTenant 将租户 ID 写入上下文，供安全过滤器使用
+func WithTenant(ctx context.Context, tenantID string) context.Context {
+	return context.WithValue(ctx, tenantKey{}, tenantID)
+}
+
+// TenantFromContext 从上下文中获取租户 ID
+func TenantFromContext(ctx context.Context) (string, bool) {
+	tenantID, ok := ctx.Value(tenantKey{}).(string)
+	return tenantID, ok && tenantID != ""
+}
+
+// SecurityFilter 文档级安全过滤器
+// 根据上下文和索引返回需要追加到查询中的过滤条件，返回错误时查询会被拒绝
+type SecurityFilter func(ctx context.Context, index string) ([]Query, error)
+
+// TenantFilter 返回按租户字段过滤的安全过滤器
+// 上下文中没有租户信息时返回 ErrTenantNotFound，避免跨租户读取
+func TenantFilter(field string) SecurityFilter {
+	return func(ctx context.Context, index string) ([]Query, error) {
+		tenantID, ok := TenantFromContext(ctx)
+		if !ok {
+			return nil, ErrTenantNotFound
+		}
+		return []Query{NewEqualQuery(field, tenantID)}, nil
+	}
+}
+
+// WithSecurityFilter 包装客户端，在每次查询前自动追加安全过滤条件
+// 多个过滤器的条件会同时生效
+func WithSecurityFilter(c Client, filters ...SecurityFilter) Client {
+	if len(filters) == 0 {
+		return c
+	}
+	return &securedClient{Client: c, filters: filters}
+}
+
+// securedClient 带安全过滤的客户端
+type securedClient struct {
+	Client
+	filters []SecurityFilter
+}
+
+// Search 追加安全过滤条件后搜索文档
+func (c *securedClient) Search(ctx context.Context, index string, req *Request) (*Response, error) {
+	secured, err := c.secureRequest(ctx, index, req)
+	if err != nil {
+		return nil, err
+	}
+	return c.Client.Search(ctx, index, secured)
+}
+
+// SearchAll 追加安全过滤条件后遍历文档
+func (c *securedClient) SearchAll(ctx context.Context, index string, req *Request, fn func(hits []Hit) error, opts ...SearchAllOptFn) error {
+	secured, err := c.secureRequest(ctx, index, req)
+	if err != nil {
+		return err
+	}
+	return c.Client.SearchAll(ctx, index, secured, fn, opts...)
+}
+
+// Count 追加安全过滤条件后计数
+func (c *securedClient) Count(ctx context.Context, index string, query *Query) (int64, error) {
+	secured, err := c.secureQuery(ctx, index, query)
+	if err != nil {
+		return 0, err
+	}
+	return c.Client.Count(ctx, index, secured)
+}
+
+// MGet 按 ID 查询并追加安全过滤条件，不满足过滤条件的文档视为不存在
+// 与底层 MGet 不同，结果基于搜索，新写入但尚未刷新的文档不可见
+func (c *securedClient) MGet(ctx context.Context, index string, ids []string) ([]Hit, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	q := NewInQuery("_id", ids)
+	secured, err := c.secureRequest(ctx, index, &Request{Query: &q, Size: ptr.Of(len(ids))})
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.Client.Search(ctx, index, secured)
+	if err != nil {
+		return nil, err
+	}
+
+	byID := make(map[string]Hit, len(resp.Hits.Hits))
+	for _, hit := range resp.Hits.Hits {
+		if hit.Id_ != nil {
+			byID[*hit.Id_] = hit
+		}
+	}
+	hits := make([]Hit, 0, len(byID))
+	for _, id := range ids {
+		if hit, ok := byID[id]; ok {
+			hits = append(hits, hit)
+		}
+	}
+	return hits, nil
+}
+
+// UpdateByQuery 追加安全过滤条件后按查询更新
+func (c *securedClient) UpdateByQuery(ctx context.Context, index string, query *Query, script *Script, opts ...ByQueryOptFn) (*ByQueryResponse, error) {
+	secured, err := c.secureQuery(ctx, index, query)
+	if err != nil {
+		return nil, err
+	}
+	return c.Client.UpdateByQuery(ctx, index, secured, script, opts...)
+}
+
+// DeleteByQuery 追加安全过滤条件后按查询删除
+func (c *securedClient) DeleteByQuery(ctx context.Context, index string, query *Query, opts ...ByQueryOptFn) (*ByQueryResponse, error) {
+	if query == nil {
+		return nil, ErrQueryRequired
+	}
+	secured, err := c.secureQuery(ctx, index, query)
+	if err != nil {
+		return nil, err
+	}
+	return c.Client.DeleteByQuery(ctx, index, secured, opts...)
+}
+
+// secureRequest 复制请求并追加安全过滤条件，不修改调用方的请求
+func (c *securedClient) secureRequest(ctx context.Context, index string, req *Request) (*Request, error) {
+	secured := &Request{}
+	if req != nil {
+		*secured = *req
+	}
+
+	if secured.Knn != nil {
+		knn := *secured.Knn
+		filter, err := c.secureQuery(ctx, index, knn.Filter)
+		if err != nil {
+			return nil, err
+		}
+		knn.Filter = filter
+		secured.Knn = &knn
+		// 纯 kNN 搜索不追加 query，否则仅含过滤条件的 query 会命中所有可见文档
+		if secured.Query == nil {
+			return secured, nil
+		}
+	}
+
+	query, err := c.secureQuery(ctx, index, secured.Query)
+	if err != nil {
+		return nil, err
+	}
+	secured.Query = query
+	return secured, nil
+}
+
+// secureQuery 将原查询放入 bool must 保留相关性评分，安全过滤条件放入 bool filter 不参与评分
+func (c *securedClient) secureQuery(ctx context.Context, index string, q *Query) (*Query, error) {
+	var filter []Query
+	for _, f := range c.filters {
+		extra, err := f(ctx, index)
+		if err != nil {
+			return nil, err
+		}
+		filter = append(filter, extra...)
+	}
+
+	secured := &BoolQuery{Filter: filter}
+	if q != nil {
+		secured.Must = []Query{*q}
+	}
+	return &Query{Bool: secured}, nil
+}
@@ -0,0 +1,95 @@
+package es
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// recordClient 记录最后一次收到的请求
+type recordClient struct {
+	Client
+	req   *Request
+	query *Query
+}
+
+func (c *recordClient) Search(_ context.Context, _ string, req *Request) (*Response, error) {
+	c.req = req
+	return &Response{}, nil
+}
+
+func (c *recordClient) Count(_ context.Context, _ string, query *Query) (int64, error) {
+	c.query = query
+	return 0, nil
+}
+
+func TestSecurityFilter(t *testing.T) {
+	rec := &recordClient{}
+	c := WithSecurityFilter(rec, TenantFilter("tenant_id"))
+	ctx := WithTenant(context.Background(), "t1")
+	tenant := NewEqualQuery("tenant_id", "t1")
+	match := NewMatchQuery("title", "hello")
+
+	t.Run("原查询放入 must 保留评分", func(t *testing.T) {
+		req := &Request{Query: &match}
+		if _, err := c.Search(ctx, "docs", req); err != nil {
+			t.Fatal(err)
+		}
+		want := &Query{Bool: &BoolQuery{Must: []Query{match}, Filter: []Query{tenant}}}
+		if !reflect.DeepEqual(rec.req.Query, want) {
+			t.Fatalf("query = %+v, want %+v", rec.req.Query.Bool, want.Bool)
+		}
+		if req.Query != &match {
+			t.Fatal("caller request should not be modified")
+		}
+	})
+
+	t.Run("没有查询时只有过滤条件", func(t *testing.T) {
+		if _, err := c.Count(ctx, "docs", nil); err != nil {
+			t.Fatal(err)
+		}
+		want := &Query{Bool: &BoolQuery{Filter: []Query{tenant}}}
+		if !reflect.DeepEqual(rec.query, want) {
+			t.Fatalf("query = %+v, want %+v", rec.query.Bool, want.Bool)
+		}
+	})
+
+	t.Run("kNN 过滤条件", func(t *testing.T) {
+		knnFilter := NewEqualQuery("lang", "zh")
+		knn := NewKnnQuery("embedding", []float32{1, 0}, 10, 100)
+		knn.Filter = &knnFilter
+		if _, err := c.Search(ctx, "docs", &Request{Knn: knn}); err != nil {
+			t.Fatal(err)
+		}
+		want := &Query{Bool: &BoolQuery{Must: []Query{knnFilter}, Filter: []Query{tenant}}}
+		if !reflect.DeepEqual(rec.req.Knn.Filter, want) {
+			t.Fatalf("knn filter = %+v, want %+v", rec.req.Knn.Filter.Bool, want.Bool)
+		}
+		if rec.req.Query != nil {
+			t.Fatalf("pure kNN search should not add query, got %+v", rec.req.Query)
+		}
+		if knn.Filter != &knnFilter {
+			t.Fatal("caller kNN query should not be modified")
+		}
+	})
+
+	t.Run("混合检索", func(t *testing.T) {
+		knn := NewKnnQuery("embedding", []float32{1, 0}, 10, 100)
+		if _, err := c.Search(ctx, "docs", &Request{Query: &match, Knn: knn}); err != nil {
+			t.Fatal(err)
+		}
+		wantKnn := &Query{Bool: &BoolQuery{Filter: []Query{tenant}}}
+		wantQuery := &Query{Bool: &BoolQuery{Must: []Query{match}, Filter: []Query{tenant}}}
+		if !reflect.DeepEqual(rec.req.Knn.Filter, wantKnn) || !reflect.DeepEqual(rec.req.Query, wantQuery) {
+			t.Fatalf("request = %+v", rec.req)
+		}
+	})
+
+	t.Run("缺少租户时拒绝查询", func(t *testing.T) {
+		_, err := c.Search(context.Background(), "docs", &Request{Query: &match})
+		if !errors.Is(err, ErrTenantNotFound) {
+			t.Fatalf("Search() error = %v, want ErrTenantNotFound", err)
+		}
+	})
+}
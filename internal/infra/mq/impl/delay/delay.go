@@ -0,0 +1,199 @@
+// Package delay 基于 Redis 有序集合的延迟消息调度器，为不支持任意延迟的消息队列实现 PublishDelay
+//
+// 延迟消息以到期时间为分数写入有序集合，Run 定期取出到期的消息交给发送函数投递。
+// 取出时不删除而是将分数推后一个租期，发送成功后才删除；调度器在发送前崩溃时，消息在租期结束后被其他实例重新取出，
+// 投递语义为至少一次。多个实例可以同时运行 Run，取出操作由脚本保证原子性
+package delay
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/common/hlog"
+
+	"github.com/ZampoRen/go-server-comon/internal/infra/cache"
+	"github.com/ZampoRen/go-server-comon/internal/infra/mq"
+)
+
+const (
+	// addScript 写入延迟消息，ARGV[1] 为到期时间（毫秒），ARGV[2] 为消息
+	addScript = `return redis.call("ZADD", KEYS[1], ARGV[1], ARGV[2])`
+	// claimScript 取出至多 ARGV[2] 条到期时间不晚于 ARGV[1] 的消息，并将其到期时间推后到 ARGV[3]
+	claimScript = `local items = redis.call("ZRANGEBYSCORE", KEYS[1], "-inf", ARGV[1], "LIMIT", 0, ARGV[2])
+for _, item in ipairs(items) do
+	redis.call("ZADD", KEYS[1], ARGV[3], item)
+end
+return items`
+	// removeScript 删除已发送的消息
+	removeScript = `return redis.call("ZREM", KEYS[1], unpack(ARGV))`
+	// countScript 返回尚未发送的消息数
+	countScript = `return redis.call("ZCARD", KEYS[1])`
+)
+
+// SendFunc 投递到期消息的函数，通常为目标消息队列的 Send
+type SendFunc func(ctx context.Context, msgs ...*mq.Message) error
+
+// Option 选项
+type Option func(o *option)
+
+type option struct {
+	key      string
+	interval time.Duration
+	batch    int64
+	lease    time.Duration
+}
+
+// WithKey 设置有序集合的键，默认 mq:delay
+func WithKey(key string) Option {
+	return func(o *option) {
+		if key != "" {
+			o.key = key
+		}
+	}
+}
+
+// WithInterval 设置检查到期消息的间隔，默认 1s，决定了延迟的精度
+func WithInterval(d time.Duration) Option {
+	return func(o *option) {
+		if d > 0 {
+			o.interval = d
+		}
+	}
+}
+
+// WithBatch 设置每次取出的最大消息数，默认 100
+func WithBatch(n int64) Option {
+	return func(o *option) {
+		if n > 0 {
+			o.batch = n
+		}
+	}
+}
+
+// WithLease 设置取出后到重新可见的租期，默认 30s，应大于一批消息的发送耗时
+func WithLease(d time.Duration) Option {
+	return func(o *option) {
+		if d > 0 {
+			o.lease = d
+		}
+	}
+}
+
+// Scheduler 延迟消息调度器
+type Scheduler struct {
+	client cache.Cmdable
+	send   SendFunc
+	o      *option
+}
+
+// New 创建调度器，client 需支持 Lua 脚本，到期的消息通过 send 投递
+func New(client cache.Cmdable, send SendFunc, opts ...Option) *Scheduler {
+	o := &option{key: "mq:delay", interval: time.Second, batch: 100, lease: 30 * time.Second}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return &Scheduler{client: client, send: send, o: o}
+}
+
+// envelope 延迟消息在有序集合中的编码，ID 保证相同内容的消息不会被合并
+type envelope struct {
+	ID      string            `json:"id"`
+	Topic   string            `json:"topic"`
+	Key     string            `json:"key,omitempty"`
+	Tag     string            `json:"tag,omitempty"`
+	Body    []byte            `json:"body,omitempty"`
+	Headers map[string]string `json:"headers,omitempty"`
+}
+
+// Schedule 保存消息，delay 之后由 Run 投递到 topic
+func (s *Scheduler) Schedule(ctx context.Context, topic string, msg *mq.Message, delay time.Duration) error {
+	id := make([]byte, 8)
+	_, _ = rand.Read(id)
+	member, err := json.Marshal(&envelope{
+		ID:      hex.EncodeToString(id),
+		Topic:   topic,
+		Key:     msg.Key,
+		Tag:     msg.Tag,
+		Body:    msg.Body,
+		Headers: msg.Headers,
+	})
+	if err != nil {
+		return err
+	}
+	due := time.Now().Add(delay).UnixMilli()
+	if err := s.client.Eval(ctx, addScript, []string{s.o.key}, due, member).Err(); err != nil {
+		return fmt.Errorf("mq: schedule delayed message to %s: %w", topic, err)
+	}
+	return nil
+}
+
+// Pending 返回尚未发送的延迟消息数，包括已取出但未确认发送成功的消息
+func (s *Scheduler) Pending(ctx context.Context) (int64, error) {
+	return s.client.Eval(ctx, countScript, []string{s.o.key}).Int64()
+}
+
+// Run 定期投递到期的消息，直到 ctx 结束
+func (s *Scheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.o.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		// 取满一批说明可能还有到期的消息，继续取出直到不足一批
+		for ctx.Err() == nil {
+			n, err := s.poll(ctx)
+			if err != nil {
+				if ctx.Err() == nil && !errors.Is(err, context.Canceled) {
+					hlog.CtxWarnf(ctx, "[MQ] poll delayed messages from %s failed: %v", s.o.key, err)
+				}
+				break
+			}
+			if int64(n) < s.o.batch {
+				break
+			}
+		}
+	}
+}
+
+// poll 取出一批到期的消息并投递，返回取出的消息数
+func (s *Scheduler) poll(ctx context.Context) (int, error) {
+	now := time.Now()
+	res, err := s.client.Eval(ctx, claimScript, []string{s.o.key},
+		now.UnixMilli(), s.o.batch, now.Add(s.o.lease).UnixMilli()).Result()
+	if err != nil {
+		return 0, err
+	}
+	items, _ := res.([]interface{})
+
+	done := make([]interface{}, 0, len(items))
+	for _, item := range items {
+		member, _ := item.(string)
+		var e envelope
+		if err := json.Unmarshal([]byte(member), &e); err != nil {
+			hlog.CtxErrorf(ctx, "[MQ] drop malformed delayed message %q: %v", member, err)
+			done = append(done, member)
+			continue
+		}
+		m := &mq.Message{Topic: e.Topic, Key: e.Key, Tag: e.Tag, Body: e.Body, Headers: e.Headers}
+		if err := s.send(ctx, m); err != nil {
+			// 不删除，租期结束后重新取出
+			hlog.CtxWarnf(ctx, "[MQ] send delayed message %s to %s failed: %v", e.ID, e.Topic, err)
+			continue
+		}
+		done = append(done, member)
+	}
+	if len(done) > 0 {
+		if err := s.client.Eval(context.WithoutCancel(ctx), removeScript, []string{s.o.key}, done...).Err(); err != nil {
+			return len(items), fmt.Errorf("remove %d delayed messages: %w", len(done), err)
+		}
+	}
+	return len(items), nil
+}
@@ -0,0 +1,345 @@
+// Package memory 进程内消息队列，用于本地开发与测试
+//
+// 消息不持久化，进程退出或消费者关闭时未处理的消息会丢失；顺序消费只在单个消费者内保证
+// 延迟消息由进程内定时器投递，到期时才分发给当时已订阅的消费组
+package memory
+
+import (
+	"context"
+	"hash/fnv"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/common/hlog"
+
+	"github.com/ZampoRen/go-server-comon/internal/infra/mq"
+)
+
+// Option 进程内消息队列选项
+type Option func(o *option)
+
+type option struct {
+	queueSize  int
+	retryDelay time.Duration
+}
+
+// WithQueueSize 设置每个主题每个消费组的队列长度，默认 1024，队列满时 Send 阻塞
+func WithQueueSize(n int) Option {
+	return func(o *option) {
+		if n > 0 {
+			o.queueSize = n
+		}
+	}
+}
+
+// WithRetryDelay 设置首次重试的延迟，之后每次翻倍，最长 10s，默认 100ms
+func WithRetryDelay(d time.Duration) Option {
+	return func(o *option) {
+		if d > 0 {
+			o.retryDelay = d
+		}
+	}
+}
+
+// Broker 进程内消息队列，消费组在首次订阅时创建，之前发送到该主题的消息不会投递给它
+type Broker struct {
+	o *option
+
+	mu     sync.Mutex
+	queues map[string]map[string]chan *mq.Message // topic -> group -> queue
+	seq    atomic.Int64
+}
+
+// New 创建进程内消息队列
+func New(opts ...Option) *Broker {
+	o := &option{queueSize: 1024, retryDelay: 100 * time.Millisecond}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return &Broker{o: o, queues: make(map[string]map[string]chan *mq.Message)}
+}
+
+var defaultBroker = New()
+
+// Default 返回进程内共享的消息队列，MQ_TYPE=memory 时使用
+func Default() *Broker {
+	return defaultBroker
+}
+
+// Producer 返回生产者
+func (b *Broker) Producer() mq.Producer {
+	return &producer{b: b}
+}
+
+// NewConsumer 创建消费组 group 的消费者
+func (b *Broker) NewConsumer(group string, opts ...mq.ConsumerOptFn) mq.Consumer {
+	return &consumer{b: b, group: group, o: mq.NewConsumerOption(opts...)}
+}
+
+// queue 返回主题下消费组的队列，不存在时创建
+func (b *Broker) queue(topic, group string) chan *mq.Message {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	groups, ok := b.queues[topic]
+	if !ok {
+		groups = make(map[string]chan *mq.Message)
+		b.queues[topic] = groups
+	}
+	q, ok := groups[group]
+	if !ok {
+		q = make(chan *mq.Message, b.o.queueSize)
+		groups[group] = q
+	}
+	return q
+}
+
+// publish 向主题的每个消费组投递一份消息
+func (b *Broker) publish(ctx context.Context, m *mq.Message) error {
+	b.mu.Lock()
+	queues := make([]chan *mq.Message, 0, len(b.queues[m.Topic]))
+	for _, q := range b.queues[m.Topic] {
+		queues = append(queues, q)
+	}
+	b.mu.Unlock()
+
+	id := strconv.FormatInt(b.seq.Add(1), 10)
+	now := time.Now()
+	for _, q := range queues {
+		msg := *m
+		msg.ID, msg.Attempt, msg.PublishedAt = id, 1, now
+		select {
+		case q <- &msg:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+type producer struct {
+	b      *Broker
+	closed atomic.Bool
+}
+
+// Send 依次投递消息，队列满时阻塞直到 ctx 结束
+func (p *producer) Send(ctx context.Context, msgs ...*mq.Message) error {
+	if p.closed.Load() {
+		return mq.ErrClosed
+	}
+	for _, m := range msgs {
+		if err := p.b.publish(ctx, m); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// PublishDelay 使用进程内定时器延迟投递，进程退出时尚未到期的消息丢失
+func (p *producer) PublishDelay(ctx context.Context, topic string, msg *mq.Message, delay time.Duration) error {
+	if p.closed.Load() {
+		return mq.ErrClosed
+	}
+	m := *msg
+	m.Topic = topic
+	if delay <= 0 {
+		return p.b.publish(ctx, &m)
+	}
+	time.AfterFunc(delay, func() {
+		if err := p.b.publish(context.WithoutCancel(ctx), &m); err != nil {
+			hlog.CtxWarnf(ctx, "[MQ] publish delayed message to %s failed: %v", topic, err)
+		}
+	})
+	return nil
+}
+
+// Close 关闭生产者
+func (p *producer) Close() error {
+	p.closed.Store(true)
+	return nil
+}
+
+type subscription struct {
+	topic string
+	h     mq.Handler
+	o     *mq.SubscribeOption
+	queue chan *mq.Message
+}
+
+type consumer struct {
+	b     *Broker
+	group string
+	o     *mq.ConsumerOption
+
+	mu      sync.Mutex
+	subs    []*subscription
+	started bool
+	closed  bool
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// Subscribe 订阅主题，订阅后发送到该主题的消息即开始在队列中累积
+func (c *consumer) Subscribe(topic string, h mq.Handler, opts ...mq.SubscribeOptFn) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return mq.ErrClosed
+	}
+	if c.started {
+		return mq.ErrStarted
+	}
+	c.subs = append(c.subs, &subscription{
+		topic: topic,
+		h:     c.o.Wrap(c.group, topic, h),
+		o:     mq.NewSubscribeOption(opts...),
+		queue: c.b.queue(topic, c.group),
+	})
+	return nil
+}
+
+// Start 为每个订阅启动处理协程
+func (c *consumer) Start(ctx context.Context) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return mq.ErrClosed
+	}
+	if c.started {
+		return mq.ErrStarted
+	}
+	c.started = true
+
+	ctx, c.cancel = context.WithCancel(context.WithoutCancel(ctx))
+	for _, sub := range c.subs {
+		if c.o.Orderly {
+			c.startOrderly(ctx, sub)
+			continue
+		}
+		for i := 0; i < c.o.Concurrency; i++ {
+			c.wg.Go(func() { c.work(ctx, sub, sub.queue) })
+		}
+	}
+	return nil
+}
+
+// startOrderly 按 Key 的哈希将消息分到 Concurrency 个通道，每个通道串行处理
+func (c *consumer) startOrderly(ctx context.Context, sub *subscription) {
+	lanes := make([]chan *mq.Message, c.o.Concurrency)
+	for i := range lanes {
+		lanes[i] = make(chan *mq.Message)
+		c.wg.Go(func() { c.work(ctx, sub, lanes[i]) })
+	}
+	c.wg.Go(func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case m := <-sub.queue:
+				h := fnv.New32a()
+				_, _ = h.Write([]byte(m.Key))
+				select {
+				case lanes[h.Sum32()%uint32(len(lanes))] <- m:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	})
+}
+
+func (c *consumer) work(ctx context.Context, sub *subscription, ch <-chan *mq.Message) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case m := <-ch:
+			c.handle(ctx, sub, m)
+		}
+	}
+}
+
+// handle 处理一条消息，失败时重试：并发消费时延迟后放回队列，顺序消费时在原地等待后重试以保持顺序
+// Handler 的 ctx 不随 Close 取消，保证处理中的消息能够完成
+func (c *consumer) handle(ctx context.Context, sub *subscription, m *mq.Message) {
+	if !sub.o.Match(m.Tag) {
+		return
+	}
+	for {
+		err := sub.h(context.WithoutCancel(ctx), m)
+		if err == nil {
+			return
+		}
+		if m.Attempt > c.o.MaxRetries {
+			hlog.CtxErrorf(ctx, "[MQ] drop message %s of %s after %d attempts: %v", m.ID, m.Topic, m.Attempt, err)
+			return
+		}
+		hlog.CtxWarnf(ctx, "[MQ] handle message %s of %s failed, attempt %d: %v", m.ID, m.Topic, m.Attempt, err)
+
+		retry := *m
+		retry.Attempt++
+		delay := c.retryDelay(m.Attempt)
+		if !c.o.Orderly {
+			c.wg.Go(func() { c.redeliver(ctx, sub.queue, &retry, delay) })
+			return
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+		m = &retry
+	}
+}
+
+// redeliver 延迟后将消息放回队列，消费者关闭时尽量放回，队列已满则丢弃
+func (c *consumer) redeliver(ctx context.Context, queue chan *mq.Message, m *mq.Message, delay time.Duration) {
+	select {
+	case <-time.After(delay):
+		select {
+		case queue <- m:
+		case <-ctx.Done():
+		}
+	case <-ctx.Done():
+		select {
+		case queue <- m:
+		default:
+			hlog.CtxWarnf(ctx, "[MQ] queue of %s is full, drop retrying message %s", m.Topic, m.ID)
+		}
+	}
+}
+
+func (c *consumer) retryDelay(attempt int) time.Duration {
+	d := c.b.o.retryDelay << min(attempt-1, 16)
+	return min(d, 10*time.Second)
+}
+
+// Close 停止处理协程并等待处理中的消息完成
+func (c *consumer) Close(ctx context.Context) error {
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		return nil
+	}
+	c.closed = true
+	cancel := c.cancel
+	c.mu.Unlock()
+	if cancel == nil {
+		return nil
+	}
+	cancel()
+
+	done := make(chan struct{})
+	go func() {
+		c.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
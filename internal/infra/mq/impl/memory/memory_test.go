@@ -0,0 +1,165 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ZampoRen/go-server-comon/internal/infra/mq"
+)
+
+// collector 记录收到的消息
+type collector struct {
+	mu   sync.Mutex
+	msgs []*mq.Message
+}
+
+func (c *collector) handle(_ context.Context, m *mq.Message) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.msgs = append(c.msgs, m)
+	return nil
+}
+
+func (c *collector) wait(t *testing.T, n int) []*mq.Message {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		c.mu.Lock()
+		if len(c.msgs) >= n {
+			msgs := append([]*mq.Message(nil), c.msgs...)
+			c.mu.Unlock()
+			return msgs
+		}
+		c.mu.Unlock()
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("received fewer than %d messages", n)
+	return nil
+}
+
+func startConsumer(t *testing.T, b *Broker, group, topic string, h mq.Handler, opts []mq.ConsumerOptFn, subOpts ...mq.SubscribeOptFn) {
+	t.Helper()
+	c := b.NewConsumer(group, opts...)
+	if err := c.Subscribe(topic, h, subOpts...); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = c.Close(context.Background()) })
+}
+
+// TestBroker 测试消费组、标签过滤、重试与顺序消费
+func TestBroker(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("每个消费组各收到一份", func(t *testing.T) {
+		b := New()
+		var g1, g2 collector
+		startConsumer(t, b, "g1", "topic", g1.handle, nil)
+		startConsumer(t, b, "g2", "topic", g2.handle, nil, mq.WithTags("created"))
+
+		p := b.Producer()
+		err := p.Send(ctx,
+			&mq.Message{Topic: "topic", Tag: "created", Body: []byte("a")},
+			&mq.Message{Topic: "topic", Tag: "deleted", Body: []byte("b")},
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+		g1.wait(t, 2)
+		if got := g2.wait(t, 1); string(got[0].Body) != "a" || got[0].ID == "" || got[0].Attempt != 1 {
+			t.Fatalf("g2 received %+v", got[0])
+		}
+		time.Sleep(20 * time.Millisecond)
+		if len(g2.msgs) != 1 {
+			t.Fatalf("g2 received %d messages, want 1", len(g2.msgs))
+		}
+	})
+
+	t.Run("失败重试", func(t *testing.T) {
+		b := New(WithRetryDelay(time.Millisecond))
+		var c collector
+		startConsumer(t, b, "g", "topic", func(ctx context.Context, m *mq.Message) error {
+			if m.Attempt < 3 {
+				return errors.New("temporary")
+			}
+			return c.handle(ctx, m)
+		}, nil)
+
+		if err := b.Producer().Send(ctx, &mq.Message{Topic: "topic"}); err != nil {
+			t.Fatal(err)
+		}
+		if got := c.wait(t, 1); got[0].Attempt != 3 {
+			t.Fatalf("Attempt = %d, want 3", got[0].Attempt)
+		}
+	})
+
+	t.Run("顺序消费", func(t *testing.T) {
+		b := New(WithRetryDelay(time.Millisecond))
+		var c collector
+		failed := false
+		startConsumer(t, b, "g", "topic", func(ctx context.Context, m *mq.Message) error {
+			if string(m.Body) == "k1-1" && !failed {
+				failed = true
+				return errors.New("temporary")
+			}
+			return c.handle(ctx, m)
+		}, []mq.ConsumerOptFn{mq.WithOrderly(), mq.WithConcurrency(4)})
+
+		p := b.Producer()
+		for i := 0; i < 5; i++ {
+			for _, key := range []string{"k1", "k2"} {
+				if err := p.Send(ctx, &mq.Message{Topic: "topic", Key: key, Body: fmt.Appendf(nil, "%s-%d", key, i)}); err != nil {
+					t.Fatal(err)
+				}
+			}
+		}
+
+		next := map[string]int{}
+		for _, m := range c.wait(t, 10) {
+			want := fmt.Sprintf("%s-%d", m.Key, next[m.Key])
+			if string(m.Body) != want {
+				t.Fatalf("got %s, want %s", m.Body, want)
+			}
+			next[m.Key]++
+		}
+	})
+
+	t.Run("延迟投递", func(t *testing.T) {
+		b := New()
+		var c collector
+		startConsumer(t, b, "g", "timeout", c.handle, nil)
+
+		start := time.Now()
+		if err := b.Producer().PublishDelay(ctx, "timeout", &mq.Message{Key: "order-1"}, 50*time.Millisecond); err != nil {
+			t.Fatal(err)
+		}
+		got := c.wait(t, 1)
+		if elapsed := time.Since(start); elapsed < 50*time.Millisecond {
+			t.Fatalf("delivered after %v, want >= 50ms", elapsed)
+		}
+		if got[0].Topic != "timeout" || got[0].Key != "order-1" {
+			t.Fatalf("received %+v", got[0])
+		}
+	})
+
+	t.Run("关闭后拒绝发送与订阅", func(t *testing.T) {
+		b := New()
+		p := b.Producer()
+		_ = p.Close()
+		if err := p.Send(ctx, &mq.Message{Topic: "topic"}); !errors.Is(err, mq.ErrClosed) {
+			t.Fatalf("Send() error = %v, want ErrClosed", err)
+		}
+		c := b.NewConsumer("g")
+		_ = c.Start(ctx)
+		if err := c.Subscribe("topic", func(context.Context, *mq.Message) error { return nil }); !errors.Is(err, mq.ErrStarted) {
+			t.Fatalf("Subscribe() error = %v, want ErrStarted", err)
+		}
+		_ = c.Close(ctx)
+	})
+}
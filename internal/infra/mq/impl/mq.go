@@ -0,0 +1,115 @@
+// Package impl 按 MQ_TYPE 创建消息队列的生产者与消费者
+package impl
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ZampoRen/go-server-comon/internal/infra/cache"
+	cacheredis "github.com/ZampoRen/go-server-comon/internal/infra/cache/impl/redis"
+	"github.com/ZampoRen/go-server-comon/internal/infra/mq"
+	"github.com/ZampoRen/go-server-comon/internal/infra/mq/impl/memory"
+	"github.com/ZampoRen/go-server-comon/internal/infra/mq/impl/redis"
+	"github.com/ZampoRen/go-server-comon/pkg/envkey"
+)
+
+// backend 一种消息队列实现的构造函数，从环境变量读取配置
+type backend struct {
+	producer func(ctx context.Context) (mq.Producer, error)
+	consumer func(ctx context.Context, group string, opts ...mq.ConsumerOptFn) (mq.Consumer, error)
+}
+
+// backends 已编译的实现，依赖较重的实现通过构建标签注册，如 -tags rocketmq
+var backends = map[string]backend{
+	"memory": {
+		producer: func(context.Context) (mq.Producer, error) {
+			return memory.Default().Producer(), nil
+		},
+		consumer: func(_ context.Context, group string, opts ...mq.ConsumerOptFn) (mq.Consumer, error) {
+			return memory.Default().NewConsumer(group, opts...), nil
+		},
+	},
+	"redis": {
+		producer: func(context.Context) (mq.Producer, error) {
+			q, err := redisQueue()
+			if err != nil {
+				return nil, err
+			}
+			return q.Producer(), nil
+		},
+		consumer: func(_ context.Context, group string, opts ...mq.ConsumerOptFn) (mq.Consumer, error) {
+			q, err := redisQueue()
+			if err != nil {
+				return nil, err
+			}
+			return q.NewConsumer(group, opts...), nil
+		},
+	},
+}
+
+// redisQueue 进程内共享的 Redis Streams 消息队列，使用独立于缓存的连接池，避免阻塞读取占用缓存连接
+var redisQueue = sync.OnceValues(func() (*redis.Queue, error) {
+	return redis.New(redisClient(),
+		redis.WithKeyPrefix(envkey.GetStringD("MQ_REDIS_KEY_PREFIX", "mq:")),
+		redis.WithMaxLen(envkey.GetI64D("MQ_REDIS_MAXLEN", 0)),
+		redis.WithVisibilityTimeout(envkey.GetDurationD("MQ_REDIS_VISIBILITY_TIMEOUT", 30*time.Second)),
+		redis.WithDelayInterval(envkey.GetDurationD("MQ_REDIS_DELAY_INTERVAL", time.Second)),
+	)
+})
+
+// redisClient 进程内共享的消息队列专用 Redis 客户端，供 Redis Streams 与延迟消息使用
+var redisClient = sync.OnceValue(func() cache.Cmdable {
+	if addr := envkey.GetStringD("MQ_REDIS_ADDR", ""); addr != "" {
+		return cacheredis.NewWithAddrAndPassword(addr, envkey.GetStringD("MQ_REDIS_PASSWORD", ""), cacheredis.WithName("mq"))
+	}
+	return cacheredis.New(cacheredis.WithName("mq"))
+})
+
+// NewProducer 根据环境变量创建生产者
+// 环境变量:
+//   - MQ_TYPE: 消息队列类型 (rocketmq/redis/memory)，rocketmq 需使用 -tags rocketmq 构建
+//   - MQ_REDIS_ADDR, MQ_REDIS_PASSWORD: Redis Streams 与 RocketMQ 延迟消息使用的 Redis，未设置时使用 REDIS_ADDR、REDIS_PASSWORD
+//   - MQ_REDIS_KEY_PREFIX: 流的键前缀（默认 mq:）
+//   - MQ_REDIS_MAXLEN: 流的近似最大长度（默认不裁剪）
+//   - MQ_REDIS_VISIBILITY_TIMEOUT: 可见性超时（默认 30s）
+//   - MQ_REDIS_DELAY_INTERVAL: 检查到期延迟消息的间隔（默认 1s）
+//   - ROCKETMQ_NAME_SERVERS: NameServer 地址，逗号分隔
+//   - ROCKETMQ_NAMESPACE: 命名空间
+//   - ROCKETMQ_ACCESS_KEY, ROCKETMQ_SECRET_KEY: ACL 凭证
+//   - ROCKETMQ_PRODUCER_GROUP: 生产者组
+//   - ROCKETMQ_RETRY: 发送失败时的重试次数（默认 2）
+//   - ROCKETMQ_DELAY_KEY: 延迟不等于任何延迟级别时，保存延迟消息的有序集合的键（默认 rocketmq:__delay__）
+func NewProducer(ctx context.Context) (mq.Producer, error) {
+	b, err := backendFromEnv()
+	if err != nil {
+		return nil, err
+	}
+	return b.producer(ctx)
+}
+
+// NewConsumer 根据环境变量创建消费组 group 的消费者，环境变量同 NewProducer
+func NewConsumer(ctx context.Context, group string, opts ...mq.ConsumerOptFn) (mq.Consumer, error) {
+	b, err := backendFromEnv()
+	if err != nil {
+		return nil, err
+	}
+	return b.consumer(ctx, group, opts...)
+}
+
+func backendFromEnv() (backend, error) {
+	mqType := envkey.GetStringD("MQ_TYPE", "")
+	b, ok := backends[mqType]
+	if !ok {
+		types := make([]string, 0, len(backends))
+		for t := range backends {
+			types = append(types, t)
+		}
+		sort.Strings(types)
+		return backend{}, fmt.Errorf("unknown mq type: %s, supported types: %s", mqType, strings.Join(types, ", "))
+	}
+	return b, nil
+}
@@ -0,0 +1,537 @@
+// Package redis 基于 Redis Streams 的轻量消息队列，适用于不想引入 Kafka、RocketMQ 的小型服务
+//
+// 每个主题对应一个流，消费组对应流的消费组。消费者处理成功后确认消息；处理失败或消费者崩溃时消息留在待确认列表，
+// 空闲超过可见性超时（WithVisibilityTimeout）后被同组的消费者认领并重新投递，超过最大重试次数后确认并丢弃。
+// 顺序消费只在单个消费者内保证：新消息与认领的消息交给同一个协程串行处理，失败的消息原地重试并阻塞后续消息，
+// 重试前刷新消息的空闲时间，避免被同组的其他消费者认领。
+// 延迟消息先写入有序集合（见 delay 包），由运行中的消费者在到期后追加到流，没有消费者运行时延迟消息会累积
+package redis
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/common/hlog"
+
+	"github.com/ZampoRen/go-server-comon/internal/infra/cache"
+	"github.com/ZampoRen/go-server-comon/internal/infra/mq"
+	"github.com/ZampoRen/go-server-comon/internal/infra/mq/impl/delay"
+)
+
+// 消息在流中的字段名
+const (
+	fieldKey     = "key"
+	fieldTag     = "tag"
+	fieldBody    = "body"
+	fieldHeaders = "headers"
+	fieldTime    = "ts"
+)
+
+// Option 选项
+type Option func(o *option)
+
+type option struct {
+	keyPrefix         string
+	maxLen            int64
+	visibilityTimeout time.Duration
+	block             time.Duration
+	delayInterval     time.Duration
+}
+
+// WithKeyPrefix 设置流的键前缀，流的键为前缀加主题，默认 mq:
+func WithKeyPrefix(prefix string) Option {
+	return func(o *option) {
+		o.keyPrefix = prefix
+	}
+}
+
+// WithMaxLen 设置流的近似最大长度，发送时裁剪，默认不裁剪
+// 裁剪会删除尚未被慢消费组读取的消息，应设置为远大于积压量的值
+func WithMaxLen(n int64) Option {
+	return func(o *option) {
+		o.maxLen = n
+	}
+}
+
+// WithVisibilityTimeout 设置可见性超时，默认 30s
+// 消息投递后超过该时间未确认即可被同组的其他消费者认领，应大于 Handler 的最长处理时间
+func WithVisibilityTimeout(d time.Duration) Option {
+	return func(o *option) {
+		if d > 0 {
+			o.visibilityTimeout = d
+		}
+	}
+}
+
+// WithBlock 设置读取新消息时的最长阻塞时间，默认 2s，影响 Close 的等待时间
+func WithBlock(d time.Duration) Option {
+	return func(o *option) {
+		if d > 0 {
+			o.block = d
+		}
+	}
+}
+
+// WithDelayInterval 设置检查到期延迟消息的间隔，默认 1s
+func WithDelayInterval(d time.Duration) Option {
+	return func(o *option) {
+		if d > 0 {
+			o.delayInterval = d
+		}
+	}
+}
+
+func newOption(opts []Option) *option {
+	o := &option{keyPrefix: "mq:", visibilityTimeout: 30 * time.Second, block: 2 * time.Second, delayInterval: time.Second}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// Queue 基于 Redis Streams 的消息队列
+type Queue struct {
+	streams cache.Streams
+	delay   *delay.Scheduler
+	o       *option
+}
+
+// New 创建消息队列，client 需实现 cache.Streams，否则返回 cache.ErrStreamsNotSupported
+// 延迟消息保存在键为前缀加 __delay__ 的有序集合中
+func New(client cache.Cmdable, opts ...Option) (*Queue, error) {
+	streams, ok := client.(cache.Streams)
+	if !ok {
+		return nil, cache.ErrStreamsNotSupported
+	}
+	q := &Queue{streams: streams, o: newOption(opts)}
+	q.delay = delay.New(client, q.send,
+		delay.WithKey(q.o.keyPrefix+"__delay__"),
+		delay.WithInterval(q.o.delayInterval),
+		delay.WithLease(q.o.visibilityTimeout),
+	)
+	return q, nil
+}
+
+// Producer 返回生产者
+func (q *Queue) Producer() mq.Producer {
+	return &producer{q: q}
+}
+
+// send 依次将消息追加到主题对应的流
+func (q *Queue) send(ctx context.Context, msgs ...*mq.Message) error {
+	for _, m := range msgs {
+		values, err := encode(m)
+		if err != nil {
+			return err
+		}
+		if _, err := q.streams.XAdd(ctx, q.o.keyPrefix+m.Topic, q.o.maxLen, values); err != nil {
+			return fmt.Errorf("mq: send to %s: %w", m.Topic, err)
+		}
+	}
+	return nil
+}
+
+// NewConsumer 创建消费组 group 的消费者
+// 消费组在首次 Start 时创建，只消费创建之后写入的消息
+func (q *Queue) NewConsumer(group string, opts ...mq.ConsumerOptFn) mq.Consumer {
+	return &consumer{
+		streams: q.streams,
+		delay:   q.delay,
+		group:   group,
+		name:    consumerName(),
+		o:       q.o,
+		co:      mq.NewConsumerOption(opts...),
+	}
+}
+
+type producer struct {
+	q      *Queue
+	closed bool
+	mu     sync.RWMutex
+}
+
+// Send 依次将消息追加到主题对应的流
+func (p *producer) Send(ctx context.Context, msgs ...*mq.Message) error {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if p.closed {
+		return mq.ErrClosed
+	}
+	return p.q.send(ctx, msgs...)
+}
+
+// PublishDelay 将消息写入延迟集合，到期后由消费者追加到流，精度为 WithDelayInterval
+func (p *producer) PublishDelay(ctx context.Context, topic string, msg *mq.Message, delay time.Duration) error {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if p.closed {
+		return mq.ErrClosed
+	}
+	if delay <= 0 {
+		m := *msg
+		m.Topic = topic
+		return p.q.send(ctx, &m)
+	}
+	return p.q.delay.Schedule(ctx, topic, msg, delay)
+}
+
+// Close 关闭生产者，不关闭 Redis 客户端
+func (p *producer) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.closed = true
+	return nil
+}
+
+func encode(m *mq.Message) (map[string]interface{}, error) {
+	values := map[string]interface{}{
+		fieldKey:  m.Key,
+		fieldTag:  m.Tag,
+		fieldBody: m.Body,
+		fieldTime: time.Now().UnixMilli(),
+	}
+	if len(m.Headers) > 0 {
+		headers, err := json.Marshal(m.Headers)
+		if err != nil {
+			return nil, err
+		}
+		values[fieldHeaders] = headers
+	}
+	return values, nil
+}
+
+func decode(topic string, sm cache.StreamMessage, attempt int) *mq.Message {
+	str := func(field string) string {
+		s, _ := sm.Values[field].(string)
+		return s
+	}
+	m := &mq.Message{
+		Topic:   topic,
+		Key:     str(fieldKey),
+		Tag:     str(fieldTag),
+		Body:    []byte(str(fieldBody)),
+		ID:      sm.ID,
+		Attempt: attempt,
+	}
+	if headers := str(fieldHeaders); headers != "" {
+		_ = json.Unmarshal([]byte(headers), &m.Headers)
+	}
+	if ts, err := strconv.ParseInt(str(fieldTime), 10, 64); err == nil {
+		m.PublishedAt = time.UnixMilli(ts)
+	}
+	return m
+}
+
+// consumerName 生成消费者名称，同一消费组内唯一
+func consumerName() string {
+	host, _ := os.Hostname()
+	b := make([]byte, 4)
+	_, _ = rand.Read(b)
+	return fmt.Sprintf("%s-%d-%s", host, os.Getpid(), hex.EncodeToString(b))
+}
+
+type subscription struct {
+	topic  string
+	stream string
+	h      mq.Handler
+	o      *mq.SubscribeOption
+
+	// queue 顺序消费时读取与认领的消息都交给同一个协程处理
+	queue chan *mq.Message
+
+	mu       sync.Mutex
+	inflight map[string]struct{} // 本消费者正在处理或等待处理的消息，认领时跳过
+}
+
+// track 记录消息开始处理，消息已在处理中时返回 false
+func (s *subscription) track(id string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.inflight[id]; ok {
+		return false
+	}
+	s.inflight[id] = struct{}{}
+	return true
+}
+
+// done 记录消息处理结束
+func (s *subscription) done(id string) {
+	s.mu.Lock()
+	delete(s.inflight, id)
+	s.mu.Unlock()
+}
+
+// handling 判断消息是否正在由本消费者处理
+func (s *subscription) handling(id string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, ok := s.inflight[id]
+	return ok
+}
+
+type consumer struct {
+	streams cache.Streams
+	delay   *delay.Scheduler
+	group   string
+	name    string
+	o       *option
+	co      *mq.ConsumerOption
+
+	mu      sync.Mutex
+	subs    []*subscription
+	started bool
+	closed  bool
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// Subscribe 订阅主题
+func (c *consumer) Subscribe(topic string, h mq.Handler, opts ...mq.SubscribeOptFn) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return mq.ErrClosed
+	}
+	if c.started {
+		return mq.ErrStarted
+	}
+	c.subs = append(c.subs, &subscription{
+		topic:    topic,
+		stream:   c.o.keyPrefix + topic,
+		h:        c.co.Wrap(c.group, topic, h),
+		o:        mq.NewSubscribeOption(opts...),
+		queue:    make(chan *mq.Message),
+		inflight: make(map[string]struct{}),
+	})
+	return nil
+}
+
+// Start 创建消费组，为每个订阅启动读取与认领协程（顺序消费时还有串行处理协程），并启动延迟消息的调度
+func (c *consumer) Start(ctx context.Context) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return mq.ErrClosed
+	}
+	if c.started {
+		return mq.ErrStarted
+	}
+	for _, sub := range c.subs {
+		if err := c.streams.XGroupCreate(ctx, sub.stream, c.group, "$"); err != nil {
+			return fmt.Errorf("mq: create group %s on %s: %w", c.group, sub.stream, err)
+		}
+	}
+	c.started = true
+
+	ctx, c.cancel = context.WithCancel(context.WithoutCancel(ctx))
+	c.wg.Go(func() { c.delay.Run(ctx) })
+	for _, sub := range c.subs {
+		sem := make(chan struct{}, c.co.Concurrency)
+		c.wg.Go(func() { c.read(ctx, sub, sem) })
+		c.wg.Go(func() { c.reclaim(ctx, sub, sem) })
+		if c.co.Orderly {
+			c.wg.Go(func() { c.serial(ctx, sub) })
+		}
+	}
+	return nil
+}
+
+// serial 顺序消费时串行处理读取与认领的消息
+func (c *consumer) serial(ctx context.Context, sub *subscription) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case m := <-sub.queue:
+			c.handle(ctx, sub, m)
+			sub.done(m.ID)
+		}
+	}
+}
+
+// read 循环读取新消息并处理
+func (c *consumer) read(ctx context.Context, sub *subscription, sem chan struct{}) {
+	for ctx.Err() == nil {
+		msgs, err := c.streams.XReadGroup(ctx, sub.stream, c.group, c.name, int64(c.co.Concurrency), c.o.block)
+		if err != nil && strings.HasPrefix(err.Error(), "NOGROUP") {
+			// 流被删除后消费组随之消失，重新创建
+			err = c.streams.XGroupCreate(ctx, sub.stream, c.group, "$")
+		}
+		if err != nil {
+			c.backoff(ctx, "read", sub, err)
+			continue
+		}
+		for _, sm := range msgs {
+			c.dispatch(ctx, sub, sem, decode(sub.topic, sm, 1))
+		}
+	}
+}
+
+// reclaim 定期认领空闲超过可见性超时的待确认消息，超过最大重试次数的消息确认后丢弃
+// 本消费者正在处理的消息（如顺序消费原地重试中的消息）不认领
+func (c *consumer) reclaim(ctx context.Context, sub *subscription, sem chan struct{}) {
+	ticker := time.NewTicker(max(c.o.visibilityTimeout/2, 10*time.Millisecond))
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		pending, err := c.streams.XPending(ctx, sub.stream, c.group, c.o.visibilityTimeout, 100)
+		if err != nil {
+			c.backoff(ctx, "list pending", sub, err)
+			continue
+		}
+		attempts := make(map[string]int, len(pending))
+		var ids []string
+		for _, p := range pending {
+			if sub.handling(p.ID) {
+				continue
+			}
+			if p.RetryCount > int64(c.co.MaxRetries) {
+				hlog.CtxErrorf(ctx, "[MQ] drop message %s of %s after %d attempts", p.ID, sub.topic, p.RetryCount)
+				c.ack(ctx, sub, p.ID)
+				continue
+			}
+			attempts[p.ID] = int(p.RetryCount) + 1
+			ids = append(ids, p.ID)
+		}
+		if len(ids) == 0 {
+			continue
+		}
+
+		claimed, err := c.streams.XClaim(ctx, sub.stream, c.group, c.name, c.o.visibilityTimeout, ids...)
+		if err != nil {
+			c.backoff(ctx, "claim", sub, err)
+			continue
+		}
+		for _, sm := range claimed {
+			c.dispatch(ctx, sub, sem, decode(sub.topic, sm, attempts[sm.ID]))
+		}
+	}
+}
+
+// dispatch 顺序消费时交给串行处理协程，否则占用一个并发名额后异步处理；已在处理中的消息忽略
+func (c *consumer) dispatch(ctx context.Context, sub *subscription, sem chan struct{}, m *mq.Message) {
+	if !sub.track(m.ID) {
+		return
+	}
+	if c.co.Orderly {
+		select {
+		case sub.queue <- m:
+		case <-ctx.Done():
+			sub.done(m.ID)
+		}
+		return
+	}
+	select {
+	case sem <- struct{}{}:
+	case <-ctx.Done():
+		sub.done(m.ID)
+		return
+	}
+	c.wg.Go(func() {
+		defer func() {
+			sub.done(m.ID)
+			<-sem
+		}()
+		c.handle(ctx, sub, m)
+	})
+}
+
+// handle 处理消息，成功或标签不匹配时确认；并发消费失败时不确认，等待可见性超时后被重新认领，
+// 顺序消费失败时以可见性超时为间隔原地重试。Handler 的 ctx 不随 Close 取消，保证处理中的消息能够完成
+func (c *consumer) handle(ctx context.Context, sub *subscription, m *mq.Message) {
+	if !sub.o.Match(m.Tag) {
+		c.ack(ctx, sub, m.ID)
+		return
+	}
+	for {
+		err := sub.h(context.WithoutCancel(ctx), m)
+		if err == nil {
+			c.ack(ctx, sub, m.ID)
+			return
+		}
+		if m.Attempt > c.co.MaxRetries {
+			hlog.CtxErrorf(ctx, "[MQ] drop message %s of %s after %d attempts: %v", m.ID, m.Topic, m.Attempt, err)
+			c.ack(ctx, sub, m.ID)
+			return
+		}
+		hlog.CtxWarnf(ctx, "[MQ] handle message %s of %s failed, attempt %d: %v", m.ID, m.Topic, m.Attempt, err)
+		if !c.co.Orderly {
+			return
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(c.o.visibilityTimeout):
+		}
+		c.touch(ctx, sub, m.ID)
+		m.Attempt++
+	}
+}
+
+// touch 重新认领自己持有的消息以刷新空闲时间，避免原地重试时被同组的其他消费者认领
+func (c *consumer) touch(ctx context.Context, sub *subscription, id string) {
+	if _, err := c.streams.XClaim(context.WithoutCancel(ctx), sub.stream, c.group, c.name, 0, id); err != nil {
+		hlog.CtxWarnf(ctx, "[MQ] refresh idle time of message %s of %s failed: %v", id, sub.topic, err)
+	}
+}
+
+func (c *consumer) ack(ctx context.Context, sub *subscription, id string) {
+	if err := c.streams.XAck(context.WithoutCancel(ctx), sub.stream, c.group, id); err != nil {
+		hlog.CtxWarnf(ctx, "[MQ] ack message %s of %s failed: %v", id, sub.topic, err)
+	}
+}
+
+// backoff 记录错误并等待 1s，消费者关闭导致的错误不记录
+func (c *consumer) backoff(ctx context.Context, op string, sub *subscription, err error) {
+	if ctx.Err() != nil || errors.Is(err, context.Canceled) {
+		return
+	}
+	hlog.CtxWarnf(ctx, "[MQ] %s %s of group %s failed: %v", op, sub.stream, c.group, err)
+	select {
+	case <-ctx.Done():
+	case <-time.After(time.Second):
+	}
+}
+
+// Close 停止读取并等待处理中的消息完成，未处理的消息留在待确认列表中由其他消费者认领
+func (c *consumer) Close(ctx context.Context) error {
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		return nil
+	}
+	c.closed = true
+	cancel := c.cancel
+	c.mu.Unlock()
+	if cancel == nil {
+		return nil
+	}
+	cancel()
+
+	done := make(chan struct{})
+	go func() {
+		c.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
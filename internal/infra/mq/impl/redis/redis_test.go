@@ -0,0 +1,198 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	goredis "github.com/redis/go-redis/v9"
+
+	cacheredis "github.com/ZampoRen/go-server-comon/internal/infra/cache/impl/redis"
+	"github.com/ZampoRen/go-server-comon/internal/infra/mq"
+)
+
+func newTestQueue(t *testing.T, opts ...Option) *Queue {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	client := cacheredis.NewWithClientOptions(&goredis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = client.(interface{ Close() error }).Close() })
+	q, err := New(client, append([]Option{WithBlock(20 * time.Millisecond)}, opts...)...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return q
+}
+
+// received 记录收到的消息
+type received struct {
+	mu   sync.Mutex
+	msgs []*mq.Message
+}
+
+func (r *received) add(m *mq.Message) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.msgs = append(r.msgs, m)
+}
+
+func (r *received) wait(t *testing.T, n int) []*mq.Message {
+	t.Helper()
+	deadline := time.Now().Add(3 * time.Second)
+	for time.Now().Before(deadline) {
+		r.mu.Lock()
+		if len(r.msgs) >= n {
+			msgs := append([]*mq.Message(nil), r.msgs...)
+			r.mu.Unlock()
+			return msgs
+		}
+		r.mu.Unlock()
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("received fewer than %d messages", n)
+	return nil
+}
+
+func start(t *testing.T, c mq.Consumer, topic string, h mq.Handler, opts ...mq.SubscribeOptFn) {
+	t.Helper()
+	if err := c.Subscribe(topic, h, opts...); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = c.Close(context.Background()) })
+}
+
+// TestQueue 测试消费组、消息字段、延迟投递、失败重新认领与超过重试次数后丢弃
+func TestQueue(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("消费组与消息字段", func(t *testing.T) {
+		q := newTestQueue(t)
+		var g1, g2 received
+		start(t, q.NewConsumer("g1"), "orders", func(_ context.Context, m *mq.Message) error {
+			g1.add(m)
+			return nil
+		})
+		start(t, q.NewConsumer("g2"), "orders", func(_ context.Context, m *mq.Message) error {
+			g2.add(m)
+			return nil
+		}, mq.WithTags("paid"))
+
+		msg := &mq.Message{Topic: "orders", Key: "o-1", Tag: "paid", Body: []byte(`{"id":1}`)}
+		msg.SetHeader("trace", "abc")
+		if err := q.Producer().Send(ctx, msg, &mq.Message{Topic: "orders", Tag: "created"}); err != nil {
+			t.Fatal(err)
+		}
+
+		got := g2.wait(t, 1)[0]
+		if got.Key != "o-1" || string(got.Body) != `{"id":1}` || got.Header("trace") != "abc" || got.Attempt != 1 || got.PublishedAt.IsZero() {
+			t.Fatalf("message = %+v", got)
+		}
+		g1.wait(t, 2)
+	})
+
+	t.Run("延迟投递", func(t *testing.T) {
+		q := newTestQueue(t, WithDelayInterval(10*time.Millisecond))
+		var r received
+		start(t, q.NewConsumer("g"), "timeout", func(_ context.Context, m *mq.Message) error {
+			r.add(m)
+			return nil
+		})
+
+		begin := time.Now()
+		p := q.Producer()
+		for _, key := range []string{"o-1", "o-1"} {
+			if err := p.PublishDelay(ctx, "timeout", &mq.Message{Key: key, Body: []byte("x")}, 100*time.Millisecond); err != nil {
+				t.Fatal(err)
+			}
+		}
+		got := r.wait(t, 2)
+		if elapsed := time.Since(begin); elapsed < 100*time.Millisecond {
+			t.Fatalf("delivered after %v, want >= 100ms", elapsed)
+		}
+		if got[0].Topic != "timeout" || got[0].Key != "o-1" || string(got[0].Body) != "x" {
+			t.Fatalf("message = %+v", got[0])
+		}
+		if n, err := q.delay.Pending(ctx); err != nil || n != 0 {
+			t.Fatalf("pending delayed = %d, %v", n, err)
+		}
+	})
+
+	t.Run("失败后重新认领", func(t *testing.T) {
+		q := newTestQueue(t, WithVisibilityTimeout(50*time.Millisecond))
+		var r received
+		start(t, q.NewConsumer("g"), "orders", func(_ context.Context, m *mq.Message) error {
+			if m.Attempt < 2 {
+				return errors.New("temporary")
+			}
+			r.add(m)
+			return nil
+		})
+		if err := q.Producer().Send(ctx, &mq.Message{Topic: "orders", Body: []byte("x")}); err != nil {
+			t.Fatal(err)
+		}
+		if got := r.wait(t, 1)[0]; got.Attempt != 2 || string(got.Body) != "x" {
+			t.Fatalf("message = %+v", got)
+		}
+	})
+
+	t.Run("超过重试次数后丢弃", func(t *testing.T) {
+		q := newTestQueue(t, WithVisibilityTimeout(20*time.Millisecond))
+		var r received
+		start(t, q.NewConsumer("g", mq.WithMaxRetries(1)), "orders", func(_ context.Context, m *mq.Message) error {
+			r.add(m)
+			return errors.New("permanent")
+		})
+		if err := q.Producer().Send(ctx, &mq.Message{Topic: "orders"}); err != nil {
+			t.Fatal(err)
+		}
+		r.wait(t, 2)
+		time.Sleep(150 * time.Millisecond)
+		if n := len(r.wait(t, 2)); n != 2 {
+			t.Fatalf("handled %d times, want 2", n)
+		}
+		pending, err := q.streams.XPending(ctx, "mq:orders", "g", 0, 10)
+		if err != nil || len(pending) != 0 {
+			t.Fatalf("pending = %v, %v", pending, err)
+		}
+	})
+
+	t.Run("顺序消费原地重试时不被重复认领", func(t *testing.T) {
+		q := newTestQueue(t, WithVisibilityTimeout(30*time.Millisecond))
+		var (
+			r       received
+			active  atomic.Int32
+			overlap atomic.Bool
+		)
+		start(t, q.NewConsumer("g", mq.WithOrderly()), "orders", func(_ context.Context, m *mq.Message) error {
+			if active.Add(1) > 1 {
+				overlap.Store(true)
+			}
+			defer active.Add(-1)
+			time.Sleep(5 * time.Millisecond)
+			r.add(m)
+			if m.Key == "o-1" && m.Attempt < 3 {
+				return errors.New("temporary")
+			}
+			return nil
+		})
+		if err := q.Producer().Send(ctx, &mq.Message{Topic: "orders", Key: "o-1"}, &mq.Message{Topic: "orders", Key: "o-2"}); err != nil {
+			t.Fatal(err)
+		}
+		got := r.wait(t, 4)
+		time.Sleep(100 * time.Millisecond)
+		got = r.wait(t, 4)
+		var keys []string
+		for _, m := range got {
+			keys = append(keys, m.Key)
+		}
+		if overlap.Load() || len(keys) != 4 || keys[2] != "o-1" || keys[3] != "o-2" {
+			t.Fatalf("handled %v, overlap = %v", keys, overlap.Load())
+		}
+	})
+}
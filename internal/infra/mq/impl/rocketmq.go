@@ -0,0 +1,36 @@
+//go:build rocketmq
+
+package impl
+
+import (
+	"context"
+
+	"github.com/ZampoRen/go-server-comon/internal/infra/mq"
+	"github.com/ZampoRen/go-server-comon/internal/infra/mq/impl/rocketmq"
+	"github.com/ZampoRen/go-server-comon/pkg/envkey"
+)
+
+func init() {
+	backends["rocketmq"] = backend{
+		producer: func(context.Context) (mq.Producer, error) {
+			return rocketmq.NewProducer(rocketMQConfig())
+		},
+		consumer: func(_ context.Context, group string, opts ...mq.ConsumerOptFn) (mq.Consumer, error) {
+			return rocketmq.NewConsumer(rocketMQConfig(), group, opts...)
+		},
+	}
+}
+
+// rocketMQConfig 从环境变量读取 RocketMQ 配置
+func rocketMQConfig() *rocketmq.Config {
+	return &rocketmq.Config{
+		NameServers:   envkey.GetStringSliceD("ROCKETMQ_NAME_SERVERS", ",", nil),
+		Namespace:     envkey.GetStringD("ROCKETMQ_NAMESPACE", ""),
+		AccessKey:     envkey.GetStringD("ROCKETMQ_ACCESS_KEY", ""),
+		SecretKey:     envkey.GetStringD("ROCKETMQ_SECRET_KEY", ""),
+		ProducerGroup: envkey.GetStringD("ROCKETMQ_PRODUCER_GROUP", ""),
+		Retry:         envkey.GetIntD("ROCKETMQ_RETRY", 2),
+		DelayClient:   redisClient(),
+		DelayKey:      envkey.GetStringD("ROCKETMQ_DELAY_KEY", ""),
+	}
+}
@@ -0,0 +1,303 @@
+//go:build rocketmq
+
+// Package rocketmq 基于 apache/rocketmq-client-go/v2 的消息队列实现
+//
+// 客户端依赖较重，只在使用 -tags rocketmq 构建时编译，make check 会带该标签执行 vet 与测试
+package rocketmq
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/apache/rocketmq-client-go/v2"
+	"github.com/apache/rocketmq-client-go/v2/consumer"
+	"github.com/apache/rocketmq-client-go/v2/primitive"
+	"github.com/apache/rocketmq-client-go/v2/producer"
+	"github.com/cloudwego/hertz/pkg/common/hlog"
+
+	"github.com/ZampoRen/go-server-comon/internal/infra/cache"
+	"github.com/ZampoRen/go-server-comon/internal/infra/mq"
+	"github.com/ZampoRen/go-server-comon/internal/infra/mq/impl/delay"
+)
+
+// Config RocketMQ 连接配置
+type Config struct {
+	NameServers []string // NameServer 地址
+	Namespace   string   // 命名空间，用于多环境共用集群时隔离主题与消费组
+	AccessKey   string
+	SecretKey   string
+	// ProducerGroup 生产者组，默认 DEFAULT_PRODUCER
+	ProducerGroup string
+	// Retry 发送失败时的重试次数，默认 2
+	Retry int
+	// DelayLevels Broker 的 messageDelayLevel 配置，从级别 1 开始，默认为 RocketMQ 的默认配置
+	// 1s 5s 10s 30s 1m 2m 3m 4m 5m 6m 7m 8m 9m 10m 20m 30m 1h 2h
+	DelayLevels []time.Duration
+	// DelayClient 保存延迟消息的 Redis，延迟不等于任何级别时由 delay.Scheduler 到期后投递，需支持 Lua 脚本
+	// 为空时延迟向上取整到最近的级别，超过最大级别返回 mq.ErrDelayTooLong
+	DelayClient cache.Cmdable
+	// DelayKey 延迟消息有序集合的键，默认 rocketmq:__delay__
+	DelayKey string
+}
+
+// defaultDelayLevels RocketMQ Broker 默认的延迟级别
+var defaultDelayLevels = []time.Duration{
+	time.Second, 5 * time.Second, 10 * time.Second, 30 * time.Second,
+	time.Minute, 2 * time.Minute, 3 * time.Minute, 4 * time.Minute, 5 * time.Minute,
+	6 * time.Minute, 7 * time.Minute, 8 * time.Minute, 9 * time.Minute, 10 * time.Minute,
+	20 * time.Minute, 30 * time.Minute, time.Hour, 2 * time.Hour,
+}
+
+// credentials 配置了 AccessKey 时返回 ACL 凭证
+func (c *Config) credentials() (primitive.Credentials, bool) {
+	return primitive.Credentials{AccessKey: c.AccessKey, SecretKey: c.SecretKey}, c.AccessKey != ""
+}
+
+// NewProducer 创建并启动生产者，带 Key 的消息按 Key 的哈希选择队列，保证相同 Key 的消息有序
+func NewProducer(cfg *Config) (mq.Producer, error) {
+	if len(cfg.NameServers) == 0 {
+		return nil, errors.New("rocketmq: name servers are required")
+	}
+	retry := cfg.Retry
+	if retry <= 0 {
+		retry = 2
+	}
+	opts := []producer.Option{
+		producer.WithNameServer(cfg.NameServers),
+		producer.WithNamespace(cfg.Namespace),
+		producer.WithRetry(retry),
+		producer.WithQueueSelector(producer.NewHashQueueSelector()),
+	}
+	if cfg.ProducerGroup != "" {
+		opts = append(opts, producer.WithGroupName(cfg.ProducerGroup))
+	}
+	if cred, ok := cfg.credentials(); ok {
+		opts = append(opts, producer.WithCredentials(cred))
+	}
+
+	p, err := rocketmq.NewProducer(opts...)
+	if err != nil {
+		return nil, err
+	}
+	if err := p.Start(); err != nil {
+		return nil, err
+	}
+	levels := cfg.DelayLevels
+	if len(levels) == 0 {
+		levels = defaultDelayLevels
+	}
+	rp := &rmqProducer{p: p, delayLevels: levels}
+	if cfg.DelayClient != nil {
+		key := cfg.DelayKey
+		if key == "" {
+			key = "rocketmq:__delay__"
+		}
+		rp.scheduler = delay.New(cfg.DelayClient, rp.Send, delay.WithKey(key))
+		var ctx context.Context
+		ctx, rp.cancel = context.WithCancel(context.Background())
+		rp.wg.Go(func() { rp.scheduler.Run(ctx) })
+	}
+	return rp, nil
+}
+
+type rmqProducer struct {
+	p           rocketmq.Producer
+	delayLevels []time.Duration
+
+	// scheduler 投递无法用延迟级别表达的延迟消息，为空时向上取整到级别
+	scheduler *delay.Scheduler
+	cancel    context.CancelFunc
+	wg        sync.WaitGroup
+}
+
+// Send 逐条同步发送，发送结果非 SendOK 时返回错误
+func (p *rmqProducer) Send(ctx context.Context, msgs ...*mq.Message) error {
+	for _, m := range msgs {
+		if err := p.send(ctx, m, 0); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// PublishDelay delay 恰好等于某个级别时使用 RocketMQ 的延迟级别投递，否则交给 delay.Scheduler 到期后投递
+// 未配置 DelayClient 时 delay 向上取整到最近的级别，超过最大级别时返回 mq.ErrDelayTooLong
+func (p *rmqProducer) PublishDelay(ctx context.Context, topic string, msg *mq.Message, delay time.Duration) error {
+	m := *msg
+	m.Topic = topic
+	if delay <= 0 {
+		return p.send(ctx, &m, 0)
+	}
+	for i, d := range p.delayLevels {
+		if d == delay {
+			return p.send(ctx, &m, i+1)
+		}
+	}
+	if p.scheduler != nil {
+		return p.scheduler.Schedule(ctx, topic, msg, delay)
+	}
+	for i, d := range p.delayLevels {
+		if d > delay {
+			return p.send(ctx, &m, i+1)
+		}
+	}
+	return fmt.Errorf("%w: %v exceeds max delay level %v", mq.ErrDelayTooLong, delay, p.delayLevels[len(p.delayLevels)-1])
+}
+
+// send 同步发送一条消息，delayLevel 为 0 时立即投递
+func (p *rmqProducer) send(ctx context.Context, m *mq.Message, delayLevel int) error {
+	msg := primitive.NewMessage(m.Topic, m.Body)
+	if m.Tag != "" {
+		msg.WithTag(m.Tag)
+	}
+	if m.Key != "" {
+		msg.WithKeys([]string{m.Key})
+		msg.WithShardingKey(m.Key)
+	}
+	// WithProperties 会替换整个属性表并引用调用方的 map，逐个设置以保留标签与 Key
+	for k, v := range m.Headers {
+		msg.WithProperty(k, v)
+	}
+	if delayLevel > 0 {
+		msg.WithDelayTimeLevel(delayLevel)
+	}
+
+	res, err := p.p.SendSync(ctx, msg)
+	if err != nil {
+		return err
+	}
+	if res.Status != primitive.SendOK {
+		return errors.New("rocketmq: send " + m.Topic + " failed: " + res.String())
+	}
+	return nil
+}
+
+// Close 停止延迟消息的调度并关闭生产者，未到期的延迟消息保留在 Redis 中
+func (p *rmqProducer) Close() error {
+	if p.cancel != nil {
+		p.cancel()
+		p.wg.Wait()
+	}
+	return p.p.Shutdown()
+}
+
+// NewConsumer 创建消费组 group 的推模式消费者，集群消费模式
+// 顺序消费时失败的消息会阻塞所在队列，直到重试成功或超过最大重试次数
+func NewConsumer(cfg *Config, group string, opts ...mq.ConsumerOptFn) (mq.Consumer, error) {
+	if len(cfg.NameServers) == 0 {
+		return nil, errors.New("rocketmq: name servers are required")
+	}
+	o := mq.NewConsumerOption(opts...)
+	copts := []consumer.Option{
+		consumer.WithGroupName(group),
+		consumer.WithNameServer(cfg.NameServers),
+		consumer.WithNamespace(cfg.Namespace),
+		consumer.WithConsumerModel(consumer.Clustering),
+		consumer.WithConsumerOrder(o.Orderly),
+		consumer.WithMaxReconsumeTimes(int32(o.MaxRetries)),
+		consumer.WithConsumeGoroutineNums(o.Concurrency),
+	}
+	if cred, ok := cfg.credentials(); ok {
+		copts = append(copts, consumer.WithCredentials(cred))
+	}
+
+	c, err := rocketmq.NewPushConsumer(copts...)
+	if err != nil {
+		return nil, err
+	}
+	return &rmqConsumer{c: c, group: group, o: o}, nil
+}
+
+type rmqConsumer struct {
+	c     rocketmq.PushConsumer
+	group string
+	o     *mq.ConsumerOption
+
+	mu      sync.Mutex
+	started bool
+	closed  bool
+}
+
+// Subscribe 订阅主题，标签过滤由 Broker 完成
+func (c *rmqConsumer) Subscribe(topic string, h mq.Handler, opts ...mq.SubscribeOptFn) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return mq.ErrClosed
+	}
+	if c.started {
+		return mq.ErrStarted
+	}
+
+	o := mq.NewSubscribeOption(opts...)
+	h = c.o.Wrap(c.group, topic, h)
+	selector := consumer.MessageSelector{Type: consumer.TAG, Expression: "*"}
+	if len(o.Tags) > 0 {
+		selector.Expression = strings.Join(o.Tags, " || ")
+	}
+	return c.c.Subscribe(topic, selector, func(ctx context.Context, msgs ...*primitive.MessageExt) (consumer.ConsumeResult, error) {
+		for _, ext := range msgs {
+			m := toMessage(ext)
+			if err := h(ctx, m); err != nil {
+				hlog.CtxWarnf(ctx, "[MQ] handle message %s of %s failed, attempt %d: %v", m.ID, m.Topic, m.Attempt, err)
+				if c.o.Orderly {
+					return consumer.SuspendCurrentQueueAMoment, nil
+				}
+				return consumer.ConsumeRetryLater, nil
+			}
+		}
+		return consumer.ConsumeSuccess, nil
+	})
+}
+
+// toMessage 转换为 mq.Message，Headers 包含发送方设置的属性与 RocketMQ 的系统属性
+func toMessage(ext *primitive.MessageExt) *mq.Message {
+	return &mq.Message{
+		Topic:       ext.Topic,
+		Key:         ext.GetKeys(),
+		Tag:         ext.GetTags(),
+		Body:        ext.Body,
+		Headers:     ext.GetProperties(),
+		ID:          ext.MsgId,
+		Attempt:     int(ext.ReconsumeTimes) + 1,
+		PublishedAt: time.UnixMilli(ext.BornTimestamp),
+	}
+}
+
+// Start 启动消费者
+func (c *rmqConsumer) Start(context.Context) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return mq.ErrClosed
+	}
+	if c.started {
+		return mq.ErrStarted
+	}
+	c.started = true
+	return c.c.Start()
+}
+
+// Close 关闭消费者，客户端的 Shutdown 会等待处理中的消息并持久化消费位点
+func (c *rmqConsumer) Close(ctx context.Context) error {
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		return nil
+	}
+	c.closed = true
+	c.mu.Unlock()
+
+	done := make(chan error, 1)
+	go func() { done <- c.c.Shutdown() }()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,232 @@
+//go:build rocketmq
+
+package rocketmq
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/apache/rocketmq-client-go/v2"
+	"github.com/apache/rocketmq-client-go/v2/consumer"
+	"github.com/apache/rocketmq-client-go/v2/primitive"
+	goredis "github.com/redis/go-redis/v9"
+
+	cacheredis "github.com/ZampoRen/go-server-comon/internal/infra/cache/impl/redis"
+	"github.com/ZampoRen/go-server-comon/internal/infra/mq"
+	"github.com/ZampoRen/go-server-comon/internal/infra/mq/impl/delay"
+)
+
+// stubProducer 记录同步发送的消息
+type stubProducer struct {
+	rocketmq.Producer
+	status primitive.SendStatus
+
+	mu   sync.Mutex
+	sent []*primitive.Message
+}
+
+func (p *stubProducer) SendSync(_ context.Context, msgs ...*primitive.Message) (*primitive.SendResult, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.sent = append(p.sent, msgs...)
+	return &primitive.SendResult{Status: p.status}, nil
+}
+
+func (p *stubProducer) Shutdown() error { return nil }
+
+// messages 返回已发送的消息
+func (p *stubProducer) messages() []*primitive.Message {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]*primitive.Message(nil), p.sent...)
+}
+
+// stubPushConsumer 保存订阅的回调，由测试直接投递消息
+type stubPushConsumer struct {
+	rocketmq.PushConsumer
+	selector consumer.MessageSelector
+	callback func(context.Context, ...*primitive.MessageExt) (consumer.ConsumeResult, error)
+}
+
+func (c *stubPushConsumer) Subscribe(_ string, selector consumer.MessageSelector,
+	f func(context.Context, ...*primitive.MessageExt) (consumer.ConsumeResult, error)) error {
+	c.selector = selector
+	c.callback = f
+	return nil
+}
+
+func (c *stubPushConsumer) Start() error    { return nil }
+func (c *stubPushConsumer) Shutdown() error { return nil }
+
+func TestProducer(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("消息转换", func(t *testing.T) {
+		stub := &stubProducer{status: primitive.SendOK}
+		p := &rmqProducer{p: stub, delayLevels: defaultDelayLevels}
+		msg := &mq.Message{Topic: "orders", Key: "o1", Tag: "created", Body: []byte("hi"), Headers: map[string]string{"trace": "t1"}}
+		if err := p.Send(ctx, msg); err != nil {
+			t.Fatal(err)
+		}
+		got := stub.sent[0]
+		if got.Topic != "orders" || string(got.Body) != "hi" || got.GetTags() != "created" ||
+			got.GetKeys() != "o1" || got.GetShardingKey() != "o1" || got.GetProperty("trace") != "t1" {
+			t.Fatalf("sent message = %+v", got)
+		}
+		if got.GetProperty(primitive.PropertyDelayTimeLevel) != "" {
+			t.Fatalf("immediate message should not have delay level, got %s", got.GetProperty(primitive.PropertyDelayTimeLevel))
+		}
+	})
+
+	t.Run("发送状态非 SendOK 时返回错误", func(t *testing.T) {
+		p := &rmqProducer{p: &stubProducer{status: primitive.SendFlushDiskTimeout}, delayLevels: defaultDelayLevels}
+		if err := p.Send(ctx, &mq.Message{Topic: "orders"}); err == nil {
+			t.Fatal("Send() should fail")
+		}
+	})
+
+	t.Run("未配置 DelayClient 时延迟向上取整到级别", func(t *testing.T) {
+		stub := &stubProducer{status: primitive.SendOK}
+		p := &rmqProducer{p: stub, delayLevels: defaultDelayLevels}
+		if err := p.PublishDelay(ctx, "orders", &mq.Message{Body: []byte("x")}, 20*time.Second); err != nil {
+			t.Fatal(err)
+		}
+		got := stub.sent[0]
+		if got.Topic != "orders" || got.GetProperty(primitive.PropertyDelayTimeLevel) != "4" {
+			t.Fatalf("delay level = %s, want 4 (30s)", got.GetProperty(primitive.PropertyDelayTimeLevel))
+		}
+	})
+
+	t.Run("未配置 DelayClient 时超过最大延迟级别", func(t *testing.T) {
+		p := &rmqProducer{p: &stubProducer{status: primitive.SendOK}, delayLevels: defaultDelayLevels}
+		if err := p.PublishDelay(ctx, "orders", &mq.Message{}, 3*time.Hour); !errors.Is(err, mq.ErrDelayTooLong) {
+			t.Fatalf("PublishDelay() error = %v, want ErrDelayTooLong", err)
+		}
+	})
+}
+
+func TestConsumer(t *testing.T) {
+	ctx := context.Background()
+	ext := &primitive.MessageExt{
+		Message:        primitive.Message{Topic: "orders", Body: []byte("hi")},
+		MsgId:          "m1",
+		ReconsumeTimes: 2,
+		BornTimestamp:  time.Now().UnixMilli(),
+	}
+	ext.WithTag("created")
+	ext.WithKeys([]string{"o1"})
+
+	newConsumer := func(opts ...mq.ConsumerOptFn) (*rmqConsumer, *stubPushConsumer) {
+		stub := &stubPushConsumer{}
+		return &rmqConsumer{c: stub, group: "g", o: mq.NewConsumerOption(opts...)}, stub
+	}
+
+	t.Run("标签过滤与消息转换", func(t *testing.T) {
+		c, stub := newConsumer()
+		var got *mq.Message
+		err := c.Subscribe("orders", func(_ context.Context, m *mq.Message) error {
+			got = m
+			return nil
+		}, mq.WithTags("created", "paid"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if stub.selector.Type != consumer.TAG || stub.selector.Expression != "created || paid" {
+			t.Fatalf("selector = %+v", stub.selector)
+		}
+		if res, _ := stub.callback(ctx, ext); res != consumer.ConsumeSuccess {
+			t.Fatalf("consume result = %v, want ConsumeSuccess", res)
+		}
+		if got.ID != "m1" || got.Key != "o1" || got.Tag != "created" || got.Attempt != 3 || string(got.Body) != "hi" {
+			t.Fatalf("message = %+v", got)
+		}
+	})
+
+	t.Run("处理失败时稍后重试", func(t *testing.T) {
+		c, stub := newConsumer()
+		_ = c.Subscribe("orders", func(context.Context, *mq.Message) error { return errors.New("boom") })
+		if res, _ := stub.callback(ctx, ext); res != consumer.ConsumeRetryLater {
+			t.Fatalf("consume result = %v, want ConsumeRetryLater", res)
+		}
+	})
+
+	t.Run("顺序消费失败时挂起队列", func(t *testing.T) {
+		c, stub := newConsumer(mq.WithOrderly())
+		_ = c.Subscribe("orders", func(context.Context, *mq.Message) error { return errors.New("boom") })
+		if res, _ := stub.callback(ctx, ext); res != consumer.SuspendCurrentQueueAMoment {
+			t.Fatalf("consume result = %v, want SuspendCurrentQueueAMoment", res)
+		}
+	})
+
+	t.Run("启动后不能订阅", func(t *testing.T) {
+		c, _ := newConsumer()
+		if err := c.Start(ctx); err != nil {
+			t.Fatal(err)
+		}
+		if err := c.Subscribe("orders", func(context.Context, *mq.Message) error { return nil }); !errors.Is(err, mq.ErrStarted) {
+			t.Fatalf("Subscribe() error = %v, want ErrStarted", err)
+		}
+		if err := c.Close(ctx); err != nil {
+			t.Fatal(err)
+		}
+	})
+}
+
+func TestPublishDelayScheduler(t *testing.T) {
+	ctx := context.Background()
+	mr := miniredis.RunT(t)
+	client := cacheredis.NewWithClientOptions(&goredis.Options{Addr: mr.Addr()})
+	stub := &stubProducer{status: primitive.SendOK}
+	p := &rmqProducer{p: stub, delayLevels: defaultDelayLevels}
+	p.scheduler = delay.New(client, p.Send, delay.WithKey("rocketmq:__delay__"), delay.WithInterval(10*time.Millisecond))
+	runCtx, cancel := context.WithCancel(ctx)
+	p.cancel = cancel
+	p.wg.Go(func() { p.scheduler.Run(runCtx) })
+	defer p.Close()
+
+	t.Run("恰好等于级别时使用 Broker 延迟", func(t *testing.T) {
+		if err := p.PublishDelay(ctx, "orders", &mq.Message{}, time.Minute); err != nil {
+			t.Fatal(err)
+		}
+		sent := stub.messages()
+		if len(sent) != 1 || sent[0].GetProperty(primitive.PropertyDelayTimeLevel) != "5" {
+			t.Fatalf("sent = %v, want one message with delay level 5 (1m)", sent)
+		}
+	})
+
+	t.Run("不等于级别或超过最大级别时由调度器投递", func(t *testing.T) {
+		for _, d := range []time.Duration{61 * time.Minute, 3 * time.Hour} {
+			if err := p.PublishDelay(ctx, "orders", &mq.Message{}, d); err != nil {
+				t.Fatalf("PublishDelay(%v) error = %v", d, err)
+			}
+		}
+		if n, err := p.scheduler.Pending(ctx); err != nil || n != 2 {
+			t.Fatalf("Pending() = %d, %v, want 2", n, err)
+		}
+		if n := len(stub.messages()); n != 1 {
+			t.Fatalf("sent %d messages, want 1", n)
+		}
+	})
+
+	t.Run("到期后由调度器发送", func(t *testing.T) {
+		if err := p.PublishDelay(ctx, "orders", &mq.Message{Key: "o1", Body: []byte("due")}, 50*time.Millisecond); err != nil {
+			t.Fatal(err)
+		}
+		deadline := time.Now().Add(3 * time.Second)
+		for time.Now().Before(deadline) {
+			if sent := stub.messages(); len(sent) == 2 {
+				if got := sent[1]; got.Topic != "orders" || string(got.Body) != "due" || got.GetKeys() != "o1" ||
+					got.GetProperty(primitive.PropertyDelayTimeLevel) != "" {
+					t.Fatalf("delayed message = %v", got)
+				}
+				return
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+		t.Fatal("delayed message not sent")
+	})
+}
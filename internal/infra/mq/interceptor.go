@@ -0,0 +1,39 @@
+package mq
+
+import "context"
+
+// HandlerInfo 消息处理的上下文信息，供拦截器使用
+type HandlerInfo struct {
+	Group string // 消费组
+	Topic string // 订阅的主题
+	// MaxRetries 最大重试次数，Message.Attempt > MaxRetries 时为最后一次投递
+	MaxRetries int
+}
+
+// Interceptor 消费拦截器，形式与 gRPC 服务端拦截器相同，调用 handler 继续处理
+type Interceptor func(ctx context.Context, msg *Message, info *HandlerInfo, handler Handler) error
+
+// ChainInterceptors 将多个拦截器组合为一个，第一个位于最外层
+func ChainInterceptors(interceptors ...Interceptor) Interceptor {
+	return func(ctx context.Context, msg *Message, info *HandlerInfo, handler Handler) error {
+		return wrap(handler, info, interceptors)(ctx, msg)
+	}
+}
+
+// Wrap 用消费者的拦截器包裹订阅 topic 的 Handler，由各实现在 Subscribe 时调用
+func (o *ConsumerOption) Wrap(group, topic string, h Handler) Handler {
+	if len(o.Interceptors) == 0 {
+		return h
+	}
+	return wrap(h, &HandlerInfo{Group: group, Topic: topic, MaxRetries: o.MaxRetries}, o.Interceptors)
+}
+
+func wrap(h Handler, info *HandlerInfo, interceptors []Interceptor) Handler {
+	for i := len(interceptors) - 1; i >= 0; i-- {
+		interceptor, next := interceptors[i], h
+		h = func(ctx context.Context, msg *Message) error {
+			return interceptor(ctx, msg, info, next)
+		}
+	}
+	return h
+}
@@ -0,0 +1,83 @@
+// Package mq 消息队列抽象，业务代码只依赖本包的接口，具体实现通过 impl.NewProducer、impl.NewConsumer 按 MQ_TYPE 选择
+//
+//	producer, _ := impl.NewProducer(ctx)
+//	err := producer.Send(ctx, &mq.Message{Topic: "user-events", Key: "42", Tag: "created", Body: body})
+//	err = producer.PublishDelay(ctx, "order-timeout", &mq.Message{Key: orderID}, 30*time.Minute)
+//
+//	consumer, _ := impl.NewConsumer(ctx, "search-indexer", mq.WithOrderly(), mq.WithInterceptors(mqmw.Tracing(), mqmw.Logging()))
+//	_ = consumer.Subscribe("user-events", handle, mq.WithTags("created", "updated"))
+//	_ = consumer.Start(ctx)
+//	defer consumer.Close(ctx)
+//
+// 投递语义为至少一次，Handler 需要幂等
+package mq
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var (
+	// ErrClosed 生产者或消费者已关闭
+	ErrClosed = errors.New("mq: closed")
+	// ErrStarted 消费者已启动，不能再订阅
+	ErrStarted = errors.New("mq: consumer already started")
+	// ErrDelayTooLong 延迟超过消息队列支持的最大值
+	ErrDelayTooLong = errors.New("mq: delay too long")
+)
+
+// Message 消息
+type Message struct {
+	Topic string
+	// Key 业务键，顺序消费时相同 Key 的消息按发送顺序处理，也用于按键查询与去重
+	Key string
+	// Tag 标签，消费方可通过 WithTags 过滤
+	Tag     string
+	Body    []byte
+	Headers map[string]string
+
+	// 以下字段只在消费时有效
+	// ID 由消息队列分配的消息 ID
+	ID string
+	// Attempt 投递次数，从 1 开始，重试时递增
+	Attempt int
+	// PublishedAt 消息的发送时间
+	PublishedAt time.Time
+}
+
+// Header 获取消息头
+func (m *Message) Header(key string) string {
+	return m.Headers[key]
+}
+
+// SetHeader 设置消息头
+func (m *Message) SetHeader(key, value string) {
+	if m.Headers == nil {
+		m.Headers = make(map[string]string)
+	}
+	m.Headers[key] = value
+}
+
+// Producer 生产者，并发安全
+type Producer interface {
+	// Send 同步发送消息，返回 nil 时消息已被消息队列接收
+	Send(ctx context.Context, msgs ...*Message) error
+	// PublishDelay 将消息发送到 topic 并在 delay 之后才投递给消费者，delay <= 0 时等同于 Send
+	// 消息的 Topic 字段被忽略，PublishedAt 为实际投递的时间
+	PublishDelay(ctx context.Context, topic string, msg *Message, delay time.Duration) error
+	Close() error
+}
+
+// Handler 消息处理函数，返回错误时消息稍后重新投递，超过最大重试次数后丢弃
+type Handler func(ctx context.Context, msg *Message) error
+
+// Consumer 消费者，同一消费组的多个消费者分摊消息，不同消费组各自收到全部消息
+type Consumer interface {
+	// Subscribe 订阅主题，须在 Start 之前调用
+	Subscribe(topic string, h Handler, opts ...SubscribeOptFn) error
+	// Start 开始消费
+	Start(ctx context.Context) error
+	// Close 停止拉取新消息并等待处理中的消息完成，ctx 结束时不再等待
+	Close(ctx context.Context) error
+}
@@ -0,0 +1,85 @@
+package mq
+
+import "slices"
+
+// ConsumerOptFn 消费者选项函数
+type ConsumerOptFn func(o *ConsumerOption)
+
+// ConsumerOption 消费者选项，各实现按自身能力映射
+type ConsumerOption struct {
+	Orderly     bool // 顺序消费，相同 Key 的消息串行处理，处理失败时阻塞后续消息直到重试成功或超过重试次数
+	MaxRetries  int  // 处理失败后的最大重试次数
+	Concurrency int  // 每个订阅的并发处理数，顺序消费时为每个队列串行
+	// Interceptors 消费拦截器，按顺序包裹每个订阅的 Handler
+	Interceptors []Interceptor
+}
+
+// NewConsumerOption 返回应用了 opts 的消费者选项，默认最多重试 16 次、并发 20
+func NewConsumerOption(opts ...ConsumerOptFn) *ConsumerOption {
+	o := &ConsumerOption{MaxRetries: 16, Concurrency: 20}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// WithOrderly 开启顺序消费
+func WithOrderly() ConsumerOptFn {
+	return func(o *ConsumerOption) {
+		o.Orderly = true
+	}
+}
+
+// WithMaxRetries 设置最大重试次数，0 表示不重试
+func WithMaxRetries(n int) ConsumerOptFn {
+	return func(o *ConsumerOption) {
+		if n >= 0 {
+			o.MaxRetries = n
+		}
+	}
+}
+
+// WithConcurrency 设置每个订阅的并发处理数
+func WithConcurrency(n int) ConsumerOptFn {
+	return func(o *ConsumerOption) {
+		if n > 0 {
+			o.Concurrency = n
+		}
+	}
+}
+
+// WithInterceptors 添加消费拦截器，第一个位于最外层，多次调用时依次追加
+func WithInterceptors(interceptors ...Interceptor) ConsumerOptFn {
+	return func(o *ConsumerOption) {
+		o.Interceptors = append(o.Interceptors, interceptors...)
+	}
+}
+
+// SubscribeOptFn 订阅选项函数
+type SubscribeOptFn func(o *SubscribeOption)
+
+// SubscribeOption 订阅选项
+type SubscribeOption struct {
+	Tags []string // 只接收这些标签的消息，为空时接收全部
+}
+
+// NewSubscribeOption 返回应用了 opts 的订阅选项
+func NewSubscribeOption(opts ...SubscribeOptFn) *SubscribeOption {
+	o := &SubscribeOption{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// WithTags 按标签过滤，RocketMQ 在服务端过滤，其他实现在客户端过滤
+func WithTags(tags ...string) SubscribeOptFn {
+	return func(o *SubscribeOption) {
+		o.Tags = append(o.Tags, tags...)
+	}
+}
+
+// Match 判断标签是否满足过滤条件
+func (o *SubscribeOption) Match(tag string) bool {
+	return len(o.Tags) == 0 || slices.Contains(o.Tags, tag)
+}
@@ -0,0 +1,446 @@
+// Package audit 提供记录数据变更的 GORM 插件
+//
+//	db.Use(audit.New(audit.SinkFunc(func(ctx context.Context, events []*audit.Event) {
+//		for _, e := range events {
+//			hlog.CtxInfof(ctx, "[Audit] %s %s %v by %s: %v", e.Action, e.Table, e.PrimaryKey, e.Actor, e.Changes)
+//		}
+//	}), audit.WithTables("users", "orders")))
+//
+//	ctx = audit.WithActor(ctx, "admin:1001")
+//	db.WithContext(ctx).Model(&user).Update("status", 2)
+//
+// 开销：更新与删除前会按相同条件查询受影响的行（删除多一次查询），更新后再按主键查询新值（更新多两次查询），
+// 创建不额外查询，只应对需要审计的表开启。
+//
+// 使用序列化器的字段（如 crypto 加密字段、JSON 字段）不记录具体值，非空时记为 Masked，
+// 避免敏感信息以明文或密文的形式进入审计日志。
+//
+// 事务：通过 txmanager 开启的事务中，事件缓存到最外层事务提交后发出，回滚时丢弃；
+// Sink 实现 TxSink 时改为在语句所在的连接（包括事务）中同步写入，写入失败时语句返回错误，
+// 审计记录与业务数据一起提交或回滚，适用于要求审计不可丢失的场景。
+// 直接使用 db.Transaction 开启的事务无法感知提交结果，事件在语句执行后立即发出。
+package audit
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/common/hlog"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/ZampoRen/go-server-comon/internal/infra/orm/txmanager"
+)
+
+// Action 变更类型
+type Action string
+
+const (
+	ActionCreate Action = "create"
+	ActionUpdate Action = "update"
+	ActionDelete Action = "delete"
+)
+
+// Masked 使用序列化器的字段在事件中的取值
+const Masked = "***"
+
+// Change 单个字段的变更，创建时 Old 为 nil，删除时 New 为 nil
+type Change struct {
+	Old interface{} `json:"old"`
+	New interface{} `json:"new"`
+}
+
+// Event 审计事件，对应一行数据的变更
+type Event struct {
+	Table      string            `json:"table"`
+	Action     Action            `json:"action"`
+	PrimaryKey interface{}       `json:"primary_key"`
+	Changes    map[string]Change `json:"changes"`
+	Actor      string            `json:"actor,omitempty"`
+	Time       time.Time         `json:"time"`
+}
+
+// Sink 审计事件接收器，在写操作（或所在事务提交）的协程中同步调用，耗时操作应自行异步处理
+type Sink interface {
+	Emit(ctx context.Context, events []*Event)
+}
+
+// TxSink 在写操作所在的连接中写入审计事件的 Sink，tx 与业务语句共用事务，
+// 返回错误时业务语句失败，所在事务随之回滚
+type TxSink interface {
+	Sink
+	EmitTx(tx *gorm.DB, events []*Event) error
+}
+
+// SinkFunc 函数形式的 Sink
+type SinkFunc func(ctx context.Context, events []*Event)
+
+// Emit 实现 Sink
+func (f SinkFunc) Emit(ctx context.Context, events []*Event) {
+	f(ctx, events)
+}
+
+type actorKey struct{}
+
+// WithActor 在 ctx 中记录操作人
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// ActorFromContext 返回 ctx 中记录的操作人
+func ActorFromContext(ctx context.Context) string {
+	actor, _ := ctx.Value(actorKey{}).(string)
+	return actor
+}
+
+const oldRowsKey = "audit:old_rows"
+
+type option struct {
+	tables  map[string]struct{}
+	ignore  map[string]struct{}
+	actorFn func(ctx context.Context) string
+	maxRows int
+	clock   func() time.Time
+}
+
+// Option 插件选项
+type Option func(o *option)
+
+// WithTables 只审计指定的表，默认审计所有表
+func WithTables(tables ...string) Option {
+	return func(o *option) {
+		o.tables = make(map[string]struct{}, len(tables))
+		for _, t := range tables {
+			o.tables[t] = struct{}{}
+		}
+	}
+}
+
+// WithIgnoreColumns 忽略指定列的变更，如 updated_at
+func WithIgnoreColumns(columns ...string) Option {
+	return func(o *option) {
+		for _, c := range columns {
+			o.ignore[c] = struct{}{}
+		}
+	}
+}
+
+// WithActorFunc 设置从 ctx 获取操作人的函数，默认使用 ActorFromContext
+func WithActorFunc(fn func(ctx context.Context) string) Option {
+	return func(o *option) {
+		if fn != nil {
+			o.actorFn = fn
+		}
+	}
+}
+
+// WithMaxRows 设置单条语句最多审计的行数，超出部分不记录，默认 1000
+func WithMaxRows(n int) Option {
+	return func(o *option) {
+		if n > 0 {
+			o.maxRows = n
+		}
+	}
+}
+
+type plugin struct {
+	sink Sink
+	opt  *option
+}
+
+// New 创建审计插件
+func New(sink Sink, opts ...Option) gorm.Plugin {
+	o := &option{
+		ignore:  make(map[string]struct{}),
+		actorFn: ActorFromContext,
+		maxRows: 1000,
+		clock:   time.Now,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return &plugin{sink: sink, opt: o}
+}
+
+// Name 实现 gorm.Plugin
+func (p *plugin) Name() string {
+	return "orm:audit"
+}
+
+// Initialize 实现 gorm.Plugin
+func (p *plugin) Initialize(db *gorm.DB) error {
+	cb := db.Callback()
+	for _, err := range []error{
+		cb.Create().After("gorm:create").Register("orm:audit_after", p.afterCreate),
+		cb.Update().Before("gorm:update").Register("orm:audit_before", p.loadOld),
+		cb.Update().After("gorm:update").Register("orm:audit_after", p.afterUpdate),
+		cb.Delete().Before("gorm:delete").Register("orm:audit_before", p.loadOld),
+		cb.Delete().After("gorm:delete").Register("orm:audit_after", p.afterDelete),
+	} {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// enabled 判断当前语句是否需要审计
+func (p *plugin) enabled(db *gorm.DB) bool {
+	if db.Statement.Schema == nil || db.Statement.Schema.PrioritizedPrimaryField == nil {
+		return false
+	}
+	if p.opt.tables == nil {
+		return true
+	}
+	_, ok := p.opt.tables[db.Statement.Table]
+	return ok
+}
+
+func (p *plugin) afterCreate(db *gorm.DB) {
+	if db.Error != nil || !p.enabled(db) {
+		return
+	}
+
+	stmt := db.Statement
+	var events []*Event
+	eachStruct(stmt.ReflectValue, func(rv reflect.Value) {
+		changes := make(map[string]Change)
+		for _, field := range stmt.Schema.Fields {
+			if field.DBName == "" || p.ignored(field.DBName) {
+				continue
+			}
+			if v, zero := field.ValueOf(stmt.Context, rv); !zero {
+				if field.Serializer != nil {
+					v = Masked
+				}
+				changes[field.DBName] = Change{New: v}
+			}
+		}
+		pk, _ := stmt.Schema.PrioritizedPrimaryField.ValueOf(stmt.Context, rv)
+		events = append(events, p.event(db, ActionCreate, pk, changes))
+	})
+	p.emit(db, events)
+}
+
+// loadOld 按语句条件查询变更前的行
+func (p *plugin) loadOld(db *gorm.DB) {
+	if db.Error != nil || !p.enabled(db) {
+		return
+	}
+	query, ok := p.scope(db)
+	if !ok {
+		return
+	}
+	rows, err := p.queryRows(db, query)
+	if err != nil {
+		hlog.CtxWarnf(db.Statement.Context, "[Audit] load rows of %s failed: %v", db.Statement.Table, err)
+		return
+	}
+	db.InstanceSet(oldRowsKey, rows)
+}
+
+func (p *plugin) afterUpdate(db *gorm.DB) {
+	old, ok := p.oldRows(db)
+	if !ok || len(old) == 0 {
+		return
+	}
+
+	pkName := db.Statement.Schema.PrioritizedPrimaryField.DBName
+	pks := make([]interface{}, 0, len(old))
+	for _, row := range old {
+		pks = append(pks, row[pkName])
+	}
+	query := p.session(db).Where(clause.IN{Column: clause.PrimaryColumn, Values: pks})
+	current, err := p.queryRows(db, query)
+	if err != nil {
+		hlog.CtxWarnf(db.Statement.Context, "[Audit] load updated rows of %s failed: %v", db.Statement.Table, err)
+		return
+	}
+	newByPK := make(map[interface{}]map[string]interface{}, len(current))
+	for _, row := range current {
+		newByPK[row[pkName]] = row
+	}
+
+	var events []*Event
+	for _, before := range old {
+		after, ok := newByPK[before[pkName]]
+		if !ok {
+			continue
+		}
+		changes := make(map[string]Change)
+		for col, v := range after {
+			if p.ignored(col) {
+				continue
+			}
+			if !reflect.DeepEqual(before[col], v) {
+				changes[col] = Change{Old: mask(db, col, before[col]), New: mask(db, col, v)}
+			}
+		}
+		if len(changes) > 0 {
+			events = append(events, p.event(db, ActionUpdate, before[pkName], changes))
+		}
+	}
+	p.emit(db, events)
+}
+
+func (p *plugin) afterDelete(db *gorm.DB) {
+	old, ok := p.oldRows(db)
+	if !ok {
+		return
+	}
+
+	pkName := db.Statement.Schema.PrioritizedPrimaryField.DBName
+	events := make([]*Event, 0, len(old))
+	for _, row := range old {
+		changes := make(map[string]Change, len(row))
+		for col, v := range row {
+			if !p.ignored(col) {
+				changes[col] = Change{Old: mask(db, col, v)}
+			}
+		}
+		events = append(events, p.event(db, ActionDelete, row[pkName], changes))
+	}
+	p.emit(db, events)
+}
+
+func (p *plugin) oldRows(db *gorm.DB) ([]map[string]interface{}, bool) {
+	if db.Error != nil || !p.enabled(db) {
+		return nil, false
+	}
+	v, ok := db.InstanceGet(oldRowsKey)
+	if !ok {
+		return nil, false
+	}
+	rows, ok := v.([]map[string]interface{})
+	return rows, ok
+}
+
+// session 返回与当前语句共用连接（包括事务）的新查询
+func (p *plugin) session(db *gorm.DB) *gorm.DB {
+	model := reflect.New(db.Statement.Schema.ModelType).Interface()
+	return db.Session(&gorm.Session{NewDB: true}).Model(model).Table(db.Statement.Table)
+}
+
+// scope 复用当前语句的 WHERE 条件，模型实例带主键时追加主键条件
+// 既没有条件也没有主键时返回 false（GORM 默认会拒绝这类全表操作）
+func (p *plugin) scope(db *gorm.DB) (*gorm.DB, bool) {
+	stmt := db.Statement
+	query := p.session(db)
+	scoped := false
+
+	if c, ok := stmt.Clauses["WHERE"]; ok {
+		if where, ok := c.Expression.(clause.Where); ok && len(where.Exprs) > 0 {
+			query = query.Clauses(where)
+			scoped = true
+		}
+	}
+	if stmt.ReflectValue.Kind() == reflect.Struct {
+		if pk, zero := stmt.Schema.PrioritizedPrimaryField.ValueOf(stmt.Context, stmt.ReflectValue); !zero {
+			query = query.Where(clause.Eq{Column: clause.PrimaryColumn, Value: pk})
+			scoped = true
+		}
+	}
+	return query, scoped || stmt.AllowGlobalUpdate
+}
+
+// queryRows 查询最多 maxRows 行，以列名到值的映射返回
+func (p *plugin) queryRows(db *gorm.DB, query *gorm.DB) ([]map[string]interface{}, error) {
+	rows, err := query.Limit(p.opt.maxRows).Rows()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanRows(rows)
+}
+
+// mask 使用序列化器的列不记录查询到的原始值，非空时返回 Masked
+func mask(db *gorm.DB, column string, v interface{}) interface{} {
+	if v == nil {
+		return nil
+	}
+	if field := db.Statement.Schema.LookUpField(column); field != nil && field.Serializer != nil {
+		return Masked
+	}
+	return v
+}
+
+func (p *plugin) ignored(column string) bool {
+	_, ok := p.opt.ignore[column]
+	return ok
+}
+
+func (p *plugin) event(db *gorm.DB, action Action, pk interface{}, changes map[string]Change) *Event {
+	return &Event{
+		Table:      db.Statement.Table,
+		Action:     action,
+		PrimaryKey: pk,
+		Changes:    changes,
+		Actor:      p.opt.actorFn(db.Statement.Context),
+		Time:       p.opt.clock(),
+	}
+}
+
+// emit 发出事件：TxSink 在当前连接中同步写入，否则等 txmanager 事务提交后发出
+func (p *plugin) emit(db *gorm.DB, events []*Event) {
+	if len(events) == 0 {
+		return
+	}
+	if ts, ok := p.sink.(TxSink); ok {
+		if err := ts.EmitTx(db.Session(&gorm.Session{NewDB: true}), events); err != nil {
+			_ = db.AddError(fmt.Errorf("emit audit events of %s failed: %w", db.Statement.Table, err))
+		}
+		return
+	}
+	txmanager.AfterCommit(db.Statement.Context, func(ctx context.Context) {
+		p.sink.Emit(ctx, events)
+	})
+}
+
+// eachStruct 遍历单个结构体或结构体（指针）切片
+func eachStruct(rv reflect.Value, fn func(rv reflect.Value)) {
+	rv = reflect.Indirect(rv)
+	switch rv.Kind() {
+	case reflect.Struct:
+		fn(rv)
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < rv.Len(); i++ {
+			if elem := reflect.Indirect(rv.Index(i)); elem.Kind() == reflect.Struct {
+				fn(elem)
+			}
+		}
+	}
+}
+
+// scanRows 将结果集读取为列名到值的映射，[]byte 转换为 string 便于比较与序列化
+func scanRows(rows *sql.Rows) ([]map[string]interface{}, error) {
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+
+	var result []map[string]interface{}
+	for rows.Next() {
+		values := make([]interface{}, len(columns))
+		ptrs := make([]interface{}, len(columns))
+		for i := range values {
+			ptrs[i] = &values[i]
+		}
+		if err := rows.Scan(ptrs...); err != nil {
+			return nil, fmt.Errorf("scan audit row: %w", err)
+		}
+
+		row := make(map[string]interface{}, len(columns))
+		for i, column := range columns {
+			if b, ok := values[i].([]byte); ok {
+				row[column] = string(b)
+			} else {
+				row[column] = values[i]
+			}
+		}
+		result = append(result, row)
+	}
+	return result, rows.Err()
+}
@@ -0,0 +1,214 @@
+package audit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+
+	"github.com/ZampoRen/go-server-comon/internal/infra/orm/crypto"
+	"github.com/ZampoRen/go-server-comon/internal/infra/orm/impl/sqlite"
+	"github.com/ZampoRen/go-server-comon/internal/infra/orm/txmanager"
+)
+
+type testUser struct {
+	ID     int64
+	Name   string
+	Status int
+}
+
+// TestPlugin 测试创建、更新、删除的审计事件
+func TestPlugin(t *testing.T) {
+	db, err := sqlite.NewWithConfig("", &gorm.Config{Logger: gormlogger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&testUser{}); err != nil {
+		t.Fatal(err)
+	}
+
+	var events []*Event
+	sink := SinkFunc(func(ctx context.Context, es []*Event) { events = append(events, es...) })
+	if err := db.Use(New(sink, WithTables("test_users"))); err != nil {
+		t.Fatalf("Use() error = %v", err)
+	}
+
+	ctx := WithActor(context.Background(), "admin")
+	tx := db.WithContext(ctx)
+	a, b := &testUser{Name: "a"}, &testUser{Name: "b"}
+	tx.Create([]*testUser{a, b})
+	tx.Model(a).Update("status", 2)
+	tx.Model(&testUser{}).Where("name = ?", "b").Updates(map[string]any{"name": "b"})
+	tx.Delete(&testUser{}, b.ID)
+
+	want := []struct {
+		action  Action
+		pk      interface{}
+		changes map[string]Change
+	}{
+		{ActionCreate, a.ID, map[string]Change{"id": {New: a.ID}, "name": {New: "a"}}},
+		{ActionCreate, b.ID, map[string]Change{"id": {New: b.ID}, "name": {New: "b"}}},
+		{ActionUpdate, a.ID, map[string]Change{"status": {Old: int64(0), New: int64(2)}}},
+		{ActionDelete, b.ID, map[string]Change{"id": {Old: b.ID}, "name": {Old: "b"}, "status": {Old: int64(0)}}},
+	}
+	if len(events) != len(want) {
+		t.Fatalf("got %d events, want %d: %+v", len(events), len(want), events)
+	}
+	for i, w := range want {
+		e := events[i]
+		if e.Action != w.action || e.PrimaryKey != w.pk || e.Actor != "admin" || e.Table != "test_users" {
+			t.Errorf("event[%d] = %+v, want %s of %v", i, e, w.action, w.pk)
+		}
+		if len(e.Changes) != len(w.changes) {
+			t.Errorf("event[%d].Changes = %v, want %v", i, e.Changes, w.changes)
+			continue
+		}
+		for col, c := range w.changes {
+			if e.Changes[col] != c {
+				t.Errorf("event[%d].Changes[%s] = %v, want %v", i, col, e.Changes[col], c)
+			}
+		}
+	}
+}
+
+type secretUser struct {
+	ID    int64
+	Phone string `gorm:"serializer:encrypt"`
+}
+
+// TestSerializerField 测试加密字段在各类事件中统一记为 Masked
+func TestSerializerField(t *testing.T) {
+	keyring, err := crypto.NewKeyring("k1", map[string][]byte{"k1": bytes.Repeat([]byte{1}, 32)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	crypto.Register(keyring)
+
+	db, err := sqlite.NewWithConfig("", &gorm.Config{Logger: gormlogger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&secretUser{}); err != nil {
+		t.Fatal(err)
+	}
+	var events []*Event
+	sink := SinkFunc(func(ctx context.Context, es []*Event) { events = append(events, es...) })
+	if err := db.Use(New(sink)); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Use(crypto.NewPlugin()); err != nil {
+		t.Fatal(err)
+	}
+
+	u := &secretUser{Phone: "13800000000"}
+	db.Create(u)
+	db.Model(u).Update("phone", "13900000000")
+	db.Delete(u)
+
+	want := []Change{{New: Masked}, {Old: Masked, New: Masked}, {Old: Masked}}
+	if len(events) != len(want) {
+		t.Fatalf("got %d events, want %d: %+v", len(events), len(want), events)
+	}
+	for i, w := range want {
+		if got := events[i].Changes["phone"]; got != w {
+			t.Errorf("event[%d] %s phone = %v, want %v", i, events[i].Action, got, w)
+		}
+	}
+}
+
+type auditLog struct {
+	ID      int64
+	Payload string
+}
+
+// tableSink 将事件写入 audit_logs 表
+type tableSink struct{}
+
+func (tableSink) Emit(ctx context.Context, events []*Event) {}
+
+func (tableSink) EmitTx(tx *gorm.DB, events []*Event) error {
+	for _, e := range events {
+		payload, err := json.Marshal(e)
+		if err != nil {
+			return err
+		}
+		if err := tx.Create(&auditLog{Payload: string(payload)}).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// TestTransaction 测试事务中的审计事件随事务提交或回滚
+func TestTransaction(t *testing.T) {
+	newDB := func(t *testing.T, sink Sink) *gorm.DB {
+		db, err := sqlite.NewWithConfig("", &gorm.Config{Logger: gormlogger.Discard})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := db.AutoMigrate(&testUser{}, &auditLog{}); err != nil {
+			t.Fatal(err)
+		}
+		if err := db.Use(New(sink, WithTables("test_users"))); err != nil {
+			t.Fatal(err)
+		}
+		return db
+	}
+	ctx := context.Background()
+	errRollback := errors.New("rollback")
+
+	t.Run("提交后发出，回滚时丢弃", func(t *testing.T) {
+		var events []*Event
+		db := newDB(t, SinkFunc(func(ctx context.Context, es []*Event) { events = append(events, es...) }))
+		tm := txmanager.New(db)
+
+		err := tm.Tx(ctx, func(ctx context.Context) error {
+			if err := txmanager.FromContext(ctx, db).Create(&testUser{Name: "a"}).Error; err != nil {
+				return err
+			}
+			if len(events) != 0 {
+				t.Fatalf("events emitted before commit: %+v", events)
+			}
+			return nil
+		})
+		if err != nil || len(events) != 1 {
+			t.Fatalf("Tx() = %v, events = %+v", err, events)
+		}
+
+		err = tm.Tx(ctx, func(ctx context.Context) error {
+			txmanager.FromContext(ctx, db).Create(&testUser{Name: "b"})
+			return errRollback
+		})
+		if !errors.Is(err, errRollback) || len(events) != 1 {
+			t.Fatalf("Tx() = %v, events = %+v", err, events)
+		}
+	})
+
+	t.Run("TxSink 与业务数据同事务写入", func(t *testing.T) {
+		db := newDB(t, tableSink{})
+		err := db.Transaction(func(tx *gorm.DB) error {
+			tx.Create(&testUser{Name: "a"})
+			return errRollback
+		})
+		if !errors.Is(err, errRollback) {
+			t.Fatal(err)
+		}
+		var n int64
+		db.Model(&auditLog{}).Count(&n)
+		if n != 0 {
+			t.Fatalf("audit logs = %d after rollback, want 0", n)
+		}
+
+		if err := db.Create(&testUser{Name: "b"}).Error; err != nil {
+			t.Fatal(err)
+		}
+		db.Model(&auditLog{}).Count(&n)
+		if n != 1 {
+			t.Fatalf("audit logs = %d, want 1", n)
+		}
+	})
+}
@@ -0,0 +1,90 @@
+package orm
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+
+	"github.com/ZampoRen/go-server-comon/pkg/envkey"
+	logger "github.com/ZampoRen/go-server-comon/pkg/logs"
+)
+
+// NewLogger 构建各驱动共用的 GORM logger（基于 pkg/logs 的 sql_logger）
+// level 可选值: silent, error, warn, info，为空时使用 info；slowThreshold 为 0 时使用 200ms
+func NewLogger(level string, slowThreshold time.Duration, ignoreRecordNotFoundError bool) *logger.GormLogger {
+	// 解析日志级别
+	var logLevel gormlogger.LogLevel
+	switch level {
+	case "silent":
+		logLevel = gormlogger.Silent
+	case "error":
+		logLevel = gormlogger.Error
+	case "warn":
+		logLevel = gormlogger.Warn
+	case "info":
+		logLevel = gormlogger.Info
+	default:
+		logLevel = gormlogger.Info
+	}
+
+	// 设置慢查询阈值
+	if slowThreshold == 0 {
+		slowThreshold = 200 * time.Millisecond
+	}
+
+	// 创建 logger（使用 pkg/logs 包，包名是 logger）
+	gormLogger := logger.NewGormLogger(logLevel, slowThreshold)
+	// 如果用户明确设置了 IgnoreRecordNotFoundError，则使用该值
+	// 否则使用默认值 true（NewGormLogger 已设置）
+	if !ignoreRecordNotFoundError {
+		gormLogger.IgnoreRecordNotFoundError = false
+	}
+
+	return gormLogger
+}
+
+// PoolConfig 连接池配置
+type PoolConfig struct {
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+	ConnMaxIdleTime time.Duration
+}
+
+// PoolConfigFromEnv 从环境变量 <prefix>_MAX_OPEN_CONNS、<prefix>_MAX_IDLE_CONNS、
+// <prefix>_CONN_MAX_LIFETIME、<prefix>_CONN_MAX_IDLE_TIME 读取连接池配置，没有设置则使用默认值
+func PoolConfigFromEnv(prefix string) PoolConfig {
+	// 连接最大生存时间（默认 1 小时）
+	connMaxLifetime := envkey.GetDurationD(prefix+"_CONN_MAX_LIFETIME", time.Hour)
+	// 连接最大空闲时间（默认 10 分钟）
+	connMaxIdleTime := envkey.GetDurationD(prefix+"_CONN_MAX_IDLE_TIME", 10*time.Minute)
+
+	return PoolConfig{
+		// 最大打开连接数（默认 100）
+		MaxOpenConns: envkey.GetIntD(prefix+"_MAX_OPEN_CONNS", 100),
+		// 最大空闲连接数（默认 10）
+		MaxIdleConns:    envkey.GetIntD(prefix+"_MAX_IDLE_CONNS", 10),
+		ConnMaxLifetime: connMaxLifetime,
+		ConnMaxIdleTime: connMaxIdleTime,
+	}
+}
+
+// ConfigurePool 使用 PoolConfigFromEnv(prefix) 配置数据库连接池和超时设置
+func ConfigurePool(db *gorm.DB, prefix string) error {
+	return ApplyPool(db, PoolConfigFromEnv(prefix))
+}
+
+// ApplyPool 将连接池配置应用到 db
+func ApplyPool(db *gorm.DB, pc PoolConfig) error {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+
+	sqlDB.SetMaxOpenConns(pc.MaxOpenConns)
+	sqlDB.SetMaxIdleConns(pc.MaxIdleConns)
+	sqlDB.SetConnMaxLifetime(pc.ConnMaxLifetime)
+	sqlDB.SetConnMaxIdleTime(pc.ConnMaxIdleTime)
+	return nil
+}
@@ -0,0 +1,366 @@
+// Package crypto 提供字段级加密的 GORM 序列化器，用于手机号、身份证号等敏感信息的落库加密
+//
+// 启动时注册序列化器：
+//
+//	keyring, err := crypto.KeyringFromEnv()
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	crypto.Register(keyring)
+//
+// 并为每个使用加密模型的连接注册插件，否则 Update(column, value) 与 Updates(map) 会绕过序列化器写入明文：
+//
+//	if err := db.Use(crypto.NewPlugin()); err != nil {
+//		log.Fatal(err)
+//	}
+//
+// 模型中通过 serializer 标签标记需要加密的字段，支持 string、*string 与 []byte：
+//
+//	type User struct {
+//		ID    int64
+//		Phone string `gorm:"serializer:encrypt;type:varchar(255)"`
+//	}
+//
+// 密文格式为 "<keyID>:<base64(nonce|ciphertext)>"，使用 AES-GCM 加密，每次加密使用随机 nonce，
+// 因此同一明文的密文不同，加密字段不能直接用于等值查询。
+// 模型的表名与列名作为附加数据参与认证，密文复制到其它表或列后无法解密。
+// 轮换密钥时将新密钥设为主密钥并保留旧密钥：新写入使用主密钥，旧数据仍可解密，
+// 可借助 Keyring.NeedsRotation 找出仍使用旧密钥的记录重新写入。
+package crypto
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"sync/atomic"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/callbacks"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// SerializerName 序列化器名称，对应标签 gorm:"serializer:encrypt"
+const SerializerName = "encrypt"
+
+var (
+	// ErrUnknownKey 密文使用的密钥不在密钥环中
+	ErrUnknownKey = errors.New("crypto: unknown key id")
+	// ErrMalformed 密文格式错误
+	ErrMalformed = errors.New("crypto: malformed ciphertext")
+	// ErrNoKeyring 序列化器尚未设置密钥环
+	ErrNoKeyring = errors.New("crypto: keyring not set")
+)
+
+// Keyring 密钥环，使用主密钥加密，按密文中的密钥 ID 解密
+type Keyring struct {
+	primary string
+	aeads   map[string]cipher.AEAD
+}
+
+// NewKeyring 创建密钥环，keys 为密钥 ID 到 AES 密钥（16/24/32 字节）的映射，primary 为加密使用的密钥 ID
+func NewKeyring(primary string, keys map[string][]byte) (*Keyring, error) {
+	if _, ok := keys[primary]; !ok {
+		return nil, fmt.Errorf("crypto: primary key %q not found", primary)
+	}
+
+	k := &Keyring{primary: primary, aeads: make(map[string]cipher.AEAD, len(keys))}
+	for id, key := range keys {
+		if id == "" || strings.Contains(id, ":") {
+			return nil, fmt.Errorf("crypto: invalid key id %q", id)
+		}
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, fmt.Errorf("crypto: key %q: %w", id, err)
+		}
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, fmt.Errorf("crypto: key %q: %w", id, err)
+		}
+		k.aeads[id] = aead
+	}
+	return k, nil
+}
+
+// KeyringFromEnv 从环境变量创建密钥环
+// ORM_CRYPTO_KEYS 为逗号分隔的 "<keyID>:<base64 密钥>"，ORM_CRYPTO_PRIMARY 为主密钥 ID，
+// 未设置主密钥时使用 ORM_CRYPTO_KEYS 中的最后一个
+func KeyringFromEnv() (*Keyring, error) {
+	raw := os.Getenv("ORM_CRYPTO_KEYS")
+	if raw == "" {
+		return nil, fmt.Errorf("crypto: ORM_CRYPTO_KEYS is required")
+	}
+
+	keys := make(map[string][]byte)
+	var last string
+	for _, item := range strings.Split(raw, ",") {
+		id, encoded, ok := strings.Cut(strings.TrimSpace(item), ":")
+		if !ok {
+			return nil, fmt.Errorf("crypto: invalid ORM_CRYPTO_KEYS item %q", item)
+		}
+		key, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("crypto: decode key %q: %w", id, err)
+		}
+		keys[id] = key
+		last = id
+	}
+
+	primary := os.Getenv("ORM_CRYPTO_PRIMARY")
+	if primary == "" {
+		primary = last
+	}
+	return NewKeyring(primary, keys)
+}
+
+// Encrypt 使用主密钥加密，additionalData 参与认证但不加密，解密时必须一致
+func (k *Keyring) Encrypt(plaintext, additionalData []byte) (string, error) {
+	aead := k.aeads[k.primary]
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(plaintext)+aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := aead.Seal(nonce, nonce, plaintext, additionalData)
+	return k.primary + ":" + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt 按密文中的密钥 ID 解密，additionalData 须与加密时一致
+func (k *Keyring) Decrypt(ciphertext string, additionalData []byte) ([]byte, error) {
+	id, encoded, ok := strings.Cut(ciphertext, ":")
+	if !ok {
+		return nil, ErrMalformed
+	}
+	aead, ok := k.aeads[id]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownKey, id)
+	}
+	sealed, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil || len(sealed) < aead.NonceSize() {
+		return nil, ErrMalformed
+	}
+	return aead.Open(nil, sealed[:aead.NonceSize()], sealed[aead.NonceSize():], additionalData)
+}
+
+// NeedsRotation 判断密文是否使用了非主密钥加密
+func (k *Keyring) NeedsRotation(ciphertext string) bool {
+	id, _, _ := strings.Cut(ciphertext, ":")
+	return id != k.primary
+}
+
+// Serializer 加密序列化器，实现 schema.SerializerInterface
+// GORM 在解析模型时会复制序列化器的值，因此密钥环放在共享的指针中，
+// 通过 SetKeyring 替换后所有副本立即生效，轮换密钥无需重启
+type Serializer struct {
+	keyring *atomic.Pointer[Keyring]
+}
+
+var _ schema.SerializerInterface = (*Serializer)(nil)
+
+// defaultSerializer Register 注册的全局序列化器
+var defaultSerializer = &Serializer{keyring: new(atomic.Pointer[Keyring])}
+
+// NewSerializer 创建加密序列化器
+func NewSerializer(k *Keyring) *Serializer {
+	s := &Serializer{keyring: new(atomic.Pointer[Keyring])}
+	s.SetKeyring(k)
+	return s
+}
+
+// SetKeyring 替换密钥环
+func (s *Serializer) SetKeyring(k *Keyring) {
+	s.keyring.Store(k)
+}
+
+// Register 设置全局序列化器的密钥环并以 SerializerName 注册
+// 必须在首次使用加密模型（包括 AutoMigrate）之前调用，之后再次调用只替换密钥环
+func Register(k *Keyring) {
+	defaultSerializer.SetKeyring(k)
+	schema.RegisterSerializer(SerializerName, defaultSerializer)
+}
+
+// Scan 实现 schema.SerializerInterface，解密数据库中的值
+func (s *Serializer) Scan(ctx context.Context, field *schema.Field, dst reflect.Value, dbValue interface{}) error {
+	var ciphertext string
+	switch v := dbValue.(type) {
+	case nil:
+		return nil
+	case []byte:
+		ciphertext = string(v)
+	case string:
+		ciphertext = v
+	default:
+		return fmt.Errorf("crypto: unsupported db value type %T for field %s", dbValue, field.Name)
+	}
+	if ciphertext == "" {
+		return nil
+	}
+
+	k := s.keyring.Load()
+	if k == nil {
+		return ErrNoKeyring
+	}
+	plaintext, err := k.Decrypt(ciphertext, additionalData(field))
+	if err != nil {
+		return fmt.Errorf("crypto: decrypt field %s: %w", field.Name, err)
+	}
+
+	fieldValue := reflect.New(field.FieldType).Elem()
+	switch field.FieldType {
+	case reflect.TypeOf(""):
+		fieldValue.SetString(string(plaintext))
+	case reflect.TypeOf((*string)(nil)):
+		str := string(plaintext)
+		fieldValue.Set(reflect.ValueOf(&str))
+	case reflect.TypeOf([]byte(nil)):
+		fieldValue.SetBytes(plaintext)
+	default:
+		return fmt.Errorf("crypto: unsupported field type %s for field %s", field.FieldType, field.Name)
+	}
+	field.ReflectValueOf(ctx, dst).Set(fieldValue)
+	return nil
+}
+
+// Value 实现 schema.SerializerInterface，加密字段值，空值不加密
+func (s *Serializer) Value(ctx context.Context, field *schema.Field, dst reflect.Value, fieldValue interface{}) (interface{}, error) {
+	var plaintext []byte
+	switch v := fieldValue.(type) {
+	case string:
+		if v == "" {
+			return "", nil
+		}
+		plaintext = []byte(v)
+	case *string:
+		if v == nil {
+			return nil, nil
+		}
+		plaintext = []byte(*v)
+	case []byte:
+		if v == nil {
+			return nil, nil
+		}
+		plaintext = v
+	default:
+		return nil, fmt.Errorf("crypto: unsupported field type %T for field %s", fieldValue, field.Name)
+	}
+	k := s.keyring.Load()
+	if k == nil {
+		return nil, ErrNoKeyring
+	}
+	return k.Encrypt(plaintext, additionalData(field))
+}
+
+// additionalData 返回字段的认证附加数据 "<表名>.<列名>"
+func additionalData(field *schema.Field) []byte {
+	return []byte(field.Schema.Table + "." + field.DBName)
+}
+
+// encrypted 返回字段使用的加密序列化器，未加密时返回 nil
+func encrypted(field *schema.Field) *Serializer {
+	if field == nil {
+		return nil
+	}
+	s, _ := field.Serializer.(*Serializer)
+	return s
+}
+
+// setKey 记录由插件生成的 SET 子句，语句执行后删除
+const setKey = "crypto:set"
+
+type plugin struct{}
+
+// NewPlugin 创建加密插件
+// GORM 只在 Create 与以结构体为参数的 Updates 中调用序列化器，Update(column, value) 与 Updates(map)
+// 直接使用传入的值，插件在执行更新前加密这些值
+func NewPlugin() gorm.Plugin {
+	return plugin{}
+}
+
+// Name 实现 gorm.Plugin
+func (plugin) Name() string {
+	return "orm:crypto"
+}
+
+// Initialize 实现 gorm.Plugin
+func (p plugin) Initialize(db *gorm.DB) error {
+	cb := db.Callback()
+	if err := cb.Update().Before("gorm:update").Register("orm:crypto_encrypt", p.encryptAssignments); err != nil {
+		return err
+	}
+	return cb.Update().After("gorm:update").Register("orm:crypto_cleanup", p.cleanup)
+}
+
+// encryptAssignments 以 map 更新加密字段时，提前生成 SET 子句并加密其中的加密字段
+// 模型实例上的字段仍赋值为明文；显式指定的 SET 子句无法区分明文与密文，写入加密字段时返回错误
+func (plugin) encryptAssignments(db *gorm.DB) {
+	stmt := db.Statement
+	if db.Error != nil || stmt.Schema == nil {
+		return
+	}
+	if c, ok := stmt.Clauses["SET"]; ok {
+		if set, ok := c.Expression.(clause.Set); ok {
+			for _, a := range set {
+				if encrypted(stmt.Schema.LookUpField(a.Column.Name)) != nil {
+					_ = db.AddError(fmt.Errorf("crypto: SET clause on encrypted column %s is not supported", a.Column.Name))
+					return
+				}
+			}
+		}
+		return
+	}
+
+	values, ok := stmt.Dest.(map[string]interface{})
+	if !ok {
+		return
+	}
+	found := false
+	for k := range values {
+		if encrypted(stmt.Schema.LookUpField(k)) != nil {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return
+	}
+
+	set := callbacks.ConvertToAssignments(stmt)
+	for i, a := range set {
+		field := stmt.Schema.LookUpField(a.Column.Name)
+		s := encrypted(field)
+		if s == nil {
+			continue
+		}
+		switch a.Value.(type) {
+		case nil:
+			continue
+		case string, *string, []byte:
+		default:
+			_ = db.AddError(fmt.Errorf("crypto: unsupported value type %T for encrypted column %s", a.Value, field.DBName))
+			return
+		}
+		v, err := s.Value(stmt.Context, field, stmt.ReflectValue, a.Value)
+		if err != nil {
+			_ = db.AddError(err)
+			return
+		}
+		set[i].Value = v
+	}
+	if len(set) > 0 {
+		stmt.AddClause(set)
+		db.InstanceSet(setKey, true)
+	}
+}
+
+// cleanup 删除 encryptAssignments 生成的 SET 子句，与 gorm:update 的行为一致
+func (plugin) cleanup(db *gorm.DB) {
+	if _, ok := db.InstanceGet(setKey); ok {
+		delete(db.Statement.Clauses, "SET")
+	}
+}
@@ -0,0 +1,180 @@
+package crypto
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+
+	"github.com/ZampoRen/go-server-comon/internal/infra/orm/impl/sqlite"
+	"github.com/ZampoRen/go-server-comon/internal/infra/orm/repo"
+)
+
+type testUser struct {
+	ID     int64
+	Phone  string  `gorm:"serializer:encrypt"`
+	IDCard *string `gorm:"serializer:encrypt"`
+	Secret []byte  `gorm:"serializer:encrypt"`
+}
+
+var (
+	key1 = bytes.Repeat([]byte{1}, 32)
+	key2 = bytes.Repeat([]byte{2}, 32)
+)
+
+func mustKeyring(t *testing.T, primary string, keys map[string][]byte) *Keyring {
+	t.Helper()
+	k, err := NewKeyring(primary, keys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return k
+}
+
+// TestSerializer 测试字段加密落库与密钥轮换
+func TestSerializer(t *testing.T) {
+	Register(mustKeyring(t, "k1", map[string][]byte{"k1": key1}))
+
+	db, err := sqlite.NewWithConfig("", &gorm.Config{Logger: gormlogger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&testUser{}); err != nil {
+		t.Fatal(err)
+	}
+	idCard := "110101199001011234"
+	u := &testUser{Phone: "13800000000", IDCard: &idCard, Secret: []byte("s")}
+	if err := db.Create(u).Error; err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	var raw string
+	db.Raw("SELECT phone FROM test_users WHERE id = ?", u.ID).Scan(&raw)
+	if !strings.HasPrefix(raw, "k1:") || strings.Contains(raw, "13800000000") {
+		t.Errorf("stored phone = %q, want ciphertext with key k1", raw)
+	}
+
+	// 轮换为 k2，旧数据仍可读取
+	rotated := mustKeyring(t, "k2", map[string][]byte{"k1": key1, "k2": key2})
+	Register(rotated)
+	var got testUser
+	if err := db.First(&got, u.ID).Error; err != nil {
+		t.Fatalf("First() error = %v", err)
+	}
+	if got.Phone != u.Phone || got.IDCard == nil || *got.IDCard != idCard || string(got.Secret) != "s" {
+		t.Errorf("First() = %+v, want decrypted fields", got)
+	}
+	if !rotated.NeedsRotation(raw) {
+		t.Error("NeedsRotation() = false, want true")
+	}
+
+	// 移除旧密钥后无法解密
+	Register(mustKeyring(t, "k2", map[string][]byte{"k2": key2}))
+	if err := db.First(&testUser{}, u.ID).Error; !errors.Is(err, ErrUnknownKey) {
+		t.Errorf("First() without old key error = %v, want ErrUnknownKey", err)
+	}
+}
+
+// TestNewKeyring 测试密钥环参数校验
+func TestNewKeyring(t *testing.T) {
+	tests := []struct {
+		name    string
+		primary string
+		keys    map[string][]byte
+	}{
+		{"主密钥不存在", "k2", map[string][]byte{"k1": key1}},
+		{"密钥长度错误", "k1", map[string][]byte{"k1": []byte("short")}},
+		{"密钥 ID 含冒号", "k:1", map[string][]byte{"k:1": key1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewKeyring(tt.primary, tt.keys); err == nil {
+				t.Error("NewKeyring() error = nil, want error")
+			}
+		})
+	}
+}
+
+// TestPlugin 测试 Update(column, value) 与 Updates(map) 写入密文
+func TestPlugin(t *testing.T) {
+	Register(mustKeyring(t, "k1", map[string][]byte{"k1": key1}))
+
+	db, err := sqlite.NewWithConfig("", &gorm.Config{Logger: gormlogger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Use(NewPlugin()); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&testUser{}); err != nil {
+		t.Fatal(err)
+	}
+
+	rawPhone := func(t *testing.T, id int64) string {
+		t.Helper()
+		var raw string
+		db.Raw("SELECT phone FROM test_users WHERE id = ?", id).Scan(&raw)
+		return raw
+	}
+	// assertPhone 检查库中为密文且能解密为 want
+	assertPhone := func(t *testing.T, id int64, want string) {
+		t.Helper()
+		if raw := rawPhone(t, id); !strings.HasPrefix(raw, "k1:") || strings.Contains(raw, want) {
+			t.Fatalf("stored phone = %q, want ciphertext", raw)
+		}
+		var got testUser
+		if err := db.First(&got, id).Error; err != nil || got.Phone != want {
+			t.Fatalf("First() = %+v, %v, want phone %s", got, err, want)
+		}
+	}
+
+	u := &testUser{Phone: "13800000000"}
+	if err := db.Create(u).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("Update 单列", func(t *testing.T) {
+		if err := db.Model(u).Update("phone", "13900000000").Error; err != nil {
+			t.Fatal(err)
+		}
+		if u.Phone != "13900000000" {
+			t.Fatalf("model phone = %q, want plaintext", u.Phone)
+		}
+		assertPhone(t, u.ID, "13900000000")
+	})
+
+	t.Run("Updates map", func(t *testing.T) {
+		if err := db.Model(u).Updates(map[string]interface{}{"Phone": "13700000000", "secret": []byte("s")}).Error; err != nil {
+			t.Fatal(err)
+		}
+		assertPhone(t, u.ID, "13700000000")
+	})
+
+	t.Run("repo.UpdateFields", func(t *testing.T) {
+		r := repo.New[testUser, int64](db)
+		if err := r.UpdateFields(context.Background(), u.ID, map[string]any{"phone": "13600000000"}); err != nil {
+			t.Fatal(err)
+		}
+		assertPhone(t, u.ID, "13600000000")
+	})
+
+	t.Run("拒绝表达式写入加密字段", func(t *testing.T) {
+		err := db.Model(u).Update("phone", gorm.Expr("?", "13500000000")).Error
+		if err == nil || strings.Contains(rawPhone(t, u.ID), "13500000000") {
+			t.Fatalf("Update() error = %v, stored = %q", err, rawPhone(t, u.ID))
+		}
+	})
+
+	t.Run("密文不能复制到其它列", func(t *testing.T) {
+		if err := db.Exec("UPDATE test_users SET id_card = phone WHERE id = ?", u.ID).Error; err != nil {
+			t.Fatal(err)
+		}
+		if err := db.First(&testUser{}, u.ID).Error; err == nil {
+			t.Fatal("First() error = nil, want authentication failure")
+		}
+	})
+}
@@ -0,0 +1,123 @@
+package orm
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"net"
+	"sync"
+	"syscall"
+
+	"gorm.io/gorm"
+
+	"github.com/ZampoRen/go-server-comon/internal/errno"
+	"github.com/ZampoRen/go-server-comon/pkg/errorx"
+)
+
+var (
+	connErrorsMu sync.RWMutex
+	connErrors   = []error{driver.ErrBadConn, sql.ErrConnDone, syscall.ECONNREFUSED, syscall.ECONNRESET, syscall.EPIPE}
+)
+
+// RegisterConnError 注册驱动特有的连接错误，如 mysql.ErrInvalidConn，由各驱动在 init 中调用
+func RegisterConnError(errs ...error) {
+	connErrorsMu.Lock()
+	defer connErrorsMu.Unlock()
+	connErrors = append(connErrors, errs...)
+}
+
+// IsConnError 判断 err 是否为连接层面的错误（连接被拒绝、断开、网络超时等）
+func IsConnError(err error) bool {
+	if err == nil {
+		return false
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+
+	connErrorsMu.RLock()
+	defer connErrorsMu.RUnlock()
+	for _, target := range connErrors {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// WrapError 将连接错误与超时转换为 errorx 错误码，其它错误原样返回
+//   - 超时（context.DeadlineExceeded 或网络超时）: errno.ErrDBTimeout
+//   - 连接错误: errno.ErrDBUnavailable
+//
+// 转换后仍可通过 errors.Is 匹配原始错误
+func WrapError(err error) error {
+	if err == nil {
+		return nil
+	}
+	var se errorx.StatusError
+	if errors.As(err, &se) {
+		return err
+	}
+
+	var netErr net.Error
+	if errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout()) {
+		return errorx.WrapByCode(err, errno.ErrDBTimeout)
+	}
+	if IsConnError(err) {
+		return errorx.WrapByCode(err, errno.ErrDBUnavailable)
+	}
+	return err
+}
+
+// IsDuplicateKey 判断 err 是否为唯一键冲突，通过 db 的驱动识别驱动特有的错误码（如 MySQL 1062）
+func IsDuplicateKey(db *gorm.DB, err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return true
+	}
+	if t, ok := db.Dialector.(gorm.ErrorTranslator); ok {
+		return errors.Is(t.Translate(err), gorm.ErrDuplicatedKey)
+	}
+	return false
+}
+
+// errorPlugin 在每次操作后将 db.Error 中的连接错误转换为 errorx 错误码
+type errorPlugin struct{}
+
+// NewErrorPlugin 创建连接错误转换插件，调用方拿到的 db.Error 即为 errorx 错误，无需感知驱动错误类型
+func NewErrorPlugin() gorm.Plugin {
+	return errorPlugin{}
+}
+
+// Name 实现 gorm.Plugin
+func (errorPlugin) Name() string {
+	return "orm:conn_error"
+}
+
+// Initialize 实现 gorm.Plugin
+func (p errorPlugin) Initialize(db *gorm.DB) error {
+	cb := db.Callback()
+	for _, err := range []error{
+		cb.Create().After("*").Register(p.Name(), wrapDBError),
+		cb.Query().After("*").Register(p.Name(), wrapDBError),
+		cb.Update().After("*").Register(p.Name(), wrapDBError),
+		cb.Delete().After("*").Register(p.Name(), wrapDBError),
+		cb.Row().After("*").Register(p.Name(), wrapDBError),
+		cb.Raw().After("*").Register(p.Name(), wrapDBError),
+	} {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func wrapDBError(db *gorm.DB) {
+	if db.Error != nil {
+		db.Error = WrapError(db.Error)
+	}
+}
@@ -0,0 +1,230 @@
+// Package fixtures 为集成测试加载 YAML/JSON 测试数据
+//
+// 文件内容可以是行列表，表名取自文件名（users.yml 对应 users 表）：
+//
+//	# users.yml
+//	- id: 1
+//	  name: alice
+//	- id: 2
+//	  name: bob
+//
+// 也可以是表名到行列表的映射，按文档中的顺序插入，被外键引用的表应写在前面：
+//
+//	users:
+//	  - id: 1
+//	    name: alice
+//	orders:
+//	  - id: 1
+//	    user_id: 1
+//
+// 测试中通过 Setup 在事务内加载，测试结束时自动回滚（MySQL 的 TRUNCATE 会隐式提交事务，
+// 事务中清空表时 Truncate 改用 DELETE）：
+//
+//	func TestUserRepo(t *testing.T) {
+//		db := fixtures.Setup(t, db, "testdata/fixtures")
+//		repo := NewUserRepo(db)
+//		...
+//	}
+package fixtures
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"go.yaml.in/yaml/v3"
+	"gorm.io/gorm"
+)
+
+// Table 一张表的测试数据
+type Table struct {
+	Name string
+	Rows []map[string]interface{}
+}
+
+// Parse 解析文件内容，name 为文件名，内容为行列表时用于推断表名
+// 内容为表名到行列表的映射时按文档中的顺序返回
+func Parse(name string, data []byte) ([]Table, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("fixtures: parse %s: %w", name, err)
+	}
+	if len(doc.Content) == 0 {
+		return nil, nil
+	}
+
+	root := doc.Content[0]
+	switch root.Kind {
+	case yaml.SequenceNode:
+		table := strings.TrimSuffix(filepath.Base(name), filepath.Ext(name))
+		rows, err := decodeRows(name, root)
+		if err != nil {
+			return nil, err
+		}
+		return []Table{{Name: table, Rows: rows}}, nil
+	case yaml.MappingNode:
+		tables := make([]Table, 0, len(root.Content)/2)
+		for i := 0; i+1 < len(root.Content); i += 2 {
+			table, value := root.Content[i].Value, root.Content[i+1]
+			if value.Kind != yaml.SequenceNode {
+				return nil, fmt.Errorf("fixtures: %s: table %s must be a list of rows", name, table)
+			}
+			rows, err := decodeRows(name, value)
+			if err != nil {
+				return nil, err
+			}
+			tables = append(tables, Table{Name: table, Rows: rows})
+		}
+		return tables, nil
+	case yaml.ScalarNode:
+		if root.Tag == "!!null" {
+			return nil, nil
+		}
+	}
+	return nil, fmt.Errorf("fixtures: %s: unexpected document type %s", name, root.Tag)
+}
+
+// decodeRows 解析行列表节点
+func decodeRows(name string, node *yaml.Node) ([]map[string]interface{}, error) {
+	var list []interface{}
+	if err := node.Decode(&list); err != nil {
+		return nil, fmt.Errorf("fixtures: parse %s: %w", name, err)
+	}
+	return toRows(name, list)
+}
+
+// toRows 将解析结果转换为行，嵌套的对象与数组序列化为 JSON 字符串，便于写入 JSON 列
+func toRows(name string, list []interface{}) ([]map[string]interface{}, error) {
+	rows := make([]map[string]interface{}, 0, len(list))
+	for i, item := range list {
+		row, ok := item.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("fixtures: %s: row %d must be a mapping", name, i)
+		}
+		for k, v := range row {
+			switch v.(type) {
+			case map[string]interface{}, []interface{}:
+				b, err := json.Marshal(v)
+				if err != nil {
+					return nil, fmt.Errorf("fixtures: %s: row %d column %s: %w", name, i, k, err)
+				}
+				row[k] = string(b)
+			}
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// Load 按顺序加载文件到 db，path 为目录时加载其中的 .yml/.yaml/.json 文件（按文件名排序）
+// Load 不开启事务，需要回滚时使用 Setup 或在事务中调用
+func Load(db *gorm.DB, paths ...string) error {
+	files, err := expand(paths)
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return fmt.Errorf("fixtures: %w", err)
+		}
+		tables, err := Parse(file, data)
+		if err != nil {
+			return err
+		}
+		if err := Insert(db, tables...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Insert 逐行插入测试数据，各行的列可以不同
+func Insert(db *gorm.DB, tables ...Table) error {
+	for _, table := range tables {
+		for i, row := range table.Rows {
+			if err := db.Table(table.Name).Create(row).Error; err != nil {
+				return fmt.Errorf("fixtures: insert %s row %d: %w", table.Name, i, err)
+			}
+		}
+	}
+	return nil
+}
+
+// Setup 开启事务并加载测试数据，返回事务连接，测试结束时自动回滚
+// 加载失败时测试立即失败
+func Setup(t testing.TB, db *gorm.DB, paths ...string) *gorm.DB {
+	t.Helper()
+	tx := db.Begin()
+	if tx.Error != nil {
+		t.Fatalf("fixtures: begin: %v", tx.Error)
+	}
+	t.Cleanup(func() {
+		tx.Rollback()
+	})
+	if err := Load(tx, paths...); err != nil {
+		t.Fatal(err)
+	}
+	return tx
+}
+
+// Truncate 清空表数据并重置自增主键
+// db 处于事务中时使用 DELETE，避免 MySQL 的 TRUNCATE 隐式提交事务，此时 MySQL 不重置自增主键
+func Truncate(db *gorm.DB, tables ...string) error {
+	_, inTx := db.Statement.ConnPool.(gorm.TxCommitter)
+	for _, table := range tables {
+		var err error
+		switch {
+		case db.Dialector.Name() == "sqlite":
+			if err = db.Exec("DELETE FROM " + quote(db, table)).Error; err == nil && db.Migrator().HasTable("sqlite_sequence") {
+				err = db.Exec("DELETE FROM sqlite_sequence WHERE name = ?", table).Error
+			}
+		case inTx:
+			err = db.Exec("DELETE FROM " + quote(db, table)).Error
+		default:
+			err = db.Exec("TRUNCATE TABLE " + quote(db, table)).Error
+		}
+		if err != nil {
+			return fmt.Errorf("fixtures: truncate %s: %w", table, err)
+		}
+	}
+	return nil
+}
+
+func quote(db *gorm.DB, name string) string {
+	var b strings.Builder
+	db.Dialector.QuoteTo(&b, name)
+	return b.String()
+}
+
+// expand 将目录展开为其中的测试数据文件
+func expand(paths []string) ([]string, error) {
+	var files []string
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, fmt.Errorf("fixtures: %w", err)
+		}
+		if !info.IsDir() {
+			files = append(files, path)
+			continue
+		}
+
+		entries, err := os.ReadDir(path)
+		if err != nil {
+			return nil, fmt.Errorf("fixtures: %w", err)
+		}
+		for _, e := range entries {
+			switch filepath.Ext(e.Name()) {
+			case ".yml", ".yaml", ".json":
+				if !e.IsDir() {
+					files = append(files, filepath.Join(path, e.Name()))
+				}
+			}
+		}
+	}
+	return files, nil
+}
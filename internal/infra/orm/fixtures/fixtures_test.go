@@ -0,0 +1,117 @@
+package fixtures
+
+import (
+	"testing"
+
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+
+	"github.com/ZampoRen/go-server-comon/internal/infra/orm/impl/sqlite"
+)
+
+type user struct {
+	ID      int64
+	Name    string
+	Profile string
+}
+
+type order struct {
+	ID     int64
+	UserID int64
+	Amount int
+}
+
+func newDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := sqlite.NewWithConfig("", &gorm.Config{Logger: gormlogger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&user{}, &order{}); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func count(t *testing.T, db *gorm.DB, model interface{}) int64 {
+	t.Helper()
+	var n int64
+	if err := db.Model(model).Count(&n).Error; err != nil {
+		t.Fatal(err)
+	}
+	return n
+}
+
+// TestSetup 测试在事务中加载目录下的测试数据并在测试结束后回滚
+func TestSetup(t *testing.T) {
+	db := newDB(t)
+
+	t.Run("加载", func(t *testing.T) {
+		tx := Setup(t, db, "testdata")
+		if got := count(t, tx, &user{}); got != 2 {
+			t.Errorf("users = %d, want 2", got)
+		}
+		if got := count(t, tx, &order{}); got != 2 {
+			t.Errorf("orders = %d, want 2", got)
+		}
+		var u user
+		tx.First(&u, 1)
+		if u.Profile != `{"city":"beijing"}` {
+			t.Errorf("profile = %q, want JSON", u.Profile)
+		}
+	})
+
+	if got := count(t, db, &user{}); got != 0 {
+		t.Errorf("users after rollback = %d, want 0", got)
+	}
+}
+
+// TestTruncate 测试清空表并重置自增主键
+func TestTruncate(t *testing.T) {
+	db := newDB(t)
+	if err := Load(db, "testdata/users.yml"); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if err := Truncate(db, "users"); err != nil {
+		t.Fatalf("Truncate() error = %v", err)
+	}
+	if got := count(t, db, &user{}); got != 0 {
+		t.Errorf("users after truncate = %d, want 0", got)
+	}
+
+	u := &user{Name: "c"}
+	db.Create(u)
+	if u.ID != 1 {
+		t.Errorf("id after truncate = %d, want 1", u.ID)
+	}
+}
+
+// TestParse 测试映射形式按文档顺序返回表
+func TestParse(t *testing.T) {
+	data := []byte(`
+users:
+  - id: 1
+    name: alice
+orders:
+  - id: 1
+    user_id: 1
+    meta: {channel: app}
+`)
+	tables, err := Parse("seed.yml", data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tables) != 2 || tables[0].Name != "users" || tables[1].Name != "orders" {
+		t.Fatalf("tables = %+v, want users before orders", tables)
+	}
+	if got := tables[1].Rows[0]["meta"]; got != `{"channel":"app"}` {
+		t.Errorf("meta = %v, want JSON", got)
+	}
+
+	if tables, err := Parse("empty.yml", nil); err != nil || tables != nil {
+		t.Errorf("Parse(empty) = %v, %v", tables, err)
+	}
+	if _, err := Parse("bad.yml", []byte("users: 1")); err == nil {
+		t.Error("Parse() expected error for non-list table")
+	}
+}
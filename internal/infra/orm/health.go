@@ -0,0 +1,241 @@
+package orm
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/common/hlog"
+
+	"github.com/ZampoRen/go-server-comon/internal/errno"
+	"github.com/ZampoRen/go-server-comon/pkg/errorx"
+)
+
+// PoolStats 连接池使用情况，取自 sql.DBStats
+type PoolStats struct {
+	MaxOpenConns int           `json:"max_open_conns"` // 连接池上限，0 表示不限制
+	OpenConns    int           `json:"open_conns"`     // 当前连接总数
+	InUse        int           `json:"in_use"`         // 使用中的连接数
+	Idle         int           `json:"idle"`           // 空闲连接数
+	WaitCount    int64         `json:"wait_count"`     // 等待连接的累计次数
+	WaitDuration time.Duration `json:"wait_duration"`  // 等待连接的累计时长
+}
+
+// Saturation 返回连接池饱和度（使用中的连接 / 连接池上限），不限制时返回 0
+func (s PoolStats) Saturation() float64 {
+	if s.MaxOpenConns <= 0 {
+		return 0
+	}
+	return float64(s.InUse) / float64(s.MaxOpenConns)
+}
+
+// HealthReport 健康检查结果
+type HealthReport struct {
+	Healthy bool          `json:"healthy"`
+	Latency time.Duration `json:"latency"`
+	Pool    *PoolStats    `json:"pool,omitempty"`
+	Error   string        `json:"error,omitempty"`
+}
+
+// HealthCheck 执行一次 Ping 并读取连接池使用情况，ctx 没有截止时间时使用 1s 超时
+// 返回的 error 已通过 WrapError 转换为 errorx 错误码
+func HealthCheck(ctx context.Context, db *DB) (*HealthReport, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Second)
+		defer cancel()
+	}
+
+	report := &HealthReport{}
+	sqlDB, err := db.DB()
+	if err == nil {
+		start := time.Now()
+		err = sqlDB.PingContext(ctx)
+		report.Latency = time.Since(start)
+
+		stats := sqlDB.Stats()
+		report.Pool = &PoolStats{
+			MaxOpenConns: stats.MaxOpenConnections,
+			OpenConns:    stats.OpenConnections,
+			InUse:        stats.InUse,
+			Idle:         stats.Idle,
+			WaitCount:    stats.WaitCount,
+			WaitDuration: stats.WaitDuration,
+		}
+	}
+
+	if err != nil {
+		err = WrapError(err)
+		report.Error = err.Error()
+		return report, err
+	}
+	report.Healthy = true
+	return report, nil
+}
+
+// Status 后台探活记录的可用性状态
+type Status struct {
+	Available bool      `json:"available"`
+	Since     time.Time `json:"since"`      // 进入当前状态的时间
+	LastCheck time.Time `json:"last_check"` // 最近一次探活时间
+	Failures  int       `json:"failures"`   // 连续失败次数
+	LastError string    `json:"last_error,omitempty"`
+}
+
+// Supervisor 后台定期 Ping 数据库，记录可用性供 /readyz 使用
+// database/sql 会在连接失效后自动重建连接，定期 Ping 可以尽早触发重连并发现故障
+type Supervisor struct {
+	db        *DB
+	name      string
+	interval  time.Duration
+	timeout   time.Duration
+	threshold int
+
+	mu     sync.RWMutex
+	status Status
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// SupervisorOption 后台探活选项
+type SupervisorOption func(s *Supervisor)
+
+// WithSupervisorName 设置日志中的数据源名称，默认 DefaultDataSource
+func WithSupervisorName(name string) SupervisorOption {
+	return func(s *Supervisor) {
+		s.name = name
+	}
+}
+
+// WithPingInterval 设置探活间隔，默认 5s
+func WithPingInterval(d time.Duration) SupervisorOption {
+	return func(s *Supervisor) {
+		if d > 0 {
+			s.interval = d
+		}
+	}
+}
+
+// WithPingTimeout 设置单次 Ping 的超时时间，默认 1s
+func WithPingTimeout(d time.Duration) SupervisorOption {
+	return func(s *Supervisor) {
+		if d > 0 {
+			s.timeout = d
+		}
+	}
+}
+
+// WithFailureThreshold 设置连续失败多少次后标记为不可用，默认 3
+func WithFailureThreshold(n int) SupervisorOption {
+	return func(s *Supervisor) {
+		if n > 0 {
+			s.threshold = n
+		}
+	}
+}
+
+// NewSupervisor 创建后台探活，初始状态为可用
+func NewSupervisor(db *DB, opts ...SupervisorOption) *Supervisor {
+	s := &Supervisor{
+		db:        db,
+		name:      DefaultDataSource,
+		interval:  5 * time.Second,
+		timeout:   time.Second,
+		threshold: 3,
+		status:    Status{Available: true, Since: time.Now()},
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Start 立即探活一次并启动后台探活，重复调用无效
+func (s *Supervisor) Start(ctx context.Context) error {
+	s.mu.Lock()
+	if s.cancel != nil {
+		s.mu.Unlock()
+		return nil
+	}
+	ctx, s.cancel = context.WithCancel(context.WithoutCancel(ctx))
+	s.done = make(chan struct{})
+	s.mu.Unlock()
+
+	s.check(ctx)
+	go s.loop(ctx)
+	return nil
+}
+
+// Stop 停止后台探活
+func (s *Supervisor) Stop(context.Context) error {
+	s.mu.Lock()
+	cancel, done := s.cancel, s.done
+	s.cancel = nil
+	s.mu.Unlock()
+
+	if cancel != nil {
+		cancel()
+		<-done
+	}
+	return nil
+}
+
+// Status 返回当前状态
+func (s *Supervisor) Status() Status {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.status
+}
+
+// Ready 供就绪探针调用，不可用时返回 errno.ErrDBUnavailable
+func (s *Supervisor) Ready(context.Context) error {
+	st := s.Status()
+	if st.Available {
+		return nil
+	}
+	return errorx.WrapByCode(errors.New(st.LastError), errno.ErrDBUnavailable,
+		errorx.Extra("data_source", s.name))
+}
+
+func (s *Supervisor) loop(ctx context.Context) {
+	defer close(s.done)
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.check(ctx)
+		}
+	}
+}
+
+// check 执行一次探活，连续失败达到阈值时标记为不可用，成功一次即恢复
+func (s *Supervisor) check(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+	_, err := HealthCheck(ctx, s.db)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	s.status.LastCheck = now
+	if err == nil {
+		if !s.status.Available {
+			hlog.CtxInfof(ctx, "[ORM] data source %s recovered after %d failures", s.name, s.status.Failures)
+			s.status.Available, s.status.Since = true, now
+		}
+		s.status.Failures, s.status.LastError = 0, ""
+		return
+	}
+
+	s.status.Failures++
+	s.status.LastError = errorx.ErrorWithoutStack(err)
+	if s.status.Available && s.status.Failures >= s.threshold {
+		hlog.CtxErrorf(ctx, "[ORM] data source %s unavailable: %s", s.name, s.status.LastError)
+		s.status.Available, s.status.Since = false, now
+	}
+}
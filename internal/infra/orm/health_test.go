@@ -0,0 +1,76 @@
+package orm_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ZampoRen/go-server-comon/internal/errno"
+	"github.com/ZampoRen/go-server-comon/internal/infra/orm"
+	"github.com/ZampoRen/go-server-comon/internal/infra/orm/impl/sqlite"
+	"github.com/ZampoRen/go-server-comon/pkg/errorx"
+)
+
+// TestSupervisor 测试健康检查与后台探活在连接关闭后标记为不可用
+func TestSupervisor(t *testing.T) {
+	ctx := context.Background()
+	db, err := sqlite.NewMemory()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if report, err := orm.HealthCheck(ctx, db); err != nil || !report.Healthy || report.Pool == nil {
+		t.Fatalf("HealthCheck() = %+v, %v, want healthy", report, err)
+	}
+
+	s := orm.NewSupervisor(db, orm.WithPingInterval(10*time.Millisecond), orm.WithFailureThreshold(2))
+	_ = s.Start(ctx)
+	defer s.Stop(ctx)
+	if err := s.Ready(ctx); err != nil {
+		t.Fatalf("Ready() error = %v", err)
+	}
+
+	sqlDB, _ := db.DB()
+	_ = sqlDB.Close()
+	deadline := time.Now().Add(time.Second)
+	for s.Status().Available && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	err = s.Ready(ctx)
+	var se errorx.StatusError
+	if !errors.As(err, &se) || se.Code() != errno.ErrDBUnavailable {
+		t.Errorf("Ready() error = %v, want code %d", err, errno.ErrDBUnavailable)
+	}
+	if st := s.Status(); st.Available || st.Failures < 2 {
+		t.Errorf("Status() = %+v, want unavailable after 2 failures", st)
+	}
+}
+
+// TestWrapError 测试连接错误转换为 errorx 错误码
+func TestWrapError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int32
+	}{
+		{"非连接错误", context.Canceled, 0},
+		{"超时", context.DeadlineExceeded, errno.ErrDBTimeout},
+		{"连接失效", errors.Join(errors.New("query"), sql.ErrConnDone), errno.ErrDBUnavailable},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := orm.WrapError(tt.err)
+			var se errorx.StatusError
+			got := int32(0)
+			if errors.As(err, &se) {
+				got = se.Code()
+			}
+			if got != tt.want || !errors.Is(err, tt.err) {
+				t.Errorf("WrapError() code = %d, want %d, err = %v", got, tt.want, err)
+			}
+		})
+	}
+}
@@ -2,21 +2,45 @@ package mysql
 
 import (
 	"fmt"
+	"net"
+	"net/url"
 	"os"
+	"strconv"
+	"strings"
 	"time"
 
+	mysqldriver "github.com/go-sql-driver/mysql"
 	"gorm.io/driver/mysql"
 	"gorm.io/gorm"
 	gormlogger "gorm.io/gorm/logger"
 
+	"github.com/ZampoRen/go-server-comon/internal/infra/orm"
 	"github.com/ZampoRen/go-server-comon/pkg/envkey"
 	logger "github.com/ZampoRen/go-server-comon/pkg/logs"
 )
 
 // Config GORM 配置选项
+// 未显式设置的字段从 <EnvPrefix>_ 开头的环境变量读取，文档中以默认前缀 MYSQL 为例
 type Config struct {
-	// DSN 数据库连接字符串，如果为空则从环境变量 MYSQL_DSN 读取
+	// EnvPrefix 环境变量前缀，默认 MYSQL，多数据源时可为每个数据源设置不同前缀，如 MYSQL_ANALYTICS
+	EnvPrefix string
+	// DSN 数据库连接字符串，如果为空则使用 Host 等字段构建，仍为空则从环境变量 MYSQL_DSN 读取
 	DSN string
+	// Host 数据库地址，设置后根据 Host、Port、User、Password、Database、Params 构建 DSN
+	Host string
+	// Port 数据库端口，默认 3306
+	Port int
+	// User 用户名
+	User string
+	// Password 密码
+	Password string
+	// Database 数据库名
+	Database string
+	// Params 额外的连接参数，如 charset、timeout、time_zone
+	// 默认设置 charset=utf8mb4、parseTime=true、loc=Local，可被覆盖
+	Params map[string]string
+	// Pool 连接池配置，如果为空则从环境变量 MYSQL_MAX_OPEN_CONNS 等读取
+	Pool *orm.PoolConfig
 	// LogLevel 日志级别，可选值: silent, error, warn, info
 	// 如果为空，默认使用 info
 	LogLevel string
@@ -26,6 +50,24 @@ type Config struct {
 	IgnoreRecordNotFoundError bool
 	// GormConfig 自定义 GORM 配置，如果提供则优先使用此配置
 	GormConfig *gorm.Config
+	// ReplicaDSNs 只读副本连接字符串，如果为空则从环境变量 MYSQL_REPLICA_DSNS（逗号分隔）读取
+	// 为空时不启用读写分离
+	ReplicaDSNs []string
+	// ReplicaPolicy 副本负载均衡策略，可选值: random, round_robin
+	// 如果为空则从环境变量 MYSQL_REPLICA_POLICY 读取，默认 random
+	ReplicaPolicy string
+	// StickyWindow 写后读粘滞时长，通过 WithSticky 标记的 ctx 写入后该时长内的读请求走主库
+	// 如果为 0 则从环境变量 MYSQL_STICKY_WINDOW 读取，默认不开启
+	StickyWindow time.Duration
+	// SlowQuerySink 慢查询接收器，超过 SlowThreshold 的查询会转发给它
+	// 仅在未提供 GormConfig.Logger 时生效
+	SlowQuerySink logger.SlowQuerySink
+	// ExplainSlowQuery 是否对转发给 SlowQuerySink 的慢 SELECT 自动执行 EXPLAIN
+	ExplainSlowQuery bool
+}
+
+func init() {
+	orm.RegisterConnError(mysqldriver.ErrInvalidConn)
 }
 
 // New 创建新的 MySQL 数据库连接，使用默认配置和 sql_logger
@@ -50,11 +92,17 @@ func NewWithOptions(config *Config) (*gorm.DB, error) {
 
 	// 获取 DSN
 	dsn := config.DSN
+	if dsn == "" && config.Host != "" {
+		var err error
+		if dsn, err = config.BuildDSN(); err != nil {
+			return nil, err
+		}
+	}
 	if dsn == "" {
-		dsn = os.Getenv("MYSQL_DSN")
+		dsn = os.Getenv(config.env("DSN"))
 	}
 	if dsn == "" {
-		return nil, fmt.Errorf("mysql dsn is required, set MYSQL_DSN environment variable or provide DSN in config")
+		return nil, fmt.Errorf("mysql dsn is required, set %s environment variable or provide DSN in config", config.env("DSN"))
 	}
 
 	// 构建 GORM 配置
@@ -80,10 +128,25 @@ func NewWithOptions(config *Config) (*gorm.DB, error) {
 	}
 
 	// 配置连接池和超时设置
-	if err := configureConnectionPool(db); err != nil {
+	if err := orm.ApplyPool(db, config.pool()); err != nil {
 		return nil, fmt.Errorf("configure connection pool failed: %w", err)
 	}
 
+	// 连接错误转换为 errorx 错误码
+	if err := db.Use(orm.NewErrorPlugin()); err != nil {
+		return nil, fmt.Errorf("register error plugin failed: %w", err)
+	}
+
+	// 慢查询 EXPLAIN 需要使用已建立的连接
+	if gl, ok := gormConfig.Logger.(*logger.GormLogger); ok && gl.SlowQuerySink != nil && config.ExplainSlowQuery {
+		gl.Explain = logger.GormExplainer(db, 2)
+	}
+
+	// 配置读写分离
+	if err := configureResolver(db, config); err != nil {
+		return nil, fmt.Errorf("configure resolver failed: %w", err)
+	}
+
 	return db, nil
 }
 
@@ -116,76 +179,121 @@ func NewWithConfig(dsn string, gormConfig *gorm.Config) (*gorm.DB, error) {
 		return nil, fmt.Errorf("configure connection pool failed: %w", err)
 	}
 
+	// 连接错误转换为 errorx 错误码
+	if err := db.Use(orm.NewErrorPlugin()); err != nil {
+		return nil, fmt.Errorf("register error plugin failed: %w", err)
+	}
+
 	return db, nil
 }
 
 // buildGormLogger 根据配置构建 GORM logger
 func buildGormLogger(config *Config) gormlogger.Interface {
-	// 解析日志级别
-	var logLevel gormlogger.LogLevel
-	switch config.LogLevel {
-	case "silent":
-		logLevel = gormlogger.Silent
-	case "error":
-		logLevel = gormlogger.Error
-	case "warn":
-		logLevel = gormlogger.Warn
-	case "info":
-		logLevel = gormlogger.Info
-	default:
-		logLevel = gormlogger.Info
-	}
-
-	// 设置慢查询阈值
-	slowThreshold := config.SlowThreshold
-	if slowThreshold == 0 {
-		slowThreshold = 200 * time.Millisecond
-	}
-
-	// 创建 logger（使用 pkg/logs 包，包名是 logger）
-	gormLogger := logger.NewGormLogger(logLevel, slowThreshold)
-	// 如果用户明确设置了 IgnoreRecordNotFoundError，则使用该值
-	// 否则使用默认值 true（NewGormLogger 已设置）
-	if !config.IgnoreRecordNotFoundError {
-		gormLogger.IgnoreRecordNotFoundError = false
-	}
-
+	gormLogger := orm.NewLogger(config.LogLevel, config.SlowThreshold, config.IgnoreRecordNotFoundError)
+	gormLogger.SlowQuerySink = config.SlowQuerySink
 	return gormLogger
 }
 
-// configureConnectionPool 配置数据库连接池和超时设置
-// 从环境变量读取配置，如果没有设置则使用默认值
-func configureConnectionPool(db *gorm.DB) error {
-	sqlDB, err := db.DB()
+// BuildDSN 根据 Host、Port、User、Password、Database、Params 构建 DSN
+func (c *Config) BuildDSN() (string, error) {
+	port := c.Port
+	if port == 0 {
+		port = 3306
+	}
+
+	cfg := mysqldriver.NewConfig()
+	cfg.User = c.User
+	cfg.Passwd = c.Password
+	cfg.Net = "tcp"
+	cfg.Addr = net.JoinHostPort(c.Host, strconv.Itoa(port))
+	cfg.DBName = c.Database
+
+	params := url.Values{}
+	params.Set("charset", "utf8mb4")
+	params.Set("parseTime", "true")
+	params.Set("loc", "Local")
+	for k, v := range c.Params {
+		params.Set(k, v)
+	}
+	// 交给驱动解析参数，parseTime、loc 等驱动识别的参数会被规范化，其余参数作为连接属性保留
+	parsed, err := mysqldriver.ParseDSN(cfg.FormatDSN() + "?" + params.Encode())
 	if err != nil {
-		return err
+		return "", fmt.Errorf("build mysql dsn: %w", err)
 	}
+	return parsed.FormatDSN(), nil
+}
 
-	// 最大打开连接数（默认 100）
-	maxOpenConns := envkey.GetIntD("MYSQL_MAX_OPEN_CONNS", 100)
-	sqlDB.SetMaxOpenConns(maxOpenConns)
+// env 返回带前缀的环境变量名
+func (c *Config) env(key string) string {
+	prefix := c.EnvPrefix
+	if prefix == "" {
+		prefix = "MYSQL"
+	}
+	return prefix + "_" + key
+}
 
-	// 最大空闲连接数（默认 10）
-	maxIdleConns := envkey.GetIntD("MYSQL_MAX_IDLE_CONNS", 10)
-	sqlDB.SetMaxIdleConns(maxIdleConns)
+// pool 返回连接池配置
+func (c *Config) pool() orm.PoolConfig {
+	if c.Pool != nil {
+		return *c.Pool
+	}
+	prefix := c.EnvPrefix
+	if prefix == "" {
+		prefix = "MYSQL"
+	}
+	return orm.PoolConfigFromEnv(prefix)
+}
 
-	// 连接最大生存时间（默认 1 小时）
-	connMaxLifetimeStr := envkey.GetStringD("MYSQL_CONN_MAX_LIFETIME", "1h")
-	connMaxLifetime, err := time.ParseDuration(connMaxLifetimeStr)
-	if err != nil {
-		// 如果解析失败，使用默认值 1 小时
-		connMaxLifetime = time.Hour
+// configureResolver 根据配置和环境变量注册只读副本
+func configureResolver(db *gorm.DB, config *Config) error {
+	dsns := config.ReplicaDSNs
+	if len(dsns) == 0 {
+		dsns = envkey.GetStringSliceD(config.env("REPLICA_DSNS"), ",", nil)
+	}
+	if len(dsns) == 0 {
+		return nil
 	}
-	sqlDB.SetConnMaxLifetime(connMaxLifetime)
 
-	// 连接最大空闲时间（默认 10 分钟）
-	connMaxIdleTimeStr := envkey.GetStringD("MYSQL_CONN_MAX_IDLE_TIME", "10m")
-	connMaxIdleTime, err := time.ParseDuration(connMaxIdleTimeStr)
-	if err != nil {
-		// 如果解析失败，使用默认值 10 分钟
-		connMaxIdleTime = 10 * time.Minute
+	policy := config.ReplicaPolicy
+	if policy == "" {
+		policy = envkey.GetStringD(config.env("REPLICA_POLICY"), PolicyRandom)
+	}
+
+	stickyWindow := config.StickyWindow
+	if stickyWindow == 0 {
+		if v := os.Getenv(config.env("STICKY_WINDOW")); v != "" {
+			d, err := time.ParseDuration(v)
+			if err != nil {
+				return fmt.Errorf("invalid %s: %w", config.env("STICKY_WINDOW"), err)
+			}
+			stickyWindow = d
+		}
 	}
-	sqlDB.SetConnMaxIdleTime(connMaxIdleTime)
 
-	return nil
+	replicas := make([]gorm.Dialector, 0, len(dsns))
+	for _, dsn := range dsns {
+		replicas = append(replicas, mysql.Open(dsn))
+	}
+	return useResolver(db, replicas, policy, stickyWindow, config.pool())
+}
+
+// configureConnectionPool 配置数据库连接池和超时设置
+// 从 MYSQL_ 前缀的环境变量读取配置，如果没有设置则使用默认值
+func configureConnectionPool(db *gorm.DB) error {
+	return orm.ConfigurePool(db, "MYSQL")
+}
+
+// Register 将 config 注册为命名数据源，通过 orm.Get(name) 获取连接
+// config 为空时使用 MYSQL_<NAME> 作为环境变量前缀，如 analytics 对应 MYSQL_ANALYTICS_DSN，
+// 默认数据源 orm.DefaultDataSource 使用 MYSQL 前缀
+func Register(name string, config *Config) error {
+	if config == nil {
+		config = &Config{}
+		if name != orm.DefaultDataSource {
+			config.EnvPrefix = "MYSQL_" + strings.ToUpper(name)
+		}
+	}
+	return orm.Register(name, func() (*gorm.DB, error) {
+		return NewWithOptions(config)
+	})
 }
@@ -0,0 +1,38 @@
+package mysql
+
+import "testing"
+
+// TestBuildDSN 测试根据字段构建 DSN
+func TestBuildDSN(t *testing.T) {
+	tests := []struct {
+		name   string
+		config Config
+		want   string
+	}{
+		{
+			name:   "默认参数",
+			config: Config{Host: "127.0.0.1", User: "root", Password: "p@ss:word", Database: "app"},
+			want:   "root:p@ss:word@tcp(127.0.0.1:3306)/app?charset=utf8mb4&loc=Local&parseTime=true",
+		},
+		{
+			name: "覆盖参数",
+			config: Config{Host: "db", Port: 3307, User: "u", Database: "app", Params: map[string]string{
+				"charset":   "utf8",
+				"parseTime": "false",
+				"time_zone": "'+08:00'",
+			}},
+			want: "u@tcp(db:3307)/app?charset=utf8&loc=Local&time_zone=%27%2B08%3A00%27",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.config.BuildDSN()
+			if err != nil {
+				t.Fatalf("BuildDSN() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("BuildDSN() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
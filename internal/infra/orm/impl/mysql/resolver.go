@@ -0,0 +1,130 @@
+package mysql
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/plugin/dbresolver"
+
+	"github.com/ZampoRen/go-server-comon/internal/infra/orm"
+)
+
+const (
+	// PolicyRandom 随机选择副本
+	PolicyRandom = "random"
+	// PolicyRoundRobin 轮询选择副本
+	PolicyRoundRobin = "round_robin"
+)
+
+type stickyKey struct{}
+
+// stickyState 记录同一请求最近一次写入的时间（UnixNano）
+type stickyState struct {
+	lastWrite atomic.Int64
+}
+
+// WithSticky 为 ctx 开启写后读粘滞：使用该 ctx 写入后 Config.StickyWindow 内的读请求会走主库，
+// 避免主从延迟导致读不到刚写入的数据。通常在请求入口的中间件中调用，业务代码无需感知
+func WithSticky(ctx context.Context) context.Context {
+	if _, ok := ctx.Value(stickyKey{}).(*stickyState); ok {
+		return ctx
+	}
+	return context.WithValue(ctx, stickyKey{}, &stickyState{})
+}
+
+// resolverPolicy 将配置中的策略名转换为 dbresolver.Policy
+func resolverPolicy(name string) (dbresolver.Policy, error) {
+	switch name {
+	case "", PolicyRandom:
+		return dbresolver.RandomPolicy{}, nil
+	case PolicyRoundRobin:
+		return dbresolver.StrictRoundRobinPolicy(), nil
+	default:
+		return nil, fmt.Errorf("unknown replica policy: %s", name)
+	}
+}
+
+// useResolver 注册读写分离插件，replicas 为空时不做任何处理
+func useResolver(db *gorm.DB, replicas []gorm.Dialector, policy string, stickyWindow time.Duration, pool orm.PoolConfig) error {
+	if len(replicas) == 0 {
+		return nil
+	}
+
+	p, err := resolverPolicy(policy)
+	if err != nil {
+		return err
+	}
+
+	resolver := dbresolver.Register(dbresolver.Config{
+		Replicas: replicas,
+		Policy:   p,
+	})
+	if err := db.Use(resolver); err != nil {
+		return fmt.Errorf("register dbresolver: %w", err)
+	}
+	// 副本与主库使用相同的连接池配置
+	resolver.SetMaxOpenConns(pool.MaxOpenConns).
+		SetMaxIdleConns(pool.MaxIdleConns).
+		SetConnMaxLifetime(pool.ConnMaxLifetime).
+		SetConnMaxIdleTime(pool.ConnMaxIdleTime)
+
+	if stickyWindow > 0 {
+		return registerSticky(db, stickyWindow)
+	}
+	return nil
+}
+
+// registerSticky 注册写后读粘滞的回调：写操作后记录时间，读操作在窗口期内强制走主库
+// dbresolver 的回调已注册在最前面，因此读回调放在其后，通过 dbresolver.Write 重新选择连接
+func registerSticky(db *gorm.DB, window time.Duration) error {
+	markWrite := func(db *gorm.DB) {
+		if db.Error != nil || db.Statement.Context == nil {
+			return
+		}
+		if s, ok := db.Statement.Context.Value(stickyKey{}).(*stickyState); ok {
+			s.lastWrite.Store(time.Now().UnixNano())
+		}
+	}
+	markRawWrite := func(db *gorm.DB) {
+		if !isSelect(db.Statement.SQL.String()) {
+			markWrite(db)
+		}
+	}
+	stickRead := func(db *gorm.DB) {
+		if db.Statement.Context == nil {
+			return
+		}
+		s, ok := db.Statement.Context.Value(stickyKey{}).(*stickyState)
+		if !ok {
+			return
+		}
+		if last := s.lastWrite.Load(); last > 0 && time.Since(time.Unix(0, last)) < window {
+			dbresolver.Write.ModifyStatement(db.Statement)
+		}
+	}
+
+	cb := db.Callback()
+	for _, err := range []error{
+		cb.Create().After("*").Register("orm:sticky_write", markWrite),
+		cb.Update().After("*").Register("orm:sticky_write", markWrite),
+		cb.Delete().After("*").Register("orm:sticky_write", markWrite),
+		cb.Raw().After("*").Register("orm:sticky_write", markRawWrite),
+		cb.Query().After("gorm:db_resolver").Before("gorm:query").Register("orm:sticky_read", stickRead),
+		cb.Row().After("gorm:db_resolver").Before("gorm:row").Register("orm:sticky_read", stickRead),
+		cb.Raw().After("gorm:db_resolver").Before("gorm:raw").Register("orm:sticky_read", stickRead),
+	} {
+		if err != nil {
+			return fmt.Errorf("register sticky callback: %w", err)
+		}
+	}
+	return nil
+}
+
+func isSelect(sql string) bool {
+	sql = strings.TrimSpace(sql)
+	return len(sql) >= 6 && strings.EqualFold(sql[:6], "select")
+}
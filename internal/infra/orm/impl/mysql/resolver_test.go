@@ -0,0 +1,91 @@
+package mysql
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+
+	"github.com/ZampoRen/go-server-comon/internal/infra/orm"
+)
+
+type testUser struct {
+	ID   int64
+	Name string
+}
+
+// openResolverDB 使用两个 SQLite 文件模拟主库与副本，两者数据互不同步
+func openResolverDB(t *testing.T, window time.Duration) *gorm.DB {
+	t.Helper()
+	dir := t.TempDir()
+	primary, replica := filepath.Join(dir, "primary.db"), filepath.Join(dir, "replica.db")
+
+	cfg := &gorm.Config{Logger: gormlogger.Discard}
+	rdb, err := gorm.Open(sqlite.Open(replica), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := rdb.AutoMigrate(&testUser{}); err != nil {
+		t.Fatal(err)
+	}
+
+	db, err := gorm.Open(sqlite.Open(primary), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&testUser{}); err != nil {
+		t.Fatal(err)
+	}
+	pool := orm.PoolConfig{MaxOpenConns: 1, MaxIdleConns: 1}
+	if err := useResolver(db, []gorm.Dialector{sqlite.Open(replica)}, PolicyRoundRobin, window, pool); err != nil {
+		t.Fatalf("useResolver() error = %v", err)
+	}
+	return db
+}
+
+func countUsers(t *testing.T, db *gorm.DB) int64 {
+	t.Helper()
+	var n int64
+	if err := db.Model(&testUser{}).Count(&n).Error; err != nil {
+		t.Fatal(err)
+	}
+	return n
+}
+
+// TestResolverSticky 测试读请求默认走副本，开启粘滞的 ctx 写入后读主库
+func TestResolverSticky(t *testing.T) {
+	db := openResolverDB(t, time.Minute)
+
+	ctx := WithSticky(context.Background())
+	if got := countUsers(t, db.WithContext(ctx)); got != 0 {
+		t.Fatalf("count before write = %d, want 0", got)
+	}
+
+	if err := db.WithContext(context.Background()).Create(&testUser{Name: "alice"}).Error; err != nil {
+		t.Fatal(err)
+	}
+	if got := countUsers(t, db.WithContext(ctx)); got != 0 {
+		t.Errorf("count after write without sticky = %d, want 0 (replica)", got)
+	}
+
+	if err := db.WithContext(ctx).Create(&testUser{Name: "bob"}).Error; err != nil {
+		t.Fatal(err)
+	}
+	if got := countUsers(t, db.WithContext(ctx)); got != 2 {
+		t.Errorf("count after sticky write = %d, want 2 (primary)", got)
+	}
+	if got := countUsers(t, db); got != 0 {
+		t.Errorf("count without ctx = %d, want 0 (replica)", got)
+	}
+}
+
+// TestResolverPolicy 测试未知的负载均衡策略
+func TestResolverPolicy(t *testing.T) {
+	if _, err := resolverPolicy("least_conn"); err == nil {
+		t.Error("resolverPolicy(least_conn) error = nil, want error")
+	}
+}
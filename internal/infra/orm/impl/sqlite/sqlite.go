@@ -0,0 +1,153 @@
+// Package sqlite 提供基于 SQLite 的 GORM 连接，用于仓储层单元测试与本地开发
+// 驱动依赖 cgo（mattn/go-sqlite3），构建时需要 CGO_ENABLED=1
+package sqlite
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"github.com/ZampoRen/go-server-comon/internal/infra/orm"
+	"github.com/ZampoRen/go-server-comon/pkg/envkey"
+	logger "github.com/ZampoRen/go-server-comon/pkg/logs"
+)
+
+// memorySeq 为每个内存数据库生成独立名称，保证多次 NewMemory 之间互不影响
+var memorySeq atomic.Int64
+
+// Config GORM 配置选项
+type Config struct {
+	// Path 数据库文件路径，如果为空则从环境变量 SQLITE_PATH 读取，仍为空时使用内存数据库
+	Path string
+	// LogLevel 日志级别，可选值: silent, error, warn, info
+	// 如果为空，默认使用 info
+	LogLevel string
+	// SlowThreshold 慢查询阈值，默认 200ms
+	SlowThreshold time.Duration
+	// IgnoreRecordNotFoundError 是否忽略记录未找到错误，默认 true
+	IgnoreRecordNotFoundError bool
+	// GormConfig 自定义 GORM 配置，如果提供则优先使用此配置
+	GormConfig *gorm.Config
+}
+
+// New 创建新的 SQLite 数据库连接，使用默认配置和 sql_logger
+func New() (*gorm.DB, error) {
+	return NewWithOptions(nil)
+}
+
+// NewWithPath 使用指定的数据库文件创建连接
+func NewWithPath(path string) (*gorm.DB, error) {
+	config := &Config{
+		Path: path,
+	}
+	return NewWithOptions(config)
+}
+
+// NewMemory 创建独立的内存数据库，连接池关闭后数据即被丢弃
+func NewMemory() (*gorm.DB, error) {
+	return NewWithOptions(&Config{Path: ":memory:"})
+}
+
+// NewWithOptions 使用配置选项创建数据库连接
+func NewWithOptions(config *Config) (*gorm.DB, error) {
+	// 设置默认值
+	if config == nil {
+		config = &Config{}
+	}
+
+	path := config.Path
+	if path == "" {
+		path = os.Getenv("SQLITE_PATH")
+	}
+	memory := path == "" || path == ":memory:"
+
+	// 构建 GORM 配置
+	var gormConfig *gorm.Config
+	if config.GormConfig != nil {
+		// 使用用户提供的配置
+		gormConfig = config.GormConfig
+		// 如果用户没有设置 Logger，则使用我们的 sql_logger
+		if gormConfig.Logger == nil {
+			gormConfig.Logger = orm.NewLogger(config.LogLevel, config.SlowThreshold, config.IgnoreRecordNotFoundError)
+		}
+	} else {
+		// 使用默认配置，并设置 sql_logger
+		gormConfig = &gorm.Config{
+			Logger: orm.NewLogger(config.LogLevel, config.SlowThreshold, config.IgnoreRecordNotFoundError),
+		}
+	}
+
+	return open(buildDSN(path, memory), memory, gormConfig)
+}
+
+// NewWithConfig 使用自定义 GORM 配置创建数据库连接
+// 如果 config.Logger 为空，则使用默认的 sql_logger
+func NewWithConfig(path string, gormConfig *gorm.Config) (*gorm.DB, error) {
+	if path == "" {
+		path = os.Getenv("SQLITE_PATH")
+	}
+	memory := path == "" || path == ":memory:"
+
+	if gormConfig == nil {
+		gormConfig = &gorm.Config{}
+	}
+	if gormConfig.Logger == nil {
+		gormConfig.Logger = logger.DefaultGormLogger()
+	}
+
+	return open(buildDSN(path, memory), memory, gormConfig)
+}
+
+func open(dsn string, memory bool, gormConfig *gorm.Config) (*gorm.DB, error) {
+	db, err := gorm.Open(sqlite.Open(dsn), gormConfig)
+	if err != nil {
+		return nil, fmt.Errorf("sqlite open, dsn: %s, err: %w", dsn, err)
+	}
+
+	// 配置连接池和超时设置
+	if err := configureConnectionPool(db, memory); err != nil {
+		return nil, fmt.Errorf("configure connection pool failed: %w", err)
+	}
+
+	return db, nil
+}
+
+// buildDSN 构建连接字符串，默认开启外键约束
+// 内存数据库使用共享缓存，使连接池中的多个连接看到同一份数据；
+// 文件数据库设置 busy_timeout，避免并发写入时立即返回 database is locked
+func buildDSN(path string, memory bool) string {
+	if memory {
+		return fmt.Sprintf("file:memdb%d?mode=memory&cache=shared&_foreign_keys=1", memorySeq.Add(1))
+	}
+	if strings.Contains(path, "?") {
+		return path
+	}
+	return path + "?_foreign_keys=1&_busy_timeout=5000"
+}
+
+// configureConnectionPool 配置数据库连接池和超时设置
+// 从 SQLITE_ 前缀的环境变量读取配置，如果没有设置则使用默认值
+func configureConnectionPool(db *gorm.DB, memory bool) error {
+	if err := orm.ConfigurePool(db, "SQLITE"); err != nil {
+		return err
+	}
+	if !memory {
+		return nil
+	}
+
+	// 共享缓存的内存数据库在最后一个连接关闭时销毁，
+	// 因此需要保留至少一个空闲连接且不让连接因超时被回收
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+	sqlDB.SetMaxIdleConns(max(1, envkey.GetIntD("SQLITE_MAX_IDLE_CONNS", 10)))
+	sqlDB.SetConnMaxLifetime(0)
+	sqlDB.SetConnMaxIdleTime(0)
+	return nil
+}
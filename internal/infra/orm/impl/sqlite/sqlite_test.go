@@ -0,0 +1,74 @@
+package sqlite
+
+import (
+	"path/filepath"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+type testUser struct {
+	ID   int64
+	Name string
+}
+
+// TestNewMemory 测试内存数据库的读写与相互隔离
+func TestNewMemory(t *testing.T) {
+	db1, err := NewMemory()
+	if err != nil {
+		t.Fatalf("NewMemory() error = %v", err)
+	}
+	db2, err := NewMemory()
+	if err != nil {
+		t.Fatalf("NewMemory() error = %v", err)
+	}
+
+	if err := db1.AutoMigrate(&testUser{}); err != nil {
+		t.Fatalf("AutoMigrate() error = %v", err)
+	}
+	if err := db1.Create(&testUser{Name: "alice"}).Error; err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	var got testUser
+	if err := db1.First(&got).Error; err != nil || got.Name != "alice" {
+		t.Errorf("First() = %+v, %v, want alice", got, err)
+	}
+	if db2.Migrator().HasTable(&testUser{}) {
+		t.Errorf("HasTable() on another memory db = true, want false")
+	}
+}
+
+// TestNewWithPath 测试文件数据库在重新打开后数据仍然存在
+func TestNewWithPath(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.db")
+
+	db, err := NewWithPath(path)
+	if err != nil {
+		t.Fatalf("NewWithPath() error = %v", err)
+	}
+	if err := db.AutoMigrate(&testUser{}); err != nil {
+		t.Fatalf("AutoMigrate() error = %v", err)
+	}
+	db.Create(&testUser{Name: "bob"})
+	closeDB(t, db)
+
+	db, err = NewWithOptions(&Config{Path: path, LogLevel: "silent"})
+	if err != nil {
+		t.Fatalf("NewWithOptions() error = %v", err)
+	}
+	defer closeDB(t, db)
+	var count int64
+	if err := db.Model(&testUser{}).Count(&count).Error; err != nil || count != 1 {
+		t.Errorf("Count() = %d, %v, want 1", count, err)
+	}
+}
+
+func closeDB(t *testing.T, db *gorm.DB) {
+	t.Helper()
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = sqlDB.Close()
+}
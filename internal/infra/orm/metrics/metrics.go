@@ -0,0 +1,158 @@
+// Package metrics 提供导出连接池统计与查询耗时的 GORM 插件
+//
+//	db, _ := mysql.New()
+//	_ = db.Use(metrics.New("user", metrics.WithRegisterer(reg)))
+//
+// 导出的指标：
+//   - go_sql_*{db_name}: sql.DBStats，包括 open/idle/in_use 连接数与等待次数、等待时长
+//   - orm_query_duration_seconds{instance, operation, table}: 按操作与表统计的执行耗时
+//   - orm_query_errors_total{instance, operation, table}: 执行失败次数（不含记录未找到）
+package metrics
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/common/hlog"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"gorm.io/gorm"
+)
+
+const startKey = "orm:metrics_start"
+
+// DefaultBuckets 查询耗时直方图的默认分桶（秒）
+var DefaultBuckets = []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5}
+
+type option struct {
+	registerer prometheus.Registerer
+	buckets    []float64
+}
+
+// Option 插件选项
+type Option func(o *option)
+
+// WithRegisterer 设置指标注册器，默认 prometheus.DefaultRegisterer
+func WithRegisterer(reg prometheus.Registerer) Option {
+	return func(o *option) {
+		if reg != nil {
+			o.registerer = reg
+		}
+	}
+}
+
+// WithBuckets 设置查询耗时直方图的分桶
+func WithBuckets(buckets []float64) Option {
+	return func(o *option) {
+		if len(buckets) > 0 {
+			o.buckets = buckets
+		}
+	}
+}
+
+type plugin struct {
+	name     string
+	opt      *option
+	duration *prometheus.HistogramVec
+	errors   *prometheus.CounterVec
+}
+
+// New 创建指标插件，name 作为指标的 instance/db_name 标签区分多个数据库
+func New(name string, opts ...Option) gorm.Plugin {
+	o := &option{
+		registerer: prometheus.DefaultRegisterer,
+		buckets:    DefaultBuckets,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return &plugin{name: name, opt: o}
+}
+
+// Name 实现 gorm.Plugin
+func (p *plugin) Name() string {
+	return "orm:metrics:" + p.name
+}
+
+// Initialize 实现 gorm.Plugin，注册指标与回调
+func (p *plugin) Initialize(db *gorm.DB) error {
+	p.duration = register(p.opt.registerer, prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "orm",
+		Name:      "query_duration_seconds",
+		Help:      "Duration of ORM operations in seconds.",
+		Buckets:   p.opt.buckets,
+	}, []string{"instance", "operation", "table"}))
+	p.errors = register(p.opt.registerer, prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "orm",
+		Name:      "query_errors_total",
+		Help:      "Number of failed ORM operations.",
+	}, []string{"instance", "operation", "table"}))
+
+	if sqlDB, err := db.DB(); err == nil {
+		register(p.opt.registerer, collectors.NewDBStatsCollector(sqlDB, p.name))
+	}
+
+	cb := db.Callback()
+	for _, err := range []error{
+		cb.Create().Before("gorm:create").Register("orm:metrics_before", p.before),
+		cb.Create().After("gorm:create").Register("orm:metrics_after", p.after("create")),
+		cb.Query().Before("gorm:query").Register("orm:metrics_before", p.before),
+		cb.Query().After("gorm:query").Register("orm:metrics_after", p.after("query")),
+		cb.Update().Before("gorm:update").Register("orm:metrics_before", p.before),
+		cb.Update().After("gorm:update").Register("orm:metrics_after", p.after("update")),
+		cb.Delete().Before("gorm:delete").Register("orm:metrics_before", p.before),
+		cb.Delete().After("gorm:delete").Register("orm:metrics_after", p.after("delete")),
+		cb.Row().Before("gorm:row").Register("orm:metrics_before", p.before),
+		cb.Row().After("gorm:row").Register("orm:metrics_after", p.after("row")),
+		cb.Raw().Before("gorm:raw").Register("orm:metrics_before", p.before),
+		cb.Raw().After("gorm:raw").Register("orm:metrics_after", p.after("raw")),
+	} {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *plugin) before(db *gorm.DB) {
+	db.InstanceSet(startKey, time.Now())
+}
+
+func (p *plugin) after(operation string) func(db *gorm.DB) {
+	return func(db *gorm.DB) {
+		v, ok := db.InstanceGet(startKey)
+		if !ok {
+			return
+		}
+		start, ok := v.(time.Time)
+		if !ok {
+			return
+		}
+
+		table := db.Statement.Table
+		if table == "" {
+			table = "unknown"
+		}
+		p.duration.WithLabelValues(p.name, operation, table).Observe(time.Since(start).Seconds())
+		if db.Error != nil && !errors.Is(db.Error, gorm.ErrRecordNotFound) {
+			p.errors.WithLabelValues(p.name, operation, table).Inc()
+		}
+	}
+}
+
+// register 注册指标，已注册时复用已有的指标，使多个数据库实例共享同一组指标
+func register[C prometheus.Collector](reg prometheus.Registerer, c C) C {
+	err := reg.Register(c)
+	if err == nil {
+		return c
+	}
+	var are prometheus.AlreadyRegisteredError
+	if errors.As(err, &are) {
+		if existing, ok := are.ExistingCollector.(C); ok {
+			return existing
+		}
+	}
+	hlog.CtxWarnf(context.Background(), "[ORM] register metrics failed: %v", err)
+	return c
+}
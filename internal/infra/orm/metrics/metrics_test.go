@@ -0,0 +1,85 @@
+package metrics
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+
+	"github.com/ZampoRen/go-server-comon/internal/infra/orm/impl/sqlite"
+)
+
+type testUser struct {
+	ID   int64
+	Name string
+}
+
+// TestPlugin 测试查询耗时与连接池指标的导出
+func TestPlugin(t *testing.T) {
+	db, err := sqlite.NewWithConfig("", &gorm.Config{Logger: gormlogger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	reg := prometheus.NewRegistry()
+	if err := db.Use(New("test", WithRegisterer(reg))); err != nil {
+		t.Fatalf("Use() error = %v", err)
+	}
+
+	if err := db.AutoMigrate(&testUser{}); err != nil {
+		t.Fatal(err)
+	}
+	db.Create(&testUser{Name: "a"})
+	var u testUser
+	db.First(&u)
+	db.Table("missing").Find(&u)
+
+	mfs, err := reg.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// families 指标名 -> "operation/table/" -> 计数
+	families := make(map[string]map[string]uint64)
+	for _, mf := range mfs {
+		f := make(map[string]uint64)
+		for _, m := range mf.GetMetric() {
+			key := ""
+			for _, l := range m.GetLabel() {
+				if l.GetName() == "operation" || l.GetName() == "table" {
+					key += l.GetValue() + "/"
+				}
+			}
+			if h := m.GetHistogram(); h != nil {
+				f[key] = h.GetSampleCount()
+			} else if c := m.GetCounter(); c != nil {
+				f[key] = uint64(c.GetValue())
+			}
+		}
+		families[mf.GetName()] = f
+	}
+
+	tests := []struct {
+		name   string
+		metric string
+		key    string
+		want   uint64
+	}{
+		{"create 耗时", "orm_query_duration_seconds", "create/test_users/", 1},
+		{"query 耗时", "orm_query_duration_seconds", "query/test_users/", 1},
+		{"query 错误", "orm_query_errors_total", "query/missing/", 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, ok := families[tt.metric]
+			if !ok {
+				t.Fatalf("metric %s not found", tt.metric)
+			}
+			if got := f[tt.key]; got != tt.want {
+				t.Errorf("%s{%s} = %d, want %d", tt.metric, tt.key, got, tt.want)
+			}
+		})
+	}
+	if _, ok := families["go_sql_open_connections"]; !ok {
+		t.Error("metric go_sql_open_connections not found")
+	}
+}
@@ -0,0 +1,81 @@
+// Package model 定义各服务统一的基础模型约定
+//
+//	type User struct {
+//		model.Base
+//		Name string
+//	}
+//
+//	// 基于版本号的乐观锁更新，版本不一致时返回 errno.ErrVersionConflict
+//	err := model.Update(ctx, db, &user, map[string]any{"name": "new"})
+package model
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/ZampoRen/go-server-comon/internal/errno"
+	"github.com/ZampoRen/go-server-comon/internal/infra/orm/txmanager"
+	"github.com/ZampoRen/go-server-comon/pkg/errorx"
+)
+
+// Base 基础模型：自增主键、创建与更新时间、软删除以及乐观锁版本号
+type Base struct {
+	ID        int64          `gorm:"primaryKey;autoIncrement" json:"id"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+	Version   int64          `gorm:"not null;default:1" json:"version"`
+}
+
+// BaseModel 返回基础模型，嵌入 Base 的结构体自动实现 Versioned
+func (b *Base) BaseModel() *Base {
+	return b
+}
+
+// BeforeCreate 新建记录的版本号从 1 开始
+func (b *Base) BeforeCreate(*gorm.DB) error {
+	if b.Version == 0 {
+		b.Version = 1
+	}
+	return nil
+}
+
+// Versioned 嵌入了 Base 的模型
+type Versioned interface {
+	BaseModel() *Base
+}
+
+// Update 以 entity 当前的版本号为条件更新 fields（键为列名），并将版本号加 1
+// 成功后同步更新 entity 的 Version；记录不存在时返回 gorm.ErrRecordNotFound，
+// 版本号不一致时返回 errno.ErrVersionConflict。ctx 中存在事务时自动加入
+func Update(ctx context.Context, db *gorm.DB, entity Versioned, fields map[string]any) error {
+	base := entity.BaseModel()
+	updates := make(map[string]any, len(fields)+1)
+	for k, v := range fields {
+		updates[k] = v
+	}
+	updates["version"] = gorm.Expr("version + 1")
+
+	tx := txmanager.FromContext(ctx, db)
+	result := tx.Model(entity).Where("version = ?", base.Version).Updates(updates)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected > 0 {
+		base.Version++
+		return nil
+	}
+
+	// 未更新任何行：区分记录不存在与版本冲突
+	var count int64
+	if err := txmanager.FromContext(ctx, db).Model(entity).Where("id = ?", base.ID).Count(&count).Error; err != nil {
+		return err
+	}
+	if count == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return errorx.New(errno.ErrVersionConflict, errorx.Extra("id", strconv.FormatInt(base.ID, 10)))
+}
@@ -0,0 +1,62 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+
+	"github.com/ZampoRen/go-server-comon/internal/errno"
+	"github.com/ZampoRen/go-server-comon/internal/infra/orm/impl/sqlite"
+	"github.com/ZampoRen/go-server-comon/pkg/errorx"
+)
+
+type testUser struct {
+	Base
+	Name string
+}
+
+// TestUpdate 测试乐观锁更新、版本冲突与软删除
+func TestUpdate(t *testing.T) {
+	ctx := context.Background()
+	db, err := sqlite.NewWithConfig("", &gorm.Config{Logger: gormlogger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&testUser{}); err != nil {
+		t.Fatal(err)
+	}
+
+	u := &testUser{Name: "a"}
+	if err := db.Create(u).Error; err != nil || u.Version != 1 {
+		t.Fatalf("Create() = %v, version = %d, want 1", err, u.Version)
+	}
+	stale := *u
+
+	if err := Update(ctx, db, u, map[string]any{"name": "b"}); err != nil || u.Version != 2 {
+		t.Fatalf("Update() = %v, version = %d, want 2", err, u.Version)
+	}
+	var got testUser
+	db.First(&got, u.ID)
+	if got.Name != "b" || got.Version != 2 {
+		t.Errorf("stored = %s/%d, want b/2", got.Name, got.Version)
+	}
+
+	err = Update(ctx, db, &stale, map[string]any{"name": "c"})
+	var se errorx.StatusError
+	if !errors.As(err, &se) || se.Code() != errno.ErrVersionConflict {
+		t.Errorf("Update() with stale version error = %v, want ErrVersionConflict", err)
+	}
+
+	db.Delete(u)
+	if err := Update(ctx, db, u, map[string]any{"name": "d"}); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("Update() after soft delete error = %v, want ErrRecordNotFound", err)
+	}
+	var count int64
+	db.Unscoped().Model(&testUser{}).Count(&count)
+	if count != 1 {
+		t.Errorf("unscoped count = %d, want 1 (soft deleted)", count)
+	}
+}
@@ -0,0 +1,145 @@
+package orm
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+
+	"gorm.io/gorm"
+)
+
+// DefaultDataSource 默认数据源名称
+const DefaultDataSource = "main"
+
+var (
+	// ErrDataSourceNotFound 数据源未注册
+	ErrDataSourceNotFound = errors.New("orm: data source not found")
+	// ErrDataSourceExists 数据源已注册
+	ErrDataSourceExists = errors.New("orm: data source already registered")
+)
+
+// dataSource 已注册的数据源，首次 Get 时才建立连接
+type dataSource struct {
+	mu   sync.Mutex
+	open func() (*DB, error)
+	db   *DB
+}
+
+var registry = struct {
+	sync.RWMutex
+	sources map[string]*dataSource
+}{sources: make(map[string]*dataSource)}
+
+// Register 注册命名数据源，open 在首次 Get 时调用，失败后下次 Get 会重试
+// 各驱动提供了基于自身配置的封装，如 mysql.Register
+func Register(name string, open func() (*DB, error)) error {
+	registry.Lock()
+	defer registry.Unlock()
+	if _, ok := registry.sources[name]; ok {
+		return fmt.Errorf("%w: %s", ErrDataSourceExists, name)
+	}
+	registry.sources[name] = &dataSource{open: open}
+	return nil
+}
+
+// Get 获取命名数据源的连接
+func Get(name string) (*DB, error) {
+	registry.RLock()
+	ds, ok := registry.sources[name]
+	registry.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrDataSourceNotFound, name)
+	}
+
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+	if ds.db != nil {
+		return ds.db, nil
+	}
+	db, err := ds.open()
+	if err != nil {
+		return nil, fmt.Errorf("orm: open data source %s: %w", name, err)
+	}
+	ds.db = db
+	return db, nil
+}
+
+// MustGet 同 Get，失败时 panic
+func MustGet(name string) *DB {
+	db, err := Get(name)
+	if err != nil {
+		panic(err)
+	}
+	return db
+}
+
+// Names 返回已注册的数据源名称
+func Names() []string {
+	registry.RLock()
+	defer registry.RUnlock()
+	names := make([]string, 0, len(registry.sources))
+	for name := range registry.sources {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// CloseAll 关闭所有已建立连接的数据源并清空注册表，返回所有关闭失败的错误
+func CloseAll() error {
+	registry.Lock()
+	sources := registry.sources
+	registry.sources = make(map[string]*dataSource)
+	registry.Unlock()
+
+	var errs []error
+	for name, ds := range sources {
+		ds.mu.Lock()
+		db := ds.db
+		ds.db = nil
+		ds.mu.Unlock()
+		if db == nil {
+			continue
+		}
+		if err := closeDB(db); err != nil {
+			errs = append(errs, fmt.Errorf("orm: close data source %s: %w", name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// poolCaller 持有额外连接池的插件，如 dbresolver 的读写分离副本
+type poolCaller interface {
+	Call(fc func(connPool gorm.ConnPool) error) error
+}
+
+// closeDB 关闭主库连接池以及插件持有的副本连接池
+func closeDB(db *DB) error {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	closed := map[gorm.ConnPool]bool{sqlDB: true}
+	for _, plugin := range db.Config.Plugins {
+		caller, ok := plugin.(poolCaller)
+		if !ok {
+			continue
+		}
+		_ = caller.Call(func(pool gorm.ConnPool) error {
+			if closed[pool] {
+				return nil
+			}
+			closed[pool] = true
+			if c, ok := pool.(io.Closer); ok {
+				errs = append(errs, c.Close())
+			}
+			return nil
+		})
+	}
+	errs = append(errs, sqlDB.Close())
+	return errors.Join(errs...)
+}
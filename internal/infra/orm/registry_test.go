@@ -0,0 +1,91 @@
+package orm_test
+
+import (
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/plugin/dbresolver"
+
+	"github.com/ZampoRen/go-server-comon/internal/infra/orm"
+	ormsqlite "github.com/ZampoRen/go-server-comon/internal/infra/orm/impl/sqlite"
+)
+
+// TestRegistry 测试命名数据源的注册、惰性打开与关闭
+func TestRegistry(t *testing.T) {
+	defer orm.CloseAll()
+
+	opened := 0
+	open := func() (*gorm.DB, error) {
+		opened++
+		return ormsqlite.NewMemory()
+	}
+	if err := orm.Register(orm.DefaultDataSource, open); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	if err := orm.Register(orm.DefaultDataSource, open); !errors.Is(err, orm.ErrDataSourceExists) {
+		t.Errorf("Register() duplicate error = %v, want ErrDataSourceExists", err)
+	}
+	_ = orm.Register("analytics", open)
+	if opened != 0 {
+		t.Errorf("opened = %d after Register, want 0", opened)
+	}
+
+	main1 := orm.MustGet(orm.DefaultDataSource)
+	main2 := orm.MustGet(orm.DefaultDataSource)
+	analytics := orm.MustGet("analytics")
+	if main1 != main2 || main1 == analytics || opened != 2 {
+		t.Errorf("Get() returned unexpected instances, opened = %d", opened)
+	}
+	if _, err := orm.Get("missing"); !errors.Is(err, orm.ErrDataSourceNotFound) {
+		t.Errorf("Get() missing error = %v, want ErrDataSourceNotFound", err)
+	}
+
+	if err := orm.CloseAll(); err != nil {
+		t.Fatalf("CloseAll() error = %v", err)
+	}
+	if names := orm.Names(); len(names) != 0 {
+		t.Errorf("Names() after CloseAll = %v, want empty", names)
+	}
+}
+
+// TestCloseAllReplicas 测试 CloseAll 同时关闭读写分离的副本连接池
+func TestCloseAllReplicas(t *testing.T) {
+	defer orm.CloseAll()
+
+	dir := t.TempDir()
+	db, err := ormsqlite.NewWithPath(filepath.Join(dir, "main.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resolver := dbresolver.Register(dbresolver.Config{
+		Replicas: []gorm.Dialector{sqlite.Open(filepath.Join(dir, "replica1.db")), sqlite.Open(filepath.Join(dir, "replica2.db"))},
+	})
+	if err := db.Use(resolver); err != nil {
+		t.Fatal(err)
+	}
+	var pools []*sql.DB
+	_ = resolver.Call(func(pool gorm.ConnPool) error {
+		if sqlDB, ok := pool.(*sql.DB); ok {
+			pools = append(pools, sqlDB)
+		}
+		return nil
+	})
+	if len(pools) != 3 {
+		t.Fatalf("resolver pools = %d, want main and 2 replicas", len(pools))
+	}
+
+	_ = orm.Register(orm.DefaultDataSource, func() (*gorm.DB, error) { return db, nil })
+	orm.MustGet(orm.DefaultDataSource)
+	if err := orm.CloseAll(); err != nil {
+		t.Fatalf("CloseAll() error = %v", err)
+	}
+	for i, pool := range pools {
+		if err := pool.Ping(); err == nil {
+			t.Errorf("pool %d still open after CloseAll", i)
+		}
+	}
+}
@@ -0,0 +1,287 @@
+// Package repo 提供基于泛型的通用仓储，封装各服务重复的 CRUD 代码
+//
+//	type UserRepo struct {
+//		repo.Repository[model.User, int64]
+//	}
+//
+//	func NewUserRepo(db *gorm.DB, c localcache.Cache[*model.User]) *UserRepo {
+//		return &UserRepo{Repository: repo.New[model.User, int64](db, repo.WithCache[model.User, int64](c, "user:"))}
+//	}
+//
+// 所有方法都通过 txmanager.FromContext 获取连接，ctx 中存在事务时自动加入，
+// 此时缓存删除与写后钩子通过 txmanager.AfterCommit 推迟到事务提交后执行
+package repo
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/ZampoRen/go-server-comon/internal/infra/orm/txmanager"
+	"github.com/ZampoRen/go-server-comon/pkg/localcache"
+)
+
+// ErrNotFound 记录不存在，与 gorm.ErrRecordNotFound 相同，两者均可用于 errors.Is
+var ErrNotFound = gorm.ErrRecordNotFound
+
+const (
+	// DefaultPageSize FindPage 的默认每页条数
+	DefaultPageSize = 20
+	// MaxPageSize FindPage 的最大每页条数
+	MaxPageSize = 1000
+)
+
+// Scope 查询条件，与 gorm.DB.Scopes 的参数一致
+type Scope = func(db *gorm.DB) *gorm.DB
+
+// Repository 通用仓储，T 为模型类型，ID 为主键类型
+type Repository[T any, ID comparable] interface {
+	// Create 创建记录，主键回填到 entity
+	Create(ctx context.Context, entity *T) error
+	// BatchCreate 按 batchSize 分批创建记录，batchSize <= 0 时一次性创建
+	BatchCreate(ctx context.Context, entities []*T, batchSize int) error
+	// GetByID 按主键查询，不存在时返回 ErrNotFound
+	GetByID(ctx context.Context, id ID) (*T, error)
+	// GetByIDs 按主键批量查询，不存在的主键会被忽略，结果顺序不保证与 ids 一致
+	GetByIDs(ctx context.Context, ids []ID) ([]*T, error)
+	// UpdateFields 按主键更新指定字段，fields 的键为列名
+	UpdateFields(ctx context.Context, id ID, fields map[string]any) error
+	// Delete 按主键删除，模型包含 gorm.DeletedAt 时为软删除
+	Delete(ctx context.Context, id ID) error
+	// BatchDelete 按主键批量删除
+	BatchDelete(ctx context.Context, ids []ID) error
+	// FindPage 分页查询，page 从 1 开始，返回当前页记录与总数
+	FindPage(ctx context.Context, page, size int, scopes ...Scope) ([]*T, int64, error)
+	// DB 返回绑定了 ctx（及其事务）的连接，用于编写自定义查询
+	DB(ctx context.Context) *gorm.DB
+}
+
+type option[T any, ID comparable] struct {
+	cache      localcache.Cache[*T]
+	keyPrefix  string
+	afterWrite []func(ctx context.Context, ids ...ID)
+}
+
+// Option 仓储选项
+type Option[T any, ID comparable] func(o *option[T, ID])
+
+// WithCache 为 GetByID 启用本地缓存，键为 keyPrefix + 主键
+// 通过本仓储执行的写操作会删除对应主键的缓存，在事务中时于提交后删除
+func WithCache[T any, ID comparable](c localcache.Cache[*T], keyPrefix string) Option[T, ID] {
+	return func(o *option[T, ID]) {
+		o.cache = c
+		o.keyPrefix = keyPrefix
+	}
+}
+
+// WithAfterWrite 注册写操作成功后的钩子，参数为受影响的主键，可用于删除 Redis 等外部缓存
+// 写操作在事务中时钩子于提交后执行，事务回滚时不执行
+func WithAfterWrite[T any, ID comparable](fn func(ctx context.Context, ids ...ID)) Option[T, ID] {
+	return func(o *option[T, ID]) {
+		o.afterWrite = append(o.afterWrite, fn)
+	}
+}
+
+type repository[T any, ID comparable] struct {
+	db  *gorm.DB
+	opt *option[T, ID]
+}
+
+// New 创建通用仓储
+func New[T any, ID comparable](db *gorm.DB, opts ...Option[T, ID]) Repository[T, ID] {
+	o := &option[T, ID]{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return &repository[T, ID]{db: db, opt: o}
+}
+
+// DB 返回绑定了 ctx（及其事务）的连接
+func (r *repository[T, ID]) DB(ctx context.Context) *gorm.DB {
+	return txmanager.FromContext(ctx, r.db)
+}
+
+// Create 创建记录
+func (r *repository[T, ID]) Create(ctx context.Context, entity *T) error {
+	if err := r.DB(ctx).Create(entity).Error; err != nil {
+		return err
+	}
+	r.invalidate(ctx, r.idsOf(ctx, entity)...)
+	return nil
+}
+
+// BatchCreate 分批创建记录
+func (r *repository[T, ID]) BatchCreate(ctx context.Context, entities []*T, batchSize int) error {
+	if len(entities) == 0 {
+		return nil
+	}
+	if batchSize <= 0 {
+		batchSize = len(entities)
+	}
+	if err := r.DB(ctx).CreateInBatches(entities, batchSize).Error; err != nil {
+		return err
+	}
+	r.invalidate(ctx, r.idsOf(ctx, entities...)...)
+	return nil
+}
+
+// GetByID 按主键查询
+func (r *repository[T, ID]) GetByID(ctx context.Context, id ID) (*T, error) {
+	fetch := func(ctx context.Context) (*T, error) {
+		entity := new(T)
+		if err := r.DB(ctx).Where(r.pkEq(id)).Take(entity).Error; err != nil {
+			return nil, err
+		}
+		return entity, nil
+	}
+	// 事务内的读取可能看到未提交的数据，不能写入缓存
+	if r.opt.cache == nil || txmanager.InTx(ctx) {
+		return fetch(ctx)
+	}
+
+	entity, err := r.opt.cache.Get(ctx, r.key(id), fetch)
+	if err != nil {
+		return nil, err
+	}
+	// 返回副本，避免调用方修改缓存中的实例
+	cp := *entity
+	return &cp, nil
+}
+
+// GetByIDs 按主键批量查询
+func (r *repository[T, ID]) GetByIDs(ctx context.Context, ids []ID) ([]*T, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	var entities []*T
+	if err := r.DB(ctx).Where(r.pkIn(ids)).Find(&entities).Error; err != nil {
+		return nil, err
+	}
+	return entities, nil
+}
+
+// UpdateFields 按主键更新指定字段
+func (r *repository[T, ID]) UpdateFields(ctx context.Context, id ID, fields map[string]any) error {
+	if len(fields) == 0 {
+		return nil
+	}
+	if err := r.DB(ctx).Model(new(T)).Where(r.pkEq(id)).Updates(fields).Error; err != nil {
+		return err
+	}
+	r.invalidate(ctx, id)
+	return nil
+}
+
+// Delete 按主键删除
+func (r *repository[T, ID]) Delete(ctx context.Context, id ID) error {
+	if err := r.DB(ctx).Where(r.pkEq(id)).Delete(new(T)).Error; err != nil {
+		return err
+	}
+	r.invalidate(ctx, id)
+	return nil
+}
+
+// BatchDelete 按主键批量删除
+func (r *repository[T, ID]) BatchDelete(ctx context.Context, ids []ID) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	if err := r.DB(ctx).Where(r.pkIn(ids)).Delete(new(T)).Error; err != nil {
+		return err
+	}
+	r.invalidate(ctx, ids...)
+	return nil
+}
+
+// FindPage 分页查询
+func (r *repository[T, ID]) FindPage(ctx context.Context, page, size int, scopes ...Scope) ([]*T, int64, error) {
+	if page < 1 {
+		page = 1
+	}
+	if size <= 0 {
+		size = DefaultPageSize
+	}
+	if size > MaxPageSize {
+		size = MaxPageSize
+	}
+
+	db := r.DB(ctx).Model(new(T)).Scopes(scopes...)
+	var total int64
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var entities []*T
+	if total == 0 {
+		return entities, 0, nil
+	}
+	if err := db.Offset((page - 1) * size).Limit(size).Find(&entities).Error; err != nil {
+		return nil, 0, err
+	}
+	return entities, total, nil
+}
+
+// pkEq 构建主键等值条件
+func (r *repository[T, ID]) pkEq(id ID) clause.Expression {
+	return clause.Eq{Column: clause.PrimaryColumn, Value: id}
+}
+
+// pkIn 构建主键 IN 条件
+func (r *repository[T, ID]) pkIn(ids []ID) clause.Expression {
+	values := make([]any, len(ids))
+	for i, id := range ids {
+		values[i] = id
+	}
+	return clause.IN{Column: clause.PrimaryColumn, Values: values}
+}
+
+func (r *repository[T, ID]) key(id ID) string {
+	return fmt.Sprintf("%s%v", r.opt.keyPrefix, id)
+}
+
+// idsOf 通过 gorm 的模型解析取出实体的主键，未能解析时忽略
+func (r *repository[T, ID]) idsOf(ctx context.Context, entities ...*T) []ID {
+	if r.opt.cache == nil && len(r.opt.afterWrite) == 0 {
+		return nil
+	}
+	stmt := &gorm.Statement{DB: r.db}
+	if err := stmt.Parse(new(T)); err != nil || stmt.Schema.PrioritizedPrimaryField == nil {
+		return nil
+	}
+	field := stmt.Schema.PrioritizedPrimaryField
+
+	ids := make([]ID, 0, len(entities))
+	for _, entity := range entities {
+		v, zero := field.ValueOf(ctx, reflect.ValueOf(entity).Elem())
+		if id, ok := v.(ID); ok && !zero {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// invalidate 删除本地缓存并执行写后钩子，在事务中时推迟到提交后，
+// 避免提交前并发的 GetByID 把旧数据重新写入缓存
+func (r *repository[T, ID]) invalidate(ctx context.Context, ids ...ID) {
+	if len(ids) == 0 {
+		return
+	}
+	txmanager.AfterCommit(ctx, func(ctx context.Context) {
+		r.invalidateNow(ctx, ids...)
+	})
+}
+
+func (r *repository[T, ID]) invalidateNow(ctx context.Context, ids ...ID) {
+	if r.opt.cache != nil {
+		keys := make([]string, len(ids))
+		for i, id := range ids {
+			keys[i] = r.key(id)
+		}
+		r.opt.cache.Del(ctx, keys...)
+	}
+	for _, fn := range r.opt.afterWrite {
+		fn(ctx, ids...)
+	}
+}
@@ -0,0 +1,141 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+
+	"github.com/ZampoRen/go-server-comon/internal/infra/orm/impl/sqlite"
+	"github.com/ZampoRen/go-server-comon/internal/infra/orm/txmanager"
+	"github.com/ZampoRen/go-server-comon/pkg/localcache"
+)
+
+type testUser struct {
+	ID   int64
+	Name string
+	Age  int
+}
+
+func newRepo(t *testing.T, opts ...Option[testUser, int64]) Repository[testUser, int64] {
+	t.Helper()
+	db, err := sqlite.NewWithConfig("", &gorm.Config{Logger: gormlogger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&testUser{}); err != nil {
+		t.Fatal(err)
+	}
+	return New[testUser, int64](db, opts...)
+}
+
+// TestRepository 测试基本的增删改查与分页
+func TestRepository(t *testing.T) {
+	ctx := context.Background()
+	r := newRepo(t)
+
+	u := &testUser{Name: "a", Age: 1}
+	if err := r.Create(ctx, u); err != nil || u.ID == 0 {
+		t.Fatalf("Create() = %v, id = %d", err, u.ID)
+	}
+	batch := []*testUser{{Name: "b", Age: 2}, {Name: "c", Age: 3}, {Name: "d", Age: 4}}
+	if err := r.BatchCreate(ctx, batch, 2); err != nil {
+		t.Fatalf("BatchCreate() error = %v", err)
+	}
+
+	if err := r.UpdateFields(ctx, u.ID, map[string]any{"age": 10}); err != nil {
+		t.Fatalf("UpdateFields() error = %v", err)
+	}
+	if got, err := r.GetByID(ctx, u.ID); err != nil || got.Age != 10 {
+		t.Errorf("GetByID() = %+v, %v, want age 10", got, err)
+	}
+
+	items, total, err := r.FindPage(ctx, 2, 3, func(db *gorm.DB) *gorm.DB { return db.Order("id") })
+	if err != nil || total != 4 || len(items) != 1 || items[0].Name != "d" {
+		t.Errorf("FindPage() = %d items, total %d, %v, want [d] of 4", len(items), total, err)
+	}
+
+	if err := r.BatchDelete(ctx, []int64{batch[0].ID, batch[1].ID}); err != nil {
+		t.Fatalf("BatchDelete() error = %v", err)
+	}
+	if err := r.Delete(ctx, u.ID); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if _, err := r.GetByID(ctx, u.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("GetByID() after delete error = %v, want ErrNotFound", err)
+	}
+	if got, _ := r.GetByIDs(ctx, []int64{u.ID, batch[2].ID}); len(got) != 1 {
+		t.Errorf("GetByIDs() = %d items, want 1", len(got))
+	}
+}
+
+// TestRepositoryCache 测试写操作删除缓存并触发写后钩子
+func TestRepositoryCache(t *testing.T) {
+	ctx := context.Background()
+	c := localcache.New[*testUser](localcache.WithLocalSlotNum(1), localcache.WithLocalSlotSize(10), localcache.WithLinkDisable())
+	defer c.Stop()
+
+	var written []int64
+	r := newRepo(t,
+		WithCache[testUser, int64](c, "user:"),
+		WithAfterWrite[testUser, int64](func(ctx context.Context, ids ...int64) {
+			written = append(written, ids...)
+		}),
+	)
+
+	u := &testUser{Name: "a"}
+	_ = r.Create(ctx, u)
+	got, _ := r.GetByID(ctx, u.ID)
+	got.Name = "modified"
+
+	// 绕过仓储直接修改，缓存仍返回旧值
+	r.DB(ctx).Model(&testUser{}).Where("id = ?", u.ID).Update("name", "b")
+	if got, _ := r.GetByID(ctx, u.ID); got.Name != "a" {
+		t.Errorf("GetByID() from cache = %q, want %q", got.Name, "a")
+	}
+
+	_ = r.UpdateFields(ctx, u.ID, map[string]any{"name": "c"})
+	if got, _ := r.GetByID(ctx, u.ID); got.Name != "c" {
+		t.Errorf("GetByID() after UpdateFields = %q, want %q", got.Name, "c")
+	}
+	if len(written) != 2 || written[0] != u.ID || written[1] != u.ID {
+		t.Errorf("afterWrite ids = %v, want [%d %d]", written, u.ID, u.ID)
+	}
+
+	t.Run("事务提交后删除缓存", func(t *testing.T) {
+		written = nil
+		tm := txmanager.New(r.DB(ctx))
+		err := tm.Tx(ctx, func(ctx context.Context) error {
+			if err := r.UpdateFields(ctx, u.ID, map[string]any{"name": "d"}); err != nil {
+				return err
+			}
+			if len(written) != 0 {
+				t.Errorf("afterWrite called before commit: %v", written)
+			}
+			return nil
+		})
+		if err != nil || len(written) != 1 {
+			t.Fatalf("Tx() = %v, afterWrite ids = %v", err, written)
+		}
+		if got, _ := r.GetByID(ctx, u.ID); got.Name != "d" {
+			t.Errorf("GetByID() after commit = %q, want %q", got.Name, "d")
+		}
+	})
+
+	t.Run("事务回滚时不执行钩子", func(t *testing.T) {
+		written = nil
+		tm := txmanager.New(r.DB(ctx))
+		_ = tm.Tx(ctx, func(ctx context.Context) error {
+			_ = r.UpdateFields(ctx, u.ID, map[string]any{"name": "e"})
+			return errors.New("rollback")
+		})
+		if len(written) != 0 {
+			t.Fatalf("afterWrite ids = %v, want none", written)
+		}
+		if got, _ := r.GetByID(ctx, u.ID); got.Name != "d" {
+			t.Errorf("GetByID() after rollback = %q, want %q", got.Name, "d")
+		}
+	})
+}
@@ -0,0 +1,138 @@
+// Package tracing 提供为每条 SQL 语句创建 span 的 GORM 插件
+//
+//	db, _ := mysql.New()
+//	_ = db.Use(tracing.New("user"))
+//
+//	db.WithContext(ctx).First(&user, id)
+//
+// span 挂在 ctx 中的当前 span 下，只有通过 WithContext 传入请求的 ctx 时才能与上游链路关联。
+// 只记录带占位符的 SQL，不记录参数值，避免把业务数据写入链路数据
+package tracing
+
+import (
+	"errors"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+const (
+	tracerName = "github.com/ZampoRen/go-server-comon/internal/infra/orm/tracing"
+	spanKey    = "orm:tracing_span"
+)
+
+type option struct {
+	tracerProvider trace.TracerProvider
+	statement      bool
+}
+
+// Option 插件选项
+type Option func(o *option)
+
+// WithTracerProvider 设置 TracerProvider，默认使用全局 TracerProvider
+func WithTracerProvider(tp trace.TracerProvider) Option {
+	return func(o *option) {
+		if tp != nil {
+			o.tracerProvider = tp
+		}
+	}
+}
+
+// WithoutStatement 不记录 SQL 语句，只记录操作类型与表名
+func WithoutStatement() Option {
+	return func(o *option) {
+		o.statement = false
+	}
+}
+
+type plugin struct {
+	name   string
+	opt    *option
+	tracer trace.Tracer
+}
+
+// New 创建链路追踪插件，name 作为 span 的 db.instance 属性区分多个数据库
+func New(name string, opts ...Option) gorm.Plugin {
+	o := &option{statement: true}
+	for _, opt := range opts {
+		opt(o)
+	}
+	if o.tracerProvider == nil {
+		o.tracerProvider = otel.GetTracerProvider()
+	}
+	return &plugin{name: name, opt: o, tracer: o.tracerProvider.Tracer(tracerName)}
+}
+
+// Name 实现 gorm.Plugin
+func (p *plugin) Name() string {
+	return "orm:tracing:" + p.name
+}
+
+// Initialize 实现 gorm.Plugin，注册回调
+func (p *plugin) Initialize(db *gorm.DB) error {
+	cb := db.Callback()
+	for _, err := range []error{
+		cb.Create().Before("gorm:create").Register("orm:tracing_before", p.before("create")),
+		cb.Create().After("gorm:create").Register("orm:tracing_after", p.after),
+		cb.Query().Before("gorm:query").Register("orm:tracing_before", p.before("query")),
+		cb.Query().After("gorm:query").Register("orm:tracing_after", p.after),
+		cb.Update().Before("gorm:update").Register("orm:tracing_before", p.before("update")),
+		cb.Update().After("gorm:update").Register("orm:tracing_after", p.after),
+		cb.Delete().Before("gorm:delete").Register("orm:tracing_before", p.before("delete")),
+		cb.Delete().After("gorm:delete").Register("orm:tracing_after", p.after),
+		cb.Row().Before("gorm:row").Register("orm:tracing_before", p.before("row")),
+		cb.Row().After("gorm:row").Register("orm:tracing_after", p.after),
+		cb.Raw().Before("gorm:raw").Register("orm:tracing_before", p.before("raw")),
+		cb.Raw().After("gorm:raw").Register("orm:tracing_after", p.after),
+	} {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *plugin) before(operation string) func(db *gorm.DB) {
+	return func(db *gorm.DB) {
+		if db.Statement.Context == nil {
+			return
+		}
+		ctx, span := p.tracer.Start(db.Statement.Context, "orm."+operation,
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(
+				attribute.String("db.system", db.Dialector.Name()),
+				attribute.String("db.instance", p.name),
+				attribute.String("db.operation", operation),
+			),
+		)
+		db.Statement.Context = ctx
+		db.InstanceSet(spanKey, span)
+	}
+}
+
+func (p *plugin) after(db *gorm.DB) {
+	v, ok := db.InstanceGet(spanKey)
+	if !ok {
+		return
+	}
+	span, ok := v.(trace.Span)
+	if !ok {
+		return
+	}
+	defer span.End()
+
+	if db.Statement.Table != "" {
+		span.SetAttributes(attribute.String("db.sql.table", db.Statement.Table))
+	}
+	if p.opt.statement {
+		span.SetAttributes(attribute.String("db.statement", db.Statement.SQL.String()))
+	}
+	span.SetAttributes(attribute.Int64("db.rows_affected", db.RowsAffected))
+	if db.Error != nil && !errors.Is(db.Error, gorm.ErrRecordNotFound) {
+		span.RecordError(db.Error)
+		span.SetStatus(codes.Error, db.Error.Error())
+	}
+}
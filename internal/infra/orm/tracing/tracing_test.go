@@ -0,0 +1,62 @@
+package tracing
+
+import (
+	"context"
+	"testing"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+
+	"github.com/ZampoRen/go-server-comon/internal/infra/orm/impl/sqlite"
+)
+
+type testUser struct {
+	ID   int64
+	Name string
+}
+
+// TestPlugin 测试语句 span 挂在调用方 span 下
+func TestPlugin(t *testing.T) {
+	db, err := sqlite.NewWithConfig("", &gorm.Config{Logger: gormlogger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&testUser{}); err != nil {
+		t.Fatal(err)
+	}
+
+	sr := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(sr))
+	if err := db.Use(New("test", WithTracerProvider(tp))); err != nil {
+		t.Fatalf("Use() error = %v", err)
+	}
+
+	ctx, parent := tp.Tracer("test").Start(context.Background(), "parent")
+	db.WithContext(ctx).Create(&testUser{Name: "a"})
+	var u testUser
+	db.WithContext(ctx).Where("name = ?", "secret").First(&u)
+	parent.End()
+
+	spans := sr.Ended()
+	if len(spans) != 3 {
+		t.Fatalf("got %d spans, want 3", len(spans))
+	}
+	for _, s := range spans[:2] {
+		if s.Parent().SpanID() != parent.SpanContext().SpanID() {
+			t.Errorf("span %s parent = %v, want %v", s.Name(), s.Parent().SpanID(), parent.SpanContext().SpanID())
+		}
+	}
+	if spans[1].Name() != "orm.query" {
+		t.Errorf("name = %q, want orm.query", spans[1].Name())
+	}
+	for _, attr := range spans[1].Attributes() {
+		if attr.Key == "db.statement" && attr.Value.AsString() == "" {
+			t.Error("db.statement is empty")
+		}
+		if attr.Key == "db.sql.table" && attr.Value.AsString() != "test_users" {
+			t.Errorf("table = %q", attr.Value.AsString())
+		}
+	}
+}
@@ -0,0 +1,109 @@
+// Package txmanager 提供基于 context 传播的事务管理
+//
+// 服务层开启事务：
+//
+//	err := tm.Tx(ctx, func(ctx context.Context) error {
+//		if err := userRepo.Create(ctx, u); err != nil {
+//			return err
+//		}
+//		return auditRepo.Create(ctx, log)
+//	})
+//
+// 仓储层通过 FromContext 获取连接，存在外层事务时自动加入：
+//
+//	func (r *userRepo) Create(ctx context.Context, u *User) error {
+//		return txmanager.FromContext(ctx, r.db).Create(u).Error
+//	}
+//
+// 删除缓存、发送事件等依赖提交结果的操作通过 AfterCommit 注册，在最外层事务提交后执行
+package txmanager
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+
+	"gorm.io/gorm"
+)
+
+type txKey struct{}
+
+// txState ctx 中的事务及提交后执行的钩子，嵌套事务的钩子在内层成功后并入外层
+type txState struct {
+	tx     *gorm.DB
+	parent *txState
+
+	mu    sync.Mutex
+	hooks []func(ctx context.Context)
+}
+
+func (s *txState) add(hooks ...func(ctx context.Context)) {
+	s.mu.Lock()
+	s.hooks = append(s.hooks, hooks...)
+	s.mu.Unlock()
+}
+
+// Manager 事务管理器
+type Manager interface {
+	// Tx 在事务中执行 fn，fn 返回错误或 panic 时回滚，否则提交
+	// fn 收到的 ctx 携带事务，ctx 中已存在事务时通过 SavePoint 嵌套执行，
+	// 内层回滚只撤销内层的修改，最终由最外层统一提交
+	Tx(ctx context.Context, fn func(ctx context.Context) error, opts ...*sql.TxOptions) error
+}
+
+type manager struct {
+	db *gorm.DB
+}
+
+// New 创建事务管理器
+func New(db *gorm.DB) Manager {
+	return &manager{db: db}
+}
+
+// Tx 在事务中执行 fn
+func (m *manager) Tx(ctx context.Context, fn func(ctx context.Context) error, opts ...*sql.TxOptions) error {
+	parent, _ := ctx.Value(txKey{}).(*txState)
+	state := &txState{parent: parent}
+	err := FromContext(ctx, m.db).Transaction(func(tx *gorm.DB) error {
+		state.tx = tx
+		return fn(context.WithValue(ctx, txKey{}, state))
+	}, opts...)
+	if err != nil {
+		return err
+	}
+
+	// 内层事务的修改要等最外层提交后才可见，钩子并入外层；外层回滚时一并丢弃
+	if parent != nil {
+		parent.add(state.hooks...)
+		return nil
+	}
+	for _, hook := range state.hooks {
+		hook(ctx)
+	}
+	return nil
+}
+
+// AfterCommit 注册在 ctx 中的事务提交后执行的钩子，事务回滚时不执行；
+// ctx 中不存在事务时立即执行。钩子收到的 ctx 为开启最外层事务时的 ctx，不携带事务
+func AfterCommit(ctx context.Context, hook func(ctx context.Context)) {
+	state, ok := ctx.Value(txKey{}).(*txState)
+	if !ok {
+		hook(ctx)
+		return
+	}
+	state.add(hook)
+}
+
+// FromContext 返回 ctx 中的事务，不存在时返回绑定了 ctx 的 db
+func FromContext(ctx context.Context, db *gorm.DB) *gorm.DB {
+	if state, ok := ctx.Value(txKey{}).(*txState); ok {
+		return state.tx.WithContext(ctx)
+	}
+	return db.WithContext(ctx)
+}
+
+// InTx 判断 ctx 中是否存在事务
+func InTx(ctx context.Context) bool {
+	_, ok := ctx.Value(txKey{}).(*txState)
+	return ok
+}
@@ -0,0 +1,175 @@
+package txmanager
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+
+	"github.com/ZampoRen/go-server-comon/internal/infra/orm/impl/sqlite"
+)
+
+type testUser struct {
+	ID   int64
+	Name string
+}
+
+func newDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := sqlite.NewWithConfig("", &gorm.Config{Logger: gormlogger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&testUser{}); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func create(ctx context.Context, db *gorm.DB, name string) error {
+	return FromContext(ctx, db).Create(&testUser{Name: name}).Error
+}
+
+func names(t *testing.T, db *gorm.DB) []string {
+	t.Helper()
+	var got []string
+	if err := db.Model(&testUser{}).Order("id").Pluck("name", &got).Error; err != nil {
+		t.Fatal(err)
+	}
+	return got
+}
+
+// TestTx 测试提交、回滚与嵌套事务
+func TestTx(t *testing.T) {
+	errRollback := errors.New("rollback")
+
+	tests := []struct {
+		name string
+		fn   func(ctx context.Context, tm Manager, db *gorm.DB) error
+		want []string
+	}{
+		{
+			name: "提交",
+			fn: func(ctx context.Context, tm Manager, db *gorm.DB) error {
+				return tm.Tx(ctx, func(ctx context.Context) error {
+					if !InTx(ctx) {
+						return errors.New("ctx not in tx")
+					}
+					return create(ctx, db, "a")
+				})
+			},
+			want: []string{"a"},
+		},
+		{
+			name: "返回错误时回滚",
+			fn: func(ctx context.Context, tm Manager, db *gorm.DB) error {
+				return tm.Tx(ctx, func(ctx context.Context) error {
+					_ = create(ctx, db, "a")
+					return errRollback
+				})
+			},
+		},
+		{
+			name: "内层回滚不影响外层",
+			fn: func(ctx context.Context, tm Manager, db *gorm.DB) error {
+				return tm.Tx(ctx, func(ctx context.Context) error {
+					_ = create(ctx, db, "outer")
+					_ = tm.Tx(ctx, func(ctx context.Context) error {
+						_ = create(ctx, db, "inner")
+						return errRollback
+					})
+					return nil
+				})
+			},
+			want: []string{"outer"},
+		},
+		{
+			name: "外层回滚撤销内层",
+			fn: func(ctx context.Context, tm Manager, db *gorm.DB) error {
+				return tm.Tx(ctx, func(ctx context.Context) error {
+					if err := tm.Tx(ctx, func(ctx context.Context) error {
+						return create(ctx, db, "inner")
+					}); err != nil {
+						return err
+					}
+					return errRollback
+				})
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := newDB(t)
+			_ = tt.fn(context.Background(), New(db), db)
+			got := names(t, db)
+			if len(got) != len(tt.want) {
+				t.Fatalf("names = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("names = %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+// TestAfterCommit 测试提交后钩子只在最外层事务提交后执行
+func TestAfterCommit(t *testing.T) {
+	errRollback := errors.New("rollback")
+	db := newDB(t)
+	tm := New(db)
+	ctx := context.Background()
+
+	t.Run("无事务时立即执行", func(t *testing.T) {
+		called := false
+		AfterCommit(ctx, func(context.Context) { called = true })
+		if !called {
+			t.Fatal("hook not called")
+		}
+	})
+
+	t.Run("提交后执行", func(t *testing.T) {
+		var got []string
+		err := tm.Tx(ctx, func(ctx context.Context) error {
+			AfterCommit(ctx, func(ctx context.Context) {
+				if InTx(ctx) {
+					t.Error("hook ctx should not carry tx")
+				}
+				got = append(got, "outer")
+			})
+			_ = tm.Tx(ctx, func(ctx context.Context) error {
+				AfterCommit(ctx, func(context.Context) { got = append(got, "inner") })
+				return nil
+			})
+			_ = tm.Tx(ctx, func(ctx context.Context) error {
+				AfterCommit(ctx, func(context.Context) { got = append(got, "rolled back") })
+				return errRollback
+			})
+			if len(got) != 0 {
+				t.Errorf("hooks called before commit: %v", got)
+			}
+			return nil
+		})
+		if err != nil || len(got) != 2 || got[0] != "outer" || got[1] != "inner" {
+			t.Fatalf("hooks = %v, err = %v", got, err)
+		}
+	})
+
+	t.Run("回滚时不执行", func(t *testing.T) {
+		called := false
+		_ = tm.Tx(ctx, func(ctx context.Context) error {
+			_ = tm.Tx(ctx, func(ctx context.Context) error {
+				AfterCommit(ctx, func(context.Context) { called = true })
+				return nil
+			})
+			return errRollback
+		})
+		if called {
+			t.Fatal("hook called after rollback")
+		}
+	})
+}
@@ -0,0 +1,86 @@
+package provider
+
+import (
+	"context"
+	"io"
+
+	"github.com/ZampoRen/go-server-comon/internal/infra/cache"
+	"github.com/ZampoRen/go-server-comon/internal/infra/cache/impl/redis"
+	"github.com/ZampoRen/go-server-comon/internal/infra/es"
+	esimpl "github.com/ZampoRen/go-server-comon/internal/infra/es/impl/es"
+	"github.com/ZampoRen/go-server-comon/internal/infra/mq"
+	mqimpl "github.com/ZampoRen/go-server-comon/internal/infra/mq/impl"
+	"github.com/ZampoRen/go-server-comon/internal/infra/orm"
+	"github.com/ZampoRen/go-server-comon/internal/infra/orm/impl/mysql"
+	"github.com/ZampoRen/go-server-comon/internal/infra/orm/txmanager"
+	"github.com/ZampoRen/go-server-comon/internal/infra/storage"
+	storageimpl "github.com/ZampoRen/go-server-comon/internal/infra/storage/impl"
+	"github.com/ZampoRen/go-server-comon/pkg/di"
+)
+
+// Register 向容器注册基础设施的默认构造函数
+// 各构造函数沿用原有的环境变量配置，只有在被 Invoke 时才会真正建立连接：
+//   - *orm.DB: mysql.New，停止时关闭连接池
+//   - txmanager.Manager: 基于 *orm.DB 的事务管理器
+//   - cache.Cmdable: redis.New
+//   - es.Client: es.New
+//   - storage.Storage: storage.New
+//   - mq.Producer: mq.NewProducer，按 MQ_TYPE 选择实现，停止时关闭；消费者按消费组创建，不在容器中注册
+//
+// 需要自定义时可在 Register 之后再次 di.Provide 覆盖对应类型
+func Register(c *di.Container) error {
+	if err := di.Provide(c, func(ctx context.Context, c *di.Container) (*orm.DB, error) {
+		return mysql.New()
+	}, di.WithName[*orm.DB]("mysql"), di.OnStop(closeDB)); err != nil {
+		return err
+	}
+
+	if err := di.Provide(c, func(ctx context.Context, c *di.Container) (txmanager.Manager, error) {
+		db, err := di.Invoke[*orm.DB](ctx, c)
+		if err != nil {
+			return nil, err
+		}
+		return txmanager.New(db), nil
+	}); err != nil {
+		return err
+	}
+
+	if err := di.Provide(c, func(ctx context.Context, c *di.Container) (cache.Cmdable, error) {
+		return redis.New(), nil
+	}, di.WithName[cache.Cmdable]("redis"), di.OnStop(closeIfCloser[cache.Cmdable])); err != nil {
+		return err
+	}
+
+	if err := di.Provide(c, func(ctx context.Context, c *di.Container) (es.Client, error) {
+		return esimpl.New()
+	}, di.WithName[es.Client]("es")); err != nil {
+		return err
+	}
+
+	if err := di.Provide(c, func(ctx context.Context, c *di.Container) (storage.Storage, error) {
+		return storageimpl.New(ctx)
+	}, di.WithName[storage.Storage]("storage")); err != nil {
+		return err
+	}
+
+	return di.Provide(c, func(ctx context.Context, c *di.Container) (mq.Producer, error) {
+		return mqimpl.NewProducer(ctx)
+	}, di.WithName[mq.Producer]("mq"), di.OnStop(closeIfCloser[mq.Producer]))
+}
+
+// closeDB 关闭 gorm 底层连接池
+func closeDB(_ context.Context, db *orm.DB) error {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.Close()
+}
+
+// closeIfCloser 实现了 io.Closer 的实例在停止时关闭
+func closeIfCloser[T any](_ context.Context, v T) error {
+	if closer, ok := any(v).(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
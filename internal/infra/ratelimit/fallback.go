@@ -0,0 +1,60 @@
+package ratelimit
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/common/hlog"
+)
+
+// FallbackOption 降级限流器选项
+type FallbackOption func(o *fallbackLimiter)
+
+// WithRetryInterval 设置主限流器出错后改用降级限流器的时长，期间不再访问主限流器，默认 5s
+func WithRetryInterval(d time.Duration) FallbackOption {
+	return func(o *fallbackLimiter) {
+		if d > 0 {
+			o.retryInterval = d
+		}
+	}
+}
+
+// fallbackLimiter 主限流器出错时改用降级限流器
+type fallbackLimiter struct {
+	primary       Limiter
+	fallback      Limiter
+	retryInterval time.Duration
+	// downUntil 主限流器恢复检查的时间（UnixNano），0 表示主限流器正常
+	downUntil atomic.Int64
+	now       func() time.Time
+}
+
+// NewFallback 创建带降级的限流器，通常 primary 为 NewRedis，fallback 为 NewLocal
+// primary 出错时本次及之后一段时间内的请求改用 fallback，降级期间配额只在当前实例内生效
+func NewFallback(primary, fallback Limiter, opts ...FallbackOption) Limiter {
+	l := &fallbackLimiter{primary: primary, fallback: fallback, retryInterval: 5 * time.Second, now: time.Now}
+	for _, opt := range opts {
+		opt(l)
+	}
+	return l
+}
+
+func (l *fallbackLimiter) Allow(ctx context.Context, key string, limit Limit) (Result, error) {
+	down := l.downUntil.Load()
+	if down != 0 && l.now().UnixNano() < down {
+		return l.fallback.Allow(ctx, key, limit)
+	}
+
+	res, err := l.primary.Allow(ctx, key, limit)
+	if err == nil {
+		if down != 0 && l.downUntil.CompareAndSwap(down, 0) {
+			hlog.CtxInfof(ctx, "[RateLimit] primary limiter recovered")
+		}
+		return res, nil
+	}
+	if l.downUntil.Swap(l.now().Add(l.retryInterval).UnixNano()) == 0 {
+		hlog.CtxWarnf(ctx, "[RateLimit] primary limiter failed, fall back to local limiter for %s: %v", l.retryInterval, err)
+	}
+	return l.fallback.Allow(ctx, key, limit)
+}
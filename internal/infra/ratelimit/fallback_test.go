@@ -0,0 +1,54 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+type failingLimiter struct {
+	err   error
+	calls int
+}
+
+func (l *failingLimiter) Allow(context.Context, string, Limit) (Result, error) {
+	l.calls++
+	if l.err != nil {
+		return Result{}, l.err
+	}
+	return Result{Allowed: true, Remaining: 99}, nil
+}
+
+func TestFallbackLimiter(t *testing.T) {
+	now := time.Unix(0, 0)
+	primary := &failingLimiter{err: errors.New("redis down")}
+	l := NewFallback(primary, NewLocal(), WithRetryInterval(time.Second)).(*fallbackLimiter)
+	l.now = func() time.Time { return now }
+
+	ctx := context.Background()
+	limit := Limit{Rate: 1, Burst: 1}
+
+	t.Run("主限流器出错时降级", func(t *testing.T) {
+		if res, err := l.Allow(ctx, "k", limit); err != nil || !res.Allowed {
+			t.Fatalf("res = %+v, err = %v", res, err)
+		}
+		if res, _ := l.Allow(ctx, "k", limit); res.Allowed {
+			t.Error("fallback limiter should enforce the limit")
+		}
+		if primary.calls != 1 {
+			t.Errorf("primary calls = %d, want 1 during retry interval", primary.calls)
+		}
+	})
+
+	t.Run("超过重试间隔后恢复", func(t *testing.T) {
+		primary.err = nil
+		now = now.Add(time.Second)
+		if res, _ := l.Allow(ctx, "k", limit); res.Remaining != 99 {
+			t.Errorf("res = %+v, want primary result", res)
+		}
+		if l.downUntil.Load() != 0 {
+			t.Error("primary should be marked as recovered")
+		}
+	})
+}
@@ -0,0 +1,72 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// sweepInterval 清理空闲令牌桶的间隔
+const sweepInterval = time.Minute
+
+// localLimiter 进程内令牌桶限流器，只对当前实例生效
+type localLimiter struct {
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+	limit  Limit
+}
+
+// NewLocal 创建进程内限流器，已补满的空闲令牌桶会被定期清理
+func NewLocal() Limiter {
+	return &localLimiter{buckets: make(map[string]*bucket), now: time.Now}
+}
+
+func (l *localLimiter) Allow(_ context.Context, key string, limit Limit) (Result, error) {
+	if limit.IsZero() {
+		return Result{Allowed: true, Remaining: math.MaxInt32}, nil
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.sweep(now)
+
+	burst := float64(limit.BurstSize())
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: burst, last: now, limit: limit}
+		l.buckets[key] = b
+	}
+	b.tokens = math.Min(burst, b.tokens+now.Sub(b.last).Seconds()*limit.Rate)
+	b.last = now
+
+	if b.tokens < 1 {
+		wait := time.Duration((1 - b.tokens) / limit.Rate * float64(time.Second))
+		return Result{RetryAfter: wait}, nil
+	}
+	b.tokens--
+	return Result{Allowed: true, Remaining: int(b.tokens)}, nil
+}
+
+// sweep 删除已经补满的令牌桶，补满后的桶与新建的桶等价
+func (l *localLimiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < sweepInterval {
+		return
+	}
+	l.lastSweep = now
+	for key, b := range l.buckets {
+		full := time.Duration((float64(b.limit.BurstSize()) - b.tokens) / b.limit.Rate * float64(time.Second))
+		if now.Sub(b.last) >= full {
+			delete(l.buckets, key)
+		}
+	}
+}
@@ -0,0 +1,40 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestLocalLimiter(t *testing.T) {
+	now := time.Unix(0, 0)
+	l := NewLocal().(*localLimiter)
+	l.now = func() time.Time { return now }
+
+	ctx := context.Background()
+	limit := Limit{Rate: 2, Burst: 3}
+	for i := 0; i < 3; i++ {
+		if res, _ := l.Allow(ctx, "k", limit); !res.Allowed || res.Remaining != 2-i {
+			t.Fatalf("request %d: %+v", i, res)
+		}
+	}
+
+	res, _ := l.Allow(ctx, "k", limit)
+	if res.Allowed || res.RetryAfter != 500*time.Millisecond {
+		t.Fatalf("over limit: %+v", res)
+	}
+	if res, _ := l.Allow(ctx, "other", limit); !res.Allowed {
+		t.Error("keys should be limited independently")
+	}
+
+	now = now.Add(500 * time.Millisecond)
+	if res, _ := l.Allow(ctx, "k", limit); !res.Allowed {
+		t.Errorf("after refill: %+v", res)
+	}
+
+	now = now.Add(2 * time.Minute)
+	_, _ = l.Allow(ctx, "k", limit)
+	if len(l.buckets) != 1 {
+		t.Errorf("idle buckets not swept: %d", len(l.buckets))
+	}
+}
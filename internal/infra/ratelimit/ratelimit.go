@@ -0,0 +1,66 @@
+// Package ratelimit 提供基于令牌桶的限流器，支持进程内与基于 Redis 的分布式两种实现
+//
+//	limiter := ratelimit.NewLocal()          // 单实例限流
+//	limiter := ratelimit.NewRedis(client)    // 多实例共享配额
+//	limiter := ratelimit.NewFallback(ratelimit.NewRedis(client), ratelimit.NewLocal()) // Redis 故障时降级为单实例限流
+//
+//	res, err := limiter.Allow(ctx, "user:42", ratelimit.PerSecond(10))
+//	if err == nil && !res.Allowed {
+//		// 等待 res.RetryAfter 后重试
+//	}
+package ratelimit
+
+import (
+	"context"
+	"time"
+)
+
+// Limit 令牌桶的速率与容量
+type Limit struct {
+	// Rate 每秒补充的令牌数
+	Rate float64
+	// Burst 桶容量，即允许的最大突发请求数，<= 0 时取 Rate 向上取整（至少为 1）
+	Burst int
+}
+
+// PerSecond 每秒 n 个请求，突发容量为 n
+func PerSecond(n int) Limit {
+	return Limit{Rate: float64(n), Burst: n}
+}
+
+// PerMinute 每分钟 n 个请求，突发容量为 n
+func PerMinute(n int) Limit {
+	return Limit{Rate: float64(n) / 60, Burst: n}
+}
+
+// IsZero 判断是否未设置限制
+func (l Limit) IsZero() bool {
+	return l.Rate <= 0
+}
+
+// BurstSize 返回有效的桶容量
+func (l Limit) BurstSize() int {
+	if l.Burst > 0 {
+		return l.Burst
+	}
+	if b := int(l.Rate + 0.999999); b > 1 {
+		return b
+	}
+	return 1
+}
+
+// Result 限流结果
+type Result struct {
+	// Allowed 是否允许本次请求
+	Allowed bool
+	// Remaining 剩余可用的令牌数
+	Remaining int
+	// RetryAfter 被拒绝时至少需要等待的时长
+	RetryAfter time.Duration
+}
+
+// Limiter 限流器
+type Limiter interface {
+	// Allow 从 key 对应的令牌桶中获取一个令牌，同一个 key 应始终使用相同的 limit
+	Allow(ctx context.Context, key string, limit Limit) (Result, error)
+}
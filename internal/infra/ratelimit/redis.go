@@ -0,0 +1,95 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/ZampoRen/go-server-comon/internal/infra/cache"
+)
+
+// tokenBucketScript 令牌桶脚本，使用 Redis 服务器时间避免各实例时钟不一致
+// KEYS[1]: 令牌桶键；ARGV[1]: 每秒补充的令牌数；ARGV[2]: 桶容量
+// 返回 {是否允许, 剩余令牌数, 需要等待的毫秒数}
+const tokenBucketScript = `
+local rate = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+local t = redis.call("TIME")
+local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+
+local state = redis.call("HMGET", KEYS[1], "tokens", "ts")
+local tokens = tonumber(state[1])
+local ts = tonumber(state[2])
+if tokens == nil or ts == nil then
+	tokens = burst
+	ts = now
+end
+tokens = math.min(burst, tokens + math.max(0, now - ts) * rate / 1000)
+
+local allowed = 0
+local retry = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+else
+	retry = math.ceil((1 - tokens) * 1000 / rate)
+end
+
+redis.call("HSET", KEYS[1], "tokens", tostring(tokens), "ts", tostring(now))
+redis.call("PEXPIRE", KEYS[1], math.ceil(burst * 1000 / rate) + 1000)
+return {allowed, math.floor(tokens), retry}
+`
+
+// RedisOption Redis 限流器选项
+type RedisOption func(o *redisLimiter)
+
+// WithKeyPrefix 设置令牌桶键的前缀，默认 ratelimit:
+func WithKeyPrefix(prefix string) RedisOption {
+	return func(o *redisLimiter) {
+		o.prefix = prefix
+	}
+}
+
+// redisLimiter 基于 Redis 的分布式令牌桶限流器，所有实例共享配额
+type redisLimiter struct {
+	client cache.Cmdable
+	prefix string
+}
+
+// NewRedis 创建基于 Redis 的限流器，令牌桶在补满后自动过期
+func NewRedis(client cache.Cmdable, opts ...RedisOption) Limiter {
+	l := &redisLimiter{client: client, prefix: "ratelimit:"}
+	for _, opt := range opts {
+		opt(l)
+	}
+	return l
+}
+
+func (l *redisLimiter) Allow(ctx context.Context, key string, limit Limit) (Result, error) {
+	if limit.IsZero() {
+		return Result{Allowed: true}, nil
+	}
+
+	val, err := l.client.Eval(ctx, tokenBucketScript, []string{l.prefix + key},
+		strconv.FormatFloat(limit.Rate, 'f', -1, 64), limit.BurstSize()).Result()
+	if err != nil {
+		return Result{}, err
+	}
+
+	values, ok := val.([]interface{})
+	if !ok || len(values) != 3 {
+		return Result{}, fmt.Errorf("unexpected rate limit script result: %v", val)
+	}
+	nums := make([]int64, len(values))
+	for i, v := range values {
+		if nums[i], ok = v.(int64); !ok {
+			return Result{}, fmt.Errorf("unexpected rate limit script result: %v", val)
+		}
+	}
+	return Result{
+		Allowed:    nums[0] == 1,
+		Remaining:  int(nums[1]),
+		RetryAfter: time.Duration(nums[2]) * time.Millisecond,
+	}, nil
+}
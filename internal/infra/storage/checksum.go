@@ -0,0 +1,223 @@
+package storage
+
+import (
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"hash/crc64"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+const (
+	// MetaContentMD5 保存 MD5（base64）的对象元数据键
+	MetaContentMD5 = "content-md5"
+	// MetaCRC64 保存 CRC64（十进制）的对象元数据键
+	MetaCRC64 = "crc64ecma"
+)
+
+var crc64Table = crc64.MakeTable(crc64.ECMA)
+
+var (
+	// ErrChecksumMismatch 下载内容与上传时记录的校验和不一致，可通过 errors.Is 判断
+	ErrChecksumMismatch = errors.New("checksum mismatch")
+	// ErrMultipartChecksum 分片上传开启了校验但没有传入预先计算的校验和
+	ErrMultipartChecksum = errors.New("checksum options on multipart upload require precomputed checksums, use PutLargeObject")
+)
+
+// ChecksumError 校验和不一致的详细信息
+type ChecksumError struct {
+	Key       string // 对象键
+	Algorithm string // 校验算法，MetaContentMD5、MetaCRC64 或分片校验的 etag
+	Expected  string // 上传时记录的校验和
+	Actual    string // 下载内容的校验和
+}
+
+func (e *ChecksumError) Error() string {
+	return fmt.Sprintf("%s: key: %s, algorithm: %s, expected: %s, actual: %s", ErrChecksumMismatch, e.Key, e.Algorithm, e.Expected, e.Actual)
+}
+
+func (e *ChecksumError) Unwrap() error {
+	return ErrChecksumMismatch
+}
+
+// Checksums 上传前计算的校验和
+type Checksums struct {
+	// ContentMD5 base64 编码的 MD5，未开启时为空
+	ContentMD5 string
+	// Metadata 需要写入对象元数据的校验和
+	Metadata map[string]string
+}
+
+// ComputeChecksums 按 option 计算 content 的校验和，返回可用于上传的 Reader 与释放资源的 cleanup
+// content 支持 io.Seeker 时计算后重置到原位置；否则边计算边写入临时文件，上传时从文件读取，
+// 内存占用与对象大小无关。option 未开启校验时原样返回。cleanup 不为 nil，上传结束后调用
+func ComputeChecksums(content io.Reader, option *PutOption) (io.Reader, *Checksums, func(), error) {
+	cleanup := func() {}
+	if !option.ContentMD5 && !option.CRC64 {
+		return content, nil, cleanup, nil
+	}
+
+	var (
+		md5Hash   hash.Hash
+		crc64Hash hash.Hash64
+		writers   []io.Writer
+	)
+	if option.ContentMD5 {
+		md5Hash = md5.New()
+		writers = append(writers, md5Hash)
+	}
+	if option.CRC64 {
+		crc64Hash = crc64.New(crc64Table)
+		writers = append(writers, crc64Hash)
+	}
+	w := io.MultiWriter(writers...)
+
+	body := content
+	seeker, ok := content.(io.Seeker)
+	var start int64
+	if ok {
+		var err error
+		if start, err = seeker.Seek(0, io.SeekCurrent); err != nil {
+			ok = false
+		}
+	}
+	if ok {
+		if _, err := io.Copy(w, content); err != nil {
+			return nil, nil, cleanup, err
+		}
+		if _, err := seeker.Seek(start, io.SeekStart); err != nil {
+			return nil, nil, cleanup, err
+		}
+	} else {
+		f, err := os.CreateTemp("", "storage-upload-*")
+		if err != nil {
+			return nil, nil, cleanup, err
+		}
+		cleanup = func() {
+			_ = f.Close()
+			_ = os.Remove(f.Name())
+		}
+		n, err := io.Copy(f, io.TeeReader(content, w))
+		if err == nil {
+			_, err = f.Seek(0, io.SeekStart)
+		}
+		if err != nil {
+			cleanup()
+			return nil, nil, func() {}, err
+		}
+		option.ObjectSize = n
+		body = f
+	}
+
+	sums := &Checksums{Metadata: make(map[string]string, 2)}
+	if md5Hash != nil {
+		sums.ContentMD5 = base64.StdEncoding.EncodeToString(md5Hash.Sum(nil))
+		sums.Metadata[MetaContentMD5] = sums.ContentMD5
+	}
+	if crc64Hash != nil {
+		sums.Metadata[MetaCRC64] = strconv.FormatUint(crc64Hash.Sum64(), 10)
+	}
+	return body, sums, cleanup, nil
+}
+
+// MultipartMetadata 返回分片上传初始化时需要写入对象元数据的校验和
+// 分片上传在初始化时就要写入元数据，开启了校验但没有通过 WithChecksums 传入整个对象的校验和时
+// 返回 ErrMultipartChecksum，大文件应使用 PutLargeObject，由其预先计算
+func MultipartMetadata(option *PutOption) (map[string]string, error) {
+	if !option.ContentMD5 && !option.CRC64 {
+		return nil, nil
+	}
+	if option.Checksums == nil {
+		return nil, ErrMultipartChecksum
+	}
+	return option.Checksums.Metadata, nil
+}
+
+// verifyPartETag 校验分片的 ETag 与本地计算的 MD5 一致，发现传输中被截断或损坏的分片
+// ETag 不是 MD5 时（如服务端使用 KMS 加密）跳过校验
+func verifyPartETag(objectKey string, partNumber int32, data []byte, etag string) error {
+	etag = strings.ToLower(strings.Trim(etag, `"`))
+	if len(etag) != 2*md5.Size {
+		return nil
+	}
+	if _, err := hex.DecodeString(etag); err != nil {
+		return nil
+	}
+	sum := md5.Sum(data)
+	if actual := hex.EncodeToString(sum[:]); actual != etag {
+		return &ChecksumError{Key: fmt.Sprintf("%s#part%d", objectKey, partNumber), Algorithm: "etag", Expected: etag, Actual: actual}
+	}
+	return nil
+}
+
+// VerifyChecksum 使用对象元数据中记录的校验和校验下载内容，元数据中没有校验和时不校验
+func VerifyChecksum(objectKey string, data []byte, metadata map[string]string) error {
+	if expected, ok := metadata[MetaCRC64]; ok {
+		if actual := strconv.FormatUint(crc64.Checksum(data, crc64Table), 10); actual != expected {
+			return &ChecksumError{Key: objectKey, Algorithm: MetaCRC64, Expected: expected, Actual: actual}
+		}
+	}
+	if expected, ok := metadata[MetaContentMD5]; ok {
+		sum := md5.Sum(data)
+		if actual := base64.StdEncoding.EncodeToString(sum[:]); actual != expected {
+			return &ChecksumError{Key: objectKey, Algorithm: MetaContentMD5, Expected: expected, Actual: actual}
+		}
+	}
+	return nil
+}
+
+// NewVerifyingReader 返回边读边计算校验和的 Reader，读到末尾时使用 metadata 中记录的校验和校验，
+// 不一致时以 *ChecksumError 代替 io.EOF 返回；metadata 中没有校验和时原样返回 rc
+func NewVerifyingReader(objectKey string, rc io.ReadCloser, metadata map[string]string) io.ReadCloser {
+	v := &verifyingReader{ReadCloser: rc, key: objectKey}
+	if expected, ok := metadata[MetaCRC64]; ok {
+		v.crc64, v.crc64Expected = crc64.New(crc64Table), expected
+	}
+	if expected, ok := metadata[MetaContentMD5]; ok {
+		v.md5, v.md5Expected = md5.New(), expected
+	}
+	if v.crc64 == nil && v.md5 == nil {
+		return rc
+	}
+	return v
+}
+
+type verifyingReader struct {
+	io.ReadCloser
+	key string
+
+	crc64         hash.Hash64
+	crc64Expected string
+	md5           hash.Hash
+	md5Expected   string
+}
+
+func (v *verifyingReader) Read(p []byte) (int, error) {
+	n, err := v.ReadCloser.Read(p)
+	if v.crc64 != nil {
+		v.crc64.Write(p[:n])
+	}
+	if v.md5 != nil {
+		v.md5.Write(p[:n])
+	}
+	if err != io.EOF {
+		return n, err
+	}
+	if v.crc64 != nil {
+		if actual := strconv.FormatUint(v.crc64.Sum64(), 10); actual != v.crc64Expected {
+			return n, &ChecksumError{Key: v.key, Algorithm: MetaCRC64, Expected: v.crc64Expected, Actual: actual}
+		}
+	}
+	if v.md5 != nil {
+		if actual := base64.StdEncoding.EncodeToString(v.md5.Sum(nil)); actual != v.md5Expected {
+			return n, &ChecksumError{Key: v.key, Algorithm: MetaContentMD5, Expected: v.md5Expected, Actual: actual}
+		}
+	}
+	return n, err
+}
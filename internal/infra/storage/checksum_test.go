@@ -0,0 +1,107 @@
+package storage
+
+import (
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestChecksum(t *testing.T) {
+	tests := []struct {
+		name    string
+		content io.Reader
+	}{
+		{"支持 Seek", strings.NewReader("hello world")},
+		{"不支持 Seek", struct{ io.Reader }{strings.NewReader("hello world")}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			option := &PutOption{ContentMD5: true, CRC64: true}
+			body, sums, cleanup, err := ComputeChecksums(tt.content, option)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer cleanup()
+			data, err := io.ReadAll(body)
+			if err != nil || string(data) != "hello world" {
+				t.Fatalf("body = %q, err = %v", data, err)
+			}
+			if sums.ContentMD5 != "XrY7u+Ae7tCTyyK7j1rNww==" || sums.Metadata[MetaCRC64] == "" {
+				t.Fatalf("checksums = %+v", sums)
+			}
+
+			if err := VerifyChecksum("k", data, sums.Metadata); err != nil {
+				t.Errorf("VerifyChecksum() error = %v", err)
+			}
+			err = VerifyChecksum("k", data[:5], sums.Metadata)
+			var ce *ChecksumError
+			if !errors.Is(err, ErrChecksumMismatch) || !errors.As(err, &ce) || ce.Algorithm != MetaCRC64 {
+				t.Errorf("VerifyChecksum() truncated error = %v", err)
+			}
+		})
+	}
+
+	t.Run("流式校验", func(t *testing.T) {
+		_, sums, _, err := ComputeChecksums(strings.NewReader("hello world"), &PutOption{ContentMD5: true, CRC64: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+		rc := NewVerifyingReader("k", io.NopCloser(strings.NewReader("hello world")), sums.Metadata)
+		if data, err := io.ReadAll(rc); err != nil || string(data) != "hello world" {
+			t.Errorf("ReadAll() = %q, %v", data, err)
+		}
+		rc = NewVerifyingReader("k", io.NopCloser(strings.NewReader("hello")), sums.Metadata)
+		if _, err := io.ReadAll(rc); !errors.Is(err, ErrChecksumMismatch) {
+			t.Errorf("ReadAll() truncated error = %v, want ErrChecksumMismatch", err)
+		}
+	})
+
+	t.Run("不支持 Seek 时写入临时文件并记录大小", func(t *testing.T) {
+		option := &PutOption{CRC64: true}
+		body, _, cleanup, err := ComputeChecksums(struct{ io.Reader }{strings.NewReader("hello world")}, option)
+		if err != nil {
+			t.Fatal(err)
+		}
+		f, ok := body.(*os.File)
+		if !ok || option.ObjectSize != 11 {
+			t.Fatalf("body = %T, size = %d", body, option.ObjectSize)
+		}
+		cleanup()
+		if _, err := os.Stat(f.Name()); !os.IsNotExist(err) {
+			t.Errorf("temp file %s not removed: %v", f.Name(), err)
+		}
+	})
+
+	t.Run("分片上传需要预先计算的校验和", func(t *testing.T) {
+		if _, err := MultipartMetadata(&PutOption{CRC64: true}); !errors.Is(err, ErrMultipartChecksum) {
+			t.Errorf("MultipartMetadata() error = %v, want ErrMultipartChecksum", err)
+		}
+		sums := &Checksums{Metadata: map[string]string{MetaCRC64: "1"}}
+		if md, err := MultipartMetadata(&PutOption{CRC64: true, Checksums: sums}); err != nil || md[MetaCRC64] != "1" {
+			t.Errorf("MultipartMetadata() = %v, %v", md, err)
+		}
+	})
+
+	t.Run("分片 ETag 校验", func(t *testing.T) {
+		data := []byte("hello world")
+		if err := verifyPartETag("k", 1, data, `"5EB63BBBE01EEED093CB22BB8F5ACDC3"`); err != nil {
+			t.Errorf("verifyPartETag() error = %v", err)
+		}
+		if err := verifyPartETag("k", 1, data[:5], `"5eb63bbbe01eeed093cb22bb8f5acdc3"`); !errors.Is(err, ErrChecksumMismatch) {
+			t.Errorf("verifyPartETag() error = %v, want ErrChecksumMismatch", err)
+		}
+		if err := verifyPartETag("k", 1, data, "kms-etag"); err != nil {
+			t.Errorf("verifyPartETag() non-md5 etag error = %v", err)
+		}
+	})
+
+	t.Run("未开启时不计算", func(t *testing.T) {
+		r := strings.NewReader("x")
+		body, sums, _, err := ComputeChecksums(r, &PutOption{})
+		if err != nil || sums != nil || body != io.Reader(r) {
+			t.Errorf("body = %v, sums = %v, err = %v", body, sums, err)
+		}
+	})
+}
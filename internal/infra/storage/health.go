@@ -0,0 +1,9 @@
+package storage
+
+import "context"
+
+// Ready 供就绪探针调用，列出 prefix 下的一个对象以检查存储服务是否可访问且有读取权限
+func Ready(ctx context.Context, s Storage, prefix string) error {
+	_, err := s.ListObjectsPaginated(ctx, &ListObjectsPaginatedInput{Prefix: prefix, PageSize: 1})
+	return err
+}
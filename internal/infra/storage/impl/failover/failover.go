@@ -0,0 +1,287 @@
+package failover
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/common/hlog"
+
+	"github.com/ZampoRen/go-server-comon/internal/infra/storage"
+)
+
+// Option 双写存储配置选项
+type Option func(o *option)
+
+type option struct {
+	// mirrorTimeout 单次镜像操作的超时时间
+	mirrorTimeout time.Duration
+	// mirrorConcurrency 同时进行的镜像操作上限，超出后丢弃并记录日志
+	mirrorConcurrency int
+	// readFallback 主存储读取失败时是否回退到从存储
+	readFallback bool
+}
+
+// WithMirrorTimeout 设置单次镜像操作的超时时间（默认 5 分钟）
+func WithMirrorTimeout(d time.Duration) Option {
+	return func(o *option) {
+		if d > 0 {
+			o.mirrorTimeout = d
+		}
+	}
+}
+
+// WithMirrorConcurrency 设置同时进行的镜像操作上限（默认 64）
+func WithMirrorConcurrency(n int) Option {
+	return func(o *option) {
+		if n > 0 {
+			o.mirrorConcurrency = n
+		}
+	}
+}
+
+// WithReadFallback 设置主存储读取失败时是否回退到从存储（默认 true）
+func WithReadFallback(enable bool) Option {
+	return func(o *option) {
+		o.readFallback = enable
+	}
+}
+
+// dualStorage 双写存储实现
+// 写操作同步写入主存储，成功后异步镜像到从存储；
+// 读操作优先读取主存储，失败时回退到从存储
+type dualStorage struct {
+	primary   storage.Storage
+	secondary storage.Storage
+	opt       *option
+	sem       chan struct{}
+
+	// uploads 分片上传初始化时的选项，合并后镜像时使用，键为 uploadID
+	mu      sync.Mutex
+	uploads map[string][]storage.PutOptFn
+}
+
+// New 创建双写存储，用于云厂商迁移期间在不修改调用方的情况下同时写入两个存储
+func New(primary, secondary storage.Storage, opts ...Option) storage.Storage {
+	opt := &option{
+		mirrorTimeout:     5 * time.Minute,
+		mirrorConcurrency: 64,
+		readFallback:      true,
+	}
+	for _, o := range opts {
+		o(opt)
+	}
+
+	return &dualStorage{
+		primary:   primary,
+		secondary: secondary,
+		opt:       opt,
+		sem:       make(chan struct{}, opt.mirrorConcurrency),
+		uploads:   make(map[string][]storage.PutOptFn),
+	}
+}
+
+// mirror 异步执行镜像操作，不受调用方 ctx 取消的影响
+func (d *dualStorage) mirror(ctx context.Context, op, objectKey string, fn func(ctx context.Context) error) {
+	select {
+	case d.sem <- struct{}{}:
+	default:
+		hlog.CtxWarnf(ctx, "[Storage] mirror %s dropped, too many pending mirrors, key: %s", op, objectKey)
+		return
+	}
+
+	mctx := context.WithoutCancel(ctx)
+	go func() {
+		defer func() {
+			<-d.sem
+			if r := recover(); r != nil {
+				hlog.CtxErrorf(mctx, "[Storage] mirror %s panic, key: %s, err: %v", op, objectKey, r)
+			}
+		}()
+
+		tctx, cancel := context.WithTimeout(mctx, d.opt.mirrorTimeout)
+		defer cancel()
+
+		if err := fn(tctx); err != nil {
+			hlog.CtxErrorf(mctx, "[Storage] mirror %s failed, key: %s, err: %v", op, objectKey, err)
+		}
+	}()
+}
+
+func (d *dualStorage) PutObject(ctx context.Context, objectKey string, content []byte, opts ...storage.PutOptFn) error {
+	if err := d.primary.PutObject(ctx, objectKey, content, opts...); err != nil {
+		return err
+	}
+
+	d.mirror(ctx, "PutObject", objectKey, func(ctx context.Context) error {
+		return d.secondary.PutObject(ctx, objectKey, content, opts...)
+	})
+	return nil
+}
+
+func (d *dualStorage) PutObjectWithReader(ctx context.Context, objectKey string, content io.Reader, opts ...storage.PutOptFn) error {
+	if err := d.primary.PutObjectWithReader(ctx, objectKey, content, opts...); err != nil {
+		return err
+	}
+
+	// Reader 只能消费一次，镜像时从主存储流式回读
+	d.mirror(ctx, "PutObjectWithReader", objectKey, func(ctx context.Context) error {
+		rc, err := d.primary.GetObjectReader(ctx, objectKey)
+		if err != nil {
+			return err
+		}
+		defer rc.Close()
+		return d.secondary.PutObjectWithReader(ctx, objectKey, rc, opts...)
+	})
+	return nil
+}
+
+func (d *dualStorage) GetObjectReader(ctx context.Context, objectKey string) (io.ReadCloser, error) {
+	rc, err := d.primary.GetObjectReader(ctx, objectKey)
+	if err == nil || !d.opt.readFallback {
+		return rc, err
+	}
+
+	hlog.CtxWarnf(ctx, "[Storage] primary GetObjectReader failed, fallback to secondary, key: %s, err: %v", objectKey, err)
+	return d.secondary.GetObjectReader(ctx, objectKey)
+}
+
+func (d *dualStorage) GetObject(ctx context.Context, objectKey string) ([]byte, error) {
+	data, err := d.primary.GetObject(ctx, objectKey)
+	if err == nil || !d.opt.readFallback {
+		return data, err
+	}
+
+	hlog.CtxWarnf(ctx, "[Storage] primary GetObject failed, fallback to secondary, key: %s, err: %v", objectKey, err)
+	return d.secondary.GetObject(ctx, objectKey)
+}
+
+func (d *dualStorage) DeleteObject(ctx context.Context, objectKey string) error {
+	if err := d.primary.DeleteObject(ctx, objectKey); err != nil {
+		return err
+	}
+
+	d.mirror(ctx, "DeleteObject", objectKey, func(ctx context.Context) error {
+		return d.secondary.DeleteObject(ctx, objectKey)
+	})
+	return nil
+}
+
+func (d *dualStorage) GetObjectUrl(ctx context.Context, objectKey string, opts ...storage.GetOptFn) (string, error) {
+	url, err := d.primary.GetObjectUrl(ctx, objectKey, opts...)
+	if err == nil || !d.opt.readFallback {
+		return url, err
+	}
+
+	hlog.CtxWarnf(ctx, "[Storage] primary GetObjectUrl failed, fallback to secondary, key: %s, err: %v", objectKey, err)
+	return d.secondary.GetObjectUrl(ctx, objectKey, opts...)
+}
+
+func (d *dualStorage) HeadObject(ctx context.Context, objectKey string, opts ...storage.GetOptFn) (*storage.FileInfo, error) {
+	f, err := d.primary.HeadObject(ctx, objectKey, opts...)
+	if err == nil || !d.opt.readFallback {
+		return f, err
+	}
+
+	hlog.CtxWarnf(ctx, "[Storage] primary HeadObject failed, fallback to secondary, key: %s, err: %v", objectKey, err)
+	return d.secondary.HeadObject(ctx, objectKey, opts...)
+}
+
+func (d *dualStorage) ListAllObjects(ctx context.Context, prefix string, opts ...storage.GetOptFn) ([]*storage.FileInfo, error) {
+	files, err := d.primary.ListAllObjects(ctx, prefix, opts...)
+	if err == nil || !d.opt.readFallback {
+		return files, err
+	}
+
+	hlog.CtxWarnf(ctx, "[Storage] primary ListAllObjects failed, fallback to secondary, prefix: %s, err: %v", prefix, err)
+	return d.secondary.ListAllObjects(ctx, prefix, opts...)
+}
+
+// ListObjectsPaginated 游标在不同厂商之间不通用，分页列举只读取主存储
+func (d *dualStorage) ListObjectsPaginated(ctx context.Context, input *storage.ListObjectsPaginatedInput, opts ...storage.GetOptFn) (*storage.ListObjectsPaginatedOutput, error) {
+	return d.primary.ListObjectsPaginated(ctx, input, opts...)
+}
+
+// InitMultipartUpload uploadID 只在主存储有效，分片上传只写入主存储，合并成功后再整体镜像
+func (d *dualStorage) InitMultipartUpload(ctx context.Context, objectKey string, opts ...storage.PutOptFn) (string, error) {
+	uploadID, err := d.primary.InitMultipartUpload(ctx, objectKey, opts...)
+	if err != nil {
+		return "", err
+	}
+
+	d.mu.Lock()
+	d.uploads[uploadID] = opts
+	d.mu.Unlock()
+	return uploadID, nil
+}
+
+// takeUpload 取出并删除分片上传初始化时的选项
+func (d *dualStorage) takeUpload(uploadID string) []storage.PutOptFn {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	opts := d.uploads[uploadID]
+	delete(d.uploads, uploadID)
+	return opts
+}
+
+func (d *dualStorage) UploadPart(ctx context.Context, objectKey, uploadID string, partNumber int32, content io.Reader, size int64) (*storage.CompletedPart, error) {
+	return d.primary.UploadPart(ctx, objectKey, uploadID, partNumber, content, size)
+}
+
+func (d *dualStorage) CompleteMultipartUpload(ctx context.Context, objectKey, uploadID string, parts []storage.CompletedPart) error {
+	if err := d.primary.CompleteMultipartUpload(ctx, objectKey, uploadID, parts); err != nil {
+		return err
+	}
+	opts := d.takeUpload(uploadID)
+
+	// 从主存储流式回读并分片写入从存储，内存占用约为两个分片
+	d.mirror(ctx, "CompleteMultipartUpload", objectKey, func(ctx context.Context) error {
+		rc, err := d.primary.GetObjectReader(ctx, objectKey)
+		if err != nil {
+			return err
+		}
+		defer rc.Close()
+		return storage.PutLargeObject(ctx, d.secondary, objectKey, rc, storage.DefaultPartSize, 1, opts...)
+	})
+	return nil
+}
+
+func (d *dualStorage) AbortMultipartUpload(ctx context.Context, objectKey, uploadID string) error {
+	d.takeUpload(uploadID)
+	return d.primary.AbortMultipartUpload(ctx, objectKey, uploadID)
+}
+
+func (d *dualStorage) CopyObject(ctx context.Context, srcKey, dstKey string) error {
+	if err := d.primary.CopyObject(ctx, srcKey, dstKey); err != nil {
+		return err
+	}
+
+	d.mirror(ctx, "CopyObject", dstKey, func(ctx context.Context) error {
+		return d.secondary.CopyObject(ctx, srcKey, dstKey)
+	})
+	return nil
+}
+
+func (d *dualStorage) MoveObject(ctx context.Context, srcKey, dstKey string) error {
+	if err := d.primary.MoveObject(ctx, srcKey, dstKey); err != nil {
+		return err
+	}
+
+	d.mirror(ctx, "MoveObject", dstKey, func(ctx context.Context) error {
+		return d.secondary.MoveObject(ctx, srcKey, dstKey)
+	})
+	return nil
+}
+
+func (d *dualStorage) DeleteObjects(ctx context.Context, objectKeys []string) error {
+	if err := d.primary.DeleteObjects(ctx, objectKeys); err != nil {
+		return err
+	}
+
+	d.mirror(ctx, "DeleteObjects", fmt.Sprintf("%d keys", len(objectKeys)), func(ctx context.Context) error {
+		return d.secondary.DeleteObjects(ctx, objectKeys)
+	})
+	return nil
+}
@@ -0,0 +1,103 @@
+package failover
+
+import (
+	"bytes"
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ZampoRen/go-server-comon/internal/infra/storage"
+	"github.com/ZampoRen/go-server-comon/internal/infra/storage/impl/memory"
+)
+
+// recordStorage 记录上传时的 Content-Type
+type recordStorage struct {
+	storage.Storage
+
+	mu           sync.Mutex
+	contentTypes map[string]string
+}
+
+func (r *recordStorage) record(objectKey string, opts []storage.PutOptFn) {
+	option := storage.PutOption{}
+	for _, opt := range opts {
+		opt(&option)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if option.ContentType != nil {
+		r.contentTypes[objectKey] = *option.ContentType
+	}
+}
+
+func (r *recordStorage) contentType(objectKey string) string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.contentTypes[objectKey]
+}
+
+func (r *recordStorage) PutObject(ctx context.Context, objectKey string, content []byte, opts ...storage.PutOptFn) error {
+	r.record(objectKey, opts)
+	return r.Storage.PutObject(ctx, objectKey, content, opts...)
+}
+
+func (r *recordStorage) InitMultipartUpload(ctx context.Context, objectKey string, opts ...storage.PutOptFn) (string, error) {
+	r.record(objectKey, opts)
+	return r.Storage.InitMultipartUpload(ctx, objectKey, opts...)
+}
+
+// waitFor 等待异步镜像完成
+func waitFor(t *testing.T, s storage.Storage, objectKey string) []byte {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if data, err := s.GetObject(context.Background(), objectKey); err == nil {
+			return data
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("object %s not mirrored", objectKey)
+	return nil
+}
+
+func TestMirror(t *testing.T) {
+	ctx := context.Background()
+	primary := memory.New()
+	secondary := &recordStorage{Storage: memory.New(), contentTypes: make(map[string]string)}
+	s := New(primary, secondary)
+
+	t.Run("分片上传合并后流式镜像并保留选项", func(t *testing.T) {
+		data := bytes.Repeat([]byte("0123456789abcdef"), int(storage.MinPartSize)/16*2+1)
+		err := storage.PutLargeObject(ctx, s, "large", bytes.NewReader(data), storage.MinPartSize, 2, storage.WithContentType("video/mp4"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := waitFor(t, secondary, "large"); !bytes.Equal(got, data) {
+			t.Fatalf("mirrored %d bytes, want %d", len(got), len(data))
+		}
+		if ct := secondary.contentType("large"); ct != "video/mp4" {
+			t.Fatalf("mirrored content type = %q, want video/mp4", ct)
+		}
+	})
+
+	t.Run("Reader 上传从主存储回读镜像", func(t *testing.T) {
+		if err := s.PutObjectWithReader(ctx, "small", bytes.NewReader([]byte("hello"))); err != nil {
+			t.Fatal(err)
+		}
+		if got := waitFor(t, secondary, "small"); string(got) != "hello" {
+			t.Fatalf("mirrored = %q", got)
+		}
+	})
+
+	t.Run("读取回退到从存储", func(t *testing.T) {
+		if err := secondary.PutObject(ctx, "only-secondary", []byte("x")); err != nil {
+			t.Fatal(err)
+		}
+		rc, err := s.GetObjectReader(ctx, "only-secondary")
+		if err != nil {
+			t.Fatal(err)
+		}
+		_ = rc.Close()
+	})
+}
@@ -0,0 +1,221 @@
+package gcs
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ZampoRen/go-server-comon/internal/infra/storage"
+)
+
+// fakeGCS 模拟 JSON API 的上传、元数据、删除与 XML API 的下载
+type fakeGCS struct {
+	mu      sync.Mutex
+	objects map[string]fakeObject
+}
+
+type fakeObject struct {
+	data     []byte
+	metadata map[string]string
+}
+
+func (f *fakeGCS) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	notFound := func() {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = io.WriteString(w, `{"error":{"code":404,"message":"No such object","errors":[{"reason":"notFound"}]}}`)
+	}
+	path := r.URL.EscapedPath()
+	switch {
+	case path == "/storage/v1/b/bucket":
+		w.WriteHeader(http.StatusOK)
+	case path == "/upload/storage/v1/b/bucket/o":
+		_, params, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+		mr := multipart.NewReader(r.Body, params["boundary"])
+		part, _ := mr.NextPart()
+		var resource struct {
+			Name     string            `json:"name"`
+			Metadata map[string]string `json:"metadata"`
+		}
+		_ = json.NewDecoder(part).Decode(&resource)
+		part, _ = mr.NextPart()
+		data, _ := io.ReadAll(part)
+		f.objects[resource.Name] = fakeObject{data: data, metadata: resource.Metadata}
+		_, _ = io.WriteString(w, `{}`)
+	case strings.HasPrefix(path, "/storage/v1/b/bucket/o/"):
+		name, _ := url.PathUnescape(strings.TrimPrefix(path, "/storage/v1/b/bucket/o/"))
+		obj, ok := f.objects[name]
+		if !ok {
+			notFound()
+			return
+		}
+		if r.Method == http.MethodDelete {
+			delete(f.objects, name)
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"name":     name,
+			"size":     "5",
+			"etag":     "etag",
+			"updated":  time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+			"metadata": obj.metadata,
+		})
+	case strings.HasPrefix(path, "/bucket/"):
+		name, _ := url.PathUnescape(strings.TrimPrefix(path, "/bucket/"))
+		obj, ok := f.objects[name]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = io.WriteString(w, `<Error><Code>NoSuchKey</Code><Message>not found</Message></Error>`)
+			return
+		}
+		for k, v := range obj.metadata {
+			w.Header().Set(metaHeaderPrefix+k, v)
+		}
+		_, _ = w.Write(obj.data)
+	default:
+		notFound()
+	}
+}
+
+func TestGCS(t *testing.T) {
+	ctx := context.Background()
+	srv := httptest.NewServer(&fakeGCS{objects: make(map[string]fakeObject)})
+	defer srv.Close()
+
+	s, err := NewWithConfig(ctx, &Config{Bucket: "bucket", Endpoint: srv.URL, HTTPClient: srv.Client(), SignerEmail: "sa@example.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("上传下载与元数据", func(t *testing.T) {
+		err := s.PutObject(ctx, "dir/a b.txt", []byte("hello"), storage.WithTagging(map[string]string{"env": "test"}), storage.WithCRC64())
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, err := s.GetObject(ctx, "dir/a b.txt")
+		if err != nil || string(data) != "hello" {
+			t.Fatalf("GetObject() = %q, %v", data, err)
+		}
+		f, err := s.HeadObject(ctx, "dir/a b.txt", storage.WithGetTagging(true))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if f.Size != 5 || f.Tagging["env"] != "test" {
+			t.Errorf("HeadObject() = %+v", f)
+		}
+	})
+
+	t.Run("对象不存在", func(t *testing.T) {
+		if _, err := s.HeadObject(ctx, "missing"); !errors.Is(err, storage.ErrObjectNotFound) {
+			t.Errorf("HeadObject(missing) error = %v", err)
+		}
+		if _, err := s.GetObject(ctx, "missing"); !errors.Is(storage.ClassifyError(err), storage.ErrObjectNotFound) {
+			t.Errorf("GetObject(missing) error = %v", err)
+		}
+		if err := s.DeleteObject(ctx, "missing"); err != nil {
+			t.Errorf("DeleteObject(missing) error = %v", err)
+		}
+	})
+}
+
+func TestSignURL(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		endpoint string
+		host     string
+		path     string
+	}{
+		{name: "默认地址", endpoint: DefaultEndpoint, host: "storage.googleapis.com", path: "/bucket/dir/a%20b%2Bc.png"},
+		{name: "带路径前缀的地址", endpoint: "https://proxy.example.com/gcs", host: "proxy.example.com", path: "/gcs/bucket/dir/a%20b%2Bc.png"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &gcsClient{
+				bucketName: "bucket",
+				endpoint:   tt.endpoint,
+				signer:     &keySigner{emailAddr: "sa@project.iam.gserviceaccount.com", key: key},
+			}
+			raw, err := c.signURL(context.Background(), http.MethodGet, "dir/a b+c.png", time.Hour, now)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			u, err := url.Parse(raw)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if u.Host != tt.host || u.EscapedPath() != tt.path {
+				t.Errorf("url = %s, want host %s and path %s", raw, tt.host, tt.path)
+			}
+			q := u.Query()
+			if q.Get("X-Goog-Credential") != "sa@project.iam.gserviceaccount.com/20240102/auto/storage/goog4_request" ||
+				q.Get("X-Goog-Date") != "20240102T030405Z" || q.Get("X-Goog-Expires") != "3600" {
+				t.Errorf("query = %v", q)
+			}
+
+			// 使用公钥校验签名
+			canonicalQuery := raw[strings.Index(raw, "?")+1 : strings.Index(raw, "&X-Goog-Signature=")]
+			canonicalRequest := "GET\n" + tt.path + "\n" + canonicalQuery + "\nhost:" + tt.host + "\n\nhost\nUNSIGNED-PAYLOAD"
+			sum := sha256.Sum256([]byte(canonicalRequest))
+			stringToSign := "GOOG4-RSA-SHA256\n20240102T030405Z\n20240102/auto/storage/goog4_request\n" + hex.EncodeToString(sum[:])
+			signature, err := hex.DecodeString(q.Get("X-Goog-Signature"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			digest := sha256.Sum256([]byte(stringToSign))
+			if err := rsa.VerifyPKCS1v15(&key.PublicKey, crypto.SHA256, digest[:], signature); err != nil {
+				t.Errorf("verify signature failed: %v", err)
+			}
+		})
+	}
+}
+
+// TestIAMSignerEmail 测试读取签名邮箱失败后不缓存错误
+func TestIAMSignerEmail(t *testing.T) {
+	calls := 0
+	s := &iamSigner{lookupEmail: func(ctx context.Context) (string, error) {
+		calls++
+		if err := ctx.Err(); err != nil {
+			return "", err
+		}
+		return "sa@project.iam.gserviceaccount.com", nil
+	}}
+
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := s.email(canceled); !errors.Is(err, context.Canceled) {
+		t.Fatalf("email() error = %v, want context.Canceled", err)
+	}
+	for range 2 {
+		if email, err := s.email(context.Background()); err != nil || email != "sa@project.iam.gserviceaccount.com" {
+			t.Fatalf("email() = %q, %v", email, err)
+		}
+	}
+	if calls != 2 {
+		t.Fatalf("lookup calls = %d, want 2", calls)
+	}
+}
@@ -0,0 +1,232 @@
+package gcs
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"cloud.google.com/go/compute/metadata"
+	"golang.org/x/oauth2/google"
+
+	"github.com/ZampoRen/go-server-comon/internal/infra/storage"
+)
+
+const (
+	// signAlgorithm V4 签名算法
+	signAlgorithm = "GOOG4-RSA-SHA256"
+	// maxSignExpires V4 签名 URL 最长有效期为 7 天
+	maxSignExpires = 7 * 24 * time.Hour
+	// iamCredentialsEndpoint IAM Credentials API 地址
+	iamCredentialsEndpoint = "https://iamcredentials.googleapis.com/v1"
+)
+
+// signer 计算 V4 签名
+type signer interface {
+	// email 返回签名服务账号邮箱
+	email(ctx context.Context) (string, error)
+	// sign 使用服务账号私钥对 data 做 RSA-SHA256 签名
+	sign(ctx context.Context, data []byte) ([]byte, error)
+}
+
+// newSigner 提供服务账号 JSON 密钥时在本地签名，否则调用 IAM signBlob 接口（Workload Identity）
+func newSigner(client *http.Client, credentialsJSON []byte, signerEmail string) (signer, error) {
+	if len(credentialsJSON) > 0 {
+		conf, err := google.JWTConfigFromJSON(credentialsJSON)
+		if err != nil {
+			return nil, fmt.Errorf("parse gcs service account failed: %w", err)
+		}
+		key, err := parsePrivateKey(conf.PrivateKey)
+		if err != nil {
+			return nil, err
+		}
+		return &keySigner{emailAddr: conf.Email, key: key}, nil
+	}
+	return &iamSigner{client: client, emailAddr: signerEmail, lookupEmail: metadataEmail}, nil
+}
+
+// parsePrivateKey 解析 PEM 格式的 PKCS8 或 PKCS1 RSA 私钥
+func parsePrivateKey(data []byte) (*rsa.PrivateKey, error) {
+	if block, _ := pem.Decode(data); block != nil {
+		data = block.Bytes
+	}
+	if key, err := x509.ParsePKCS8PrivateKey(data); err == nil {
+		rsaKey, ok := key.(*rsa.PrivateKey)
+		if !ok {
+			return nil, errors.New("gcs service account private key is not RSA")
+		}
+		return rsaKey, nil
+	}
+	key, err := x509.ParsePKCS1PrivateKey(data)
+	if err != nil {
+		return nil, fmt.Errorf("parse gcs service account private key failed: %w", err)
+	}
+	return key, nil
+}
+
+// keySigner 使用服务账号私钥在本地签名
+type keySigner struct {
+	emailAddr string
+	key       *rsa.PrivateKey
+}
+
+func (s *keySigner) email(context.Context) (string, error) {
+	return s.emailAddr, nil
+}
+
+func (s *keySigner) sign(_ context.Context, data []byte) ([]byte, error) {
+	sum := sha256.Sum256(data)
+	return rsa.SignPKCS1v15(rand.Reader, s.key, crypto.SHA256, sum[:])
+}
+
+// iamSigner 调用 IAM Credentials signBlob 接口签名，服务账号邮箱为空时从元数据服务读取
+type iamSigner struct {
+	client *http.Client
+	// lookupEmail 读取默认服务账号邮箱，默认为 metadataEmail
+	lookupEmail func(ctx context.Context) (string, error)
+
+	mu        sync.Mutex
+	emailAddr string
+}
+
+// metadataEmail 从元数据服务读取默认服务账号邮箱
+func metadataEmail(ctx context.Context) (string, error) {
+	return metadata.EmailWithContext(ctx, "default")
+}
+
+// email 返回签名服务账号邮箱，只缓存成功读取的结果，ctx 取消等临时错误在下次调用时重试
+func (s *iamSigner) email(ctx context.Context) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.emailAddr != "" {
+		return s.emailAddr, nil
+	}
+	email, err := s.lookupEmail(ctx)
+	if err != nil {
+		return "", fmt.Errorf("get gcs signer email from metadata server failed: %w", err)
+	}
+	if email == "" {
+		return "", errors.New("get gcs signer email from metadata server failed: empty email")
+	}
+	s.emailAddr = email
+	return email, nil
+}
+
+func (s *iamSigner) sign(ctx context.Context, data []byte) ([]byte, error) {
+	email, err := s.email(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	u := iamCredentialsEndpoint + "/projects/-/serviceAccounts/" + url.PathEscape(email) + ":signBlob"
+	c := &gcsClient{client: s.client}
+	var res struct {
+		SignedBlob string `json:"signedBlob"`
+	}
+	if err := c.doJSON(ctx, http.MethodPost, u, map[string]string{
+		"payload": base64.StdEncoding.EncodeToString(data),
+	}, &res); err != nil {
+		return nil, fmt.Errorf("sign blob failed: %w", err)
+	}
+	return base64.StdEncoding.DecodeString(res.SignedBlob)
+}
+
+// GetObjectUrl 生成 V4 签名的下载 URL
+// GCS 不提供图片处理能力，WithProcess 与 WithImageProcess 会被忽略
+func (t *gcsClient) GetObjectUrl(ctx context.Context, objectKey string, opts ...storage.GetOptFn) (string, error) {
+	opt := storage.GetOption{}
+	for _, optFn := range opts {
+		optFn(&opt)
+	}
+
+	expires := time.Duration(opt.Expire) * time.Second
+	if expires <= 0 || expires > maxSignExpires {
+		expires = maxSignExpires
+	}
+
+	u, err := t.signURL(ctx, http.MethodGet, objectKey, expires, time.Now())
+	if err != nil {
+		return "", fmt.Errorf("presign get object failed: %w", err)
+	}
+	return u, nil
+}
+
+// signURL 按 V4 签名流程生成预签名 URL，Endpoint 带路径时 URL 与签名使用的路径都包含该前缀
+// https://cloud.google.com/storage/docs/access-control/signing-urls-manually
+func (t *gcsClient) signURL(ctx context.Context, method, objectKey string, expires time.Duration, now time.Time) (string, error) {
+	email, err := t.signer.email(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	u, err := url.Parse(t.endpoint)
+	if err != nil {
+		return "", err
+	}
+	now = now.UTC()
+	datestamp := now.Format("20060102")
+	scope := datestamp + "/auto/storage/goog4_request"
+	path := strings.TrimSuffix(u.EscapedPath(), "/") + t.xmlPath(objectKey)
+
+	query := url.Values{}
+	query.Set("X-Goog-Algorithm", signAlgorithm)
+	query.Set("X-Goog-Credential", email+"/"+scope)
+	query.Set("X-Goog-Date", now.Format("20060102T150405Z"))
+	query.Set("X-Goog-Expires", strconv.Itoa(int(expires.Seconds())))
+	query.Set("X-Goog-SignedHeaders", "host")
+	canonicalQuery := canonicalQueryString(query)
+
+	canonicalRequest := strings.Join([]string{
+		method,
+		path,
+		canonicalQuery,
+		"host:" + u.Host + "\n",
+		"host",
+		"UNSIGNED-PAYLOAD",
+	}, "\n")
+	sum := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := strings.Join([]string{
+		signAlgorithm,
+		now.Format("20060102T150405Z"),
+		scope,
+		hex.EncodeToString(sum[:]),
+	}, "\n")
+
+	signature, err := t.signer.sign(ctx, []byte(stringToSign))
+	if err != nil {
+		return "", err
+	}
+
+	return u.Scheme + "://" + u.Host + path + "?" + canonicalQuery + "&X-Goog-Signature=" + hex.EncodeToString(signature), nil
+}
+
+// canonicalQueryString 按键排序并使用 RFC 3986 转义
+func canonicalQueryString(query url.Values) string {
+	keys := make([]string, 0, len(query))
+	for k := range query {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	parts := make([]string, 0, len(keys))
+	for _, k := range keys {
+		for _, v := range query[k] {
+			parts = append(parts, escape(k)+"="+escape(v))
+		}
+	}
+	return strings.Join(parts, "&")
+}
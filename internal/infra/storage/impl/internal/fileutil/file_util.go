@@ -3,30 +3,52 @@ package fileutil
 import (
 	"context"
 
+	"github.com/cloudwego/hertz/pkg/common/hlog"
+
 	"github.com/ZampoRen/go-server-comon/internal/infra/storage"
+	"github.com/ZampoRen/go-server-comon/pkg/taskgroup"
 )
 
+// defaultURLConcurrency 默认的 URL 生成并发数
+const defaultURLConcurrency = 16
+
+// Concurrency 批量获取对象 URL、标签等信息的并发数，未设置时为 16
+func Concurrency(opt *storage.GetOption) int {
+	if opt != nil && opt.URLConcurrency > 0 {
+		return opt.URLConcurrency
+	}
+	return defaultURLConcurrency
+}
+
 // AssembleFileUrl 为文件列表组装 URL
-func AssembleFileUrl(ctx context.Context, urlExpire *int64, files []*storage.FileInfo, s storage.Storage) ([]*storage.FileInfo, error) {
-	if files == nil || s == nil {
+// 以至多 opt.URLConcurrency 的并发生成，单个文件失败时按 opt.URLFailurePolicy 处理
+func AssembleFileUrl(ctx context.Context, opt *storage.GetOption, files []*storage.FileInfo, s storage.Storage) ([]*storage.FileInfo, error) {
+	if len(files) == 0 || s == nil {
 		return files, nil
 	}
 
-	// 使用简单的并发方式获取 URL
-	// 注意：这里简化了实现，实际可以使用 taskgroup 等并发库
-	for _, f := range files {
-		expire := int64(7 * 60 * 60 * 24) // 默认 7 天
-		if urlExpire != nil && *urlExpire > 0 {
-			expire = *urlExpire
+	expire := int64(7 * 60 * 60 * 24) // 默认 7 天
+	policy := storage.URLFailFast
+	if opt != nil {
+		if opt.Expire > 0 {
+			expire = opt.Expire
 		}
-
+		policy = opt.URLFailurePolicy
+	}
+	err := taskgroup.ForEach(ctx, files, Concurrency(opt), func(ctx context.Context, _ int, f *storage.FileInfo) error {
 		url, err := s.GetObjectUrl(ctx, f.Key, storage.WithExpire(expire))
-		if err != nil {
-			return nil, err
+		if err == nil {
+			f.URL = url
+			return nil
 		}
-
-		f.URL = url
+		if policy == storage.URLSkipFailed {
+			hlog.CtxWarnf(ctx, "[Storage] assemble file url failed, skipped, key: %s, err: %v", f.Key, err)
+			return nil
+		}
+		return err
+	})
+	if err != nil {
+		return nil, err
 	}
-
 	return files, nil
 }
@@ -0,0 +1,71 @@
+package fileutil
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+
+	"github.com/ZampoRen/go-server-comon/internal/infra/storage"
+)
+
+// urlStorage 只实现 GetObjectUrl，键为 bad 时返回错误
+type urlStorage struct {
+	storage.Storage
+	calls atomic.Int32
+}
+
+func (s *urlStorage) GetObjectUrl(ctx context.Context, objectKey string, opts ...storage.GetOptFn) (string, error) {
+	s.calls.Add(1)
+	if objectKey == "bad" {
+		return "", errors.New("presign failed")
+	}
+	return "https://example.com/" + objectKey, nil
+}
+
+func newFiles(keys ...string) []*storage.FileInfo {
+	files := make([]*storage.FileInfo, 0, len(keys))
+	for _, key := range keys {
+		files = append(files, &storage.FileInfo{Key: key})
+	}
+	return files
+}
+
+func TestAssembleFileUrl(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("并发生成", func(t *testing.T) {
+		keys := make([]string, 100)
+		for i := range keys {
+			keys[i] = fmt.Sprintf("k%d", i)
+		}
+		s := &urlStorage{}
+		files, err := AssembleFileUrl(ctx, &storage.GetOption{URLConcurrency: 8}, newFiles(keys...), s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, f := range files {
+			if f.URL != "https://example.com/"+f.Key {
+				t.Fatalf("URL of %s = %q", f.Key, f.URL)
+			}
+		}
+	})
+
+	t.Run("默认失败即返回", func(t *testing.T) {
+		if _, err := AssembleFileUrl(ctx, &storage.GetOption{}, newFiles("a", "bad", "c"), &urlStorage{}); err == nil {
+			t.Fatal("expected error")
+		}
+	})
+
+	t.Run("跳过失败的文件", func(t *testing.T) {
+		opt := &storage.GetOption{URLFailurePolicy: storage.URLSkipFailed}
+		files, err := AssembleFileUrl(ctx, opt, newFiles("a", "bad", "c"), &urlStorage{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if files[0].URL == "" || files[1].URL != "" || files[2].URL == "" {
+			t.Errorf("files = %+v %+v %+v", files[0], files[1], files[2])
+		}
+	})
+}
@@ -0,0 +1,138 @@
+package util
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/cloudwego/hertz/pkg/common/hlog"
+
+	"github.com/ZampoRen/go-server-comon/internal/infra/storage"
+	"github.com/ZampoRen/go-server-comon/pkg/taskgroup"
+)
+
+const (
+	// MaxCopyObjectSize 单次 CopyObject 支持的最大对象大小，超过时使用 UploadPartCopy 分片复制
+	MaxCopyObjectSize int64 = 5 << 30
+	// copyPartSize 分片复制的默认分片大小，对象过大时按 storage.MaxParts 增大
+	copyPartSize int64 = 512 << 20
+	// copyConcurrency 分片复制的并发数
+	copyConcurrency = 4
+)
+
+// S3Copier CopyObject 用到的 S3 API，*s3.Client 实现了该接口
+type S3Copier interface {
+	HeadObject(ctx context.Context, params *s3.HeadObjectInput, optFns ...func(*s3.Options)) (*s3.HeadObjectOutput, error)
+	CopyObject(ctx context.Context, params *s3.CopyObjectInput, optFns ...func(*s3.Options)) (*s3.CopyObjectOutput, error)
+	CreateMultipartUpload(ctx context.Context, params *s3.CreateMultipartUploadInput, optFns ...func(*s3.Options)) (*s3.CreateMultipartUploadOutput, error)
+	UploadPartCopy(ctx context.Context, params *s3.UploadPartCopyInput, optFns ...func(*s3.Options)) (*s3.UploadPartCopyOutput, error)
+	CompleteMultipartUpload(ctx context.Context, params *s3.CompleteMultipartUploadInput, optFns ...func(*s3.Options)) (*s3.CompleteMultipartUploadOutput, error)
+	AbortMultipartUpload(ctx context.Context, params *s3.AbortMultipartUploadInput, optFns ...func(*s3.Options)) (*s3.AbortMultipartUploadOutput, error)
+}
+
+// CopyObject 在 bucket 内将 srcKey 复制到 dstKey，源对象不存在时返回 storage.ErrObjectNotFound
+// 不超过 MaxCopyObjectSize 时使用单次 CopyObject；超过时使用 UploadPartCopy 分片复制，
+// 保留源对象的 Content-Type 等头与用户元数据，但不复制对象标签
+func CopyObject(ctx context.Context, c S3Copier, bucket, srcKey, dstKey string) error {
+	head, err := c.HeadObject(ctx, &s3.HeadObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(srcKey),
+	})
+	if err != nil {
+		var nf *types.NotFound
+		if errors.As(err, &nf) {
+			return storage.ErrObjectNotFound
+		}
+		return err
+	}
+
+	source := aws.String(bucket + "/" + url.PathEscape(srcKey))
+	size := aws.ToInt64(head.ContentLength)
+	if size <= MaxCopyObjectSize {
+		_, err = c.CopyObject(ctx, &s3.CopyObjectInput{
+			Bucket:     aws.String(bucket),
+			Key:        aws.String(dstKey),
+			CopySource: source,
+		})
+		var nsk *types.NoSuchKey
+		if errors.As(err, &nsk) {
+			return storage.ErrObjectNotFound
+		}
+		return err
+	}
+
+	created, err := c.CreateMultipartUpload(ctx, &s3.CreateMultipartUploadInput{
+		Bucket:             aws.String(bucket),
+		Key:                aws.String(dstKey),
+		ContentType:        head.ContentType,
+		ContentEncoding:    head.ContentEncoding,
+		ContentDisposition: head.ContentDisposition,
+		ContentLanguage:    head.ContentLanguage,
+		CacheControl:       head.CacheControl,
+		Metadata:           head.Metadata,
+	})
+	if err != nil {
+		return fmt.Errorf("init multipart copy failed, key: %s, err: %w", dstKey, err)
+	}
+	uploadID := created.UploadId
+
+	parts, err := copyParts(ctx, c, bucket, dstKey, uploadID, source, head.ETag, size)
+	if err == nil {
+		_, err = c.CompleteMultipartUpload(ctx, &s3.CompleteMultipartUploadInput{
+			Bucket:          aws.String(bucket),
+			Key:             aws.String(dstKey),
+			UploadId:        uploadID,
+			MultipartUpload: &types.CompletedMultipartUpload{Parts: parts},
+		})
+	}
+	if err != nil {
+		// 调用方 ctx 可能已取消，清理时不受其影响
+		_, abortErr := c.AbortMultipartUpload(context.WithoutCancel(ctx), &s3.AbortMultipartUploadInput{
+			Bucket:   aws.String(bucket),
+			Key:      aws.String(dstKey),
+			UploadId: uploadID,
+		})
+		if abortErr != nil {
+			hlog.CtxErrorf(ctx, "[Storage] abort multipart copy failed, key: %s, uploadID: %s, err: %v", dstKey, aws.ToString(uploadID), abortErr)
+		}
+		return err
+	}
+	return nil
+}
+
+// copyParts 按分片并发复制 [0, size)，以 etag 校验源对象在复制期间没有被修改
+func copyParts(ctx context.Context, c S3Copier, bucket, dstKey string, uploadID, source, etag *string, size int64) ([]types.CompletedPart, error) {
+	partSize := max(copyPartSize, (size+storage.MaxParts-1)/storage.MaxParts)
+	parts := make([]types.CompletedPart, (size+partSize-1)/partSize)
+	err := taskgroup.ForEach(ctx, parts, copyConcurrency, func(ctx context.Context, i int, _ types.CompletedPart) error {
+		start := int64(i) * partSize
+		end := min(start+partSize, size) - 1
+		partNumber := int32(i + 1)
+		out, err := c.UploadPartCopy(ctx, &s3.UploadPartCopyInput{
+			Bucket:            aws.String(bucket),
+			Key:               aws.String(dstKey),
+			UploadId:          uploadID,
+			PartNumber:        aws.Int32(partNumber),
+			CopySource:        source,
+			CopySourceRange:   aws.String(fmt.Sprintf("bytes=%d-%d", start, end)),
+			CopySourceIfMatch: etag,
+		})
+		if err != nil {
+			return fmt.Errorf("copy part %d failed, key: %s, err: %w", partNumber, dstKey, err)
+		}
+		var partETag *string
+		if out.CopyPartResult != nil {
+			partETag = out.CopyPartResult.ETag
+		}
+		parts[i] = types.CompletedPart{ETag: partETag, PartNumber: aws.Int32(partNumber)}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return parts, nil
+}
@@ -0,0 +1,154 @@
+package util
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+
+	"github.com/ZampoRen/go-server-comon/internal/infra/storage"
+)
+
+// copyServer 模拟 S3 复制相关接口，记录收到的请求
+type copyServer struct {
+	size     int64
+	failPart int
+
+	mu          sync.Mutex
+	copies      []string
+	ranges      map[int]string
+	ifMatch     string
+	contentType string
+	completed   []int
+	aborted     bool
+}
+
+func (s *copyServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	q := r.URL.Query()
+	switch {
+	case r.Method == http.MethodHead:
+		if r.URL.Path != "/bucket/src key" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Length", strconv.FormatInt(s.size, 10))
+		w.Header().Set("Content-Type", "video/mp4")
+		w.Header().Set("ETag", `"src-etag"`)
+	case r.Method == http.MethodPost && q.Has("uploads"):
+		s.contentType = r.Header.Get("Content-Type")
+		fmt.Fprint(w, `<InitiateMultipartUploadResult><UploadId>u1</UploadId></InitiateMultipartUploadResult>`)
+	case r.Method == http.MethodPut && q.Has("partNumber"):
+		n, _ := strconv.Atoi(q.Get("partNumber"))
+		if n == s.failPart {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		s.ranges[n] = r.Header.Get("X-Amz-Copy-Source-Range")
+		s.ifMatch = r.Header.Get("X-Amz-Copy-Source-If-Match")
+		fmt.Fprintf(w, `<CopyPartResult><ETag>"p%d"</ETag></CopyPartResult>`, n)
+	case r.Method == http.MethodPut:
+		s.copies = append(s.copies, r.Header.Get("X-Amz-Copy-Source"))
+		fmt.Fprint(w, `<CopyObjectResult><ETag>"e"</ETag></CopyObjectResult>`)
+	case r.Method == http.MethodPost && q.Has("uploadId"):
+		var body struct {
+			Parts []struct {
+				PartNumber int
+				ETag       string
+			} `xml:"Part"`
+		}
+		_ = xml.NewDecoder(r.Body).Decode(&body)
+		for _, p := range body.Parts {
+			if p.ETag != fmt.Sprintf(`"p%d"`, p.PartNumber) {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			s.completed = append(s.completed, p.PartNumber)
+		}
+		fmt.Fprint(w, `<CompleteMultipartUploadResult><ETag>"done"</ETag></CompleteMultipartUploadResult>`)
+	case r.Method == http.MethodDelete && q.Has("uploadId"):
+		s.aborted = true
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusBadRequest)
+	}
+}
+
+func newCopyClient(t *testing.T, s *copyServer) *s3.Client {
+	t.Helper()
+	s.ranges = make(map[int]string)
+	srv := httptest.NewServer(s)
+	t.Cleanup(srv.Close)
+	return s3.New(s3.Options{
+		Region:                     "cn-hangzhou",
+		Credentials:                credentials.NewStaticCredentialsProvider("ak", "sk", ""),
+		BaseEndpoint:               aws.String(srv.URL),
+		UsePathStyle:               true,
+		RequestChecksumCalculation: aws.RequestChecksumCalculationWhenRequired,
+		ResponseChecksumValidation: aws.ResponseChecksumValidationWhenRequired,
+	})
+}
+
+func TestCopyObject(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("不超过 5GB 时单次复制", func(t *testing.T) {
+		s := &copyServer{size: MaxCopyObjectSize}
+		if err := CopyObject(ctx, newCopyClient(t, s), "bucket", "src key", "dst"); err != nil {
+			t.Fatal(err)
+		}
+		if len(s.copies) != 1 || s.copies[0] != "bucket/src%20key" || len(s.ranges) != 0 {
+			t.Fatalf("copies = %v, parts = %v", s.copies, s.ranges)
+		}
+	})
+
+	t.Run("超过 5GB 时分片复制", func(t *testing.T) {
+		size := MaxCopyObjectSize + 1
+		s := &copyServer{size: size}
+		if err := CopyObject(ctx, newCopyClient(t, s), "bucket", "src key", "dst"); err != nil {
+			t.Fatal(err)
+		}
+		n := int((size + copyPartSize - 1) / copyPartSize)
+		if len(s.copies) != 0 || len(s.ranges) != n || len(s.completed) != n {
+			t.Fatalf("copies = %v, parts = %d, completed = %v, want %d parts", s.copies, len(s.ranges), s.completed, n)
+		}
+		var next int64
+		for i := 1; i <= n; i++ {
+			end := min(next+copyPartSize, size) - 1
+			if want := fmt.Sprintf("bytes=%d-%d", next, end); s.ranges[i] != want || s.completed[i-1] != i {
+				t.Fatalf("part %d range = %q, want %q, completed = %v", i, s.ranges[i], want, s.completed)
+			}
+			next = end + 1
+		}
+		if s.ifMatch != `"src-etag"` || s.contentType != "video/mp4" || s.aborted {
+			t.Fatalf("if-match = %q, content-type = %q, aborted = %v", s.ifMatch, s.contentType, s.aborted)
+		}
+	})
+
+	t.Run("分片失败时取消上传", func(t *testing.T) {
+		s := &copyServer{size: 3 * MaxCopyObjectSize, failPart: 2}
+		if err := CopyObject(ctx, newCopyClient(t, s), "bucket", "src key", "dst"); err == nil {
+			t.Fatal("CopyObject() should fail")
+		}
+		if !s.aborted || len(s.completed) != 0 {
+			t.Fatalf("aborted = %v, completed = %v", s.aborted, s.completed)
+		}
+	})
+
+	t.Run("源对象不存在", func(t *testing.T) {
+		s := &copyServer{}
+		if err := CopyObject(ctx, newCopyClient(t, s), "bucket", "missing", "dst"); !errors.Is(err, storage.ErrObjectNotFound) {
+			t.Fatalf("CopyObject() error = %v, want ErrObjectNotFound", err)
+		}
+	})
+}
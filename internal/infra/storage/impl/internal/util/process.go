@@ -0,0 +1,112 @@
+package util
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/smithy-go/middleware"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+
+	"github.com/ZampoRen/go-server-comon/internal/infra/storage"
+)
+
+// OSSProcess 返回阿里云 OSS 与火山引擎 TOS 通用的图片处理参数，如 image/resize,w_200/format,webp
+// 未设置处理参数时返回空字符串
+func OSSProcess(opt *storage.GetOption) string {
+	if opt.Process != "" {
+		return opt.Process
+	}
+	p := opt.ImageProcess
+	if p == nil {
+		return ""
+	}
+
+	actions := []string{"image"}
+	if p.Width > 0 || p.Height > 0 {
+		resize := "resize"
+		if p.Width > 0 {
+			resize += ",w_" + strconv.Itoa(p.Width)
+		}
+		if p.Height > 0 {
+			resize += ",h_" + strconv.Itoa(p.Height)
+		}
+		actions = append(actions, resize)
+	}
+	if p.Quality > 0 {
+		actions = append(actions, "quality,q_"+strconv.Itoa(p.Quality))
+	}
+	if p.Format != "" {
+		actions = append(actions, "format,"+p.Format)
+	}
+	if p.Watermark != "" {
+		actions = append(actions, "watermark,text_"+base64.URLEncoding.EncodeToString([]byte(p.Watermark)))
+	}
+	if len(actions) == 1 {
+		return ""
+	}
+	return strings.Join(actions, "/")
+}
+
+// CIProcess 返回腾讯云数据万象的图片处理参数，如 imageMogr2/thumbnail/200x/format/webp
+// 未设置处理参数时返回空字符串
+func CIProcess(opt *storage.GetOption) string {
+	if opt.Process != "" {
+		return opt.Process
+	}
+	p := opt.ImageProcess
+	if p == nil {
+		return ""
+	}
+
+	var steps []string
+	mogr := "imageMogr2"
+	if p.Width > 0 || p.Height > 0 {
+		mogr += "/thumbnail/"
+		if p.Width > 0 {
+			mogr += strconv.Itoa(p.Width)
+		}
+		mogr += "x"
+		if p.Height > 0 {
+			mogr += strconv.Itoa(p.Height)
+		}
+	}
+	if p.Quality > 0 {
+		mogr += "/quality/" + strconv.Itoa(p.Quality)
+	}
+	if p.Format != "" {
+		mogr += "/format/" + p.Format
+	}
+	if mogr != "imageMogr2" {
+		steps = append(steps, mogr)
+	}
+	if p.Watermark != "" {
+		steps = append(steps, "watermark/2/text/"+base64.URLEncoding.EncodeToString([]byte(p.Watermark)))
+	}
+	return strings.Join(steps, "|")
+}
+
+// WithPresignQuery 在签名前为请求附加查询参数，使其包含在签名中
+func WithPresignQuery(key, value string) func(*s3.PresignOptions) {
+	return func(o *s3.PresignOptions) {
+		o.ClientOptions = append(o.ClientOptions, func(o *s3.Options) {
+			o.APIOptions = append(o.APIOptions, func(stack *middleware.Stack) error {
+				return stack.Build.Add(middleware.BuildMiddlewareFunc("AddPresignQuery", func(
+					ctx context.Context, in middleware.BuildInput, next middleware.BuildHandler,
+				) (middleware.BuildOutput, middleware.Metadata, error) {
+					req, ok := in.Request.(*smithyhttp.Request)
+					if !ok {
+						return middleware.BuildOutput{}, middleware.Metadata{}, fmt.Errorf("unexpected request type %T", in.Request)
+					}
+					q := req.URL.Query()
+					q.Set(key, value)
+					req.URL.RawQuery = q.Encode()
+					return next.HandleBuild(ctx, in)
+				}), middleware.After)
+			})
+		})
+	}
+}
@@ -0,0 +1,74 @@
+package util
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+
+	"github.com/ZampoRen/go-server-comon/internal/infra/storage"
+)
+
+func TestProcess(t *testing.T) {
+	tests := []struct {
+		name    string
+		opt     storage.GetOption
+		wantOSS string
+		wantCI  string
+	}{
+		{"未设置", storage.GetOption{}, "", ""},
+		{"原样透传", storage.GetOption{Process: "style/thumb"}, "style/thumb", "style/thumb"},
+		{
+			"缩放与格式",
+			storage.GetOption{ImageProcess: &storage.ImageProcess{Width: 200, Format: "webp", Quality: 80}},
+			"image/resize,w_200/quality,q_80/format,webp",
+			"imageMogr2/thumbnail/200x/quality/80/format/webp",
+		},
+		{
+			"文字水印",
+			storage.GetOption{ImageProcess: &storage.ImageProcess{Height: 100, Watermark: "hi"}},
+			"image/resize,h_100/watermark,text_aGk=",
+			"imageMogr2/thumbnail/x100|watermark/2/text/aGk=",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := OSSProcess(&tt.opt); got != tt.wantOSS {
+				t.Errorf("OSSProcess() = %q, want %q", got, tt.wantOSS)
+			}
+			if got := CIProcess(&tt.opt); got != tt.wantCI {
+				t.Errorf("CIProcess() = %q, want %q", got, tt.wantCI)
+			}
+		})
+	}
+}
+
+func TestWithPresignQuery(t *testing.T) {
+	client := s3.New(s3.Options{
+		Region:       "cn-hangzhou",
+		Credentials:  credentials.NewStaticCredentialsProvider("ak", "sk", ""),
+		BaseEndpoint: aws.String("https://oss-cn-hangzhou.aliyuncs.com"),
+	})
+	req, err := s3.NewPresignClient(client).PresignGetObject(context.Background(), &s3.GetObjectInput{
+		Bucket: aws.String("bucket"),
+		Key:    aws.String("a.jpg"),
+	}, WithPresignQuery("x-oss-process", "image/resize,w_200"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	u, err := url.Parse(req.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := u.Query().Get("x-oss-process"); got != "image/resize,w_200" {
+		t.Errorf("x-oss-process = %q, url: %s", got, req.URL)
+	}
+	if !strings.Contains(req.URL, "X-Amz-Signature=") {
+		t.Errorf("url not signed: %s", req.URL)
+	}
+}
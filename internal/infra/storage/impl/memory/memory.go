@@ -0,0 +1,444 @@
+package memory
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ZampoRen/go-server-comon/internal/infra/storage"
+	"github.com/ZampoRen/go-server-comon/internal/infra/storage/impl/internal/fileutil"
+)
+
+var (
+	// ErrNoSuchUpload 分片上传不存在或已完成、已取消
+	ErrNoSuchUpload = errors.New("memory: no such upload")
+	// ErrInvalidPart 合并时分片不存在、ETag 不一致或未按 PartNumber 升序排列
+	ErrInvalidPart = errors.New("memory: invalid part")
+)
+
+// Option 构造选项
+type Option func(o *option)
+
+type option struct {
+	bucket    string
+	urlPrefix string
+	now       func() time.Time
+}
+
+// WithBucket 设置存储桶名称，默认 memory
+func WithBucket(bucket string) Option {
+	return func(o *option) {
+		if bucket != "" {
+			o.bucket = bucket
+		}
+	}
+}
+
+// WithURLPrefix 设置 GetObjectUrl 返回的 URL 前缀，默认 memory://<bucket>/
+func WithURLPrefix(prefix string) Option {
+	return func(o *option) {
+		o.urlPrefix = prefix
+	}
+}
+
+// WithClock 设置时钟，用于 LastModified 与 URL 过期时间
+func WithClock(now func() time.Time) Option {
+	return func(o *option) {
+		if now != nil {
+			o.now = now
+		}
+	}
+}
+
+// object 存储的对象
+type object struct {
+	data         []byte
+	contentType  string
+	tagging      map[string]string
+	metadata     map[string]string
+	etag         string
+	lastModified time.Time
+}
+
+// upload 进行中的分片上传
+type upload struct {
+	objectKey string
+	option    storage.PutOption
+	metadata  map[string]string
+	parts     map[int32][]byte
+}
+
+// memoryStorage 进程内的 storage.Storage 实现，适用于单元测试
+type memoryStorage struct {
+	mu       sync.RWMutex
+	opt      *option
+	objects  map[string]*object
+	uploads  map[string]*upload
+	uploadID int64
+}
+
+// New 创建进程内存储，行为与云厂商实现保持一致：
+// 分页按键的字典序返回，不存在的对象返回 storage.ErrObjectNotFound，
+// GetObjectUrl 返回形如 memory://<bucket>/<key>?expires=<unix> 的占位 URL
+func New(opts ...Option) storage.Storage {
+	o := &option{
+		bucket: "memory",
+		now:    time.Now,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	if o.urlPrefix == "" {
+		o.urlPrefix = "memory://" + o.bucket + "/"
+	}
+
+	return &memoryStorage{
+		opt:     o,
+		objects: make(map[string]*object),
+		uploads: make(map[string]*upload),
+	}
+}
+
+func etag(data []byte) string {
+	sum := md5.Sum(data)
+	return `"` + hex.EncodeToString(sum[:]) + `"`
+}
+
+func copyMap(m map[string]string) map[string]string {
+	if len(m) == 0 {
+		return nil
+	}
+	out := make(map[string]string, len(m))
+	for k, v := range m {
+		out[k] = v
+	}
+	return out
+}
+
+// put 保存对象，调用方需持有写锁
+func (m *memoryStorage) put(objectKey string, data []byte, option *storage.PutOption, metadata map[string]string) {
+	obj := &object{
+		data:         data,
+		tagging:      copyMap(option.Tagging),
+		metadata:     metadata,
+		etag:         etag(data),
+		lastModified: m.opt.now(),
+	}
+	if option.ContentType != nil {
+		obj.contentType = *option.ContentType
+	}
+	m.objects[objectKey] = obj
+}
+
+func (m *memoryStorage) PutObject(ctx context.Context, objectKey string, content []byte, opts ...storage.PutOptFn) error {
+	opts = append(opts, storage.WithObjectSize(int64(len(content))))
+	return m.PutObjectWithReader(ctx, objectKey, bytes.NewReader(content), opts...)
+}
+
+func (m *memoryStorage) PutObjectWithReader(ctx context.Context, objectKey string, content io.Reader, opts ...storage.PutOptFn) error {
+	option := storage.PutOption{}
+	for _, opt := range opts {
+		opt(&option)
+	}
+
+	content, checksums, cleanup, err := storage.ComputeChecksums(content, &option)
+	if err != nil {
+		return fmt.Errorf("compute checksums failed: %w", err)
+	}
+	defer cleanup()
+	data, err := io.ReadAll(content)
+	if err != nil {
+		return err
+	}
+	var metadata map[string]string
+	if checksums != nil {
+		metadata = checksums.Metadata
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.put(objectKey, data, &option, metadata)
+	return nil
+}
+
+func (m *memoryStorage) GetObject(ctx context.Context, objectKey string) ([]byte, error) {
+	m.mu.RLock()
+	obj, ok := m.objects[objectKey]
+	m.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("get object failed: %w", storage.ErrObjectNotFound)
+	}
+
+	if err := storage.VerifyChecksum(objectKey, obj.data, obj.metadata); err != nil {
+		return nil, err
+	}
+	return bytes.Clone(obj.data), nil
+}
+
+func (m *memoryStorage) GetObjectReader(ctx context.Context, objectKey string) (io.ReadCloser, error) {
+	data, err := m.GetObject(ctx, objectKey)
+	if err != nil {
+		return nil, err
+	}
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
+
+func (m *memoryStorage) DeleteObject(ctx context.Context, objectKey string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.objects, objectKey)
+	return nil
+}
+
+func (m *memoryStorage) GetObjectUrl(ctx context.Context, objectKey string, opts ...storage.GetOptFn) (string, error) {
+	opt := storage.GetOption{}
+	for _, optFn := range opts {
+		optFn(&opt)
+	}
+
+	expire := int64(60 * 60 * 24) // 默认 1 天
+	if opt.Expire > 0 {
+		expire = opt.Expire
+	}
+	expiresAt := m.opt.now().Add(time.Duration(expire) * time.Second).Unix()
+	u := fmt.Sprintf("%s%s?expires=%d", m.opt.urlPrefix, url.PathEscape(objectKey), expiresAt)
+	if opt.Process != "" {
+		u += "&process=" + url.QueryEscape(opt.Process)
+	}
+	return u, nil
+}
+
+func (m *memoryStorage) ListAllObjects(ctx context.Context, prefix string, opts ...storage.GetOptFn) ([]*storage.FileInfo, error) {
+	const DefaultPageSize = 100
+
+	var files []*storage.FileInfo
+	var cursor string
+	for {
+		output, err := m.ListObjectsPaginated(ctx, &storage.ListObjectsPaginatedInput{
+			Prefix:   prefix,
+			PageSize: DefaultPageSize,
+			Cursor:   cursor,
+		}, opts...)
+		if err != nil {
+			return nil, err
+		}
+
+		cursor = output.Cursor
+		files = append(files, output.Files...)
+		if !output.IsTruncated {
+			break
+		}
+	}
+
+	return files, nil
+}
+
+// ListObjectsPaginated 按键的字典序分页，游标为上一页最后一个键
+func (m *memoryStorage) ListObjectsPaginated(ctx context.Context, input *storage.ListObjectsPaginatedInput, opts ...storage.GetOptFn) (*storage.ListObjectsPaginatedOutput, error) {
+	if input == nil {
+		return nil, fmt.Errorf("input cannot be nil")
+	}
+	if input.PageSize <= 0 {
+		return nil, fmt.Errorf("page size must be positive")
+	}
+
+	opt := storage.GetOption{}
+	for _, optFn := range opts {
+		optFn(&opt)
+	}
+
+	m.mu.RLock()
+	keys := make([]string, 0, len(m.objects))
+	for key := range m.objects {
+		if strings.HasPrefix(key, input.Prefix) && key > input.Cursor {
+			keys = append(keys, key)
+		}
+	}
+	slices.Sort(keys)
+
+	output := &storage.ListObjectsPaginatedOutput{}
+	if len(keys) > input.PageSize {
+		keys = keys[:input.PageSize]
+		output.IsTruncated = true
+		output.Cursor = keys[len(keys)-1]
+	}
+	for _, key := range keys {
+		output.Files = append(output.Files, m.fileInfo(key, m.objects[key], opt.WithTagging))
+	}
+	m.mu.RUnlock()
+
+	if opt.WithURL {
+		files, err := fileutil.AssembleFileUrl(ctx, &opt, output.Files, m)
+		if err != nil {
+			return nil, err
+		}
+		output.Files = files
+	}
+
+	return output, nil
+}
+
+// fileInfo 返回对象的元数据，调用方需持有读锁
+func (m *memoryStorage) fileInfo(objectKey string, obj *object, withTagging bool) *storage.FileInfo {
+	f := &storage.FileInfo{
+		Key:          objectKey,
+		LastModified: obj.lastModified,
+		ETag:         obj.etag,
+		Size:         int64(len(obj.data)),
+	}
+	if withTagging {
+		f.Tagging = copyMap(obj.tagging)
+	}
+	return f
+}
+
+func (m *memoryStorage) HeadObject(ctx context.Context, objectKey string, opts ...storage.GetOptFn) (*storage.FileInfo, error) {
+	opt := storage.GetOption{}
+	for _, optFn := range opts {
+		optFn(&opt)
+	}
+
+	m.mu.RLock()
+	obj, ok := m.objects[objectKey]
+	if !ok {
+		m.mu.RUnlock()
+		return nil, storage.ErrObjectNotFound
+	}
+	f := m.fileInfo(objectKey, obj, opt.WithTagging)
+	m.mu.RUnlock()
+
+	if opt.WithURL {
+		var err error
+		f.URL, err = m.GetObjectUrl(ctx, objectKey, opts...)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return f, nil
+}
+
+func (m *memoryStorage) InitMultipartUpload(ctx context.Context, objectKey string, opts ...storage.PutOptFn) (string, error) {
+	option := storage.PutOption{}
+	for _, opt := range opts {
+		opt(&option)
+	}
+	metadata, err := storage.MultipartMetadata(&option)
+	if err != nil {
+		return "", err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.uploadID++
+	uploadID := fmt.Sprintf("upload-%d", m.uploadID)
+	m.uploads[uploadID] = &upload{
+		objectKey: objectKey,
+		option:    option,
+		metadata:  metadata,
+		parts:     make(map[int32][]byte),
+	}
+	return uploadID, nil
+}
+
+// lookupUpload 返回 objectKey 对应的分片上传，调用方需持有锁
+func (m *memoryStorage) lookupUpload(objectKey, uploadID string) (*upload, error) {
+	u, ok := m.uploads[uploadID]
+	if !ok || u.objectKey != objectKey {
+		return nil, ErrNoSuchUpload
+	}
+	return u, nil
+}
+
+func (m *memoryStorage) UploadPart(ctx context.Context, objectKey, uploadID string, partNumber int32, content io.Reader, size int64) (*storage.CompletedPart, error) {
+	if partNumber < 1 || partNumber > storage.MaxParts {
+		return nil, fmt.Errorf("%w: part number %d out of range", ErrInvalidPart, partNumber)
+	}
+	data, err := io.ReadAll(content)
+	if err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	u, err := m.lookupUpload(objectKey, uploadID)
+	if err != nil {
+		return nil, err
+	}
+	u.parts[partNumber] = data
+	return &storage.CompletedPart{PartNumber: partNumber, ETag: etag(data)}, nil
+}
+
+func (m *memoryStorage) CompleteMultipartUpload(ctx context.Context, objectKey, uploadID string, parts []storage.CompletedPart) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	u, err := m.lookupUpload(objectKey, uploadID)
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	for i, p := range parts {
+		data, ok := u.parts[p.PartNumber]
+		if !ok || p.ETag != etag(data) || (i > 0 && p.PartNumber <= parts[i-1].PartNumber) {
+			return fmt.Errorf("%w: part number %d", ErrInvalidPart, p.PartNumber)
+		}
+		buf.Write(data)
+	}
+
+	m.put(objectKey, buf.Bytes(), &u.option, u.metadata)
+	delete(m.uploads, uploadID)
+	return nil
+}
+
+func (m *memoryStorage) AbortMultipartUpload(ctx context.Context, objectKey, uploadID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, err := m.lookupUpload(objectKey, uploadID); err != nil {
+		return err
+	}
+	delete(m.uploads, uploadID)
+	return nil
+}
+
+func (m *memoryStorage) CopyObject(ctx context.Context, srcKey, dstKey string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	src, ok := m.objects[srcKey]
+	if !ok {
+		return storage.ErrObjectNotFound
+	}
+
+	dst := *src
+	dst.lastModified = m.opt.now()
+	m.objects[dstKey] = &dst
+	return nil
+}
+
+func (m *memoryStorage) MoveObject(ctx context.Context, srcKey, dstKey string) error {
+	if err := m.CopyObject(ctx, srcKey, dstKey); err != nil {
+		return err
+	}
+	if srcKey == dstKey {
+		return nil
+	}
+	return m.DeleteObject(ctx, srcKey)
+}
+
+func (m *memoryStorage) DeleteObjects(ctx context.Context, objectKeys []string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, key := range objectKeys {
+		delete(m.objects, key)
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+package memory
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/ZampoRen/go-server-comon/internal/infra/storage"
+)
+
+func TestMemory(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("上传下载与元数据", func(t *testing.T) {
+		s := New()
+		err := s.PutObject(ctx, "a.txt", []byte("hello"), storage.WithTagging(map[string]string{"env": "test"}), storage.WithCRC64())
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, err := s.GetObject(ctx, "a.txt")
+		if err != nil || string(data) != "hello" {
+			t.Fatalf("GetObject() = %q, %v", data, err)
+		}
+		f, err := s.HeadObject(ctx, "a.txt", storage.WithGetTagging(true), storage.WithURL(true))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if f.Size != 5 || f.Tagging["env"] != "test" || !strings.HasPrefix(f.URL, "memory://memory/a.txt?expires=") {
+			t.Errorf("HeadObject() = %+v", f)
+		}
+		if _, err := s.HeadObject(ctx, "missing"); !errors.Is(err, storage.ErrObjectNotFound) {
+			t.Errorf("HeadObject(missing) error = %v", err)
+		}
+		if _, err := s.GetObject(ctx, "missing"); !errors.Is(err, storage.ErrObjectNotFound) {
+			t.Errorf("GetObject(missing) error = %v", err)
+		}
+	})
+
+	t.Run("分页", func(t *testing.T) {
+		s := New()
+		for i := 0; i < 5; i++ {
+			_ = s.PutObject(ctx, fmt.Sprintf("dir/%d", i), []byte("x"))
+		}
+		_ = s.PutObject(ctx, "other", []byte("x"))
+
+		var keys []string
+		cursor := ""
+		for pages := 0; ; pages++ {
+			out, err := s.ListObjectsPaginated(ctx, &storage.ListObjectsPaginatedInput{Prefix: "dir/", PageSize: 2, Cursor: cursor})
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, f := range out.Files {
+				keys = append(keys, f.Key)
+			}
+			if !out.IsTruncated {
+				break
+			}
+			cursor = out.Cursor
+		}
+		if got := strings.Join(keys, ","); got != "dir/0,dir/1,dir/2,dir/3,dir/4" {
+			t.Errorf("keys = %s", got)
+		}
+	})
+
+	t.Run("分片上传", func(t *testing.T) {
+		s := New()
+		data := bytes.Repeat([]byte("0123456789"), int(storage.MinPartSize/10)*2+3)
+		if err := storage.PutLargeObject(ctx, s, "big", bytes.NewReader(data), storage.MinPartSize, 2); err != nil {
+			t.Fatal(err)
+		}
+		got, err := s.GetObject(ctx, "big")
+		if err != nil || !bytes.Equal(got, data) {
+			t.Fatalf("GetObject(big) len = %d, err = %v", len(got), err)
+		}
+
+		uploadID, _ := s.InitMultipartUpload(ctx, "aborted")
+		if err := s.AbortMultipartUpload(ctx, "aborted", uploadID); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := s.UploadPart(ctx, "aborted", uploadID, 1, strings.NewReader("x"), 1); !errors.Is(err, ErrNoSuchUpload) {
+			t.Errorf("UploadPart() after abort error = %v", err)
+		}
+	})
+
+	t.Run("复制移动与批量删除", func(t *testing.T) {
+		s := New()
+		_ = s.PutObject(ctx, "src", []byte("v"))
+		if err := s.CopyObject(ctx, "src", "copy"); err != nil {
+			t.Fatal(err)
+		}
+		if err := s.MoveObject(ctx, "src", "moved"); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := s.HeadObject(ctx, "src"); !errors.Is(err, storage.ErrObjectNotFound) {
+			t.Errorf("src still exists after move, err = %v", err)
+		}
+		if err := s.DeleteObjects(ctx, []string{"copy", "moved", "missing"}); err != nil {
+			t.Fatal(err)
+		}
+		files, _ := s.ListAllObjects(ctx, "")
+		if len(files) != 0 {
+			t.Errorf("ListAllObjects() = %d files, want 0", len(files))
+		}
+	})
+}
@@ -3,9 +3,13 @@ package impl
 import (
 	"context"
 	"fmt"
+	"os"
 
 	"github.com/ZampoRen/go-server-comon/internal/infra/storage"
 	"github.com/ZampoRen/go-server-comon/internal/infra/storage/impl/aliyun"
+	"github.com/ZampoRen/go-server-comon/internal/infra/storage/impl/failover"
+	"github.com/ZampoRen/go-server-comon/internal/infra/storage/impl/gcs"
+	"github.com/ZampoRen/go-server-comon/internal/infra/storage/impl/memory"
 	"github.com/ZampoRen/go-server-comon/internal/infra/storage/impl/tencent"
 	"github.com/ZampoRen/go-server-comon/internal/infra/storage/impl/volcengine"
 	"github.com/ZampoRen/go-server-comon/pkg/envkey"
@@ -15,17 +19,57 @@ import (
 type Storage = storage.Storage
 
 // New 根据环境变量创建存储客户端
-// 支持的类型: tos, aliyun, tencent
+// 支持的类型: tos, aliyun, tencent, gcs, memory（进程内存储，仅用于本地开发与测试）
 // 环境变量:
-//   - STORAGE_TYPE: 存储类型 (tos/aliyun/tencent)
+//   - STORAGE_TYPE: 存储类型 (tos/aliyun/tencent/gcs/memory)
 //   - STORAGE_BUCKET: 存储桶名称
+//   - STORAGE_MIRROR_TYPE: 镜像存储类型，设置后开启双写模式（写主存储并异步镜像到该存储，读主存储失败时回退）
+//   - STORAGE_MIRROR_BUCKET: 镜像存储桶名称（默认与 STORAGE_BUCKET 相同）
+//   - STORAGE_OBSERVABILITY: 是否为每个存储开启链路追踪与 Prometheus 指标（默认 false），见 storage.WithObservability
 //   - TOS_ACCESS_KEY, TOS_SECRET_KEY, TOS_ENDPOINT, TOS_REGION: 火山引擎 TOS 配置
 //   - ALIYUN_ACCESS_KEY, ALIYUN_SECRET_KEY, ALIYUN_ENDPOINT, ALIYUN_REGION: 阿里云 OSS 配置
 //   - TENCENT_ACCESS_KEY, TENCENT_SECRET_KEY, TENCENT_ENDPOINT, TENCENT_REGION: 腾讯云 COS 配置
+//   - GCS_CREDENTIALS_JSON, GCS_CREDENTIALS_FILE: Google Cloud Storage 服务账号密钥内容或文件路径，
+//     都为空时使用 Application Default Credentials（如 GKE Workload Identity）
+//   - GCS_ENDPOINT: GCS 访问地址（默认 https://storage.googleapis.com）
+//   - GCS_SIGNER_EMAIL: 未提供服务账号密钥时用于生成预签名 URL 的服务账号邮箱（默认从元数据服务读取）
 func New(ctx context.Context) (Storage, error) {
 	storageType := envkey.GetStringD("STORAGE_TYPE", "")
 	bucketName := envkey.GetStringD("STORAGE_BUCKET", "")
 
+	primary, err := newFromEnv(ctx, storageType, bucketName)
+	if err != nil {
+		return nil, err
+	}
+
+	mirrorType := envkey.GetStringD("STORAGE_MIRROR_TYPE", "")
+	if mirrorType == "" {
+		return primary, nil
+	}
+
+	mirrorBucket := envkey.GetStringD("STORAGE_MIRROR_BUCKET", bucketName)
+	secondary, err := newFromEnv(ctx, mirrorType, mirrorBucket)
+	if err != nil {
+		return nil, fmt.Errorf("init mirror storage failed: %w", err)
+	}
+
+	return failover.New(primary, secondary), nil
+}
+
+// newFromEnv 根据存储类型从对应的环境变量读取配置并创建存储客户端
+func newFromEnv(ctx context.Context, storageType, bucketName string) (Storage, error) {
+	s, err := newClientFromEnv(ctx, storageType, bucketName)
+	if err != nil {
+		return nil, err
+	}
+	if envkey.GetBoolD("STORAGE_OBSERVABILITY", false) {
+		s = storage.WithObservability(s, storage.WithLabels(storageType, bucketName))
+	}
+	return s, nil
+}
+
+// newClientFromEnv 根据存储类型从对应的环境变量读取厂商配置
+func newClientFromEnv(ctx context.Context, storageType, bucketName string) (Storage, error) {
 	switch storageType {
 	case "tos":
 		return volcengine.New(
@@ -54,12 +98,29 @@ func New(ctx context.Context) (Storage, error) {
 			envkey.GetStringD("TENCENT_ENDPOINT", ""),
 			envkey.GetStringD("TENCENT_REGION", ""),
 		)
+	case "gcs":
+		credentialsJSON := []byte(envkey.GetStringD("GCS_CREDENTIALS_JSON", ""))
+		if file := envkey.GetStringD("GCS_CREDENTIALS_FILE", ""); len(credentialsJSON) == 0 && file != "" {
+			var err error
+			if credentialsJSON, err = os.ReadFile(file); err != nil {
+				return nil, fmt.Errorf("read gcs credentials file failed: %w", err)
+			}
+		}
+		return gcs.NewWithConfig(ctx, &gcs.Config{
+			Bucket:          bucketName,
+			CredentialsJSON: credentialsJSON,
+			Endpoint:        envkey.GetStringD("GCS_ENDPOINT", ""),
+			SignerEmail:     envkey.GetStringD("GCS_SIGNER_EMAIL", ""),
+		})
+	case "memory":
+		return memory.New(memory.WithBucket(bucketName)), nil
 	default:
-		return nil, fmt.Errorf("unknown storage type: %s, supported types: tos, aliyun, tencent", storageType)
+		return nil, fmt.Errorf("unknown storage type: %s, supported types: tos, aliyun, tencent, gcs, memory", storageType)
 	}
 }
 
 // NewWithType 根据指定类型创建存储客户端
+// 仅支持基于 AK/SK 的厂商，GCS 请使用 gcs.NewWithConfig
 func NewWithType(ctx context.Context, storageType string, ak, sk, bucketName, endpoint, region string) (Storage, error) {
 	switch storageType {
 	case "tos":
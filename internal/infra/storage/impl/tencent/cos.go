@@ -19,6 +19,7 @@ import (
 	"github.com/ZampoRen/go-server-comon/internal/infra/storage"
 	"github.com/ZampoRen/go-server-comon/internal/infra/storage/impl/internal/fileutil"
 	"github.com/ZampoRen/go-server-comon/internal/infra/storage/impl/internal/util"
+	"github.com/ZampoRen/go-server-comon/pkg/taskgroup"
 )
 
 type cosClient struct {
@@ -108,12 +109,25 @@ func (t *cosClient) PutObjectWithReader(ctx context.Context, objectKey string, c
 		opt(&option)
 	}
 
+	content, checksums, cleanup, err := storage.ComputeChecksums(content, &option)
+	if err != nil {
+		return fmt.Errorf("compute checksums failed: %w", err)
+	}
+	defer cleanup()
+
 	input := &s3.PutObjectInput{
 		Bucket: aws.String(bucket),
 		Key:    aws.String(objectKey),
 		Body:   content,
 	}
 
+	if checksums != nil {
+		input.Metadata = checksums.Metadata
+		if checksums.ContentMD5 != "" {
+			input.ContentMD5 = aws.String(checksums.ContentMD5)
+		}
+	}
+
 	if option.ContentType != nil {
 		input.ContentType = option.ContentType
 	}
@@ -138,7 +152,7 @@ func (t *cosClient) PutObjectWithReader(ctx context.Context, objectKey string, c
 		input.Tagging = aws.String(util.MapToQuery(option.Tagging))
 	}
 
-	_, err := client.PutObject(ctx, input)
+	_, err = client.PutObject(ctx, input)
 	return err
 }
 
@@ -151,7 +165,7 @@ func (t *cosClient) GetObject(ctx context.Context, objectKey string) ([]byte, er
 		Key:    aws.String(objectKey),
 	})
 	if err != nil {
-		return nil, fmt.Errorf("get object failed: %v", err)
+		return nil, fmt.Errorf("get object failed: %w", err)
 	}
 	defer result.Body.Close()
 
@@ -160,9 +174,24 @@ func (t *cosClient) GetObject(ctx context.Context, objectKey string) ([]byte, er
 		return nil, err
 	}
 
+	if err := storage.VerifyChecksum(objectKey, body, result.Metadata); err != nil {
+		return nil, err
+	}
+
 	return body, nil
 }
 
+func (t *cosClient) GetObjectReader(ctx context.Context, objectKey string) (io.ReadCloser, error) {
+	result, err := t.client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(t.bucketName),
+		Key:    aws.String(objectKey),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("get object failed: %w", err)
+	}
+	return storage.NewVerifyingReader(objectKey, result.Body, result.Metadata), nil
+}
+
 func (t *cosClient) DeleteObject(ctx context.Context, objectKey string) error {
 	client := t.client
 	bucket := t.bucketName
@@ -197,7 +226,12 @@ func (t *cosClient) GetObjectUrl(ctx context.Context, objectKey string, opts ...
 		options.Expires = time.Duration(expire) * time.Second
 	})
 	if err != nil {
-		return "", fmt.Errorf("get object presigned url failed: %v", err)
+		return "", fmt.Errorf("get object presigned url failed: %w", err)
+	}
+
+	// 数据万象的处理参数不参与签名，直接附加在预签名 URL 之后
+	if process := util.CIProcess(&opt); process != "" {
+		return req.URL + "&" + process, nil
 	}
 
 	return req.URL, nil
@@ -296,23 +330,26 @@ func (t *cosClient) ListObjectsPaginated(ctx context.Context, input *storage.Lis
 	}
 
 	if opt.WithTagging {
-		// 简化实现，不使用 taskgroup
-		for _, f := range files {
+		err := taskgroup.ForEach(ctx, files, fileutil.Concurrency(&opt), func(ctx context.Context, _ int, f *storage.FileInfo) error {
 			tagging, err := client.GetObjectTagging(ctx, &s3.GetObjectTaggingInput{
 				Bucket: aws.String(bucket),
 				Key:    aws.String(f.Key),
 			})
 			if err != nil {
-				return nil, err
+				return err
 			}
 
 			f.Tagging = tagsToMap(tagging.TagSet)
+			return nil
+		})
+		if err != nil {
+			return nil, err
 		}
 	}
 
 	if opt.WithURL {
 		var err error
-		files, err = fileutil.AssembleFileUrl(ctx, &opt.Expire, files, t)
+		files, err = fileutil.AssembleFileUrl(ctx, &opt, files, t)
 		if err != nil {
 			return nil, err
 		}
@@ -389,3 +426,122 @@ func tagsToMap(tags []types.Tag) map[string]string {
 	}
 	return m
 }
+
+func (t *cosClient) InitMultipartUpload(ctx context.Context, objectKey string, opts ...storage.PutOptFn) (string, error) {
+	option := storage.PutOption{}
+	for _, opt := range opts {
+		opt(&option)
+	}
+
+	input := &s3.CreateMultipartUploadInput{
+		Bucket:             aws.String(t.bucketName),
+		Key:                aws.String(objectKey),
+		ContentType:        option.ContentType,
+		ContentEncoding:    option.ContentEncoding,
+		ContentDisposition: option.ContentDisposition,
+		ContentLanguage:    option.ContentLanguage,
+		Expires:            option.Expires,
+	}
+	if option.Tagging != nil {
+		input.Tagging = aws.String(util.MapToQuery(option.Tagging))
+	}
+	metadata, err := storage.MultipartMetadata(&option)
+	if err != nil {
+		return "", err
+	}
+	input.Metadata = metadata
+
+	output, err := t.client.CreateMultipartUpload(ctx, input)
+	if err != nil {
+		return "", err
+	}
+	return aws.ToString(output.UploadId), nil
+}
+
+func (t *cosClient) UploadPart(ctx context.Context, objectKey, uploadID string, partNumber int32, content io.Reader, size int64) (*storage.CompletedPart, error) {
+	output, err := t.client.UploadPart(ctx, &s3.UploadPartInput{
+		Bucket:        aws.String(t.bucketName),
+		Key:           aws.String(objectKey),
+		UploadId:      aws.String(uploadID),
+		PartNumber:    aws.Int32(partNumber),
+		Body:          content,
+		ContentLength: aws.Int64(size),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &storage.CompletedPart{
+		PartNumber: partNumber,
+		ETag:       aws.ToString(output.ETag),
+	}, nil
+}
+
+func (t *cosClient) CompleteMultipartUpload(ctx context.Context, objectKey, uploadID string, parts []storage.CompletedPart) error {
+	completed := make([]types.CompletedPart, 0, len(parts))
+	for _, p := range parts {
+		completed = append(completed, types.CompletedPart{
+			PartNumber: aws.Int32(p.PartNumber),
+			ETag:       aws.String(p.ETag),
+		})
+	}
+
+	_, err := t.client.CompleteMultipartUpload(ctx, &s3.CompleteMultipartUploadInput{
+		Bucket:          aws.String(t.bucketName),
+		Key:             aws.String(objectKey),
+		UploadId:        aws.String(uploadID),
+		MultipartUpload: &types.CompletedMultipartUpload{Parts: completed},
+	})
+	return err
+}
+
+func (t *cosClient) AbortMultipartUpload(ctx context.Context, objectKey, uploadID string) error {
+	_, err := t.client.AbortMultipartUpload(ctx, &s3.AbortMultipartUploadInput{
+		Bucket:   aws.String(t.bucketName),
+		Key:      aws.String(objectKey),
+		UploadId: aws.String(uploadID),
+	})
+	return err
+}
+
+func (t *cosClient) CopyObject(ctx context.Context, srcKey, dstKey string) error {
+	return util.CopyObject(ctx, t.client, t.bucketName, srcKey, dstKey)
+}
+
+func (t *cosClient) MoveObject(ctx context.Context, srcKey, dstKey string) error {
+	if err := t.CopyObject(ctx, srcKey, dstKey); err != nil {
+		return err
+	}
+	return t.DeleteObject(ctx, srcKey)
+}
+
+func (t *cosClient) DeleteObjects(ctx context.Context, objectKeys []string) error {
+	failed := make(map[string]error)
+	for start := 0; start < len(objectKeys); start += storage.MaxDeleteObjects {
+		end := min(start+storage.MaxDeleteObjects, len(objectKeys))
+
+		objects := make([]types.ObjectIdentifier, 0, end-start)
+		for _, key := range objectKeys[start:end] {
+			objects = append(objects, types.ObjectIdentifier{Key: aws.String(key)})
+		}
+
+		output, err := t.client.DeleteObjects(ctx, &s3.DeleteObjectsInput{
+			Bucket: aws.String(t.bucketName),
+			Delete: &types.Delete{
+				Objects: objects,
+				Quiet:   aws.Bool(true), // 只返回删除失败的对象
+			},
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, e := range output.Errors {
+			failed[aws.ToString(e.Key)] = fmt.Errorf("%s: %s", aws.ToString(e.Code), aws.ToString(e.Message))
+		}
+	}
+
+	if len(failed) > 0 {
+		return &storage.DeleteObjectsError{Failed: failed}
+	}
+	return nil
+}
@@ -0,0 +1,168 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/cloudwego/hertz/pkg/common/hlog"
+)
+
+const (
+	// MinPartSize 分片最小大小（最后一个分片除外），各厂商均为 5MB
+	MinPartSize int64 = 5 << 20
+	// DefaultPartSize 默认分片大小
+	DefaultPartSize int64 = 16 << 20
+	// MaxParts 单次分片上传的最大分片数
+	MaxParts = 10000
+)
+
+// ErrTooManyParts 分片数超过 MaxParts，需要增大分片大小
+var ErrTooManyParts = errors.New("too many parts, increase part size")
+
+// PutLargeObject 使用分片上传将 r 中的内容上传到 objectKey
+// partSize 小于 MinPartSize 时使用 DefaultPartSize，concurrency 小于 1 时为 1；
+// 内存占用约为 partSize * (concurrency + 1)。
+// 内容不足一个分片时退化为 PutObject；任一分片失败时取消上传并清理已上传的分片。
+// 开启 WithContentMD5/WithCRC64 时先计算整个对象的校验和写入元数据，供下载时校验，
+// r 不支持 io.Seeker 时会先写入临时文件；上传时逐个分片比对 ETag 与本地 MD5
+func PutLargeObject(ctx context.Context, s Storage, objectKey string, r io.Reader, partSize int64, concurrency int, opts ...PutOptFn) error {
+	if partSize < MinPartSize {
+		partSize = DefaultPartSize
+	}
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	option := PutOption{}
+	for _, opt := range opts {
+		opt(&option)
+	}
+	verify := option.ContentMD5 || option.CRC64
+	if verify && option.Checksums == nil {
+		body, sums, cleanup, err := ComputeChecksums(r, &option)
+		if err != nil {
+			return fmt.Errorf("compute checksums failed, key: %s, err: %w", objectKey, err)
+		}
+		defer cleanup()
+		r = body
+		opts = append(opts[:len(opts):len(opts)], WithChecksums(sums))
+	}
+
+	first, err := readPart(r, partSize)
+	if err != nil {
+		return err
+	}
+	if int64(len(first)) < partSize {
+		return s.PutObject(ctx, objectKey, first, opts...)
+	}
+
+	uploadID, err := s.InitMultipartUpload(ctx, objectKey, opts...)
+	if err != nil {
+		return fmt.Errorf("init multipart upload failed, key: %s, err: %w", objectKey, err)
+	}
+
+	parts, err := uploadParts(ctx, s, objectKey, uploadID, first, r, partSize, concurrency, verify)
+	if err == nil {
+		err = s.CompleteMultipartUpload(ctx, objectKey, uploadID, parts)
+	}
+	if err != nil {
+		// 调用方 ctx 可能已取消，清理时不受其影响
+		if abortErr := s.AbortMultipartUpload(context.WithoutCancel(ctx), objectKey, uploadID); abortErr != nil {
+			hlog.CtxErrorf(ctx, "[Storage] abort multipart upload failed, key: %s, uploadID: %s, err: %v", objectKey, uploadID, abortErr)
+		}
+		return err
+	}
+	return nil
+}
+
+// uploadParts 顺序读取分片并以 concurrency 个并发上传，返回按 PartNumber 排列的分片列表
+// verify 为 true 时校验每个分片返回的 ETag
+func uploadParts(ctx context.Context, s Storage, objectKey, uploadID string, first []byte, r io.Reader, partSize int64, concurrency int, verify bool) ([]CompletedPart, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		mu       sync.Mutex
+		wg       sync.WaitGroup
+		firstErr error
+		parts    []CompletedPart
+	)
+	fail := func(err error) {
+		mu.Lock()
+		if firstErr == nil {
+			firstErr = err
+			cancel()
+		}
+		mu.Unlock()
+	}
+
+	sem := make(chan struct{}, concurrency)
+	data := first
+	for partNumber := int32(1); len(data) > 0; partNumber++ {
+		if partNumber > MaxParts {
+			fail(ErrTooManyParts)
+			break
+		}
+
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			fail(ctx.Err())
+			break
+		}
+
+		mu.Lock()
+		parts = append(parts, CompletedPart{PartNumber: partNumber})
+		mu.Unlock()
+		wg.Add(1)
+		go func(partNumber int32, data []byte) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			part, err := s.UploadPart(ctx, objectKey, uploadID, partNumber, bytes.NewReader(data), int64(len(data)))
+			if err != nil {
+				fail(fmt.Errorf("upload part %d failed, key: %s, err: %w", partNumber, objectKey, err))
+				return
+			}
+			if verify {
+				if err := verifyPartETag(objectKey, partNumber, data, part.ETag); err != nil {
+					fail(err)
+					return
+				}
+			}
+			mu.Lock()
+			parts[partNumber-1] = *part
+			mu.Unlock()
+		}(partNumber, data)
+
+		var err error
+		if data, err = readPart(r, partSize); err != nil {
+			fail(err)
+			break
+		}
+	}
+
+	wg.Wait()
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	return parts, nil
+}
+
+// readPart 读取至多 size 字节，读到末尾时返回的数据可能不足 size
+func readPart(r io.Reader, size int64) ([]byte, error) {
+	buf := make([]byte, size)
+	n, err := io.ReadFull(r, buf)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return nil, fmt.Errorf("read part failed: %w", err)
+	}
+	return buf[:n], nil
+}
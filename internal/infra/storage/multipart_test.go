@@ -0,0 +1,155 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"testing"
+)
+
+// fakeMultipart 只实现分片上传相关方法的 Storage
+type fakeMultipart struct {
+	Storage
+
+	mu       sync.Mutex
+	parts    map[int32][]byte
+	object   []byte
+	putCalls int
+	aborted  bool
+	failPart int32
+	badETag  int32
+	metadata map[string]string
+}
+
+func (f *fakeMultipart) PutObject(ctx context.Context, objectKey string, content []byte, opts ...PutOptFn) error {
+	f.putCalls++
+	f.object = content
+	return nil
+}
+
+func (f *fakeMultipart) InitMultipartUpload(ctx context.Context, objectKey string, opts ...PutOptFn) (string, error) {
+	option := PutOption{}
+	for _, opt := range opts {
+		opt(&option)
+	}
+	metadata, err := MultipartMetadata(&option)
+	if err != nil {
+		return "", err
+	}
+	f.metadata = metadata
+	f.parts = make(map[int32][]byte)
+	return "upload-1", nil
+}
+
+func (f *fakeMultipart) UploadPart(ctx context.Context, objectKey, uploadID string, partNumber int32, content io.Reader, size int64) (*CompletedPart, error) {
+	if partNumber == f.failPart {
+		return nil, errors.New("boom")
+	}
+	data, err := io.ReadAll(content)
+	if err != nil {
+		return nil, err
+	}
+	f.mu.Lock()
+	f.parts[partNumber] = data
+	f.mu.Unlock()
+	if partNumber == f.badETag {
+		// 模拟分片在传输中被截断
+		sum := md5.Sum(data[1:])
+		return &CompletedPart{PartNumber: partNumber, ETag: `"` + hex.EncodeToString(sum[:]) + `"`}, nil
+	}
+	return &CompletedPart{PartNumber: partNumber, ETag: fmt.Sprintf("etag-%d", partNumber)}, nil
+}
+
+func (f *fakeMultipart) CompleteMultipartUpload(ctx context.Context, objectKey, uploadID string, parts []CompletedPart) error {
+	if !sort.SliceIsSorted(parts, func(i, j int) bool { return parts[i].PartNumber < parts[j].PartNumber }) {
+		return errors.New("parts not sorted")
+	}
+	var buf bytes.Buffer
+	for _, p := range parts {
+		if p.ETag != fmt.Sprintf("etag-%d", p.PartNumber) {
+			return fmt.Errorf("unexpected etag %q", p.ETag)
+		}
+		buf.Write(f.parts[p.PartNumber])
+	}
+	f.object = buf.Bytes()
+	return nil
+}
+
+func (f *fakeMultipart) AbortMultipartUpload(ctx context.Context, objectKey, uploadID string) error {
+	f.aborted = true
+	return nil
+}
+
+func TestPutLargeObject(t *testing.T) {
+	ctx := context.Background()
+	payload := func(n int64) []byte {
+		b := make([]byte, n)
+		for i := range b {
+			b[i] = byte(i % 251)
+		}
+		return b
+	}
+
+	t.Run("小文件直接上传", func(t *testing.T) {
+		f := &fakeMultipart{}
+		data := payload(MinPartSize - 1)
+		if err := PutLargeObject(ctx, f, "k", bytes.NewReader(data), MinPartSize, 4); err != nil {
+			t.Fatal(err)
+		}
+		if f.putCalls != 1 || !bytes.Equal(f.object, data) {
+			t.Fatalf("putCalls = %d, object len = %d", f.putCalls, len(f.object))
+		}
+	})
+
+	t.Run("分片并发上传", func(t *testing.T) {
+		f := &fakeMultipart{}
+		data := payload(MinPartSize*3 + 17)
+		if err := PutLargeObject(ctx, f, "k", bytes.NewReader(data), MinPartSize, 2); err != nil {
+			t.Fatal(err)
+		}
+		if f.putCalls != 0 || len(f.parts) != 4 || !bytes.Equal(f.object, data) {
+			t.Fatalf("putCalls = %d, parts = %d, object len = %d", f.putCalls, len(f.parts), len(f.object))
+		}
+	})
+
+	t.Run("分片失败时取消上传", func(t *testing.T) {
+		f := &fakeMultipart{failPart: 2}
+		data := payload(MinPartSize * 3)
+		if err := PutLargeObject(ctx, f, "k", bytes.NewReader(data), MinPartSize, 2); err == nil {
+			t.Fatal("expected error")
+		}
+		if !f.aborted {
+			t.Fatal("upload not aborted")
+		}
+	})
+
+	t.Run("开启校验时写入整个对象的校验和", func(t *testing.T) {
+		f := &fakeMultipart{}
+		data := payload(MinPartSize*2 + 5)
+		r := struct{ io.Reader }{bytes.NewReader(data)}
+		if err := PutLargeObject(ctx, f, "k", r, MinPartSize, 2, WithCRC64(), WithContentMD5()); err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(f.object, data) {
+			t.Fatalf("object len = %d, want %d", len(f.object), len(data))
+		}
+		if err := VerifyChecksum("k", data, f.metadata); err != nil || f.metadata[MetaCRC64] == "" || f.metadata[MetaContentMD5] == "" {
+			t.Fatalf("metadata = %v, verify err = %v", f.metadata, err)
+		}
+	})
+
+	t.Run("分片 ETag 不一致时取消上传", func(t *testing.T) {
+		f := &fakeMultipart{badETag: 2}
+		data := payload(MinPartSize * 3)
+		err := PutLargeObject(ctx, f, "k", bytes.NewReader(data), MinPartSize, 2, WithCRC64())
+		if !errors.Is(err, ErrChecksumMismatch) || !f.aborted {
+			t.Fatalf("PutLargeObject() error = %v, aborted = %v", err, f.aborted)
+		}
+	})
+}
@@ -0,0 +1,338 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/common/hlog"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "github.com/ZampoRen/go-server-comon/internal/infra/storage"
+
+var (
+	// DefaultDurationBuckets 操作耗时直方图的默认分桶（秒）
+	DefaultDurationBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10, 30, 60}
+	// DefaultBytesBuckets 传输字节数直方图的默认分桶，1KB 到 1GB
+	DefaultBytesBuckets = prometheus.ExponentialBuckets(1024, 4, 11)
+)
+
+// ObservabilityOption 可观测性选项
+type ObservabilityOption func(o *observabilityOption)
+
+type observabilityOption struct {
+	provider        string
+	bucket          string
+	tracerProvider  trace.TracerProvider
+	registerer      prometheus.Registerer
+	durationBuckets []float64
+}
+
+// WithLabels 设置厂商与存储桶名称，作为指标标签与 span 属性
+func WithLabels(provider, bucket string) ObservabilityOption {
+	return func(o *observabilityOption) {
+		o.provider = provider
+		o.bucket = bucket
+	}
+}
+
+// WithTracerProvider 设置 TracerProvider，默认使用全局 TracerProvider
+func WithTracerProvider(tp trace.TracerProvider) ObservabilityOption {
+	return func(o *observabilityOption) {
+		if tp != nil {
+			o.tracerProvider = tp
+		}
+	}
+}
+
+// WithRegisterer 设置指标注册器，默认 prometheus.DefaultRegisterer
+func WithRegisterer(reg prometheus.Registerer) ObservabilityOption {
+	return func(o *observabilityOption) {
+		if reg != nil {
+			o.registerer = reg
+		}
+	}
+}
+
+// WithDurationBuckets 设置操作耗时直方图的分桶
+func WithDurationBuckets(buckets []float64) ObservabilityOption {
+	return func(o *observabilityOption) {
+		if len(buckets) > 0 {
+			o.durationBuckets = buckets
+		}
+	}
+}
+
+// observedStorage 为每个操作创建 span 并记录指标的 Storage 装饰器
+type observedStorage struct {
+	s        Storage
+	opt      *observabilityOption
+	tracer   trace.Tracer
+	attrs    []attribute.KeyValue
+	duration *prometheus.HistogramVec
+	bytes    *prometheus.HistogramVec
+}
+
+// WithObservability 用 OpenTelemetry span 与 Prometheus 指标包装 s
+// 导出的指标：
+//   - storage_operation_duration_seconds{provider, bucket, operation, status}: 操作耗时
+//   - storage_operation_bytes{provider, bucket, operation}: 上传与下载的字节数
+//
+// status 取值 ok、not_found、access_denied、throttled、error；
+// span 只记录对象键与字节数，不记录对象内容
+func WithObservability(s Storage, opts ...ObservabilityOption) Storage {
+	o := &observabilityOption{
+		provider:        "unknown",
+		bucket:          "unknown",
+		tracerProvider:  otel.GetTracerProvider(),
+		registerer:      prometheus.DefaultRegisterer,
+		durationBuckets: DefaultDurationBuckets,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	return &observedStorage{
+		s:      s,
+		opt:    o,
+		tracer: o.tracerProvider.Tracer(tracerName),
+		attrs: []attribute.KeyValue{
+			attribute.String("storage.provider", o.provider),
+			attribute.String("storage.bucket", o.bucket),
+		},
+		duration: register(o.registerer, prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: "storage",
+			Name:      "operation_duration_seconds",
+			Help:      "Duration of object storage operations in seconds.",
+			Buckets:   o.durationBuckets,
+		}, []string{"provider", "bucket", "operation", "status"})),
+		bytes: register(o.registerer, prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: "storage",
+			Name:      "operation_bytes",
+			Help:      "Bytes transferred by object storage operations.",
+			Buckets:   DefaultBytesBuckets,
+		}, []string{"provider", "bucket", "operation"})),
+	}
+}
+
+// register 注册指标，已注册时复用已有的指标，使多个存储实例共享同一组指标
+func register[C prometheus.Collector](reg prometheus.Registerer, c C) C {
+	err := reg.Register(c)
+	if err == nil {
+		return c
+	}
+	var are prometheus.AlreadyRegisteredError
+	if errors.As(err, &are) {
+		if existing, ok := are.ExistingCollector.(C); ok {
+			return existing
+		}
+	}
+	hlog.CtxWarnf(context.Background(), "[Storage] register metrics failed: %v", err)
+	return c
+}
+
+// statusOf 返回指标的 status 标签
+func statusOf(err error) string {
+	if err == nil {
+		return "ok"
+	}
+	if errors.Is(err, ErrObjectNotFound) {
+		return "not_found"
+	}
+	code, status := errorCode(err)
+	switch {
+	case notFoundCodes[code]:
+		return "not_found"
+	case deniedCodes[code] || status == 403:
+		return "access_denied"
+	case isThrottled(code, status):
+		return "throttled"
+	}
+	return "error"
+}
+
+// do 执行操作并记录 span 与指标，run 返回传输的字节数，未知时返回 0
+func (o *observedStorage) do(ctx context.Context, op, objectKey string, run func(ctx context.Context) (int64, error)) error {
+	ctx, span := o.tracer.Start(ctx, "storage."+op,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(o.attrs...),
+		trace.WithAttributes(
+			attribute.String("storage.operation", op),
+			attribute.String("storage.key", objectKey),
+		),
+	)
+	defer span.End()
+
+	start := time.Now()
+	n, err := run(ctx)
+	status := statusOf(err)
+
+	o.duration.WithLabelValues(o.opt.provider, o.opt.bucket, op, status).Observe(time.Since(start).Seconds())
+	if n > 0 {
+		o.bytes.WithLabelValues(o.opt.provider, o.opt.bucket, op).Observe(float64(n))
+		span.SetAttributes(attribute.Int64("storage.bytes", n))
+	}
+	// 对象不存在通常是正常的业务分支，不视为错误
+	if err != nil && status != "not_found" {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	return err
+}
+
+// countingReader 统计已读取的字节数
+type countingReader struct {
+	io.Reader
+	n int64
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.Reader.Read(p)
+	r.n += int64(n)
+	return n, err
+}
+
+func (o *observedStorage) PutObject(ctx context.Context, objectKey string, content []byte, opts ...PutOptFn) error {
+	return o.do(ctx, "PutObject", objectKey, func(ctx context.Context) (int64, error) {
+		return int64(len(content)), o.s.PutObject(ctx, objectKey, content, opts...)
+	})
+}
+
+func (o *observedStorage) PutObjectWithReader(ctx context.Context, objectKey string, content io.Reader, opts ...PutOptFn) error {
+	return o.do(ctx, "PutObjectWithReader", objectKey, func(ctx context.Context) (int64, error) {
+		// 保留 io.Seeker 等能力，SDK 依赖它计算长度与重试，因此不替换 content，只在无法 Seek 时计数
+		if _, ok := content.(io.Seeker); ok {
+			option := PutOption{}
+			for _, opt := range opts {
+				opt(&option)
+			}
+			return option.ObjectSize, o.s.PutObjectWithReader(ctx, objectKey, content, opts...)
+		}
+		cr := &countingReader{Reader: content}
+		err := o.s.PutObjectWithReader(ctx, objectKey, cr, opts...)
+		return cr.n, err
+	})
+}
+
+func (o *observedStorage) GetObject(ctx context.Context, objectKey string) ([]byte, error) {
+	var data []byte
+	err := o.do(ctx, "GetObject", objectKey, func(ctx context.Context) (n int64, err error) {
+		data, err = o.s.GetObject(ctx, objectKey)
+		return int64(len(data)), err
+	})
+	return data, err
+}
+
+// GetObjectReader 只记录打开对象的耗时与结果，读取的字节数不计入
+func (o *observedStorage) GetObjectReader(ctx context.Context, objectKey string) (io.ReadCloser, error) {
+	var rc io.ReadCloser
+	err := o.do(ctx, "GetObjectReader", objectKey, func(ctx context.Context) (n int64, err error) {
+		rc, err = o.s.GetObjectReader(ctx, objectKey)
+		return 0, err
+	})
+	return rc, err
+}
+
+func (o *observedStorage) DeleteObject(ctx context.Context, objectKey string) error {
+	return o.do(ctx, "DeleteObject", objectKey, func(ctx context.Context) (int64, error) {
+		return 0, o.s.DeleteObject(ctx, objectKey)
+	})
+}
+
+func (o *observedStorage) GetObjectUrl(ctx context.Context, objectKey string, opts ...GetOptFn) (string, error) {
+	var url string
+	err := o.do(ctx, "GetObjectUrl", objectKey, func(ctx context.Context) (n int64, err error) {
+		url, err = o.s.GetObjectUrl(ctx, objectKey, opts...)
+		return 0, err
+	})
+	return url, err
+}
+
+func (o *observedStorage) HeadObject(ctx context.Context, objectKey string, opts ...GetOptFn) (*FileInfo, error) {
+	var f *FileInfo
+	err := o.do(ctx, "HeadObject", objectKey, func(ctx context.Context) (n int64, err error) {
+		f, err = o.s.HeadObject(ctx, objectKey, opts...)
+		return 0, err
+	})
+	return f, err
+}
+
+func (o *observedStorage) ListAllObjects(ctx context.Context, prefix string, opts ...GetOptFn) ([]*FileInfo, error) {
+	var files []*FileInfo
+	err := o.do(ctx, "ListAllObjects", prefix, func(ctx context.Context) (n int64, err error) {
+		files, err = o.s.ListAllObjects(ctx, prefix, opts...)
+		return 0, err
+	})
+	return files, err
+}
+
+func (o *observedStorage) ListObjectsPaginated(ctx context.Context, input *ListObjectsPaginatedInput, opts ...GetOptFn) (*ListObjectsPaginatedOutput, error) {
+	var prefix string
+	if input != nil {
+		prefix = input.Prefix
+	}
+	var output *ListObjectsPaginatedOutput
+	err := o.do(ctx, "ListObjectsPaginated", prefix, func(ctx context.Context) (n int64, err error) {
+		output, err = o.s.ListObjectsPaginated(ctx, input, opts...)
+		return 0, err
+	})
+	return output, err
+}
+
+func (o *observedStorage) InitMultipartUpload(ctx context.Context, objectKey string, opts ...PutOptFn) (string, error) {
+	var uploadID string
+	err := o.do(ctx, "InitMultipartUpload", objectKey, func(ctx context.Context) (n int64, err error) {
+		uploadID, err = o.s.InitMultipartUpload(ctx, objectKey, opts...)
+		return 0, err
+	})
+	return uploadID, err
+}
+
+func (o *observedStorage) UploadPart(ctx context.Context, objectKey, uploadID string, partNumber int32, content io.Reader, size int64) (*CompletedPart, error) {
+	var part *CompletedPart
+	err := o.do(ctx, "UploadPart", objectKey, func(ctx context.Context) (n int64, err error) {
+		part, err = o.s.UploadPart(ctx, objectKey, uploadID, partNumber, content, size)
+		return size, err
+	})
+	return part, err
+}
+
+func (o *observedStorage) CompleteMultipartUpload(ctx context.Context, objectKey, uploadID string, parts []CompletedPart) error {
+	return o.do(ctx, "CompleteMultipartUpload", objectKey, func(ctx context.Context) (int64, error) {
+		return 0, o.s.CompleteMultipartUpload(ctx, objectKey, uploadID, parts)
+	})
+}
+
+func (o *observedStorage) AbortMultipartUpload(ctx context.Context, objectKey, uploadID string) error {
+	return o.do(ctx, "AbortMultipartUpload", objectKey, func(ctx context.Context) (int64, error) {
+		return 0, o.s.AbortMultipartUpload(ctx, objectKey, uploadID)
+	})
+}
+
+func (o *observedStorage) CopyObject(ctx context.Context, srcKey, dstKey string) error {
+	return o.do(ctx, "CopyObject", dstKey, func(ctx context.Context) (int64, error) {
+		return 0, o.s.CopyObject(ctx, srcKey, dstKey)
+	})
+}
+
+func (o *observedStorage) MoveObject(ctx context.Context, srcKey, dstKey string) error {
+	return o.do(ctx, "MoveObject", dstKey, func(ctx context.Context) (int64, error) {
+		return 0, o.s.MoveObject(ctx, srcKey, dstKey)
+	})
+}
+
+func (o *observedStorage) DeleteObjects(ctx context.Context, objectKeys []string) error {
+	var key string
+	if len(objectKeys) > 0 {
+		key = objectKeys[0]
+	}
+	return o.do(ctx, "DeleteObjects", key, func(ctx context.Context) (int64, error) {
+		return 0, o.s.DeleteObjects(ctx, objectKeys)
+	})
+}
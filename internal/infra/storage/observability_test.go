@@ -0,0 +1,67 @@
+package storage
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// TestWithObservability 测试耗时与字节数指标的导出
+func TestWithObservability(t *testing.T) {
+	ctx := context.Background()
+	reg := prometheus.NewRegistry()
+	f := &flakyStorage{failures: 1, err: &apiError{code: "NoSuchKey", status: 404}}
+	s := WithObservability(f, WithLabels("tos", "b"), WithRegisterer(reg))
+
+	_, _ = s.GetObject(ctx, "k")
+	_, _ = s.GetObject(ctx, "k")
+	// 不支持 Seek 的 Reader 通过计数得到字节数
+	_ = s.PutObjectWithReader(ctx, "k", struct{ io.Reader }{strings.NewReader("hello")})
+
+	mfs, err := reg.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// got 指标名 -> "operation/status/" -> 样本数与样本和
+	type sample struct {
+		count uint64
+		sum   float64
+	}
+	got := make(map[string]map[string]sample)
+	for _, mf := range mfs {
+		m := make(map[string]sample)
+		for _, metric := range mf.GetMetric() {
+			key := ""
+			for _, l := range metric.GetLabel() {
+				if l.GetName() == "operation" || l.GetName() == "status" {
+					key += l.GetValue() + "/"
+				}
+			}
+			m[key] = sample{metric.GetHistogram().GetSampleCount(), metric.GetHistogram().GetSampleSum()}
+		}
+		got[mf.GetName()] = m
+	}
+
+	tests := []struct {
+		name   string
+		metric string
+		key    string
+		want   sample
+	}{
+		{"不存在", "storage_operation_duration_seconds", "GetObject/not_found/", sample{count: 1}},
+		{"成功", "storage_operation_duration_seconds", "GetObject/ok/", sample{count: 1}},
+		{"下载字节数", "storage_operation_bytes", "GetObject/", sample{1, 2}},
+		{"Reader 上传字节数", "storage_operation_bytes", "PutObjectWithReader/", sample{1, 5}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := got[tt.metric][tt.key]
+			if s.count != tt.want.count || (tt.want.sum > 0 && s.sum != tt.want.sum) {
+				t.Errorf("%s{%s} = %+v, want %+v", tt.metric, tt.key, s, tt.want)
+			}
+		})
+	}
+}
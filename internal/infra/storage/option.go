@@ -9,11 +9,34 @@ type GetOptFn func(option *GetOption)
 
 // GetOption 获取选项
 type GetOption struct {
-	Expire      int64 // 过期时间（秒）
-	WithURL     bool  // 是否包含 URL
-	WithTagging bool  // 是否包含标签
+	Expire           int64            // 过期时间（秒）
+	WithURL          bool             // 是否包含 URL
+	WithTagging      bool             // 是否包含标签
+	URLConcurrency   int              // 批量生成 URL 的并发数
+	URLFailurePolicy URLFailurePolicy // 批量生成 URL 时单个文件失败的处理策略
+	Process          string           // 厂商原生的数据处理参数，见 WithProcess
+	ImageProcess     *ImageProcess    // 与厂商无关的图片处理参数，见 WithImageProcess
 }
 
+// ImageProcess 与厂商无关的图片处理参数，由各厂商实现转换为各自的处理语法
+type ImageProcess struct {
+	Width     int    // 缩放后的宽度，0 表示按高度等比缩放
+	Height    int    // 缩放后的高度，0 表示按宽度等比缩放
+	Format    string // 输出格式，如 webp、jpg、png
+	Quality   int    // 输出质量 1-100，0 表示不调整
+	Watermark string // 文字水印
+}
+
+// URLFailurePolicy 批量生成 URL 时单个文件失败的处理策略
+type URLFailurePolicy int
+
+const (
+	// URLFailFast 任一文件失败时返回错误（默认）
+	URLFailFast URLFailurePolicy = iota
+	// URLSkipFailed 跳过失败的文件，其 URL 为空，并记录日志
+	URLSkipFailed
+)
+
 // WithExpire 设置过期时间
 func WithExpire(expire int64) GetOptFn {
 	return func(o *GetOption) {
@@ -28,6 +51,40 @@ func WithURL(withURL bool) GetOptFn {
 	}
 }
 
+// WithURLConcurrency 设置列举对象时批量生成 URL 的并发数（默认 16）
+func WithURLConcurrency(n int) GetOptFn {
+	return func(o *GetOption) {
+		o.URLConcurrency = n
+	}
+}
+
+// WithURLFailurePolicy 设置批量生成 URL 时单个文件失败的处理策略（默认 URLFailFast）
+func WithURLFailurePolicy(p URLFailurePolicy) GetOptFn {
+	return func(o *GetOption) {
+		o.URLFailurePolicy = p
+	}
+}
+
+// WithProcess 为预签名 URL 附加厂商原生的数据处理参数，参数原样透传：
+//   - aliyun: x-oss-process 的值，如 image/resize,w_200 或 style/<样式名>
+//   - volcengine: x-tos-process 的值，如 image/resize,w_200 或 style/<样式名>
+//   - tencent: 数据万象处理参数，如 imageMogr2/thumbnail/200x 或 <样式分隔符><样式名>
+//
+// 同时设置 WithImageProcess 时优先使用 WithProcess
+func WithProcess(style string) GetOptFn {
+	return func(o *GetOption) {
+		o.Process = style
+	}
+}
+
+// WithImageProcess 为预签名 URL 附加图片处理参数（缩放、格式转换、质量、文字水印），
+// 由各厂商实现转换为各自的处理语法
+func WithImageProcess(p ImageProcess) GetOptFn {
+	return func(o *GetOption) {
+		o.ImageProcess = &p
+	}
+}
+
 // WithGetTagging 设置是否包含标签
 func WithGetTagging(withTagging bool) GetOptFn {
 	return func(o *GetOption) {
@@ -44,6 +101,9 @@ type PutOption struct {
 	Expires            *time.Time        // 过期时间
 	Tagging            map[string]string // 标签
 	ObjectSize         int64             // 对象大小
+	ContentMD5         bool              // 是否计算并校验 MD5
+	CRC64              bool              // 是否计算并校验 CRC64
+	Checksums          *Checksums        // 预先计算的整个对象的校验和，分片上传初始化时写入元数据，见 WithChecksums
 }
 
 // PutOptFn 上传选项函数
@@ -102,3 +162,27 @@ func WithExpires(v time.Time) PutOptFn {
 		o.Expires = &v
 	}
 }
+
+// WithContentMD5 上传时计算 MD5，由服务端校验上传内容，并写入对象元数据供下载时校验
+// content 不支持 io.Seeker 时会先写入临时文件；分片上传需使用 PutLargeObject 或 WithChecksums 传入校验和
+func WithContentMD5() PutOptFn {
+	return func(o *PutOption) {
+		o.ContentMD5 = true
+	}
+}
+
+// WithChecksums 传入预先计算的整个对象的校验和，分片上传初始化时写入对象元数据，
+// PutLargeObject 开启校验时自动设置，手动分片上传时需自行计算
+func WithChecksums(sums *Checksums) PutOptFn {
+	return func(o *PutOption) {
+		o.Checksums = sums
+	}
+}
+
+// WithCRC64 上传时计算 CRC64（ECMA，与各厂商的 crc64ecma 一致）并写入对象元数据，下载时校验
+// content 不支持 io.Seeker 时会先写入临时文件；分片上传需使用 PutLargeObject 或 WithChecksums 传入校验和
+func WithCRC64() PutOptFn {
+	return func(o *PutOption) {
+		o.CRC64 = true
+	}
+}
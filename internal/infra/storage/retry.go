@@ -0,0 +1,306 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"syscall"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/common/hlog"
+
+	"github.com/ZampoRen/go-server-comon/internal/errno"
+	"github.com/ZampoRen/go-server-comon/pkg/errorx"
+	"github.com/ZampoRen/go-server-comon/pkg/retry"
+)
+
+// RetryPolicy 重试策略，零值字段使用默认值
+type RetryPolicy struct {
+	// MaxRetries 最大重试次数（不含首次执行），默认 3，为负数时不重试
+	MaxRetries int
+	// MinBackoff 指数退避的初始间隔，默认 100ms
+	MinBackoff time.Duration
+	// MaxBackoff 指数退避的最大间隔，默认 5s
+	MaxBackoff time.Duration
+	// Retryable 自定义可重试错误的判断，默认为 IsTransient
+	Retryable func(err error) bool
+}
+
+var (
+	notFoundCodes  = map[string]bool{"NoSuchKey": true, "NotFound": true}
+	deniedCodes    = map[string]bool{"AccessDenied": true, "Forbidden": true, "InvalidAccessKeyId": true, "SignatureDoesNotMatch": true}
+	throttledCodes = map[string]bool{
+		"SlowDown": true, "Throttling": true, "ThrottlingException": true, "RequestLimitExceeded": true,
+		"TooManyRequests": true, "TooManyRequestsException": true, "RequestThrottled": true,
+	}
+	transientCodes = map[string]bool{"InternalError": true, "ServiceUnavailable": true, "RequestTimeout": true}
+)
+
+// errorCode 提取厂商返回的错误码与 HTTP 状态码
+// aliyun/tencent/volcengine 均通过 S3 SDK 访问，错误实现了 ErrorCode 与 HTTPStatusCode
+func errorCode(err error) (code string, status int) {
+	var ce interface{ ErrorCode() string }
+	if errors.As(err, &ce) {
+		code = ce.ErrorCode()
+	}
+	var he interface{ HTTPStatusCode() int }
+	if errors.As(err, &he) {
+		status = he.HTTPStatusCode()
+	}
+	return code, status
+}
+
+func isThrottled(code string, status int) bool {
+	return throttledCodes[code] || status == 429
+}
+
+// IsTransient 判断错误是否为可重试的瞬时错误：限流、服务端 5xx、网络错误、单次请求超时与校验和不一致
+// 调用方 ctx 的取消不视为瞬时错误
+func IsTransient(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, ErrObjectNotFound) {
+		return false
+	}
+
+	code, status := errorCode(err)
+	if isThrottled(code, status) || transientCodes[code] || status >= 500 {
+		return true
+	}
+	if status > 0 {
+		// 其它 4xx 错误重试也不会成功
+		return false
+	}
+
+	// 下载内容不完整时重新下载可能成功
+	if errors.Is(err, ErrChecksumMismatch) || errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, context.DeadlineExceeded) ||
+		errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, syscall.EPIPE) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+// ClassifyError 将各厂商的错误统一转换为 errorx 错误码，无法识别的错误原样返回
+//   - 对象不存在: errno.ErrObjectNotFound，仍可通过 errors.Is(err, ErrObjectNotFound) 判断
+//   - 无权限或签名错误: errno.ErrStorageAccessDenied
+//   - 限流: errno.ErrStorageThrottled
+func ClassifyError(err error) error {
+	if err == nil {
+		return nil
+	}
+	var se errorx.StatusError
+	if errors.As(err, &se) {
+		return err
+	}
+
+	code, status := errorCode(err)
+	switch {
+	case errors.Is(err, ErrObjectNotFound):
+		return errorx.WrapByCode(err, errno.ErrObjectNotFound)
+	case notFoundCodes[code] || (code == "" && status == 404):
+		return errorx.WrapByCode(fmt.Errorf("%w: %w", ErrObjectNotFound, err), errno.ErrObjectNotFound)
+	case deniedCodes[code] || status == 403:
+		return errorx.WrapByCode(err, errno.ErrStorageAccessDenied)
+	case isThrottled(code, status):
+		return errorx.WrapByCode(err, errno.ErrStorageThrottled)
+	}
+	return err
+}
+
+// retryStorage 为幂等操作增加重试并统一错误码的 Storage 装饰器
+type retryStorage struct {
+	s Storage
+	p RetryPolicy
+}
+
+// WithRetry 用重试策略包装 s，幂等操作遇到瞬时错误时按指数退避（带抖动）重试，
+// 所有操作返回的错误都经过 ClassifyError 转换
+// 以下操作不重试：InitMultipartUpload、CompleteMultipartUpload（重复执行会产生多余的上传或失败），
+// 以及 content 不支持 io.Seeker 的 PutObjectWithReader、UploadPart（Reader 无法重复读取）
+func WithRetry(s Storage, policy RetryPolicy) Storage {
+	if policy.MaxRetries == 0 {
+		policy.MaxRetries = 3
+	}
+	if policy.MinBackoff <= 0 {
+		policy.MinBackoff = 100 * time.Millisecond
+	}
+	if policy.MaxBackoff <= 0 {
+		policy.MaxBackoff = 5 * time.Second
+	}
+	if policy.Retryable == nil {
+		policy.Retryable = IsTransient
+	}
+	return &retryStorage{s: s, p: policy}
+}
+
+// do 执行操作，失败且错误可重试时按指数退避重试，调用方 ctx 结束时立即返回
+func (r *retryStorage) do(ctx context.Context, op string, idempotent bool, run func(ctx context.Context) error) error {
+	maxAttempts := r.p.MaxRetries + 1
+	if !idempotent || r.p.MaxRetries < 0 {
+		maxAttempts = 1
+	}
+	err := retry.Do(ctx, run,
+		retry.WithMaxAttempts(maxAttempts),
+		retry.WithBackoff(r.p.MinBackoff, r.p.MaxBackoff),
+		retry.WithRetryIf(r.p.Retryable),
+		retry.WithOnRetry(func(attempt int, err error, wait time.Duration) {
+			hlog.CtxWarnf(ctx, "[Storage] %s failed, retry %d after %s, err: %v", op, attempt, wait, err)
+		}),
+	)
+	return ClassifyError(err)
+}
+
+// rewinder 返回将 content 重置到当前位置的函数，content 不支持 Seek 时返回 nil
+func rewinder(content io.Reader) func() error {
+	s, ok := content.(io.Seeker)
+	if !ok {
+		return nil
+	}
+	start, err := s.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return nil
+	}
+	return func() error {
+		_, err := s.Seek(start, io.SeekStart)
+		return err
+	}
+}
+
+// doReader 执行读取 content 的操作，content 支持 Seek 时每次重试前重置读取位置
+func (r *retryStorage) doReader(ctx context.Context, op string, content io.Reader, run func(ctx context.Context) error) error {
+	rewind := rewinder(content)
+	attempted := false
+	return r.do(ctx, op, rewind != nil, func(ctx context.Context) error {
+		if attempted {
+			if err := rewind(); err != nil {
+				return err
+			}
+		}
+		attempted = true
+		return run(ctx)
+	})
+}
+
+func (r *retryStorage) PutObject(ctx context.Context, objectKey string, content []byte, opts ...PutOptFn) error {
+	return r.do(ctx, "PutObject", true, func(ctx context.Context) error {
+		return r.s.PutObject(ctx, objectKey, content, opts...)
+	})
+}
+
+func (r *retryStorage) PutObjectWithReader(ctx context.Context, objectKey string, content io.Reader, opts ...PutOptFn) error {
+	return r.doReader(ctx, "PutObjectWithReader", content, func(ctx context.Context) error {
+		return r.s.PutObjectWithReader(ctx, objectKey, content, opts...)
+	})
+}
+
+func (r *retryStorage) GetObject(ctx context.Context, objectKey string) ([]byte, error) {
+	var data []byte
+	err := r.do(ctx, "GetObject", true, func(ctx context.Context) (err error) {
+		data, err = r.s.GetObject(ctx, objectKey)
+		return err
+	})
+	return data, err
+}
+
+// GetObjectReader 只重试打开对象，读取过程中的错误由调用方处理
+func (r *retryStorage) GetObjectReader(ctx context.Context, objectKey string) (io.ReadCloser, error) {
+	var rc io.ReadCloser
+	err := r.do(ctx, "GetObjectReader", true, func(ctx context.Context) (err error) {
+		rc, err = r.s.GetObjectReader(ctx, objectKey)
+		return err
+	})
+	return rc, err
+}
+
+func (r *retryStorage) DeleteObject(ctx context.Context, objectKey string) error {
+	return r.do(ctx, "DeleteObject", true, func(ctx context.Context) error {
+		return r.s.DeleteObject(ctx, objectKey)
+	})
+}
+
+func (r *retryStorage) GetObjectUrl(ctx context.Context, objectKey string, opts ...GetOptFn) (string, error) {
+	var url string
+	err := r.do(ctx, "GetObjectUrl", true, func(ctx context.Context) (err error) {
+		url, err = r.s.GetObjectUrl(ctx, objectKey, opts...)
+		return err
+	})
+	return url, err
+}
+
+func (r *retryStorage) HeadObject(ctx context.Context, objectKey string, opts ...GetOptFn) (*FileInfo, error) {
+	var f *FileInfo
+	err := r.do(ctx, "HeadObject", true, func(ctx context.Context) (err error) {
+		f, err = r.s.HeadObject(ctx, objectKey, opts...)
+		return err
+	})
+	return f, err
+}
+
+func (r *retryStorage) ListAllObjects(ctx context.Context, prefix string, opts ...GetOptFn) ([]*FileInfo, error) {
+	var files []*FileInfo
+	err := r.do(ctx, "ListAllObjects", true, func(ctx context.Context) (err error) {
+		files, err = r.s.ListAllObjects(ctx, prefix, opts...)
+		return err
+	})
+	return files, err
+}
+
+func (r *retryStorage) ListObjectsPaginated(ctx context.Context, input *ListObjectsPaginatedInput, opts ...GetOptFn) (*ListObjectsPaginatedOutput, error) {
+	var output *ListObjectsPaginatedOutput
+	err := r.do(ctx, "ListObjectsPaginated", true, func(ctx context.Context) (err error) {
+		output, err = r.s.ListObjectsPaginated(ctx, input, opts...)
+		return err
+	})
+	return output, err
+}
+
+func (r *retryStorage) InitMultipartUpload(ctx context.Context, objectKey string, opts ...PutOptFn) (string, error) {
+	var uploadID string
+	err := r.do(ctx, "InitMultipartUpload", false, func(ctx context.Context) (err error) {
+		uploadID, err = r.s.InitMultipartUpload(ctx, objectKey, opts...)
+		return err
+	})
+	return uploadID, err
+}
+
+func (r *retryStorage) UploadPart(ctx context.Context, objectKey, uploadID string, partNumber int32, content io.Reader, size int64) (*CompletedPart, error) {
+	var part *CompletedPart
+	err := r.doReader(ctx, "UploadPart", content, func(ctx context.Context) (err error) {
+		part, err = r.s.UploadPart(ctx, objectKey, uploadID, partNumber, content, size)
+		return err
+	})
+	return part, err
+}
+
+func (r *retryStorage) CompleteMultipartUpload(ctx context.Context, objectKey, uploadID string, parts []CompletedPart) error {
+	return r.do(ctx, "CompleteMultipartUpload", false, func(ctx context.Context) error {
+		return r.s.CompleteMultipartUpload(ctx, objectKey, uploadID, parts)
+	})
+}
+
+func (r *retryStorage) AbortMultipartUpload(ctx context.Context, objectKey, uploadID string) error {
+	return r.do(ctx, "AbortMultipartUpload", true, func(ctx context.Context) error {
+		return r.s.AbortMultipartUpload(ctx, objectKey, uploadID)
+	})
+}
+
+func (r *retryStorage) CopyObject(ctx context.Context, srcKey, dstKey string) error {
+	return r.do(ctx, "CopyObject", true, func(ctx context.Context) error {
+		return r.s.CopyObject(ctx, srcKey, dstKey)
+	})
+}
+
+// MoveObject 拆分为可分别重试的 CopyObject 与 DeleteObject
+func (r *retryStorage) MoveObject(ctx context.Context, srcKey, dstKey string) error {
+	if err := r.CopyObject(ctx, srcKey, dstKey); err != nil {
+		return err
+	}
+	return r.DeleteObject(ctx, srcKey)
+}
+
+func (r *retryStorage) DeleteObjects(ctx context.Context, objectKeys []string) error {
+	return r.do(ctx, "DeleteObjects", true, func(ctx context.Context) error {
+		return r.s.DeleteObjects(ctx, objectKeys)
+	})
+}
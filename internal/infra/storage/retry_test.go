@@ -0,0 +1,117 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/ZampoRen/go-server-comon/internal/errno"
+	"github.com/ZampoRen/go-server-comon/pkg/errorx"
+)
+
+// apiError 模拟 S3 SDK 返回的错误
+type apiError struct {
+	code   string
+	status int
+}
+
+func (e *apiError) Error() string       { return e.code }
+func (e *apiError) ErrorCode() string   { return e.code }
+func (e *apiError) HTTPStatusCode() int { return e.status }
+
+// flakyStorage 前 failures 次调用返回 err
+type flakyStorage struct {
+	Storage
+
+	failures int
+	err      error
+	calls    int
+	bodies   []string
+}
+
+func (f *flakyStorage) fail() error {
+	f.calls++
+	if f.calls <= f.failures {
+		return f.err
+	}
+	return nil
+}
+
+func (f *flakyStorage) GetObject(ctx context.Context, objectKey string) ([]byte, error) {
+	if err := f.fail(); err != nil {
+		return nil, err
+	}
+	return []byte("ok"), nil
+}
+
+func (f *flakyStorage) PutObjectWithReader(ctx context.Context, objectKey string, content io.Reader, opts ...PutOptFn) error {
+	body, _ := io.ReadAll(content)
+	f.bodies = append(f.bodies, string(body))
+	return f.fail()
+}
+
+func (f *flakyStorage) InitMultipartUpload(ctx context.Context, objectKey string, opts ...PutOptFn) (string, error) {
+	return "", f.fail()
+}
+
+func codeOf(err error) int32 {
+	var se errorx.StatusError
+	if errors.As(err, &se) {
+		return se.Code()
+	}
+	return 0
+}
+
+func TestWithRetry(t *testing.T) {
+	ctx := context.Background()
+	policy := RetryPolicy{MaxRetries: 2, MinBackoff: time.Millisecond, MaxBackoff: time.Millisecond}
+
+	tests := []struct {
+		name      string
+		failures  int
+		err       error
+		wantCalls int
+		wantCode  int32
+	}{
+		{"限流后重试成功", 2, &apiError{code: "SlowDown", status: 503}, 3, 0},
+		{"重试耗尽返回限流错误码", 5, &apiError{code: "SlowDown", status: 503}, 3, errno.ErrStorageThrottled},
+		{"对象不存在不重试", 5, &apiError{code: "NoSuchKey", status: 404}, 1, errno.ErrObjectNotFound},
+		{"无权限不重试", 5, &apiError{code: "AccessDenied", status: 403}, 1, errno.ErrStorageAccessDenied},
+		{"网络中断重试", 1, io.ErrUnexpectedEOF, 2, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := &flakyStorage{failures: tt.failures, err: tt.err}
+			_, err := WithRetry(f, policy).GetObject(ctx, "k")
+			if f.calls != tt.wantCalls {
+				t.Errorf("calls = %d, want %d", f.calls, tt.wantCalls)
+			}
+			if got := codeOf(err); got != tt.wantCode {
+				t.Errorf("code = %d, want %d, err: %v", got, tt.wantCode, err)
+			}
+			if tt.wantCode == errno.ErrObjectNotFound && !errors.Is(err, ErrObjectNotFound) {
+				t.Errorf("errors.Is(err, ErrObjectNotFound) = false")
+			}
+		})
+	}
+
+	t.Run("Reader 重试前重置读取位置", func(t *testing.T) {
+		f := &flakyStorage{failures: 1, err: io.ErrUnexpectedEOF}
+		if err := WithRetry(f, policy).PutObjectWithReader(ctx, "k", bytes.NewReader([]byte("data"))); err != nil {
+			t.Fatal(err)
+		}
+		if len(f.bodies) != 2 || f.bodies[1] != "data" {
+			t.Errorf("bodies = %q", f.bodies)
+		}
+	})
+
+	t.Run("非幂等操作不重试", func(t *testing.T) {
+		f := &flakyStorage{failures: 1, err: io.ErrUnexpectedEOF}
+		if _, err := WithRetry(f, policy).InitMultipartUpload(ctx, "k"); err == nil || f.calls != 1 {
+			t.Errorf("calls = %d, err = %v", f.calls, err)
+		}
+	})
+}
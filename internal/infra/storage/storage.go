@@ -3,7 +3,9 @@ package storage
 import (
 	"context"
 	"errors"
+	"fmt"
 	"io"
+	"slices"
 	"time"
 )
 
@@ -20,6 +22,9 @@ type Storage interface {
 	PutObjectWithReader(ctx context.Context, objectKey string, content io.Reader, opts ...PutOptFn) error
 	// GetObject 获取指定键的对象
 	GetObject(ctx context.Context, objectKey string) ([]byte, error)
+	// GetObjectReader 以流的方式获取指定键的对象，调用方负责关闭；
+	// 对象记录了校验和时在读到末尾时校验，不一致时返回 *ChecksumError
+	GetObjectReader(ctx context.Context, objectKey string) (io.ReadCloser, error)
 	// DeleteObject 删除指定键的对象
 	DeleteObject(ctx context.Context, objectKey string) error
 	// GetObjectUrl 返回对象的预签名 URL
@@ -33,6 +38,22 @@ type Storage interface {
 	// ListObjectsPaginated 返回支持分页的对象列表
 	// 处理大量对象时使用此方法
 	ListObjectsPaginated(ctx context.Context, input *ListObjectsPaginatedInput, opts ...GetOptFn) (*ListObjectsPaginatedOutput, error)
+	// InitMultipartUpload 初始化分片上传，返回 uploadID
+	// 大文件建议直接使用 PutLargeObject
+	InitMultipartUpload(ctx context.Context, objectKey string, opts ...PutOptFn) (string, error)
+	// UploadPart 上传一个分片，partNumber 从 1 开始，size 为分片大小
+	UploadPart(ctx context.Context, objectKey, uploadID string, partNumber int32, content io.Reader, size int64) (*CompletedPart, error)
+	// CompleteMultipartUpload 合并已上传的分片，parts 需按 PartNumber 升序排列
+	CompleteMultipartUpload(ctx context.Context, objectKey, uploadID string, parts []CompletedPart) error
+	// AbortMultipartUpload 取消分片上传并清理已上传的分片
+	AbortMultipartUpload(ctx context.Context, objectKey, uploadID string) error
+	// CopyObject 在存储桶内将 srcKey 复制到 dstKey，由服务端完成，不经过本地
+	CopyObject(ctx context.Context, srcKey, dstKey string) error
+	// MoveObject 将 srcKey 移动到 dstKey（复制后删除源对象）
+	MoveObject(ctx context.Context, srcKey, dstKey string) error
+	// DeleteObjects 批量删除对象，使用厂商的批量删除接口，每批最多 MaxDeleteObjects 个
+	// 部分对象删除失败时返回 *DeleteObjectsError
+	DeleteObjects(ctx context.Context, objectKeys []string) error
 }
 
 // SecurityToken 安全令牌
@@ -58,6 +79,32 @@ type ListObjectsPaginatedOutput struct {
 	IsTruncated bool        // false: 所有结果已返回，true: 还有更多结果
 }
 
+// MaxDeleteObjects 单次批量删除的最大对象数
+const MaxDeleteObjects = 1000
+
+// DeleteObjectsError 批量删除中部分对象删除失败
+type DeleteObjectsError struct {
+	Failed map[string]error // 删除失败的对象键及原因
+}
+
+func (e *DeleteObjectsError) Error() string {
+	keys := make([]string, 0, len(e.Failed))
+	for key := range e.Failed {
+		keys = append(keys, key)
+	}
+	if len(keys) == 0 {
+		return "delete objects failed"
+	}
+	slices.Sort(keys)
+	return fmt.Sprintf("delete %d objects failed, first key: %s, err: %v", len(keys), keys[0], e.Failed[keys[0]])
+}
+
+// CompletedPart 已上传的分片
+type CompletedPart struct {
+	PartNumber int32  // 分片序号
+	ETag       string // 分片 ETag
+}
+
 // FileInfo 文件信息
 type FileInfo struct {
 	Key          string            `json:"key"`           // 对象键
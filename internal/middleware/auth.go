@@ -0,0 +1,464 @@
+package middleware
+
+import (
+	"context"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/ZampoRen/go-server-comon/internal/errno"
+	"github.com/ZampoRen/go-server-comon/pkg/envkey"
+	"github.com/ZampoRen/go-server-comon/pkg/errorx"
+)
+
+const (
+	// AuthorizationKey 携带 Bearer token 的 metadata 键
+	AuthorizationKey = "authorization"
+
+	// jwksMinRefreshInterval 遇到未知 kid 时强制刷新 JWKS 的最小间隔，避免伪造的 kid 打满 JWKS 服务
+	jwksMinRefreshInterval = 30 * time.Second
+)
+
+var (
+	// ErrMissingToken 请求中没有 Bearer token
+	ErrMissingToken = errors.New("missing bearer token")
+	// ErrUnknownKey token 的 kid 在 JWKS 中不存在
+	ErrUnknownKey = errors.New("unknown signing key")
+	// ErrRefreshTokenUsed 使用刷新 token 认证请求
+	ErrRefreshTokenUsed = errors.New("refresh token cannot be used for authentication")
+)
+
+// token 类型，写入 Claims.TokenType
+const (
+	// TokenTypeAccess 访问 token，用于认证请求
+	TokenTypeAccess = "access"
+	// TokenTypeRefresh 刷新 token，只能用于 SessionManager.Refresh 换取新的 token，不能用于认证请求
+	TokenTypeRefresh = "refresh"
+)
+
+// Claims JWT 声明
+type Claims struct {
+	jwt.RegisteredClaims
+	// Roles 角色列表
+	Roles []string `json:"roles,omitempty"`
+	// TokenType token 类型，由 SessionManager 签发的 token 为 access 或 refresh，其他签发方可以为空
+	TokenType string `json:"typ,omitempty"`
+}
+
+// HasRole 判断是否拥有角色
+func (c *Claims) HasRole(role string) bool {
+	for _, r := range c.Roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+type claimsCtxKey struct{}
+
+// ContextWithClaims 返回携带 JWT 声明的 ctx
+func ContextWithClaims(ctx context.Context, claims *Claims) context.Context {
+	return context.WithValue(ctx, claimsCtxKey{}, claims)
+}
+
+// ClaimsFromContext 返回认证拦截器写入 ctx 的 JWT 声明，公开方法或未认证时返回 false
+func ClaimsFromContext(ctx context.Context) (*Claims, bool) {
+	claims, ok := ctx.Value(claimsCtxKey{}).(*Claims)
+	return claims, ok && claims != nil
+}
+
+// AuthOption 认证选项
+type AuthOption func(o *authOption)
+
+type authOption struct {
+	secret      []byte
+	publicKey   *rsa.PublicKey
+	jwksURL     string
+	jwksRefresh time.Duration
+	issuer      string
+	audience    string
+	leeway      time.Duration
+	public      []string
+	httpClient  *http.Client
+	revoked     func(ctx context.Context, claims *Claims) error
+}
+
+// WithHS256Secret 使用 HS256 共享密钥校验签名
+func WithHS256Secret(secret []byte) AuthOption {
+	return func(o *authOption) {
+		o.secret = secret
+	}
+}
+
+// WithRS256PublicKey 使用 RS256 公钥校验签名
+func WithRS256PublicKey(key *rsa.PublicKey) AuthOption {
+	return func(o *authOption) {
+		o.publicKey = key
+	}
+}
+
+// WithJWKS 从 JWKS 地址获取 RS256 公钥，按 token 的 kid 选择，每隔 refresh 刷新一次，
+// 遇到未知 kid 时立即刷新（至少间隔 30s），refresh <= 0 时默认 1h
+func WithJWKS(url string, refresh time.Duration) AuthOption {
+	return func(o *authOption) {
+		o.jwksURL = url
+		o.jwksRefresh = refresh
+	}
+}
+
+// WithIssuer 要求 token 的 iss 与 issuer 一致
+func WithIssuer(issuer string) AuthOption {
+	return func(o *authOption) {
+		o.issuer = issuer
+	}
+}
+
+// WithAudience 要求 token 的 aud 包含 audience
+func WithAudience(audience string) AuthOption {
+	return func(o *authOption) {
+		o.audience = audience
+	}
+}
+
+// WithLeeway 设置校验 exp、nbf、iat 时允许的时钟偏差，默认 30s
+func WithLeeway(leeway time.Duration) AuthOption {
+	return func(o *authOption) {
+		o.leeway = leeway
+	}
+}
+
+// WithPublicMethods 设置不需要认证的方法，方法为完整名称，如 /user.User/Login，
+// 以 /* 结尾时匹配服务下的所有方法，如 /grpc.health.v1.Health/*
+func WithPublicMethods(methods ...string) AuthOption {
+	return func(o *authOption) {
+		o.public = append(o.public, methods...)
+	}
+}
+
+// WithJWKSHTTPClient 设置获取 JWKS 的 HTTP 客户端，默认超时 10s
+func WithJWKSHTTPClient(client *http.Client) AuthOption {
+	return func(o *authOption) {
+		o.httpClient = client
+	}
+}
+
+// WithRevocationCheck 设置校验签名后检查 token 是否已注销的函数，返回错误时拒绝请求
+func WithRevocationCheck(fn func(ctx context.Context, claims *Claims) error) AuthOption {
+	return func(o *authOption) {
+		o.revoked = fn
+	}
+}
+
+// WithSessionManager 使用 m 的密钥、iss 与 aud 校验 token，并检查 m 的黑名单，
+// gRPC 拦截器与 HTTP 的 JWTAuth 使用同一个 SessionManager 时注销对两者同时生效
+func WithSessionManager(m *SessionManager) AuthOption {
+	return func(o *authOption) {
+		o.secret = m.o.secret
+		o.issuer = m.o.issuer
+		o.audience = m.o.audience
+		o.revoked = m.CheckRevoked
+	}
+}
+
+// Verifier JWT 校验器，gRPC 拦截器与 HTTP 中间件共用
+type Verifier struct {
+	o      *authOption
+	parser *jwt.Parser
+	jwks   *jwks
+}
+
+// NewVerifier 创建 JWT 校验器
+// 未通过选项设置密钥时从环境变量读取：
+//   - JWT_SECRET: HS256 共享密钥
+//   - JWT_JWKS_URL: JWKS 地址
+//   - JWT_JWKS_REFRESH: JWKS 刷新间隔（默认 1h）
+//   - JWT_ISSUER、JWT_AUDIENCE: 期望的 iss 与 aud
+func NewVerifier(opts ...AuthOption) (*Verifier, error) {
+	o := &authOption{leeway: 30 * time.Second}
+	for _, opt := range opts {
+		opt(o)
+	}
+	if o.secret == nil && o.publicKey == nil && o.jwksURL == "" {
+		o.secret = []byte(envkey.GetString("JWT_SECRET"))
+		o.jwksURL = envkey.GetString("JWT_JWKS_URL")
+		o.jwksRefresh = envkey.GetDurationD("JWT_JWKS_REFRESH", time.Hour)
+		if len(o.secret) == 0 {
+			o.secret = nil
+		}
+	}
+	if o.issuer == "" {
+		o.issuer = envkey.GetString("JWT_ISSUER")
+	}
+	if o.audience == "" {
+		o.audience = envkey.GetString("JWT_AUDIENCE")
+	}
+
+	var methods []string
+	if o.secret != nil {
+		methods = append(methods, jwt.SigningMethodHS256.Alg())
+	}
+	if o.publicKey != nil || o.jwksURL != "" {
+		methods = append(methods, jwt.SigningMethodRS256.Alg())
+	}
+	if len(methods) == 0 {
+		return nil, errors.New("jwt verifier requires a HS256 secret, RS256 public key or JWKS url")
+	}
+
+	parserOpts := []jwt.ParserOption{
+		jwt.WithValidMethods(methods),
+		jwt.WithLeeway(o.leeway),
+		jwt.WithExpirationRequired(),
+	}
+	if o.issuer != "" {
+		parserOpts = append(parserOpts, jwt.WithIssuer(o.issuer))
+	}
+	if o.audience != "" {
+		parserOpts = append(parserOpts, jwt.WithAudience(o.audience))
+	}
+
+	v := &Verifier{o: o, parser: jwt.NewParser(parserOpts...)}
+	if o.jwksURL != "" {
+		client := o.httpClient
+		if client == nil {
+			client = &http.Client{Timeout: 10 * time.Second}
+		}
+		refresh := o.jwksRefresh
+		if refresh <= 0 {
+			refresh = time.Hour
+		}
+		v.jwks = &jwks{url: o.jwksURL, client: client, refresh: refresh}
+	}
+	return v, nil
+}
+
+// Verify 校验 token 并返回其中的声明
+func (v *Verifier) Verify(ctx context.Context, token string) (*Claims, error) {
+	claims := &Claims{}
+	_, err := v.parser.ParseWithClaims(token, claims, func(t *jwt.Token) (interface{}, error) {
+		if t.Method.Alg() == jwt.SigningMethodHS256.Alg() {
+			return v.o.secret, nil
+		}
+		kid, _ := t.Header["kid"].(string)
+		if v.jwks != nil && (kid != "" || v.o.publicKey == nil) {
+			return v.jwks.key(ctx, kid)
+		}
+		return v.o.publicKey, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+// VerifyAccess 校验用于认证请求的 token，拒绝刷新 token，设置了 WithRevocationCheck 时检查是否已注销
+func (v *Verifier) VerifyAccess(ctx context.Context, token string) (*Claims, error) {
+	claims, err := v.Verify(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	if claims.TokenType == TokenTypeRefresh {
+		return nil, ErrRefreshTokenUsed
+	}
+	if v.o.revoked != nil {
+		if err := v.o.revoked(ctx, claims); err != nil {
+			return nil, err
+		}
+	}
+	return claims, nil
+}
+
+// IsPublic 判断方法是否不需要认证
+func (v *Verifier) IsPublic(method string) bool {
+	for _, m := range v.o.public {
+		if m == method || (strings.HasSuffix(m, "/*") && strings.HasPrefix(method, strings.TrimSuffix(m, "*"))) {
+			return true
+		}
+	}
+	return false
+}
+
+// BearerToken 从 Authorization 头的值中取出 token
+func BearerToken(authorization string) (string, error) {
+	const prefix = "bearer "
+	if len(authorization) <= len(prefix) || !strings.EqualFold(authorization[:len(prefix)], prefix) {
+		return "", ErrMissingToken
+	}
+	return strings.TrimSpace(authorization[len(prefix):]), nil
+}
+
+// UnaryServerAuthInterceptor 校验 metadata 中 authorization 的 Bearer token，通过后将声明写入 ctx，
+// 通过 ClaimsFromContext 读取；校验失败返回 Unauthenticated，errno.ErrUnauthenticated 写入 trailer。
+// 选项见 NewVerifier，配置错误时 panic；使用 WithSessionManager 时检查 SessionManager 的黑名单
+func UnaryServerAuthInterceptor(opts ...AuthOption) grpc.UnaryServerInterceptor {
+	v := mustNewVerifier(opts...)
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, err := v.authenticate(ctx, info.FullMethod)
+		if err != nil {
+			_ = grpc.SetTrailer(ctx, metadata.Pairs(ErrorCodeTrailerKey, strconv.FormatInt(int64(errno.ErrUnauthenticated), 10)))
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerAuthInterceptor 同 UnaryServerAuthInterceptor，用于流式调用
+func StreamServerAuthInterceptor(opts ...AuthOption) grpc.StreamServerInterceptor {
+	v := mustNewVerifier(opts...)
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := v.authenticate(ss.Context(), info.FullMethod)
+		if err != nil {
+			ss.SetTrailer(metadata.Pairs(ErrorCodeTrailerKey, strconv.FormatInt(int64(errno.ErrUnauthenticated), 10)))
+			return err
+		}
+		return handler(srv, &contextStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+func mustNewVerifier(opts ...AuthOption) *Verifier {
+	v, err := NewVerifier(opts...)
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+// authenticate 校验请求的 token，公开方法直接放行
+func (v *Verifier) authenticate(ctx context.Context, method string) (context.Context, error) {
+	if v.IsPublic(method) {
+		return ctx, nil
+	}
+
+	var authorization string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(AuthorizationKey); len(values) > 0 {
+			authorization = values[0]
+		}
+	}
+	token, err := BearerToken(authorization)
+	if err == nil {
+		var claims *Claims
+		if claims, err = v.VerifyAccess(ctx, token); err == nil {
+			return ContextWithClaims(ctx, claims), nil
+		}
+	}
+
+	var se errorx.StatusError
+	_ = errors.As(errorx.New(errno.ErrUnauthenticated), &se)
+	return ctx, status.Error(codes.Unauthenticated, fmt.Sprintf("%s: %v", se.Msg(), err))
+}
+
+// contextStream 替换 ServerStream 的 ctx
+type contextStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *contextStream) Context() context.Context {
+	return s.ctx
+}
+
+// jwks 缓存 JWKS 中的 RSA 公钥，获取失败时继续使用旧的公钥，两次获取至少间隔 jwksMinRefreshInterval
+type jwks struct {
+	url     string
+	client  *http.Client
+	refresh time.Duration
+
+	mu          sync.Mutex
+	keys        map[string]*rsa.PublicKey
+	fetchedAt   time.Time
+	attemptedAt time.Time
+}
+
+// key 返回 kid 对应的公钥，缓存过期或 kid 未知时重新获取
+func (j *jwks) key(ctx context.Context, kid string) (*rsa.PublicKey, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	key, ok := j.keys[kid]
+	if ok && time.Since(j.fetchedAt) <= j.refresh {
+		return key, nil
+	}
+	if time.Since(j.attemptedAt) < jwksMinRefreshInterval {
+		if ok {
+			return key, nil
+		}
+		return nil, fmt.Errorf("%w: kid=%s", ErrUnknownKey, kid)
+	}
+
+	j.attemptedAt = time.Now()
+	keys, err := j.fetch(ctx)
+	if err != nil {
+		// 获取失败时继续使用旧的公钥，避免 JWKS 服务短暂不可用导致所有请求失败
+		if ok {
+			return key, nil
+		}
+		return nil, err
+	}
+	j.keys, j.fetchedAt = keys, time.Now()
+
+	if key, ok = keys[kid]; !ok {
+		return nil, fmt.Errorf("%w: kid=%s", ErrUnknownKey, kid)
+	}
+	return key, nil
+}
+
+// fetch 获取 JWKS 并解析其中用于签名的 RSA 公钥
+func (j *jwks) fetch(ctx context.Context) (map[string]*rsa.PublicKey, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, j.url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := j.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("fetch jwks failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetch jwks failed: unexpected status %d", resp.StatusCode)
+	}
+
+	var set struct {
+		Keys []struct {
+			Kid string `json:"kid"`
+			Kty string `json:"kty"`
+			Use string `json:"use"`
+			N   string `json:"n"`
+			E   string `json:"e"`
+		} `json:"keys"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&set); err != nil {
+		return nil, fmt.Errorf("parse jwks failed: %w", err)
+	}
+
+	keys := make(map[string]*rsa.PublicKey, len(set.Keys))
+	for _, k := range set.Keys {
+		if k.Kty != "RSA" || (k.Use != "" && k.Use != "sig") {
+			continue
+		}
+		n, err := base64.RawURLEncoding.DecodeString(k.N)
+		if err != nil {
+			return nil, fmt.Errorf("parse jwks key %s failed: %w", k.Kid, err)
+		}
+		e, err := base64.RawURLEncoding.DecodeString(k.E)
+		if err != nil {
+			return nil, fmt.Errorf("parse jwks key %s failed: %w", k.Kid, err)
+		}
+		keys[k.Kid] = &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}
+	}
+	return keys, nil
+}
@@ -0,0 +1,109 @@
+package middleware
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func signToken(t *testing.T, method jwt.SigningMethod, key interface{}, kid string, claims *Claims) string {
+	t.Helper()
+	token := jwt.NewWithClaims(method, claims)
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
+	s, err := token.SignedString(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func newClaims(sub string) *Claims {
+	return &Claims{
+		RegisteredClaims: jwt.RegisteredClaims{Subject: sub, ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour))},
+		Roles:            []string{"admin"},
+	}
+}
+
+func TestUnaryServerAuthInterceptor(t *testing.T) {
+	secret := []byte("secret")
+	interceptor := UnaryServerAuthInterceptor(WithHS256Secret(secret), WithPublicMethods("/user.User/Login"))
+	call := func(ctx context.Context, method string) (*Claims, error) {
+		var claims *Claims
+		_, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: method}, func(ctx context.Context, req interface{}) (interface{}, error) {
+			claims, _ = ClaimsFromContext(ctx)
+			return nil, nil
+		})
+		return claims, err
+	}
+	withToken := func(token string) context.Context {
+		return metadata.NewIncomingContext(context.Background(), metadata.Pairs(AuthorizationKey, "Bearer "+token))
+	}
+
+	t.Run("有效 token", func(t *testing.T) {
+		claims, err := call(withToken(signToken(t, jwt.SigningMethodHS256, secret, "", newClaims("42"))), "/user.User/GetUser")
+		if err != nil || claims == nil || claims.Subject != "42" || !claims.HasRole("admin") {
+			t.Errorf("claims = %+v, err = %v", claims, err)
+		}
+	})
+
+	t.Run("缺少或错误的 token", func(t *testing.T) {
+		for _, ctx := range []context.Context{
+			context.Background(),
+			withToken(signToken(t, jwt.SigningMethodHS256, []byte("other"), "", newClaims("42"))),
+		} {
+			if _, err := call(ctx, "/user.User/GetUser"); status.Code(err) != codes.Unauthenticated {
+				t.Errorf("err = %v, want Unauthenticated", err)
+			}
+		}
+	})
+
+	t.Run("公开方法", func(t *testing.T) {
+		if _, err := call(context.Background(), "/user.User/Login"); err != nil {
+			t.Errorf("err = %v", err)
+		}
+	})
+}
+
+func TestVerifierJWKS(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]any{"keys": []map[string]string{{
+			"kid": "k1",
+			"kty": "RSA",
+			"use": "sig",
+			"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}}})
+	}))
+	defer srv.Close()
+
+	v, err := NewVerifier(WithJWKS(srv.URL, time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	if _, err := v.Verify(ctx, signToken(t, jwt.SigningMethodRS256, key, "k1", newClaims("42"))); err != nil {
+		t.Errorf("Verify() error = %v", err)
+	}
+	if _, err := v.Verify(ctx, signToken(t, jwt.SigningMethodRS256, key, "k2", newClaims("42"))); err == nil {
+		t.Error("Verify() with unknown kid error = nil")
+	}
+}
@@ -0,0 +1,46 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/ZampoRen/go-server-comon/internal/errno"
+	"github.com/ZampoRen/go-server-comon/pkg/breaker"
+	"github.com/ZampoRen/go-server-comon/pkg/errorx"
+)
+
+// UnaryClientBreakerInterceptor 按方法熔断，熔断时不发起调用，直接返回包装了 breaker.ErrOpen 的 errno.ErrUnavailable
+// 需要放在 UnaryClientErrorxInterceptor 之前，以便按 errorx 错误码的稳定性标记区分下游故障与业务错误；
+// 未携带 errorx 错误码的 gRPC 错误只有 Unavailable、DeadlineExceeded、ResourceExhausted、Internal、Unknown、DataLoss 计为失败
+func UnaryClientBreakerInterceptor(group *breaker.Group) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		done, err := group.Get(method).Allow()
+		if err != nil {
+			return errorx.WrapByCode(err, errno.ErrUnavailable, errorx.Extra(ErrorMethodExtraKey, method))
+		}
+		err = invoker(ctx, method, req, reply, cc, opts...)
+		done(breakerFailure(err))
+		return err
+	}
+}
+
+// breakerFailure 返回交给熔断器判断的错误，下游正常响应的 gRPC 错误返回 nil
+func breakerFailure(err error) error {
+	var se errorx.StatusError
+	if err == nil || errors.As(err, &se) {
+		return err
+	}
+	s, ok := status.FromError(err)
+	if !ok {
+		return err
+	}
+	switch s.Code() {
+	case codes.Unavailable, codes.DeadlineExceeded, codes.ResourceExhausted, codes.Internal, codes.Unknown, codes.DataLoss:
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/ZampoRen/go-server-comon/internal/errno"
+	"github.com/ZampoRen/go-server-comon/pkg/breaker"
+	"github.com/ZampoRen/go-server-comon/pkg/errorx"
+)
+
+func TestUnaryClientBreakerInterceptor(t *testing.T) {
+	interceptor := UnaryClientBreakerInterceptor(breaker.NewGroup(breaker.WithMinRequests(2)))
+	invoke := func(method string, err error) (error, int) {
+		calls := 0
+		got := interceptor(context.Background(), method, nil, nil, nil, func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+			calls++
+			return err
+		})
+		return got, calls
+	}
+
+	// 业务错误不计为失败
+	for i := 0; i < 3; i++ {
+		invoke("/user.User/GetUser", status.Error(codes.NotFound, "not found"))
+		invoke("/user.User/GetUser", errorx.New(errno.ErrInvalidParam))
+	}
+	if _, calls := invoke("/user.User/GetUser", nil); calls != 1 {
+		t.Fatal("breaker opened on business errors")
+	}
+
+	for i := 0; i < 2; i++ {
+		invoke("/user.User/ListUsers", status.Error(codes.Unavailable, "down"))
+	}
+	err, calls := invoke("/user.User/ListUsers", nil)
+	if calls != 0 || !errors.Is(err, breaker.ErrOpen) {
+		t.Fatalf("calls = %d, err = %v, want rejected with ErrOpen", calls, err)
+	}
+	var se errorx.StatusError
+	if !errors.As(err, &se) || se.Code() != errno.ErrUnavailable {
+		t.Fatalf("err = %v, want errno.ErrUnavailable", err)
+	}
+
+	// 熔断按方法隔离
+	if _, calls := invoke("/user.User/GetUser", nil); calls != 1 {
+		t.Fatal("breaker not isolated per method")
+	}
+}
@@ -0,0 +1,164 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/ZampoRen/go-server-comon/internal/errno"
+	"github.com/ZampoRen/go-server-comon/pkg/errorx"
+)
+
+const (
+	// ErrorCodeTrailerKey 服务端返回 errorx 错误码时使用的 trailer 键
+	ErrorCodeTrailerKey = "x-errorx-code"
+	// ErrorMethodExtraKey 客户端解码出的 errorx 错误中记录调用方法的 Extra 键
+	ErrorMethodExtraKey = "grpc_method"
+)
+
+// UnaryServerErrorxInterceptor 将 handler 返回的 errorx 错误码写入 trailer，供 UnaryClientErrorxInterceptor 在调用方还原，
+// 并按错误码注册的 HTTP 状态码转换为对应的 gRPC 状态码（见 GRPCCode），未使用 errorx 拦截器的调用方也能按状态码处理
+func UnaryServerErrorxInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		resp, err := handler(ctx, req)
+		if err == nil {
+			return resp, nil
+		}
+		if _, ok := status.FromError(err); ok {
+			return resp, err
+		}
+
+		var se errorx.StatusError
+		if !errors.As(err, &se) {
+			return resp, err
+		}
+		_ = grpc.SetTrailer(ctx, metadata.Pairs(ErrorCodeTrailerKey, strconv.FormatInt(int64(se.Code()), 10)))
+		return resp, status.Error(GRPCCode(se.Code()), se.Msg())
+	}
+}
+
+// StreamServerErrorxInterceptor 同 UnaryServerErrorxInterceptor，用于流式调用
+func StreamServerErrorxInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		err := handler(srv, ss)
+		if err == nil {
+			return nil
+		}
+		if _, ok := status.FromError(err); ok {
+			return err
+		}
+
+		var se errorx.StatusError
+		if !errors.As(err, &se) {
+			return err
+		}
+		ss.SetTrailer(metadata.Pairs(ErrorCodeTrailerKey, strconv.FormatInt(int64(se.Code()), 10)))
+		return status.Error(GRPCCode(se.Code()), se.Msg())
+	}
+}
+
+// GRPCCode 按 errorx 错误码注册的 HTTP 状态码返回对应的 gRPC 状态码，没有对应关系时返回 Unknown
+func GRPCCode(c int32) codes.Code {
+	switch errno.HTTPStatus(c) {
+	case http.StatusBadRequest:
+		return codes.InvalidArgument
+	case http.StatusUnauthorized:
+		return codes.Unauthenticated
+	case http.StatusForbidden:
+		return codes.PermissionDenied
+	case http.StatusNotFound:
+		return codes.NotFound
+	case http.StatusConflict:
+		return codes.Aborted
+	case http.StatusUnprocessableEntity:
+		return codes.FailedPrecondition
+	case http.StatusTooManyRequests:
+		return codes.ResourceExhausted
+	case http.StatusInternalServerError:
+		return codes.Internal
+	case http.StatusNotImplemented:
+		return codes.Unimplemented
+	case http.StatusServiceUnavailable:
+		return codes.Unavailable
+	case http.StatusGatewayTimeout:
+		return codes.DeadlineExceeded
+	default:
+		return codes.Unknown
+	}
+}
+
+// UnaryClientErrorxInterceptor 根据 trailer 中的错误码将 gRPC 错误还原为 errorx 错误，
+// 调用方可以直接用 errors.As 取得 errorx.StatusError
+func UnaryClientErrorxInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		var trailer metadata.MD
+		err := invoker(ctx, method, req, reply, cc, append(opts, grpc.Trailer(&trailer))...)
+		if err == nil {
+			return nil
+		}
+		return decodeErrorx(err, trailer, method)
+	}
+}
+
+// StreamClientErrorxInterceptor 同 UnaryClientErrorxInterceptor，用于流式调用，在 RecvMsg 返回错误时还原
+func StreamClientErrorxInterceptor() grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		cs, err := streamer(ctx, desc, cc, method, opts...)
+		if err != nil {
+			return nil, err
+		}
+		return &errorxClientStream{ClientStream: cs, method: method}, nil
+	}
+}
+
+// errorxClientStream 在流结束时根据 trailer 还原 errorx 错误
+type errorxClientStream struct {
+	grpc.ClientStream
+	method string
+}
+
+func (s *errorxClientStream) RecvMsg(m interface{}) error {
+	err := s.ClientStream.RecvMsg(m)
+	if err == nil || err == io.EOF {
+		return err
+	}
+	// RecvMsg 返回错误后 trailer 已就绪
+	return decodeErrorx(err, s.Trailer(), s.method)
+}
+
+// decodeErrorx 按 trailer 中的错误码包装 err，没有错误码时原样返回
+func decodeErrorx(err error, trailer metadata.MD, method string) error {
+	values := trailer.Get(ErrorCodeTrailerKey)
+	if len(values) == 0 {
+		return err
+	}
+	code, perr := strconv.ParseInt(values[0], 10, 32)
+	if perr != nil {
+		return err
+	}
+	return errorx.WrapByCode(err, int32(code), errorx.Extra(ErrorMethodExtraKey, method))
+}
+
+// UnaryClientInterceptors 返回服务间调用的默认客户端拦截器链：链路追踪、errorx 解码，
+// 幂等方法的重试由 rpcclient.Dial 通过 gRPC service config 完成
+func UnaryClientInterceptors() []grpc.UnaryClientInterceptor {
+	return []grpc.UnaryClientInterceptor{
+		UnaryClientTracingInterceptor(),
+		UnaryClientErrorxInterceptor(),
+	}
+}
+
+// StreamClientInterceptors 返回流式调用的默认客户端拦截器链：链路追踪、errorx 解码，流式调用不重试
+func StreamClientInterceptors() []grpc.StreamClientInterceptor {
+	return []grpc.StreamClientInterceptor{
+		StreamClientTracingInterceptor(),
+		StreamClientErrorxInterceptor(),
+	}
+}
@@ -0,0 +1,105 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/ZampoRen/go-server-comon/internal/errno"
+	"github.com/ZampoRen/go-server-comon/pkg/errorx"
+)
+
+// stubClientStream 在消息读完后返回 err，并带上 trailer
+type stubClientStream struct {
+	grpc.ClientStream
+	err     error
+	trailer metadata.MD
+}
+
+func (s *stubClientStream) RecvMsg(interface{}) error { return s.err }
+func (s *stubClientStream) Trailer() metadata.MD      { return s.trailer }
+
+func TestStreamClientErrorxInterceptor(t *testing.T) {
+	interceptor := StreamClientErrorxInterceptor()
+	open := func(stub *stubClientStream) grpc.ClientStream {
+		cs, err := interceptor(context.Background(), &grpc.StreamDesc{ServerStreams: true}, nil, "/user.User/WatchUsers",
+			func(context.Context, *grpc.StreamDesc, *grpc.ClientConn, string, ...grpc.CallOption) (grpc.ClientStream, error) {
+				return stub, nil
+			})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return cs
+	}
+
+	t.Run("按 trailer 还原 errorx 错误", func(t *testing.T) {
+		cs := open(&stubClientStream{
+			err:     status.Error(codes.Unknown, "forbidden"),
+			trailer: metadata.Pairs(ErrorCodeTrailerKey, "100005"),
+		})
+		var se errorx.StatusError
+		if err := cs.RecvMsg(nil); !errors.As(err, &se) || se.Code() != errno.ErrPermissionDenied {
+			t.Fatalf("RecvMsg() error = %v, want code %d", err, errno.ErrPermissionDenied)
+		}
+	})
+
+	t.Run("流正常结束", func(t *testing.T) {
+		cs := open(&stubClientStream{err: io.EOF, trailer: metadata.Pairs(ErrorCodeTrailerKey, "100005")})
+		if err := cs.RecvMsg(nil); err != io.EOF {
+			t.Fatalf("RecvMsg() error = %v, want io.EOF", err)
+		}
+	})
+}
+
+// stubServerStream 记录 SetTrailer 设置的 trailer
+type stubServerStream struct {
+	grpc.ServerStream
+	trailer metadata.MD
+}
+
+func (s *stubServerStream) SetTrailer(md metadata.MD) { s.trailer = metadata.Join(s.trailer, md) }
+
+func TestStreamServerErrorxInterceptor(t *testing.T) {
+	ss := &stubServerStream{}
+	err := StreamServerErrorxInterceptor()(nil, ss, &grpc.StreamServerInfo{FullMethod: "/user.User/WatchUsers"},
+		func(interface{}, grpc.ServerStream) error { return errorx.New(errno.ErrPermissionDenied) })
+	if status.Code(err) != codes.PermissionDenied {
+		t.Fatalf("error = %v, want PermissionDenied", err)
+	}
+	if got := ss.trailer.Get(ErrorCodeTrailerKey); len(got) != 1 || got[0] != "100005" {
+		t.Fatalf("trailer = %v", ss.trailer)
+	}
+}
+
+func TestUnaryServerErrorxInterceptor(t *testing.T) {
+	tests := map[int32]codes.Code{
+		errno.ErrInvalidParam:         codes.InvalidArgument,
+		errno.ErrUnauthenticated:      codes.Unauthenticated,
+		errno.ErrPermissionDenied:     codes.PermissionDenied,
+		errno.ErrNotFound:             codes.NotFound,
+		errno.ErrVersionConflict:      codes.Aborted,
+		errno.ErrTooManyRequests:      codes.ResourceExhausted,
+		errno.ErrDBUnavailable:        codes.Unavailable,
+		errno.ErrDBTimeout:            codes.DeadlineExceeded,
+		errno.ErrInternal:             codes.Internal,
+		errno.ErrIdempotencyKeyReused: codes.FailedPrecondition,
+	}
+	for c, want := range tests {
+		ss := &stubServerStream{}
+		err := StreamServerErrorxInterceptor()(nil, ss, &grpc.StreamServerInfo{}, func(interface{}, grpc.ServerStream) error {
+			return errorx.New(c)
+		})
+		if got := status.Code(err); got != want {
+			t.Errorf("code %d: status = %s, want %s", c, got, want)
+		}
+		if got := ss.trailer.Get(ErrorCodeTrailerKey); len(got) != 1 {
+			t.Errorf("code %d: trailer = %v", c, ss.trailer)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package httpmw
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/app"
+
+	"github.com/ZampoRen/go-server-comon/internal/errno"
+	"github.com/ZampoRen/go-server-comon/internal/response"
+	"github.com/ZampoRen/go-server-comon/pkg/errorx"
+)
+
+// ConcurrencyOption 并发限制配置选项
+type ConcurrencyOption func(o *concurrencyOption)
+
+type concurrencyOption struct {
+	maxQueue     int
+	queueTimeout time.Duration
+}
+
+// WithQueue 设置等待队列长度和最长等待时间
+// 并发已满时最多 size 个请求排队等待，超过 timeout 仍未获得执行机会则返回 429
+func WithQueue(size int, timeout time.Duration) ConcurrencyOption {
+	return func(o *concurrencyOption) {
+		o.maxQueue = size
+		o.queueTimeout = timeout
+	}
+}
+
+// routeLimiter 单个路由的并发限制器
+type routeLimiter struct {
+	sem     chan struct{}
+	waiting atomic.Int64
+}
+
+// ConcurrencyLimit 限制每个路由同时处理的请求数
+// 挂载在路由组上时，组内每个路由独立计数；超出限制（且排队失败）时返回 ErrTooManyRequests（HTTP 429）
+func ConcurrencyLimit(maxConcurrent int, opts ...ConcurrencyOption) app.HandlerFunc {
+	if maxConcurrent <= 0 {
+		panic("maxConcurrent should be greater than 0")
+	}
+
+	opt := &concurrencyOption{}
+	for _, o := range opts {
+		o(opt)
+	}
+
+	var limiters sync.Map
+	return func(ctx context.Context, c *app.RequestContext) {
+		v, _ := limiters.LoadOrStore(c.FullPath(), &routeLimiter{sem: make(chan struct{}, maxConcurrent)})
+		l := v.(*routeLimiter)
+
+		if !l.acquire(ctx, opt) {
+			response.Abort(ctx, c, errorx.New(errno.ErrTooManyRequests))
+			return
+		}
+		defer l.release()
+
+		c.Next(ctx)
+	}
+}
+
+// acquire 获取执行许可，并发已满时按配置排队等待
+func (l *routeLimiter) acquire(ctx context.Context, opt *concurrencyOption) bool {
+	select {
+	case l.sem <- struct{}{}:
+		return true
+	default:
+	}
+
+	if opt.maxQueue <= 0 {
+		return false
+	}
+	if l.waiting.Add(1) > int64(opt.maxQueue) {
+		l.waiting.Add(-1)
+		return false
+	}
+	defer l.waiting.Add(-1)
+
+	timer := time.NewTimer(opt.queueTimeout)
+	defer timer.Stop()
+
+	select {
+	case l.sem <- struct{}{}:
+		return true
+	case <-timer.C:
+		return false
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// release 归还执行许可
+func (l *routeLimiter) release() {
+	<-l.sem
+}
@@ -0,0 +1,230 @@
+package httpmw
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/app"
+)
+
+// CORSOption 跨域中间件选项
+type CORSOption func(o *corsOption)
+
+type corsOption struct {
+	origins          map[string]struct{}
+	wildcards        []wildcardOrigin
+	allowAll         bool
+	methods          string
+	headers          string
+	exposeHeaders    string
+	allowCredentials bool
+	maxAge           string
+}
+
+// WithAllowOrigins 设置允许的来源，如 https://app.example.com；* 允许任意来源，
+// https://*.example.com 允许 example.com 的任意子域名。未设置时不允许任何跨域请求
+func WithAllowOrigins(origins ...string) CORSOption {
+	return func(o *corsOption) {
+		for _, origin := range origins {
+			switch {
+			case origin == "*":
+				o.allowAll = true
+			case strings.Contains(origin, "://*."):
+				scheme, suffix, _ := strings.Cut(strings.ToLower(origin), "://*")
+				o.wildcards = append(o.wildcards, wildcardOrigin{prefix: scheme + "://", suffix: suffix})
+			default:
+				o.origins[strings.ToLower(origin)] = struct{}{}
+			}
+		}
+	}
+}
+
+// WithAllowMethods 设置允许的方法，默认 GET、POST、PUT、PATCH、DELETE、HEAD
+func WithAllowMethods(methods ...string) CORSOption {
+	return func(o *corsOption) {
+		o.methods = strings.ToUpper(strings.Join(methods, ", "))
+	}
+}
+
+// WithAllowHeaders 设置允许的请求头，默认 Origin、Content-Type、Authorization、X-Request-ID
+func WithAllowHeaders(headers ...string) CORSOption {
+	return func(o *corsOption) {
+		o.headers = strings.Join(headers, ", ")
+	}
+}
+
+// WithExposeHeaders 设置浏览器可读取的响应头，默认 X-Request-ID
+func WithExposeHeaders(headers ...string) CORSOption {
+	return func(o *corsOption) {
+		o.exposeHeaders = strings.Join(headers, ", ")
+	}
+}
+
+// WithAllowCredentials 允许携带 Cookie 等凭证，此时响应中返回请求的来源而不是 *，
+// 必须通过 WithAllowOrigins 列出具体的来源，不能与 * 同时使用
+func WithAllowCredentials() CORSOption {
+	return func(o *corsOption) {
+		o.allowCredentials = true
+	}
+}
+
+// WithMaxAge 设置预检请求结果的缓存时间，默认 12h
+func WithMaxAge(d time.Duration) CORSOption {
+	return func(o *corsOption) {
+		o.maxAge = strconv.Itoa(int(d.Seconds()))
+	}
+}
+
+// CORS 处理跨域请求，预检请求（OPTIONS 且带有 Access-Control-Request-Method）直接返回 204，
+// 来源不在允许列表中时不写入跨域响应头，由浏览器拒绝，请求本身仍交给后续 handler 处理。
+// 同时允许任意来源与凭证时任何网站都能携带用户凭证发起请求，视为配置错误并 panic
+func CORS(opts ...CORSOption) app.HandlerFunc {
+	o := &corsOption{
+		origins:       make(map[string]struct{}),
+		methods:       "GET, POST, PUT, PATCH, DELETE, HEAD",
+		headers:       "Origin, Content-Type, Authorization, " + RequestIDHeader,
+		exposeHeaders: RequestIDHeader,
+		maxAge:        strconv.Itoa(int((12 * time.Hour).Seconds())),
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	if o.allowAll && o.allowCredentials {
+		panic("CORS with credentials requires an explicit origin list, not *")
+	}
+
+	return func(ctx context.Context, c *app.RequestContext) {
+		origin := string(c.GetHeader("Origin"))
+		if origin == "" {
+			c.Next(ctx)
+			return
+		}
+
+		h := &c.Response.Header
+		h.Add("Vary", "Origin")
+		preflight := string(c.Method()) == http.MethodOptions && len(c.GetHeader("Access-Control-Request-Method")) > 0
+		if !o.allowed(origin) {
+			if preflight {
+				c.AbortWithStatus(http.StatusNoContent)
+				return
+			}
+			c.Next(ctx)
+			return
+		}
+
+		if o.allowAll {
+			h.Set("Access-Control-Allow-Origin", "*")
+		} else {
+			h.Set("Access-Control-Allow-Origin", origin)
+		}
+		if o.allowCredentials {
+			h.Set("Access-Control-Allow-Credentials", "true")
+		}
+
+		if preflight {
+			h.Set("Access-Control-Allow-Methods", o.methods)
+			h.Set("Access-Control-Allow-Headers", o.headers)
+			h.Set("Access-Control-Max-Age", o.maxAge)
+			c.AbortWithStatus(http.StatusNoContent)
+			return
+		}
+		if o.exposeHeaders != "" {
+			h.Set("Access-Control-Expose-Headers", o.exposeHeaders)
+		}
+		c.Next(ctx)
+	}
+}
+
+// allowed 判断来源是否在允许列表中
+func (o *corsOption) allowed(origin string) bool {
+	if o.allowAll {
+		return true
+	}
+	origin = strings.ToLower(origin)
+	if _, ok := o.origins[origin]; ok {
+		return true
+	}
+	for _, w := range o.wildcards {
+		if w.match(origin) {
+			return true
+		}
+	}
+	return false
+}
+
+// wildcardOrigin https://*.example.com 形式的来源，prefix 为 https://，suffix 为 .example.com
+type wildcardOrigin struct {
+	prefix string
+	suffix string
+}
+
+func (w wildcardOrigin) match(origin string) bool {
+	return len(origin) > len(w.prefix)+len(w.suffix) && strings.HasPrefix(origin, w.prefix) && strings.HasSuffix(origin, w.suffix)
+}
+
+// SecurityOption 安全响应头中间件选项
+type SecurityOption func(o *securityOption)
+
+type securityOption struct {
+	headers map[string]string
+}
+
+// WithHSTS 设置 Strict-Transport-Security 的有效期，<= 0 时不返回该响应头，默认不返回，只应在全站 HTTPS 时开启
+func WithHSTS(maxAge time.Duration, includeSubDomains bool) SecurityOption {
+	return func(o *securityOption) {
+		if maxAge <= 0 {
+			delete(o.headers, "Strict-Transport-Security")
+			return
+		}
+		v := "max-age=" + strconv.Itoa(int(maxAge.Seconds()))
+		if includeSubDomains {
+			v += "; includeSubDomains"
+		}
+		o.headers["Strict-Transport-Security"] = v
+	}
+}
+
+// WithContentSecurityPolicy 设置 Content-Security-Policy，默认 default-src 'none'; frame-ancestors 'none'，适用于只返回 JSON 的接口
+func WithContentSecurityPolicy(policy string) SecurityOption {
+	return func(o *securityOption) {
+		o.headers["Content-Security-Policy"] = policy
+	}
+}
+
+// WithSecurityHeader 设置任意安全响应头，value 为空时不返回该响应头
+func WithSecurityHeader(key, value string) SecurityOption {
+	return func(o *securityOption) {
+		if value == "" {
+			delete(o.headers, key)
+			return
+		}
+		o.headers[key] = value
+	}
+}
+
+// SecurityHeaders 为所有响应添加常用的安全响应头：
+// X-Content-Type-Options、X-Frame-Options、Referrer-Policy、Content-Security-Policy，
+// 以及通过 WithHSTS 开启的 Strict-Transport-Security。handler 已设置的同名响应头不会被覆盖
+func SecurityHeaders(opts ...SecurityOption) app.HandlerFunc {
+	o := &securityOption{headers: map[string]string{
+		"X-Content-Type-Options":  "nosniff",
+		"X-Frame-Options":         "DENY",
+		"Referrer-Policy":         "strict-origin-when-cross-origin",
+		"Content-Security-Policy": "default-src 'none'; frame-ancestors 'none'",
+	}}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	return func(ctx context.Context, c *app.RequestContext) {
+		c.Next(ctx)
+		for k, v := range o.headers {
+			if len(c.Response.Header.Peek(k)) == 0 {
+				c.Response.Header.Set(k, v)
+			}
+		}
+	}
+}
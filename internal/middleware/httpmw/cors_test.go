@@ -0,0 +1,81 @@
+package httpmw
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/common/ut"
+)
+
+// serve 以 origin 发起请求，preflight 为 true 时发起预检请求，返回请求上下文与后续 handler 是否执行
+func serve(handler app.HandlerFunc, method, origin string, preflight bool) (*app.RequestContext, bool) {
+	headers := []ut.Header{{Key: "Origin", Value: origin}}
+	if preflight {
+		headers = append(headers, ut.Header{Key: "Access-Control-Request-Method", Value: http.MethodPut})
+	}
+	c := ut.CreateUtRequestContext(method, "/users", nil, headers...)
+	next := false
+	c.SetHandlers(app.HandlersChain{handler, func(ctx context.Context, c *app.RequestContext) { next = true }})
+	c.Next(context.Background())
+	return c, next
+}
+
+func TestCORS(t *testing.T) {
+	cors := CORS(WithAllowOrigins("https://app.example.com", "https://*.example.org"), WithAllowCredentials())
+
+	t.Run("预检请求", func(t *testing.T) {
+		c, next := serve(cors, http.MethodOptions, "https://app.example.com", true)
+		h := &c.Response.Header
+		if next || c.Response.StatusCode() != http.StatusNoContent {
+			t.Fatalf("status = %d, next = %v", c.Response.StatusCode(), next)
+		}
+		if string(h.Peek("Access-Control-Allow-Origin")) != "https://app.example.com" ||
+			string(h.Peek("Access-Control-Allow-Credentials")) != "true" ||
+			len(h.Peek("Access-Control-Allow-Methods")) == 0 {
+			t.Errorf("headers = %s", h.Header())
+		}
+	})
+
+	t.Run("子域名通配", func(t *testing.T) {
+		for origin, want := range map[string]bool{
+			"https://a.example.org":      true,
+			"https://a.b.example.org":    true,
+			"https://example.org":        false,
+			"https://evilexample.org":    false,
+			"http://a.example.org":       false,
+			"https://a.example.org.evil": false,
+		} {
+			c, next := serve(cors, http.MethodGet, origin, false)
+			got := string(c.Response.Header.Peek("Access-Control-Allow-Origin")) == origin
+			if got != want || !next {
+				t.Errorf("origin %s allowed = %v, want %v, next = %v", origin, got, want, next)
+			}
+		}
+	})
+
+	t.Run("拒绝的来源", func(t *testing.T) {
+		c, next := serve(cors, http.MethodOptions, "https://evil.com", true)
+		h := &c.Response.Header
+		if next || len(h.Peek("Access-Control-Allow-Origin")) != 0 || len(h.Peek("Access-Control-Allow-Credentials")) != 0 {
+			t.Errorf("next = %v, headers = %s", next, h.Header())
+		}
+	})
+
+	t.Run("任意来源不返回凭证", func(t *testing.T) {
+		c, _ := serve(CORS(WithAllowOrigins("*")), http.MethodGet, "https://evil.com", false)
+		if string(c.Response.Header.Peek("Access-Control-Allow-Origin")) != "*" {
+			t.Errorf("headers = %s", c.Response.Header.Header())
+		}
+	})
+
+	t.Run("任意来源与凭证同时使用时 panic", func(t *testing.T) {
+		defer func() {
+			if recover() == nil {
+				t.Error("expected panic")
+			}
+		}()
+		CORS(WithAllowOrigins("*"), WithAllowCredentials())
+	})
+}
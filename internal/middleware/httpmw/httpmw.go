@@ -0,0 +1,11 @@
+// Package httpmw 提供 Hertz HTTP 中间件
+//
+//	h := server.New()
+//	h.Use(
+//		httpmw.Tracing(),
+//		httpmw.RequestID(),
+//		httpmw.Recovery(),
+//	)
+//
+// 中间件拒绝请求时使用 response.Abort 返回统一的错误响应
+package httpmw
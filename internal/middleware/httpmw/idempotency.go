@@ -0,0 +1,54 @@
+package httpmw
+
+import (
+	"bytes"
+	"context"
+
+	"github.com/cloudwego/hertz/pkg/app"
+
+	"github.com/ZampoRen/go-server-comon/internal/idempotency"
+	"github.com/ZampoRen/go-server-comon/internal/middleware"
+	"github.com/ZampoRen/go-server-comon/internal/response"
+)
+
+// Idempotency 对携带 Idempotency-Key 请求头的请求保证只成功执行一次，重复请求返回首次请求的状态码、Content-Type 与响应体，
+// 并设置 Idempotent-Replayed: true；没有该请求头的请求直接放行。状态码为 5xx 的响应不记录，客户端可以使用同一个键重试。
+// 作用域为路由与 JWTAuth 写入的 JWT subject，应注册在 JWTAuth 之后，只挂载在需要幂等的写接口上
+func Idempotency(store *idempotency.Store) app.HandlerFunc {
+	return func(ctx context.Context, c *app.RequestContext) {
+		key := string(c.GetHeader(idempotency.Header))
+		if key == "" {
+			c.Next(ctx)
+			return
+		}
+
+		scope := "http:" + string(c.Method()) + " " + c.FullPath()
+		if claims, ok := middleware.ClaimsFromContext(ctx); ok {
+			scope += ":" + claims.Subject
+		}
+		fingerprint := idempotency.Fingerprint(c.Request.URI().QueryString(), c.Request.Body())
+		resp, replayed, err := store.Do(ctx, scope, key, fingerprint, func(ctx context.Context) (*idempotency.Response, error) {
+			c.Next(ctx)
+			status := c.Response.StatusCode()
+			if status >= 500 {
+				return nil, nil
+			}
+			return &idempotency.Response{
+				Status: status,
+				Header: map[string]string{"Content-Type": string(c.Response.Header.ContentType())},
+				Body:   bytes.Clone(c.Response.Body()),
+			}, nil
+		})
+		if err != nil {
+			response.Abort(ctx, c, err)
+			return
+		}
+		if replayed {
+			c.Response.Header.Set(idempotency.ReplayedHeader, "true")
+			c.Response.Header.SetContentType(resp.Header["Content-Type"])
+			c.Response.SetStatusCode(resp.Status)
+			c.Response.SetBody(resp.Body)
+			c.Abort()
+		}
+	}
+}
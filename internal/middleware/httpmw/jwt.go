@@ -0,0 +1,47 @@
+package httpmw
+
+import (
+	"context"
+
+	"github.com/cloudwego/hertz/pkg/app"
+
+	"github.com/ZampoRen/go-server-comon/internal/errno"
+	"github.com/ZampoRen/go-server-comon/internal/middleware"
+	"github.com/ZampoRen/go-server-comon/internal/response"
+	"github.com/ZampoRen/go-server-comon/pkg/errorx"
+)
+
+// JWTAuth 校验 Authorization 头中的 Bearer token 并检查是否已注销，通过后将声明写入 ctx，
+// 通过 middleware.ClaimsFromContext 读取；校验失败返回 errno.ErrUnauthenticated（HTTP 401）
+// 登录、刷新与注销接口通过 m 的 Issue、Refresh、Revoke 实现，登录与刷新接口不应挂载该中间件
+func JWTAuth(m *middleware.SessionManager) app.HandlerFunc {
+	return func(ctx context.Context, c *app.RequestContext) {
+		token, err := middleware.BearerToken(string(c.GetHeader("Authorization")))
+		if err == nil {
+			var claims *middleware.Claims
+			if claims, err = m.Authenticate(ctx, token); err == nil {
+				c.Next(middleware.ContextWithClaims(ctx, claims))
+				return
+			}
+		}
+		response.Abort(ctx, c, errorx.WrapByCode(err, errno.ErrUnauthenticated))
+	}
+}
+
+// RequireRole 要求 JWTAuth 写入的声明中包含任一角色，否则返回 errno.ErrPermissionDenied（HTTP 403）
+func RequireRole(roles ...string) app.HandlerFunc {
+	return func(ctx context.Context, c *app.RequestContext) {
+		claims, ok := middleware.ClaimsFromContext(ctx)
+		if !ok {
+			response.Abort(ctx, c, errorx.New(errno.ErrUnauthenticated))
+			return
+		}
+		for _, role := range roles {
+			if claims.HasRole(role) {
+				c.Next(ctx)
+				return
+			}
+		}
+		response.Abort(ctx, c, errorx.New(errno.ErrPermissionDenied))
+	}
+}
@@ -0,0 +1,50 @@
+package httpmw
+
+import (
+	"context"
+
+	"github.com/cloudwego/hertz/pkg/app"
+
+	"github.com/ZampoRen/go-server-comon/pkg/i18n"
+)
+
+// LocaleKey 请求语言在 RequestContext 中的键
+const LocaleKey = "locale"
+
+// LocaleOption 语言中间件选项
+type LocaleOption func(o *localeOption)
+
+type localeOption struct {
+	query string
+}
+
+// WithLocaleQuery 设置指定语言的查询参数名，参数优先于 Accept-Language，默认 lang，为空时不读取
+func WithLocaleQuery(name string) LocaleOption {
+	return func(o *localeOption) {
+		o.query = name
+	}
+}
+
+// Locale 按查询参数与 Accept-Language 从 bundle 中选择请求的语言，将本地化器写入 ctx（见 i18n.FromContext），
+// 语言写入 RequestContext 与 Content-Language 响应头。注册后 response.Error 按请求语言返回 errorx 错误消息
+func Locale(bundle *i18n.Bundle, opts ...LocaleOption) app.HandlerFunc {
+	o := &localeOption{query: "lang"}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	return func(ctx context.Context, c *app.RequestContext) {
+		var locales []string
+		if o.query != "" {
+			if lang := c.Query(o.query); lang != "" {
+				locales = append(locales, lang)
+			}
+		}
+		locales = append(locales, i18n.ParseAcceptLanguage(string(c.GetHeader("Accept-Language")))...)
+		locale := bundle.MatchLocales(locales...)
+
+		c.Set(LocaleKey, locale)
+		c.Response.Header.Set("Content-Language", locale)
+		c.Next(i18n.NewContext(ctx, bundle.Localizer(locale)))
+	}
+}
@@ -0,0 +1,111 @@
+package httpmw
+
+import (
+	"context"
+	"math"
+	"strconv"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/common/hlog"
+
+	"github.com/ZampoRen/go-server-comon/internal/errno"
+	"github.com/ZampoRen/go-server-comon/internal/infra/ratelimit"
+	"github.com/ZampoRen/go-server-comon/internal/middleware"
+	"github.com/ZampoRen/go-server-comon/internal/response"
+	"github.com/ZampoRen/go-server-comon/pkg/errorx"
+)
+
+// 限流响应头
+const (
+	RateLimitLimitHeader     = "X-RateLimit-Limit"
+	RateLimitRemainingHeader = "X-RateLimit-Remaining"
+	RetryAfterHeader         = "Retry-After"
+)
+
+// RateLimitKeyFunc 返回限流键，返回空字符串时不限流
+type RateLimitKeyFunc func(ctx context.Context, c *app.RequestContext) string
+
+// KeyByIP 按客户端 IP 限流，IP 由 c.ClientIP 获取，部署在代理之后时需通过 engine.SetClientIPFunc 配置可信代理
+func KeyByIP(_ context.Context, c *app.RequestContext) string {
+	if ip := c.ClientIP(); ip != "" {
+		return "ip:" + ip
+	}
+	return ""
+}
+
+// KeyByUser 按 JWTAuth 写入的 JWT subject 限流，未认证的请求按客户端 IP 限流
+func KeyByUser(ctx context.Context, c *app.RequestContext) string {
+	if claims, ok := middleware.ClaimsFromContext(ctx); ok && claims.Subject != "" {
+		return "sub:" + claims.Subject
+	}
+	return KeyByIP(ctx, c)
+}
+
+// KeyByRoute 按路由限流，所有调用方共享配额
+func KeyByRoute(_ context.Context, c *app.RequestContext) string {
+	return "route:" + string(c.Method()) + " " + c.FullPath()
+}
+
+// RateLimitOption 限流中间件选项
+type RateLimitOption func(o *rateLimitOption)
+
+type rateLimitOption struct {
+	key      RateLimitKeyFunc
+	perRoute bool
+}
+
+// WithRateLimitKey 设置限流键，可使用 KeyByIP、KeyByUser、KeyByRoute 或自定义函数，默认 KeyByIP
+func WithRateLimitKey(fn RateLimitKeyFunc) RateLimitOption {
+	return func(o *rateLimitOption) {
+		if fn != nil {
+			o.key = fn
+		}
+	}
+}
+
+// WithPerRoute 每个路由独立计算配额，默认同一个键在挂载该中间件的所有路由间共享配额
+func WithPerRoute() RateLimitOption {
+	return func(o *rateLimitOption) {
+		o.perRoute = true
+	}
+}
+
+// RateLimit 按 limit 限流，limiter 通常为 ratelimit.NewFallback(ratelimit.NewRedis(client), ratelimit.NewLocal())，
+// Redis 故障时降级为单实例限流。超出限制时返回 errno.ErrTooManyRequests（HTTP 429）与 Retry-After，
+// 所有响应都带有 X-RateLimit-Limit 与 X-RateLimit-Remaining；限流器出错时放行并打印告警。
+// 按用户限流时应注册在 JWTAuth 之后，不同用途的限流应使用不同的键函数，避免共享令牌桶
+func RateLimit(limiter ratelimit.Limiter, limit ratelimit.Limit, opts ...RateLimitOption) app.HandlerFunc {
+	o := &rateLimitOption{key: KeyByIP}
+	for _, opt := range opts {
+		opt(o)
+	}
+	burst := strconv.Itoa(limit.BurstSize())
+
+	return func(ctx context.Context, c *app.RequestContext) {
+		key := o.key(ctx, c)
+		if key == "" || limit.IsZero() {
+			c.Next(ctx)
+			return
+		}
+		if o.perRoute {
+			key += ":" + string(c.Method()) + " " + c.FullPath()
+		}
+
+		res, err := limiter.Allow(ctx, "http:"+key, limit)
+		if err != nil {
+			hlog.CtxWarnf(ctx, "[RateLimit] check %s failed, allow request: %v", key, err)
+			c.Next(ctx)
+			return
+		}
+
+		h := &c.Response.Header
+		h.Set(RateLimitLimitHeader, burst)
+		h.Set(RateLimitRemainingHeader, strconv.Itoa(res.Remaining))
+		if !res.Allowed {
+			h.Set(RetryAfterHeader, strconv.FormatInt(int64(math.Ceil(res.RetryAfter.Seconds())), 10))
+			response.Abort(ctx, c, errorx.New(errno.ErrTooManyRequests))
+			return
+		}
+		c.Next(ctx)
+	}
+}
@@ -0,0 +1,82 @@
+package httpmw
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime/debug"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/common/hlog"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/ZampoRen/go-server-comon/internal/errno"
+	"github.com/ZampoRen/go-server-comon/internal/response"
+	"github.com/ZampoRen/go-server-comon/pkg/errorx"
+	logger "github.com/ZampoRen/go-server-comon/pkg/logs"
+)
+
+// RecoveryOption panic 恢复中间件选项
+type RecoveryOption func(o *recoveryOption)
+
+type recoveryOption struct {
+	registerer prometheus.Registerer
+}
+
+// WithRecoveryRegisterer 设置指标注册器，默认 prometheus.DefaultRegisterer
+func WithRecoveryRegisterer(reg prometheus.Registerer) RecoveryOption {
+	return func(o *recoveryOption) {
+		if reg != nil {
+			o.registerer = reg
+		}
+	}
+}
+
+// Recovery 捕获后续 handler 中的 panic，通过 logger.ErrorX 记录堆栈，并返回 errno.ErrInternal 的统一错误响应，
+// 响应中带有与日志对应的 incident_id。代替 Hertz 默认的 recovery 中间件，应在 Tracing、RequestID 之后注册
+// 导出的指标：
+//   - http_server_panics_total{method, route}: 按路由统计的 panic 次数
+func Recovery(opts ...RecoveryOption) app.HandlerFunc {
+	o := &recoveryOption{registerer: prometheus.DefaultRegisterer}
+	for _, opt := range opts {
+		opt(o)
+	}
+	panics := register(o.registerer, prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "http",
+		Subsystem: "server",
+		Name:      "panics_total",
+		Help:      "Number of panics recovered in HTTP handlers.",
+	}, []string{"method", "route"}))
+
+	return func(ctx context.Context, c *app.RequestContext) {
+		defer func() {
+			r := recover()
+			if r == nil {
+				return
+			}
+			method, route := string(c.Method()), c.FullPath()
+			panics.WithLabelValues(method, route).Inc()
+
+			err := errorx.New(errno.ErrInternal, errorx.Extra("route", route))
+			logger.Default().ErrorX(ctx, fmt.Sprintf("[Recovery] panic in %s %s: %v\n%s", method, route, r, debug.Stack()), err)
+			response.Abort(ctx, c, err)
+		}()
+		c.Next(ctx)
+	}
+}
+
+// register 注册指标，已注册时复用已有的指标
+func register[C prometheus.Collector](reg prometheus.Registerer, c C) C {
+	err := reg.Register(c)
+	if err == nil {
+		return c
+	}
+	var are prometheus.AlreadyRegisteredError
+	if errors.As(err, &are) {
+		if existing, ok := are.ExistingCollector.(C); ok {
+			return existing
+		}
+	}
+	hlog.CtxWarnf(context.Background(), "[HTTP] register metrics failed: %v", err)
+	return c
+}
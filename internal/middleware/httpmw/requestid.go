@@ -0,0 +1,91 @@
+package httpmw
+
+import (
+	"context"
+
+	"github.com/cloudwego/hertz/pkg/app"
+
+	"github.com/ZampoRen/go-server-comon/internal/middleware"
+	logger "github.com/ZampoRen/go-server-comon/pkg/logs"
+)
+
+const (
+	// RequestIDHeader 请求 ID 的请求头与响应头
+	RequestIDHeader = "X-Request-ID"
+	// maxRequestIDLen 调用方传入的请求 ID 的最大长度，超过时重新生成
+	maxRequestIDLen = 128
+)
+
+// RequestIDOption 请求 ID 中间件选项
+type RequestIDOption func(o *requestIDOption)
+
+type requestIDOption struct {
+	header    string
+	generator func() string
+}
+
+// WithRequestIDHeader 设置读取与返回请求 ID 的 header，默认 X-Request-ID
+func WithRequestIDHeader(header string) RequestIDOption {
+	return func(o *requestIDOption) {
+		if header != "" {
+			o.header = header
+		}
+	}
+}
+
+// WithRequestIDGenerator 设置请求 ID 生成函数，默认生成 32 位十六进制字符串
+func WithRequestIDGenerator(fn func() string) RequestIDOption {
+	return func(o *requestIDOption) {
+		if fn != nil {
+			o.generator = fn
+		}
+	}
+}
+
+// RequestID 从请求头读取请求 ID，没有或格式不合法时生成，写入 RequestContext、ctx 与响应头
+// ctx 中的请求 ID 会被 hlog.CtxXxx 日志自动输出、被 logger.ErrorX 写入 errorx Extra，
+// 并由 rpcclient 的客户端拦截器通过 metadata 传给下游 gRPC 服务。应在 Tracing 之后、其他中间件之前注册
+func RequestID(opts ...RequestIDOption) app.HandlerFunc {
+	o := &requestIDOption{
+		header:    RequestIDHeader,
+		generator: middleware.NewRequestID,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	return func(ctx context.Context, c *app.RequestContext) {
+		requestID := string(c.GetHeader(o.header))
+		if !validRequestID(requestID) {
+			requestID = o.generator()
+		}
+
+		c.Set(logger.RequestIDKey, requestID)
+		c.Response.Header.Set(o.header, requestID)
+		c.Next(logger.ContextWithRequestID(ctx, requestID))
+	}
+}
+
+// GetRequestID 返回当前请求的请求 ID，优先读取 ctx，其次读取 RequestContext，没有时返回空字符串
+func GetRequestID(ctx context.Context, c *app.RequestContext) string {
+	if id := logger.RequestIDFromContext(ctx); id != "" {
+		return id
+	}
+	if c != nil {
+		return c.GetString(logger.RequestIDKey)
+	}
+	return ""
+}
+
+// validRequestID 只接受长度不超过 maxRequestIDLen 的可打印 ASCII 字符，避免日志注入
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLen {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] < 0x21 || id[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
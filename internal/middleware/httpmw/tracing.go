@@ -0,0 +1,149 @@
+package httpmw
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/protocol"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "github.com/ZampoRen/go-server-comon/internal/middleware/httpmw"
+
+// TracingOption 链路追踪中间件选项
+type TracingOption func(o *tracingOption)
+
+type tracingOption struct {
+	tracerProvider trace.TracerProvider
+	propagator     propagation.TextMapPropagator
+	skip           map[string]struct{}
+}
+
+// WithTracerProvider 设置 TracerProvider，默认使用全局 TracerProvider
+func WithTracerProvider(tp trace.TracerProvider) TracingOption {
+	return func(o *tracingOption) {
+		if tp != nil {
+			o.tracerProvider = tp
+		}
+	}
+}
+
+// WithPropagator 设置跨进程传播器，默认使用全局 TextMapPropagator（trace.Init 设置为 W3C tracecontext）
+func WithPropagator(p propagation.TextMapPropagator) TracingOption {
+	return func(o *tracingOption) {
+		if p != nil {
+			o.propagator = p
+		}
+	}
+}
+
+// WithTracingSkip 不为指定路由创建 span，路由为注册时的路径，如 /healthz
+func WithTracingSkip(routes ...string) TracingOption {
+	return func(o *tracingOption) {
+		for _, r := range routes {
+			o.skip[r] = struct{}{}
+		}
+	}
+}
+
+// Tracing 从请求头提取上游的 W3C trace 上下文并为每个请求创建服务端 span，span 名称为 "方法 路由"，如 GET /users/:id
+// 后续 handler 使用同一 ctx 发起的 gRPC 调用（经 middleware.UnaryClientTracingInterceptor）与
+// Redis、MySQL、ES 调用都挂在该 span 下；响应头中写入 traceparent，便于调用方按 trace ID 排查。
+// 应作为第一个中间件注册
+func Tracing(opts ...TracingOption) app.HandlerFunc {
+	o := &tracingOption{
+		tracerProvider: otel.GetTracerProvider(),
+		propagator:     otel.GetTextMapPropagator(),
+		skip:           make(map[string]struct{}),
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	tracer := o.tracerProvider.Tracer(tracerName)
+
+	return func(ctx context.Context, c *app.RequestContext) {
+		route := c.FullPath()
+		if _, ok := o.skip[route]; ok {
+			c.Next(ctx)
+			return
+		}
+
+		method := string(c.Method())
+		name := method
+		if route != "" {
+			name += " " + route
+		}
+		ctx = o.propagator.Extract(ctx, &requestHeaderCarrier{h: &c.Request.Header})
+		ctx, span := tracer.Start(ctx, name,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				attribute.String("http.request.method", method),
+				attribute.String("http.route", route),
+				attribute.String("url.path", string(c.Path())),
+				attribute.String("client.address", c.ClientIP()),
+				attribute.String("user_agent.original", string(c.UserAgent())),
+			),
+		)
+		defer span.End()
+		o.propagator.Inject(ctx, &responseHeaderCarrier{h: &c.Response.Header})
+
+		c.Next(ctx)
+
+		statusCode := c.Response.StatusCode()
+		span.SetAttributes(attribute.Int("http.response.status_code", statusCode))
+		if errs := c.Errors; len(errs) > 0 {
+			span.RecordError(errs.Last())
+		}
+		// 客户端错误不视为服务端 span 失败
+		if statusCode >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, http.StatusText(statusCode))
+		}
+	}
+}
+
+// requestHeaderCarrier 将 Hertz 请求头适配为 propagation.TextMapCarrier
+type requestHeaderCarrier struct {
+	h *protocol.RequestHeader
+}
+
+func (c *requestHeaderCarrier) Get(key string) string {
+	return string(c.h.Peek(key))
+}
+
+func (c *requestHeaderCarrier) Set(key, value string) {
+	c.h.Set(key, value)
+}
+
+func (c *requestHeaderCarrier) Keys() []string {
+	var keys []string
+	c.h.VisitAll(func(k, _ []byte) {
+		keys = append(keys, string(k))
+	})
+	return keys
+}
+
+// responseHeaderCarrier 将 Hertz 响应头适配为 propagation.TextMapCarrier
+type responseHeaderCarrier struct {
+	h *protocol.ResponseHeader
+}
+
+func (c *responseHeaderCarrier) Get(key string) string {
+	return string(c.h.Peek(key))
+}
+
+func (c *responseHeaderCarrier) Set(key, value string) {
+	c.h.Set(key, value)
+}
+
+func (c *responseHeaderCarrier) Keys() []string {
+	var keys []string
+	c.h.VisitAll(func(k, _ []byte) {
+		keys = append(keys, string(k))
+	})
+	return keys
+}
@@ -0,0 +1,79 @@
+package middleware
+
+import (
+	"context"
+	"slices"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/ZampoRen/go-server-comon/internal/idempotency"
+)
+
+// UnaryServerIdempotencyInterceptor 对携带 idempotency-key 元数据的 methods 调用保证只成功执行一次，
+// 重复调用返回首次调用的响应并设置响应头 idempotent-replayed，methods 为空时对所有方法生效，没有该元数据的调用直接放行。
+// 只记录成功的响应，handler 返回错误时释放幂等键，客户端可以使用同一个键重试。
+// 作用域为方法与认证拦截器写入的 JWT subject，应放在 UnaryServerAuthInterceptor 与 UnaryServerErrorxInterceptor 之后
+func UnaryServerIdempotencyInterceptor(store *idempotency.Store, methods ...string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if len(methods) > 0 && !slices.Contains(methods, info.FullMethod) {
+			return handler(ctx, req)
+		}
+		var key string
+		if md, ok := metadata.FromIncomingContext(ctx); ok {
+			if values := md.Get(idempotency.Header); len(values) > 0 {
+				key = values[0]
+			}
+		}
+		msg, ok := req.(proto.Message)
+		if key == "" || !ok {
+			return handler(ctx, req)
+		}
+		body, err := proto.MarshalOptions{Deterministic: true}.Marshal(msg)
+		if err != nil {
+			return nil, err
+		}
+
+		scope := "grpc:" + info.FullMethod
+		if claims, ok := ClaimsFromContext(ctx); ok {
+			scope += ":" + claims.Subject
+		}
+		var reply interface{}
+		resp, replayed, err := store.Do(ctx, scope, key, idempotency.Fingerprint(body), func(ctx context.Context) (*idempotency.Response, error) {
+			var err error
+			reply, err = handler(ctx, req)
+			if err != nil {
+				return nil, err
+			}
+			// 响应不是 proto 消息时不记录，重复调用会再次执行
+			m, ok := reply.(proto.Message)
+			if !ok {
+				return nil, nil
+			}
+			a, err := anypb.New(m)
+			if err != nil {
+				return nil, nil
+			}
+			b, err := proto.Marshal(a)
+			if err != nil {
+				return nil, nil
+			}
+			return &idempotency.Response{Body: b}, nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		if !replayed {
+			return reply, nil
+		}
+
+		var a anypb.Any
+		if err := proto.Unmarshal(resp.Body, &a); err != nil {
+			return nil, err
+		}
+		_ = grpc.SetHeader(ctx, metadata.Pairs(idempotency.ReplayedHeader, "true"))
+		return a.UnmarshalNew()
+	}
+}
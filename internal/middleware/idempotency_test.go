@@ -0,0 +1,91 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	goredis "github.com/redis/go-redis/v9"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	"github.com/ZampoRen/go-server-comon/internal/errno"
+	"github.com/ZampoRen/go-server-comon/internal/idempotency"
+	cacheredis "github.com/ZampoRen/go-server-comon/internal/infra/cache/impl/redis"
+	"github.com/ZampoRen/go-server-comon/pkg/errorx"
+)
+
+func TestUnaryServerIdempotencyInterceptor(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := cacheredis.NewWithClientOptions(&goredis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = client.(interface{ Close() error }).Close() })
+
+	interceptor := UnaryServerIdempotencyInterceptor(idempotency.New(client), "/pay.Pay/Charge")
+	info := &grpc.UnaryServerInfo{FullMethod: "/pay.Pay/Charge"}
+	var calls int
+	var fail error
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		calls++
+		if fail != nil {
+			return nil, fail
+		}
+		return wrapperspb.Int64(int64(calls)), nil
+	}
+	withKey := func(key string) context.Context {
+		return metadata.NewIncomingContext(context.Background(), metadata.Pairs(idempotency.Header, key))
+	}
+	charge := func(ctx context.Context, req proto.Message) (int64, error) {
+		t.Helper()
+		reply, err := interceptor(ctx, req, info, handler)
+		if err != nil {
+			return 0, err
+		}
+		return reply.(*wrapperspb.Int64Value).Value, nil
+	}
+
+	t.Run("重复调用返回首次的响应", func(t *testing.T) {
+		for range 2 {
+			got, err := charge(withKey("k1"), wrapperspb.String("100"))
+			if err != nil || got != 1 {
+				t.Fatalf("reply = %d, err = %v, want 1", got, err)
+			}
+		}
+		if calls != 1 {
+			t.Errorf("calls = %d, want 1", calls)
+		}
+	})
+
+	t.Run("参数不同时拒绝", func(t *testing.T) {
+		_, err := charge(withKey("k1"), wrapperspb.String("200"))
+		var se errorx.StatusError
+		if !errors.As(err, &se) || se.Code() != errno.ErrIdempotencyKeyReused {
+			t.Fatalf("err = %v, want ErrIdempotencyKeyReused", err)
+		}
+	})
+
+	t.Run("失败后可以重试", func(t *testing.T) {
+		fail = errors.New("failed")
+		if _, err := charge(withKey("k2"), wrapperspb.String("100")); !errors.Is(err, fail) {
+			t.Fatalf("err = %v, want %v", err, fail)
+		}
+		fail = nil
+		if got, err := charge(withKey("k2"), wrapperspb.String("100")); err != nil || got != int64(calls) {
+			t.Fatalf("reply = %d, err = %v", got, err)
+		}
+	})
+
+	t.Run("没有幂等键时直接调用", func(t *testing.T) {
+		before := calls
+		for range 2 {
+			if _, err := charge(context.Background(), wrapperspb.String("100")); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if calls != before+2 {
+			t.Errorf("calls = %d, want %d", calls, before+2)
+		}
+	})
+}
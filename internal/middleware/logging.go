@@ -0,0 +1,289 @@
+package middleware
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/common/hlog"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/ZampoRen/go-server-comon/internal/errno"
+	"github.com/ZampoRen/go-server-comon/pkg/errorx"
+	logger "github.com/ZampoRen/go-server-comon/pkg/logs"
+)
+
+// RequestIDKey 请求 ID 的 metadata 键，调用方未传入时由日志拦截器生成并通过 header 返回
+const RequestIDKey = "x-request-id"
+
+// ContextWithRequestID 返回携带请求 ID 的 ctx，与 logger.ContextWithRequestID 相同，日志会自动带上请求 ID
+func ContextWithRequestID(ctx context.Context, requestID string) context.Context {
+	return logger.ContextWithRequestID(ctx, requestID)
+}
+
+// RequestIDFromContext 返回 ctx 中的请求 ID，包括 Hertz 请求 ID 中间件写入的请求 ID，没有时返回空字符串
+func RequestIDFromContext(ctx context.Context) string {
+	return logger.RequestIDFromContext(ctx)
+}
+
+// NewRequestID 生成 32 位十六进制的请求 ID
+func NewRequestID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// LogOption 日志拦截器选项
+type LogOption func(o *logOption)
+
+type logOption struct {
+	payload    bool
+	maxPayload int
+	skip       map[string]struct{}
+}
+
+// WithLogPayload 记录请求与响应内容，超过 maxLen 字节时截断，maxLen <= 0 时不截断
+// 内容可能包含敏感信息，只建议在调试时开启
+func WithLogPayload(maxLen int) LogOption {
+	return func(o *logOption) {
+		o.payload = true
+		o.maxPayload = maxLen
+	}
+}
+
+// WithLogSkip 不记录指定方法的日志，方法为完整名称，如 /grpc.health.v1.Health/Check
+// 默认跳过健康检查，设置后覆盖默认值
+func WithLogSkip(methods ...string) LogOption {
+	return func(o *logOption) {
+		o.skip = make(map[string]struct{}, len(methods))
+		for _, m := range methods {
+			o.skip[m] = struct{}{}
+		}
+	}
+}
+
+func newLogOption(opts ...LogOption) *logOption {
+	o := &logOption{
+		skip: map[string]struct{}{
+			"/grpc.health.v1.Health/Check": {},
+			"/grpc.health.v1.Health/Watch": {},
+		},
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// UnaryServerLoggingInterceptor 记录每次调用的方法、对端地址、请求大小、耗时、gRPC 状态码、errorx 错误码
+// 以及请求 ID 与 trace ID。请求 ID 从 metadata 的 x-request-id 读取，没有时生成，写入 ctx 并通过 header 返回
+func UnaryServerLoggingInterceptor(opts ...LogOption) grpc.UnaryServerInterceptor {
+	o := newLogOption(opts...)
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, requestID := withIncomingRequestID(ctx)
+		_ = grpc.SetHeader(ctx, metadata.Pairs(RequestIDKey, requestID))
+		if _, ok := o.skip[info.FullMethod]; ok {
+			return handler(ctx, req)
+		}
+
+		start := time.Now()
+		resp, err := handler(ctx, req)
+
+		l := callLog{method: info.FullMethod, size: messageSize(req), latency: time.Since(start), err: err}
+		if o.payload {
+			l.payload = " req=" + formatPayload(req, o.maxPayload) + " resp=" + formatPayload(resp, o.maxPayload)
+		}
+		l.log(ctx)
+		return resp, err
+	}
+}
+
+// StreamServerLoggingInterceptor 同 UnaryServerLoggingInterceptor，用于流式调用
+// 在流结束时记录一次，请求大小为接收消息的总大小，开启 WithLogPayload 时只记录消息数量
+func StreamServerLoggingInterceptor(opts ...LogOption) grpc.StreamServerInterceptor {
+	o := newLogOption(opts...)
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, requestID := withIncomingRequestID(ss.Context())
+		_ = ss.SetHeader(metadata.Pairs(RequestIDKey, requestID))
+		ws := &loggingStream{ServerStream: ss, ctx: ctx}
+		if _, ok := o.skip[info.FullMethod]; ok {
+			return handler(srv, ws)
+		}
+
+		start := time.Now()
+		err := handler(srv, ws)
+
+		l := callLog{method: info.FullMethod, size: ws.recvSize, latency: time.Since(start), err: err}
+		if o.payload {
+			l.payload = " recv_msgs=" + strconv.Itoa(ws.recvMsgs) + " sent_msgs=" + strconv.Itoa(ws.sentMsgs)
+		}
+		l.log(ctx)
+		return err
+	}
+}
+
+// UnaryClientLoggingInterceptor 记录每次调用的方法、目标地址、耗时、gRPC 状态码与 errorx 错误码，
+// 并将 ctx 中的请求 ID 写入 metadata 传给下游。成功的调用记录 Debug，失败的调用记录 Warn
+func UnaryClientLoggingInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		requestID := RequestIDFromContext(ctx)
+		if requestID != "" {
+			ctx = metadata.AppendToOutgoingContext(ctx, RequestIDKey, requestID)
+		}
+
+		start := time.Now()
+		err := invoker(ctx, method, req, reply, cc, opts...)
+
+		target := "unknown"
+		if cc != nil {
+			target = cc.Target()
+		}
+		var errCode int32
+		var se errorx.StatusError
+		if errors.As(err, &se) {
+			errCode = se.Code()
+		}
+		const format = "[gRPC] call method=%s target=%s latency=%v code=%s errno=%d request_id=%s"
+		args := []interface{}{method, target, time.Since(start), status.Code(err), errCode, requestID}
+		if err != nil {
+			hlog.CtxWarnf(ctx, format+" err=%v", append(args, err)...)
+		} else {
+			hlog.CtxDebugf(ctx, format, args...)
+		}
+		return err
+	}
+}
+
+// withIncomingRequestID 从 metadata 读取或生成请求 ID 并写入 ctx
+func withIncomingRequestID(ctx context.Context) (context.Context, string) {
+	if id := RequestIDFromContext(ctx); id != "" {
+		return ctx, id
+	}
+	var requestID string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(RequestIDKey); len(values) > 0 {
+			requestID = values[0]
+		}
+	}
+	if requestID == "" {
+		requestID = NewRequestID()
+	}
+	return ContextWithRequestID(ctx, requestID), requestID
+}
+
+// callLog 一次调用的日志内容
+type callLog struct {
+	method  string
+	size    int
+	latency time.Duration
+	err     error
+	payload string
+}
+
+// log 成功的调用记录 Info，客户端错误记录 Warn，服务端错误记录 Error
+func (l callLog) log(ctx context.Context) {
+	var errCode int32
+	var se errorx.StatusError
+	if errors.As(l.err, &se) {
+		errCode = se.Code()
+	}
+	code := status.Code(l.err)
+
+	addr := "unknown"
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		addr = p.Addr.String()
+	}
+	traceID := ""
+	if sc := trace.SpanContextFromContext(ctx); sc.HasTraceID() {
+		traceID = sc.TraceID().String()
+	}
+
+	const format = "[gRPC] method=%s peer=%s req_size=%d latency=%v code=%s errno=%d request_id=%s trace_id=%s%s"
+	args := []interface{}{l.method, addr, l.size, l.latency, code, errCode, RequestIDFromContext(ctx), traceID, l.payload}
+	switch {
+	case code == codes.OK:
+		hlog.CtxInfof(ctx, format, args...)
+	case isServerError(code, errCode):
+		hlog.CtxErrorf(ctx, format+" err=%v", append(args, l.err)...)
+	default:
+		hlog.CtxWarnf(ctx, format+" err=%v", append(args, l.err)...)
+	}
+}
+
+// isServerError 判断是否为服务端错误，errorx 错误按错误码对应的 HTTP 状态码判断
+func isServerError(code codes.Code, errCode int32) bool {
+	if errCode != 0 {
+		return errno.HTTPStatus(errCode) >= http.StatusInternalServerError
+	}
+	switch code {
+	case codes.Unknown, codes.Internal, codes.DataLoss, codes.Unavailable, codes.Unimplemented:
+		return true
+	default:
+		return false
+	}
+}
+
+// loggingStream 记录接收与发送的消息，并提供携带请求 ID 的 ctx
+type loggingStream struct {
+	grpc.ServerStream
+	ctx      context.Context
+	recvSize int
+	recvMsgs int
+	sentMsgs int
+}
+
+func (s *loggingStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *loggingStream) RecvMsg(m interface{}) error {
+	err := s.ServerStream.RecvMsg(m)
+	if err == nil {
+		s.recvMsgs++
+		s.recvSize += messageSize(m)
+	}
+	return err
+}
+
+func (s *loggingStream) SendMsg(m interface{}) error {
+	err := s.ServerStream.SendMsg(m)
+	if err == nil {
+		s.sentMsgs++
+	}
+	return err
+}
+
+// messageSize 返回 protobuf 消息序列化后的大小，非 protobuf 消息返回 0
+func messageSize(m interface{}) int {
+	if pm, ok := m.(proto.Message); ok {
+		return proto.Size(pm)
+	}
+	return 0
+}
+
+// formatPayload 将消息格式化为单行 JSON，超过 maxLen 时截断
+func formatPayload(m interface{}, maxLen int) string {
+	pm, ok := m.(proto.Message)
+	if !ok || pm == nil {
+		return "null"
+	}
+	b, err := protojson.MarshalOptions{}.Marshal(pm)
+	if err != nil {
+		return "<" + err.Error() + ">"
+	}
+	if maxLen > 0 && len(b) > maxLen {
+		return string(b[:maxLen]) + "...(truncated)"
+	}
+	return string(b)
+}
@@ -0,0 +1,33 @@
+package middleware
+
+import (
+	"context"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestUnaryServerLoggingInterceptor(t *testing.T) {
+	interceptor := UnaryServerLoggingInterceptor(WithLogPayload(64))
+	info := &grpc.UnaryServerInfo{FullMethod: "/user.User/GetUser"}
+
+	t.Run("透传请求 ID", func(t *testing.T) {
+		ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(RequestIDKey, "req-1"))
+		_, _ = interceptor(ctx, nil, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+			if got := RequestIDFromContext(ctx); got != "req-1" {
+				t.Errorf("request id = %q, want req-1", got)
+			}
+			return nil, nil
+		})
+	})
+
+	t.Run("生成请求 ID", func(t *testing.T) {
+		_, _ = interceptor(context.Background(), nil, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+			if got := RequestIDFromContext(ctx); len(got) != 32 {
+				t.Errorf("request id = %q, want generated id", got)
+			}
+			return nil, nil
+		})
+	})
+}
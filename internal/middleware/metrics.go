@@ -0,0 +1,105 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/common/hlog"
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+// DefaultRPCDurationBuckets 调用耗时直方图的默认分桶（秒）
+var DefaultRPCDurationBuckets = []float64{.001, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// MetricsOption 指标拦截器选项
+type MetricsOption func(o *metricsOption)
+
+type metricsOption struct {
+	registerer prometheus.Registerer
+	buckets    []float64
+}
+
+// WithMetricsRegisterer 设置指标注册器，默认 prometheus.DefaultRegisterer
+func WithMetricsRegisterer(reg prometheus.Registerer) MetricsOption {
+	return func(o *metricsOption) {
+		if reg != nil {
+			o.registerer = reg
+		}
+	}
+}
+
+// WithMetricsBuckets 设置调用耗时直方图的分桶
+func WithMetricsBuckets(buckets []float64) MetricsOption {
+	return func(o *metricsOption) {
+		if len(buckets) > 0 {
+			o.buckets = buckets
+		}
+	}
+}
+
+// UnaryClientMetricsInterceptor 记录客户端调用次数与耗时
+// 导出的指标：
+//   - grpc_client_handled_total{grpc_service, grpc_method, grpc_code}: 按状态码统计的调用次数
+//   - grpc_client_handling_seconds{grpc_service, grpc_method}: 调用耗时，包含重试
+func UnaryClientMetricsInterceptor(opts ...MetricsOption) grpc.UnaryClientInterceptor {
+	o := &metricsOption{
+		registerer: prometheus.DefaultRegisterer,
+		buckets:    DefaultRPCDurationBuckets,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	handled := registerCollector(o.registerer, prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "grpc",
+		Subsystem: "client",
+		Name:      "handled_total",
+		Help:      "Total number of RPCs completed by the client.",
+	}, []string{"grpc_service", "grpc_method", "grpc_code"}))
+	duration := registerCollector(o.registerer, prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "grpc",
+		Subsystem: "client",
+		Name:      "handling_seconds",
+		Help:      "Duration of RPCs completed by the client in seconds.",
+		Buckets:   o.buckets,
+	}, []string{"grpc_service", "grpc_method"}))
+
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		start := time.Now()
+		err := invoker(ctx, method, req, reply, cc, opts...)
+
+		service, name := splitMethod(method)
+		duration.WithLabelValues(service, name).Observe(time.Since(start).Seconds())
+		handled.WithLabelValues(service, name, status.Code(err).String()).Inc()
+		return err
+	}
+}
+
+// splitMethod 将 /package.Service/Method 拆分为服务名与方法名
+func splitMethod(fullMethod string) (service, method string) {
+	name := strings.TrimPrefix(fullMethod, "/")
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		return name[:i], name[i+1:]
+	}
+	return "unknown", name
+}
+
+// registerCollector 注册指标，已注册时复用已有的指标，使多个连接共享同一组指标
+func registerCollector[C prometheus.Collector](reg prometheus.Registerer, c C) C {
+	err := reg.Register(c)
+	if err == nil {
+		return c
+	}
+	var are prometheus.AlreadyRegisteredError
+	if errors.As(err, &are) {
+		if existing, ok := are.ExistingCollector.(C); ok {
+			return existing
+		}
+	}
+	hlog.CtxWarnf(context.Background(), "[Metrics] register metrics failed: %v", err)
+	return c
+}
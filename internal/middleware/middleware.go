@@ -1,17 +1,28 @@
+// Package middleware 提供 gRPC 服务端与客户端拦截器
+//
+// 服务端：
+//
+//	grpc.NewServer(
+//		grpc.ChainUnaryInterceptor(
+//			middleware.UnaryServerTracingInterceptor(),
+//			middleware.UnaryServerRecoveryInterceptor(),
+//			middleware.UnaryServerLoggingInterceptor(),
+//			middleware.UnaryServerTimeoutInterceptor(5*time.Second),
+//			middleware.UnaryServerAuthInterceptor(middleware.WithPublicMethods("/user.User/Login")),
+//			middleware.UnaryServerErrorxInterceptor(),
+//			middleware.UnaryServerValidationInterceptor(nil),
+//		),
+//		grpc.ChainStreamInterceptor(
+//			middleware.StreamServerTracingInterceptor(),
+//			middleware.StreamServerRecoveryInterceptor(),
+//			middleware.StreamServerLoggingInterceptor(),
+//			middleware.StreamServerTimeoutInterceptor(time.Minute),
+//			middleware.StreamServerAuthInterceptor(),
+//			middleware.StreamServerErrorxInterceptor(),
+//		),
+//	)
+//
+// 链路追踪拦截器使用 trace.Init 设置的全局 TracerProvider 与传播器，应放在最前面，使其余拦截器的日志带上 trace ID。
+// 客户端使用 UnaryClientInterceptors 与 StreamClientInterceptors 返回的默认拦截器链，需要限制调用时长时在其前面加上 UnaryClientTimeoutInterceptor；
+// 服务间调用优先使用 rpcclient.Dial，它挂载这些拦截器并负责 keepalive、负载均衡与幂等方法重试
 package middleware
-
-import (
-	"context"
-
-	"google.golang.org/grpc"
-)
-
-// TODO: Add gRPC interceptor/middleware implementations
-
-// UnaryServerInterceptor example
-func UnaryServerInterceptor() grpc.UnaryServerInterceptor {
-	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
-		// TODO: Implement middleware logic
-		return handler(ctx, req)
-	}
-}
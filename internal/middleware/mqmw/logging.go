@@ -0,0 +1,46 @@
+package mqmw
+
+import (
+	"context"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/common/hlog"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/ZampoRen/go-server-comon/internal/infra/mq"
+	"github.com/ZampoRen/go-server-comon/internal/middleware"
+	logger "github.com/ZampoRen/go-server-comon/pkg/logs"
+)
+
+// Logging 记录每条消息的主题、消费组、消息 ID、Key、投递次数、耗时、errorx 错误码以及请求 ID 与 trace ID。
+// 请求 ID 从消息头 x-request-id 读取，没有时生成并写入 ctx。成功记录 Info，客户端错误记录 Warn，服务端错误记录 Error
+func Logging() mq.Interceptor {
+	return func(ctx context.Context, msg *mq.Message, info *mq.HandlerInfo, handler mq.Handler) error {
+		requestID := logger.RequestIDFromContext(ctx)
+		if requestID == "" {
+			if requestID = msg.Header(RequestIDHeader); requestID == "" {
+				requestID = middleware.NewRequestID()
+			}
+			ctx = logger.ContextWithRequestID(ctx, requestID)
+		}
+
+		start := time.Now()
+		err := handler(ctx, msg)
+
+		traceID := ""
+		if sc := trace.SpanContextFromContext(ctx); sc.HasTraceID() {
+			traceID = sc.TraceID().String()
+		}
+		const format = "[MQ] topic=%s group=%s msg_id=%s key=%s attempt=%d latency=%v errno=%d request_id=%s trace_id=%s"
+		args := []interface{}{info.Topic, info.Group, msg.ID, msg.Key, msg.Attempt, time.Since(start), errorCode(err), requestID, traceID}
+		switch {
+		case err == nil:
+			hlog.CtxInfof(ctx, format, args...)
+		case isServerError(err):
+			hlog.CtxErrorf(ctx, format+" err=%v", append(args, err)...)
+		default:
+			hlog.CtxWarnf(ctx, format+" err=%v", append(args, err)...)
+		}
+		return err
+	}
+}
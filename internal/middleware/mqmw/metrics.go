@@ -0,0 +1,113 @@
+package mqmw
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/common/hlog"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/ZampoRen/go-server-comon/internal/infra/mq"
+)
+
+// DefaultHandleDurationBuckets 消息处理耗时直方图的默认分桶（秒）
+var DefaultHandleDurationBuckets = []float64{.001, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10, 30}
+
+// MetricsOption 指标拦截器选项
+type MetricsOption func(o *metricsOption)
+
+type metricsOption struct {
+	registerer prometheus.Registerer
+	buckets    []float64
+}
+
+// WithMetricsRegisterer 设置指标注册器，默认 prometheus.DefaultRegisterer
+func WithMetricsRegisterer(reg prometheus.Registerer) MetricsOption {
+	return func(o *metricsOption) {
+		if reg != nil {
+			o.registerer = reg
+		}
+	}
+}
+
+// WithMetricsBuckets 设置处理耗时直方图的分桶
+func WithMetricsBuckets(buckets []float64) MetricsOption {
+	return func(o *metricsOption) {
+		if len(buckets) > 0 {
+			o.buckets = buckets
+		}
+	}
+}
+
+// Metrics 记录消息处理次数、耗时与投递延迟
+// 导出的指标：
+//   - mq_consumer_handled_total{topic, group, result}: 按结果统计的处理次数，result 为 ok、client_error 或 server_error
+//   - mq_consumer_handling_seconds{topic, group}: 处理耗时
+//   - mq_consumer_lag_seconds{topic, group}: 从发送到开始处理的时间，包括重试的等待
+func Metrics(opts ...MetricsOption) mq.Interceptor {
+	o := &metricsOption{
+		registerer: prometheus.DefaultRegisterer,
+		buckets:    DefaultHandleDurationBuckets,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	handled := register(o.registerer, prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "mq",
+		Subsystem: "consumer",
+		Name:      "handled_total",
+		Help:      "Total number of messages handled by the consumer.",
+	}, []string{"topic", "group", "result"}))
+	duration := register(o.registerer, prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "mq",
+		Subsystem: "consumer",
+		Name:      "handling_seconds",
+		Help:      "Duration of message handling in seconds.",
+		Buckets:   o.buckets,
+	}, []string{"topic", "group"}))
+	lag := register(o.registerer, prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "mq",
+		Subsystem: "consumer",
+		Name:      "lag_seconds",
+		Help:      "Time from message publication to the start of handling in seconds.",
+		Buckets:   prometheus.ExponentialBuckets(.01, 4, 10),
+	}, []string{"topic", "group"}))
+
+	return func(ctx context.Context, msg *mq.Message, info *mq.HandlerInfo, handler mq.Handler) error {
+		start := time.Now()
+		if !msg.PublishedAt.IsZero() {
+			lag.WithLabelValues(info.Topic, info.Group).Observe(start.Sub(msg.PublishedAt).Seconds())
+		}
+		err := handler(ctx, msg)
+
+		duration.WithLabelValues(info.Topic, info.Group).Observe(time.Since(start).Seconds())
+		result := "ok"
+		switch {
+		case err == nil:
+		case isServerError(err):
+			result = "server_error"
+		default:
+			result = "client_error"
+		}
+		handled.WithLabelValues(info.Topic, info.Group, result).Inc()
+		return err
+	}
+}
+
+// register 注册指标，已注册时复用已有的指标，使多个消费者共享同一组指标
+func register[C prometheus.Collector](reg prometheus.Registerer, c C) C {
+	err := reg.Register(c)
+	if err == nil {
+		return c
+	}
+	var are prometheus.AlreadyRegisteredError
+	if errors.As(err, &are) {
+		if existing, ok := are.ExistingCollector.(C); ok {
+			return existing
+		}
+	}
+	hlog.CtxWarnf(context.Background(), "[MQ] register metrics failed: %v", err)
+	return c
+}
@@ -0,0 +1,41 @@
+// Package mqmw 提供消息队列消费拦截器，与 gRPC 拦截器对应，使消息处理与 RPC 处理有相同的日志、链路追踪与指标
+//
+//	consumer, _ := impl.NewConsumer(ctx, "search-indexer", mq.WithInterceptors(
+//		mqmw.Tracing(),
+//		mqmw.Retry(mqmw.WithDeadLetter(producer)),
+//		mqmw.Logging(),
+//		mqmw.Metrics(),
+//		mqmw.Recovery(),
+//	))
+//
+// Tracing 应放在最前面，使其余拦截器的日志带上 trace ID；Recovery 放在最后，将 handler 的 panic 转换为错误，
+// 使日志、指标与重试决策都能看到。发送方使用 WrapProducer 包裹生产者，将 trace 上下文与请求 ID 写入消息头
+package mqmw
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/ZampoRen/go-server-comon/internal/errno"
+	"github.com/ZampoRen/go-server-comon/pkg/errorx"
+)
+
+// RequestIDHeader 请求 ID 的消息头，与 gRPC metadata 的 x-request-id 对应
+const RequestIDHeader = "x-request-id"
+
+// errorCode 返回 errorx 错误码，非 errorx 错误返回 0
+func errorCode(err error) int32 {
+	var se errorx.StatusError
+	if errors.As(err, &se) {
+		return se.Code()
+	}
+	return 0
+}
+
+// isServerError 判断是否为服务端错误，errorx 错误按错误码对应的 HTTP 状态码判断，其他错误都视为服务端错误
+func isServerError(err error) bool {
+	if code := errorCode(err); code != 0 {
+		return errno.HTTPStatus(code) >= http.StatusInternalServerError
+	}
+	return err != nil
+}
@@ -0,0 +1,29 @@
+package mqmw
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+
+	"github.com/ZampoRen/go-server-comon/internal/errno"
+	"github.com/ZampoRen/go-server-comon/internal/infra/mq"
+	"github.com/ZampoRen/go-server-comon/pkg/errorx"
+	logger "github.com/ZampoRen/go-server-comon/pkg/logs"
+)
+
+// Recovery 捕获 handler 中的 panic，通过 logger.ErrorX 记录堆栈后返回 errno.ErrInternal，消息按失败处理，
+// 避免单条消息的 panic 导致进程退出。错误带有主题、消息 ID 与事故 ID
+func Recovery() mq.Interceptor {
+	return func(ctx context.Context, msg *mq.Message, info *mq.HandlerInfo, handler mq.Handler) (err error) {
+		defer func() {
+			r := recover()
+			if r == nil {
+				return
+			}
+			err = errorx.New(errno.ErrInternal, errorx.Extra("topic", info.Topic), errorx.Extra("msg_id", msg.ID))
+			incidentID := logger.Default().ErrorX(ctx, fmt.Sprintf("[Recovery] panic in message %s of %s: %v\n%s", msg.ID, info.Topic, r, debug.Stack()), err)
+			errorx.SetExtra(err, "incident_id", incidentID)
+		}()
+		return handler(ctx, msg)
+	}
+}
@@ -0,0 +1,124 @@
+package mqmw
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/cloudwego/hertz/pkg/common/hlog"
+
+	"github.com/ZampoRen/go-server-comon/internal/errno"
+	"github.com/ZampoRen/go-server-comon/internal/infra/mq"
+	"github.com/ZampoRen/go-server-comon/pkg/errorx"
+)
+
+// 死信消息中记录来源的消息头
+const (
+	DeadLetterTopicHeader   = "x-dlq-topic"
+	DeadLetterGroupHeader   = "x-dlq-group"
+	DeadLetterAttemptHeader = "x-dlq-attempt"
+	DeadLetterErrorHeader   = "x-dlq-error"
+	DeadLetterErrnoHeader   = "x-dlq-errno"
+)
+
+// RetryOption 重试拦截器选项
+type RetryOption func(o *retryOption)
+
+type retryOption struct {
+	deadLetter mq.Producer
+	topic      func(info *mq.HandlerInfo) string
+	retryable  func(err error) bool
+}
+
+// WithDeadLetter 设置死信队列的生产者，不可重试或重试次数耗尽的消息发送到死信主题，未设置时直接丢弃
+func WithDeadLetter(p mq.Producer) RetryOption {
+	return func(o *retryOption) {
+		o.deadLetter = p
+	}
+}
+
+// WithDeadLetterTopic 设置死信主题，默认为 "主题.消费组.DLQ"，使不同消费组的死信互不干扰
+func WithDeadLetterTopic(fn func(info *mq.HandlerInfo) string) RetryOption {
+	return func(o *retryOption) {
+		if fn != nil {
+			o.topic = fn
+		}
+	}
+}
+
+// WithRetryable 设置判断错误是否可重试的函数，默认为 Retryable
+func WithRetryable(fn func(err error) bool) RetryOption {
+	return func(o *retryOption) {
+		if fn != nil {
+			o.retryable = fn
+		}
+	}
+}
+
+// Retryable 默认的重试判断：非 errorx 错误、服务端错误、限流与乐观锁冲突可以重试，
+// 其他客户端错误（参数错误、资源不存在等）重试也不会成功
+func Retryable(err error) bool {
+	code := errorCode(err)
+	if code == 0 {
+		return true
+	}
+	status := errno.HTTPStatus(code)
+	return status >= http.StatusInternalServerError || status == http.StatusTooManyRequests || code == errno.ErrVersionConflict
+}
+
+// Retry 按 errorx 错误码决定失败的消息是否重试：可重试且未到最后一次投递时返回错误，由消息队列稍后重新投递；
+// 不可重试或最后一次投递失败时将消息发送到死信主题并返回 nil 确认消息，死信发送失败时返回原错误
+func Retry(opts ...RetryOption) mq.Interceptor {
+	o := &retryOption{
+		topic: func(info *mq.HandlerInfo) string {
+			return info.Topic + "." + info.Group + ".DLQ"
+		},
+		retryable: Retryable,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	return func(ctx context.Context, msg *mq.Message, info *mq.HandlerInfo, handler mq.Handler) error {
+		err := handler(ctx, msg)
+		if err == nil {
+			return nil
+		}
+		retryable := o.retryable(err)
+		if retryable && msg.Attempt <= info.MaxRetries {
+			return err
+		}
+
+		reason := "retries exhausted"
+		if !retryable {
+			reason = "not retryable"
+		}
+		if o.deadLetter == nil {
+			hlog.CtxErrorf(ctx, "[MQ] drop message %s of %s (%s), attempt %d: %v", msg.ID, info.Topic, reason, msg.Attempt, err)
+			return nil
+		}
+		topic := o.topic(info)
+		if dlqErr := o.deadLetter.Send(context.WithoutCancel(ctx), deadLetter(topic, msg, info, err)); dlqErr != nil {
+			hlog.CtxErrorf(ctx, "[MQ] send message %s of %s to %s failed: %v", msg.ID, info.Topic, topic, dlqErr)
+			return err
+		}
+		hlog.CtxWarnf(ctx, "[MQ] move message %s of %s to %s (%s), attempt %d: %v", msg.ID, info.Topic, topic, reason, msg.Attempt, err)
+		return nil
+	}
+}
+
+// deadLetter 返回发送到死信主题的消息副本，消息头中记录来源与失败原因
+func deadLetter(topic string, msg *mq.Message, info *mq.HandlerInfo, err error) *mq.Message {
+	dl := &mq.Message{Topic: topic, Key: msg.Key, Tag: msg.Tag, Body: msg.Body}
+	for k, v := range msg.Headers {
+		dl.SetHeader(k, v)
+	}
+	dl.SetHeader(DeadLetterTopicHeader, info.Topic)
+	dl.SetHeader(DeadLetterGroupHeader, info.Group)
+	dl.SetHeader(DeadLetterAttemptHeader, strconv.Itoa(msg.Attempt))
+	dl.SetHeader(DeadLetterErrorHeader, errorx.ErrorWithoutStack(err))
+	if code := errorCode(err); code != 0 {
+		dl.SetHeader(DeadLetterErrnoHeader, strconv.FormatInt(int64(code), 10))
+	}
+	return dl
+}
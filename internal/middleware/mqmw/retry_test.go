@@ -0,0 +1,130 @@
+package mqmw
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ZampoRen/go-server-comon/internal/errno"
+	"github.com/ZampoRen/go-server-comon/internal/infra/mq"
+	"github.com/ZampoRen/go-server-comon/internal/infra/mq/impl/memory"
+	"github.com/ZampoRen/go-server-comon/pkg/errorx"
+)
+
+// dlqRecorder 记录发送到死信主题的消息
+type dlqRecorder struct {
+	mq.Producer
+	msgs []*mq.Message
+	err  error
+}
+
+func (r *dlqRecorder) Send(_ context.Context, msgs ...*mq.Message) error {
+	if r.err != nil {
+		return r.err
+	}
+	r.msgs = append(r.msgs, msgs...)
+	return nil
+}
+
+func TestRetry(t *testing.T) {
+	info := &mq.HandlerInfo{Group: "indexer", Topic: "users", MaxRetries: 2}
+	temporary := errors.New("temporary")
+	invalid := errorx.New(errno.ErrInvalidParam)
+
+	tests := []struct {
+		name    string
+		attempt int
+		err     error
+		sendErr error
+		wantErr error
+		wantDLQ bool
+	}{
+		{name: "成功", attempt: 1},
+		{name: "可重试", attempt: 1, err: temporary, wantErr: temporary},
+		{name: "重试次数耗尽", attempt: 3, err: temporary, wantDLQ: true},
+		{name: "不可重试", attempt: 1, err: invalid, wantDLQ: true},
+		{name: "死信发送失败", attempt: 1, err: invalid, sendErr: errors.New("broker down"), wantErr: invalid},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dlq := &dlqRecorder{err: tt.sendErr}
+			msg := &mq.Message{ID: "1", Key: "42", Body: []byte("x"), Attempt: tt.attempt}
+			err := Retry(WithDeadLetter(dlq))(context.Background(), msg, info, func(context.Context, *mq.Message) error {
+				return tt.err
+			})
+			if !errors.Is(err, tt.wantErr) || (tt.wantErr == nil && err != nil) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if got := len(dlq.msgs) == 1; got != tt.wantDLQ {
+				t.Fatalf("dead lettered = %v, want %v", got, tt.wantDLQ)
+			}
+			if tt.wantDLQ {
+				dl := dlq.msgs[0]
+				if dl.Topic != "users.indexer.DLQ" || dl.Key != "42" || dl.Header(DeadLetterTopicHeader) != "users" || dl.Header(DeadLetterErrorHeader) == "" {
+					t.Fatalf("dead letter = %+v", dl)
+				}
+			}
+		})
+	}
+}
+
+// TestInterceptorsWithConsumer 测试拦截器在消费者上的顺序，以及 panic 经 Recovery 转为错误后进入死信主题
+func TestInterceptorsWithConsumer(t *testing.T) {
+	ctx := context.Background()
+	b := memory.New(memory.WithRetryDelay(time.Millisecond))
+
+	dead := make(chan *mq.Message, 1)
+	dlqConsumer := b.NewConsumer("ops")
+	_ = dlqConsumer.Subscribe("users.indexer.DLQ", func(_ context.Context, m *mq.Message) error {
+		dead <- m
+		return nil
+	})
+
+	var mu sync.Mutex
+	var order []string
+	record := func(name string) mq.Interceptor {
+		return func(ctx context.Context, msg *mq.Message, info *mq.HandlerInfo, handler mq.Handler) error {
+			mu.Lock()
+			order = append(order, name)
+			mu.Unlock()
+			return handler(ctx, msg)
+		}
+	}
+	c := b.NewConsumer("indexer", mq.WithMaxRetries(1), mq.WithInterceptors(
+		record("a"),
+		Retry(WithDeadLetter(b.Producer())),
+		record("b"),
+		Recovery(),
+	))
+	_ = c.Subscribe("users", func(context.Context, *mq.Message) error {
+		panic("boom")
+	})
+	for _, c := range []mq.Consumer{dlqConsumer, c} {
+		if err := c.Start(ctx); err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { _ = c.Close(ctx) })
+	}
+
+	if err := b.Producer().Send(ctx, &mq.Message{Topic: "users", Key: "42"}); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case m := <-dead:
+		if m.Key != "42" || m.Header(DeadLetterAttemptHeader) != "2" || m.Header(DeadLetterErrnoHeader) != strconv.Itoa(int(errno.ErrInternal)) {
+			t.Fatalf("dead letter = %+v", m)
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("message was not dead lettered")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if want := []string{"a", "b", "a", "b"}; !slices.Equal(order, want) {
+		t.Fatalf("order = %v, want %v", order, want)
+	}
+}
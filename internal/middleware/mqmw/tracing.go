@@ -0,0 +1,159 @@
+package mqmw
+
+import (
+	"context"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/ZampoRen/go-server-comon/internal/infra/mq"
+	logger "github.com/ZampoRen/go-server-comon/pkg/logs"
+)
+
+const tracerName = "github.com/ZampoRen/go-server-comon/internal/middleware/mqmw"
+
+// TracingOption 链路追踪拦截器选项
+type TracingOption func(o *tracingOption)
+
+type tracingOption struct {
+	tracerProvider trace.TracerProvider
+	propagator     propagation.TextMapPropagator
+}
+
+// WithTracerProvider 设置 TracerProvider，默认使用全局 TracerProvider
+func WithTracerProvider(tp trace.TracerProvider) TracingOption {
+	return func(o *tracingOption) {
+		if tp != nil {
+			o.tracerProvider = tp
+		}
+	}
+}
+
+// WithPropagator 设置跨进程传播器，默认使用全局 TextMapPropagator（trace.Init 设置为 W3C tracecontext）
+func WithPropagator(p propagation.TextMapPropagator) TracingOption {
+	return func(o *tracingOption) {
+		if p != nil {
+			o.propagator = p
+		}
+	}
+}
+
+func newTracingOption(opts ...TracingOption) *tracingOption {
+	o := &tracingOption{
+		tracerProvider: otel.GetTracerProvider(),
+		propagator:     otel.GetTextMapPropagator(),
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// Tracing 从消息头中提取发送方的 trace 上下文并为每条消息创建消费者 span，span 名称为 "主题 process"，
+// handler 中的 Redis、MySQL、gRPC 调用都挂在该 span 下。只有服务端错误才将 span 标记为失败
+func Tracing(opts ...TracingOption) mq.Interceptor {
+	o := newTracingOption(opts...)
+	tracer := o.tracerProvider.Tracer(tracerName)
+	return func(ctx context.Context, msg *mq.Message, info *mq.HandlerInfo, handler mq.Handler) error {
+		ctx = o.propagator.Extract(ctx, propagation.MapCarrier(msg.Headers))
+		attrs := []attribute.KeyValue{
+			attribute.String("messaging.operation.type", "process"),
+			attribute.String("messaging.destination.name", info.Topic),
+			attribute.String("messaging.consumer.group.name", info.Group),
+			attribute.String("messaging.message.id", msg.ID),
+			attribute.Int("messaging.message.attempt", msg.Attempt),
+		}
+		if !msg.PublishedAt.IsZero() {
+			attrs = append(attrs, attribute.Int64("messaging.message.lag_ms", time.Since(msg.PublishedAt).Milliseconds()))
+		}
+		ctx, span := tracer.Start(ctx, info.Topic+" process",
+			trace.WithSpanKind(trace.SpanKindConsumer),
+			trace.WithAttributes(attrs...),
+		)
+		defer span.End()
+
+		err := handler(ctx, msg)
+		if err != nil {
+			if code := errorCode(err); code != 0 {
+				span.SetAttributes(attribute.Int("errorx.code", int(code)))
+			}
+			if isServerError(err) {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+			}
+		}
+		return err
+	}
+}
+
+// WrapProducer 包裹生产者，发送前为每条消息创建生产者 span，并将 trace 上下文与 ctx 中的请求 ID 写入消息头，
+// 使消费方的 Tracing 与 Logging 能够关联到发送方。不修改调用方传入的消息
+func WrapProducer(p mq.Producer, opts ...TracingOption) mq.Producer {
+	o := newTracingOption(opts...)
+	return &tracingProducer{Producer: p, o: o, tracer: o.tracerProvider.Tracer(tracerName)}
+}
+
+type tracingProducer struct {
+	mq.Producer
+	o      *tracingOption
+	tracer trace.Tracer
+}
+
+func (p *tracingProducer) Send(ctx context.Context, msgs ...*mq.Message) error {
+	spans := make([]trace.Span, len(msgs))
+	out := make([]*mq.Message, len(msgs))
+	for i, m := range msgs {
+		var spanCtx context.Context
+		spanCtx, spans[i] = p.start(ctx, m.Topic)
+		out[i] = p.inject(spanCtx, m)
+	}
+	err := p.Producer.Send(ctx, out...)
+	for _, span := range spans {
+		end(span, err)
+	}
+	return err
+}
+
+func (p *tracingProducer) PublishDelay(ctx context.Context, topic string, msg *mq.Message, delay time.Duration) error {
+	spanCtx, span := p.start(ctx, topic)
+	span.SetAttributes(attribute.Int64("messaging.message.delay_ms", delay.Milliseconds()))
+	err := p.Producer.PublishDelay(ctx, topic, p.inject(spanCtx, msg), delay)
+	end(span, err)
+	return err
+}
+
+func (p *tracingProducer) start(ctx context.Context, topic string) (context.Context, trace.Span) {
+	return p.tracer.Start(ctx, topic+" send",
+		trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithAttributes(
+			attribute.String("messaging.operation.type", "send"),
+			attribute.String("messaging.destination.name", topic),
+		),
+	)
+}
+
+// inject 返回写入了 trace 上下文与请求 ID 的消息副本
+func (p *tracingProducer) inject(ctx context.Context, m *mq.Message) *mq.Message {
+	out := *m
+	out.Headers = make(map[string]string, len(m.Headers)+2)
+	for k, v := range m.Headers {
+		out.Headers[k] = v
+	}
+	p.o.propagator.Inject(ctx, propagation.MapCarrier(out.Headers))
+	if requestID := logger.RequestIDFromContext(ctx); requestID != "" && out.Headers[RequestIDHeader] == "" {
+		out.Headers[RequestIDHeader] = requestID
+	}
+	return &out
+}
+
+func end(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
@@ -0,0 +1,76 @@
+package mqmw
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/ZampoRen/go-server-comon/internal/infra/mq"
+	"github.com/ZampoRen/go-server-comon/internal/infra/mq/impl/memory"
+	logger "github.com/ZampoRen/go-server-comon/pkg/logs"
+)
+
+// TestTracingPropagation 测试生产者写入消息头的 trace 上下文与请求 ID 能被消费方还原
+func TestTracingPropagation(t *testing.T) {
+	ctx := context.Background()
+	sr := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(sr))
+	opts := []TracingOption{WithTracerProvider(tp), WithPropagator(propagation.TraceContext{})}
+
+	b := memory.New()
+	type seen struct {
+		sc        trace.SpanContext
+		requestID string
+	}
+	got := make(chan seen, 1)
+	c := b.NewConsumer("indexer", mq.WithInterceptors(Tracing(opts...), Logging()))
+	_ = c.Subscribe("users", func(ctx context.Context, m *mq.Message) error {
+		got <- seen{sc: trace.SpanContextFromContext(ctx), requestID: logger.RequestIDFromContext(ctx)}
+		return nil
+	})
+	if err := c.Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = c.Close(ctx) })
+
+	p := WrapProducer(b.Producer(), opts...)
+	msg := &mq.Message{Topic: "users", Key: "42"}
+	if err := p.Send(logger.ContextWithRequestID(ctx, "req-1"), msg); err != nil {
+		t.Fatal(err)
+	}
+	if msg.Headers != nil {
+		t.Fatalf("caller message modified: %v", msg.Headers)
+	}
+
+	var s seen
+	select {
+	case s = <-got:
+	case <-time.After(3 * time.Second):
+		t.Fatal("message not received")
+	}
+	if s.requestID != "req-1" {
+		t.Errorf("request ID = %q, want req-1", s.requestID)
+	}
+
+	// 消费者 span 在 handler 返回后结束
+	deadline := time.Now().Add(time.Second)
+	for len(sr.Ended()) < 2 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	spans := sr.Ended()
+	if len(spans) != 2 {
+		t.Fatalf("ended spans = %d, want 2", len(spans))
+	}
+	producer := spans[0]
+	if producer.SpanKind() != trace.SpanKindProducer || producer.Name() != "users send" {
+		t.Fatalf("producer span = %s %v", producer.Name(), producer.SpanKind())
+	}
+	if s.sc.TraceID() != producer.SpanContext().TraceID() || spans[1].Parent().SpanID() != producer.SpanContext().SpanID() {
+		t.Errorf("consumer span is not a child of producer span")
+	}
+}
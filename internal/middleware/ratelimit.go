@@ -0,0 +1,158 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net"
+	"strconv"
+
+	"github.com/cloudwego/hertz/pkg/common/hlog"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"github.com/ZampoRen/go-server-comon/internal/errno"
+	"github.com/ZampoRen/go-server-comon/internal/infra/ratelimit"
+	"github.com/ZampoRen/go-server-comon/pkg/errorx"
+)
+
+// RetryAfterKey 被限流时返回建议重试等待秒数的 trailer 键
+const RetryAfterKey = "retry-after"
+
+// RateLimitOption 限流选项
+type RateLimitOption func(o *rateLimitOption)
+
+type rateLimitOption struct {
+	methodLimits map[string]ratelimit.Limit
+	clientLimit  ratelimit.Limit
+	clientLimits map[string]ratelimit.Limit
+	clientKey    func(ctx context.Context) string
+}
+
+// WithMethodLimit 限制方法的总请求速率，所有调用方共享配额，方法为完整名称，如 /user.User/CreateUser
+func WithMethodLimit(method string, limit ratelimit.Limit) RateLimitOption {
+	return func(o *rateLimitOption) {
+		o.methodLimits[method] = limit
+	}
+}
+
+// WithClientLimit 限制每个调用方对每个方法的请求速率，未通过 WithClientMethodLimit 单独设置的方法使用该限制
+func WithClientLimit(limit ratelimit.Limit) RateLimitOption {
+	return func(o *rateLimitOption) {
+		o.clientLimit = limit
+	}
+}
+
+// WithClientMethodLimit 单独设置每个调用方对 method 的请求速率
+func WithClientMethodLimit(method string, limit ratelimit.Limit) RateLimitOption {
+	return func(o *rateLimitOption) {
+		o.clientLimits[method] = limit
+	}
+}
+
+// WithClientKey 设置识别调用方的函数，默认使用认证拦截器写入的 JWT subject，未认证时使用对端 IP
+func WithClientKey(fn func(ctx context.Context) string) RateLimitOption {
+	return func(o *rateLimitOption) {
+		o.clientKey = fn
+	}
+}
+
+// UnaryServerRateLimitInterceptor 按方法与调用方限流，limiter 可以是 ratelimit.NewLocal 或 ratelimit.NewRedis
+// 超出限制时返回 ResourceExhausted，errno.ErrTooManyRequests 与建议等待的秒数写入 trailer；
+// 限流器出错时放行并打印告警，避免 Redis 故障导致服务不可用。应放在认证拦截器之后，以便按用户限流
+func UnaryServerRateLimitInterceptor(limiter ratelimit.Limiter, opts ...RateLimitOption) grpc.UnaryServerInterceptor {
+	o := newRateLimitOption(opts...)
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if trailer, err := o.allow(ctx, limiter, info.FullMethod); err != nil {
+			_ = grpc.SetTrailer(ctx, trailer)
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerRateLimitInterceptor 同 UnaryServerRateLimitInterceptor，用于流式调用，每个流计一次请求
+func StreamServerRateLimitInterceptor(limiter ratelimit.Limiter, opts ...RateLimitOption) grpc.StreamServerInterceptor {
+	o := newRateLimitOption(opts...)
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if trailer, err := o.allow(ss.Context(), limiter, info.FullMethod); err != nil {
+			ss.SetTrailer(trailer)
+			return err
+		}
+		return handler(srv, ss)
+	}
+}
+
+func newRateLimitOption(opts ...RateLimitOption) *rateLimitOption {
+	o := &rateLimitOption{
+		methodLimits: make(map[string]ratelimit.Limit),
+		clientLimits: make(map[string]ratelimit.Limit),
+		clientKey:    defaultClientKey,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// limitCheck 一项限流检查
+type limitCheck struct {
+	key   string
+	limit ratelimit.Limit
+}
+
+// allow 依次检查方法与调用方的限制，被拒绝时返回 trailer 与 gRPC 错误
+func (o *rateLimitOption) allow(ctx context.Context, limiter ratelimit.Limiter, method string) (metadata.MD, error) {
+	checks := make([]limitCheck, 0, 2)
+	if limit, ok := o.methodLimits[method]; ok {
+		checks = append(checks, limitCheck{key: "method:" + method, limit: limit})
+	}
+	clientLimit, ok := o.clientLimits[method]
+	if !ok {
+		clientLimit = o.clientLimit
+	}
+	if !clientLimit.IsZero() {
+		if client := o.clientKey(ctx); client != "" {
+			checks = append(checks, limitCheck{key: "client:" + client + ":" + method, limit: clientLimit})
+		}
+	}
+
+	for _, c := range checks {
+		res, err := limiter.Allow(ctx, c.key, c.limit)
+		if err != nil {
+			hlog.CtxWarnf(ctx, "[RateLimit] check %s failed, allow request: %v", c.key, err)
+			continue
+		}
+		if res.Allowed {
+			continue
+		}
+
+		var se errorx.StatusError
+		_ = errors.As(errorx.New(errno.ErrTooManyRequests), &se)
+		retryAfter := int64(math.Ceil(res.RetryAfter.Seconds()))
+		trailer := metadata.Pairs(
+			ErrorCodeTrailerKey, strconv.FormatInt(int64(se.Code()), 10),
+			RetryAfterKey, strconv.FormatInt(retryAfter, 10),
+		)
+		return trailer, status.Error(codes.ResourceExhausted, se.Msg())
+	}
+	return nil, nil
+}
+
+// defaultClientKey 优先使用 JWT subject，否则使用对端 IP
+func defaultClientKey(ctx context.Context) string {
+	if claims, ok := ClaimsFromContext(ctx); ok && claims.Subject != "" {
+		return "sub:" + claims.Subject
+	}
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		addr := p.Addr.String()
+		if host, _, err := net.SplitHostPort(addr); err == nil {
+			return "ip:" + host
+		}
+		return "ip:" + addr
+	}
+	return ""
+}
@@ -0,0 +1,30 @@
+package middleware
+
+import (
+	"context"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/ZampoRen/go-server-comon/internal/infra/ratelimit"
+)
+
+func TestUnaryServerRateLimitInterceptor(t *testing.T) {
+	interceptor := UnaryServerRateLimitInterceptor(ratelimit.NewLocal(),
+		WithClientLimit(ratelimit.Limit{Rate: 1, Burst: 2}),
+		WithClientKey(func(ctx context.Context) string { return "c1" }),
+	)
+	info := &grpc.UnaryServerInfo{FullMethod: "/user.User/GetUser"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) { return nil, nil }
+
+	for i := 0; i < 2; i++ {
+		if _, err := interceptor(context.Background(), nil, info, handler); err != nil {
+			t.Fatalf("request %d: %v", i, err)
+		}
+	}
+	if _, err := interceptor(context.Background(), nil, info, handler); status.Code(err) != codes.ResourceExhausted {
+		t.Errorf("err = %v, want ResourceExhausted", err)
+	}
+}
@@ -0,0 +1,64 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"strconv"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/ZampoRen/go-server-comon/internal/errno"
+	"github.com/ZampoRen/go-server-comon/pkg/errorx"
+	logger "github.com/ZampoRen/go-server-comon/pkg/logs"
+)
+
+// IncidentIDTrailerKey 服务端返回事故 ID 时使用的 trailer 键，与日志中的 incident_id 对应
+const IncidentIDTrailerKey = "x-incident-id"
+
+// UnaryServerRecoveryInterceptor 捕获 handler 中的 panic，记录堆栈后返回 errno.ErrInternal 对应的 Internal 错误，
+// 错误码与事故 ID 写入 trailer，避免单个请求的 panic 导致进程退出。应放在拦截器链的最外层
+func UnaryServerRecoveryInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				trailer, st := recoverPanic(ctx, info.FullMethod, r)
+				_ = grpc.SetTrailer(ctx, trailer)
+				err = st
+			}
+		}()
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerRecoveryInterceptor 同 UnaryServerRecoveryInterceptor，用于流式调用
+func StreamServerRecoveryInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				trailer, st := recoverPanic(ss.Context(), info.FullMethod, r)
+				ss.SetTrailer(trailer)
+				err = st
+			}
+		}()
+		return handler(srv, ss)
+	}
+}
+
+// recoverPanic 记录 panic 与堆栈，返回携带错误码与事故 ID 的 trailer 以及对应的 gRPC 错误
+func recoverPanic(ctx context.Context, method string, r interface{}) (metadata.MD, error) {
+	err := errorx.New(errno.ErrInternal, errorx.Extra(ErrorMethodExtraKey, method))
+	incidentID := logger.Default().ErrorX(ctx, fmt.Sprintf("[Recovery] panic in %s: %v\n%s", method, r, debug.Stack()), err)
+
+	var se errorx.StatusError
+	_ = errors.As(err, &se)
+	trailer := metadata.Pairs(
+		ErrorCodeTrailerKey, strconv.FormatInt(int64(se.Code()), 10),
+		IncidentIDTrailerKey, incidentID,
+	)
+	return trailer, status.Error(codes.Internal, se.Msg())
+}
@@ -0,0 +1,295 @@
+// Package rpcclient 创建服务间调用的 gRPC 连接，预置 keepalive、round_robin 负载均衡、幂等方法重试与标准客户端拦截器
+//
+//	conn, err := rpcclient.Dial("user-svc:9000",
+//		rpcclient.WithIdempotentMethods("/user.User/GetUser", "/user.User/ListUsers"),
+//		rpcclient.WithTimeout(3*time.Second),
+//	)
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	defer conn.Close()
+//	client := userclient.NewUserClient(conn)
+//
+// clientgen 生成的 DialXxx 以 proto 中声明的幂等方法调用 Dial，如 userclient.DialUser("user-svc:9000")。
+//
+// 拦截器依次为链路追踪、日志、指标、超时（设置 WithTimeout 时）、熔断（设置 WithBreaker 时）与 errorx 解码。
+// 重试由 gRPC 的 service config 完成，只重试 WithIdempotentMethods 声明的方法，且只在 UNAVAILABLE 时重试，
+// 非幂等方法不会被重复执行。服务端的 keepalive.EnforcementPolicy.MinTime 需要不大于 WithKeepalive 的间隔
+//
+// 通过注册中心发现实例时使用 discovery:/// 并设置 WithDiscovery，实例上下线时连接的地址随之更新：
+//
+//	conn, err := rpcclient.Dial("discovery:///user-service", rpcclient.WithDiscovery(backend))
+package rpcclient
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/keepalive"
+
+	"github.com/ZampoRen/go-server-comon/internal/middleware"
+	"github.com/ZampoRen/go-server-comon/pkg/breaker"
+	"github.com/ZampoRen/go-server-comon/pkg/discovery"
+)
+
+// 默认参数
+const (
+	// DefaultKeepaliveTime 连接空闲多久后发送 ping
+	DefaultKeepaliveTime = 30 * time.Second
+	// DefaultKeepaliveTimeout ping 无响应多久后关闭连接
+	DefaultKeepaliveTimeout = 10 * time.Second
+	// DefaultMaxAttempts 幂等方法的最大调用次数（含首次调用）
+	DefaultMaxAttempts = 3
+)
+
+// Option 连接选项
+type Option func(o *option)
+
+type option struct {
+	creds            credentials.TransportCredentials
+	keepalive        keepalive.ClientParameters
+	idempotent       []string
+	maxAttempts      int
+	initialBackoff   time.Duration
+	maxBackoff       time.Duration
+	timeout          time.Duration
+	breaker          *breaker.Group
+	metricsOptions   []middleware.MetricsOption
+	interceptors     []grpc.UnaryClientInterceptor
+	dialOptions      []grpc.DialOption
+	skipInterceptors bool
+}
+
+// WithTransportCredentials 设置传输层凭证，默认明文传输
+func WithTransportCredentials(creds credentials.TransportCredentials) Option {
+	return func(o *option) {
+		if creds != nil {
+			o.creds = creds
+		}
+	}
+}
+
+// WithKeepalive 设置 keepalive 的 ping 间隔与超时，默认 30s 与 10s
+func WithKeepalive(interval, timeout time.Duration) Option {
+	return func(o *option) {
+		if interval > 0 {
+			o.keepalive.Time = interval
+		}
+		if timeout > 0 {
+			o.keepalive.Timeout = timeout
+		}
+	}
+}
+
+// WithIdempotentMethods 声明可安全重试的方法，方法为完整名称，如 /user.User/GetUser，
+// 也可以是 /user.User/ 表示服务下的所有方法
+func WithIdempotentMethods(methods ...string) Option {
+	return func(o *option) {
+		o.idempotent = append(o.idempotent, methods...)
+	}
+}
+
+// WithRetryPolicy 设置幂等方法的最大调用次数（含首次调用）与指数退避的初始、最大间隔，默认 3 次、100ms、1s
+// gRPC 限制 maxAttempts 不超过 5
+func WithRetryPolicy(maxAttempts int, initialBackoff, maxBackoff time.Duration) Option {
+	return func(o *option) {
+		if maxAttempts > 0 {
+			o.maxAttempts = maxAttempts
+		}
+		if initialBackoff > 0 {
+			o.initialBackoff = initialBackoff
+		}
+		if maxBackoff > 0 {
+			o.maxBackoff = maxBackoff
+		}
+	}
+}
+
+// WithTimeout 限制每次调用的最长时间，见 middleware.UnaryClientTimeoutInterceptor
+func WithTimeout(max time.Duration) Option {
+	return func(o *option) {
+		o.timeout = max
+	}
+}
+
+// WithBreaker 按方法熔断，见 middleware.UnaryClientBreakerInterceptor
+func WithBreaker(opts ...breaker.Option) Option {
+	return func(o *option) {
+		o.breaker = breaker.NewGroup(opts...)
+	}
+}
+
+// WithMetricsOptions 设置指标拦截器的选项，如 middleware.WithMetricsRegisterer
+func WithMetricsOptions(opts ...middleware.MetricsOption) Option {
+	return func(o *option) {
+		o.metricsOptions = append(o.metricsOptions, opts...)
+	}
+}
+
+// WithInterceptors 在标准拦截器之后追加拦截器
+func WithInterceptors(interceptors ...grpc.UnaryClientInterceptor) Option {
+	return func(o *option) {
+		o.interceptors = append(o.interceptors, interceptors...)
+	}
+}
+
+// WithoutStandardInterceptors 不挂载标准拦截器，只使用 WithInterceptors 设置的拦截器
+func WithoutStandardInterceptors() Option {
+	return func(o *option) {
+		o.skipInterceptors = true
+	}
+}
+
+// WithDiscovery 通过 d 解析 discovery:///服务名 形式的 target，见 discovery.NewResolverBuilder
+func WithDiscovery(d discovery.Discovery) Option {
+	return func(o *option) {
+		o.dialOptions = append(o.dialOptions, grpc.WithResolvers(discovery.NewResolverBuilder(d)))
+	}
+}
+
+// WithDialOptions 追加 grpc.DialOption，追加在默认选项之后，可覆盖默认值
+func WithDialOptions(opts ...grpc.DialOption) Option {
+	return func(o *option) {
+		o.dialOptions = append(o.dialOptions, opts...)
+	}
+}
+
+// Dial 创建到 service 的连接，service 为 gRPC target，没有 scheme 时使用 dns:///，
+// 使 round_robin 在域名解析出的所有地址间均衡，使用 discovery:/// 时在注册中心的所有实例间均衡。连接是惰性建立的，Dial 不会等待连接就绪
+func Dial(service string, opts ...Option) (*grpc.ClientConn, error) {
+	o := &option{
+		creds: insecure.NewCredentials(),
+		keepalive: keepalive.ClientParameters{
+			Time:    DefaultKeepaliveTime,
+			Timeout: DefaultKeepaliveTimeout,
+		},
+		maxAttempts:    DefaultMaxAttempts,
+		initialBackoff: 100 * time.Millisecond,
+		maxBackoff:     time.Second,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	serviceConfig, err := o.serviceConfig()
+	if err != nil {
+		return nil, err
+	}
+	dialOpts := append([]grpc.DialOption{
+		grpc.WithTransportCredentials(o.creds),
+		grpc.WithKeepaliveParams(o.keepalive),
+		grpc.WithDefaultServiceConfig(serviceConfig),
+		grpc.WithChainUnaryInterceptor(o.unaryInterceptors()...),
+		grpc.WithChainStreamInterceptor(middleware.StreamClientInterceptors()...),
+	}, o.dialOptions...)
+
+	conn, err := grpc.NewClient(Target(service), dialOpts...)
+	if err != nil {
+		return nil, fmt.Errorf("dial %s failed: %w", service, err)
+	}
+	return conn, nil
+}
+
+// Target 返回 service 对应的 gRPC target，没有 scheme 时加上 dns:///
+func Target(service string) string {
+	if strings.Contains(service, "://") || strings.HasPrefix(service, "unix:") {
+		return service
+	}
+	return "dns:///" + service
+}
+
+func (o *option) unaryInterceptors() []grpc.UnaryClientInterceptor {
+	var interceptors []grpc.UnaryClientInterceptor
+	if !o.skipInterceptors {
+		interceptors = append(interceptors,
+			middleware.UnaryClientTracingInterceptor(),
+			middleware.UnaryClientLoggingInterceptor(),
+			middleware.UnaryClientMetricsInterceptor(o.metricsOptions...),
+		)
+		if o.timeout > 0 {
+			interceptors = append(interceptors, middleware.UnaryClientTimeoutInterceptor(o.timeout))
+		}
+		if o.breaker != nil {
+			interceptors = append(interceptors, middleware.UnaryClientBreakerInterceptor(o.breaker))
+		}
+		interceptors = append(interceptors, middleware.UnaryClientErrorxInterceptor())
+	}
+	return append(interceptors, o.interceptors...)
+}
+
+// serviceConfig gRPC service config，格式见 https://github.com/grpc/grpc/blob/master/doc/service_config.md
+type serviceConfig struct {
+	LoadBalancingConfig []map[string]struct{} `json:"loadBalancingConfig"`
+	MethodConfig        []methodConfig        `json:"methodConfig,omitempty"`
+}
+
+type methodConfig struct {
+	Name        []methodName `json:"name"`
+	RetryPolicy retryPolicy  `json:"retryPolicy"`
+}
+
+type methodName struct {
+	Service string `json:"service"`
+	Method  string `json:"method,omitempty"`
+}
+
+type retryPolicy struct {
+	MaxAttempts          int      `json:"maxAttempts"`
+	InitialBackoff       string   `json:"initialBackoff"`
+	MaxBackoff           string   `json:"maxBackoff"`
+	BackoffMultiplier    float64  `json:"backoffMultiplier"`
+	RetryableStatusCodes []string `json:"retryableStatusCodes"`
+}
+
+// serviceConfig 生成启用 round_robin 与幂等方法重试的 service config
+func (o *option) serviceConfig() (string, error) {
+	cfg := serviceConfig{
+		LoadBalancingConfig: []map[string]struct{}{{"round_robin": {}}},
+	}
+	if len(o.idempotent) > 0 && o.maxAttempts > 1 {
+		names := make([]methodName, 0, len(o.idempotent))
+		for _, m := range o.idempotent {
+			name, err := parseMethod(m)
+			if err != nil {
+				return "", err
+			}
+			names = append(names, name)
+		}
+		cfg.MethodConfig = []methodConfig{{
+			Name: names,
+			RetryPolicy: retryPolicy{
+				MaxAttempts:          o.maxAttempts,
+				InitialBackoff:       durationString(o.initialBackoff),
+				MaxBackoff:           durationString(o.maxBackoff),
+				BackoffMultiplier:    2,
+				RetryableStatusCodes: []string{"UNAVAILABLE"},
+			},
+		}}
+	}
+
+	b, err := json.Marshal(cfg)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+// parseMethod 解析 /package.Service/Method 或 /package.Service/
+func parseMethod(fullMethod string) (methodName, error) {
+	name := strings.TrimPrefix(fullMethod, "/")
+	i := strings.LastIndex(name, "/")
+	if i <= 0 {
+		return methodName{}, fmt.Errorf("invalid method %q, want /package.Service/Method", fullMethod)
+	}
+	return methodName{Service: name[:i], Method: name[i+1:]}, nil
+}
+
+// durationString 将时长格式化为 service config 要求的秒数，如 0.1s
+func durationString(d time.Duration) string {
+	return fmt.Sprintf("%gs", d.Seconds())
+}
@@ -0,0 +1,107 @@
+package rpcclient
+
+import (
+	"context"
+	"net"
+	"sync"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	"github.com/ZampoRen/go-server-comon/internal/middleware"
+)
+
+// flakyServer 每个方法的前 failures 次调用返回 Unavailable
+type flakyServer struct {
+	mu       sync.Mutex
+	failures int
+	calls    map[string]int
+}
+
+func (s *flakyServer) handle(srv interface{}, stream grpc.ServerStream) error {
+	method, _ := grpc.MethodFromServerStream(stream)
+	var req emptypb.Empty
+	if err := stream.RecvMsg(&req); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	s.calls[method]++
+	n := s.calls[method]
+	s.mu.Unlock()
+	if n <= s.failures {
+		return status.Error(codes.Unavailable, "try again")
+	}
+	return stream.SendMsg(&emptypb.Empty{})
+}
+
+func TestDial(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fs := &flakyServer{failures: 2, calls: make(map[string]int)}
+	srv := grpc.NewServer(grpc.UnknownServiceHandler(fs.handle))
+	go func() { _ = srv.Serve(lis) }()
+	defer srv.Stop()
+
+	reg := prometheus.NewRegistry()
+	conn, err := Dial(lis.Addr().String(),
+		WithIdempotentMethods("/test.Svc/Get"),
+		WithMetricsOptions(middleware.WithMetricsRegisterer(reg)),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	t.Run("幂等方法在 UNAVAILABLE 时重试", func(t *testing.T) {
+		if err := conn.Invoke(context.Background(), "/test.Svc/Get", &emptypb.Empty{}, &emptypb.Empty{}); err != nil {
+			t.Fatalf("Invoke() error = %v", err)
+		}
+		if got := fs.calls["/test.Svc/Get"]; got != 3 {
+			t.Errorf("calls = %d, want 3", got)
+		}
+	})
+
+	t.Run("非幂等方法不重试", func(t *testing.T) {
+		err := conn.Invoke(context.Background(), "/test.Svc/Put", &emptypb.Empty{}, &emptypb.Empty{})
+		if status.Code(err) != codes.Unavailable {
+			t.Fatalf("err = %v, want Unavailable", err)
+		}
+		if got := fs.calls["/test.Svc/Put"]; got != 1 {
+			t.Errorf("calls = %d, want 1", got)
+		}
+	})
+
+	t.Run("记录调用指标", func(t *testing.T) {
+		mfs, err := reg.Gather()
+		if err != nil {
+			t.Fatal(err)
+		}
+		names := make(map[string]bool)
+		for _, mf := range mfs {
+			names[mf.GetName()] = true
+		}
+		if !names["grpc_client_handled_total"] || !names["grpc_client_handling_seconds"] {
+			t.Errorf("metrics = %v", names)
+		}
+	})
+}
+
+func TestTarget(t *testing.T) {
+	for in, want := range map[string]string{
+		"user-svc:9000":          "dns:///user-svc:9000",
+		"dns:///user-svc:9000":   "dns:///user-svc:9000",
+		"unix:///tmp/user.sock":  "unix:///tmp/user.sock",
+		"passthrough:///1.2.3.4": "passthrough:///1.2.3.4",
+		"discovery:///user":      "discovery:///user",
+	} {
+		if got := Target(in); got != want {
+			t.Errorf("Target(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,280 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/common/hlog"
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/ZampoRen/go-server-comon/internal/infra/cache"
+	"github.com/ZampoRen/go-server-comon/pkg/envkey"
+)
+
+var (
+	// ErrInvalidRefreshToken 刷新 token 无效、已使用或已注销
+	ErrInvalidRefreshToken = errors.New("invalid refresh token")
+	// ErrTokenRevoked 访问 token 已注销
+	ErrTokenRevoked = errors.New("token revoked")
+)
+
+// TokenPair 登录或刷新后签发的 token
+type TokenPair struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	// TokenType 固定为 Bearer
+	TokenType string `json:"token_type"`
+	// ExpiresIn 访问 token 的有效期（秒）
+	ExpiresIn int64 `json:"expires_in"`
+}
+
+// SessionOption 会话选项
+type SessionOption func(o *sessionOption)
+
+type sessionOption struct {
+	secret     []byte
+	issuer     string
+	audience   string
+	accessTTL  time.Duration
+	refreshTTL time.Duration
+	keyPrefix  string
+	failOpen   bool
+	roles      func(ctx context.Context, subject string) ([]string, error)
+	now        func() time.Time
+}
+
+// WithSessionSecret 设置 HS256 签名密钥，默认读取环境变量 JWT_SECRET
+func WithSessionSecret(secret []byte) SessionOption {
+	return func(o *sessionOption) {
+		o.secret = secret
+	}
+}
+
+// WithSessionIssuer 设置签发 token 的 iss，默认读取环境变量 JWT_ISSUER
+func WithSessionIssuer(issuer string) SessionOption {
+	return func(o *sessionOption) {
+		o.issuer = issuer
+	}
+}
+
+// WithSessionAudience 设置签发 token 的 aud，默认读取环境变量 JWT_AUDIENCE
+func WithSessionAudience(audience string) SessionOption {
+	return func(o *sessionOption) {
+		o.audience = audience
+	}
+}
+
+// WithSessionTTL 设置访问 token 与刷新 token 的有效期，默认 15m 与 7 天
+func WithSessionTTL(access, refresh time.Duration) SessionOption {
+	return func(o *sessionOption) {
+		if access > 0 {
+			o.accessTTL = access
+		}
+		if refresh > 0 {
+			o.refreshTTL = refresh
+		}
+	}
+}
+
+// WithSessionKeyPrefix 设置缓存键前缀，默认 session:
+func WithSessionKeyPrefix(prefix string) SessionOption {
+	return func(o *sessionOption) {
+		o.keyPrefix = prefix
+	}
+}
+
+// WithSessionFailOpen 设置查询黑名单失败时是否放行，默认读取环境变量 SESSION_FAIL_OPEN（默认 true），
+// 避免缓存故障导致所有请求认证失败；
+// 设置为 false 时查询失败的请求按未认证拒绝
+func WithSessionFailOpen(failOpen bool) SessionOption {
+	return func(o *sessionOption) {
+		o.failOpen = failOpen
+	}
+}
+
+// WithSessionRoles 设置刷新 token 时重新加载 subject 角色的函数，使角色变更在刷新后生效；
+// 未设置时沿用刷新 token 中的角色
+func WithSessionRoles(fn func(ctx context.Context, subject string) ([]string, error)) SessionOption {
+	return func(o *sessionOption) {
+		o.roles = fn
+	}
+}
+
+// SessionManager 签发、刷新与注销 JWT
+// 刷新 token 的 jti 保存在缓存中，刷新时删除并签发新的一对 token，每个刷新 token 只能使用一次；
+// 注销时将访问 token 的 jti 加入黑名单直到其过期。签发的 token 与 UnaryServerAuthInterceptor
+// 使用相同的 Claims，gRPC 拦截器通过 WithSessionManager 共用同一个 SessionManager 时同样检查黑名单
+type SessionManager struct {
+	o        *sessionOption
+	store    cache.Cmdable
+	verifier *Verifier
+}
+
+// NewSessionManager 创建会话管理器，store 通常为 Redis
+func NewSessionManager(store cache.Cmdable, opts ...SessionOption) (*SessionManager, error) {
+	o := &sessionOption{
+		secret:     []byte(envkey.GetString("JWT_SECRET")),
+		issuer:     envkey.GetString("JWT_ISSUER"),
+		audience:   envkey.GetString("JWT_AUDIENCE"),
+		accessTTL:  15 * time.Minute,
+		refreshTTL: 7 * 24 * time.Hour,
+		keyPrefix:  "session:",
+		failOpen:   envkey.GetBoolD("SESSION_FAIL_OPEN", true),
+		now:        time.Now,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	if len(o.secret) == 0 {
+		return nil, errors.New("session manager requires a HS256 secret")
+	}
+
+	verifier, err := NewVerifier(WithHS256Secret(o.secret), WithIssuer(o.issuer), WithAudience(o.audience))
+	if err != nil {
+		return nil, err
+	}
+	return &SessionManager{o: o, store: store, verifier: verifier}, nil
+}
+
+// Issue 为 subject（通常为用户 ID）签发一对 token
+func (m *SessionManager) Issue(ctx context.Context, subject string, roles ...string) (*TokenPair, error) {
+	now := m.o.now()
+	access, _, err := m.sign(subject, roles, TokenTypeAccess, now, m.o.accessTTL)
+	if err != nil {
+		return nil, err
+	}
+	refresh, refreshID, err := m.sign(subject, roles, TokenTypeRefresh, now, m.o.refreshTTL)
+	if err != nil {
+		return nil, err
+	}
+	if err := m.store.Set(ctx, m.refreshKey(refreshID), subject, m.o.refreshTTL).Err(); err != nil {
+		return nil, fmt.Errorf("save refresh token failed: %w", err)
+	}
+
+	return &TokenPair{
+		AccessToken:  access,
+		RefreshToken: refresh,
+		TokenType:    "Bearer",
+		ExpiresIn:    int64(m.o.accessTTL.Seconds()),
+	}, nil
+}
+
+// Refresh 校验刷新 token 并签发新的一对 token，原刷新 token 随即失效
+func (m *SessionManager) Refresh(ctx context.Context, refreshToken string) (*TokenPair, error) {
+	claims, err := m.verifier.Verify(ctx, refreshToken)
+	if err != nil || claims.TokenType != TokenTypeRefresh || claims.ID == "" {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	if err := m.store.GetDel(ctx, m.refreshKey(claims.ID)).Err(); err != nil {
+		if errors.Is(err, cache.Nil) {
+			return nil, ErrInvalidRefreshToken
+		}
+		return nil, fmt.Errorf("load refresh token failed: %w", err)
+	}
+	roles := claims.Roles
+	if m.o.roles != nil {
+		if roles, err = m.o.roles(ctx, claims.Subject); err != nil {
+			return nil, fmt.Errorf("load roles of %s failed: %w", claims.Subject, err)
+		}
+	}
+	return m.Issue(ctx, claims.Subject, roles...)
+}
+
+// Revoke 注销会话：访问 token 加入黑名单直到过期，refreshToken 非空时同时使其失效
+func (m *SessionManager) Revoke(ctx context.Context, access *Claims, refreshToken string) error {
+	if access != nil && access.ID != "" && access.ExpiresAt != nil {
+		if ttl := access.ExpiresAt.Sub(m.o.now()); ttl > 0 {
+			if err := m.store.Set(ctx, m.blacklistKey(access.ID), 1, ttl).Err(); err != nil {
+				return fmt.Errorf("revoke access token failed: %w", err)
+			}
+		}
+	}
+
+	if refreshToken == "" {
+		return nil
+	}
+	claims, err := m.verifier.Verify(ctx, refreshToken)
+	if err != nil || claims.TokenType != TokenTypeRefresh {
+		// 已过期或无效的刷新 token 本身不能再使用
+		return nil
+	}
+	if err := m.store.Del(ctx, m.refreshKey(claims.ID)).Err(); err != nil {
+		return fmt.Errorf("revoke refresh token failed: %w", err)
+	}
+	return nil
+}
+
+// Authenticate 校验访问 token 并检查黑名单
+func (m *SessionManager) Authenticate(ctx context.Context, token string) (*Claims, error) {
+	claims, err := m.verifier.VerifyAccess(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	if err := m.CheckRevoked(ctx, claims); err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+// CheckRevoked 检查访问 token 是否已注销，已注销时返回 ErrTokenRevoked
+// 查询黑名单失败时按 WithSessionFailOpen 放行（记录告警）或返回错误
+func (m *SessionManager) CheckRevoked(ctx context.Context, claims *Claims) error {
+	if claims.ID == "" {
+		return nil
+	}
+
+	n, err := m.store.Exists(ctx, m.blacklistKey(claims.ID)).Result()
+	if err != nil {
+		if m.o.failOpen {
+			hlog.CtxWarnf(ctx, "[Session] check blacklist for %s failed, allow request: %v", claims.Subject, err)
+			return nil
+		}
+		return fmt.Errorf("check blacklist failed: %w", err)
+	}
+	if n > 0 {
+		return ErrTokenRevoked
+	}
+	return nil
+}
+
+// Verifier 返回校验签发 token 的校验器
+func (m *SessionManager) Verifier() *Verifier {
+	return m.verifier
+}
+
+// sign 签发 token，返回 token 与其 jti
+func (m *SessionManager) sign(subject string, roles []string, typ string, now time.Time, ttl time.Duration) (string, string, error) {
+	id := NewRequestID()
+	claims := &Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        id,
+			Subject:   subject,
+			Issuer:    m.o.issuer,
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+		},
+		Roles:     roles,
+		TokenType: typ,
+	}
+	if m.o.audience != "" {
+		claims.Audience = jwt.ClaimStrings{m.o.audience}
+	}
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(m.o.secret)
+	if err != nil {
+		return "", "", fmt.Errorf("sign %s token failed: %w", typ, err)
+	}
+	return token, id, nil
+}
+
+func (m *SessionManager) refreshKey(id string) string {
+	return m.o.keyPrefix + "refresh:" + id
+}
+
+func (m *SessionManager) blacklistKey(id string) string {
+	return m.o.keyPrefix + "blacklist:" + id
+}
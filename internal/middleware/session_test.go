@@ -0,0 +1,126 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	goredis "github.com/redis/go-redis/v9"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/ZampoRen/go-server-comon/internal/infra/cache/impl/memory"
+	cacheredis "github.com/ZampoRen/go-server-comon/internal/infra/cache/impl/redis"
+)
+
+func TestSessionManager(t *testing.T) {
+	ctx := context.Background()
+	m, err := NewSessionManager(memory.New(), WithSessionSecret([]byte("secret")), WithSessionIssuer("test"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pair, err := m.Issue(ctx, "1001", "admin")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("访问 token 认证成功", func(t *testing.T) {
+		claims, err := m.Authenticate(ctx, pair.AccessToken)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if claims.Subject != "1001" || !claims.HasRole("admin") {
+			t.Errorf("claims = %+v", claims)
+		}
+	})
+
+	t.Run("刷新 token 不能用于认证", func(t *testing.T) {
+		if _, err := m.Authenticate(ctx, pair.RefreshToken); !errors.Is(err, ErrRefreshTokenUsed) {
+			t.Errorf("err = %v, want ErrRefreshTokenUsed", err)
+		}
+	})
+
+	t.Run("刷新 token 只能使用一次", func(t *testing.T) {
+		next, err := m.Refresh(ctx, pair.RefreshToken)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := m.Refresh(ctx, pair.RefreshToken); !errors.Is(err, ErrInvalidRefreshToken) {
+			t.Errorf("err = %v, want ErrInvalidRefreshToken", err)
+		}
+		pair = next
+	})
+
+	t.Run("注销后访问 token 与刷新 token 失效", func(t *testing.T) {
+		claims, err := m.Authenticate(ctx, pair.AccessToken)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := m.Revoke(ctx, claims, pair.RefreshToken); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := m.Authenticate(ctx, pair.AccessToken); !errors.Is(err, ErrTokenRevoked) {
+			t.Errorf("err = %v, want ErrTokenRevoked", err)
+		}
+		if _, err := m.Refresh(ctx, pair.RefreshToken); !errors.Is(err, ErrInvalidRefreshToken) {
+			t.Errorf("err = %v, want ErrInvalidRefreshToken", err)
+		}
+	})
+}
+
+// TestSessionInterceptor 测试 gRPC 拦截器检查 SessionManager 的黑名单
+func TestSessionInterceptor(t *testing.T) {
+	ctx := context.Background()
+	call := func(interceptor grpc.UnaryServerInterceptor, token string) error {
+		ctx := metadata.NewIncomingContext(ctx, metadata.Pairs(AuthorizationKey, "Bearer "+token))
+		_, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: "/user.User/UpdateUser"}, func(ctx context.Context, req interface{}) (interface{}, error) {
+			return nil, nil
+		})
+		return err
+	}
+
+	t.Run("注销后 gRPC 请求被拒绝", func(t *testing.T) {
+		m, err := NewSessionManager(memory.New(), WithSessionSecret([]byte("secret")))
+		if err != nil {
+			t.Fatal(err)
+		}
+		interceptor := UnaryServerAuthInterceptor(WithSessionManager(m))
+		pair, _ := m.Issue(ctx, "1001")
+		if err := call(interceptor, pair.AccessToken); err != nil {
+			t.Fatalf("call() error = %v", err)
+		}
+		claims, _ := m.Authenticate(ctx, pair.AccessToken)
+		if err := m.Revoke(ctx, claims, ""); err != nil {
+			t.Fatal(err)
+		}
+		if err := call(interceptor, pair.AccessToken); status.Code(err) != codes.Unauthenticated {
+			t.Fatalf("call() error = %v, want Unauthenticated", err)
+		}
+	})
+
+	t.Run("查询黑名单失败", func(t *testing.T) {
+		mr := miniredis.RunT(t)
+		store := cacheredis.NewWithClientOptions(&goredis.Options{Addr: mr.Addr(), MaxRetries: -1})
+		open, _ := NewSessionManager(store, WithSessionSecret([]byte("secret")))
+		closed, _ := NewSessionManager(store, WithSessionSecret([]byte("secret")), WithSessionFailOpen(false))
+		pair, err := open.Issue(ctx, "1001")
+		if err != nil {
+			t.Fatal(err)
+		}
+		mr.Close()
+
+		if _, err := open.Authenticate(ctx, pair.AccessToken); err != nil {
+			t.Errorf("fail open Authenticate() error = %v", err)
+		}
+		if _, err := closed.Authenticate(ctx, pair.AccessToken); err == nil {
+			t.Error("fail closed Authenticate() expected error")
+		}
+		if err := call(UnaryServerAuthInterceptor(WithSessionManager(closed)), pair.AccessToken); status.Code(err) != codes.Unauthenticated {
+			t.Errorf("fail closed call() error = %v, want Unauthenticated", err)
+		}
+	})
+}
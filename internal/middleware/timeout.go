@@ -0,0 +1,151 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/common/hlog"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/ZampoRen/go-server-comon/internal/errno"
+	"github.com/ZampoRen/go-server-comon/pkg/errorx"
+)
+
+// TimeoutOption 超时拦截器选项
+type TimeoutOption func(o *timeoutOption)
+
+type timeoutOption struct {
+	timeout time.Duration
+	methods map[string]time.Duration
+}
+
+// WithMethodTimeout 单独设置方法的超时时间，方法为完整名称，如 /user.User/ExportUsers
+func WithMethodTimeout(method string, timeout time.Duration) TimeoutOption {
+	return func(o *timeoutOption) {
+		o.methods[method] = timeout
+	}
+}
+
+func newTimeoutOption(timeout time.Duration, opts ...TimeoutOption) *timeoutOption {
+	o := &timeoutOption{timeout: timeout, methods: make(map[string]time.Duration)}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// timeoutFor 返回方法的超时时间，<= 0 表示不限制
+func (o *timeoutOption) timeoutFor(method string) time.Duration {
+	if d, ok := o.methods[method]; ok {
+		return d
+	}
+	return o.timeout
+}
+
+// UnaryServerTimeoutInterceptor 调用方未设置 deadline 时使用 timeout 作为处理时限，调用方设置的 deadline 保持不变
+// handler 因超时失败时记录告警并返回 DeadlineExceeded，errno.ErrDeadlineExceeded 写入 trailer
+func UnaryServerTimeoutInterceptor(timeout time.Duration, opts ...TimeoutOption) grpc.UnaryServerInterceptor {
+	o := newTimeoutOption(timeout, opts...)
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, cancel := withDefaultTimeout(ctx, o.timeoutFor(info.FullMethod))
+		defer cancel()
+
+		resp, err := handler(ctx, req)
+		if trailer, st := deadlineExceeded(ctx, info.FullMethod, err); st != nil {
+			_ = grpc.SetTrailer(ctx, trailer)
+			return nil, st
+		}
+		return resp, err
+	}
+}
+
+// StreamServerTimeoutInterceptor 同 UnaryServerTimeoutInterceptor，用于流式调用，时限覆盖整个流
+func StreamServerTimeoutInterceptor(timeout time.Duration, opts ...TimeoutOption) grpc.StreamServerInterceptor {
+	o := newTimeoutOption(timeout, opts...)
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, cancel := withDefaultTimeout(ss.Context(), o.timeoutFor(info.FullMethod))
+		defer cancel()
+
+		err := handler(srv, &contextStream{ServerStream: ss, ctx: ctx})
+		if trailer, st := deadlineExceeded(ctx, info.FullMethod, err); st != nil {
+			ss.SetTrailer(trailer)
+			return st
+		}
+		return err
+	}
+}
+
+// UnaryClientTimeoutInterceptor 将调用的 deadline 限制在 max 以内：ctx 没有 deadline 或 deadline 晚于 max 时使用 max，
+// 更早的 deadline 保持不变。调用因超时失败时记录告警并返回错误码为 errno.ErrDeadlineExceeded 的 errorx 错误
+func UnaryClientTimeoutInterceptor(max time.Duration, opts ...TimeoutOption) grpc.UnaryClientInterceptor {
+	o := newTimeoutOption(max, opts...)
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, callOpts ...grpc.CallOption) error {
+		ctx, cancel := withMaxTimeout(ctx, o.timeoutFor(method))
+		defer cancel()
+
+		err := invoker(ctx, method, req, reply, cc, callOpts...)
+		if err == nil || !isDeadlineExceeded(err) {
+			return err
+		}
+		hlog.CtxWarnf(ctx, "[Timeout] call %s deadline exceeded: %v", method, err)
+		var se errorx.StatusError
+		if errors.As(err, &se) {
+			return err
+		}
+		return errorx.WrapByCode(err, errno.ErrDeadlineExceeded, errorx.Extra(ErrorMethodExtraKey, method))
+	}
+}
+
+// withDefaultTimeout ctx 没有 deadline 时设置 timeout
+func withDefaultTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if _, ok := ctx.Deadline(); ok || timeout <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
+// withMaxTimeout ctx 没有 deadline 或 deadline 晚于 now+max 时设置 max
+func withMaxTimeout(ctx context.Context, max time.Duration) (context.Context, context.CancelFunc) {
+	if max <= 0 {
+		return ctx, func() {}
+	}
+	if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) <= max {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, max)
+}
+
+// deadlineExceeded handler 因超时失败时记录告警，返回携带错误码的 trailer 与 DeadlineExceeded 错误，否则返回 nil
+// handler 返回的 errorx 错误与非超时的 gRPC 状态保持不变，以保留更具体的错误码
+func deadlineExceeded(ctx context.Context, method string, err error) (metadata.MD, error) {
+	if err == nil {
+		return nil, nil
+	}
+	var se errorx.StatusError
+	if errors.As(err, &se) {
+		return nil, nil
+	}
+	if st, ok := status.FromError(err); ok {
+		if st.Code() != codes.DeadlineExceeded {
+			return nil, nil
+		}
+	} else if !errors.Is(err, context.DeadlineExceeded) && !errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return nil, nil
+	}
+	deadline, _ := ctx.Deadline()
+	hlog.CtxWarnf(ctx, "[Timeout] %s deadline exceeded, deadline=%s: %v", method, deadline.Format(time.RFC3339Nano), err)
+
+	_ = errors.As(errorx.New(errno.ErrDeadlineExceeded), &se)
+	trailer := metadata.Pairs(ErrorCodeTrailerKey, strconv.FormatInt(int64(se.Code()), 10))
+	return trailer, status.Error(codes.DeadlineExceeded, se.Msg())
+}
+
+// isDeadlineExceeded 判断错误是否由超时引起，包括 context.DeadlineExceeded 与 gRPC DeadlineExceeded 状态
+func isDeadlineExceeded(err error) bool {
+	return errors.Is(err, context.DeadlineExceeded) || status.Code(err) == codes.DeadlineExceeded
+}
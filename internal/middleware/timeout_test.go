@@ -0,0 +1,90 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/ZampoRen/go-server-comon/internal/errno"
+	"github.com/ZampoRen/go-server-comon/pkg/errorx"
+)
+
+func TestUnaryServerTimeoutInterceptor(t *testing.T) {
+	interceptor := UnaryServerTimeoutInterceptor(50*time.Millisecond, WithMethodTimeout("/user.User/Export", 0))
+	info := &grpc.UnaryServerInfo{FullMethod: "/user.User/GetUser"}
+
+	t.Run("未设置 deadline 时使用默认超时", func(t *testing.T) {
+		_, err := interceptor(context.Background(), nil, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+			if _, ok := ctx.Deadline(); !ok {
+				t.Error("deadline not set")
+			}
+			<-ctx.Done()
+			return nil, ctx.Err()
+		})
+		if status.Code(err) != codes.DeadlineExceeded {
+			t.Fatalf("err = %v, want DeadlineExceeded", err)
+		}
+	})
+
+	t.Run("保留调用方的 deadline", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Hour)
+		defer cancel()
+		want, _ := ctx.Deadline()
+		_, _ = interceptor(ctx, nil, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+			if got, _ := ctx.Deadline(); !got.Equal(want) {
+				t.Errorf("deadline = %v, want %v", got, want)
+			}
+			return nil, nil
+		})
+	})
+
+	t.Run("方法超时为 0 时不限制", func(t *testing.T) {
+		_, _ = interceptor(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: "/user.User/Export"}, func(ctx context.Context, req interface{}) (interface{}, error) {
+			if _, ok := ctx.Deadline(); ok {
+				t.Error("deadline should not be set")
+			}
+			return nil, nil
+		})
+	})
+
+	t.Run("保留 errorx 错误", func(t *testing.T) {
+		_, err := interceptor(context.Background(), nil, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+			<-ctx.Done()
+			return nil, errorx.New(errno.ErrDBTimeout)
+		})
+		var se errorx.StatusError
+		if !errors.As(err, &se) || se.Code() != errno.ErrDBTimeout {
+			t.Fatalf("err = %v, want ErrDBTimeout", err)
+		}
+	})
+}
+
+func TestUnaryClientTimeoutInterceptor(t *testing.T) {
+	interceptor := UnaryClientTimeoutInterceptor(time.Second)
+
+	t.Run("限制过长的 deadline", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Hour)
+		defer cancel()
+		_ = interceptor(ctx, "/user.User/GetUser", nil, nil, nil, func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+			if deadline, ok := ctx.Deadline(); !ok || time.Until(deadline) > time.Second {
+				t.Errorf("deadline = %v, want within 1s", deadline)
+			}
+			return nil
+		})
+	})
+
+	t.Run("超时转换为 errorx 错误", func(t *testing.T) {
+		err := interceptor(context.Background(), "/user.User/GetUser", nil, nil, nil, func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+			return status.Error(codes.DeadlineExceeded, "context deadline exceeded")
+		})
+		var se errorx.StatusError
+		if !errors.As(err, &se) || se.Code() != errno.ErrDeadlineExceeded {
+			t.Fatalf("err = %v, want ErrDeadlineExceeded", err)
+		}
+	})
+}
@@ -0,0 +1,210 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	otelcodes "go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/ZampoRen/go-server-comon/pkg/errorx"
+)
+
+const tracerName = "github.com/ZampoRen/go-server-comon/internal/middleware"
+
+// TracingOption 链路追踪拦截器选项
+type TracingOption func(o *tracingOption)
+
+type tracingOption struct {
+	tracerProvider trace.TracerProvider
+	propagator     propagation.TextMapPropagator
+}
+
+// WithTracerProvider 设置 TracerProvider，默认使用全局 TracerProvider
+func WithTracerProvider(tp trace.TracerProvider) TracingOption {
+	return func(o *tracingOption) {
+		if tp != nil {
+			o.tracerProvider = tp
+		}
+	}
+}
+
+// WithPropagator 设置跨进程传播器，默认使用全局 TextMapPropagator（trace.Init 设置为 W3C tracecontext）
+func WithPropagator(p propagation.TextMapPropagator) TracingOption {
+	return func(o *tracingOption) {
+		if p != nil {
+			o.propagator = p
+		}
+	}
+}
+
+func newTracingOption(opts ...TracingOption) *tracingOption {
+	o := &tracingOption{
+		tracerProvider: otel.GetTracerProvider(),
+		propagator:     otel.GetTextMapPropagator(),
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// UnaryServerTracingInterceptor 从 metadata 中提取上游的 trace 上下文并为每次调用创建服务端 span，
+// 后续拦截器与 handler 中的 Redis、MySQL、ES 调用都挂在该 span 下。应放在拦截器链的最前面
+func UnaryServerTracingInterceptor(opts ...TracingOption) grpc.UnaryServerInterceptor {
+	o := newTracingOption(opts...)
+	tracer := o.tracerProvider.Tracer(tracerName)
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, span := o.startServerSpan(ctx, tracer, info.FullMethod)
+		defer span.End()
+
+		resp, err := handler(ctx, req)
+		recordServerStatus(span, err)
+		return resp, err
+	}
+}
+
+// StreamServerTracingInterceptor 同 UnaryServerTracingInterceptor，用于流式调用，整个流对应一个 span
+func StreamServerTracingInterceptor(opts ...TracingOption) grpc.StreamServerInterceptor {
+	o := newTracingOption(opts...)
+	tracer := o.tracerProvider.Tracer(tracerName)
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, span := o.startServerSpan(ss.Context(), tracer, info.FullMethod)
+		defer span.End()
+
+		err := handler(srv, &contextStream{ServerStream: ss, ctx: ctx})
+		recordServerStatus(span, err)
+		return err
+	}
+}
+
+// UnaryClientTracingInterceptor 为每次调用创建客户端 span，并将 trace 上下文写入 metadata 传给下游
+func UnaryClientTracingInterceptor(opts ...TracingOption) grpc.UnaryClientInterceptor {
+	o := newTracingOption(opts...)
+	tracer := o.tracerProvider.Tracer(tracerName)
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		ctx, span := o.startClientSpan(ctx, tracer, method, cc)
+		defer span.End()
+
+		err := invoker(ctx, method, req, reply, cc, opts...)
+		recordClientStatus(span, err)
+		return err
+	}
+}
+
+// StreamClientTracingInterceptor 同 UnaryClientTracingInterceptor，用于流式调用，span 在建立流后结束
+func StreamClientTracingInterceptor(opts ...TracingOption) grpc.StreamClientInterceptor {
+	o := newTracingOption(opts...)
+	tracer := o.tracerProvider.Tracer(tracerName)
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		ctx, span := o.startClientSpan(ctx, tracer, method, cc)
+		defer span.End()
+
+		cs, err := streamer(ctx, desc, cc, method, opts...)
+		recordClientStatus(span, err)
+		return cs, err
+	}
+}
+
+func (o *tracingOption) startServerSpan(ctx context.Context, tracer trace.Tracer, fullMethod string) (context.Context, trace.Span) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	ctx = o.propagator.Extract(ctx, metadataCarrier(md))
+	return tracer.Start(ctx, strings.TrimPrefix(fullMethod, "/"),
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(rpcAttributes(fullMethod)...),
+	)
+}
+
+func (o *tracingOption) startClientSpan(ctx context.Context, tracer trace.Tracer, fullMethod string, cc *grpc.ClientConn) (context.Context, trace.Span) {
+	attrs := rpcAttributes(fullMethod)
+	if cc != nil {
+		attrs = append(attrs, attribute.String("server.address", cc.Target()))
+	}
+	ctx, span := tracer.Start(ctx, strings.TrimPrefix(fullMethod, "/"),
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attrs...),
+	)
+
+	md, ok := metadata.FromOutgoingContext(ctx)
+	if ok {
+		md = md.Copy()
+	} else {
+		md = metadata.MD{}
+	}
+	o.propagator.Inject(ctx, metadataCarrier(md))
+	return metadata.NewOutgoingContext(ctx, md), span
+}
+
+// rpcAttributes 按 /package.Service/Method 拆分服务名与方法名
+func rpcAttributes(fullMethod string) []attribute.KeyValue {
+	service, method := splitMethod(fullMethod)
+	return []attribute.KeyValue{
+		attribute.String("rpc.system", "grpc"),
+		attribute.String("rpc.service", service),
+		attribute.String("rpc.method", method),
+	}
+}
+
+// recordServerStatus 记录状态码，只有服务端错误才将 span 标记为失败
+func recordServerStatus(span trace.Span, err error) {
+	code := status.Code(err)
+	span.SetAttributes(attribute.Int("rpc.grpc.status_code", int(code)))
+	if err == nil {
+		return
+	}
+
+	var errCode int32
+	var se errorx.StatusError
+	if errors.As(err, &se) {
+		errCode = se.Code()
+		span.SetAttributes(attribute.Int("errorx.code", int(errCode)))
+	}
+	if isServerError(code, errCode) {
+		span.RecordError(err)
+		span.SetStatus(otelcodes.Error, err.Error())
+	}
+}
+
+// recordClientStatus 记录状态码，任何错误都将 span 标记为失败
+func recordClientStatus(span trace.Span, err error) {
+	code := status.Code(err)
+	span.SetAttributes(attribute.Int("rpc.grpc.status_code", int(code)))
+	if code == codes.OK {
+		return
+	}
+	span.RecordError(err)
+	span.SetStatus(otelcodes.Error, err.Error())
+}
+
+// metadataCarrier 将 gRPC metadata 适配为 propagation.TextMapCarrier
+type metadataCarrier metadata.MD
+
+var _ propagation.TextMapCarrier = metadataCarrier(nil)
+
+func (c metadataCarrier) Get(key string) string {
+	values := metadata.MD(c).Get(key)
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
+
+func (c metadataCarrier) Set(key, value string) {
+	metadata.MD(c).Set(key, value)
+}
+
+func (c metadataCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for k := range c {
+		keys = append(keys, k)
+	}
+	return keys
+}
@@ -0,0 +1,86 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	otelcodes "go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/ZampoRen/go-server-comon/internal/errno"
+	"github.com/ZampoRen/go-server-comon/pkg/errorx"
+)
+
+func TestTracingInterceptors(t *testing.T) {
+	sr := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(sr))
+	opts := []TracingOption{WithTracerProvider(tp), WithPropagator(propagation.TraceContext{})}
+	client := UnaryClientTracingInterceptor(opts...)
+	server := UnaryServerTracingInterceptor(opts...)
+	info := &grpc.UnaryServerInfo{FullMethod: "/user.User/GetUser"}
+
+	call := func(handler grpc.UnaryHandler) error {
+		return client(context.Background(), info.FullMethod, nil, nil, nil,
+			func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+				// 模拟网络传输：客户端的 outgoing metadata 成为服务端的 incoming metadata
+				md, _ := metadata.FromOutgoingContext(ctx)
+				_, err := server(metadata.NewIncomingContext(context.Background(), md), req, info, handler)
+				return err
+			})
+	}
+
+	t.Run("服务端 span 与客户端 span 属于同一链路", func(t *testing.T) {
+		sr.Reset()
+		var serverSC trace.SpanContext
+		err := call(func(ctx context.Context, req interface{}) (interface{}, error) {
+			serverSC = trace.SpanContextFromContext(ctx)
+			return nil, nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		spans := sr.Ended()
+		if len(spans) != 2 {
+			t.Fatalf("got %d spans, want 2", len(spans))
+		}
+		srv, cli := spans[0], spans[1]
+		if srv.SpanKind() != trace.SpanKindServer || cli.SpanKind() != trace.SpanKindClient {
+			t.Fatalf("kinds = %v, %v", srv.SpanKind(), cli.SpanKind())
+		}
+		if srv.Name() != "user.User/GetUser" {
+			t.Errorf("name = %q", srv.Name())
+		}
+		if srv.Parent().SpanID() != cli.SpanContext().SpanID() || serverSC.TraceID() != cli.SpanContext().TraceID() {
+			t.Errorf("server span not linked to client span")
+		}
+	})
+
+	t.Run("客户端错误不标记服务端 span 失败", func(t *testing.T) {
+		sr.Reset()
+		_ = call(func(ctx context.Context, req interface{}) (interface{}, error) {
+			return nil, errorx.New(errno.ErrInvalidParam)
+		})
+		spans := sr.Ended()
+		if spans[0].Status().Code == otelcodes.Error {
+			t.Errorf("server status = %v, want unset", spans[0].Status())
+		}
+	})
+
+	t.Run("服务端错误标记失败", func(t *testing.T) {
+		sr.Reset()
+		_ = call(func(ctx context.Context, req interface{}) (interface{}, error) {
+			return nil, errors.New("db down")
+		})
+		spans := sr.Ended()
+		if spans[0].Status().Code != otelcodes.Error || spans[1].Status().Code != otelcodes.Error {
+			t.Errorf("status = %v, %v, want Error", spans[0].Status(), spans[1].Status())
+		}
+	})
+}
@@ -0,0 +1,55 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"reflect"
+
+	"google.golang.org/grpc"
+
+	"github.com/ZampoRen/go-server-comon/internal/errno"
+	"github.com/ZampoRen/go-server-comon/internal/validate"
+	"github.com/ZampoRen/go-server-comon/pkg/errorx"
+)
+
+// validator 由请求自身实现的校验，如 protoc-gen-validate 生成的 Validate 方法
+type validator interface {
+	Validate() error
+}
+
+// UnaryServerValidationInterceptor 在调用 handler 前校验请求：先调用请求的 Validate 方法（如有），
+// 再按结构体的 validate 标签使用 v 校验，v 为 nil 时使用 validate.Default()。
+// 校验失败返回 errno.ErrValidation 错误，不调用 handler；应放在 UnaryServerErrorxInterceptor 之后
+func UnaryServerValidationInterceptor(v *validate.Validator) grpc.UnaryServerInterceptor {
+	if v == nil {
+		v = validate.Default()
+	}
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if r, ok := req.(validator); ok {
+			if err := r.Validate(); err != nil {
+				var se errorx.StatusError
+				if !errors.As(err, &se) {
+					err = errorx.WrapByCode(err, errno.ErrValidation, errorx.KV("details", err.Error()))
+				}
+				return nil, err
+			}
+		}
+		if isStruct(req) {
+			if err := v.Struct(ctx, req); err != nil {
+				return nil, err
+			}
+		}
+		return handler(ctx, req)
+	}
+}
+
+func isStruct(req interface{}) bool {
+	rv := reflect.ValueOf(req)
+	for rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			return false
+		}
+		rv = rv.Elem()
+	}
+	return rv.Kind() == reflect.Struct
+}
@@ -0,0 +1,66 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"google.golang.org/grpc"
+
+	"github.com/ZampoRen/go-server-comon/internal/errno"
+	"github.com/ZampoRen/go-server-comon/pkg/errorx"
+)
+
+type validationRequest struct {
+	Mobile string `json:"mobile" validate:"required,mobile"`
+	err    error
+}
+
+func (r *validationRequest) Validate() error {
+	return r.err
+}
+
+func TestUnaryServerValidationInterceptor(t *testing.T) {
+	interceptor := UnaryServerValidationInterceptor(nil)
+	info := &grpc.UnaryServerInfo{FullMethod: "/user.User/Register"}
+	called := false
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		called = true
+		return "ok", nil
+	}
+
+	tests := []struct {
+		name string
+		req  interface{}
+		code int32
+	}{
+		{name: "校验通过", req: &validationRequest{Mobile: "13800138000"}},
+		{name: "非结构体请求", req: "raw"},
+		{name: "validate 标签不通过", req: &validationRequest{Mobile: "123"}, code: errno.ErrValidation},
+		{name: "Validate 方法不通过", req: &validationRequest{Mobile: "13800138000", err: errors.New("invalid")}, code: errno.ErrValidation},
+		{
+			name: "Validate 方法返回 errorx 错误",
+			req:  &validationRequest{Mobile: "13800138000", err: errorx.New(errno.ErrInvalidParam)},
+			code: errno.ErrInvalidParam,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			called = false
+			_, err := interceptor(context.Background(), tt.req, info, handler)
+			if tt.code == 0 {
+				if err != nil || !called {
+					t.Fatalf("err = %v, called = %v", err, called)
+				}
+				return
+			}
+			var se errorx.StatusError
+			if !errors.As(err, &se) || se.Code() != tt.code {
+				t.Fatalf("err = %v, want code %d", err, tt.code)
+			}
+			if called {
+				t.Error("handler should not be called")
+			}
+		})
+	}
+}
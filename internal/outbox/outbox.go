@@ -0,0 +1,383 @@
+// Package outbox 事务性发件箱，解决先写数据库再发消息时两者不一致的问题
+//
+// 业务在同一个 gorm 事务中写入业务数据与待发送的消息，事务提交后由 Relay 异步发送到消息队列：
+//
+//	ob := outbox.New(db, producer)
+//	err := tm.Tx(ctx, func(ctx context.Context) error {
+//		if err := userRepo.Create(ctx, u); err != nil {
+//			return err
+//		}
+//		return ob.Publish(ctx, &mq.Message{Topic: "user-events", Key: strconv.FormatInt(u.ID, 10), Tag: "created", Body: body})
+//	})
+//
+//	lc.Append(lifecycle.Hook{Name: "outbox", OnStart: ob.Start, OnStop: ob.Stop})
+//
+// 事务回滚时消息随之丢弃；事务提交后消息至少发送一次。Key 相同的消息按写入顺序发送，前一条发送失败时后续消息等待其重试，
+// 超过最大发送次数被标记为 StatusFailed 后才继续发送。发送成功但标记失败（如进程崩溃）时消息会被重复发送，
+// 消息头 x-outbox-id 在重复发送时保持不变，消费方可据此去重，从而达到近似恰好一次的效果
+package outbox
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/common/hlog"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/ZampoRen/go-server-comon/internal/infra/mq"
+	"github.com/ZampoRen/go-server-comon/internal/infra/orm/txmanager"
+)
+
+// IDHeader 消息头中的发件箱记录 ID，重复发送时不变
+const IDHeader = "x-outbox-id"
+
+var (
+	// ErrNoTransaction ctx 中没有 txmanager 开启的事务
+	ErrNoTransaction = errors.New("outbox: publish outside of a transaction")
+	// ErrStarted Relay 已启动
+	ErrStarted = errors.New("outbox: relay already started")
+)
+
+// 记录状态
+const (
+	StatusPending int8 = 0 // 待发送
+	StatusSent    int8 = 1 // 已发送
+	StatusFailed  int8 = 2 // 超过最大重试次数，不再发送
+)
+
+// Event 发件箱中的一条消息
+type Event struct {
+	ID      int64  `gorm:"primaryKey;autoIncrement"`
+	Topic   string `gorm:"size:255;not null"`
+	Key     string `gorm:"size:255;index:idx_outbox_key"`
+	Tag     string `gorm:"size:128"`
+	Body    []byte
+	Headers string `gorm:"type:text"`
+	Status  int8   `gorm:"not null;default:0;index:idx_outbox_status_next,priority:1"`
+	// NextAttemptAt 下次可以发送的时间，被 Relay 取出后推后一个租期，避免多个实例重复发送
+	NextAttemptAt time.Time `gorm:"not null;index:idx_outbox_status_next,priority:2"`
+	// ClaimedBy 最近一次取出该记录的批次
+	ClaimedBy string `gorm:"size:32;index"`
+	Attempts  int    `gorm:"not null;default:0"`
+	LastError string `gorm:"size:1024"`
+	CreatedAt time.Time
+	SentAt    *time.Time
+}
+
+// Option 选项
+type Option func(o *option)
+
+type option struct {
+	table       string
+	interval    time.Duration
+	batch       int
+	lease       time.Duration
+	maxAttempts int
+	retention   time.Duration
+}
+
+// WithTable 设置表名，默认 outbox_events
+func WithTable(table string) Option {
+	return func(o *option) {
+		if table != "" {
+			o.table = table
+		}
+	}
+}
+
+// WithInterval 设置 Relay 检查待发送消息的间隔，默认 1s
+func WithInterval(d time.Duration) Option {
+	return func(o *option) {
+		if d > 0 {
+			o.interval = d
+		}
+	}
+}
+
+// WithBatch 设置每次取出的最大消息数，默认 100
+func WithBatch(n int) Option {
+	return func(o *option) {
+		if n > 0 {
+			o.batch = n
+		}
+	}
+}
+
+// WithLease 设置取出后到可被重新取出的租期，默认 30s，应大于一批消息的发送耗时
+func WithLease(d time.Duration) Option {
+	return func(o *option) {
+		if d > 0 {
+			o.lease = d
+		}
+	}
+}
+
+// WithMaxAttempts 设置最大发送次数，默认 16，超过后记录标记为 StatusFailed
+func WithMaxAttempts(n int) Option {
+	return func(o *option) {
+		if n > 0 {
+			o.maxAttempts = n
+		}
+	}
+}
+
+// WithRetention 设置已发送记录的保留时间，默认 7 天，0 表示发送成功后立即删除
+func WithRetention(d time.Duration) Option {
+	return func(o *option) {
+		if d >= 0 {
+			o.retention = d
+		}
+	}
+}
+
+// Outbox 事务性发件箱，并发安全
+type Outbox struct {
+	db       *gorm.DB
+	producer mq.Producer
+	o        *option
+
+	mu     sync.Mutex
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// New 创建发件箱，db 为业务使用的数据库，producer 用于 Relay 发送消息
+func New(db *gorm.DB, producer mq.Producer, opts ...Option) *Outbox {
+	o := &option{
+		table:       "outbox_events",
+		interval:    time.Second,
+		batch:       100,
+		lease:       30 * time.Second,
+		maxAttempts: 16,
+		retention:   7 * 24 * time.Hour,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return &Outbox{db: db, producer: producer, o: o}
+}
+
+// Migrate 创建或更新发件箱表
+func (ob *Outbox) Migrate(ctx context.Context) error {
+	return ob.db.WithContext(ctx).Table(ob.o.table).AutoMigrate(&Event{})
+}
+
+// Publish 在 ctx 携带的事务中写入消息，事务提交后由 Relay 发送。ctx 中没有事务时返回 ErrNoTransaction
+func (ob *Outbox) Publish(ctx context.Context, msgs ...*mq.Message) error {
+	if !txmanager.InTx(ctx) {
+		return ErrNoTransaction
+	}
+	if len(msgs) == 0 {
+		return nil
+	}
+	now := time.Now()
+	events := make([]*Event, 0, len(msgs))
+	for _, m := range msgs {
+		e := &Event{Topic: m.Topic, Key: m.Key, Tag: m.Tag, Body: m.Body, NextAttemptAt: now, CreatedAt: now}
+		if len(m.Headers) > 0 {
+			headers, err := json.Marshal(m.Headers)
+			if err != nil {
+				return err
+			}
+			e.Headers = string(headers)
+		}
+		events = append(events, e)
+	}
+	return txmanager.FromContext(ctx, ob.db).Table(ob.o.table).Create(events).Error
+}
+
+// Start 启动 Relay，多个实例可以同时运行，同一条消息同一时刻只会被一个实例取出
+func (ob *Outbox) Start(context.Context) error {
+	ob.mu.Lock()
+	defer ob.mu.Unlock()
+	if ob.cancel != nil {
+		return ErrStarted
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	ob.cancel, ob.done = cancel, make(chan struct{})
+	go ob.run(ctx, ob.done)
+	return nil
+}
+
+// Stop 停止 Relay 并等待正在发送的一批消息完成，ctx 结束时不再等待
+func (ob *Outbox) Stop(ctx context.Context) error {
+	ob.mu.Lock()
+	cancel, done := ob.cancel, ob.done
+	ob.cancel, ob.done = nil, nil
+	ob.mu.Unlock()
+	if cancel == nil {
+		return nil
+	}
+	cancel()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (ob *Outbox) run(ctx context.Context, done chan struct{}) {
+	defer close(done)
+	ticker := time.NewTicker(ob.o.interval)
+	defer ticker.Stop()
+	lastCleanup := time.Time{}
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		// 取满一批说明可能还有待发送的消息，继续取出直到不足一批
+		for ctx.Err() == nil {
+			n, err := ob.Relay(ctx)
+			if err != nil {
+				if ctx.Err() == nil {
+					hlog.CtxWarnf(ctx, "[Outbox] relay %s failed: %v", ob.o.table, err)
+				}
+				break
+			}
+			if n < ob.o.batch {
+				break
+			}
+		}
+		if ob.o.retention > 0 && time.Since(lastCleanup) > time.Minute {
+			lastCleanup = time.Now()
+			if err := ob.cleanup(ctx); err != nil && ctx.Err() == nil {
+				hlog.CtxWarnf(ctx, "[Outbox] clean up %s failed: %v", ob.o.table, err)
+			}
+		}
+	}
+}
+
+// Relay 取出一批到期的消息并发送，返回取出的消息数。Start 启动后会定期调用，也可在测试或命令行工具中直接调用
+func (ob *Outbox) Relay(ctx context.Context) (int, error) {
+	events, err := ob.claim(ctx)
+	if err != nil || len(events) == 0 {
+		return 0, err
+	}
+	// failed 本批次中发送失败的 Key，同一 Key 后续的消息留到租期结束后再发送，保证按 Key 有序
+	failed := make(map[string]bool)
+	for _, e := range events {
+		if e.Key != "" && failed[e.Key] {
+			continue
+		}
+		if err := ob.producer.Send(ctx, e.message()); err != nil {
+			ob.fail(ctx, e, err)
+			if e.Key != "" {
+				failed[e.Key] = true
+			}
+			continue
+		}
+		ob.sent(ctx, e)
+	}
+	return len(events), nil
+}
+
+// claim 将到期的消息推后一个租期并标记为本批次，返回成功标记的消息
+// 同一 Key 存在更早的、正在退避或被其他批次取出的消息时不取出，避免乱序
+func (ob *Outbox) claim(ctx context.Context) ([]*Event, error) {
+	now := time.Now()
+	db := ob.db.WithContext(ctx).Table(ob.o.table)
+
+	cur := func(name string) clause.Column { return clause.Column{Table: ob.o.table, Name: name} }
+	prev := func(name string) clause.Column { return clause.Column{Table: "prev", Name: name} }
+	blocked := ob.db.Table("? AS prev", clause.Table{Name: ob.o.table}).Select("1").
+		Where("? = ? AND ? < ? AND ? = ? AND ? > ?",
+			prev("key"), cur("key"), prev("id"), cur("id"), prev("status"), StatusPending, prev("next_attempt_at"), now)
+
+	var ids []int64
+	err := db.Session(&gorm.Session{}).
+		Where("status = ? AND next_attempt_at <= ?", StatusPending, now).
+		Where("? = '' OR NOT EXISTS (?)", cur("key"), blocked).
+		Order("id").Limit(ob.o.batch).Pluck("id", &ids).Error
+	if err != nil || len(ids) == 0 {
+		return nil, err
+	}
+
+	token := claimToken()
+	res := db.Session(&gorm.Session{}).
+		Where("id IN ? AND status = ? AND next_attempt_at <= ?", ids, StatusPending, now).
+		Updates(map[string]any{"claimed_by": token, "next_attempt_at": now.Add(ob.o.lease)})
+	if res.Error != nil || res.RowsAffected == 0 {
+		return nil, res.Error
+	}
+
+	var events []*Event
+	err = db.Session(&gorm.Session{}).Where("claimed_by = ? AND status = ?", token, StatusPending).Order("id").Find(&events).Error
+	return events, err
+}
+
+// sent 标记为已发送，不保留时直接删除
+func (ob *Outbox) sent(ctx context.Context, e *Event) {
+	db := ob.db.WithContext(context.WithoutCancel(ctx)).Table(ob.o.table).Where("id = ?", e.ID)
+	var err error
+	if ob.o.retention == 0 {
+		err = db.Delete(&Event{}).Error
+	} else {
+		now := time.Now()
+		err = db.Updates(map[string]any{"status": StatusSent, "sent_at": &now, "attempts": e.Attempts + 1}).Error
+	}
+	if err != nil {
+		// 租期结束后会被重新发送，由消费方按 x-outbox-id 去重
+		hlog.CtxWarnf(ctx, "[Outbox] mark event %d of %s sent failed: %v", e.ID, e.Topic, err)
+	}
+}
+
+// fail 记录失败原因并按指数退避推迟下次发送，超过最大发送次数后标记为 StatusFailed
+func (ob *Outbox) fail(ctx context.Context, e *Event, sendErr error) {
+	attempts := e.Attempts + 1
+	lastError := sendErr.Error()
+	if len(lastError) > 1024 {
+		lastError = lastError[:1024]
+	}
+	updates := map[string]any{"attempts": attempts, "last_error": lastError}
+	if attempts >= ob.o.maxAttempts {
+		updates["status"] = StatusFailed
+		hlog.CtxErrorf(ctx, "[Outbox] give up event %d of %s after %d attempts: %v", e.ID, e.Topic, attempts, sendErr)
+	} else {
+		updates["next_attempt_at"] = time.Now().Add(backoff(attempts))
+		hlog.CtxWarnf(ctx, "[Outbox] send event %d of %s failed, attempt %d: %v", e.ID, e.Topic, attempts, sendErr)
+	}
+	err := ob.db.WithContext(context.WithoutCancel(ctx)).Table(ob.o.table).Where("id = ?", e.ID).Updates(updates).Error
+	if err != nil {
+		hlog.CtxWarnf(ctx, "[Outbox] mark event %d of %s failed: %v", e.ID, e.Topic, err)
+	}
+}
+
+// cleanup 删除超过保留时间的已发送记录
+func (ob *Outbox) cleanup(ctx context.Context) error {
+	return ob.db.WithContext(ctx).Table(ob.o.table).
+		Where("status = ? AND sent_at < ?", StatusSent, time.Now().Add(-ob.o.retention)).
+		Delete(&Event{}).Error
+}
+
+// message 转换为待发送的消息，消息头中带上记录 ID
+func (e *Event) message() *mq.Message {
+	m := &mq.Message{Topic: e.Topic, Key: e.Key, Tag: e.Tag, Body: e.Body}
+	if e.Headers != "" {
+		if err := json.Unmarshal([]byte(e.Headers), &m.Headers); err != nil {
+			hlog.Warnf("[Outbox] decode headers of event %d failed: %v", e.ID, err)
+		}
+	}
+	m.SetHeader(IDHeader, strconv.FormatInt(e.ID, 10))
+	return m
+}
+
+// backoff 第 n 次失败后的等待时间，从 1s 开始翻倍，最长 10 分钟
+func backoff(n int) time.Duration {
+	return min(time.Second<<min(n-1, 20), 10*time.Minute)
+}
+
+func claimToken() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,238 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+
+	"github.com/ZampoRen/go-server-comon/internal/infra/mq"
+	"github.com/ZampoRen/go-server-comon/internal/infra/orm/impl/sqlite"
+	"github.com/ZampoRen/go-server-comon/internal/infra/orm/txmanager"
+)
+
+// fakeProducer 记录发送的消息，err 不为空或 failBody 与消息体相同时发送失败
+type fakeProducer struct {
+	mq.Producer
+	mu       sync.Mutex
+	msgs     []*mq.Message
+	err      error
+	failBody string
+}
+
+func (p *fakeProducer) Send(_ context.Context, msgs ...*mq.Message) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.err != nil {
+		return p.err
+	}
+	for _, m := range msgs {
+		if p.failBody != "" && string(m.Body) == p.failBody {
+			return errors.New("send " + p.failBody + " failed")
+		}
+	}
+	p.msgs = append(p.msgs, msgs...)
+	return nil
+}
+
+// bodies 返回已发送消息的消息体
+func (p *fakeProducer) bodies() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	bodies := make([]string, 0, len(p.msgs))
+	for _, m := range p.msgs {
+		bodies = append(bodies, string(m.Body))
+	}
+	return bodies
+}
+
+func newTestOutbox(t *testing.T, p mq.Producer, opts ...Option) (*Outbox, *gorm.DB) {
+	t.Helper()
+	db, err := sqlite.NewWithConfig("", &gorm.Config{Logger: gormlogger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ob := New(db, p, opts...)
+	if err := ob.Migrate(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	return ob, db
+}
+
+func count(t *testing.T, db *gorm.DB, status int8) int64 {
+	t.Helper()
+	var n int64
+	if err := db.Table("outbox_events").Where("status = ?", status).Count(&n).Error; err != nil {
+		t.Fatal(err)
+	}
+	return n
+}
+
+// TestPublish 测试消息随事务提交与回滚
+func TestPublish(t *testing.T) {
+	ctx := context.Background()
+	p := &fakeProducer{}
+	ob, db := newTestOutbox(t, p)
+	tm := txmanager.New(db)
+
+	if err := ob.Publish(ctx, &mq.Message{Topic: "users"}); !errors.Is(err, ErrNoTransaction) {
+		t.Fatalf("Publish() outside tx error = %v, want ErrNoTransaction", err)
+	}
+
+	errRollback := errors.New("rollback")
+	err := tm.Tx(ctx, func(ctx context.Context) error {
+		if err := ob.Publish(ctx, &mq.Message{Topic: "users", Key: "1"}); err != nil {
+			return err
+		}
+		return errRollback
+	})
+	if !errors.Is(err, errRollback) {
+		t.Fatal(err)
+	}
+	err = tm.Tx(ctx, func(ctx context.Context) error {
+		msg := &mq.Message{Topic: "users", Key: "2", Tag: "created", Body: []byte("b")}
+		msg.SetHeader("trace", "abc")
+		return ob.Publish(ctx, msg, &mq.Message{Topic: "users", Key: "3"})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if n, err := ob.Relay(ctx); err != nil || n != 2 {
+		t.Fatalf("Relay() = %d, %v, want 2", n, err)
+	}
+	if len(p.msgs) != 2 {
+		t.Fatalf("sent %d messages, want 2", len(p.msgs))
+	}
+	m := p.msgs[0]
+	if m.Key != "2" || m.Tag != "created" || string(m.Body) != "b" || m.Header("trace") != "abc" || m.Header(IDHeader) == "" {
+		t.Fatalf("message = %+v", m)
+	}
+	if n := count(t, db, StatusSent); n != 2 {
+		t.Fatalf("sent events = %d, want 2", n)
+	}
+	if n, _ := ob.Relay(ctx); n != 0 {
+		t.Fatalf("Relay() again = %d, want 0", n)
+	}
+}
+
+// TestRelayFailure 测试发送失败后退避重试，超过最大次数后标记为失败
+func TestRelayFailure(t *testing.T) {
+	ctx := context.Background()
+	p := &fakeProducer{err: errors.New("broker down")}
+	ob, db := newTestOutbox(t, p, WithMaxAttempts(2), WithRetention(0))
+	tm := txmanager.New(db)
+	if err := tm.Tx(ctx, func(ctx context.Context) error {
+		return ob.Publish(ctx, &mq.Message{Topic: "users"})
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if n, _ := ob.Relay(ctx); n != 1 {
+		t.Fatalf("Relay() = %d, want 1", n)
+	}
+	// 退避期间不会再次取出
+	if n, _ := ob.Relay(ctx); n != 0 {
+		t.Fatalf("Relay() during backoff = %d, want 0", n)
+	}
+
+	var e Event
+	db.Table("outbox_events").First(&e)
+	if e.Attempts != 1 || e.LastError != "broker down" || e.Status != StatusPending {
+		t.Fatalf("event = %+v", e)
+	}
+
+	// 到期后再次失败，超过最大次数
+	db.Table("outbox_events").Where("id = ?", e.ID).Update("next_attempt_at", e.CreatedAt)
+	if n, _ := ob.Relay(ctx); n != 1 {
+		t.Fatalf("Relay() = %d, want 1", n)
+	}
+	if n := count(t, db, StatusFailed); n != 1 {
+		t.Fatalf("failed events = %d, want 1", n)
+	}
+}
+
+// TestRelayOrder 测试同一 Key 的消息在前一条发送失败后不会越过它发送
+func TestRelayOrder(t *testing.T) {
+	ctx := context.Background()
+	p := &fakeProducer{failBody: "a"}
+	ob, db := newTestOutbox(t, p, WithRetention(0))
+	tm := txmanager.New(db)
+	if err := tm.Tx(ctx, func(ctx context.Context) error {
+		return ob.Publish(ctx,
+			&mq.Message{Topic: "users", Key: "1", Body: []byte("a")},
+			&mq.Message{Topic: "users", Key: "1", Body: []byte("b")},
+			&mq.Message{Topic: "users", Key: "2", Body: []byte("c")},
+		)
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if n, err := ob.Relay(ctx); err != nil || n != 3 {
+		t.Fatalf("Relay() = %d, %v, want 3", n, err)
+	}
+	if got := p.bodies(); !slices.Equal(got, []string{"c"}) {
+		t.Fatalf("sent = %v, want [c]", got)
+	}
+
+	// b 的租期结束但 a 仍在退避，不能取出 b
+	db.Table("outbox_events").Where("body = ?", []byte("b")).Update("next_attempt_at", time.Now().Add(-time.Second))
+	if n, _ := ob.Relay(ctx); n != 0 {
+		t.Fatalf("Relay() while a is backing off = %d, want 0", n)
+	}
+
+	// a 到期后按顺序发送 a、b
+	p.failBody = ""
+	db.Table("outbox_events").Where("1 = 1").Update("next_attempt_at", time.Now().Add(-time.Second))
+	if n, err := ob.Relay(ctx); err != nil || n != 2 {
+		t.Fatalf("Relay() = %d, %v, want 2", n, err)
+	}
+	if got := p.bodies(); !slices.Equal(got, []string{"c", "a", "b"}) {
+		t.Fatalf("sent = %v, want [c a b]", got)
+	}
+}
+
+// TestStartStop 测试 Relay 后台运行，不保留已发送记录
+func TestStartStop(t *testing.T) {
+	ctx := context.Background()
+	p := &fakeProducer{}
+	ob, db := newTestOutbox(t, p, WithInterval(10*time.Millisecond), WithRetention(0))
+	if err := ob.Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := ob.Start(ctx); !errors.Is(err, ErrStarted) {
+		t.Fatalf("Start() again error = %v, want ErrStarted", err)
+	}
+	if err := txmanager.New(db).Tx(ctx, func(ctx context.Context) error {
+		return ob.Publish(ctx, &mq.Message{Topic: "users"})
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	deadline := time.Now().Add(3 * time.Second)
+	for {
+		p.mu.Lock()
+		n := len(p.msgs)
+		p.mu.Unlock()
+		if n == 1 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("message was not relayed")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if err := ob.Stop(ctx); err != nil {
+		t.Fatal(err)
+	}
+	var total int64
+	db.Table("outbox_events").Count(&total)
+	if total != 0 {
+		t.Fatalf("events left = %d, want 0", total)
+	}
+}
@@ -0,0 +1,134 @@
+// Package response 提供 Hertz 接口的统一响应格式
+//
+//	func GetUser(ctx context.Context, c *app.RequestContext) {
+//		u, err := svc.GetUser(ctx, id)
+//		if err != nil {
+//			response.Error(ctx, c, err)
+//			return
+//		}
+//		response.Success(c, u)
+//	}
+//
+// 响应体为 {"code": 0, "msg": "ok", "data": ..., "request_id": "..."}，
+// 失败时 code 为 errorx 错误码，HTTP 状态码由 errno.HTTPStatus 决定，服务端错误额外返回 incident_id。
+// 注册 httpmw.Locale 后，msg 为 errorx 错误按请求语言本地化的消息
+package response
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/ZampoRen/go-server-comon/internal/errno"
+	"github.com/ZampoRen/go-server-comon/pkg/errorx"
+	"github.com/ZampoRen/go-server-comon/pkg/i18n"
+	logger "github.com/ZampoRen/go-server-comon/pkg/logs"
+)
+
+// CodeOK 成功响应的 code
+const CodeOK int32 = 0
+
+// Envelope 统一响应体
+type Envelope struct {
+	Code int32  `json:"code"`
+	Msg  string `json:"msg"`
+	Data any    `json:"data,omitempty"`
+	// RequestID 请求 ID，需要注册 httpmw.RequestID 中间件
+	RequestID string `json:"request_id,omitempty"`
+	// IncidentID 服务端错误的事故 ID，与日志中的 incident_id 对应，便于用户反馈问题
+	IncidentID string `json:"incident_id,omitempty"`
+}
+
+// Success 以 200 返回 data
+func Success(c *app.RequestContext, data any) {
+	c.JSON(http.StatusOK, &Envelope{
+		Code:      CodeOK,
+		Msg:       "ok",
+		Data:      data,
+		RequestID: c.GetString(logger.RequestIDKey),
+	})
+}
+
+// Error 按 err 的 errorx 错误码返回错误响应，HTTP 状态码由 errno.HTTPStatus 决定
+// 不是 errorx 错误时，gRPC 状态按状态码转换为对应的通用错误码，其余错误视为 errno.ErrInternal。
+// 服务端错误（HTTP 5xx）通过 logger.ErrorX 记录并返回事故 ID，响应中不包含内部错误信息
+func Error(ctx context.Context, c *app.RequestContext, err error) {
+	c.JSON(render(ctx, c, err))
+}
+
+// Abort 同 Error，并终止后续 handler，用于中间件
+func Abort(ctx context.Context, c *app.RequestContext, err error) {
+	c.AbortWithStatusJSON(render(ctx, c, err))
+}
+
+func render(ctx context.Context, c *app.RequestContext, err error) (int, *Envelope) {
+	err, msg := toStatusError(err)
+	var se errorx.StatusError
+	_ = errors.As(err, &se)
+	if msg == "" {
+		if localized, ok := i18n.FromContext(ctx).Error(err); ok {
+			msg = localized
+		} else {
+			msg = se.Msg()
+		}
+	}
+
+	httpStatus := errno.HTTPStatus(se.Code())
+	env := &Envelope{
+		Code:      se.Code(),
+		Msg:       msg,
+		RequestID: logger.RequestIDFromContext(ctx),
+	}
+	if env.RequestID == "" {
+		env.RequestID = c.GetString(logger.RequestIDKey)
+	}
+	if httpStatus >= http.StatusInternalServerError {
+		// 已经由 logger.ErrorX 记录过的错误直接复用事故 ID，避免重复记录
+		env.IncidentID = se.Extra()[logger.IncidentIDKey]
+		if env.IncidentID == "" {
+			env.IncidentID = logger.Default().ErrorX(ctx, "[Response] "+string(c.Method())+" "+string(c.Path()), err)
+		}
+	}
+	return httpStatus, env
+}
+
+// grpcCodes gRPC 状态码对应的通用错误码
+var grpcCodes = map[codes.Code]int32{
+	codes.InvalidArgument:    errno.ErrInvalidParam,
+	codes.OutOfRange:         errno.ErrInvalidParam,
+	codes.Unauthenticated:    errno.ErrUnauthenticated,
+	codes.PermissionDenied:   errno.ErrPermissionDenied,
+	codes.NotFound:           errno.ErrNotFound,
+	codes.AlreadyExists:      errno.ErrConflict,
+	codes.Aborted:            errno.ErrConflict,
+	codes.FailedPrecondition: errno.ErrUnavailable,
+	codes.Unavailable:        errno.ErrUnavailable,
+	codes.ResourceExhausted:  errno.ErrTooManyRequests,
+	codes.DeadlineExceeded:   errno.ErrDeadlineExceeded,
+}
+
+// toStatusError 将 err 转换为 errorx 错误，客户端错误的 gRPC 状态保留其消息，返回的 msg 非空时代替注册的消息
+func toStatusError(err error) (error, string) {
+	var se errorx.StatusError
+	if errors.As(err, &se) {
+		return err, ""
+	}
+	if err == nil {
+		return errorx.New(errno.ErrInternal), ""
+	}
+
+	if st, ok := status.FromError(err); ok {
+		if code, ok := grpcCodes[st.Code()]; ok {
+			wrapped := errorx.WrapByCode(err, code)
+			if errno.HTTPStatus(code) < http.StatusInternalServerError {
+				return wrapped, st.Message()
+			}
+			return wrapped, ""
+		}
+	}
+	return errorx.WrapByCode(err, errno.ErrInternal), ""
+}
@@ -0,0 +1,149 @@
+// Package admin 提供内部管理端口，集中挂载调试与运维接口
+//
+//	srv, err := admin.Start()
+//	if err != nil {
+//		hlog.Fatalf("start admin server failed: %v", err)
+//	}
+//	defer srv.Shutdown(ctx)
+//
+// 挂载的接口：
+//   - /debug/pprof/: net/http/pprof
+//   - /debug/vars: expvar
+//   - /metrics: Prometheus 指标
+//   - /debug/loglevel: 查询与修改日志级别，见 logger.LevelHandler
+//   - /debug/errcodes: 已注册的错误码及其 HTTP 状态码
+//   - /debug/localcache: 通过 localcache.WithStats 开启统计的本地缓存命中率
+//
+// 管理端口不做认证，默认只监听 127.0.0.1，改为其他地址时只应监听内网地址或由网关限制访问
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"expvar"
+	"net"
+	"net/http"
+	"net/http/pprof"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/common/hlog"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"github.com/ZampoRen/go-server-comon/internal/errno"
+	"github.com/ZampoRen/go-server-comon/pkg/envkey"
+	"github.com/ZampoRen/go-server-comon/pkg/localcache"
+	logger "github.com/ZampoRen/go-server-comon/pkg/logs"
+)
+
+// Option 管理端口选项
+type Option func(o *option)
+
+type option struct {
+	addr     string
+	gatherer prometheus.Gatherer
+	handlers map[string]http.Handler
+}
+
+// WithAddr 设置监听地址，默认读取环境变量 ADMIN_ADDR，未设置时为 127.0.0.1:6060，只接受本机访问；
+// 需要从容器外或其他机器访问时显式设置，如 :6060
+func WithAddr(addr string) Option {
+	return func(o *option) {
+		o.addr = addr
+	}
+}
+
+// WithGatherer 设置 /metrics 导出的指标来源，默认 prometheus.DefaultGatherer
+func WithGatherer(g prometheus.Gatherer) Option {
+	return func(o *option) {
+		if g != nil {
+			o.gatherer = g
+		}
+	}
+}
+
+// WithHandler 挂载自定义接口，pattern 与内置接口相同时覆盖内置接口
+func WithHandler(pattern string, h http.Handler) Option {
+	return func(o *option) {
+		o.handlers[pattern] = h
+	}
+}
+
+// Server 管理端口服务
+type Server struct {
+	srv *http.Server
+	lis net.Listener
+}
+
+// Start 监听管理端口并在后台提供服务，监听失败时返回错误
+func Start(opts ...Option) (*Server, error) {
+	o := &option{
+		addr:     envkey.GetStringD("ADMIN_ADDR", "127.0.0.1:6060"),
+		gatherer: prometheus.DefaultGatherer,
+		handlers: make(map[string]http.Handler),
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	lis, err := net.Listen("tcp", o.addr)
+	if err != nil {
+		return nil, err
+	}
+	s := &Server{
+		srv: &http.Server{Handler: newMux(o), ReadHeaderTimeout: 5 * time.Second},
+		lis: lis,
+	}
+	go func() {
+		if err := s.srv.Serve(lis); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			hlog.Errorf("[Admin] server exited: %v", err)
+		}
+	}()
+	hlog.Infof("[Admin] listening on %s", lis.Addr())
+	return s, nil
+}
+
+// Addr 返回实际监听的地址
+func (s *Server) Addr() net.Addr {
+	return s.lis.Addr()
+}
+
+// Shutdown 停止管理端口，等待进行中的请求完成
+func (s *Server) Shutdown(ctx context.Context) error {
+	return s.srv.Shutdown(ctx)
+}
+
+func newMux(o *option) *http.ServeMux {
+	handlers := map[string]http.Handler{
+		"/debug/pprof/":        http.HandlerFunc(pprof.Index),
+		"/debug/pprof/cmdline": http.HandlerFunc(pprof.Cmdline),
+		"/debug/pprof/profile": http.HandlerFunc(pprof.Profile),
+		"/debug/pprof/symbol":  http.HandlerFunc(pprof.Symbol),
+		"/debug/pprof/trace":   http.HandlerFunc(pprof.Trace),
+		"/debug/vars":          expvar.Handler(),
+		"/metrics":             promhttp.HandlerFor(o.gatherer, promhttp.HandlerOpts{}),
+		"/debug/loglevel":      logger.LevelHandler(),
+		"/debug/errcodes":      jsonHandler(func() any { return errno.Codes() }),
+		"/debug/localcache":    jsonHandler(func() any { return localcache.AllStats() }),
+	}
+	for pattern, h := range o.handlers {
+		handlers[pattern] = h
+	}
+
+	mux := http.NewServeMux()
+	for pattern, h := range handlers {
+		mux.Handle(pattern, h)
+	}
+	return mux
+}
+
+// jsonHandler 以 JSON 返回 fn 的结果
+func jsonHandler(fn func() any) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		_ = enc.Encode(fn())
+	})
+}
@@ -0,0 +1,80 @@
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/ZampoRen/go-server-comon/internal/errno"
+	"github.com/ZampoRen/go-server-comon/pkg/localcache"
+	logger "github.com/ZampoRen/go-server-comon/pkg/logs"
+)
+
+func TestServer(t *testing.T) {
+	srv, err := Start(WithAddr("127.0.0.1:0"), WithGatherer(prometheus.NewRegistry()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer srv.Shutdown(context.Background())
+	base := "http://" + srv.Addr().String()
+
+	do := func(t *testing.T, method, path, body string) (int, string) {
+		req, _ := http.NewRequest(method, base+path, strings.NewReader(body))
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		b, _ := io.ReadAll(resp.Body)
+		return resp.StatusCode, string(b)
+	}
+
+	t.Run("错误码列表", func(t *testing.T) {
+		_, body := do(t, http.MethodGet, "/debug/errcodes", "")
+		var codes []errno.CodeInfo
+		if err := json.Unmarshal([]byte(body), &codes); err != nil {
+			t.Fatal(err)
+		}
+		for _, c := range codes {
+			if c.Code == errno.ErrNotFound && c.HTTPStatus == http.StatusNotFound {
+				return
+			}
+		}
+		t.Errorf("ErrNotFound missing in %s", body)
+	})
+
+	t.Run("修改日志级别", func(t *testing.T) {
+		defer func() { _ = logger.SetLevel("info") }()
+		if code, body := do(t, http.MethodPut, "/debug/loglevel", `{"level":"debug"}`); code != http.StatusOK || logger.GetLevel() != "debug" {
+			t.Errorf("code = %d, body = %s", code, body)
+		}
+		if code, _ := do(t, http.MethodPut, "/debug/loglevel", `{"level":"verbose"}`); code != http.StatusBadRequest {
+			t.Errorf("invalid level code = %d", code)
+		}
+	})
+
+	t.Run("本地缓存统计", func(t *testing.T) {
+		c := localcache.New[string](localcache.WithStats("admin_test"))
+		defer c.Stop()
+		_, _ = c.Get(context.Background(), "k", func(context.Context) (string, error) { return "v", nil })
+		_, _ = c.Get(context.Background(), "k", func(context.Context) (string, error) { return "v", nil })
+
+		_, body := do(t, http.MethodGet, "/debug/localcache", "")
+		if !strings.Contains(body, `"name": "admin_test"`) || !strings.Contains(body, `"hit_ratio": 0.5`) {
+			t.Errorf("body = %s", body)
+		}
+	})
+
+	t.Run("pprof 与指标", func(t *testing.T) {
+		for _, path := range []string{"/debug/pprof/", "/debug/vars", "/metrics"} {
+			if code, _ := do(t, http.MethodGet, path, ""); code != http.StatusOK {
+				t.Errorf("%s code = %d", path, code)
+			}
+		}
+	})
+}
@@ -0,0 +1,232 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/common/hlog"
+	"golang.org/x/crypto/bcrypt"
+
+	pb "github.com/ZampoRen/go-server-comon/api/model/user"
+	"github.com/ZampoRen/go-server-comon/internal/errno"
+	"github.com/ZampoRen/go-server-comon/internal/infra/cache"
+	"github.com/ZampoRen/go-server-comon/internal/infra/ratelimit"
+	"github.com/ZampoRen/go-server-comon/internal/middleware"
+	"github.com/ZampoRen/go-server-comon/pkg/envkey"
+	"github.com/ZampoRen/go-server-comon/pkg/errorx"
+)
+
+const (
+	minPasswordLen = 8
+	// maxPasswordLen bcrypt 只使用前 72 字节，更长的密码会被拒绝
+	maxPasswordLen = 72
+)
+
+// DefaultLoginLimit 默认的登录失败限制：同一账号连续失败 5 次后每分钟只允许一次尝试
+var DefaultLoginLimit = ratelimit.Limit{Rate: 1.0 / 60, Burst: 5}
+
+// dummyHash 用户不存在时参与比较的哈希，使响应时间与密码错误时一致，避免通过耗时判断账号是否存在
+var dummyHash, _ = bcrypt.GenerateFromPassword([]byte("dummy-password"), bcrypt.DefaultCost)
+
+// WithSessions 设置签发 JWT 的会话管理器，签发的 token 可由 middleware.UnaryServerAuthInterceptor 校验
+// 未设置时 Register 与 Login 返回 errno.ErrUnavailable
+func WithSessions(m *middleware.SessionManager) Option {
+	return func(s *Server) {
+		s.sessions = m
+	}
+}
+
+// WithLoginLimit 限制登录失败次数：每次失败从 limiter 中 account 对应的令牌桶取一个令牌，
+// 令牌耗尽后在 store 中记录锁定，锁定期间该账号的登录直接返回 ErrTooManyLoginAttempts
+// limiter 通常为 ratelimit.NewRedis，使多个实例共享计数；limit 为零值时使用 DefaultLoginLimit
+func WithLoginLimit(store cache.Cmdable, limiter ratelimit.Limiter, limit ratelimit.Limit) Option {
+	return func(s *Server) {
+		if limit.IsZero() {
+			limit = DefaultLoginLimit
+		}
+		s.guard = &loginGuard{store: store, limiter: limiter, limit: limit}
+	}
+}
+
+// Register 注册用户并签发 token
+func (s *Server) Register(ctx context.Context, req *pb.RegisterRequest) (*pb.AuthResponse, error) {
+	if s.sessions == nil {
+		return nil, errorx.New(errno.ErrUnavailable)
+	}
+	if reason := validateRecord(userRecord{Username: req.Username, Email: req.Email}); reason != "" {
+		return nil, errorx.New(errno.ErrInvalidParam, errorx.Extra("reason", reason))
+	}
+	hash, err := hashPassword(req.Password)
+	if err != nil {
+		return nil, err
+	}
+
+	user := &User{Username: req.Username, Email: req.Email, PasswordHash: hash}
+	if err := s.repo.Create(ctx, user); err != nil {
+		return nil, err
+	}
+	return s.issue(ctx, user)
+}
+
+// Login 使用用户名或邮箱与密码登录，账号不存在与密码错误均返回 ErrInvalidCredentials
+func (s *Server) Login(ctx context.Context, req *pb.LoginRequest) (*pb.AuthResponse, error) {
+	if s.sessions == nil {
+		return nil, errorx.New(errno.ErrUnavailable)
+	}
+	if req.Username == "" || req.Password == "" {
+		return nil, errorx.New(errno.ErrInvalidParam, errorx.Extra("reason", "username and password are required"))
+	}
+
+	user, err := s.repo.GetByLogin(ctx, req.Username)
+	if err != nil && errCodeOf(err) != ErrUserNotFound {
+		return nil, err
+	}
+	account := "login:" + strings.ToLower(req.Username)
+	if user != nil {
+		account = "user:" + strconv.FormatInt(user.ID, 10)
+	}
+	if err := s.guard.check(ctx, account); err != nil {
+		return nil, err
+	}
+
+	if !checkPassword(user, req.Password) {
+		s.guard.fail(ctx, account)
+		return nil, errorx.New(ErrInvalidCredentials)
+	}
+	return s.issue(ctx, user)
+}
+
+// ChangePassword 修改当前登录用户的密码，用户由认证拦截器写入 ctx 的 JWT subject 确定
+// 原密码错误计入登录失败次数；已签发的 token 在过期前仍然有效
+func (s *Server) ChangePassword(ctx context.Context, req *pb.ChangePasswordRequest) (*pb.ChangePasswordResponse, error) {
+	claims, ok := middleware.ClaimsFromContext(ctx)
+	if !ok {
+		return nil, errorx.New(errno.ErrUnauthenticated)
+	}
+	id, err := strconv.ParseInt(claims.Subject, 10, 64)
+	if err != nil {
+		return nil, errorx.WrapByCode(err, errno.ErrUnauthenticated)
+	}
+	hash, err := hashPassword(req.NewPassword)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.repo.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	account := "user:" + claims.Subject
+	if err := s.guard.check(ctx, account); err != nil {
+		return nil, err
+	}
+	if !checkPassword(user, req.OldPassword) {
+		s.guard.fail(ctx, account)
+		return nil, errorx.New(ErrInvalidCredentials)
+	}
+
+	if err := s.repo.UpdatePassword(ctx, id, hash); err != nil {
+		return nil, err
+	}
+	hlog.CtxInfof(ctx, "[User] user %d changed password", id)
+	return &pb.ChangePasswordResponse{Success: true}, nil
+}
+
+// issue 为用户签发 token，subject 为用户 ID，声明中携带用户的角色
+func (s *Server) issue(ctx context.Context, user *User) (*pb.AuthResponse, error) {
+	pair, err := s.sessions.Issue(ctx, strconv.FormatInt(user.ID, 10), user.RoleList()...)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.AuthResponse{
+		UserId:       user.ID,
+		AccessToken:  pair.AccessToken,
+		RefreshToken: pair.RefreshToken,
+		TokenType:    pair.TokenType,
+		ExpiresIn:    pair.ExpiresIn,
+	}, nil
+}
+
+// hashPassword 校验密码长度并计算 bcrypt 哈希，cost 读取环境变量 USER_BCRYPT_COST，默认 bcrypt.DefaultCost
+func hashPassword(password string) (string, error) {
+	if len(password) < minPasswordLen || len(password) > maxPasswordLen {
+		return "", errorx.New(errno.ErrInvalidParam, errorx.Extra("reason", fmt.Sprintf("password must be %d-%d bytes", minPasswordLen, maxPasswordLen)))
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), envkey.GetIntD("USER_BCRYPT_COST", bcrypt.DefaultCost))
+	if err != nil {
+		return "", err
+	}
+	return string(hash), nil
+}
+
+// checkPassword 比较密码，user 为 nil 或没有设置密码时同样执行一次比较并返回 false
+func checkPassword(user *User, password string) bool {
+	if user == nil || user.PasswordHash == "" {
+		_ = bcrypt.CompareHashAndPassword(dummyHash, []byte(password))
+		return false
+	}
+	return bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)) == nil
+}
+
+func errCodeOf(err error) int32 {
+	var se errorx.StatusError
+	if errors.As(err, &se) {
+		return se.Code()
+	}
+	return 0
+}
+
+// loginGuard 按账号限制登录失败次数，为 nil 时不限制
+// 令牌桶只在失败时消耗，锁定状态单独记录，使锁定期间即使密码正确也无法登录
+type loginGuard struct {
+	store   cache.Cmdable
+	limiter ratelimit.Limiter
+	limit   ratelimit.Limit
+}
+
+// check 账号处于锁定期时返回 ErrTooManyLoginAttempts，查询失败时放行
+func (g *loginGuard) check(ctx context.Context, account string) error {
+	if g == nil {
+		return nil
+	}
+	ttl, err := g.store.PTTL(ctx, g.lockKey(account)).Result()
+	if err != nil {
+		hlog.CtxWarnf(ctx, "[User] check login lock for %s failed, allow login: %v", account, err)
+		return nil
+	}
+	if ttl <= 0 {
+		return nil
+	}
+	return errorx.New(ErrTooManyLoginAttempts,
+		errorx.KV("retry_after", strconv.FormatInt(int64(math.Ceil(ttl.Seconds())), 10)))
+}
+
+// fail 记录一次失败，令牌耗尽时锁定账号直到可以再次取得令牌
+func (g *loginGuard) fail(ctx context.Context, account string) {
+	if g == nil {
+		return
+	}
+	res, err := g.limiter.Allow(ctx, "login:"+account, g.limit)
+	if err != nil {
+		hlog.CtxWarnf(ctx, "[User] count login failure for %s failed: %v", account, err)
+		return
+	}
+	if res.Allowed {
+		return
+	}
+	lock := max(res.RetryAfter, time.Second)
+	if err := g.store.Set(ctx, g.lockKey(account), 1, lock).Err(); err != nil {
+		hlog.CtxWarnf(ctx, "[User] lock login for %s failed: %v", account, err)
+		return
+	}
+	hlog.CtxWarnf(ctx, "[User] too many failed logins for %s, locked for %s", account, lock)
+}
+
+func (g *loginGuard) lockKey(account string) string {
+	return "login:lock:" + account
+}
@@ -0,0 +1,141 @@
+package user
+
+import (
+	"context"
+	"testing"
+
+	pb "github.com/ZampoRen/go-server-comon/api/model/user"
+	"github.com/ZampoRen/go-server-comon/internal/errno"
+	"github.com/ZampoRen/go-server-comon/internal/infra/cache/impl/memory"
+	"github.com/ZampoRen/go-server-comon/internal/infra/ratelimit"
+	storagememory "github.com/ZampoRen/go-server-comon/internal/infra/storage/impl/memory"
+	"github.com/ZampoRen/go-server-comon/internal/middleware"
+)
+
+// TestAuth 测试注册、登录、登录失败锁定与修改密码
+func TestAuth(t *testing.T) {
+	t.Setenv("USER_BCRYPT_COST", "4")
+	ctx := context.Background()
+	store := memory.New()
+	sessions, err := middleware.NewSessionManager(store, middleware.WithSessionSecret([]byte("secret")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := newTestServer(t)
+	WithSessions(sessions)(s)
+	WithLoginLimit(store, ratelimit.NewLocal(), ratelimit.Limit{Rate: 0.001, Burst: 2})(s)
+
+	reg, err := s.Register(ctx, &pb.RegisterRequest{Username: "alice", Email: "alice@example.com", Password: "password1"})
+	if err != nil || reg.AccessToken == "" {
+		t.Fatalf("Register() = %v, %v", reg, err)
+	}
+
+	t.Run("参数错误", func(t *testing.T) {
+		_, err := s.Register(ctx, &pb.RegisterRequest{Username: "bob", Email: "bob", Password: "password1"})
+		if errCodeOf(err) != errno.ErrInvalidParam {
+			t.Fatalf("Register() error = %v, want code %d", err, errno.ErrInvalidParam)
+		}
+		_, err = s.Register(ctx, &pb.RegisterRequest{Username: "bob", Email: "bob@example.com", Password: "short"})
+		if errCodeOf(err) != errno.ErrInvalidParam {
+			t.Fatalf("Register() error = %v, want code %d", err, errno.ErrInvalidParam)
+		}
+		if _, err = s.Login(ctx, &pb.LoginRequest{Username: "alice"}); errCodeOf(err) != errno.ErrInvalidParam {
+			t.Fatalf("Login() error = %v, want code %d", err, errno.ErrInvalidParam)
+		}
+	})
+
+	t.Run("用户名与邮箱登录", func(t *testing.T) {
+		for _, login := range []string{"alice", "alice@example.com"} {
+			resp, err := s.Login(ctx, &pb.LoginRequest{Username: login, Password: "password1"})
+			if err != nil || resp.UserId != reg.UserId {
+				t.Fatalf("Login(%s) = %v, %v", login, resp, err)
+			}
+		}
+	})
+
+	t.Run("token 可通过认证", func(t *testing.T) {
+		claims, err := sessions.Authenticate(ctx, reg.AccessToken)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ctx := middleware.ContextWithClaims(ctx, claims)
+		if _, err := s.ChangePassword(ctx, &pb.ChangePasswordRequest{OldPassword: "password1", NewPassword: "password2"}); err != nil {
+			t.Fatalf("ChangePassword() error = %v", err)
+		}
+		if _, err := s.Login(ctx, &pb.LoginRequest{Username: "alice", Password: "password2"}); err != nil {
+			t.Fatalf("Login() with new password error = %v", err)
+		}
+	})
+
+	t.Run("失败次数过多后锁定", func(t *testing.T) {
+		for i := 0; i < 3; i++ {
+			_, err := s.Login(ctx, &pb.LoginRequest{Username: "alice", Password: "wrong-password"})
+			if errCodeOf(err) != ErrInvalidCredentials {
+				t.Fatalf("Login() #%d error = %v, want code %d", i, err, ErrInvalidCredentials)
+			}
+		}
+		_, err := s.Login(ctx, &pb.LoginRequest{Username: "alice", Password: "password2"})
+		if errCodeOf(err) != ErrTooManyLoginAttempts {
+			t.Fatalf("Login() error = %v, want code %d", err, ErrTooManyLoginAttempts)
+		}
+	})
+
+	t.Run("未登录不能修改密码", func(t *testing.T) {
+		if _, err := s.ChangePassword(ctx, &pb.ChangePasswordRequest{OldPassword: "password2", NewPassword: "password3"}); err == nil {
+			t.Fatal("ChangePassword() without claims should fail")
+		}
+	})
+}
+
+// TestAdminLogin 测试管理员登录后使用签发的 token 导出用户，角色变更在刷新 token 后生效
+func TestAdminLogin(t *testing.T) {
+	t.Setenv("USER_BCRYPT_COST", "4")
+	ctx := context.Background()
+	s := newTestServer(t)
+	s.store = storagememory.New()
+	sessions, err := middleware.NewSessionManager(memory.New(),
+		middleware.WithSessionSecret([]byte("secret")), middleware.WithSessionRoles(s.repo.Roles))
+	if err != nil {
+		t.Fatal(err)
+	}
+	WithSessions(sessions)(s)
+
+	reg, err := s.Register(ctx, &pb.RegisterRequest{Username: "root", Email: "root@example.com", Password: "password1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.repo.UpdateRoles(ctx, reg.UserId, RoleAdmin); err != nil {
+		t.Fatal(err)
+	}
+	login, err := s.Login(ctx, &pb.LoginRequest{Username: "root", Password: "password1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	export := func(token string) error {
+		claims, err := sessions.Authenticate(ctx, token)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = s.ExportUsers(middleware.ContextWithClaims(ctx, claims), &pb.ExportUsersRequest{})
+		return err
+	}
+
+	t.Run("管理员 token 可以导出", func(t *testing.T) {
+		if err := export(login.AccessToken); err != nil {
+			t.Fatalf("ExportUsers() error = %v", err)
+		}
+	})
+
+	t.Run("撤销角色后刷新的 token 不能导出", func(t *testing.T) {
+		if err := s.repo.UpdateRoles(ctx, reg.UserId); err != nil {
+			t.Fatal(err)
+		}
+		pair, err := sessions.Refresh(ctx, login.RefreshToken)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := export(pair.AccessToken); errCodeOf(err) != errno.ErrPermissionDenied {
+			t.Fatalf("ExportUsers() error = %v, want code %d", err, errno.ErrPermissionDenied)
+		}
+	})
+}
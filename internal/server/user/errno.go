@@ -0,0 +1,30 @@
+package user
+
+import (
+	"net/http"
+
+	"github.com/ZampoRen/go-server-comon/internal/errno"
+	"github.com/ZampoRen/go-server-comon/pkg/errorx/code"
+)
+
+// 用户服务错误码 2001xx
+const (
+	// ErrUserNotFound 用户不存在
+	ErrUserNotFound int32 = 200100
+	// ErrUsernameTaken 用户名已被使用
+	ErrUsernameTaken int32 = 200101
+	// ErrEmailTaken 邮箱已被注册
+	ErrEmailTaken int32 = 200102
+	// ErrInvalidCredentials 用户名或密码错误
+	ErrInvalidCredentials int32 = 200103
+	// ErrTooManyLoginAttempts 登录失败次数过多，账号暂时锁定
+	ErrTooManyLoginAttempts int32 = 200104
+)
+
+func init() {
+	errno.Register(ErrUserNotFound, "用户 {user_id} 不存在", http.StatusNotFound, code.WithAffectStability(false))
+	errno.Register(ErrUsernameTaken, "用户名 {username} 已被使用", http.StatusConflict, code.WithAffectStability(false))
+	errno.Register(ErrEmailTaken, "邮箱 {email} 已被注册", http.StatusConflict, code.WithAffectStability(false))
+	errno.Register(ErrInvalidCredentials, "用户名或密码错误", http.StatusUnauthorized, code.WithAffectStability(false))
+	errno.Register(ErrTooManyLoginAttempts, "登录失败次数过多，请 {retry_after} 秒后重试", http.StatusTooManyRequests, code.WithAffectStability(false))
+}
@@ -0,0 +1,269 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/common/hlog"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+
+	pb "github.com/ZampoRen/go-server-comon/api/model/user"
+	"github.com/ZampoRen/go-server-comon/internal/infra/cache"
+)
+
+var (
+	// ErrWatchHubClosed WatchHub 已关闭，订阅被结束
+	ErrWatchHubClosed = errors.New("user: watch hub closed")
+	// ErrSubscriberTooSlow 订阅者消费过慢，缓冲区已满，订阅被结束，订阅者应重新订阅并全量刷新缓存
+	ErrSubscriberTooSlow = errors.New("user: event subscriber too slow")
+)
+
+// WatchUsers 推送用户变更事件，直到客户端取消、服务停止或消费过慢
+// 未配置 WithWatchHub 时返回 Unavailable，消费过慢时返回 ResourceExhausted，客户端应重新订阅
+func (s *Server) WatchUsers(req *pb.WatchUsersRequest, stream grpc.ServerStreamingServer[pb.UserEvent]) error {
+	if s.watchers == nil {
+		return status.Error(codes.Unavailable, "user events are not enabled")
+	}
+	sub, err := s.watchers.Subscribe()
+	if err != nil {
+		return status.Error(codes.Unavailable, err.Error())
+	}
+	defer sub.Close()
+
+	ids := make(map[int64]struct{}, len(req.UserIds))
+	for _, id := range req.UserIds {
+		ids[id] = struct{}{}
+	}
+
+	ctx := stream.Context()
+	for {
+		select {
+		case <-ctx.Done():
+			return status.FromContextError(ctx.Err()).Err()
+		case e, ok := <-sub.C():
+			if !ok {
+				if errors.Is(sub.Err(), ErrSubscriberTooSlow) {
+					return status.Error(codes.ResourceExhausted, sub.Err().Error())
+				}
+				return status.Error(codes.Unavailable, "user event stream closed")
+			}
+			if _, ok := ids[e.UserId]; len(ids) > 0 && !ok {
+				continue
+			}
+			if err := stream.Send(e); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// WatchHubOption WatchHub 选项
+type WatchHubOption func(o *watchHubOption)
+
+type watchHubOption struct {
+	streams   cache.Streams
+	stream    string
+	maxLen    int64
+	bufferLen int
+}
+
+// WithRedisStream 通过 Redis Stream 在多个实例之间分发事件，maxLen 为流的近似最大长度，<= 0 时不裁剪
+// 启用后发布的事件写入流，由各实例的读取协程分发给本地订阅者，任一实例上的变更都会推送给所有实例的订阅者
+func WithRedisStream(streams cache.Streams, stream string, maxLen int64) WatchHubOption {
+	return func(o *watchHubOption) {
+		o.streams, o.stream, o.maxLen = streams, stream, maxLen
+	}
+}
+
+// WithSubscriberBuffer 设置每个订阅者的缓冲区大小，默认 256
+func WithSubscriberBuffer(n int) WatchHubOption {
+	return func(o *watchHubOption) {
+		if n > 0 {
+			o.bufferLen = n
+		}
+	}
+}
+
+// WatchHub 将用户变更事件推送给 WatchUsers 的订阅者，默认只推送本实例上的变更
+//
+//	bus := eventbus.New()
+//	hub := user.NewWatchHub(user.WithRedisStream(rdb.(cache.Streams), "user:events", 10000))
+//	eventbus.Subscribe(bus, hub.Publish)
+//	svc := user.NewServer(user.NewRepository(db, user.WithEventBus(bus)), user.WithWatchHub(hub))
+//	lc.Append(lifecycle.Hook{Name: "user-events", OnStart: hub.Start, OnStop: hub.Stop})
+//
+// 仓储通过 pkg/eventbus 在进程内发布事件，缓存失效、重建索引等模块同样订阅该总线，WatchHub 只是其中一个订阅者。
+// 它不直接使用 eventbus 分发，是因为订阅者是远端的 gRPC 流：事件需要经 Redis Stream 在实例间共享，
+// 且消费过慢的订阅者应被结束，而不是像 eventbus 的异步队列那样阻塞发布方。
+// 事件不保证送达：订阅者重新订阅后应全量刷新缓存
+type WatchHub struct {
+	o *watchHubOption
+
+	mu     sync.Mutex
+	subs   map[*WatchSubscription]struct{}
+	closed bool
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewWatchHub 创建 WatchHub
+func NewWatchHub(opts ...WatchHubOption) *WatchHub {
+	o := &watchHubOption{bufferLen: 256}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return &WatchHub{o: o, subs: make(map[*WatchSubscription]struct{})}
+}
+
+// Start 启用 Redis Stream 时启动读取协程，只读取启动之后写入的事件
+func (b *WatchHub) Start(ctx context.Context) error {
+	if b.o.streams == nil {
+		return nil
+	}
+	ctx, b.cancel = context.WithCancel(context.WithoutCancel(ctx))
+	b.done = make(chan struct{})
+	go b.consume(ctx)
+	return nil
+}
+
+// Stop 停止读取协程并结束所有订阅
+func (b *WatchHub) Stop(ctx context.Context) error {
+	b.Shutdown()
+	if b.cancel == nil {
+		return nil
+	}
+	b.cancel()
+	select {
+	case <-b.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Shutdown 结束所有订阅并拒绝新的订阅，使 WatchUsers 返回，gRPC 服务才能优雅停止
+// 之后发布的事件仍会写入 Redis Stream
+func (b *WatchHub) Shutdown() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.closed = true
+	for sub := range b.subs {
+		sub.end(ErrWatchHubClosed)
+	}
+	clear(b.subs)
+}
+
+// Publish 推送事件，写入 Redis Stream 失败时退化为只在本地分发，总是返回 nil，
+// 签名与 eventbus 的处理函数一致，可直接订阅仓储发布的事件
+func (b *WatchHub) Publish(ctx context.Context, e *pb.UserEvent) error {
+	if b.o.streams != nil {
+		payload, err := proto.Marshal(e)
+		if err == nil {
+			_, err = b.o.streams.XAdd(ctx, b.o.stream, b.o.maxLen, map[string]interface{}{"event": payload})
+		}
+		if err == nil {
+			return nil
+		}
+		hlog.CtxWarnf(ctx, "[UserEvents] publish to stream %s failed, dispatch locally: %v", b.o.stream, err)
+	}
+	b.dispatch(e)
+	return nil
+}
+
+// Subscribe 订阅事件，已关闭时返回 ErrWatchHubClosed
+func (b *WatchHub) Subscribe() (*WatchSubscription, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return nil, ErrWatchHubClosed
+	}
+	sub := &WatchSubscription{bus: b, ch: make(chan *pb.UserEvent, b.o.bufferLen)}
+	b.subs[sub] = struct{}{}
+	return sub, nil
+}
+
+// dispatch 分发给本地订阅者，缓冲区已满的订阅者被结束
+func (b *WatchHub) dispatch(e *pb.UserEvent) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for sub := range b.subs {
+		select {
+		case sub.ch <- e:
+		default:
+			sub.end(ErrSubscriberTooSlow)
+			delete(b.subs, sub)
+		}
+	}
+}
+
+// consume 循环读取 Redis Stream 并分发，读取失败时等待 1s 后重试
+func (b *WatchHub) consume(ctx context.Context) {
+	defer close(b.done)
+	lastID := "$"
+	for ctx.Err() == nil {
+		msgs, err := b.o.streams.XRead(ctx, b.o.stream, lastID, 100, 5*time.Second)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			hlog.CtxWarnf(ctx, "[UserEvents] read stream %s failed: %v", b.o.stream, err)
+			select {
+			case <-ctx.Done():
+			case <-time.After(time.Second):
+			}
+			continue
+		}
+		for _, m := range msgs {
+			lastID = m.ID
+			payload, _ := m.Values["event"].(string)
+			e := &pb.UserEvent{}
+			if err := proto.Unmarshal([]byte(payload), e); err != nil {
+				hlog.CtxWarnf(ctx, "[UserEvents] invalid event %s on %s: %v", m.ID, b.o.stream, err)
+				continue
+			}
+			b.dispatch(e)
+		}
+	}
+}
+
+// WatchSubscription WatchHub 的订阅
+type WatchSubscription struct {
+	bus  *WatchHub
+	ch   chan *pb.UserEvent
+	err  error
+	once sync.Once
+}
+
+// C 返回接收事件的通道，订阅结束后通道被关闭，原因通过 Err 获取
+func (s *WatchSubscription) C() <-chan *pb.UserEvent {
+	return s.ch
+}
+
+// Err 返回订阅结束的原因，主动 Close 时为 nil
+func (s *WatchSubscription) Err() error {
+	s.bus.mu.Lock()
+	defer s.bus.mu.Unlock()
+	return s.err
+}
+
+// Close 取消订阅
+func (s *WatchSubscription) Close() {
+	s.bus.mu.Lock()
+	defer s.bus.mu.Unlock()
+	delete(s.bus.subs, s)
+	s.end(nil)
+}
+
+// end 结束订阅，调用方需持有 bus.mu
+func (s *WatchSubscription) end(err error) {
+	s.once.Do(func() {
+		s.err = err
+		close(s.ch)
+	})
+}
@@ -0,0 +1,117 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/ZampoRen/go-server-comon/api/model/user"
+	"github.com/ZampoRen/go-server-comon/pkg/eventbus"
+)
+
+// watchStream 将 WatchUsers 推送的事件写入通道
+type watchStream struct {
+	grpc.ServerStream
+	ctx    context.Context
+	events chan *pb.UserEvent
+}
+
+func (w *watchStream) Context() context.Context { return w.ctx }
+
+func (w *watchStream) Send(e *pb.UserEvent) error {
+	w.events <- e
+	return nil
+}
+
+// TestWatchUsers 测试用户变更事件的推送、过滤与订阅结束
+func TestWatchUsers(t *testing.T) {
+	events := eventbus.New()
+	bus := NewWatchHub(WithSubscriberBuffer(1))
+	eventbus.Subscribe(events, bus.Publish)
+	s := newTestServer(t, WithEventBus(events))
+	s.watchers = bus
+	ctx := context.Background()
+
+	alice, err := s.CreateUser(ctx, &pb.CreateUserRequest{Username: "alice", Email: "alice@example.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	watchCtx, cancel := context.WithCancel(ctx)
+	stream := &watchStream{ctx: watchCtx, events: make(chan *pb.UserEvent, 10)}
+	done := make(chan error, 1)
+	go func() {
+		done <- s.WatchUsers(&pb.WatchUsersRequest{UserIds: []int64{alice.UserId}}, stream)
+	}()
+	waitSubscribers(t, bus, 1)
+
+	t.Run("只推送订阅的用户", func(t *testing.T) {
+		if _, err := s.CreateUser(ctx, &pb.CreateUserRequest{Username: "bob", Email: "bob@example.com"}); err != nil {
+			t.Fatal(err)
+		}
+		if err := s.repo.Delete(ctx, alice.UserId); err != nil {
+			t.Fatal(err)
+		}
+		select {
+		case e := <-stream.events:
+			if e.Type != pb.UserEventType_USER_DELETED || e.UserId != alice.UserId {
+				t.Fatalf("event = %v, want USER_DELETED of %d", e, alice.UserId)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("no event received")
+		}
+	})
+
+	t.Run("客户端取消后返回", func(t *testing.T) {
+		cancel()
+		if err := <-done; status.Code(err) != codes.Canceled {
+			t.Fatalf("WatchUsers() error = %v, want Canceled", err)
+		}
+		waitSubscribers(t, bus, 0)
+	})
+
+	t.Run("消费过慢与关闭", func(t *testing.T) {
+		slow, err := bus.Subscribe()
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := 0; i < 2; i++ {
+			_ = bus.Publish(ctx, &pb.UserEvent{Type: pb.UserEventType_USER_UPDATED, UserId: 1})
+		}
+		<-slow.C()
+		if _, ok := <-slow.C(); ok || !errors.Is(slow.Err(), ErrSubscriberTooSlow) {
+			t.Fatalf("slow subscriber err = %v, want ErrSubscriberTooSlow", slow.Err())
+		}
+
+		closed, _ := bus.Subscribe()
+		if err := bus.Stop(ctx); err != nil {
+			t.Fatal(err)
+		}
+		if !errors.Is(closed.Err(), ErrWatchHubClosed) {
+			t.Fatalf("subscriber err = %v, want ErrWatchHubClosed", closed.Err())
+		}
+		if _, err := bus.Subscribe(); !errors.Is(err, ErrWatchHubClosed) {
+			t.Fatalf("Subscribe() after Stop error = %v", err)
+		}
+	})
+}
+
+// waitSubscribers 等待订阅者数量变为 n
+func waitSubscribers(t *testing.T, bus *WatchHub, n int) {
+	t.Helper()
+	for i := 0; i < 100; i++ {
+		bus.mu.Lock()
+		got := len(bus.subs)
+		bus.mu.Unlock()
+		if got == n {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("subscribers != %d", n)
+}
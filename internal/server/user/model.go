@@ -0,0 +1,41 @@
+package user
+
+import (
+	"strings"
+
+	pb "github.com/ZampoRen/go-server-comon/api/model/user"
+	"github.com/ZampoRen/go-server-comon/internal/infra/orm/model"
+)
+
+// User 用户表，用户名与邮箱唯一（包括已软删除的记录）
+type User struct {
+	model.Base
+	Username string `gorm:"size:64;not null;uniqueIndex" json:"username"`
+	Email    string `gorm:"size:255;not null;uniqueIndex" json:"email"`
+	// PasswordHash bcrypt 哈希，通过 CreateUser 或导入创建的用户为空，无法登录
+	PasswordHash string `gorm:"size:255;not null;default:''" json:"-"`
+	// Roles 逗号分隔的角色，如 admin，登录与刷新 token 时写入 JWT 声明
+	Roles string `gorm:"size:255;not null;default:''" json:"-"`
+}
+
+// TableName 表名
+func (User) TableName() string {
+	return "users"
+}
+
+// RoleList 返回用户的角色列表
+func (u *User) RoleList() []string {
+	if u.Roles == "" {
+		return nil
+	}
+	return strings.Split(u.Roles, ",")
+}
+
+// toPB 转换为接口中的用户信息
+func (u *User) toPB() *pb.UserInfo {
+	return &pb.UserInfo{
+		UserId:   u.ID,
+		Username: u.Username,
+		Email:    u.Email,
+	}
+}
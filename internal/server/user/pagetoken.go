@@ -0,0 +1,78 @@
+package user
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// orderColumns ListUsersRequest.order_by 与排序字段的对应关系
+var orderColumns = map[string]string{
+	"":           OrderByID,
+	"user_id":    OrderByID,
+	"username":   OrderByUsername,
+	"created_at": OrderByCreatedAt,
+}
+
+// pageToken 翻页令牌的内容，编码为 base64 的 JSON，对调用方不透明
+// 令牌记录了生成时的排序与过滤条件，条件变化后继续使用旧令牌会被拒绝
+type pageToken struct {
+	Query     string    `json:"q"`
+	ID        int64     `json:"id"`
+	Username  string    `json:"u,omitempty"`
+	CreatedAt time.Time `json:"t,omitzero"`
+}
+
+// parseListQuery 解析排序与过滤条件，order_by 不合法时返回 InvalidArgument
+func parseListQuery(username, email, orderBy string) (ListQuery, error) {
+	q := ListQuery{UsernamePrefix: username, EmailPrefix: email}
+	field, desc := strings.CutPrefix(orderBy, "-")
+	column, ok := orderColumns[field]
+	if !ok {
+		return q, status.Errorf(codes.InvalidArgument, "unsupported order_by %q", orderBy)
+	}
+	q.OrderBy, q.Desc = column, desc
+	return q, nil
+}
+
+// fingerprint 返回排序与过滤条件的标识，用于校验令牌
+func (q ListQuery) fingerprint() string {
+	dir := "asc"
+	if q.Desc {
+		dir = "desc"
+	}
+	return strings.Join([]string{q.OrderBy, dir, q.UsernamePrefix, q.EmailPrefix}, "\x00")
+}
+
+// encodePageToken 以当前页的最后一个用户生成下一页的令牌
+func encodePageToken(q ListQuery, last *User) string {
+	b, _ := json.Marshal(pageToken{
+		Query:     q.fingerprint(),
+		ID:        last.ID,
+		Username:  last.Username,
+		CreatedAt: last.CreatedAt,
+	})
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// decodePageToken 解析令牌并返回上一页的最后一个用户，令牌无效或与 q 的条件不一致时返回 InvalidArgument
+func decodePageToken(q ListQuery, token string) (*User, error) {
+	b, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid page_token")
+	}
+	var t pageToken
+	if err := json.Unmarshal(b, &t); err != nil || t.ID <= 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid page_token")
+	}
+	if t.Query != q.fingerprint() {
+		return nil, status.Errorf(codes.InvalidArgument, "page_token does not match order_by or filters")
+	}
+	u := &User{Username: t.Username}
+	u.ID, u.CreatedAt = t.ID, t.CreatedAt
+	return u, nil
+}
@@ -0,0 +1,296 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/common/hlog"
+
+	pb "github.com/ZampoRen/go-server-comon/api/model/user"
+	"github.com/ZampoRen/go-server-comon/internal/errno"
+	"github.com/ZampoRen/go-server-comon/internal/infra/orm"
+	"github.com/ZampoRen/go-server-comon/internal/infra/orm/repo"
+	"github.com/ZampoRen/go-server-comon/pkg/errorx"
+	"github.com/ZampoRen/go-server-comon/pkg/eventbus"
+	"github.com/ZampoRen/go-server-comon/pkg/localcache"
+)
+
+// CacheKeyPrefix 用户本地缓存的键前缀，键为前缀加用户 ID
+const CacheKeyPrefix = "user:"
+
+// existsQueryBatch 批量检查唯一性时单条 IN 查询的最大参数数量
+const existsQueryBatch = 500
+
+// RepositoryOption 仓储选项
+type RepositoryOption func(o *repositoryOption)
+
+type repositoryOption struct {
+	cache  localcache.Cache[*User]
+	events *eventbus.Bus
+}
+
+// WithCache 为按 ID 查询启用本地缓存，写操作会删除对应的键
+// 多实例部署时应通过 localcache.WithDeleteKeyBefore 与 cache.Invalidator 通知其他实例删除
+func WithCache(c localcache.Cache[*User]) RepositoryOption {
+	return func(o *repositoryOption) {
+		o.cache = c
+	}
+}
+
+// WithEventBus 写操作成功后向进程内事件总线发布 *pb.UserEvent，WatchHub、缓存失效、搜索索引等通过 eventbus.Subscribe 订阅
+func WithEventBus(bus *eventbus.Bus) RepositoryOption {
+	return func(o *repositoryOption) {
+		o.events = bus
+	}
+}
+
+// Repository 用户仓储，返回的错误中不存在与唯一键冲突已转换为本服务的错误码
+type Repository struct {
+	db     *orm.DB
+	users  repo.Repository[User, int64]
+	events *eventbus.Bus
+}
+
+// NewRepository 创建用户仓储
+func NewRepository(db *orm.DB, opts ...RepositoryOption) *Repository {
+	o := &repositoryOption{}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	var repoOpts []repo.Option[User, int64]
+	if o.cache != nil {
+		repoOpts = append(repoOpts, repo.WithCache[User, int64](o.cache, CacheKeyPrefix))
+	}
+	return &Repository{db: db, users: repo.New[User, int64](db, repoOpts...), events: o.events}
+}
+
+// Get 按 ID 查询用户，不存在时返回 ErrUserNotFound
+func (r *Repository) Get(ctx context.Context, id int64) (*User, error) {
+	u, err := r.users.GetByID(ctx, id)
+	if errors.Is(err, repo.ErrNotFound) {
+		return nil, errorx.New(ErrUserNotFound, errorx.KV("user_id", strconv.FormatInt(id, 10)))
+	}
+	return u, err
+}
+
+// GetByLogin 按用户名或邮箱（包含 @ 时）查询用户，不存在时返回 ErrUserNotFound
+func (r *Repository) GetByLogin(ctx context.Context, login string) (*User, error) {
+	column := "username"
+	if strings.Contains(login, "@") {
+		column = "email"
+	}
+	u := &User{}
+	err := r.users.DB(ctx).Where(column+" = ?", login).Take(u).Error
+	if errors.Is(err, repo.ErrNotFound) {
+		return nil, errorx.New(ErrUserNotFound, errorx.KV("user_id", login))
+	}
+	if err != nil {
+		return nil, err
+	}
+	return u, nil
+}
+
+// UpdatePassword 更新密码哈希，并删除本地缓存
+func (r *Repository) UpdatePassword(ctx context.Context, id int64, hash string) error {
+	if err := r.users.UpdateFields(ctx, id, map[string]any{"password_hash": hash}); err != nil {
+		return err
+	}
+	r.emit(ctx, pb.UserEventType_USER_UPDATED, id, nil)
+	return nil
+}
+
+// UpdateRoles 更新用户的角色，已签发的 token 在刷新或重新登录后生效
+func (r *Repository) UpdateRoles(ctx context.Context, id int64, roles ...string) error {
+	if err := r.users.UpdateFields(ctx, id, map[string]any{"roles": strings.Join(roles, ",")}); err != nil {
+		return err
+	}
+	r.emit(ctx, pb.UserEventType_USER_UPDATED, id, nil)
+	return nil
+}
+
+// Roles 按 JWT subject（用户 ID）查询用户的角色，用于 middleware.WithSessionRoles 在刷新 token 时重新加载
+func (r *Repository) Roles(ctx context.Context, subject string) ([]string, error) {
+	id, err := strconv.ParseInt(subject, 10, 64)
+	if err != nil {
+		return nil, errorx.WrapByCode(err, errno.ErrUnauthenticated)
+	}
+	u, err := r.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return u.RoleList(), nil
+}
+
+// Delete 软删除用户，并删除本地缓存
+func (r *Repository) Delete(ctx context.Context, id int64) error {
+	if err := r.users.Delete(ctx, id); err != nil {
+		return err
+	}
+	r.emit(ctx, pb.UserEventType_USER_DELETED, id, nil)
+	return nil
+}
+
+// Create 创建用户，用户名或邮箱已存在时返回 ErrUsernameTaken 或 ErrEmailTaken
+func (r *Repository) Create(ctx context.Context, u *User) error {
+	err := r.users.Create(ctx, u)
+	if orm.IsDuplicateKey(r.db, err) {
+		return r.duplicateError(ctx, u, err)
+	}
+	if err != nil {
+		return err
+	}
+	r.emit(ctx, pb.UserEventType_USER_CREATED, u.ID, u)
+	return nil
+}
+
+// BatchCreate 分批创建用户，任一用户的用户名或邮箱已存在时返回 errno.ErrConflict
+// 调用方应先通过 ExistingUsernames、ExistingEmails 过滤，冲突只会在并发写入时出现
+func (r *Repository) BatchCreate(ctx context.Context, users []*User, batchSize int) error {
+	err := r.users.BatchCreate(ctx, users, batchSize)
+	if orm.IsDuplicateKey(r.db, err) {
+		return errorx.WrapByCode(err, errno.ErrConflict)
+	}
+	if err != nil {
+		return err
+	}
+	for _, u := range users {
+		r.emit(ctx, pb.UserEventType_USER_CREATED, u.ID, u)
+	}
+	return nil
+}
+
+// 用户列表支持的排序字段
+const (
+	OrderByID        = "id"
+	OrderByUsername  = "username"
+	OrderByCreatedAt = "created_at"
+)
+
+// likeEscaper 转义 LIKE 通配符，使用 ! 作为转义字符以兼容 MySQL 与 SQLite
+var likeEscaper = strings.NewReplacer("!", "!!", "%", "!%", "_", "!_")
+
+// ListQuery 用户列表的过滤、排序与游标条件
+type ListQuery struct {
+	// UsernamePrefix 用户名前缀，为空时不过滤
+	UsernamePrefix string
+	// EmailPrefix 邮箱前缀，为空时不过滤
+	EmailPrefix string
+	// OrderBy 排序字段，为空时按 ID
+	OrderBy string
+	Desc    bool
+	// After 上一页的最后一个用户，只使用 ID 与排序字段，为 nil 时从第一条开始
+	After *User
+	Limit int
+}
+
+// ListPage 按游标（keyset）分页查询，排序字段相同时按 ID 排序，翻页时不会重复或遗漏
+func (r *Repository) ListPage(ctx context.Context, q ListQuery) ([]*User, error) {
+	column := q.OrderBy
+	if column == "" {
+		column = OrderByID
+	}
+	op, dir := ">", "ASC"
+	if q.Desc {
+		op, dir = "<", "DESC"
+	}
+
+	db := r.filter(r.users.DB(ctx), q)
+	if a := q.After; a != nil {
+		switch column {
+		case OrderByID:
+			db = db.Where("id "+op+" ?", a.ID)
+		case OrderByUsername:
+			db = db.Where("(username "+op+" ? OR (username = ? AND id "+op+" ?))", a.Username, a.Username, a.ID)
+		case OrderByCreatedAt:
+			db = db.Where("(created_at "+op+" ? OR (created_at = ? AND id "+op+" ?))", a.CreatedAt, a.CreatedAt, a.ID)
+		}
+	}
+	if column != OrderByID {
+		db = db.Order(column + " " + dir)
+	}
+
+	var users []*User
+	err := db.Order("id " + dir).Limit(q.Limit).Find(&users).Error
+	return users, err
+}
+
+// Count 返回满足过滤条件的用户数量，忽略排序与游标
+func (r *Repository) Count(ctx context.Context, q ListQuery) (int64, error) {
+	var total int64
+	err := r.filter(r.users.DB(ctx).Model(&User{}), q).Count(&total).Error
+	return total, err
+}
+
+// filter 追加用户名与邮箱前缀条件
+func (r *Repository) filter(db *orm.DB, q ListQuery) *orm.DB {
+	if q.UsernamePrefix != "" {
+		db = db.Where("username LIKE ? ESCAPE '!'", likeEscaper.Replace(q.UsernamePrefix)+"%")
+	}
+	if q.EmailPrefix != "" {
+		db = db.Where("email LIKE ? ESCAPE '!'", likeEscaper.Replace(q.EmailPrefix)+"%")
+	}
+	return db
+}
+
+// ListAfter 返回 ID 大于 afterID 的至多 limit 个用户，按 ID 升序，用于游标遍历
+func (r *Repository) ListAfter(ctx context.Context, afterID int64, limit int) ([]*User, error) {
+	var users []*User
+	err := r.users.DB(ctx).Where("id > ?", afterID).Order("id").Limit(limit).Find(&users).Error
+	return users, err
+}
+
+// ExistingUsernames 返回 usernames 中已被使用的用户名（包括已软删除的用户）
+func (r *Repository) ExistingUsernames(ctx context.Context, usernames []string) (map[string]struct{}, error) {
+	return r.existing(ctx, "username", usernames)
+}
+
+// ExistingEmails 返回 emails 中已被注册的邮箱（包括已软删除的用户）
+func (r *Repository) ExistingEmails(ctx context.Context, emails []string) (map[string]struct{}, error) {
+	return r.existing(ctx, "email", emails)
+}
+
+// existing 分批查询 column 取值在 values 中的记录，唯一索引对软删除的记录同样生效，因此不过滤 deleted_at
+func (r *Repository) existing(ctx context.Context, column string, values []string) (map[string]struct{}, error) {
+	found := make(map[string]struct{})
+	for start := 0; start < len(values); start += existsQueryBatch {
+		end := min(start+existsQueryBatch, len(values))
+		var hits []string
+		err := r.users.DB(ctx).Unscoped().Model(&User{}).
+			Where(column+" IN ?", values[start:end]).
+			Pluck(column, &hits).Error
+		if err != nil {
+			return nil, err
+		}
+		for _, v := range hits {
+			found[v] = struct{}{}
+		}
+	}
+	return found, nil
+}
+
+// duplicateError 唯一键冲突时查询冲突的字段，驱动的错误信息中索引名格式不一，不依赖解析
+func (r *Repository) duplicateError(ctx context.Context, u *User, cause error) error {
+	if taken, err := r.ExistingUsernames(ctx, []string{u.Username}); err == nil && len(taken) > 0 {
+		return errorx.WrapByCode(cause, ErrUsernameTaken, errorx.KV("username", u.Username))
+	}
+	return errorx.WrapByCode(cause, ErrEmailTaken, errorx.KV("email", u.Email))
+}
+
+// emit 发布用户变更事件，u 为 nil 时事件只包含用户 ID
+// 写操作已经提交，处理函数的错误只打印日志
+func (r *Repository) emit(ctx context.Context, typ pb.UserEventType, id int64, u *User) {
+	if r.events == nil {
+		return
+	}
+	e := &pb.UserEvent{Type: typ, UserId: id, Timestamp: time.Now().UnixMilli()}
+	if u != nil {
+		e.User = u.toPB()
+	}
+	if err := eventbus.Publish(ctx, r.events, e); err != nil {
+		hlog.CtxWarnf(ctx, "[User] publish user event failed: %v", err)
+	}
+}
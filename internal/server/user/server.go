@@ -2,102 +2,99 @@ package user
 
 import (
 	"context"
-	"sync"
 	"time"
 
-	"google.golang.org/grpc/codes"
-	"google.golang.org/grpc/status"
-
-	pb "go-server-comon/api/proto/user"
+	pb "github.com/ZampoRen/go-server-comon/api/model/user"
+	"github.com/ZampoRen/go-server-comon/internal/errno"
+	"github.com/ZampoRen/go-server-comon/internal/infra/storage"
+	"github.com/ZampoRen/go-server-comon/internal/middleware"
+	"github.com/ZampoRen/go-server-comon/pkg/errorx"
 )
 
 // Server implements the User gRPC server
 type Server struct {
 	pb.UnimplementedUserServer
-	mu     sync.RWMutex
-	users  map[int64]*pb.UserInfo
-	nextID int64
+	repo     *Repository
+	store    storage.Storage
+	sessions *middleware.SessionManager
+	guard    *loginGuard
+	watchers *WatchHub
+}
+
+// Option Server 选项
+type Option func(s *Server)
+
+// WithStorage 设置导入导出使用的对象存储
+func WithStorage(store storage.Storage) Option {
+	return func(s *Server) {
+		s.store = store
+	}
+}
+
+// WithWatchHub 设置 WatchUsers 使用的 WatchHub，未设置时 WatchUsers 返回 Unavailable
+func WithWatchHub(hub *WatchHub) Option {
+	return func(s *Server) {
+		s.watchers = hub
+	}
 }
 
 // NewServer creates a new User server instance
-func NewServer() *Server {
-	return &Server{
-		users:  make(map[int64]*pb.UserInfo),
-		nextID: 1,
+func NewServer(repo *Repository, opts ...Option) *Server {
+	s := &Server{repo: repo}
+	for _, opt := range opts {
+		opt(s)
 	}
+	return s
 }
 
-// GetUser 获取用户信息
+// GetUser 获取用户信息，user_id 无效时返回 errno.ErrInvalidParam，用户不存在时返回 ErrUserNotFound
 func (s *Server) GetUser(ctx context.Context, req *pb.GetUserRequest) (*pb.GetUserResponse, error) {
 	if req.UserId <= 0 {
-		return nil, status.Errorf(codes.InvalidArgument, "user_id must be greater than 0")
+		return nil, errorx.New(errno.ErrInvalidParam, errorx.Extra("reason", "user_id must be greater than 0"))
 	}
 
-	s.mu.RLock()
-	user, exists := s.users[req.UserId]
-	s.mu.RUnlock()
-
-	if !exists {
-		return nil, status.Errorf(codes.NotFound, "user with id %d not found", req.UserId)
+	user, err := s.repo.Get(ctx, req.UserId)
+	if err != nil {
+		return nil, err
 	}
 
 	return &pb.GetUserResponse{
-		UserId:    user.UserId,
+		UserId:    user.ID,
 		Username:  user.Username,
 		Email:     user.Email,
-		CreatedAt: time.Now().Format(time.RFC3339),
+		CreatedAt: user.CreatedAt.Format(time.RFC3339),
 	}, nil
 }
 
-// CreateUser 创建用户
+// CreateUser 创建用户，缺少用户名或邮箱时返回 errno.ErrInvalidParam，用户名或邮箱已存在时返回 ErrUsernameTaken 或 ErrEmailTaken
 func (s *Server) CreateUser(ctx context.Context, req *pb.CreateUserRequest) (*pb.CreateUserResponse, error) {
 	if req.Username == "" {
-		return nil, status.Errorf(codes.InvalidArgument, "username is required")
+		return nil, errorx.New(errno.ErrInvalidParam, errorx.Extra("reason", "username is required"))
 	}
 	if req.Email == "" {
-		return nil, status.Errorf(codes.InvalidArgument, "email is required")
-	}
-
-	s.mu.Lock()
-	defer s.mu.Unlock()
-
-	// 检查用户名是否已存在
-	for _, user := range s.users {
-		if user.Username == req.Username {
-			return nil, status.Errorf(codes.AlreadyExists, "username %s already exists", req.Username)
-		}
-		if user.Email == req.Email {
-			return nil, status.Errorf(codes.AlreadyExists, "email %s already exists", req.Email)
-		}
+		return nil, errorx.New(errno.ErrInvalidParam, errorx.Extra("reason", "email is required"))
 	}
 
-	// 创建新用户
-	userID := s.nextID
-	s.nextID++
-
-	newUser := &pb.UserInfo{
-		UserId:   userID,
-		Username: req.Username,
-		Email:    req.Email,
+	user := &User{Username: req.Username, Email: req.Email}
+	if err := s.repo.Create(ctx, user); err != nil {
+		return nil, err
 	}
 
-	s.users[userID] = newUser
-
 	return &pb.CreateUserResponse{
-		UserId:   userID,
-		Username: req.Username,
-		Email:    req.Email,
+		UserId:   user.ID,
+		Username: user.Username,
+		Email:    user.Email,
 		Success:  true,
 	}, nil
 }
 
-// ListUsers 获取用户列表
+// ListUsers 按游标分页获取用户列表，支持用户名、邮箱前缀过滤与排序，默认按 user_id 升序
+// 总数只在第一页返回，之后的页通过 page_token 翻页，令牌与 order_by、过滤条件绑定
+// 已废弃的 page 只接受 0 或 1，不带 page_token 请求后续页时返回 ErrInvalidParam，避免静默返回第一页
 func (s *Server) ListUsers(ctx context.Context, req *pb.ListUsersRequest) (*pb.ListUsersResponse, error) {
-	page := req.Page
-	if page <= 0 {
-		page = 1
+	if req.Page > 1 && req.PageToken == "" {
+		return nil, errorx.New(errno.ErrInvalidParam, errorx.Extra("reason", "page is deprecated, use page_token"))
 	}
-
 	pageSize := req.PageSize
 	if pageSize <= 0 {
 		pageSize = 10
@@ -106,33 +103,37 @@ func (s *Server) ListUsers(ctx context.Context, req *pb.ListUsersRequest) (*pb.L
 		pageSize = 100 // 限制最大页面大小
 	}
 
-	s.mu.RLock()
-	allUsers := make([]*pb.UserInfo, 0, len(s.users))
-	for _, user := range s.users {
-		allUsers = append(allUsers, user)
+	q, err := parseListQuery(req.Username, req.Email, req.OrderBy)
+	if err != nil {
+		return nil, err
 	}
-	total := int32(len(allUsers))
-	s.mu.RUnlock()
-
-	// 计算分页
-	start := (page - 1) * pageSize
-	end := start + pageSize
-
-	if start >= int32(len(allUsers)) {
-		return &pb.ListUsersResponse{
-			Users: []*pb.UserInfo{},
-			Total: total,
-			Page:  page,
-		}, nil
+	resp := &pb.ListUsersResponse{}
+	if req.PageToken != "" {
+		if q.After, err = decodePageToken(q, req.PageToken); err != nil {
+			return nil, err
+		}
+	} else {
+		total, err := s.repo.Count(ctx, q)
+		if err != nil {
+			return nil, err
+		}
+		resp.Total = int32(total)
 	}
 
-	if end > int32(len(allUsers)) {
-		end = int32(len(allUsers))
+	// 多取一条判断是否还有下一页
+	q.Limit = int(pageSize) + 1
+	users, err := s.repo.ListPage(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	if len(users) > int(pageSize) {
+		users = users[:pageSize]
+		resp.NextPageToken = encodePageToken(q, users[len(users)-1])
 	}
 
-	return &pb.ListUsersResponse{
-		Users: allUsers[start:end],
-		Total: total,
-		Page:  page,
-	}, nil
+	resp.Users = make([]*pb.UserInfo, 0, len(users))
+	for _, u := range users {
+		resp.Users = append(resp.Users, u.toPB())
+	}
+	return resp, nil
 }
@@ -0,0 +1,131 @@
+package user
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+
+	pb "github.com/ZampoRen/go-server-comon/api/model/user"
+	"github.com/ZampoRen/go-server-comon/internal/errno"
+	"github.com/ZampoRen/go-server-comon/internal/infra/orm/impl/sqlite"
+	"github.com/ZampoRen/go-server-comon/pkg/localcache"
+)
+
+func newTestServer(t *testing.T, opts ...RepositoryOption) *Server {
+	t.Helper()
+	db, err := sqlite.NewWithConfig("", &gorm.Config{Logger: gormlogger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&User{}); err != nil {
+		t.Fatal(err)
+	}
+	users := localcache.New[*User](localcache.WithLocalSlotNum(1), localcache.WithLocalSuccessTTL(time.Minute))
+	t.Cleanup(users.Stop)
+	return NewServer(NewRepository(db, append(opts, WithCache(users))...))
+}
+
+// TestServer 测试创建、查询、分页与错误码
+func TestServer(t *testing.T) {
+	ctx := context.Background()
+	s := newTestServer(t)
+
+	created, err := s.CreateUser(ctx, &pb.CreateUserRequest{Username: "alice", Email: "alice@example.com"})
+	if err != nil || created.UserId == 0 {
+		t.Fatalf("CreateUser() = %v, %v", created, err)
+	}
+	if _, err := s.CreateUser(ctx, &pb.CreateUserRequest{Username: "bob", Email: "bob@example.com"}); err != nil {
+		t.Fatalf("CreateUser() error = %v", err)
+	}
+
+	t.Run("按 ID 查询", func(t *testing.T) {
+		got, err := s.GetUser(ctx, &pb.GetUserRequest{UserId: created.UserId})
+		if err != nil || got.Username != "alice" || got.CreatedAt == "" {
+			t.Fatalf("GetUser() = %v, %v", got, err)
+		}
+	})
+
+	t.Run("用户不存在", func(t *testing.T) {
+		_, err := s.GetUser(ctx, &pb.GetUserRequest{UserId: 404})
+		if errCodeOf(err) != ErrUserNotFound {
+			t.Fatalf("GetUser() error = %v, want code %d", err, ErrUserNotFound)
+		}
+	})
+
+	t.Run("参数错误", func(t *testing.T) {
+		if _, err := s.GetUser(ctx, &pb.GetUserRequest{}); errCodeOf(err) != errno.ErrInvalidParam {
+			t.Fatalf("GetUser() error = %v, want code %d", err, errno.ErrInvalidParam)
+		}
+		if _, err := s.CreateUser(ctx, &pb.CreateUserRequest{Email: "dave@example.com"}); errCodeOf(err) != errno.ErrInvalidParam {
+			t.Fatalf("CreateUser() error = %v, want code %d", err, errno.ErrInvalidParam)
+		}
+		if _, err := s.CreateUser(ctx, &pb.CreateUserRequest{Username: "dave"}); errCodeOf(err) != errno.ErrInvalidParam {
+			t.Fatalf("CreateUser() error = %v, want code %d", err, errno.ErrInvalidParam)
+		}
+	})
+
+	t.Run("用户名与邮箱冲突", func(t *testing.T) {
+		_, err := s.CreateUser(ctx, &pb.CreateUserRequest{Username: "alice", Email: "other@example.com"})
+		if errCodeOf(err) != ErrUsernameTaken {
+			t.Fatalf("CreateUser() error = %v, want code %d", err, ErrUsernameTaken)
+		}
+		_, err = s.CreateUser(ctx, &pb.CreateUserRequest{Username: "carol", Email: "bob@example.com"})
+		if errCodeOf(err) != ErrEmailTaken {
+			t.Fatalf("CreateUser() error = %v, want code %d", err, ErrEmailTaken)
+		}
+	})
+
+	t.Run("游标分页", func(t *testing.T) {
+		got, err := s.ListUsers(ctx, &pb.ListUsersRequest{PageSize: 1})
+		if err != nil || got.Total != 2 || len(got.Users) != 1 || got.Users[0].Username != "alice" || got.NextPageToken == "" {
+			t.Fatalf("ListUsers() = %v, %v", got, err)
+		}
+		got, err = s.ListUsers(ctx, &pb.ListUsersRequest{PageSize: 1, PageToken: got.NextPageToken})
+		if err != nil || len(got.Users) != 1 || got.Users[0].Username != "bob" || got.NextPageToken != "" {
+			t.Fatalf("ListUsers() second page = %v, %v", got, err)
+		}
+	})
+
+	t.Run("废弃的 page 参数", func(t *testing.T) {
+		_, err := s.ListUsers(ctx, &pb.ListUsersRequest{Page: 2, PageSize: 1})
+		if errCodeOf(err) != errno.ErrInvalidParam {
+			t.Fatalf("ListUsers(page 2) error = %v, want code %d", err, errno.ErrInvalidParam)
+		}
+		got, err := s.ListUsers(ctx, &pb.ListUsersRequest{Page: 1, PageSize: 1})
+		if err != nil || len(got.Users) != 1 || got.Users[0].Username != "alice" {
+			t.Fatalf("ListUsers(page 1) = %v, %v", got, err)
+		}
+	})
+
+	t.Run("过滤与排序", func(t *testing.T) {
+		got, err := s.ListUsers(ctx, &pb.ListUsersRequest{OrderBy: "-username", PageSize: 1})
+		if err != nil || got.Users[0].Username != "bob" {
+			t.Fatalf("ListUsers() = %v, %v", got, err)
+		}
+		next, err := s.ListUsers(ctx, &pb.ListUsersRequest{OrderBy: "-username", PageSize: 1, PageToken: got.NextPageToken})
+		if err != nil || len(next.Users) != 1 || next.Users[0].Username != "alice" {
+			t.Fatalf("ListUsers() second page = %v, %v", next, err)
+		}
+		got, err = s.ListUsers(ctx, &pb.ListUsersRequest{Email: "bob@"})
+		if err != nil || got.Total != 1 || got.Users[0].Username != "bob" {
+			t.Fatalf("ListUsers(email) = %v, %v", got, err)
+		}
+		got, err = s.ListUsers(ctx, &pb.ListUsersRequest{Username: "%"})
+		if err != nil || got.Total != 0 {
+			t.Fatalf("ListUsers(username %%) = %v, %v", got, err)
+		}
+	})
+
+	t.Run("令牌与条件不一致", func(t *testing.T) {
+		got, _ := s.ListUsers(ctx, &pb.ListUsersRequest{PageSize: 1})
+		_, err := s.ListUsers(ctx, &pb.ListUsersRequest{PageSize: 1, PageToken: got.NextPageToken, OrderBy: "created_at"})
+		if status.Code(err) != codes.InvalidArgument {
+			t.Fatalf("ListUsers() error = %v, want InvalidArgument", err)
+		}
+	})
+}
@@ -0,0 +1,382 @@
+package user
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/mail"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/common/hlog"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/ZampoRen/go-server-comon/api/model/user"
+	"github.com/ZampoRen/go-server-comon/internal/errno"
+	"github.com/ZampoRen/go-server-comon/internal/infra/storage"
+	"github.com/ZampoRen/go-server-comon/internal/middleware"
+	"github.com/ZampoRen/go-server-comon/pkg/errorx"
+)
+
+// RoleAdmin 管理员角色，导入导出用户要求 JWT 声明中包含该角色
+const RoleAdmin = "admin"
+
+// requireRole 要求认证拦截器或 JWTAuth 写入 ctx 的声明中包含 role，
+// 未认证返回 errno.ErrUnauthenticated，缺少角色返回 errno.ErrPermissionDenied
+func requireRole(ctx context.Context, role string) error {
+	claims, ok := middleware.ClaimsFromContext(ctx)
+	if !ok {
+		return errorx.New(errno.ErrUnauthenticated)
+	}
+	if !claims.HasRole(role) {
+		return errorx.New(errno.ErrPermissionDenied, errorx.Extra("role", role))
+	}
+	return nil
+}
+
+const (
+	formatCSV    = "csv"
+	formatNDJSON = "ndjson"
+
+	// exportKeyPrefix 导出文件的对象键前缀，对象键由服务端生成，调用方不能指定，避免覆盖其他对象
+	exportKeyPrefix = "exports/users/"
+	// exportUploadConcurrency 导出时分片上传的并发数，内存占用约为 storage.DefaultPartSize * (并发数 + 1)
+	exportUploadConcurrency = 2
+
+	defaultExportBatchSize = 500
+	maxExportBatchSize     = 5000
+	// maxImportErrors 响应中最多返回的行错误数量，避免大文件导入时响应过大
+	maxImportErrors = 100
+	// importBatchSize 导入时单条 INSERT 的最大行数
+	importBatchSize = 500
+)
+
+var csvHeader = []string{"user_id", "username", "email"}
+
+// userRecord 导入导出的行格式
+type userRecord struct {
+	UserID   int64  `json:"user_id"`
+	Username string `json:"username"`
+	Email    string `json:"email"`
+}
+
+// ExportUsers 将用户表导出到对象存储，要求 RoleAdmin 角色，对象键由服务端在 exports/users/ 下生成并在响应中返回
+// 按 user_id 游标分批读取，边读边写入 io.Pipe，通过 storage.PutLargeObject 分片上传，不在内存中拼接整个文件
+func (s *Server) ExportUsers(ctx context.Context, req *pb.ExportUsersRequest) (*pb.ExportUsersResponse, error) {
+	if err := requireRole(ctx, RoleAdmin); err != nil {
+		return nil, err
+	}
+	if s.store == nil {
+		return nil, status.Errorf(codes.FailedPrecondition, "storage is not configured")
+	}
+
+	format, err := normalizeFormat(req.Format, "")
+	if err != nil {
+		return nil, err
+	}
+
+	batchSize := int(req.BatchSize)
+	if batchSize <= 0 {
+		batchSize = defaultExportBatchSize
+	}
+	if batchSize > maxExportBatchSize {
+		batchSize = maxExportBatchSize
+	}
+
+	objectKey := exportKey(format)
+
+	pr, pw := io.Pipe()
+	written := make(chan int64, 1)
+	go func() {
+		n, err := s.writeUsers(ctx, pw, format, batchSize)
+		_ = pw.CloseWithError(err)
+		written <- n
+	}()
+
+	contentType := "text/csv"
+	if format == formatNDJSON {
+		contentType = "application/x-ndjson"
+	}
+	err = storage.PutLargeObject(ctx, s.store, objectKey, pr, storage.DefaultPartSize, exportUploadConcurrency, storage.WithContentType(contentType))
+	// 上传失败时读端提前关闭，确保写协程退出
+	_ = pr.CloseWithError(err)
+	count := <-written
+	if err != nil {
+		hlog.CtxErrorf(ctx, "[User] export users to %s failed: %v", objectKey, err)
+		return nil, status.Errorf(codes.Internal, "export users failed: %v", err)
+	}
+
+	hlog.CtxInfof(ctx, "[User] exported %d users to %s", count, objectKey)
+	return &pb.ExportUsersResponse{
+		ObjectKey: objectKey,
+		Count:     count,
+	}, nil
+}
+
+// exportKey 生成导出文件的对象键，时间戳之后附加随机后缀，同一秒内的多次导出不会相互覆盖
+func exportKey(format string) string {
+	suffix := make([]byte, 4)
+	_, _ = rand.Read(suffix)
+	return fmt.Sprintf("%s%s-%s.%s", exportKeyPrefix, time.Now().UTC().Format("20060102150405"), hex.EncodeToString(suffix), format)
+}
+
+// writeUsers 按批次将用户写入 w，返回写入的行数
+func (s *Server) writeUsers(ctx context.Context, w io.Writer, format string, batchSize int) (int64, error) {
+	bw := bufio.NewWriter(w)
+	var (
+		csvw  *csv.Writer
+		jsonw *json.Encoder
+	)
+	switch format {
+	case formatCSV:
+		csvw = csv.NewWriter(bw)
+		if err := csvw.Write(csvHeader); err != nil {
+			return 0, err
+		}
+	case formatNDJSON:
+		jsonw = json.NewEncoder(bw)
+	}
+
+	var (
+		count   int64
+		afterID int64
+	)
+	for {
+		batch, err := s.repo.ListAfter(ctx, afterID, batchSize)
+		if err != nil {
+			return count, err
+		}
+		if len(batch) == 0 {
+			break
+		}
+		for _, u := range batch {
+			var err error
+			if csvw != nil {
+				err = csvw.Write([]string{strconv.FormatInt(u.ID, 10), u.Username, u.Email})
+			} else {
+				err = jsonw.Encode(userRecord{UserID: u.ID, Username: u.Username, Email: u.Email})
+			}
+			if err != nil {
+				return count, err
+			}
+			count++
+		}
+		afterID = batch[len(batch)-1].ID
+		if csvw != nil {
+			csvw.Flush()
+			if err := csvw.Error(); err != nil {
+				return count, err
+			}
+		}
+	}
+
+	return count, bw.Flush()
+}
+
+// ImportUsers 从对象存储导入用户，要求 RoleAdmin 角色
+// 逐行校验用户名、邮箱以及唯一性；dry_run 为 true 时只返回校验结果，不写入
+func (s *Server) ImportUsers(ctx context.Context, req *pb.ImportUsersRequest) (*pb.ImportUsersResponse, error) {
+	if err := requireRole(ctx, RoleAdmin); err != nil {
+		return nil, err
+	}
+	if s.store == nil {
+		return nil, status.Errorf(codes.FailedPrecondition, "storage is not configured")
+	}
+	if req.ObjectKey == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "object_key is required")
+	}
+
+	format, err := normalizeFormat(req.Format, req.ObjectKey)
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := s.store.GetObject(ctx, req.ObjectKey)
+	if err != nil {
+		if errors.Is(err, storage.ErrObjectNotFound) {
+			return nil, status.Errorf(codes.NotFound, "object %s not found", req.ObjectKey)
+		}
+		return nil, status.Errorf(codes.Internal, "read object %s failed: %v", req.ObjectKey, err)
+	}
+
+	resp := &pb.ImportUsersResponse{DryRun: req.DryRun}
+	addErr := func(line int64, reason string) {
+		resp.Failed++
+		if len(resp.Errors) < maxImportErrors {
+			resp.Errors = append(resp.Errors, &pb.ImportRowError{Line: line, Reason: reason})
+		}
+	}
+
+	records, err := readUsers(bytes.NewReader(content), format, addErr)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "parse %s failed: %v", req.ObjectKey, err)
+	}
+
+	// 文件内的重复通过 seen 检查，与已有用户的重复按批查询；
+	// 检查与写入之间并发创建的冲突由唯一索引兜底，此时整体失败并返回 errno.ErrConflict
+	usernames := make([]string, 0, len(records))
+	emails := make([]string, 0, len(records))
+	for _, rec := range records {
+		usernames = append(usernames, rec.Username)
+		emails = append(emails, rec.Email)
+	}
+	takenUsernames, err := s.repo.ExistingUsernames(ctx, usernames)
+	if err != nil {
+		return nil, err
+	}
+	takenEmails, err := s.repo.ExistingEmails(ctx, emails)
+	if err != nil {
+		return nil, err
+	}
+
+	users := make([]*User, 0, len(records))
+	for _, rec := range records {
+		if reason := validateRecord(rec.userRecord); reason != "" {
+			addErr(rec.line, reason)
+			continue
+		}
+		if _, ok := takenUsernames[rec.Username]; ok {
+			addErr(rec.line, fmt.Sprintf("username %s already exists", rec.Username))
+			continue
+		}
+		if _, ok := takenEmails[rec.Email]; ok {
+			addErr(rec.line, fmt.Sprintf("email %s already exists", rec.Email))
+			continue
+		}
+		takenUsernames[rec.Username] = struct{}{}
+		takenEmails[rec.Email] = struct{}{}
+		resp.Imported++
+		users = append(users, &User{Username: rec.Username, Email: rec.Email})
+	}
+	if !req.DryRun {
+		if err := s.repo.BatchCreate(ctx, users, importBatchSize); err != nil {
+			hlog.CtxErrorf(ctx, "[User] import users from %s failed: %v", req.ObjectKey, err)
+			return nil, err
+		}
+	}
+	// 解析阶段失败的行也计入总数
+	resp.Total = resp.Imported + resp.Failed
+
+	hlog.CtxInfof(ctx, "[User] import users from %s: total=%d imported=%d failed=%d dry_run=%v",
+		req.ObjectKey, resp.Total, resp.Imported, resp.Failed, req.DryRun)
+	return resp, nil
+}
+
+// lineRecord 带行号的导入记录
+type lineRecord struct {
+	userRecord
+	line int64
+}
+
+// readUsers 解析导入文件，无法解析的行通过 onErr 上报并跳过
+// 仅在文件整体不可读（如 CSV 表头缺失）时返回 error
+func readUsers(r io.Reader, format string, onErr func(line int64, reason string)) ([]lineRecord, error) {
+	var records []lineRecord
+
+	if format == formatNDJSON {
+		sc := bufio.NewScanner(r)
+		sc.Buffer(make([]byte, 64*1024), 1024*1024)
+		var line int64
+		for sc.Scan() {
+			line++
+			text := bytes.TrimSpace(sc.Bytes())
+			if len(text) == 0 {
+				continue
+			}
+			var rec userRecord
+			if err := json.Unmarshal(text, &rec); err != nil {
+				onErr(line, fmt.Sprintf("invalid json: %v", err))
+				continue
+			}
+			records = append(records, lineRecord{userRecord: rec, line: line})
+		}
+		return records, sc.Err()
+	}
+
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	header, err := cr.Read()
+	if err != nil {
+		return nil, fmt.Errorf("read csv header: %w", err)
+	}
+	index := make(map[string]int, len(header))
+	for i, h := range header {
+		index[strings.TrimSpace(strings.ToLower(h))] = i
+	}
+	for _, col := range []string{"username", "email"} {
+		if _, ok := index[col]; !ok {
+			return nil, fmt.Errorf("csv header missing column %q", col)
+		}
+	}
+	field := func(row []string, col string) string {
+		i, ok := index[col]
+		if !ok || i >= len(row) {
+			return ""
+		}
+		return strings.TrimSpace(row[i])
+	}
+
+	for {
+		row, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			var perr *csv.ParseError
+			if errors.As(err, &perr) {
+				onErr(int64(perr.Line), perr.Err.Error())
+				continue
+			}
+			return nil, err
+		}
+		line, _ := cr.FieldPos(0)
+		records = append(records, lineRecord{
+			userRecord: userRecord{Username: field(row, "username"), Email: field(row, "email")},
+			line:       int64(line),
+		})
+	}
+	return records, nil
+}
+
+// validateRecord 校验单条导入记录，返回空字符串表示通过
+func validateRecord(rec userRecord) string {
+	if rec.Username == "" {
+		return "username is required"
+	}
+	if rec.Email == "" {
+		return "email is required"
+	}
+	if addr, err := mail.ParseAddress(rec.Email); err != nil || addr.Address != rec.Email {
+		return fmt.Sprintf("invalid email %s", rec.Email)
+	}
+	return ""
+}
+
+// normalizeFormat 规范化文件格式，为空时根据对象键的扩展名推断，默认 csv
+func normalizeFormat(format, objectKey string) (string, error) {
+	format = strings.ToLower(strings.TrimSpace(format))
+	if format == "" {
+		switch strings.ToLower(path.Ext(objectKey)) {
+		case ".ndjson", ".jsonl":
+			format = formatNDJSON
+		default:
+			format = formatCSV
+		}
+	}
+	switch format {
+	case formatCSV, formatNDJSON:
+		return format, nil
+	default:
+		return "", status.Errorf(codes.InvalidArgument, "unsupported format %s", format)
+	}
+}
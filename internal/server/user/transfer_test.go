@@ -0,0 +1,114 @@
+package user
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/ZampoRen/go-server-comon/api/model/user"
+	"github.com/ZampoRen/go-server-comon/internal/errno"
+	"github.com/ZampoRen/go-server-comon/internal/infra/storage/impl/memory"
+	"github.com/ZampoRen/go-server-comon/internal/middleware"
+)
+
+// TestTransfer 测试用户导出与导入
+func TestTransfer(t *testing.T) {
+	s := newTestServer(t)
+	s.store = memory.New()
+
+	t.Run("要求管理员角色", func(t *testing.T) {
+		ctx := context.Background()
+		if _, err := s.ExportUsers(ctx, &pb.ExportUsersRequest{}); errCodeOf(err) != errno.ErrUnauthenticated {
+			t.Fatalf("ExportUsers() error = %v, want code %d", err, errno.ErrUnauthenticated)
+		}
+		ctx = middleware.ContextWithClaims(ctx, &middleware.Claims{Roles: []string{"user"}})
+		if _, err := s.ExportUsers(ctx, &pb.ExportUsersRequest{}); errCodeOf(err) != errno.ErrPermissionDenied {
+			t.Fatalf("ExportUsers() error = %v, want code %d", err, errno.ErrPermissionDenied)
+		}
+		if _, err := s.ImportUsers(ctx, &pb.ImportUsersRequest{ObjectKey: "imports/users.csv"}); errCodeOf(err) != errno.ErrPermissionDenied {
+			t.Fatalf("ImportUsers() error = %v, want code %d", err, errno.ErrPermissionDenied)
+		}
+	})
+
+	ctx := middleware.ContextWithClaims(context.Background(), &middleware.Claims{Roles: []string{RoleAdmin}})
+	for _, name := range []string{"alice", "bob"} {
+		if _, err := s.CreateUser(ctx, &pb.CreateUserRequest{Username: name, Email: name + "@example.com"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	t.Run("导出 CSV", func(t *testing.T) {
+		resp, err := s.ExportUsers(ctx, &pb.ExportUsersRequest{BatchSize: 1})
+		if err != nil || resp.Count != 2 {
+			t.Fatalf("ExportUsers() = %v, %v", resp, err)
+		}
+		if !strings.HasPrefix(resp.ObjectKey, exportKeyPrefix) || !strings.HasSuffix(resp.ObjectKey, ".csv") {
+			t.Fatalf("ExportUsers() object key = %s", resp.ObjectKey)
+		}
+		data, err := s.store.GetObject(ctx, resp.ObjectKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+		if len(lines) != 3 || lines[0] != "user_id,username,email" || !strings.HasSuffix(lines[2], ",bob,bob@example.com") {
+			t.Fatalf("exported csv = %q", data)
+		}
+	})
+
+	t.Run("导出 NDJSON 不覆盖已有导出", func(t *testing.T) {
+		first, err := s.ExportUsers(ctx, &pb.ExportUsersRequest{Format: "ndjson"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		second, err := s.ExportUsers(ctx, &pb.ExportUsersRequest{Format: "ndjson"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if first.ObjectKey == second.ObjectKey || !strings.HasSuffix(first.ObjectKey, ".ndjson") {
+			t.Fatalf("ExportUsers() object keys = %s, %s", first.ObjectKey, second.ObjectKey)
+		}
+	})
+
+	content := strings.Join([]string{
+		`{"username":"carol","email":"carol@example.com"}`,
+		`{"username":"alice","email":"alice2@example.com"}`,
+		`{"username":"dave","email":"not-an-email"}`,
+		`{"username":"carol","email":"carol2@example.com"}`,
+		`not json`,
+	}, "\n")
+	if err := s.store.PutObject(ctx, "imports/users.ndjson", []byte(content)); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("导入预检", func(t *testing.T) {
+		resp, err := s.ImportUsers(ctx, &pb.ImportUsersRequest{ObjectKey: "imports/users.ndjson", DryRun: true})
+		if err != nil || resp.Total != 5 || resp.Imported != 1 || resp.Failed != 4 || len(resp.Errors) != 4 {
+			t.Fatalf("ImportUsers(dry_run) = %v, %v", resp, err)
+		}
+		got, err := s.ListUsers(ctx, &pb.ListUsersRequest{Username: "carol"})
+		if err != nil || got.Total != 0 {
+			t.Fatalf("dry run should not write users: %v, %v", got, err)
+		}
+	})
+
+	t.Run("导入", func(t *testing.T) {
+		resp, err := s.ImportUsers(ctx, &pb.ImportUsersRequest{ObjectKey: "imports/users.ndjson"})
+		if err != nil || resp.Imported != 1 {
+			t.Fatalf("ImportUsers() = %v, %v", resp, err)
+		}
+		got, err := s.ListUsers(ctx, &pb.ListUsersRequest{Username: "carol"})
+		if err != nil || got.Total != 1 || got.Users[0].Email != "carol@example.com" {
+			t.Fatalf("ListUsers(carol) = %v, %v", got, err)
+		}
+	})
+
+	t.Run("导入对象不存在", func(t *testing.T) {
+		_, err := s.ImportUsers(ctx, &pb.ImportUsersRequest{ObjectKey: "imports/missing.csv"})
+		if status.Code(err) != codes.NotFound {
+			t.Fatalf("ImportUsers() error = %v, want NotFound", err)
+		}
+	})
+}
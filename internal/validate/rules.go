@@ -0,0 +1,68 @@
+package validate
+
+import (
+	"regexp"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+)
+
+type rule struct {
+	tag      string
+	fn       validator.Func
+	messages map[string]string
+}
+
+var builtinRules = []rule{
+	{
+		tag: "mobile",
+		fn:  func(fl validator.FieldLevel) bool { return IsMobile(fl.Field().String()) },
+		messages: map[string]string{
+			"zh": "{0}必须是有效的手机号码",
+			"en": "{0} must be a valid mobile number",
+		},
+	},
+	{
+		tag: "idcard",
+		fn:  func(fl validator.FieldLevel) bool { return IsIDCard(fl.Field().String()) },
+		messages: map[string]string{
+			"zh": "{0}必须是有效的身份证号码",
+			"en": "{0} must be a valid ID card number",
+		},
+	},
+}
+
+var mobileRegexp = regexp.MustCompile(`^1[3-9]\d{9}$`)
+
+// IsMobile 判断 s 是否为中国大陆手机号码
+func IsMobile(s string) bool {
+	return mobileRegexp.MatchString(s)
+}
+
+var (
+	idCardWeights = [17]int{7, 9, 10, 5, 8, 4, 2, 1, 6, 3, 7, 9, 10, 5, 8, 4, 2}
+	idCardChecks  = "10X98765432"
+)
+
+// IsIDCard 判断 s 是否为 18 位居民身份证号码，校验出生日期与校验码，末位 x 不区分大小写
+func IsIDCard(s string) bool {
+	if len(s) != 18 {
+		return false
+	}
+	sum := 0
+	for i := 0; i < 17; i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+		sum += int(s[i]-'0') * idCardWeights[i]
+	}
+	check := s[17]
+	if check == 'x' {
+		check = 'X'
+	}
+	if check != idCardChecks[sum%11] {
+		return false
+	}
+	birth, err := time.Parse("20060102", s[6:14])
+	return err == nil && birth.Year() >= 1900 && !birth.After(time.Now())
+}
@@ -0,0 +1,248 @@
+// Package validate 基于 go-playground/validator 的结构体校验，校验失败时返回 errno.ErrValidation 错误，
+// 消息为按语言翻译的各字段错误，同时用于 Hertz 参数绑定与 gRPC 校验拦截器
+//
+//	type CreateUserRequest struct {
+//		Username string `json:"username" validate:"required,min=3,max=32"`
+//		Mobile   string `json:"mobile" validate:"omitempty,mobile"`
+//		IDCard   string `json:"id_card" validate:"omitempty,idcard"`
+//	}
+//
+//	if err := validate.Struct(ctx, &req); err != nil {
+//		response.Error(ctx, c, err) // 参数校验失败: username为必填字段
+//	}
+//
+// 字段名取 json 标签，其次 form、query 标签；错误消息的语言取 ctx 中 i18n 本地化器的语言（见 httpmw.Locale），
+// 支持中文与英文，其它语言使用默认语言。Hertz 服务通过 server.WithCustomValidator(validate.Default()) 接入，
+// 此时绑定时没有 ctx，使用默认语言
+package validate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/go-playground/locales/en"
+	"github.com/go-playground/locales/zh"
+	ut "github.com/go-playground/universal-translator"
+	"github.com/go-playground/validator/v10"
+	entranslations "github.com/go-playground/validator/v10/translations/en"
+	zhtranslations "github.com/go-playground/validator/v10/translations/zh"
+
+	"github.com/ZampoRen/go-server-comon/internal/errno"
+	"github.com/ZampoRen/go-server-comon/pkg/errorx"
+	"github.com/ZampoRen/go-server-comon/pkg/i18n"
+)
+
+// TagName 校验规则的结构体标签
+const TagName = "validate"
+
+// Option 校验器选项
+type Option func(o *option)
+
+type option struct {
+	defaultLocale string
+	nameTags      []string
+	code          int32
+}
+
+// WithDefaultLocale 设置默认语言，可选 zh、en，默认 zh
+func WithDefaultLocale(locale string) Option {
+	return func(o *option) {
+		o.defaultLocale = locale
+	}
+}
+
+// WithFieldNameTags 设置字段名依次取自的结构体标签，默认 json、form、query，如配置结构体使用 yaml
+func WithFieldNameTags(tags ...string) Option {
+	return func(o *option) {
+		o.nameTags = tags
+	}
+}
+
+// WithErrorCode 设置校验失败时的错误码，默认 errno.ErrValidation，错误码的消息模板需包含 {details}
+func WithErrorCode(code int32) Option {
+	return func(o *option) {
+		o.code = code
+	}
+}
+
+// Validator 结构体校验器，可并发使用
+type Validator struct {
+	v             *validator.Validate
+	uni           *ut.UniversalTranslator
+	defaultLocale string
+	code          int32
+}
+
+// New 创建校验器，已注册中英文翻译与 mobile、idcard 规则
+func New(opts ...Option) *Validator {
+	o := &option{defaultLocale: "zh", nameTags: []string{"json", "form", "query"}, code: errno.ErrValidation}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	v := validator.New(validator.WithRequiredStructEnabled())
+	v.SetTagName(TagName)
+	v.RegisterTagNameFunc(func(f reflect.StructField) string {
+		return fieldName(f, o.nameTags)
+	})
+
+	zhLocale, enLocale := zh.New(), en.New()
+	uni := ut.New(zhLocale, zhLocale, enLocale)
+	zhTrans, _ := uni.GetTranslator("zh")
+	enTrans, _ := uni.GetTranslator("en")
+	if err := zhtranslations.RegisterDefaultTranslations(v, zhTrans); err != nil {
+		panic(err)
+	}
+	if err := entranslations.RegisterDefaultTranslations(v, enTrans); err != nil {
+		panic(err)
+	}
+
+	val := &Validator{v: v, uni: uni, defaultLocale: i18n.Canonical(o.defaultLocale), code: o.code}
+	for _, r := range builtinRules {
+		if err := val.RegisterRule(r.tag, r.fn, r.messages); err != nil {
+			panic(err)
+		}
+	}
+	return val
+}
+
+var (
+	defaultOnce      sync.Once
+	defaultValidator *Validator
+)
+
+// Default 返回默认校验器
+func Default() *Validator {
+	defaultOnce.Do(func() {
+		defaultValidator = New()
+	})
+	return defaultValidator
+}
+
+// Struct 使用默认校验器校验 s
+func Struct(ctx context.Context, s any) error {
+	return Default().Struct(ctx, s)
+}
+
+// RegisterRule 注册自定义规则，messages 为各语言（zh、en）的错误消息，{0} 为字段名
+// 应在开始校验前注册
+func (v *Validator) RegisterRule(tag string, fn validator.Func, messages map[string]string) error {
+	if err := v.v.RegisterValidation(tag, fn); err != nil {
+		return err
+	}
+	for locale, msg := range messages {
+		trans, found := v.uni.GetTranslator(locale)
+		if !found {
+			return fmt.Errorf("validate: unsupported locale %q", locale)
+		}
+		err := v.v.RegisterTranslation(tag, trans, func(t ut.Translator) error {
+			return t.Add(tag, msg, true)
+		}, func(t ut.Translator, fe validator.FieldError) string {
+			s, _ := t.T(tag, fe.Field())
+			return s
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Struct 校验 s，语言取 ctx 中 i18n 本地化器的语言。校验失败时返回 errno.ErrValidation（或 WithErrorCode 设置的）错误，
+// 不合法的字段名记录在 Extra 的 fields 中；s 不是结构体时返回普通错误
+func (v *Validator) Struct(ctx context.Context, s any) error {
+	return v.convert(v.v.StructCtx(ctx, s), i18n.FromContext(ctx).Locale())
+}
+
+// ValidateStruct 实现 Hertz 的 binding.StructValidator，使用默认语言
+// Hertz 传入的是请求结构体的 reflect.Value
+func (v *Validator) ValidateStruct(s any) error {
+	if rv, ok := s.(reflect.Value); ok {
+		s = rv.Interface()
+	}
+	return v.convert(v.v.Struct(s), "")
+}
+
+// Engine 实现 Hertz 的 binding.StructValidator，返回底层的 *validator.Validate
+func (v *Validator) Engine() any {
+	return v.v
+}
+
+// ValidateTag 实现 Hertz 的 binding.StructValidator
+func (v *Validator) ValidateTag() string {
+	return TagName
+}
+
+// translator 依次尝试 locale、locale 的基础语言（zh-CN -> zh）与默认语言
+func (v *Validator) translator(locale string) ut.Translator {
+	for _, l := range []string{locale, baseLocale(locale), v.defaultLocale, baseLocale(v.defaultLocale)} {
+		if l == "" {
+			continue
+		}
+		if trans, found := v.uni.GetTranslator(l); found {
+			return trans
+		}
+	}
+	trans, _ := v.uni.GetTranslator("zh")
+	return trans
+}
+
+func (v *Validator) convert(err error, locale string) error {
+	var errs validator.ValidationErrors
+	if !errors.As(err, &errs) {
+		return err
+	}
+	trans := v.translator(locale)
+	fields := make([]string, len(errs))
+	details := make([]string, len(errs))
+	for i, fe := range errs {
+		fields[i] = fieldPath(fe)
+		details[i] = fe.Translate(trans)
+	}
+	return errorx.New(v.code,
+		errorx.KV("details", strings.Join(details, "; ")),
+		errorx.Extra("fields", strings.Join(fields, ",")),
+	)
+}
+
+// WrapBindError 将 Hertz 参数绑定的错误转换为 errorx 错误：校验失败已是 errno.ErrValidation 错误，原样返回；
+// 其余解析错误包装为 errno.ErrInvalidParam
+func WrapBindError(err error) error {
+	var se errorx.StatusError
+	if err == nil || errors.As(err, &se) {
+		return err
+	}
+	return errorx.WrapByCode(err, errno.ErrInvalidParam)
+}
+
+// fieldPath 返回不含顶层结构体名的字段路径，如 address.city
+func fieldPath(fe validator.FieldError) string {
+	ns := fe.Namespace()
+	if i := strings.IndexByte(ns, '.'); i >= 0 {
+		return ns[i+1:]
+	}
+	return ns
+}
+
+// fieldName 字段名依次取 tags 中的标签，都没有时使用字段名
+func fieldName(f reflect.StructField, tags []string) string {
+	for _, tag := range tags {
+		name, _, _ := strings.Cut(f.Tag.Get(tag), ",")
+		if name == "-" {
+			return ""
+		}
+		if name != "" {
+			return name
+		}
+	}
+	return f.Name
+}
+
+func baseLocale(locale string) string {
+	base, _, _ := strings.Cut(locale, "-")
+	return base
+}
@@ -0,0 +1,126 @@
+package validate
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/go-playground/validator/v10"
+
+	"github.com/ZampoRen/go-server-comon/internal/errno"
+	"github.com/ZampoRen/go-server-comon/pkg/errorx"
+	"github.com/ZampoRen/go-server-comon/pkg/i18n"
+)
+
+type address struct {
+	City string `json:"city" validate:"required"`
+}
+
+type createUserRequest struct {
+	Username string   `json:"username" validate:"required,min=3"`
+	Mobile   string   `form:"mobile" validate:"omitempty,mobile"`
+	IDCard   string   `json:"id_card,omitempty" validate:"omitempty,idcard"`
+	Address  *address `json:"address" validate:"omitempty"`
+}
+
+func statusError(t *testing.T, err error) errorx.StatusError {
+	t.Helper()
+	var se errorx.StatusError
+	if !errors.As(err, &se) {
+		t.Fatalf("err = %v, want errorx error", err)
+	}
+	if se.Code() != errno.ErrValidation {
+		t.Fatalf("code = %d, want %d", se.Code(), errno.ErrValidation)
+	}
+	return se
+}
+
+func TestStruct(t *testing.T) {
+	ctx := context.Background()
+	valid := createUserRequest{Username: "tom", Mobile: "13800138000", IDCard: "11010519491231002X"}
+	if err := Struct(ctx, &valid); err != nil {
+		t.Fatalf("valid request: %v", err)
+	}
+
+	req := createUserRequest{Mobile: "12345", IDCard: "110105194912310021", Address: &address{}}
+	se := statusError(t, Struct(ctx, &req))
+	if got, want := se.Extra()["fields"], "username,mobile,id_card,address.city"; got != want {
+		t.Errorf("fields = %q, want %q", got, want)
+	}
+	for _, want := range []string{"username为必填字段", "mobile必须是有效的手机号码", "id_card必须是有效的身份证号码"} {
+		if !strings.Contains(se.Msg(), want) {
+			t.Errorf("msg = %q, want containing %q", se.Msg(), want)
+		}
+	}
+}
+
+func TestStructLocale(t *testing.T) {
+	b := i18n.NewBundle("zh-CN")
+	b.AddMessages("en-US", map[string]string{})
+	ctx := i18n.NewContext(context.Background(), b.Localizer("en-US"))
+
+	se := statusError(t, Struct(ctx, &createUserRequest{Username: "tom", Mobile: "1"}))
+	if want := "mobile must be a valid mobile number"; !strings.Contains(se.Msg(), want) {
+		t.Errorf("msg = %q, want containing %q", se.Msg(), want)
+	}
+
+	v := New(WithDefaultLocale("en"))
+	se = statusError(t, v.ValidateStruct(reflect.ValueOf(&createUserRequest{})))
+	if want := "username is a required field"; !strings.Contains(se.Msg(), want) {
+		t.Errorf("msg = %q, want containing %q", se.Msg(), want)
+	}
+}
+
+func TestRegisterRule(t *testing.T) {
+	v := New()
+	err := v.RegisterRule("even", func(fl validator.FieldLevel) bool {
+		return fl.Field().Int()%2 == 0
+	}, map[string]string{"zh": "{0}必须是偶数", "en": "{0} must be even"})
+	if err != nil {
+		t.Fatalf("RegisterRule: %v", err)
+	}
+	type request struct {
+		Count int `json:"count" validate:"even"`
+	}
+	se := statusError(t, v.Struct(context.Background(), &request{Count: 3}))
+	if want := "count必须是偶数"; !strings.Contains(se.Msg(), want) {
+		t.Errorf("msg = %q, want containing %q", se.Msg(), want)
+	}
+
+	if err := v.RegisterRule("odd", func(validator.FieldLevel) bool { return true }, map[string]string{"fr": "x"}); err == nil {
+		t.Error("unsupported locale should fail")
+	}
+}
+
+func TestIsIDCard(t *testing.T) {
+	tests := map[string]bool{
+		"11010519491231002X": true,
+		"11010519491231002x": true,
+		"440304200001011239": true,
+		"440304200001011238": false, // 校验码错误
+		"110105194913310021": false, // 出生日期错误
+		"11010519491231002":  false,
+		"1101051949123100AX": false,
+	}
+	for id, want := range tests {
+		if got := IsIDCard(id); got != want {
+			t.Errorf("IsIDCard(%q) = %v, want %v", id, got, want)
+		}
+	}
+}
+
+func TestWrapBindError(t *testing.T) {
+	if WrapBindError(nil) != nil {
+		t.Error("nil should stay nil")
+	}
+	verr := errorx.New(errno.ErrValidation, errorx.KV("details", "x"))
+	if got := WrapBindError(verr); got != verr {
+		t.Errorf("errorx error should be returned as is, got %v", got)
+	}
+	var se errorx.StatusError
+	if !errors.As(WrapBindError(errors.New("bad json")), &se) || se.Code() != errno.ErrInvalidParam {
+		t.Errorf("bind error should be wrapped as ErrInvalidParam")
+	}
+}
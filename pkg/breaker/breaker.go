@@ -0,0 +1,329 @@
+// Package breaker 基于滑动窗口失败率的熔断器，防止下游故障时请求堆积导致级联失败
+//
+//	b := breaker.New("user-svc", breaker.WithFailureRatio(0.5), breaker.WithOpenTimeout(30*time.Second))
+//	err := b.Do(ctx, func(ctx context.Context) error {
+//		return client.Call(ctx, req)
+//	})
+//	if errors.Is(err, breaker.ErrOpen) {
+//		// 熔断中，走降级逻辑
+//	}
+//
+// 状态转换：
+//   - Closed：正常放行，窗口内请求数不少于 MinRequests 且失败率达到 FailureRatio 时转为 Open
+//   - Open：直接拒绝并返回 ErrOpen，经过 OpenTimeout 后转为 HalfOpen
+//   - HalfOpen：至多放行 HalfOpenProbes 个探测请求，全部成功后转为 Closed，任一失败立即回到 Open
+//
+// gRPC 客户端见 middleware.UnaryClientBreakerInterceptor，HTTP 客户端见 Transport
+package breaker
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/ZampoRen/go-server-comon/pkg/errorx"
+)
+
+var (
+	// ErrOpen 熔断器打开，请求被拒绝
+	ErrOpen = errors.New("breaker: circuit open")
+	// ErrTooManyProbes 半开状态下探测请求数已达上限，请求被拒绝
+	ErrTooManyProbes = errors.New("breaker: too many half-open probes")
+)
+
+// State 熔断器状态
+type State int
+
+const (
+	StateClosed State = iota
+	StateOpen
+	StateHalfOpen
+)
+
+func (s State) String() string {
+	switch s {
+	case StateClosed:
+		return "closed"
+	case StateOpen:
+		return "open"
+	case StateHalfOpen:
+		return "half-open"
+	default:
+		return "unknown"
+	}
+}
+
+// Option 熔断器选项
+type Option func(o *option)
+
+type option struct {
+	window         time.Duration
+	buckets        int
+	minRequests    int
+	failureRatio   float64
+	openTimeout    time.Duration
+	halfOpenProbes int
+	isFailure      func(err error) bool
+	onStateChange  func(name string, from, to State)
+	now            func() time.Time
+}
+
+// WithWindow 设置统计失败率的滑动窗口长度与分桶数，默认 10s、10 个桶
+func WithWindow(window time.Duration, buckets int) Option {
+	return func(o *option) {
+		if window > 0 {
+			o.window = window
+		}
+		if buckets > 0 {
+			o.buckets = buckets
+		}
+	}
+}
+
+// WithMinRequests 设置窗口内触发熔断的最少请求数，默认 20，避免请求量少时偶发失败导致熔断
+func WithMinRequests(n int) Option {
+	return func(o *option) {
+		if n > 0 {
+			o.minRequests = n
+		}
+	}
+}
+
+// WithFailureRatio 设置触发熔断的失败率，取值 (0, 1]，默认 0.5
+func WithFailureRatio(ratio float64) Option {
+	return func(o *option) {
+		if ratio > 0 && ratio <= 1 {
+			o.failureRatio = ratio
+		}
+	}
+}
+
+// WithOpenTimeout 设置打开后转为半开前的等待时间，默认 30s
+func WithOpenTimeout(d time.Duration) Option {
+	return func(o *option) {
+		if d > 0 {
+			o.openTimeout = d
+		}
+	}
+}
+
+// WithHalfOpenProbes 设置半开状态下的探测请求数，全部成功后关闭熔断器，默认 1
+func WithHalfOpenProbes(n int) Option {
+	return func(o *option) {
+		if n > 0 {
+			o.halfOpenProbes = n
+		}
+	}
+}
+
+// WithIsFailure 自定义计为失败的错误，默认为 IsFailure
+func WithIsFailure(fn func(err error) bool) Option {
+	return func(o *option) {
+		if fn != nil {
+			o.isFailure = fn
+		}
+	}
+}
+
+// WithOnStateChange 设置状态变化的回调，回调在持有锁时同步调用，不应阻塞
+func WithOnStateChange(fn func(name string, from, to State)) Option {
+	return func(o *option) {
+		o.onStateChange = fn
+	}
+}
+
+// IsFailure 默认的失败判断：调用方 ctx 的取消与不影响稳定性的 errorx 错误（参数错误、资源不存在等）
+// 说明下游正常，不计为失败；其它错误计为失败
+func IsFailure(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+	var se errorx.StatusError
+	if errors.As(err, &se) {
+		return se.IsAffectStability()
+	}
+	return true
+}
+
+// bucket 一个时间片内的请求计数
+type bucket struct {
+	successes, failures int
+}
+
+// Breaker 熔断器，可并发使用
+type Breaker struct {
+	name string
+	o    *option
+
+	mu         sync.Mutex
+	state      State
+	generation uint64 // 每次状态变化加一，忽略旧状态下发出的请求结果
+	buckets    []bucket
+	cursor     int       // 当前桶
+	cursorAt   time.Time // 当前桶的起始时间
+	openedAt   time.Time
+	probes     int // 半开状态下已放行的探测请求
+	probeOK    int // 半开状态下成功的探测请求
+}
+
+// New 创建熔断器，name 用于状态变化回调
+func New(name string, opts ...Option) *Breaker {
+	o := &option{
+		window:         10 * time.Second,
+		buckets:        10,
+		minRequests:    20,
+		failureRatio:   0.5,
+		openTimeout:    30 * time.Second,
+		halfOpenProbes: 1,
+		isFailure:      IsFailure,
+		now:            time.Now,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return &Breaker{
+		name:     name,
+		o:        o,
+		buckets:  make([]bucket, o.buckets),
+		cursorAt: o.now(),
+	}
+}
+
+// Name 返回熔断器名称
+func (b *Breaker) Name() string {
+	return b.name
+}
+
+// State 返回当前状态
+func (b *Breaker) State() State {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.advance(b.o.now())
+	return b.state
+}
+
+// Allow 判断是否放行请求，放行时返回的 done 必须在请求结束后以请求的错误调用一次
+// 拒绝时返回 ErrOpen 或 ErrTooManyProbes
+func (b *Breaker) Allow() (done func(err error), err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.advance(b.o.now())
+	switch b.state {
+	case StateOpen:
+		return nil, ErrOpen
+	case StateHalfOpen:
+		if b.probes >= b.o.halfOpenProbes {
+			return nil, ErrTooManyProbes
+		}
+		b.probes++
+	}
+
+	generation := b.generation
+	return func(err error) {
+		b.record(generation, b.o.isFailure(err))
+	}, nil
+}
+
+// Do 熔断器放行时执行 fn 并记录结果，拒绝时返回 ErrOpen 或 ErrTooManyProbes
+func (b *Breaker) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	done, err := b.Allow()
+	if err != nil {
+		return err
+	}
+	err = fn(ctx)
+	done(err)
+	return err
+}
+
+func (b *Breaker) record(generation uint64, failed bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := b.o.now()
+	b.advance(now)
+	if generation != b.generation {
+		return
+	}
+
+	switch b.state {
+	case StateClosed:
+		if failed {
+			b.buckets[b.cursor].failures++
+		} else {
+			b.buckets[b.cursor].successes++
+		}
+		var total, failures int
+		for _, bk := range b.buckets {
+			total += bk.successes + bk.failures
+			failures += bk.failures
+		}
+		if total >= b.o.minRequests && float64(failures) >= b.o.failureRatio*float64(total) {
+			b.setState(StateOpen, now)
+		}
+	case StateHalfOpen:
+		if failed {
+			b.setState(StateOpen, now)
+			return
+		}
+		b.probeOK++
+		if b.probeOK >= b.o.halfOpenProbes {
+			b.setState(StateClosed, now)
+		}
+	}
+}
+
+// advance 滚动滑动窗口，并在打开超时后转为半开
+func (b *Breaker) advance(now time.Time) {
+	if b.state == StateOpen && now.Sub(b.openedAt) >= b.o.openTimeout {
+		b.setState(StateHalfOpen, now)
+	}
+
+	width := b.o.window / time.Duration(len(b.buckets))
+	elapsed := int(now.Sub(b.cursorAt) / width)
+	if elapsed <= 0 {
+		return
+	}
+	for i := 0; i < min(elapsed, len(b.buckets)); i++ {
+		b.cursor = (b.cursor + 1) % len(b.buckets)
+		b.buckets[b.cursor] = bucket{}
+	}
+	b.cursorAt = b.cursorAt.Add(time.Duration(elapsed) * width)
+}
+
+func (b *Breaker) setState(to State, now time.Time) {
+	from := b.state
+	b.state = to
+	b.generation++
+	b.probes, b.probeOK = 0, 0
+	switch to {
+	case StateOpen:
+		b.openedAt = now
+	case StateClosed:
+		clear(b.buckets)
+	}
+	if b.o.onStateChange != nil {
+		b.o.onStateChange(b.name, from, to)
+	}
+}
+
+// Group 按名称懒创建的一组熔断器，用于按下游方法或主机分别熔断
+type Group struct {
+	opts     []Option
+	breakers sync.Map // name -> *Breaker
+}
+
+// NewGroup 创建熔断器组，组内熔断器使用相同的选项
+func NewGroup(opts ...Option) *Group {
+	return &Group{opts: opts}
+}
+
+// Get 返回名为 name 的熔断器，不存在时创建
+func (g *Group) Get(name string) *Breaker {
+	if b, ok := g.breakers.Load(name); ok {
+		return b.(*Breaker)
+	}
+	b, _ := g.breakers.LoadOrStore(name, New(name, g.opts...))
+	return b.(*Breaker)
+}
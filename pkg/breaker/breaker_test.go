@@ -0,0 +1,159 @@
+package breaker
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ZampoRen/go-server-comon/pkg/errorx"
+	"github.com/ZampoRen/go-server-comon/pkg/errorx/code"
+)
+
+const codeInvalid int32 = 990101
+
+func init() {
+	code.Register(codeInvalid, "invalid", code.WithAffectStability(false))
+}
+
+// clock 测试用的可控时钟
+type clock struct {
+	now time.Time
+}
+
+func (c *clock) Now() time.Time { return c.now }
+
+func (c *clock) Add(d time.Duration) { c.now = c.now.Add(d) }
+
+func withClock(c *clock) Option {
+	return func(o *option) {
+		o.now = c.Now
+	}
+}
+
+var errDown = errors.New("down")
+
+func call(b *Breaker, err error) error {
+	return b.Do(context.Background(), func(ctx context.Context) error { return err })
+}
+
+func TestStateTransitions(t *testing.T) {
+	c := &clock{now: time.Unix(0, 0)}
+	var changes []State
+	b := New("svc", withClock(c), WithMinRequests(4), WithFailureRatio(0.5), WithOpenTimeout(time.Second),
+		WithHalfOpenProbes(2), WithOnStateChange(func(name string, from, to State) {
+			changes = append(changes, to)
+		}))
+
+	for _, err := range []error{nil, nil, errDown} {
+		_ = call(b, err)
+	}
+	if b.State() != StateClosed {
+		t.Fatalf("state = %s, want closed below min requests", b.State())
+	}
+	_ = call(b, errDown)
+	if b.State() != StateOpen {
+		t.Fatalf("state = %s, want open", b.State())
+	}
+	if err := call(b, nil); !errors.Is(err, ErrOpen) {
+		t.Fatalf("err = %v, want ErrOpen", err)
+	}
+
+	c.Add(time.Second)
+	if b.State() != StateHalfOpen {
+		t.Fatalf("state = %s, want half-open", b.State())
+	}
+	done1, err := b.Allow()
+	if err != nil {
+		t.Fatalf("probe 1: %v", err)
+	}
+	done2, err := b.Allow()
+	if err != nil {
+		t.Fatalf("probe 2: %v", err)
+	}
+	if _, err := b.Allow(); !errors.Is(err, ErrTooManyProbes) {
+		t.Fatalf("probe 3 err = %v, want ErrTooManyProbes", err)
+	}
+	done1(nil)
+	done2(nil)
+	if b.State() != StateClosed {
+		t.Fatalf("state = %s, want closed", b.State())
+	}
+
+	want := []State{StateOpen, StateHalfOpen, StateClosed}
+	if len(changes) != len(want) {
+		t.Fatalf("changes = %v, want %v", changes, want)
+	}
+	for i := range want {
+		if changes[i] != want[i] {
+			t.Fatalf("changes = %v, want %v", changes, want)
+		}
+	}
+}
+
+func TestHalfOpenFailure(t *testing.T) {
+	c := &clock{now: time.Unix(0, 0)}
+	b := New("svc", withClock(c), WithMinRequests(1), WithOpenTimeout(time.Second))
+	_ = call(b, errDown)
+	c.Add(time.Second)
+	_ = call(b, errDown)
+	if b.State() != StateOpen {
+		t.Fatalf("state = %s, want open after failed probe", b.State())
+	}
+}
+
+func TestSlidingWindow(t *testing.T) {
+	c := &clock{now: time.Unix(0, 0)}
+	b := New("svc", withClock(c), WithWindow(time.Second, 10), WithMinRequests(2))
+	_ = call(b, errDown)
+	// 第一次失败滑出窗口后不再计入
+	c.Add(1100 * time.Millisecond)
+	_ = call(b, nil)
+	_ = call(b, nil)
+	_ = call(b, errDown)
+	if b.State() != StateClosed {
+		t.Fatalf("state = %s, want closed", b.State())
+	}
+}
+
+func TestIsFailure(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{nil, false},
+		{context.Canceled, false},
+		{errorx.New(codeInvalid), false},
+		{errDown, true},
+		{context.DeadlineExceeded, true},
+	}
+	for _, tt := range tests {
+		if got := IsFailure(tt.err); got != tt.want {
+			t.Errorf("IsFailure(%v) = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestTransport(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer srv.Close()
+
+	client := &http.Client{Transport: Transport(srv.Client().Transport, NewGroup(WithMinRequests(2)))}
+	for i := 0; i < 2; i++ {
+		resp, err := client.Get(srv.URL)
+		if err != nil {
+			t.Fatalf("request %d: %v", i, err)
+		}
+		_ = resp.Body.Close()
+		if resp.StatusCode != http.StatusBadGateway {
+			t.Fatalf("status = %d", resp.StatusCode)
+		}
+	}
+	if _, err := client.Get(srv.URL); !errors.Is(err, ErrOpen) {
+		t.Fatalf("err = %v, want ErrOpen", err)
+	}
+}
@@ -0,0 +1,50 @@
+package breaker
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// StatusError HTTP 响应状态码计为失败时交给熔断器的错误，不会返回给调用方
+type StatusError struct {
+	StatusCode int
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("breaker: http status %d", e.StatusCode)
+}
+
+// transport 按请求主机熔断的 http.RoundTripper
+type transport struct {
+	next  http.RoundTripper
+	group *Group
+}
+
+// Transport 用熔断器组包装 next，按请求的主机（含端口）分别熔断，next 为 nil 时使用 http.DefaultTransport
+// 网络错误与 5xx 响应计为失败，熔断时 RoundTrip 返回 ErrOpen 或 ErrTooManyProbes
+//
+//	client := &http.Client{Transport: breaker.Transport(nil, breaker.NewGroup())}
+func Transport(next http.RoundTripper, group *Group) http.RoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &transport{next: next, group: group}
+}
+
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	done, err := t.group.Get(req.URL.Host).Allow()
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := t.next.RoundTrip(req)
+	switch {
+	case err != nil:
+		done(err)
+	case resp.StatusCode >= http.StatusInternalServerError:
+		done(&StatusError{StatusCode: resp.StatusCode})
+	default:
+		done(nil)
+	}
+	return resp, err
+}
@@ -0,0 +1,310 @@
+// Package di 提供轻量的依赖注入容器（无代码生成）
+//
+// 基本使用：
+//
+//	c := di.New()
+//	_ = di.Provide(c, func(ctx context.Context, c *di.Container) (*gorm.DB, error) {
+//		return mysql.New()
+//	}, di.OnStop(func(ctx context.Context, db *gorm.DB) error {
+//		sqlDB, err := db.DB()
+//		if err != nil {
+//			return err
+//		}
+//		return sqlDB.Close()
+//	}))
+//
+//	db := di.MustInvoke[*gorm.DB](ctx, c)
+//	if err := c.Start(ctx); err != nil {
+//		log.Fatal(err)
+//	}
+//	defer c.Stop(context.Background())
+package di
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+)
+
+var (
+	// ErrNotProvided 请求的类型没有注册构造函数
+	ErrNotProvided = errors.New("di: type not provided")
+	// ErrCycle 构造函数之间存在循环依赖
+	ErrCycle = errors.New("di: dependency cycle")
+	// ErrStarted 容器已启动，不能再注册构造函数
+	ErrStarted = errors.New("di: container already started")
+)
+
+// Hook 生命周期钩子
+type Hook struct {
+	Name    string
+	OnStart func(ctx context.Context) error
+	OnStop  func(ctx context.Context) error
+}
+
+// Container 依赖注入容器
+// 每个类型只对应一个构造函数，实例在首次 Invoke 时惰性创建并缓存（单例）。
+// 构造过程中注册的钩子按实例创建顺序启动、按相反顺序停止，
+// 因此被依赖的组件总是先于依赖方启动、晚于依赖方停止。
+// Start 之后才首次创建的实例在创建时立即执行 OnStart，同样会在 Stop 时停止。
+// 构造过程不保证并发安全，应在服务启动阶段由单个协程完成 Invoke。
+type Container struct {
+	mu        sync.Mutex
+	providers map[reflect.Type]*provider
+	resolving []reflect.Type
+	hooks     []*hook
+	running   bool // 已调用 Start，不能再注册构造函数
+	ready     bool // Start 已成功完成且尚未 Stop，新创建实例的钩子需要立即启动
+}
+
+// hook 记录钩子是否已成功执行 OnStart
+type hook struct {
+	Hook
+	started bool
+}
+
+type provider struct {
+	name     string
+	build    func(ctx context.Context, c *Container) (any, error)
+	instance any
+	built    bool
+}
+
+// New 创建容器
+func New() *Container {
+	return &Container{
+		providers: make(map[reflect.Type]*provider),
+	}
+}
+
+// ProvideOption 构造函数选项
+type ProvideOption[T any] func(o *provideOption[T])
+
+type provideOption[T any] struct {
+	name    string
+	onStart func(ctx context.Context, v T) error
+	onStop  func(ctx context.Context, v T) error
+}
+
+// WithName 设置钩子名称，用于日志与错误信息，默认为类型名
+func WithName[T any](name string) ProvideOption[T] {
+	return func(o *provideOption[T]) {
+		o.name = name
+	}
+}
+
+// OnStart 注册实例创建后在 Start 阶段执行的钩子
+func OnStart[T any](fn func(ctx context.Context, v T) error) ProvideOption[T] {
+	return func(o *provideOption[T]) {
+		o.onStart = fn
+	}
+}
+
+// OnStop 注册实例在 Stop 阶段执行的钩子，如关闭连接
+func OnStop[T any](fn func(ctx context.Context, v T) error) ProvideOption[T] {
+	return func(o *provideOption[T]) {
+		o.onStop = fn
+	}
+}
+
+// Provide 注册类型 T 的构造函数，重复注册时后者覆盖前者
+// 构造函数内可以通过 Invoke 获取其它依赖
+func Provide[T any](c *Container, fn func(ctx context.Context, c *Container) (T, error), opts ...ProvideOption[T]) error {
+	o := &provideOption[T]{}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	typ := typeOf[T]()
+	if o.name == "" {
+		o.name = typ.String()
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.running {
+		return ErrStarted
+	}
+
+	c.providers[typ] = &provider{
+		name: o.name,
+		build: func(ctx context.Context, c *Container) (any, error) {
+			v, err := fn(ctx, c)
+			if err != nil {
+				return nil, err
+			}
+			if o.onStart != nil || o.onStop != nil {
+				h := Hook{Name: o.name}
+				if o.onStart != nil {
+					h.OnStart = func(ctx context.Context) error { return o.onStart(ctx, v) }
+				}
+				if o.onStop != nil {
+					h.OnStop = func(ctx context.Context) error { return o.onStop(ctx, v) }
+				}
+				if err := c.addHook(ctx, h); err != nil {
+					return nil, err
+				}
+			}
+			return v, nil
+		},
+	}
+	return nil
+}
+
+// Supply 直接注册已创建好的实例
+func Supply[T any](c *Container, v T) error {
+	return Provide(c, func(context.Context, *Container) (T, error) { return v, nil })
+}
+
+// Invoke 获取类型 T 的实例，必要时递归构造其依赖
+func Invoke[T any](ctx context.Context, c *Container) (T, error) {
+	var zero T
+	v, err := c.resolve(ctx, typeOf[T]())
+	if err != nil {
+		return zero, err
+	}
+	return v.(T), nil
+}
+
+// MustInvoke 同 Invoke，失败时 panic，适合在 main 中使用
+func MustInvoke[T any](ctx context.Context, c *Container) T {
+	v, err := Invoke[T](ctx, c)
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+// resolve 构造过程会回调 Invoke，因此只在访问内部状态时持锁
+func (c *Container) resolve(ctx context.Context, typ reflect.Type) (any, error) {
+	c.mu.Lock()
+	p, ok := c.providers[typ]
+	if !ok {
+		c.mu.Unlock()
+		return nil, fmt.Errorf("%w: %s", ErrNotProvided, typ)
+	}
+	if p.built {
+		c.mu.Unlock()
+		return p.instance, nil
+	}
+	for i, t := range c.resolving {
+		if t == typ {
+			path := make([]string, 0, len(c.resolving)-i+1)
+			for _, t := range c.resolving[i:] {
+				path = append(path, t.String())
+			}
+			path = append(path, typ.String())
+			c.mu.Unlock()
+			return nil, fmt.Errorf("%w: %s", ErrCycle, strings.Join(path, " -> "))
+		}
+	}
+	c.resolving = append(c.resolving, typ)
+	c.mu.Unlock()
+
+	v, err := p.build(ctx, c)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.resolving = c.resolving[:len(c.resolving)-1]
+	if err != nil {
+		return nil, fmt.Errorf("di: build %s: %w", p.name, err)
+	}
+	p.instance, p.built = v, true
+	return v, nil
+}
+
+// Append 追加生命周期钩子，应在 Start 之前调用，Start 完成后追加的钩子不会执行
+func (c *Container) Append(h Hook) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.hooks = append(c.hooks, &hook{Hook: h})
+}
+
+// addHook 追加实例的钩子，Start 已完成时立即执行 OnStart，失败时移除该钩子并返回错误
+func (c *Container) addHook(ctx context.Context, h Hook) error {
+	e := &hook{Hook: h}
+	c.mu.Lock()
+	c.hooks = append(c.hooks, e)
+	ready := c.ready
+	c.mu.Unlock()
+	if !ready {
+		return nil
+	}
+
+	if h.OnStart != nil {
+		if err := h.OnStart(ctx); err != nil {
+			c.mu.Lock()
+			c.hooks = slices.DeleteFunc(c.hooks, func(x *hook) bool { return x == e })
+			c.mu.Unlock()
+			return fmt.Errorf("di: start %s: %w", h.Name, err)
+		}
+	}
+	c.mu.Lock()
+	e.started = true
+	c.mu.Unlock()
+	return nil
+}
+
+// Start 按注册顺序执行 OnStart 钩子，OnStart 中创建的实例的钩子也会在本次启动
+// 某个钩子失败时，已启动的钩子会按相反顺序执行 OnStop 后返回错误
+func (c *Container) Start(ctx context.Context) error {
+	c.mu.Lock()
+	c.running = true
+	c.mu.Unlock()
+
+	for i := 0; ; i++ {
+		c.mu.Lock()
+		if i >= len(c.hooks) {
+			c.ready = true
+			c.mu.Unlock()
+			return nil
+		}
+		h := c.hooks[i]
+		c.mu.Unlock()
+
+		if h.OnStart != nil {
+			if err := h.OnStart(ctx); err != nil {
+				return errors.Join(fmt.Errorf("di: start %s: %w", h.Name, err), c.Stop(ctx))
+			}
+		}
+		c.mu.Lock()
+		h.started = true
+		c.mu.Unlock()
+	}
+}
+
+// Stop 按相反顺序执行已启动钩子的 OnStop，返回所有失败钩子的错误
+// 没有调用过 Start 时会停止全部钩子，便于在初始化失败时释放已创建的资源
+func (c *Container) Stop(ctx context.Context) error {
+	c.mu.Lock()
+	var hooks, rest []*hook
+	for _, h := range c.hooks {
+		if h.started || !c.running {
+			hooks = append(hooks, h)
+		} else {
+			rest = append(rest, h)
+		}
+	}
+	c.hooks = rest
+	c.ready = false
+	c.mu.Unlock()
+
+	var errs []error
+	for i := len(hooks) - 1; i >= 0; i-- {
+		if hooks[i].OnStop == nil {
+			continue
+		}
+		if err := hooks[i].OnStop(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("di: stop %s: %w", hooks[i].Name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func typeOf[T any]() reflect.Type {
+	return reflect.TypeOf((*T)(nil)).Elem()
+}
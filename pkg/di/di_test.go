@@ -0,0 +1,142 @@
+package di
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+type testDB struct{ name string }
+
+type testRepo struct{ db *testDB }
+
+type testSvc struct{ repo *testRepo }
+
+// TestInvoke 测试依赖递归构造与单例
+func TestInvoke(t *testing.T) {
+	g := NewGomegaWithT(t)
+	ctx := context.Background()
+	c := New()
+
+	builds := 0
+	g.Expect(Provide(c, func(ctx context.Context, c *Container) (*testDB, error) {
+		builds++
+		return &testDB{name: "db"}, nil
+	})).Should(Succeed())
+	g.Expect(Provide(c, func(ctx context.Context, c *Container) (*testRepo, error) {
+		db, err := Invoke[*testDB](ctx, c)
+		if err != nil {
+			return nil, err
+		}
+		return &testRepo{db: db}, nil
+	})).Should(Succeed())
+
+	repo, err := Invoke[*testRepo](ctx, c)
+	g.Expect(err).ShouldNot(HaveOccurred())
+	g.Expect(repo.db.name).Should(Equal("db"))
+
+	db, err := Invoke[*testDB](ctx, c)
+	g.Expect(err).ShouldNot(HaveOccurred())
+	g.Expect(db).Should(BeIdenticalTo(repo.db))
+	g.Expect(builds).Should(Equal(1))
+
+	_, err = Invoke[*testSvc](ctx, c)
+	g.Expect(errors.Is(err, ErrNotProvided)).Should(BeTrue())
+}
+
+// TestInvokeCycle 测试循环依赖检测
+func TestInvokeCycle(t *testing.T) {
+	g := NewGomegaWithT(t)
+	ctx := context.Background()
+	c := New()
+
+	g.Expect(Provide(c, func(ctx context.Context, c *Container) (*testRepo, error) {
+		_, err := Invoke[*testSvc](ctx, c)
+		return nil, err
+	})).Should(Succeed())
+	g.Expect(Provide(c, func(ctx context.Context, c *Container) (*testSvc, error) {
+		_, err := Invoke[*testRepo](ctx, c)
+		return nil, err
+	})).Should(Succeed())
+
+	_, err := Invoke[*testSvc](ctx, c)
+	g.Expect(errors.Is(err, ErrCycle)).Should(BeTrue())
+	g.Expect(err.Error()).Should(ContainSubstring("*di.testSvc -> *di.testRepo -> *di.testSvc"))
+}
+
+// TestLifecycle 测试钩子按创建顺序启动、按相反顺序停止，启动失败时回滚
+func TestLifecycle(t *testing.T) {
+	g := NewGomegaWithT(t)
+	ctx := context.Background()
+
+	var events []string
+	record := func(name string) func(context.Context) error {
+		return func(context.Context) error {
+			events = append(events, name)
+			return nil
+		}
+	}
+
+	c := New()
+	g.Expect(Provide(c, func(ctx context.Context, c *Container) (*testDB, error) {
+		return &testDB{}, nil
+	}, OnStart(func(ctx context.Context, _ *testDB) error { return record("start db")(ctx) }),
+		OnStop(func(ctx context.Context, _ *testDB) error { return record("stop db")(ctx) }))).Should(Succeed())
+	g.Expect(Provide(c, func(ctx context.Context, c *Container) (*testRepo, error) {
+		db, err := Invoke[*testDB](ctx, c)
+		return &testRepo{db: db}, err
+	}, OnStart(func(ctx context.Context, _ *testRepo) error { return record("start repo")(ctx) }),
+		OnStop(func(ctx context.Context, _ *testRepo) error { return record("stop repo")(ctx) }))).Should(Succeed())
+
+	_, err := Invoke[*testRepo](ctx, c)
+	g.Expect(err).ShouldNot(HaveOccurred())
+	c.Append(Hook{Name: "server", OnStart: func(context.Context) error { return errors.New("boom") }})
+
+	err = c.Start(ctx)
+	g.Expect(err).Should(MatchError(ContainSubstring("start server")))
+	g.Expect(events).Should(Equal([]string{"start db", "start repo", "stop repo", "stop db"}))
+
+	g.Expect(Provide(c, func(ctx context.Context, c *Container) (*testSvc, error) {
+		return &testSvc{}, nil
+	})).Should(MatchError(ErrStarted))
+}
+
+// TestInvokeAfterStart 测试 Start 之后才创建的实例立即启动，并在 Stop 时停止
+func TestInvokeAfterStart(t *testing.T) {
+	g := NewGomegaWithT(t)
+	ctx := context.Background()
+
+	var events []string
+	c := New()
+	g.Expect(Provide(c, func(ctx context.Context, c *Container) (*testDB, error) {
+		return &testDB{}, nil
+	}, OnStart(func(context.Context, *testDB) error {
+		events = append(events, "start db")
+		return nil
+	}), OnStop(func(context.Context, *testDB) error {
+		events = append(events, "stop db")
+		return nil
+	}))).Should(Succeed())
+	g.Expect(Provide(c, func(ctx context.Context, c *Container) (*testRepo, error) {
+		return &testRepo{}, nil
+	}, OnStart(func(context.Context, *testRepo) error {
+		return errors.New("boom")
+	}), OnStop(func(context.Context, *testRepo) error {
+		events = append(events, "stop repo")
+		return nil
+	}))).Should(Succeed())
+	g.Expect(c.Start(ctx)).Should(Succeed())
+
+	_, err := Invoke[*testDB](ctx, c)
+	g.Expect(err).ShouldNot(HaveOccurred())
+	g.Expect(events).Should(Equal([]string{"start db"}))
+
+	// 启动失败的实例不会被缓存，也不会在 Stop 时停止
+	_, err = Invoke[*testRepo](ctx, c)
+	g.Expect(err).Should(MatchError(ContainSubstring("start *di.testRepo")))
+
+	g.Expect(c.Stop(ctx)).Should(Succeed())
+	g.Expect(events).Should(Equal([]string{"start db", "stop db"}))
+}
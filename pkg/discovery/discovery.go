@@ -0,0 +1,130 @@
+// Package discovery 服务注册与发现，支持 etcd 与 Nacos，并提供 gRPC resolver，
+// 使 rpcclient.Dial 可以通过 discovery:///服务名 连接服务的所有实例
+//
+// 服务端按健康检查结果注册实例：
+//
+//	backend, err := discovery.NewEtcd(discovery.EtcdConfig{Endpoints: []string{"etcd:2379"}})
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	addr, _ := discovery.HostAddr(":9090")
+//	registrar := discovery.NewRegistrar(backend, discovery.Instance{Service: "user-service", Addr: addr})
+//	lc.Append(lifecycle.Hook{Name: "registrar", OnStart: registrar.Start, OnStop: registrar.Stop})
+//
+// 调用方：
+//
+//	conn, err := rpcclient.Dial("discovery:///user-service", rpcclient.WithDiscovery(backend))
+//
+// 实例列表变化时 resolver 更新连接的地址，由 rpcclient 预置的 round_robin 在实例间均衡
+package discovery
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"slices"
+	"strings"
+
+	"github.com/ZampoRen/go-server-comon/pkg/envkey"
+)
+
+// Instance 服务实例
+type Instance struct {
+	// ID 实例 ID，为空时使用 Service 与 Addr 生成
+	ID string `json:"id"`
+	// Service 服务名
+	Service string `json:"service"`
+	// Addr 实例地址，如 10.0.0.5:9090
+	Addr string `json:"addr"`
+	// Metadata 实例元数据，如版本、协议
+	Metadata map[string]string `json:"metadata,omitempty"`
+}
+
+func (ins *Instance) key() string {
+	if ins.ID != "" {
+		return ins.ID
+	}
+	return ins.Service + "-" + ins.Addr
+}
+
+// Registry 服务注册，实例由实现按 TTL 续约，进程异常退出后自动过期
+type Registry interface {
+	// Register 注册实例，重复注册同一实例时更新实例信息
+	Register(ctx context.Context, ins Instance) error
+	// Deregister 注销实例
+	Deregister(ctx context.Context, ins Instance) error
+}
+
+// Discovery 服务发现
+type Discovery interface {
+	// Watch 监听 service 的实例，首次及每次实例变化时以完整的实例列表调用 update，
+	// 阻塞直到 ctx 结束或出错，ctx 结束时返回 nil
+	Watch(ctx context.Context, service string, update func([]Instance)) error
+}
+
+// Backend 同时支持注册与发现的注册中心
+type Backend interface {
+	Registry
+	Discovery
+	// Close 注销本进程注册的实例并释放连接
+	Close() error
+}
+
+// NewFromEnv 按环境变量 DISCOVERY_BACKEND 创建注册中心，可选 etcd、nacos，未设置时返回 nil
+// etcd 读取 ETCD_ENDPOINTS（逗号分隔）、ETCD_USERNAME、ETCD_PASSWORD；
+// nacos 读取 NACOS_ADDR、NACOS_NAMESPACE、NACOS_GROUP、NACOS_USERNAME、NACOS_PASSWORD
+func NewFromEnv() (Backend, error) {
+	switch backend := envkey.GetString("DISCOVERY_BACKEND"); backend {
+	case "":
+		return nil, nil
+	case "etcd":
+		return NewEtcd(EtcdConfig{
+			Endpoints: envkey.GetStringSliceD("ETCD_ENDPOINTS", ",", nil),
+			Username:  envkey.GetString("ETCD_USERNAME"),
+			Password:  envkey.GetString("ETCD_PASSWORD"),
+		})
+	case "nacos":
+		return NewNacos(NacosConfig{
+			Addr:      envkey.GetString("NACOS_ADDR"),
+			Namespace: envkey.GetString("NACOS_NAMESPACE"),
+			Group:     envkey.GetString("NACOS_GROUP"),
+			Username:  envkey.GetString("NACOS_USERNAME"),
+			Password:  envkey.GetString("NACOS_PASSWORD"),
+		})
+	default:
+		return nil, fmt.Errorf("unsupported discovery backend %q", backend)
+	}
+}
+
+// HostAddr 将监听地址转换为其他实例可访问的地址：主机为空或为 0.0.0.0、:: 时，
+// 依次使用环境变量 POD_IP 与本机第一个非回环 IPv4 地址，如 :9090 -> 10.0.0.5:9090
+func HostAddr(listenAddr string) (string, error) {
+	host, port, err := net.SplitHostPort(listenAddr)
+	if err != nil {
+		return "", err
+	}
+	if host != "" && host != "0.0.0.0" && host != "::" {
+		return listenAddr, nil
+	}
+	if ip := envkey.GetString("POD_IP"); ip != "" {
+		return net.JoinHostPort(ip, port), nil
+	}
+	addrs, err := net.InterfaceAddrs()
+	if err != nil {
+		return "", err
+	}
+	for _, a := range addrs {
+		if ipNet, ok := a.(*net.IPNet); ok && !ipNet.IP.IsLoopback() && ipNet.IP.To4() != nil {
+			return net.JoinHostPort(ipNet.IP.String(), port), nil
+		}
+	}
+	return "", fmt.Errorf("no non-loopback ipv4 address found")
+}
+
+// sortInstances 按地址排序，使实例列表的比较与 resolver 的地址顺序稳定
+func sortInstances(instances []Instance) []Instance {
+	slices.SortFunc(instances, func(a, b Instance) int {
+		return strings.Compare(a.Addr, b.Addr)
+	})
+	return instances
+}
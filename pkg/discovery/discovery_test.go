@@ -0,0 +1,334 @@
+package discovery
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	"github.com/ZampoRen/go-server-comon/pkg/health"
+)
+
+// fakeBackend 内存中的注册中心，Watch 在实例变化时推送完整列表
+type fakeBackend struct {
+	mu        sync.Mutex
+	instances map[string]Instance
+	watchers  []chan struct{}
+}
+
+func newFakeBackend() *fakeBackend {
+	return &fakeBackend{instances: make(map[string]Instance)}
+}
+
+func (b *fakeBackend) Register(_ context.Context, ins Instance) error {
+	b.mu.Lock()
+	b.instances[ins.key()] = ins
+	b.mu.Unlock()
+	b.notify()
+	return nil
+}
+
+func (b *fakeBackend) Deregister(_ context.Context, ins Instance) error {
+	b.mu.Lock()
+	delete(b.instances, ins.key())
+	b.mu.Unlock()
+	b.notify()
+	return nil
+}
+
+func (b *fakeBackend) Close() error { return nil }
+
+func (b *fakeBackend) notify() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, ch := range b.watchers {
+		select {
+		case ch <- struct{}{}:
+		default:
+		}
+	}
+}
+
+func (b *fakeBackend) list(service string) []Instance {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	var list []Instance
+	for _, ins := range b.instances {
+		if ins.Service == service {
+			list = append(list, ins)
+		}
+	}
+	return sortInstances(list)
+}
+
+func (b *fakeBackend) Watch(ctx context.Context, service string, update func([]Instance)) error {
+	ch := make(chan struct{}, 1)
+	b.mu.Lock()
+	b.watchers = append(b.watchers, ch)
+	b.mu.Unlock()
+	for {
+		update(b.list(service))
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ch:
+		}
+	}
+}
+
+func TestResolver(t *testing.T) {
+	var hits [2]atomic.Int32
+	addrs := make([]string, 2)
+	for i := range addrs {
+		lis, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatal(err)
+		}
+		srv := grpc.NewServer(grpc.UnknownServiceHandler(func(_ any, stream grpc.ServerStream) error {
+			hits[i].Add(1)
+			var req emptypb.Empty
+			if err := stream.RecvMsg(&req); err != nil {
+				return err
+			}
+			return stream.SendMsg(&emptypb.Empty{})
+		}))
+		go func() { _ = srv.Serve(lis) }()
+		defer srv.Stop()
+		addrs[i] = lis.Addr().String()
+	}
+
+	backend := newFakeBackend()
+	ctx := context.Background()
+	for _, addr := range addrs {
+		_ = backend.Register(ctx, Instance{Service: "user-service", Addr: addr})
+	}
+
+	conn, err := grpc.NewClient(Scheme+":///user-service",
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithResolvers(NewResolverBuilder(backend)),
+		grpc.WithDefaultServiceConfig(`{"loadBalancingConfig":[{"round_robin":{}}]}`),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	invoke := func(n int) {
+		t.Helper()
+		for range n {
+			if err := conn.Invoke(ctx, "/test.Svc/Get", &emptypb.Empty{}, &emptypb.Empty{}); err != nil {
+				t.Fatalf("Invoke() error = %v", err)
+			}
+		}
+	}
+
+	t.Run("在所有实例间均衡", func(t *testing.T) {
+		// round_robin 在子连接就绪前只使用已就绪的实例，等待两个实例都收到请求
+		deadline := time.Now().Add(5 * time.Second)
+		for hits[0].Load() == 0 || hits[1].Load() == 0 {
+			if time.Now().After(deadline) {
+				t.Fatalf("hits = %d, %d, want both > 0", hits[0].Load(), hits[1].Load())
+			}
+			invoke(10)
+		}
+	})
+
+	t.Run("实例注销后不再调用", func(t *testing.T) {
+		_ = backend.Deregister(ctx, Instance{Service: "user-service", Addr: addrs[1]})
+		deadline := time.Now().Add(5 * time.Second)
+		for {
+			before := hits[1].Load()
+			invoke(10)
+			if hits[1].Load() == before {
+				break
+			}
+			if time.Now().After(deadline) {
+				t.Fatal("deregistered instance still receives calls")
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	})
+}
+
+func TestResolverEmptyService(t *testing.T) {
+	conn, err := grpc.NewClient(Scheme+":///",
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithResolvers(NewResolverBuilder(newFakeBackend())),
+	)
+	if err == nil {
+		// NewClient 惰性解析，首次调用时返回 resolver 的错误
+		defer conn.Close()
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		err = conn.Invoke(ctx, "/test.Svc/Get", &emptypb.Empty{}, &emptypb.Empty{})
+	}
+	if err == nil {
+		t.Fatal("want error for empty service name")
+	}
+}
+
+func TestRegistrar(t *testing.T) {
+	backend := newFakeBackend()
+	hr := health.NewRegistry()
+	var ready atomic.Bool
+	hr.Register("db", health.CheckerFunc(func(context.Context) error {
+		if !ready.Load() {
+			return errors.New("not ready")
+		}
+		return nil
+	}))
+
+	registrar := NewRegistrar(backend, Instance{Service: "user-service", Addr: "10.0.0.5:9090"},
+		WithHealth(hr), WithCheckInterval(10*time.Millisecond))
+	ctx := context.Background()
+	if err := registrar.Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	waitFor := func(want int) {
+		t.Helper()
+		deadline := time.Now().Add(2 * time.Second)
+		for len(backend.list("user-service")) != want {
+			if time.Now().After(deadline) {
+				t.Fatalf("instances = %d, want %d", len(backend.list("user-service")), want)
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+	}
+
+	waitFor(0)
+	ready.Store(true)
+	waitFor(1)
+	if got := backend.list("user-service")[0].ID; got != "user-service-10.0.0.5:9090" {
+		t.Errorf("ID = %q", got)
+	}
+	ready.Store(false)
+	waitFor(0)
+	ready.Store(true)
+	waitFor(1)
+
+	// Shutdown 之后就绪检查失败，实例在停止服务前注销
+	hr.Shutdown()
+	waitFor(0)
+
+	if err := registrar.Stop(ctx); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestNacos(t *testing.T) {
+	var mu sync.Mutex
+	registered := make(map[string]bool)
+	var beats atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if got := q.Get("accessToken"); got != "token" && r.URL.Path != "/nacos/v1/auth/login" {
+			http.Error(w, "unauthorized", http.StatusForbidden)
+			return
+		}
+		addr := net.JoinHostPort(q.Get("ip"), q.Get("port"))
+		mu.Lock()
+		defer mu.Unlock()
+		switch r.Method + " " + r.URL.Path {
+		case "POST /nacos/v1/auth/login":
+			_ = json.NewEncoder(w).Encode(map[string]any{"accessToken": "token", "tokenTtl": 18000})
+		case "POST /nacos/v1/ns/instance":
+			registered[addr] = true
+			_, _ = w.Write([]byte("ok"))
+		case "DELETE /nacos/v1/ns/instance":
+			delete(registered, addr)
+			_, _ = w.Write([]byte("ok"))
+		case "PUT /nacos/v1/ns/instance/beat":
+			beats.Add(1)
+			code := 10200
+			if !registered[addr] {
+				code = nacosResourceNotFound
+			}
+			_ = json.NewEncoder(w).Encode(map[string]any{"code": code})
+		case "GET /nacos/v1/ns/instance/list":
+			var hosts []map[string]any
+			for a := range registered {
+				host, port, _ := net.SplitHostPort(a)
+				hosts = append(hosts, map[string]any{"ip": host, "port": json.Number(port), "healthy": true, "enabled": true})
+			}
+			_ = json.NewEncoder(w).Encode(map[string]any{"hosts": hosts})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	backend, err := NewNacos(NacosConfig{
+		Addr:         srv.URL,
+		Username:     "nacos",
+		Password:     "nacos",
+		BeatInterval: 10 * time.Millisecond,
+		PollInterval: 10 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	updates := make(chan []Instance, 16)
+	go func() {
+		_ = backend.Watch(ctx, "user-service", func(instances []Instance) { updates <- instances })
+	}()
+	next := func(want int) {
+		t.Helper()
+		for {
+			select {
+			case instances := <-updates:
+				if len(instances) == want {
+					return
+				}
+			case <-time.After(2 * time.Second):
+				t.Fatalf("no update with %d instances", want)
+			}
+		}
+	}
+	next(0)
+
+	ins := Instance{Service: "user-service", Addr: "10.0.0.5:9090"}
+	if err := backend.Register(ctx, ins); err != nil {
+		t.Fatal(err)
+	}
+	next(1)
+
+	// 实例被服务端摘除后，心跳重新注册
+	mu.Lock()
+	delete(registered, "10.0.0.5:9090")
+	mu.Unlock()
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		mu.Lock()
+		ok := registered["10.0.0.5:9090"]
+		mu.Unlock()
+		if ok {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("instance not registered again")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if beats.Load() == 0 {
+		t.Error("no heartbeat sent")
+	}
+
+	if err := backend.Close(); err != nil {
+		t.Fatal(err)
+	}
+	next(0)
+}
@@ -0,0 +1,246 @@
+package discovery
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/common/hlog"
+	clientv3 "go.etcd.io/etcd/client/v3"
+
+	"github.com/ZampoRen/go-server-comon/pkg/retry"
+)
+
+const (
+	// etcdDefaultPrefix 实例键的默认前缀，键为 前缀/服务名/实例 ID
+	etcdDefaultPrefix = "/services"
+	// etcdDefaultTTL 默认租约时长
+	etcdDefaultTTL = 10 * time.Second
+)
+
+// EtcdConfig etcd 配置
+type EtcdConfig struct {
+	// Endpoints etcd 地址，如 etcd:2379
+	Endpoints []string
+	// Username、Password 开启鉴权时的账号
+	Username string
+	Password string
+	// Prefix 实例键的前缀，默认 /services
+	Prefix string
+	// TTL 实例租约时长，进程异常退出后实例在 TTL 内过期，默认 10s
+	TTL time.Duration
+	// DialTimeout 连接超时，默认 5s
+	DialTimeout time.Duration
+}
+
+// etcdBackend 以租约键注册实例，通过 Watch 监听实例变化
+type etcdBackend struct {
+	cfg    EtcdConfig
+	client *clientv3.Client
+
+	mu     sync.Mutex
+	leases map[string]*etcdLease // 实例 ID -> 租约
+}
+
+type etcdLease struct {
+	id     clientv3.LeaseID
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewEtcd 创建 etcd 注册中心
+func NewEtcd(cfg EtcdConfig) (Backend, error) {
+	if len(cfg.Endpoints) == 0 {
+		return nil, fmt.Errorf("etcd endpoints are required")
+	}
+	if cfg.Prefix == "" {
+		cfg.Prefix = etcdDefaultPrefix
+	}
+	cfg.Prefix = strings.TrimRight(cfg.Prefix, "/")
+	if cfg.TTL <= 0 {
+		cfg.TTL = etcdDefaultTTL
+	}
+	if cfg.DialTimeout <= 0 {
+		cfg.DialTimeout = 5 * time.Second
+	}
+
+	client, err := clientv3.New(clientv3.Config{
+		Endpoints:   cfg.Endpoints,
+		Username:    cfg.Username,
+		Password:    cfg.Password,
+		DialTimeout: cfg.DialTimeout,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("create etcd client failed: %w", err)
+	}
+	return &etcdBackend{cfg: cfg, client: client, leases: make(map[string]*etcdLease)}, nil
+}
+
+func (b *etcdBackend) servicePrefix(service string) string {
+	return b.cfg.Prefix + "/" + service + "/"
+}
+
+// Register 以新租约写入实例并持续续约，续约中断（如网络分区导致租约过期）时按退避重新注册
+func (b *etcdBackend) Register(ctx context.Context, ins Instance) error {
+	ins.ID = ins.key()
+	keepCtx, cancel := context.WithCancel(context.Background())
+	id, ch, err := b.grant(ctx, keepCtx, ins)
+	if err != nil {
+		cancel()
+		return err
+	}
+	lease := &etcdLease{id: id, cancel: cancel, done: make(chan struct{})}
+
+	b.mu.Lock()
+	old := b.leases[ins.ID]
+	b.leases[ins.ID] = lease
+	b.mu.Unlock()
+	if old != nil {
+		// 键已绑定到新租约，撤销旧租约不会删除实例
+		old.stop()
+		_, _ = b.client.Revoke(ctx, old.id)
+	}
+
+	go b.keepAlive(keepCtx, lease, ch, ins)
+	return nil
+}
+
+// grant 创建租约并写入实例，续约持续到 keepCtx 结束
+func (b *etcdBackend) grant(ctx, keepCtx context.Context, ins Instance) (clientv3.LeaseID, <-chan *clientv3.LeaseKeepAliveResponse, error) {
+	value, err := json.Marshal(ins)
+	if err != nil {
+		return 0, nil, err
+	}
+	resp, err := b.client.Grant(ctx, int64(b.cfg.TTL/time.Second))
+	if err != nil {
+		return 0, nil, fmt.Errorf("grant etcd lease failed: %w", err)
+	}
+	if _, err := b.client.Put(ctx, b.servicePrefix(ins.Service)+ins.ID, string(value), clientv3.WithLease(resp.ID)); err != nil {
+		return 0, nil, fmt.Errorf("put etcd instance failed: %w", err)
+	}
+	ch, err := b.client.KeepAlive(keepCtx, resp.ID)
+	if err != nil {
+		return 0, nil, fmt.Errorf("keep alive etcd lease failed: %w", err)
+	}
+	return resp.ID, ch, nil
+}
+
+// keepAlive 消费续约响应，通道关闭且未注销时重新注册，直到 ctx 结束
+func (b *etcdBackend) keepAlive(ctx context.Context, lease *etcdLease, ch <-chan *clientv3.LeaseKeepAliveResponse, ins Instance) {
+	defer close(lease.done)
+	for {
+		for range ch {
+		}
+		if ctx.Err() != nil {
+			return
+		}
+		hlog.CtxWarnf(ctx, "[Discovery] etcd lease of %s lost, registering again", ins.ID)
+		err := retry.Do(ctx, func(ctx context.Context) (err error) {
+			var id clientv3.LeaseID
+			id, ch, err = b.grant(ctx, ctx, ins)
+			if err != nil {
+				hlog.CtxWarnf(ctx, "[Discovery] register %s to etcd failed: %v", ins.ID, err)
+				return err
+			}
+			lease.id = id
+			return nil
+		}, retry.WithMaxAttempts(math.MaxInt), retry.WithBackoff(time.Second, b.cfg.TTL),
+			// etcd 返回的是 gRPC 状态错误，租约丢失后一直重试到 ctx 结束
+			retry.WithRetryIf(func(error) bool { return true }))
+		if err != nil {
+			return
+		}
+	}
+}
+
+// stop 停止续约并等待 keepAlive 退出，之后可以安全读取 id
+func (l *etcdLease) stop() {
+	l.cancel()
+	<-l.done
+}
+
+// Deregister 撤销实例的租约，键随租约删除
+func (b *etcdBackend) Deregister(ctx context.Context, ins Instance) error {
+	id := ins.key()
+	b.mu.Lock()
+	lease := b.leases[id]
+	delete(b.leases, id)
+	b.mu.Unlock()
+	if lease == nil {
+		_, err := b.client.Delete(ctx, b.servicePrefix(ins.Service)+id)
+		return err
+	}
+	lease.stop()
+	if _, err := b.client.Revoke(ctx, lease.id); err != nil {
+		return fmt.Errorf("revoke etcd lease failed: %w", err)
+	}
+	return nil
+}
+
+// Watch 读取服务前缀下的全部实例，再从该版本之后监听变化
+func (b *etcdBackend) Watch(ctx context.Context, service string, update func([]Instance)) error {
+	prefix := b.servicePrefix(service)
+	resp, err := b.client.Get(ctx, prefix, clientv3.WithPrefix())
+	if err != nil {
+		return fmt.Errorf("list etcd instances failed: %w", err)
+	}
+	instances := make(map[string]Instance, len(resp.Kvs))
+	for _, kv := range resp.Kvs {
+		var ins Instance
+		if err := json.Unmarshal(kv.Value, &ins); err == nil {
+			instances[string(kv.Key)] = ins
+		}
+	}
+	update(instanceList(instances))
+
+	wch := b.client.Watch(clientv3.WithRequireLeader(ctx), prefix, clientv3.WithPrefix(), clientv3.WithRev(resp.Header.Revision+1))
+	for wresp := range wch {
+		if err := wresp.Err(); err != nil {
+			return fmt.Errorf("watch etcd instances failed: %w", err)
+		}
+		for _, ev := range wresp.Events {
+			key := string(ev.Kv.Key)
+			if ev.Type == clientv3.EventTypeDelete {
+				delete(instances, key)
+				continue
+			}
+			var ins Instance
+			if err := json.Unmarshal(ev.Kv.Value, &ins); err == nil {
+				instances[key] = ins
+			}
+		}
+		update(instanceList(instances))
+	}
+	if ctx.Err() != nil {
+		return nil
+	}
+	return fmt.Errorf("watch etcd instances closed")
+}
+
+// Close 撤销本进程注册的全部实例并关闭客户端
+func (b *etcdBackend) Close() error {
+	b.mu.Lock()
+	leases := b.leases
+	b.leases = make(map[string]*etcdLease)
+	b.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), b.cfg.DialTimeout)
+	defer cancel()
+	for _, lease := range leases {
+		lease.stop()
+		_, _ = b.client.Revoke(ctx, lease.id)
+	}
+	return b.client.Close()
+}
+
+func instanceList(m map[string]Instance) []Instance {
+	list := make([]Instance, 0, len(m))
+	for _, ins := range m {
+		list = append(list, ins)
+	}
+	return sortInstances(list)
+}
@@ -0,0 +1,369 @@
+package discovery
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/common/hlog"
+)
+
+const (
+	// nacosDefaultGroup 默认分组
+	nacosDefaultGroup = "DEFAULT_GROUP"
+	// nacosResourceNotFound 心跳返回的实例不存在（如已过期被摘除），需要重新注册
+	nacosResourceNotFound = 20404
+)
+
+// NacosConfig Nacos 配置
+type NacosConfig struct {
+	// Addr 服务地址，如 http://nacos:8848
+	Addr string
+	// Namespace 命名空间 ID，为空时使用 public
+	Namespace string
+	// Group 分组，默认 DEFAULT_GROUP
+	Group string
+	// Username、Password 开启鉴权时的账号
+	Username string
+	Password string
+	// BeatInterval 临时实例的心跳间隔，默认 5s，Nacos 在 15s 未收到心跳时将实例标记为不健康
+	BeatInterval time.Duration
+	// PollInterval 查询实例列表的间隔，默认 5s
+	PollInterval time.Duration
+	// HTTPClient 自定义 HTTP 客户端，默认超时 10s
+	HTTPClient *http.Client
+}
+
+// nacosBackend 使用 Open API 注册临时实例并定时发送心跳，通过定时查询实例列表发现实例变化
+type nacosBackend struct {
+	cfg    NacosConfig
+	client *http.Client
+
+	mu          sync.Mutex
+	token       string
+	tokenExpire time.Time
+	beats       map[string]*nacosBeat // 实例 ID -> 心跳
+}
+
+type nacosBeat struct {
+	ins    Instance
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewNacos 创建 Nacos 注册中心
+func NewNacos(cfg NacosConfig) (Backend, error) {
+	if cfg.Addr == "" {
+		return nil, fmt.Errorf("nacos addr is required")
+	}
+	cfg.Addr = strings.TrimRight(cfg.Addr, "/")
+	if cfg.Group == "" {
+		cfg.Group = nacosDefaultGroup
+	}
+	if cfg.BeatInterval <= 0 {
+		cfg.BeatInterval = 5 * time.Second
+	}
+	if cfg.PollInterval <= 0 {
+		cfg.PollInterval = 5 * time.Second
+	}
+	client := cfg.HTTPClient
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	return &nacosBackend{cfg: cfg, client: client, beats: make(map[string]*nacosBeat)}, nil
+}
+
+func (b *nacosBackend) instanceParams(ins Instance) (url.Values, error) {
+	host, port, err := net.SplitHostPort(ins.Addr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid instance addr %q: %w", ins.Addr, err)
+	}
+	q := url.Values{
+		"serviceName": {ins.Service},
+		"groupName":   {b.cfg.Group},
+		"ip":          {host},
+		"port":        {port},
+		"ephemeral":   {"true"},
+	}
+	if b.cfg.Namespace != "" {
+		q.Set("namespaceId", b.cfg.Namespace)
+	}
+	return q, nil
+}
+
+// Register 注册临时实例并开始发送心跳，心跳返回实例不存在时重新注册
+func (b *nacosBackend) Register(ctx context.Context, ins Instance) error {
+	ins.ID = ins.key()
+	if err := b.register(ctx, ins); err != nil {
+		return err
+	}
+
+	beatCtx, cancel := context.WithCancel(context.Background())
+	beat := &nacosBeat{ins: ins, cancel: cancel, done: make(chan struct{})}
+	b.mu.Lock()
+	old := b.beats[ins.ID]
+	b.beats[ins.ID] = beat
+	b.mu.Unlock()
+	if old != nil {
+		old.stop()
+	}
+
+	go b.heartbeat(beatCtx, beat, ins)
+	return nil
+}
+
+func (b *nacosBackend) register(ctx context.Context, ins Instance) error {
+	q, err := b.instanceParams(ins)
+	if err != nil {
+		return err
+	}
+	metadata, err := json.Marshal(ins.Metadata)
+	if err != nil {
+		return err
+	}
+	q.Set("metadata", string(metadata))
+	q.Set("weight", "1")
+	q.Set("enabled", "true")
+	q.Set("healthy", "true")
+	if _, err := b.call(ctx, http.MethodPost, "/nacos/v1/ns/instance", q); err != nil {
+		return fmt.Errorf("register nacos instance failed: %w", err)
+	}
+	return nil
+}
+
+func (b *nacosBackend) heartbeat(ctx context.Context, beat *nacosBeat, ins Instance) {
+	defer close(beat.done)
+	ticker := time.NewTicker(b.cfg.BeatInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		if err := b.beat(ctx, ins); err != nil && ctx.Err() == nil {
+			hlog.CtxWarnf(ctx, "[Discovery] nacos heartbeat of %s failed: %v", ins.ID, err)
+		}
+	}
+}
+
+func (b *nacosBackend) beat(ctx context.Context, ins Instance) error {
+	q, err := b.instanceParams(ins)
+	if err != nil {
+		return err
+	}
+	port, _ := strconv.Atoi(q.Get("port"))
+	info, err := json.Marshal(map[string]any{
+		"serviceName": b.cfg.Group + "@@" + ins.Service,
+		"ip":          q.Get("ip"),
+		"port":        port,
+		"cluster":     "DEFAULT",
+		"scheduled":   true,
+		"metadata":    ins.Metadata,
+	})
+	if err != nil {
+		return err
+	}
+	q.Set("beat", string(info))
+	body, err := b.call(ctx, http.MethodPut, "/nacos/v1/ns/instance/beat", q)
+	if err != nil {
+		return err
+	}
+	var res struct {
+		Code int `json:"code"`
+	}
+	if err := json.Unmarshal(body, &res); err == nil && res.Code == nacosResourceNotFound {
+		hlog.CtxWarnf(ctx, "[Discovery] nacos instance %s not found, registering again", ins.ID)
+		return b.register(ctx, ins)
+	}
+	return nil
+}
+
+func (bt *nacosBeat) stop() {
+	bt.cancel()
+	<-bt.done
+}
+
+// Deregister 停止心跳并注销实例
+func (b *nacosBackend) Deregister(ctx context.Context, ins Instance) error {
+	id := ins.key()
+	b.mu.Lock()
+	beat := b.beats[id]
+	delete(b.beats, id)
+	b.mu.Unlock()
+	if beat != nil {
+		beat.stop()
+	}
+	return b.deregister(ctx, ins)
+}
+
+func (b *nacosBackend) deregister(ctx context.Context, ins Instance) error {
+	q, err := b.instanceParams(ins)
+	if err != nil {
+		return err
+	}
+	if _, err := b.call(ctx, http.MethodDelete, "/nacos/v1/ns/instance", q); err != nil {
+		return fmt.Errorf("deregister nacos instance failed: %w", err)
+	}
+	return nil
+}
+
+// Watch 按 PollInterval 查询健康的实例，实例列表变化时调用 update
+func (b *nacosBackend) Watch(ctx context.Context, service string, update func([]Instance)) error {
+	ticker := time.NewTicker(b.cfg.PollInterval)
+	defer ticker.Stop()
+
+	var last []Instance
+	for first := true; ; first = false {
+		instances, err := b.list(ctx, service)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+		if first || !reflect.DeepEqual(instances, last) {
+			update(instances)
+			last = instances
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+func (b *nacosBackend) list(ctx context.Context, service string) ([]Instance, error) {
+	q := url.Values{
+		"serviceName": {service},
+		"groupName":   {b.cfg.Group},
+		"healthyOnly": {"true"},
+	}
+	if b.cfg.Namespace != "" {
+		q.Set("namespaceId", b.cfg.Namespace)
+	}
+	body, err := b.call(ctx, http.MethodGet, "/nacos/v1/ns/instance/list", q)
+	if err != nil {
+		return nil, fmt.Errorf("list nacos instances failed: %w", err)
+	}
+
+	var res struct {
+		Hosts []struct {
+			InstanceID string            `json:"instanceId"`
+			IP         string            `json:"ip"`
+			Port       int               `json:"port"`
+			Healthy    bool              `json:"healthy"`
+			Enabled    bool              `json:"enabled"`
+			Metadata   map[string]string `json:"metadata"`
+		} `json:"hosts"`
+	}
+	if err := json.Unmarshal(body, &res); err != nil {
+		return nil, fmt.Errorf("parse nacos instances failed: %w", err)
+	}
+	instances := make([]Instance, 0, len(res.Hosts))
+	for _, h := range res.Hosts {
+		if !h.Healthy || !h.Enabled {
+			continue
+		}
+		instances = append(instances, Instance{
+			ID:       h.InstanceID,
+			Service:  service,
+			Addr:     net.JoinHostPort(h.IP, strconv.Itoa(h.Port)),
+			Metadata: h.Metadata,
+		})
+	}
+	return sortInstances(instances), nil
+}
+
+// Close 注销本进程注册的全部实例
+func (b *nacosBackend) Close() error {
+	b.mu.Lock()
+	beats := b.beats
+	b.beats = make(map[string]*nacosBeat)
+	b.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	var errs []error
+	for _, beat := range beats {
+		beat.stop()
+		errs = append(errs, b.deregister(ctx, beat.ins))
+	}
+	return errors.Join(errs...)
+}
+
+// call 发送 Open API 请求，开启鉴权时带上 accessToken
+func (b *nacosBackend) call(ctx context.Context, method, path string, q url.Values) ([]byte, error) {
+	if err := b.auth(ctx, q); err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, method, b.cfg.Addr+path+"?"+q.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	return b.do(req)
+}
+
+// auth 开启鉴权时登录并在查询参数中追加 accessToken，token 过期前复用
+func (b *nacosBackend) auth(ctx context.Context, q url.Values) error {
+	if b.cfg.Username == "" {
+		return nil
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.token == "" || time.Now().After(b.tokenExpire) {
+		form := url.Values{"username": {b.cfg.Username}, "password": {b.cfg.Password}}
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, b.cfg.Addr+"/nacos/v1/auth/login", strings.NewReader(form.Encode()))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		body, err := b.do(req)
+		if err != nil {
+			return fmt.Errorf("nacos login failed: %w", err)
+		}
+
+		var res struct {
+			AccessToken string `json:"accessToken"`
+			TokenTTL    int64  `json:"tokenTtl"`
+		}
+		if err := json.Unmarshal(body, &res); err != nil {
+			return fmt.Errorf("parse nacos login response failed: %w", err)
+		}
+		b.token = res.AccessToken
+		// 提前 10% 刷新，避免请求途中过期
+		b.tokenExpire = time.Now().Add(time.Duration(res.TokenTTL) * time.Second * 9 / 10)
+	}
+	q.Set("accessToken", b.token)
+	return nil
+}
+
+func (b *nacosBackend) do(req *http.Request) ([]byte, error) {
+	resp, err := b.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("nacos returned status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return body, nil
+}
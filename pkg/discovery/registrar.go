@@ -0,0 +1,118 @@
+package discovery
+
+import (
+	"context"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/common/hlog"
+
+	"github.com/ZampoRen/go-server-comon/pkg/health"
+)
+
+// RegistrarOption 注册器选项
+type RegistrarOption func(o *registrarOption)
+
+type registrarOption struct {
+	health   *health.Registry
+	interval time.Duration
+}
+
+// WithHealth 设置决定是否注册的健康检查，默认 health.Default()，为 nil 时不检查，启动后始终注册
+func WithHealth(r *health.Registry) RegistrarOption {
+	return func(o *registrarOption) {
+		o.health = r
+	}
+}
+
+// WithCheckInterval 设置执行就绪检查的间隔，默认 5s
+func WithCheckInterval(d time.Duration) RegistrarOption {
+	return func(o *registrarOption) {
+		if d > 0 {
+			o.interval = d
+		}
+	}
+}
+
+// Registrar 按就绪检查的结果注册实例：就绪时注册，不就绪（包括 health.Registry.Shutdown 之后）时注销，
+// 使调用方只连接能正常处理请求的实例。Start、Stop 可直接作为 lifecycle.Hook 的 OnStart、OnStop
+type Registrar struct {
+	reg Registry
+	ins Instance
+	o   *registrarOption
+
+	cancel     context.CancelFunc
+	done       chan struct{}
+	registered bool
+}
+
+// NewRegistrar 创建注册器，ins.ID 为空时使用 Service 与 Addr 生成
+func NewRegistrar(reg Registry, ins Instance, opts ...RegistrarOption) *Registrar {
+	o := &registrarOption{health: health.Default(), interval: 5 * time.Second}
+	for _, opt := range opts {
+		opt(o)
+	}
+	ins.ID = ins.key()
+	return &Registrar{reg: reg, ins: ins, o: o}
+}
+
+// Start 执行一次就绪检查，就绪时注册实例，之后按间隔在后台同步注册状态，首次注册失败时返回错误
+func (r *Registrar) Start(ctx context.Context) error {
+	if err := r.sync(ctx); err != nil {
+		return err
+	}
+	loopCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	r.cancel = cancel
+	r.done = make(chan struct{})
+	go r.loop(loopCtx)
+	return nil
+}
+
+// Stop 停止后台同步并注销实例，应在停止服务之前调用
+func (r *Registrar) Stop(ctx context.Context) error {
+	if r.cancel != nil {
+		r.cancel()
+		<-r.done
+	}
+	if !r.registered {
+		return nil
+	}
+	r.registered = false
+	hlog.CtxInfof(ctx, "[Discovery] deregister %s addr=%s", r.ins.Service, r.ins.Addr)
+	return r.reg.Deregister(ctx, r.ins)
+}
+
+func (r *Registrar) loop(ctx context.Context) {
+	defer close(r.done)
+	ticker := time.NewTicker(r.o.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		if err := r.sync(ctx); err != nil && ctx.Err() == nil {
+			hlog.CtxWarnf(ctx, "[Discovery] sync registration of %s failed: %v", r.ins.Service, err)
+		}
+	}
+}
+
+// sync 按就绪检查的结果注册或注销实例
+func (r *Registrar) sync(ctx context.Context) error {
+	ready := r.o.health == nil || r.o.health.Ready(ctx).Healthy()
+	switch {
+	case ready && !r.registered:
+		if err := r.reg.Register(ctx, r.ins); err != nil {
+			return err
+		}
+		r.registered = true
+		hlog.CtxInfof(ctx, "[Discovery] register %s addr=%s", r.ins.Service, r.ins.Addr)
+	case !ready && r.registered:
+		if err := r.reg.Deregister(ctx, r.ins); err != nil {
+			return err
+		}
+		r.registered = false
+		hlog.CtxWarnf(ctx, "[Discovery] %s is not ready, deregistered addr=%s", r.ins.Service, r.ins.Addr)
+	}
+	return nil
+}
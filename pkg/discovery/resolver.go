@@ -0,0 +1,91 @@
+package discovery
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/common/hlog"
+	"google.golang.org/grpc/resolver"
+
+	"github.com/ZampoRen/go-server-comon/pkg/retry"
+)
+
+// Scheme gRPC target 的 scheme，如 discovery:///user-service
+const Scheme = "discovery"
+
+// NewResolverBuilder 返回通过 d 解析 discovery:///服务名 的 gRPC resolver，
+// 通过 grpc.WithResolvers 或 rpcclient.WithDiscovery 使用
+func NewResolverBuilder(d Discovery) resolver.Builder {
+	return &resolverBuilder{d: d}
+}
+
+// RegisterResolver 全局注册 discovery scheme 的 resolver，应在初始化时调用
+func RegisterResolver(d Discovery) {
+	resolver.Register(NewResolverBuilder(d))
+}
+
+type resolverBuilder struct {
+	d Discovery
+}
+
+func (b *resolverBuilder) Scheme() string {
+	return Scheme
+}
+
+func (b *resolverBuilder) Build(target resolver.Target, cc resolver.ClientConn, _ resolver.BuildOptions) (resolver.Resolver, error) {
+	service := strings.TrimPrefix(target.Endpoint(), "/")
+	if service == "" {
+		return nil, fmt.Errorf("service name is required in target %q", target.URL.String())
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	r := &discoveryResolver{cancel: cancel, done: make(chan struct{})}
+	go r.watch(ctx, b.d, service, cc)
+	return r, nil
+}
+
+// discoveryResolver 持续监听服务的实例，出错时上报给 gRPC 并按退避重新监听
+type discoveryResolver struct {
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+func (r *discoveryResolver) watch(ctx context.Context, d Discovery, service string, cc resolver.ClientConn) {
+	defer close(r.done)
+	for attempt := 0; ; attempt++ {
+		err := d.Watch(ctx, service, func(instances []Instance) {
+			attempt = 0
+			addrs := make([]resolver.Address, len(instances))
+			for i, ins := range instances {
+				addrs[i] = resolver.Address{Addr: ins.Addr}
+			}
+			// 没有实例时同样更新，调用失败并返回 Unavailable，而不是继续连接已下线的实例
+			_ = cc.UpdateState(resolver.State{Addresses: addrs})
+		})
+		if ctx.Err() != nil {
+			return
+		}
+		if err == nil {
+			err = errors.New("watch stopped")
+		}
+		cc.ReportError(err)
+		hlog.CtxWarnf(ctx, "[Discovery] watch %s failed: %v", service, err)
+
+		timer := time.NewTimer(retry.Backoff(time.Second, 30*time.Second, attempt))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+	}
+}
+
+func (r *discoveryResolver) ResolveNow(resolver.ResolveNowOptions) {}
+
+func (r *discoveryResolver) Close() {
+	r.cancel()
+	<-r.done
+}
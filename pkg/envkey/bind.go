@@ -0,0 +1,254 @@
+package envkey
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// Bind 根据 env 标签从环境变量填充结构体字段，v 必须为结构体指针
+// 标签格式为 `env:"NAME,required,sep=;"`：
+//   - required: 未设置时报错
+//   - sep: 切片元素的分隔符，默认为逗号
+//
+// 变量未设置时字段保留原值，原值为零值且有 default 标签时使用默认值，见 ApplyDefaults。
+//
+// 支持 string、bool、整数、浮点数、time.Duration 及其切片。
+// 结构体字段的标签名作为内部字段的前缀，如 `env:"REDIS_"` 下的 `env:"ADDR"` 读取 REDIS_ADDR，没有标签时不加前缀
+// 所有缺失与格式错误的变量汇总到一个错误码为 ErrCodeInvalidEnv 的错误中返回
+//
+//	type RedisConfig struct {
+//		Addr        string        `env:"ADDR" default:"localhost:6379"`
+//		DialTimeout time.Duration `env:"DIAL_TIMEOUT" default:"5s"`
+//	}
+//	type Config struct {
+//		Redis RedisConfig `env:"REDIS_"`
+//		DSN   string      `env:"MYSQL_DSN,required"`
+//	}
+func Bind(v any) error {
+	return BindWithPrefix("", v)
+}
+
+// BindWithPrefix 同 Bind，所有变量名都加上 prefix，用于同一配置结构的多个实例，如 MYSQL_ORDER_
+func BindWithPrefix(prefix string, v any) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("envkey bind: want non-nil struct pointer, got %T", v)
+	}
+
+	b := &binder{}
+	if err := b.bindStruct(prefix, rv.Elem()); err != nil {
+		return err
+	}
+	return invalidEnvError(b.missing, b.invalid, b.details)
+}
+
+// binder 记录绑定过程中缺失与格式错误的变量
+type binder struct {
+	missing []string
+	invalid []string
+	details []string
+}
+
+func (b *binder) bindStruct(prefix string, rv reflect.Value) error {
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		f := rt.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		tag, ok := f.Tag.Lookup("env")
+		if tag == "-" {
+			continue
+		}
+
+		fv := rv.Field(i)
+		if f.Type.Kind() == reflect.Struct && f.Type != durationType {
+			if err := b.bindStruct(prefix+tag, fv); err != nil {
+				return err
+			}
+			continue
+		}
+		if !ok {
+			continue
+		}
+
+		t, err := parseEnvTag(tag)
+		if err != nil {
+			return fmt.Errorf("envkey bind: field %s: %w", f.Name, err)
+		}
+		key := prefix + t.name
+
+		value := os.Getenv(key)
+		if value == "" {
+			if t.required {
+				b.missing = append(b.missing, key)
+				continue
+			}
+			def, ok := f.Tag.Lookup("default")
+			if !ok || !fv.IsZero() {
+				continue
+			}
+			value = def
+		}
+
+		if err := setValue(fv, value, t.sep); err != nil {
+			var ute unsupportedTypeError
+			if errors.As(err, &ute) {
+				return fmt.Errorf("envkey bind: field %s: %w", f.Name, err)
+			}
+			b.invalid = append(b.invalid, key)
+			b.details = append(b.details, fmt.Sprintf("%s=%q (%v)", key, value, err))
+		}
+	}
+	return nil
+}
+
+// ApplyDefaults 按 default 标签为零值字段填充默认值，v 必须为结构体指针，嵌套结构体递归处理
+// 默认值的格式与环境变量相同，如 `default:"5s"`、`default:"100"`，切片按 env 标签的 sep（默认逗号）分隔，
+// 如 `default:"a,b"`。默认值格式错误属于编码错误，直接返回
+func ApplyDefaults(v any) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("envkey apply defaults: want non-nil struct pointer, got %T", v)
+	}
+	return applyDefaults(rv.Elem())
+}
+
+func applyDefaults(rv reflect.Value) error {
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		f := rt.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		fv := rv.Field(i)
+		if f.Type.Kind() == reflect.Struct && f.Type != durationType {
+			if err := applyDefaults(fv); err != nil {
+				return err
+			}
+			continue
+		}
+
+		def, ok := f.Tag.Lookup("default")
+		if !ok || !fv.IsZero() {
+			continue
+		}
+		sep := ","
+		if tag, ok := f.Tag.Lookup("env"); ok && tag != "-" {
+			t, err := parseEnvTag(tag)
+			if err != nil {
+				return fmt.Errorf("envkey apply defaults: field %s: %w", f.Name, err)
+			}
+			sep = t.sep
+		}
+		if err := setValue(fv, def, sep); err != nil {
+			return fmt.Errorf("envkey apply defaults: field %s: invalid default %q: %w", f.Name, def, err)
+		}
+	}
+	return nil
+}
+
+// envTag 解析后的 env 标签
+type envTag struct {
+	name     string
+	required bool
+	sep      string
+}
+
+func parseEnvTag(tag string) (envTag, error) {
+	name, opts, _ := strings.Cut(tag, ",")
+	t := envTag{name: name, sep: ","}
+	if name == "" {
+		return t, fmt.Errorf("empty env name")
+	}
+
+	for opts != "" {
+		var opt string
+		opt, opts, _ = strings.Cut(opts, ",")
+		switch {
+		case opt == "required":
+			t.required = true
+		case strings.HasPrefix(opt, "sep="):
+			t.sep = strings.TrimPrefix(opt, "sep=")
+		default:
+			return t, fmt.Errorf("unknown env tag option %q", opt)
+		}
+	}
+	return t, nil
+}
+
+// unsupportedTypeError 字段类型不支持，属于编码错误，直接返回而不计入格式错误
+type unsupportedTypeError struct {
+	t reflect.Type
+}
+
+func (e unsupportedTypeError) Error() string {
+	return fmt.Sprintf("unsupported type %s", e.t)
+}
+
+func setValue(fv reflect.Value, value, sep string) error {
+	if fv.Type() == durationType {
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return err
+		}
+		fv.SetInt(int64(d))
+		return nil
+	}
+
+	switch fv.Kind() {
+	case reflect.String:
+		fv.SetString(value)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		fv.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(value, 10, fv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		fv.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, err := strconv.ParseUint(value, 10, fv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		fv.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(value, fv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		fv.SetFloat(f)
+	case reflect.Slice:
+		if fv.Type().Elem().Kind() == reflect.Slice {
+			return unsupportedTypeError{t: fv.Type()}
+		}
+		var items []string
+		for _, item := range strings.Split(value, sep) {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		slice := reflect.MakeSlice(fv.Type(), len(items), len(items))
+		for i, item := range items {
+			if err := setValue(slice.Index(i), item, sep); err != nil {
+				return err
+			}
+		}
+		fv.Set(slice)
+	default:
+		return unsupportedTypeError{t: fv.Type()}
+	}
+	return nil
+}
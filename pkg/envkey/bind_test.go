@@ -0,0 +1,108 @@
+package envkey
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/ZampoRen/go-server-comon/pkg/errorx"
+)
+
+type bindRedisConfig struct {
+	Addr        string        `env:"ADDR" default:"localhost:6379"`
+	DB          int           `env:"DB"`
+	DialTimeout time.Duration `env:"DIAL_TIMEOUT" default:"5s"`
+}
+
+type bindConfig struct {
+	Redis   bindRedisConfig `env:"REDIS_"`
+	DSN     string          `env:"MYSQL_DSN,required"`
+	Debug   bool            `env:"DEBUG"`
+	Ratio   float64         `env:"RATIO" default:"0.5"`
+	Brokers []string        `env:"BROKERS,sep=;" default:"a:9092,b:9092"`
+	Ports   []int           `env:"PORTS"`
+	Keep    string          `env:"KEEP"`
+}
+
+func TestBind(t *testing.T) {
+	t.Run("填充与默认值", func(t *testing.T) {
+		t.Setenv("T_REDIS_DB", "2")
+		t.Setenv("T_MYSQL_DSN", "root@/db")
+		t.Setenv("T_DEBUG", "true")
+		t.Setenv("T_PORTS", "80, 443")
+
+		cfg := bindConfig{Keep: "preset"}
+		if err := BindWithPrefix("T_", &cfg); err != nil {
+			t.Fatalf("Bind() error = %v", err)
+		}
+		want := bindConfig{
+			Redis:   bindRedisConfig{Addr: "localhost:6379", DB: 2, DialTimeout: 5 * time.Second},
+			DSN:     "root@/db",
+			Debug:   true,
+			Ratio:   0.5,
+			Brokers: []string{"a:9092,b:9092"},
+			Ports:   []int{80, 443},
+			Keep:    "preset",
+		}
+		if !reflect.DeepEqual(cfg, want) {
+			t.Errorf("Bind() = %+v, want %+v", cfg, want)
+		}
+	})
+
+	t.Run("汇总错误", func(t *testing.T) {
+		t.Setenv("T_REDIS_DIAL_TIMEOUT", "5")
+		t.Setenv("T_PORTS", "80,x")
+
+		var cfg bindConfig
+		err := BindWithPrefix("T_", &cfg)
+		var se errorx.StatusError
+		if !errors.As(err, &se) || se.Code() != ErrCodeInvalidEnv {
+			t.Fatalf("Bind() error = %v, want ErrCodeInvalidEnv", err)
+		}
+		if got := se.Extra()["missing"]; got != "T_MYSQL_DSN" {
+			t.Errorf("missing = %s", got)
+		}
+		if got := se.Extra()["invalid"]; got != "T_REDIS_DIAL_TIMEOUT,T_PORTS" {
+			t.Errorf("invalid = %s", got)
+		}
+	})
+
+	t.Run("已有值时不使用默认值", func(t *testing.T) {
+		t.Setenv("T_MYSQL_DSN", "root@/db")
+		cfg := bindConfig{Redis: bindRedisConfig{Addr: "redis:6379"}}
+		if err := BindWithPrefix("T_", &cfg); err != nil {
+			t.Fatal(err)
+		}
+		if cfg.Redis.Addr != "redis:6379" || cfg.Redis.DialTimeout != 5*time.Second {
+			t.Errorf("redis = %+v", cfg.Redis)
+		}
+	})
+
+	t.Run("非结构体指针", func(t *testing.T) {
+		var cfg bindConfig
+		if err := Bind(cfg); err == nil {
+			t.Error("Bind() error = nil, want error")
+		}
+	})
+}
+
+func TestApplyDefaults(t *testing.T) {
+	cfg := bindConfig{Ratio: 0.9}
+	if err := ApplyDefaults(&cfg); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Redis.Addr != "localhost:6379" || cfg.Redis.DialTimeout != 5*time.Second || cfg.Ratio != 0.9 {
+		t.Errorf("ApplyDefaults() = %+v", cfg)
+	}
+	if !reflect.DeepEqual(cfg.Brokers, []string{"a:9092,b:9092"}) {
+		t.Errorf("brokers = %v", cfg.Brokers)
+	}
+
+	var bad struct {
+		Port int `default:"x"`
+	}
+	if err := ApplyDefaults(&bad); err == nil {
+		t.Error("ApplyDefaults() error = nil, want invalid default error")
+	}
+}
@@ -0,0 +1,122 @@
+package envkey
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// DefaultDotenvPaths LoadDotenv 未指定路径时加载的文件，.env.local 用于本地覆盖，不应提交到仓库
+var DefaultDotenvPaths = []string{".env", ".env.local"}
+
+// LoadDotenv 加载 .env 文件中的变量到进程环境变量，需要在读取任何环境变量之前调用，通常位于 main 的开头
+// 未指定 paths 时加载 DefaultDotenvPaths，不存在的文件会被忽略
+// 优先级：真实环境变量 > 后面的文件 > 前面的文件，即已设置的环境变量不会被覆盖
+//
+// 文件格式为每行一个 KEY=VALUE，支持 # 注释、export 前缀，
+// 双引号值支持 \n、\t、\"、\\ 转义，单引号值按原样读取，无引号值中空白后的 # 视为注释
+func LoadDotenv(paths ...string) error {
+	if len(paths) == 0 {
+		paths = DefaultDotenvPaths
+	}
+
+	values := make(map[string]string)
+	for _, path := range paths {
+		if err := parseDotenvFile(path, values); err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+			return err
+		}
+	}
+
+	for k, v := range values {
+		if _, ok := os.LookupEnv(k); ok {
+			continue
+		}
+		if err := os.Setenv(k, v); err != nil {
+			return fmt.Errorf("set env %s failed: %w", k, err)
+		}
+	}
+	return nil
+}
+
+// parseDotenvFile 解析 path 并写入 values，后出现的同名变量覆盖先出现的
+func parseDotenvFile(path string, values map[string]string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		key, value, err := parseDotenvLine(line)
+		if err != nil {
+			return fmt.Errorf("parse %s:%d failed: %w", path, lineNo, err)
+		}
+		values[key] = value
+	}
+	return scanner.Err()
+}
+
+func parseDotenvLine(line string) (string, string, error) {
+	line = strings.TrimPrefix(line, "export ")
+	key, value, ok := strings.Cut(line, "=")
+	if !ok {
+		return "", "", fmt.Errorf("missing '=' in %q", line)
+	}
+	key = strings.TrimSpace(key)
+	if key == "" || strings.ContainsAny(key, " \t") {
+		return "", "", fmt.Errorf("invalid key %q", key)
+	}
+	value = strings.TrimSpace(value)
+
+	switch {
+	case strings.HasPrefix(value, `"`):
+		end := closingQuote(value)
+		if end < 0 {
+			return "", "", fmt.Errorf("unterminated double quote in %s", key)
+		}
+		unquoted, err := strconv.Unquote(value[:end+1])
+		if err != nil {
+			return "", "", fmt.Errorf("invalid double quoted value in %s: %w", key, err)
+		}
+		return key, unquoted, nil
+	case strings.HasPrefix(value, "'"):
+		end := strings.Index(value[1:], "'")
+		if end < 0 {
+			return "", "", fmt.Errorf("unterminated single quote in %s", key)
+		}
+		return key, value[1 : end+1], nil
+	default:
+		if i := strings.Index(value, " #"); i >= 0 {
+			value = value[:i]
+		} else if i := strings.Index(value, "\t#"); i >= 0 {
+			value = value[:i]
+		}
+		return key, strings.TrimSpace(value), nil
+	}
+}
+
+// closingQuote 返回与开头双引号匹配的结束双引号下标，跳过转义的引号，没有时返回 -1
+func closingQuote(s string) int {
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '"':
+			return i
+		}
+	}
+	return -1
+}
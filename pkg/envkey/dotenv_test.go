@@ -0,0 +1,58 @@
+package envkey
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadDotenv(t *testing.T) {
+	dir := t.TempDir()
+	base := filepath.Join(dir, ".env")
+	local := filepath.Join(dir, ".env.local")
+	if err := os.WriteFile(base, []byte(`# 本地开发配置
+DOTENV_TEST_ADDR=localhost:6379 # 注释
+export DOTENV_TEST_QUOTED="a \"b\"\nc"
+DOTENV_TEST_SINGLE='x #y'
+DOTENV_TEST_REAL=from-file
+DOTENV_TEST_OVERRIDE=base
+`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(local, []byte("DOTENV_TEST_OVERRIDE=local\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv("DOTENV_TEST_REAL", "from-env")
+	for _, k := range []string{"DOTENV_TEST_ADDR", "DOTENV_TEST_QUOTED", "DOTENV_TEST_SINGLE", "DOTENV_TEST_OVERRIDE"} {
+		t.Setenv(k, "")
+		os.Unsetenv(k)
+	}
+
+	if err := LoadDotenv(base, local, filepath.Join(dir, "missing.env")); err != nil {
+		t.Fatalf("LoadDotenv() error = %v", err)
+	}
+
+	want := map[string]string{
+		"DOTENV_TEST_ADDR":     "localhost:6379",
+		"DOTENV_TEST_QUOTED":   "a \"b\"\nc",
+		"DOTENV_TEST_SINGLE":   "x #y",
+		"DOTENV_TEST_REAL":     "from-env",
+		"DOTENV_TEST_OVERRIDE": "local",
+	}
+	for k, v := range want {
+		if got := os.Getenv(k); got != v {
+			t.Errorf("%s = %q, want %q", k, got, v)
+		}
+	}
+}
+
+func TestLoadDotenvInvalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".env")
+	if err := os.WriteFile(path, []byte("VALID=1\nINVALID\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := LoadDotenv(path); err == nil {
+		t.Error("LoadDotenv() error = nil, want error")
+	}
+}
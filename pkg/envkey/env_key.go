@@ -4,6 +4,10 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/common/hlog"
 )
 
 func GetIntD(key string, defaultValue int) int {
@@ -87,3 +91,57 @@ func GetBoolD(key string, defaultValue bool) bool {
 
 	return b
 }
+
+// GetDurationD 读取 time.ParseDuration 格式的时长，如 "5s"、"10m"
+// 未设置时返回默认值，格式错误时打印告警并返回默认值
+func GetDurationD(key string, defaultValue time.Duration) time.Duration {
+	v := os.Getenv(key)
+	if v == "" {
+		return defaultValue
+	}
+
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		hlog.Warnf("[envkey] invalid duration %s=%q, use default %v: %v", key, v, defaultValue, err)
+		return defaultValue
+	}
+
+	return d
+}
+
+// GetFloatD 读取浮点数，未设置时返回默认值，格式错误时打印告警并返回默认值
+func GetFloatD(key string, defaultValue float64) float64 {
+	v := os.Getenv(key)
+	if v == "" {
+		return defaultValue
+	}
+
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		hlog.Warnf("[envkey] invalid float %s=%q, use default %v: %v", key, v, defaultValue, err)
+		return defaultValue
+	}
+
+	return f
+}
+
+// GetStringSliceD 读取以 sep 分隔的字符串列表，去除各项首尾空白并忽略空项
+// 未设置或没有非空项时返回默认值
+func GetStringSliceD(key, sep string, defaultValue []string) []string {
+	v := os.Getenv(key)
+	if v == "" {
+		return defaultValue
+	}
+
+	var items []string
+	for _, item := range strings.Split(v, sep) {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	if len(items) == 0 {
+		return defaultValue
+	}
+
+	return items
+}
@@ -0,0 +1,76 @@
+package envkey
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/ZampoRen/go-server-comon/pkg/errorx"
+)
+
+func TestGetDurationD(t *testing.T) {
+	t.Run("未设置", func(t *testing.T) {
+		if got := GetDurationD("ENVKEY_TEST_DURATION", time.Second); got != time.Second {
+			t.Errorf("GetDurationD() = %v, want 1s", got)
+		}
+	})
+	t.Run("合法值", func(t *testing.T) {
+		t.Setenv("ENVKEY_TEST_DURATION", "150ms")
+		if got := GetDurationD("ENVKEY_TEST_DURATION", time.Second); got != 150*time.Millisecond {
+			t.Errorf("GetDurationD() = %v, want 150ms", got)
+		}
+	})
+	t.Run("非法值", func(t *testing.T) {
+		t.Setenv("ENVKEY_TEST_DURATION", "5")
+		if got := GetDurationD("ENVKEY_TEST_DURATION", time.Second); got != time.Second {
+			t.Errorf("GetDurationD() = %v, want 1s", got)
+		}
+	})
+}
+
+func TestGetStringSliceD(t *testing.T) {
+	t.Setenv("ENVKEY_TEST_SLICE", " a, b ,,c ")
+	if got := GetStringSliceD("ENVKEY_TEST_SLICE", ",", nil); !reflect.DeepEqual(got, []string{"a", "b", "c"}) {
+		t.Errorf("GetStringSliceD() = %v", got)
+	}
+
+	t.Setenv("ENVKEY_TEST_SLICE", " , ")
+	if got := GetStringSliceD("ENVKEY_TEST_SLICE", ",", []string{"x"}); !reflect.DeepEqual(got, []string{"x"}) {
+		t.Errorf("GetStringSliceD() = %v, want default", got)
+	}
+}
+
+func TestGetFloatD(t *testing.T) {
+	t.Setenv("ENVKEY_TEST_FLOAT", "0.25")
+	if got := GetFloatD("ENVKEY_TEST_FLOAT", 1); got != 0.25 {
+		t.Errorf("GetFloatD() = %v, want 0.25", got)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	t.Setenv("ENVKEY_TEST_SET", "v9")
+	t.Setenv("ENVKEY_TEST_TIMEOUT", "3")
+
+	err := Validate(
+		Required("ENVKEY_TEST_MISSING_B"),
+		Required("ENVKEY_TEST_MISSING_A"),
+		Required("ENVKEY_TEST_SET", OneOf("v7", "v8")),
+		Optional("ENVKEY_TEST_TIMEOUT", IsDuration),
+		Optional("ENVKEY_TEST_UNSET", IsDuration),
+	)
+	var se errorx.StatusError
+	if !errors.As(err, &se) || se.Code() != ErrCodeInvalidEnv {
+		t.Fatalf("Validate() error = %v, want ErrCodeInvalidEnv", err)
+	}
+	if got := se.Extra()["missing"]; got != "ENVKEY_TEST_MISSING_A,ENVKEY_TEST_MISSING_B" {
+		t.Errorf("missing = %s", got)
+	}
+	if got := se.Extra()["invalid"]; got != "ENVKEY_TEST_SET,ENVKEY_TEST_TIMEOUT" {
+		t.Errorf("invalid = %s", got)
+	}
+
+	if err := Validate(Required("ENVKEY_TEST_SET"), Optional("ENVKEY_TEST_UNSET", IsInt)); err != nil {
+		t.Errorf("Validate() error = %v, want nil", err)
+	}
+}
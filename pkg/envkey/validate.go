@@ -0,0 +1,147 @@
+package envkey
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ZampoRen/go-server-comon/pkg/errorx"
+	"github.com/ZampoRen/go-server-comon/pkg/errorx/code"
+)
+
+// ErrCodeInvalidEnv 环境变量缺失或格式错误
+const ErrCodeInvalidEnv int32 = 100300
+
+var (
+	// ErrNotSet 环境变量未设置或为空
+	ErrNotSet = errors.New("env not set")
+)
+
+func init() {
+	code.Register(ErrCodeInvalidEnv, "环境变量配置错误: {details}")
+}
+
+// GetStringE 读取必填的字符串，未设置或为空时返回 ErrNotSet
+func GetStringE(key string) (string, error) {
+	v := os.Getenv(key)
+	if v == "" {
+		return "", fmt.Errorf("%w: %s", ErrNotSet, key)
+	}
+	return v, nil
+}
+
+// MustGetString 读取必填的字符串，未设置或为空时 panic，仅用于启动阶段
+func MustGetString(key string) string {
+	v, err := GetStringE(key)
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+// Requirement 环境变量校验规则
+type Requirement struct {
+	Key      string             // 环境变量名
+	Optional bool               // 是否可以不设置，可选变量只在设置时校验格式
+	Check    func(string) error // 格式校验，为 nil 时只检查是否设置
+}
+
+// Required 必须设置的环境变量，check 为 nil 时只检查是否设置
+func Required(key string, check ...func(string) error) Requirement {
+	r := Requirement{Key: key}
+	if len(check) > 0 {
+		r.Check = check[0]
+	}
+	return r
+}
+
+// Optional 可以不设置的环境变量，设置时使用 check 校验格式
+func Optional(key string, check func(string) error) Requirement {
+	return Requirement{Key: key, Optional: true, Check: check}
+}
+
+// IsInt 校验整数格式
+func IsInt(v string) error {
+	_, err := strconv.ParseInt(v, 10, 64)
+	return err
+}
+
+// IsBool 校验布尔值格式
+func IsBool(v string) error {
+	_, err := strconv.ParseBool(v)
+	return err
+}
+
+// IsDuration 校验 time.ParseDuration 格式
+func IsDuration(v string) error {
+	_, err := time.ParseDuration(v)
+	return err
+}
+
+// OneOf 校验取值是否在 values 中
+func OneOf(values ...string) func(string) error {
+	return func(v string) error {
+		for _, value := range values {
+			if v == value {
+				return nil
+			}
+		}
+		return fmt.Errorf("want one of %s", strings.Join(values, "|"))
+	}
+}
+
+// Validate 在启动时一次性校验所有环境变量，返回汇总了全部问题的 errorx 错误（错误码 ErrCodeInvalidEnv）
+// 缺失与格式错误的变量名分别记录在 Extra 的 missing 与 invalid 中，全部通过时返回 nil
+//
+//	if err := envkey.Validate(
+//		envkey.Required("MYSQL_DSN"),
+//		envkey.Required("ES_VERSION", envkey.OneOf("v7", "v8")),
+//		envkey.Optional("REDIS_DIAL_TIMEOUT", envkey.IsDuration),
+//	); err != nil {
+//		log.Fatal(err)
+//	}
+func Validate(requirements ...Requirement) error {
+	var missing, invalid, details []string
+	for _, r := range requirements {
+		v := os.Getenv(r.Key)
+		if v == "" {
+			if !r.Optional {
+				missing = append(missing, r.Key)
+			}
+			continue
+		}
+		if r.Check == nil {
+			continue
+		}
+		if err := r.Check(v); err != nil {
+			invalid = append(invalid, r.Key)
+			details = append(details, fmt.Sprintf("%s=%q (%v)", r.Key, v, err))
+		}
+	}
+	return invalidEnvError(missing, invalid, details)
+}
+
+// invalidEnvError 汇总缺失与格式错误的环境变量，没有问题时返回 nil
+func invalidEnvError(missing, invalid, details []string) error {
+	if len(missing) == 0 && len(invalid) == 0 {
+		return nil
+	}
+
+	sort.Strings(missing)
+	var parts []string
+	if len(missing) > 0 {
+		parts = append(parts, "missing "+strings.Join(missing, ", "))
+	}
+	if len(details) > 0 {
+		parts = append(parts, "invalid "+strings.Join(details, ", "))
+	}
+	return errorx.New(ErrCodeInvalidEnv,
+		errorx.KV("details", strings.Join(parts, "; ")),
+		errorx.Extra("missing", strings.Join(missing, ",")),
+		errorx.Extra("invalid", strings.Join(invalid, ",")),
+	)
+}
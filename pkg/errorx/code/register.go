@@ -1,6 +1,8 @@
 package code
 
 import (
+	"sort"
+
 	"github.com/ZampoRen/go-server-comon/pkg/errorx/internal"
 )
 
@@ -12,6 +14,11 @@ func WithAffectStability(affectStability bool) RegisterOptionFn {
 	return internal.WithAffectStability(affectStability)
 }
 
+// WithRetryable 设置重试标志，true: 错误是暂时的（如限流、依赖不可用），重试可能成功，默认 false
+func WithRetryable(retryable bool) RegisterOptionFn {
+	return internal.WithRetryable(retryable)
+}
+
 // Register 注册用户预定义的错误码信息，在初始化时调用对应 PSM 服务的 code_gen 子模块
 func Register(code int32, msg string, opts ...RegisterOptionFn) {
 	internal.Register(code, msg, opts...)
@@ -21,3 +28,16 @@ func Register(code int32, msg string, opts ...RegisterOptionFn) {
 func SetDefaultErrorCode(code int32) {
 	internal.SetDefaultErrorCode(code)
 }
+
+// Definition 已注册的错误码定义
+type Definition = internal.CodeDefinition
+
+// Definitions 返回所有已注册的错误码定义，按错误码升序排列
+func Definitions() []Definition {
+	defs := make([]Definition, 0, len(internal.CodeDefinitions))
+	for _, def := range internal.CodeDefinitions {
+		defs = append(defs, *def)
+	}
+	sort.Slice(defs, func(i, j int) bool { return defs[i].Code < defs[j].Code })
+	return defs
+}
@@ -6,7 +6,7 @@
 //   - 支持错误包装和链式错误处理
 //   - 支持错误消息中的占位符替换
 //   - 支持额外信息（Extra）附加
-//   - 支持稳定性标记（IsAffectStability）
+//   - 支持稳定性标记（IsAffectStability）与重试标记（IsRetryable）
 //   - 兼容标准库 errors 包的 Unwrap、Is、As 方法
 //
 // 基本使用：
@@ -50,6 +50,9 @@
 //	// 注册错误码并设置不影响稳定性
 //	code.Register(1002, "参数验证失败", code.WithAffectStability(false))
 //
+//	// 注册可重试的错误码，retry 包据此决定是否重试
+//	code.Register(1003, "服务繁忙", code.WithRetryable(true))
+//
 //	// 设置默认错误码（用于未定义的错误码）
 //	code.SetDefaultErrorCode(9999)
 //
@@ -145,6 +148,10 @@
 //		// extra["trace_id"] = "abc-123"
 //	}
 //
+//	// 为已创建的错误追加额外信息（如日志记录时生成的事故 ID）
+//	errorx.SetExtra(err, "incident_id", "K3J9QZ2M")
+//	id, _ := errorx.SetExtraIfAbsent(err, "incident_id", "K3J9QZ2M") // 已存在时返回已有的值
+//
 // 堆栈跟踪：
 //
 // 所有通过 New、WrapByCode、Wrapf 创建的错误都会自动包含堆栈跟踪信息。
@@ -1,6 +1,7 @@
 package errorx
 
 import (
+	"errors"
 	"fmt"
 	"strings"
 
@@ -37,6 +38,23 @@ func Extra(k, v string) Option {
 	return internal.Extra(k, v)
 }
 
+// SetExtra 为已创建的错误追加额外信息，err 不是由 New 或 WrapByCode 创建时返回 false
+func SetExtra(err error, k, v string) bool {
+	if err == nil {
+		return false
+	}
+	return internal.SetExtra(err, k, v)
+}
+
+// SetExtraIfAbsent 在 k 不存在时为错误追加额外信息，返回 k 最终的值；
+// 多个 goroutine 同时设置同一个键时只有一个生效。err 不是由 New 或 WrapByCode 创建时返回 false
+func SetExtraIfAbsent(err error, k, v string) (string, bool) {
+	if err == nil {
+		return "", false
+	}
+	return internal.SetExtraIfAbsent(err, k, v)
+}
+
 // New 通过状态码获取配置文件中预定义的错误，并在调用 New 的位置生成堆栈跟踪
 func New(code int32, options ...Option) error {
 	return internal.NewByCode(code, options...)
@@ -60,6 +78,23 @@ func Wrapf(err error, format string, args ...interface{}) error {
 	return internal.Wrapf(err, format, args...)
 }
 
+// Params 返回创建 err 时通过 KV、KVf 传入的占位符参数，用于按语言重新渲染消息，
+// 错误链中没有 StatusError 时返回 nil
+func Params(err error) map[string]string {
+	var pe interface{ Params() map[string]string }
+	if errors.As(err, &pe) {
+		return pe.Params()
+	}
+	return nil
+}
+
+// IsRetryable 判断 err 是否为注册时通过 code.WithRetryable 标记为可重试的错误，
+// 错误链中没有 StatusError 时返回 false
+func IsRetryable(err error) bool {
+	var re interface{ IsRetryable() bool }
+	return errors.As(err, &re) && re.IsRetryable()
+}
+
 // ErrorWithoutStack 返回不带堆栈信息的错误消息
 func ErrorWithoutStack(err error) string {
 	if err == nil {
@@ -19,6 +19,7 @@ type CodeDefinition struct {
 	Code              int32  // 错误码
 	Message           string // 错误消息
 	IsAffectStability bool   // 是否影响稳定性
+	IsRetryable       bool   // 是否可以重试
 }
 
 // RegisterOption 注册选项函数
@@ -31,6 +32,13 @@ func WithAffectStability(affectStability bool) RegisterOption {
 	}
 }
 
+// WithRetryable 设置是否可以重试
+func WithRetryable(retryable bool) RegisterOption {
+	return func(definition *CodeDefinition) {
+		definition.IsRetryable = retryable
+	}
+}
+
 // Register 注册错误码定义
 func Register(code int32, msg string, opts ...RegisterOption) {
 	definition := &CodeDefinition{
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"sync"
 )
 
 // StatusError 状态错误接口
@@ -18,6 +19,8 @@ type statusError struct {
 	message    string
 
 	ext Extension
+	// mu 保护创建后通过 SetExtra 替换的 ext.Extra
+	mu sync.RWMutex
 }
 
 // withStatus 带状态码的错误包装
@@ -31,7 +34,9 @@ type withStatus struct {
 // Extension 扩展信息
 type Extension struct {
 	IsAffectStability bool              // 是否影响稳定性
+	IsRetryable       bool              // 是否可以重试
 	Extra             map[string]string // 额外信息
+	Params            map[string]string // 替换消息占位符的参数，用于按语言重新渲染消息
 }
 
 func (w *statusError) Code() int32 {
@@ -42,6 +47,10 @@ func (w *statusError) IsAffectStability() bool {
 	return w.ext.IsAffectStability
 }
 
+func (w *statusError) IsRetryable() bool {
+	return w.ext.IsRetryable
+}
+
 func (w *statusError) Msg() string {
 	return w.message
 }
@@ -50,10 +59,17 @@ func (w *statusError) Error() string {
 	return fmt.Sprintf("code=%d message=%s", w.statusCode, w.message)
 }
 
+// Extra 返回额外信息，返回的 map 只读，修改请使用 SetExtra
 func (w *statusError) Extra() map[string]string {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
 	return w.ext.Extra
 }
 
+func (w *statusError) Params() map[string]string {
+	return w.ext.Params
+}
+
 // Unwrap 支持 go errors.Unwrap()
 func (w *withStatus) Unwrap() error {
 	return w.cause
@@ -108,6 +124,10 @@ func Param(k, v string) Option {
 			return
 		}
 		ws.status.message = strings.Replace(ws.status.message, fmt.Sprintf("{%s}", k), v, -1)
+		if ws.status.ext.Params == nil {
+			ws.status.ext.Params = make(map[string]string)
+		}
+		ws.status.ext.Params[k] = v
 	}
 }
 
@@ -124,6 +144,38 @@ func Extra(k, v string) Option {
 	}
 }
 
+// SetExtra 为错误链中的状态错误设置额外信息，错误链中没有状态错误时返回 false
+func SetExtra(err error, k, v string) bool {
+	_, ok := setExtra(err, k, v, true)
+	return ok
+}
+
+// SetExtraIfAbsent 在 k 不存在时设置额外信息，返回 k 最终的值
+func SetExtraIfAbsent(err error, k, v string) (string, bool) {
+	return setExtra(err, k, v, false)
+}
+
+// setExtra 同一个错误可能在多个 goroutine 中共享，这里复制 map 后整体替换，不修改已通过 Extra() 返回的 map
+func setExtra(err error, k, v string, overwrite bool) (string, bool) {
+	var ws *withStatus
+	if !errors.As(err, &ws) || ws.status == nil {
+		return "", false
+	}
+	s := ws.status
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if old, ok := s.ext.Extra[k]; ok && !overwrite {
+		return old, true
+	}
+	extra := make(map[string]string, len(s.ext.Extra)+1)
+	for ek, ev := range s.ext.Extra {
+		extra[ek] = ev
+	}
+	extra[k] = v
+	s.ext.Extra = extra
+	return v, true
+}
+
 // NewByCode 通过错误码创建新错误
 func NewByCode(code int32, options ...Option) error {
 	ws := &withStatus{
@@ -175,6 +227,7 @@ func getStatusByCode(code int32) *statusError {
 			message:    codeDefinition.Message,
 			ext: Extension{
 				IsAffectStability: codeDefinition.IsAffectStability,
+				IsRetryable:       codeDefinition.IsRetryable,
 			},
 		}
 	}
@@ -0,0 +1,28 @@
+package errorx
+
+import (
+	"fmt"
+	"runtime/debug"
+)
+
+// PanicError recover 到的 panic 转换成的错误，保留 panic 的值与发生时的堆栈
+// taskgroup、singleflight、eventbus 等包在任务或处理函数 panic 时返回该错误，调用方可统一用 errors.As 识别
+type PanicError struct {
+	Value any
+	Stack []byte
+}
+
+// NewPanicError 用 recover 的返回值创建 PanicError，需在 defer 中调用，使堆栈包含 panic 的位置
+func NewPanicError(v any) *PanicError {
+	return &PanicError{Value: v, Stack: debug.Stack()}
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("panic: %v\n%s", e.Value, e.Stack)
+}
+
+// Unwrap panic 的值是 error 时返回该错误
+func (e *PanicError) Unwrap() error {
+	err, _ := e.Value.(error)
+	return err
+}
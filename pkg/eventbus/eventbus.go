@@ -0,0 +1,282 @@
+// Package eventbus 进程内的类型化事件总线，使服务内的模块通过事件解耦，而不必依赖消息队列
+//
+//	type UserUpdated struct{ ID int64 }
+//
+//	bus := eventbus.New()
+//	eventbus.Subscribe(bus, func(ctx context.Context, e UserUpdated) error {
+//		return userCache.Del(ctx, strconv.FormatInt(e.ID, 10))
+//	})
+//	eventbus.Subscribe(bus, func(ctx context.Context, e UserUpdated) error {
+//		return reindexUser(ctx, e.ID)
+//	}, eventbus.Async())
+//	lc.Append(lifecycle.Hook{Name: "eventbus", OnStop: bus.Close})
+//
+//	err := eventbus.Publish(ctx, bus, UserUpdated{ID: u.ID})
+//
+// 事件按类型 T 分发给 Subscribe[T] 的处理函数，T 与发布时的类型须完全一致（UserUpdated 与 *UserUpdated 是不同的事件）。
+// 同步处理函数在 Publish 的协程中按订阅顺序执行，Publish 返回它们的错误；异步处理函数由各自的协程按发布顺序执行，
+// 错误只打印日志。处理函数 panic 时转换为 PanicError，不影响其他处理函数与后续事件。
+// 事件只在本进程内分发，进程退出时未处理的异步事件会丢失，需要可靠投递时使用 outbox 与消息队列
+package eventbus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"runtime"
+	"sync"
+
+	"github.com/cloudwego/hertz/pkg/common/hlog"
+
+	"github.com/ZampoRen/go-server-comon/pkg/errorx"
+)
+
+// ErrClosed 事件总线已关闭
+var ErrClosed = errors.New("eventbus: closed")
+
+// PanicError 处理函数 panic 时转换成的错误，即 errorx.PanicError
+type PanicError = errorx.PanicError
+
+// Bus 事件总线，并发安全
+type Bus struct {
+	mu     sync.RWMutex
+	subs   map[reflect.Type][]*subscriber // 按订阅顺序，修改时整体替换，Publish 读取快照
+	order  []*subscriber
+	closed bool
+}
+
+// New 创建事件总线
+func New() *Bus {
+	return &Bus{subs: make(map[reflect.Type][]*subscriber)}
+}
+
+// SubscribeOption 订阅选项
+type SubscribeOption func(o *subscribeOption)
+
+type subscribeOption struct {
+	name      string
+	async     bool
+	queueSize int
+}
+
+// Async 异步处理事件：Publish 只将事件放入队列，由订阅独占的协程按发布顺序处理，
+// 处理函数的 ctx 保留发布方 ctx 中的值（如链路追踪、请求 ID），但不随其取消
+func Async() SubscribeOption {
+	return func(o *subscribeOption) {
+		o.async = true
+	}
+}
+
+// WithQueueSize 设置异步订阅的队列长度，默认 1024，队列已满时 Publish 阻塞直到有空位或 ctx 结束，
+// 因此异步处理函数不应发布自身订阅的事件类型
+func WithQueueSize(n int) SubscribeOption {
+	return func(o *subscribeOption) {
+		if n > 0 {
+			o.queueSize = n
+		}
+	}
+}
+
+// WithName 设置订阅的名称，用于日志，默认为处理函数名
+func WithName(name string) SubscribeOption {
+	return func(o *subscribeOption) {
+		o.name = name
+	}
+}
+
+// Subscribe 订阅类型为 T 的事件，返回的函数取消订阅；异步订阅取消时等待队列中的事件处理完成
+// 总线已关闭时不再订阅，返回的函数为空操作
+func Subscribe[T any](b *Bus, handler func(ctx context.Context, event T) error, opts ...SubscribeOption) (unsubscribe func()) {
+	o := &subscribeOption{
+		name:      runtime.FuncForPC(reflect.ValueOf(handler).Pointer()).Name(),
+		queueSize: 1024,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	s := &subscriber{
+		name: o.name,
+		handle: func(ctx context.Context, event any) error {
+			return handler(ctx, event.(T))
+		},
+	}
+	if o.async {
+		s.queue = make(chan envelope, o.queueSize)
+		s.done = make(chan struct{})
+		go s.run()
+	}
+
+	typ := reflect.TypeFor[T]()
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		s.close()
+		return func() {}
+	}
+	b.subs[typ] = append(b.subs[typ][:len(b.subs[typ]):len(b.subs[typ])], s)
+	b.order = append(b.order, s)
+	b.mu.Unlock()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			b.remove(typ, s)
+			s.close()
+		})
+	}
+}
+
+// Publish 将事件分发给类型 T 的所有订阅，返回同步处理函数的错误与放入异步队列失败的错误（errors.Join）。
+// 一个处理函数出错时仍会分发给其余订阅。总线关闭后返回 ErrClosed，但处理函数在关闭期间发布的事件仍会分发，
+// 使事件链（如用户更新 -> 删除缓存 -> 重建索引）在关闭时能够完成
+func Publish[T any](ctx context.Context, b *Bus, event T) error {
+	b.mu.RLock()
+	closed := b.closed
+	subs := b.subs[reflect.TypeFor[T]()]
+	b.mu.RUnlock()
+	if closed && !handling(ctx, b) {
+		return ErrClosed
+	}
+
+	var errs []error
+	for _, s := range subs {
+		if s.queue != nil {
+			errs = append(errs, s.enqueue(ctx, b, event))
+			continue
+		}
+		errs = append(errs, s.call(withHandling(ctx, b), event))
+	}
+	return errors.Join(errs...)
+}
+
+// Close 关闭事件总线：拒绝新的事件，再按订阅顺序等待各异步订阅处理完队列中的事件，
+// 先订阅的模块先结束，其处理期间发布给后续订阅的事件仍会被处理。ctx 结束时返回 ctx.Err()，
+// 剩余的事件继续在后台处理。可直接作为 lifecycle.Hook 的 OnStop
+func (b *Bus) Close(ctx context.Context) error {
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return nil
+	}
+	b.closed = true
+	order := b.order
+	b.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for _, s := range order {
+			s.close()
+		}
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (b *Bus) remove(typ reflect.Type, s *subscriber) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	subs := make([]*subscriber, 0, len(b.subs[typ]))
+	for _, sub := range b.subs[typ] {
+		if sub != s {
+			subs = append(subs, sub)
+		}
+	}
+	b.subs[typ] = subs
+	for i, sub := range b.order {
+		if sub == s {
+			b.order = append(b.order[:i:i], b.order[i+1:]...)
+			break
+		}
+	}
+}
+
+type handlingKey struct{}
+
+// withHandling 标记 ctx 处于 b 的处理函数中
+func withHandling(ctx context.Context, b *Bus) context.Context {
+	if handling(ctx, b) {
+		return ctx
+	}
+	return context.WithValue(ctx, handlingKey{}, b)
+}
+
+func handling(ctx context.Context, b *Bus) bool {
+	bus, _ := ctx.Value(handlingKey{}).(*Bus)
+	return bus == b
+}
+
+// envelope 异步队列中的事件
+type envelope struct {
+	ctx   context.Context
+	event any
+}
+
+// subscriber 订阅，queue 不为 nil 时为异步订阅
+type subscriber struct {
+	name   string
+	handle func(ctx context.Context, event any) error
+
+	queue  chan envelope
+	done   chan struct{}
+	mu     sync.RWMutex // 保护 closed 与向 queue 发送，关闭队列时持有写锁
+	closed bool
+}
+
+// call 执行处理函数，panic 时转换为 PanicError
+func (s *subscriber) call(ctx context.Context, event any) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = errorx.NewPanicError(r)
+		}
+		if err != nil {
+			err = fmt.Errorf("handle %T by %s failed: %w", event, s.name, err)
+		}
+	}()
+	return s.handle(ctx, event)
+}
+
+// enqueue 将事件放入异步队列，队列已满时阻塞直到有空位或 ctx 结束
+func (s *subscriber) enqueue(ctx context.Context, b *Bus, event any) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.closed {
+		return fmt.Errorf("publish %T to %s: %w", event, s.name, ErrClosed)
+	}
+	e := envelope{ctx: withHandling(context.WithoutCancel(ctx), b), event: event}
+	select {
+	case s.queue <- e:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("publish %T to %s: %w", event, s.name, ctx.Err())
+	}
+}
+
+func (s *subscriber) run() {
+	defer close(s.done)
+	for e := range s.queue {
+		if err := s.call(e.ctx, e.event); err != nil {
+			hlog.CtxErrorf(e.ctx, "[EventBus] %v", err)
+		}
+	}
+}
+
+// close 关闭异步队列并等待队列中的事件处理完成，同步订阅为空操作
+func (s *subscriber) close() {
+	if s.queue == nil {
+		return
+	}
+	s.mu.Lock()
+	if !s.closed {
+		s.closed = true
+		close(s.queue)
+	}
+	s.mu.Unlock()
+	<-s.done
+}
@@ -0,0 +1,159 @@
+package eventbus
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+type userUpdated struct{ ID int64 }
+
+type userIndexed struct{ ID int64 }
+
+func TestPublishSync(t *testing.T) {
+	bus := New()
+	ctx := context.Background()
+
+	var got []string
+	Subscribe(bus, func(_ context.Context, e userUpdated) error {
+		got = append(got, "cache")
+		return nil
+	})
+	Subscribe(bus, func(_ context.Context, e userUpdated) error {
+		panic("boom")
+	})
+	errFailed := errors.New("failed")
+	Subscribe(bus, func(_ context.Context, e userUpdated) error {
+		got = append(got, "search")
+		return errFailed
+	}, WithName("search"))
+	// 指针类型是不同的事件
+	Subscribe(bus, func(_ context.Context, e *userUpdated) error {
+		got = append(got, "pointer")
+		return nil
+	})
+
+	err := Publish(ctx, bus, userUpdated{ID: 1})
+	if want := []string{"cache", "search"}; !slices.Equal(got, want) {
+		t.Errorf("handled = %v, want %v", got, want)
+	}
+	var panicErr *PanicError
+	if !errors.As(err, &panicErr) || panicErr.Value != "boom" {
+		t.Errorf("err = %v, want PanicError", err)
+	}
+	if !errors.Is(err, errFailed) {
+		t.Errorf("err = %v, want %v", err, errFailed)
+	}
+
+	if err := Publish(ctx, bus, userIndexed{ID: 1}); err != nil {
+		t.Errorf("Publish() without subscribers error = %v", err)
+	}
+}
+
+func TestPublishAsync(t *testing.T) {
+	bus := New()
+	ctx := context.Background()
+
+	var mu sync.Mutex
+	var got []int64
+	Subscribe(bus, func(_ context.Context, e userUpdated) error {
+		time.Sleep(time.Millisecond)
+		mu.Lock()
+		got = append(got, e.ID)
+		mu.Unlock()
+		return nil
+	}, Async(), WithQueueSize(4))
+	Subscribe(bus, func(_ context.Context, e userUpdated) error {
+		if e.ID == 2 {
+			panic("boom")
+		}
+		return nil
+	}, Async())
+
+	for id := int64(1); id <= 10; id++ {
+		if err := Publish(ctx, bus, userUpdated{ID: id}); err != nil {
+			t.Fatalf("Publish() error = %v", err)
+		}
+	}
+	if err := bus.Close(ctx); err != nil {
+		t.Fatal(err)
+	}
+	// Close 等待队列中的事件处理完成，事件按发布顺序处理
+	if want := []int64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}; !slices.Equal(got, want) {
+		t.Errorf("handled = %v, want %v", got, want)
+	}
+	if err := Publish(ctx, bus, userUpdated{ID: 11}); !errors.Is(err, ErrClosed) {
+		t.Errorf("Publish() after Close error = %v, want ErrClosed", err)
+	}
+}
+
+func TestCloseOrder(t *testing.T) {
+	bus := New()
+	ctx := context.Background()
+
+	// 用户更新 -> 删除缓存 -> 重建索引，关闭时上游处理期间发布的事件仍被下游处理
+	release := make(chan struct{})
+	Subscribe(bus, func(ctx context.Context, e userUpdated) error {
+		<-release
+		return Publish(ctx, bus, userIndexed(e))
+	}, Async())
+	var indexed []int64
+	Subscribe(bus, func(_ context.Context, e userIndexed) error {
+		indexed = append(indexed, e.ID)
+		return nil
+	}, Async())
+
+	for id := int64(1); id <= 3; id++ {
+		if err := Publish(ctx, bus, userUpdated{ID: id}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	closed := make(chan error)
+	go func() { closed <- bus.Close(ctx) }()
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+	if err := <-closed; err != nil {
+		t.Fatal(err)
+	}
+	if want := []int64{1, 2, 3}; !slices.Equal(indexed, want) {
+		t.Errorf("indexed = %v, want %v", indexed, want)
+	}
+}
+
+func TestCloseTimeout(t *testing.T) {
+	bus := New()
+	release := make(chan struct{})
+	defer close(release)
+	Subscribe(bus, func(context.Context, userUpdated) error {
+		<-release
+		return nil
+	}, Async())
+	_ = Publish(context.Background(), bus, userUpdated{ID: 1})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := bus.Close(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Close() error = %v, want DeadlineExceeded", err)
+	}
+}
+
+func TestUnsubscribe(t *testing.T) {
+	bus := New()
+	ctx := context.Background()
+
+	var n int
+	unsubscribe := Subscribe(bus, func(context.Context, userUpdated) error {
+		n++
+		return nil
+	})
+	_ = Publish(ctx, bus, userUpdated{ID: 1})
+	unsubscribe()
+	unsubscribe()
+	_ = Publish(ctx, bus, userUpdated{ID: 2})
+	if n != 1 {
+		t.Errorf("handled %d events, want 1", n)
+	}
+}
@@ -0,0 +1,112 @@
+package health
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+)
+
+// GRPCOption gRPC 健康服务选项
+type GRPCOption func(s *grpcServer)
+
+// WithWatchInterval 设置 Watch 重新检查的间隔，默认 5s
+func WithWatchInterval(d time.Duration) GRPCOption {
+	return func(s *grpcServer) {
+		if d > 0 {
+			s.interval = d
+		}
+	}
+}
+
+// RegisterGRPC 在 gRPC 服务上注册标准健康服务 grpc.health.v1.Health，r 为 nil 时使用默认注册表
+// 服务名为空时返回就绪检查的整体状态，否则返回同名检查的状态，检查不存在时 Check 返回 NotFound。
+// 使用认证拦截器时应通过 middleware.WithPublicMethods("/grpc.health.v1.Health/*") 放行
+func RegisterGRPC(s grpc.ServiceRegistrar, r *Registry, opts ...GRPCOption) {
+	if r == nil {
+		r = defaultRegistry
+	}
+	srv := &grpcServer{r: r, interval: 5 * time.Second}
+	for _, opt := range opts {
+		opt(srv)
+	}
+	healthpb.RegisterHealthServer(s, srv)
+}
+
+// grpcServer 基于 Registry 的 gRPC 健康服务
+type grpcServer struct {
+	healthpb.UnimplementedHealthServer
+	r        *Registry
+	interval time.Duration
+}
+
+func (s *grpcServer) Check(ctx context.Context, req *healthpb.HealthCheckRequest) (*healthpb.HealthCheckResponse, error) {
+	st, ok := s.status(ctx, req.GetService())
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "unknown service %q", req.GetService())
+	}
+	return &healthpb.HealthCheckResponse{Status: st}, nil
+}
+
+func (s *grpcServer) List(ctx context.Context, _ *healthpb.HealthListRequest) (*healthpb.HealthListResponse, error) {
+	report := s.r.Ready(ctx)
+	statuses := map[string]*healthpb.HealthCheckResponse{
+		"": {Status: servingStatus(report.Status)},
+	}
+	for name, res := range report.Checks {
+		statuses[name] = &healthpb.HealthCheckResponse{Status: servingStatus(res.Status)}
+	}
+	return &healthpb.HealthListResponse{Statuses: statuses}, nil
+}
+
+// Watch 立即返回当前状态，之后按间隔重新检查，状态变化时推送；检查不存在时推送 SERVICE_UNKNOWN
+func (s *grpcServer) Watch(req *healthpb.HealthCheckRequest, stream grpc.ServerStreamingServer[healthpb.HealthCheckResponse]) error {
+	ctx := stream.Context()
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	last := healthpb.HealthCheckResponse_ServingStatus(-1)
+	for {
+		st, ok := s.status(ctx, req.GetService())
+		if !ok {
+			st = healthpb.HealthCheckResponse_SERVICE_UNKNOWN
+		}
+		if st != last {
+			if err := stream.Send(&healthpb.HealthCheckResponse{Status: st}); err != nil {
+				return err
+			}
+			last = st
+		}
+
+		select {
+		case <-ctx.Done():
+			return status.FromContextError(ctx.Err()).Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// status 服务名为空时返回就绪检查的整体状态，否则返回同名检查的状态
+func (s *grpcServer) status(ctx context.Context, service string) (healthpb.HealthCheckResponse_ServingStatus, bool) {
+	if service == "" {
+		return servingStatus(s.r.Ready(ctx).Status), true
+	}
+	if s.r.shuttingDown.Load() {
+		return healthpb.HealthCheckResponse_NOT_SERVING, true
+	}
+	res, ok := s.r.Check(ctx, service)
+	if !ok {
+		return healthpb.HealthCheckResponse_SERVICE_UNKNOWN, false
+	}
+	return servingStatus(res.Status), true
+}
+
+func servingStatus(st Status) healthpb.HealthCheckResponse_ServingStatus {
+	if st == StatusUp {
+		return healthpb.HealthCheckResponse_SERVING
+	}
+	return healthpb.HealthCheckResponse_NOT_SERVING
+}
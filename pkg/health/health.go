@@ -0,0 +1,251 @@
+// Package health 提供统一的存活与就绪检查
+//
+// 各组件向 Registry 注册检查函数，由 HTTP 探针（healthhttp）与标准 gRPC 健康服务（RegisterGRPC）共享：
+//
+//	health.Register("redis", health.CheckerFunc(cache.NewHealthChecker(client).Ready))
+//	health.Register("mysql", health.CheckerFunc(supervisor.Ready))
+//	health.Register("es", health.CheckerFunc(func(ctx context.Context) error {
+//		return es.Ready(ctx, client, "users")
+//	}), health.WithOptional())
+//
+// 存活检查（/healthz）只执行通过 WithLiveness 注册的检查，失败意味着进程需要重启；
+// 就绪检查（/readyz）执行全部检查，失败时负载均衡应暂停向该实例转发流量
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ZampoRen/go-server-comon/pkg/errorx"
+)
+
+// ErrShuttingDown 服务正在关闭，就绪检查失败
+var ErrShuttingDown = errors.New("shutting down")
+
+// Status 检查状态
+type Status string
+
+const (
+	// StatusUp 正常
+	StatusUp Status = "up"
+	// StatusDown 异常
+	StatusDown Status = "down"
+)
+
+// Checker 健康检查，返回 nil 表示正常
+type Checker interface {
+	Check(ctx context.Context) error
+}
+
+// CheckerFunc 函数形式的 Checker
+type CheckerFunc func(ctx context.Context) error
+
+// Check 实现 Checker
+func (f CheckerFunc) Check(ctx context.Context) error {
+	return f(ctx)
+}
+
+// CheckOption 检查选项
+type CheckOption func(o *check)
+
+// WithLiveness 同时作为存活检查，只应用于进程自身的状态（如死锁检测），不应依赖外部组件
+func WithLiveness() CheckOption {
+	return func(o *check) {
+		o.liveness = true
+	}
+}
+
+// WithOptional 检查失败时只在结果中体现，不影响整体状态，用于可降级的依赖
+func WithOptional() CheckOption {
+	return func(o *check) {
+		o.optional = true
+	}
+}
+
+// WithCheckTimeout 设置检查的超时时间，默认 2s
+func WithCheckTimeout(d time.Duration) CheckOption {
+	return func(o *check) {
+		if d > 0 {
+			o.timeout = d
+		}
+	}
+}
+
+type check struct {
+	checker  Checker
+	liveness bool
+	optional bool
+	timeout  time.Duration
+}
+
+// Result 单项检查结果
+type Result struct {
+	Status   Status `json:"status"`
+	Error    string `json:"error,omitempty"`
+	Latency  string `json:"latency"`
+	Optional bool   `json:"optional,omitempty"`
+}
+
+// Report 检查报告
+type Report struct {
+	Status Status             `json:"status"`
+	Checks map[string]*Result `json:"checks,omitempty"`
+	Error  string             `json:"error,omitempty"`
+}
+
+// Healthy 判断整体状态是否正常
+func (r *Report) Healthy() bool {
+	return r.Status == StatusUp
+}
+
+// Registry 检查注册表，并发安全
+type Registry struct {
+	mu           sync.RWMutex
+	checks       map[string]*check
+	shuttingDown atomic.Bool
+}
+
+// NewRegistry 创建检查注册表
+func NewRegistry() *Registry {
+	return &Registry{checks: make(map[string]*check)}
+}
+
+var defaultRegistry = NewRegistry()
+
+// Default 返回默认注册表
+func Default() *Registry {
+	return defaultRegistry
+}
+
+// Register 向默认注册表注册检查
+func Register(name string, c Checker, opts ...CheckOption) {
+	defaultRegistry.Register(name, c, opts...)
+}
+
+// Register 注册检查，同名检查后者覆盖前者
+func (r *Registry) Register(name string, c Checker, opts ...CheckOption) {
+	ch := &check{checker: c, timeout: 2 * time.Second}
+	for _, opt := range opts {
+		opt(ch)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.checks[name] = ch
+}
+
+// Unregister 取消注册检查
+func (r *Registry) Unregister(name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.checks, name)
+}
+
+// Names 返回已注册的检查名称，按名称排序
+func (r *Registry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	names := make([]string, 0, len(r.checks))
+	for name := range r.checks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Shutdown 将服务标记为正在关闭，之后的就绪检查直接失败，使负载均衡在停止服务前摘除该实例
+func (r *Registry) Shutdown() {
+	r.shuttingDown.Store(true)
+}
+
+// Live 执行存活检查
+func (r *Registry) Live(ctx context.Context) *Report {
+	return r.run(ctx, func(c *check) bool { return c.liveness })
+}
+
+// Ready 执行全部检查，服务正在关闭时直接返回失败
+func (r *Registry) Ready(ctx context.Context) *Report {
+	if r.shuttingDown.Load() {
+		return &Report{Status: StatusDown, Error: ErrShuttingDown.Error()}
+	}
+	return r.run(ctx, func(*check) bool { return true })
+}
+
+// Check 执行单项检查，检查不存在时返回 false
+func (r *Registry) Check(ctx context.Context, name string) (*Result, bool) {
+	r.mu.RLock()
+	c, ok := r.checks[name]
+	r.mu.RUnlock()
+	if !ok {
+		return nil, false
+	}
+	return c.run(ctx), true
+}
+
+// run 并发执行满足 filter 的检查，任一非可选检查失败时整体状态为 StatusDown
+func (r *Registry) run(ctx context.Context, filter func(c *check) bool) *Report {
+	r.mu.RLock()
+	checks := make(map[string]*check, len(r.checks))
+	for name, c := range r.checks {
+		if filter(c) {
+			checks[name] = c
+		}
+	}
+	r.mu.RUnlock()
+
+	report := &Report{Status: StatusUp, Checks: make(map[string]*Result, len(checks))}
+	var (
+		mu sync.Mutex
+		wg sync.WaitGroup
+	)
+	for name, c := range checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			res := c.run(ctx)
+			mu.Lock()
+			defer mu.Unlock()
+			report.Checks[name] = res
+			if res.Status == StatusDown && !c.optional {
+				report.Status = StatusDown
+			}
+		}()
+	}
+	wg.Wait()
+	return report
+}
+
+// run 在超时时间内执行检查，超时或 panic 视为失败
+func (c *check) run(ctx context.Context) *Result {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	start := time.Now()
+	done := make(chan error, 1)
+	go func() {
+		defer func() {
+			if p := recover(); p != nil {
+				done <- fmt.Errorf("panic in health check: %v", p)
+			}
+		}()
+		done <- c.checker.Check(ctx)
+	}()
+
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	res := &Result{Status: StatusUp, Latency: time.Since(start).String(), Optional: c.optional}
+	if err != nil {
+		res.Status, res.Error = StatusDown, errorx.ErrorWithoutStack(err)
+	}
+	return res
+}
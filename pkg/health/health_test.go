@@ -0,0 +1,84 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+func TestRegistry(t *testing.T) {
+	ctx := context.Background()
+	ok := CheckerFunc(func(context.Context) error { return nil })
+	fail := CheckerFunc(func(context.Context) error { return errors.New("down") })
+
+	t.Run("必选检查失败时未就绪", func(t *testing.T) {
+		r := NewRegistry()
+		r.Register("live", ok, WithLiveness())
+		r.Register("mysql", fail)
+		if report := r.Ready(ctx); report.Healthy() || report.Checks["mysql"].Error != "down" {
+			t.Errorf("ready = %+v", report)
+		}
+		if report := r.Live(ctx); !report.Healthy() || len(report.Checks) != 1 {
+			t.Errorf("live = %+v", report)
+		}
+	})
+
+	t.Run("可选检查失败不影响整体状态", func(t *testing.T) {
+		r := NewRegistry()
+		r.Register("es", fail, WithOptional())
+		if report := r.Ready(ctx); !report.Healthy() || report.Checks["es"].Status != StatusDown {
+			t.Errorf("ready = %+v", report)
+		}
+	})
+
+	t.Run("超时与 panic 视为失败", func(t *testing.T) {
+		r := NewRegistry()
+		r.Register("slow", CheckerFunc(func(context.Context) error {
+			time.Sleep(time.Second)
+			return nil
+		}), WithCheckTimeout(10*time.Millisecond))
+		r.Register("panic", CheckerFunc(func(context.Context) error { panic("boom") }))
+		report := r.Ready(ctx)
+		if report.Checks["slow"].Status != StatusDown || report.Checks["panic"].Status != StatusDown {
+			t.Errorf("ready = %+v", report)
+		}
+	})
+
+	t.Run("关闭后未就绪", func(t *testing.T) {
+		r := NewRegistry()
+		r.Register("redis", ok)
+		r.Shutdown()
+		if report := r.Ready(ctx); report.Healthy() {
+			t.Errorf("ready = %+v", report)
+		}
+	})
+}
+
+func TestGRPCServer(t *testing.T) {
+	ctx := context.Background()
+	r := NewRegistry()
+	r.Register("redis", CheckerFunc(func(context.Context) error { return nil }))
+	r.Register("mysql", CheckerFunc(func(context.Context) error { return errors.New("down") }))
+	s := &grpcServer{r: r, interval: time.Second}
+
+	tests := []struct {
+		service string
+		want    healthpb.HealthCheckResponse_ServingStatus
+	}{
+		{"", healthpb.HealthCheckResponse_NOT_SERVING},
+		{"redis", healthpb.HealthCheckResponse_SERVING},
+		{"mysql", healthpb.HealthCheckResponse_NOT_SERVING},
+	}
+	for _, tt := range tests {
+		resp, err := s.Check(ctx, &healthpb.HealthCheckRequest{Service: tt.service})
+		if err != nil || resp.GetStatus() != tt.want {
+			t.Errorf("Check(%q) = %v, %v, want %v", tt.service, resp.GetStatus(), err, tt.want)
+		}
+	}
+	if _, err := s.Check(ctx, &healthpb.HealthCheckRequest{Service: "unknown"}); err == nil {
+		t.Error("unknown service should return NotFound")
+	}
+}
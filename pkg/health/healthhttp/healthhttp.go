@@ -0,0 +1,62 @@
+// Package healthhttp 提供 Hertz 的存活与就绪探针接口
+//
+//	healthhttp.Register(h, health.Default())
+//
+// GET /healthz 与 GET /readyz 正常时返回 200，否则返回 503，响应体为 health.Report，
+// 带有 ?verbose=false 时只返回整体状态，避免向外暴露各依赖的错误信息
+package healthhttp
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/route"
+
+	"github.com/ZampoRen/go-server-comon/pkg/health"
+)
+
+// 探针路径
+const (
+	LivenessPath  = "/healthz"
+	ReadinessPath = "/readyz"
+)
+
+// Register 注册 /healthz 与 /readyz，r 为 nil 时使用默认注册表
+// 探针接口不应挂载认证、限流等中间件，需要跳过日志与链路追踪时可使用 httpmw.WithTracingSkip
+func Register(router route.IRoutes, r *health.Registry) {
+	router.GET(LivenessPath, Liveness(r))
+	router.GET(ReadinessPath, Readiness(r))
+}
+
+// Liveness 返回存活检查接口
+func Liveness(r *health.Registry) app.HandlerFunc {
+	if r == nil {
+		r = health.Default()
+	}
+	return handler(r.Live)
+}
+
+// Readiness 返回就绪检查接口
+func Readiness(r *health.Registry) app.HandlerFunc {
+	if r == nil {
+		r = health.Default()
+	}
+	return handler(r.Ready)
+}
+
+func handler(run func(ctx context.Context) *health.Report) app.HandlerFunc {
+	return func(ctx context.Context, c *app.RequestContext) {
+		report := run(ctx)
+		if c.Query("verbose") == "false" {
+			report = &health.Report{Status: report.Status, Error: report.Error}
+		}
+
+		code := http.StatusOK
+		if !report.Healthy() {
+			code = http.StatusServiceUnavailable
+		}
+		c.Header("Cache-Control", "no-store")
+		c.JSON(code, report)
+	}
+}
@@ -0,0 +1,227 @@
+// Package httpclient 服务对外的 HTTP 客户端，预置连接池、单次请求超时、幂等请求重试、按主机熔断，
+// 以及链路追踪、日志与指标中间件
+//
+//	client := httpclient.New(
+//		httpclient.WithBaseURL("http://user-svc:8888"),
+//		httpclient.WithBreaker(),
+//	)
+//	user, err := httpclient.GetJSON[User](ctx, client, "/api/user/1")
+//
+// 每次尝试依次经过链路追踪、日志、指标、WithMiddleware 追加的中间件与熔断器。
+// 只重试幂等方法（GET、HEAD、OPTIONS、PUT、DELETE、TRACE）与带 Idempotency-Key 请求头的请求，
+// 网络错误与 429、502、503、504 响应可以重试，熔断时不重试。
+// HTTPClient 返回具备同样能力的 *http.Client，可交给只接受 *http.Client 的第三方库
+package httpclient
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/ZampoRen/go-server-comon/pkg/breaker"
+	"github.com/ZampoRen/go-server-comon/pkg/retry"
+)
+
+// 默认参数
+const (
+	// DefaultTimeout 单次请求（含读取响应体）的超时时间
+	DefaultTimeout = 10 * time.Second
+	// DefaultMaxIdleConnsPerHost 每个主机保留的空闲连接数
+	DefaultMaxIdleConnsPerHost = 32
+)
+
+// Option 客户端选项
+type Option func(o *option)
+
+type option struct {
+	baseURL             string
+	header              http.Header
+	timeout             time.Duration
+	transport           http.RoundTripper
+	maxIdleConnsPerHost int
+	maxConnsPerHost     int
+	retryOptions        []retry.Option
+	breaker             *breaker.Group
+	registerer          prometheus.Registerer
+	middlewares         []Middleware
+	skipMiddlewares     bool
+}
+
+// WithBaseURL 设置基础地址，NewRequest 与 JSON 方法中的相对路径基于该地址
+func WithBaseURL(baseURL string) Option {
+	return func(o *option) {
+		o.baseURL = strings.TrimRight(baseURL, "/")
+	}
+}
+
+// WithHeader 设置 NewRequest 创建的请求默认携带的请求头
+func WithHeader(key, value string) Option {
+	return func(o *option) {
+		o.header.Set(key, value)
+	}
+}
+
+// WithTimeout 设置单次请求（每次重试单独计时）的超时时间，默认 DefaultTimeout，为 0 时不限制
+// 请求的 ctx 已设置 deadline 时沿用 ctx 的 deadline，长轮询等请求通过 ctx 设置更长的超时
+func WithTimeout(d time.Duration) Option {
+	return func(o *option) {
+		o.timeout = d
+	}
+}
+
+// WithTransport 设置底层 Transport，默认为按 WithMaxIdleConnsPerHost、WithMaxConnsPerHost 配置连接池的 http.Transport
+func WithTransport(rt http.RoundTripper) Option {
+	return func(o *option) {
+		o.transport = rt
+	}
+}
+
+// WithMaxIdleConnsPerHost 设置默认 Transport 每个主机保留的空闲连接数，默认 DefaultMaxIdleConnsPerHost
+func WithMaxIdleConnsPerHost(n int) Option {
+	return func(o *option) {
+		o.maxIdleConnsPerHost = n
+	}
+}
+
+// WithMaxConnsPerHost 设置默认 Transport 每个主机的最大连接数，默认不限制
+func WithMaxConnsPerHost(n int) Option {
+	return func(o *option) {
+		o.maxConnsPerHost = n
+	}
+}
+
+// WithRetry 设置重试选项，默认最多请求 3 次，见 retry.Do；retry.WithMaxAttempts(1) 关闭重试
+func WithRetry(opts ...retry.Option) Option {
+	return func(o *option) {
+		o.retryOptions = append(o.retryOptions, opts...)
+	}
+}
+
+// WithBreaker 按请求主机熔断，见 breaker.Transport
+func WithBreaker(opts ...breaker.Option) Option {
+	return func(o *option) {
+		o.breaker = breaker.NewGroup(opts...)
+	}
+}
+
+// WithMetricsRegisterer 设置指标注册器，默认 prometheus.DefaultRegisterer
+func WithMetricsRegisterer(reg prometheus.Registerer) Option {
+	return func(o *option) {
+		if reg != nil {
+			o.registerer = reg
+		}
+	}
+}
+
+// WithMiddleware 在标准中间件之后追加中间件
+func WithMiddleware(mws ...Middleware) Option {
+	return func(o *option) {
+		o.middlewares = append(o.middlewares, mws...)
+	}
+}
+
+// WithoutStandardMiddlewares 不挂载链路追踪、日志与指标中间件，只使用 WithMiddleware 设置的中间件
+func WithoutStandardMiddlewares() Option {
+	return func(o *option) {
+		o.skipMiddlewares = true
+	}
+}
+
+// Client HTTP 客户端，可并发使用，应在初始化时创建并复用以复用连接
+type Client struct {
+	client  *http.Client
+	baseURL string
+	header  http.Header
+}
+
+// New 创建客户端
+func New(opts ...Option) *Client {
+	o := &option{
+		header:              make(http.Header),
+		timeout:             DefaultTimeout,
+		maxIdleConnsPerHost: DefaultMaxIdleConnsPerHost,
+		registerer:          prometheus.DefaultRegisterer,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	rt := o.transport
+	if rt == nil {
+		rt = newTransport(o.maxIdleConnsPerHost, o.maxConnsPerHost)
+	}
+	if o.breaker != nil {
+		rt = breaker.Transport(rt, o.breaker)
+	}
+	var mws []Middleware
+	if !o.skipMiddlewares {
+		mws = append(mws, Tracing(), Logging(), Metrics(o.registerer))
+	}
+	mws = append(mws, o.middlewares...)
+	for i := len(mws) - 1; i >= 0; i-- {
+		rt = mws[i](rt)
+	}
+
+	return &Client{
+		client: &http.Client{
+			Transport: &retryTransport{next: rt, timeout: o.timeout, retryOptions: o.retryOptions},
+		},
+		baseURL: o.baseURL,
+		header:  o.header,
+	}
+}
+
+func newTransport(maxIdleConnsPerHost, maxConnsPerHost int) *http.Transport {
+	return &http.Transport{
+		Proxy: http.ProxyFromEnvironment,
+		DialContext: (&net.Dialer{
+			Timeout:   5 * time.Second,
+			KeepAlive: 30 * time.Second,
+		}).DialContext,
+		ForceAttemptHTTP2:     true,
+		MaxIdleConns:          256,
+		MaxIdleConnsPerHost:   maxIdleConnsPerHost,
+		MaxConnsPerHost:       maxConnsPerHost,
+		IdleConnTimeout:       90 * time.Second,
+		TLSHandshakeTimeout:   5 * time.Second,
+		ExpectContinueTimeout: time.Second,
+	}
+}
+
+// HTTPClient 返回底层的 *http.Client，具备重试、熔断与中间件，但不会使用基础地址与默认请求头
+func (c *Client) HTTPClient() *http.Client {
+	return c.client
+}
+
+// NewRequest 创建请求，path 为相对路径时拼接在基础地址之后，并带上 WithHeader 设置的请求头
+func (c *Client) NewRequest(ctx context.Context, method, path string, body io.Reader) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, c.resolve(path), body)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range c.header {
+		req.Header[k] = append([]string(nil), v...)
+	}
+	return req, nil
+}
+
+// Do 发送请求，与 http.Client.Do 相同，非 2xx 响应不返回错误，调用方需要关闭响应体
+func (c *Client) Do(req *http.Request) (*http.Response, error) {
+	return c.client.Do(req)
+}
+
+func (c *Client) resolve(path string) string {
+	if c.baseURL == "" {
+		return path
+	}
+	if u, err := url.Parse(path); err == nil && u.IsAbs() {
+		return path
+	}
+	return c.baseURL + "/" + strings.TrimLeft(path, "/")
+}
@@ -0,0 +1,175 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/ZampoRen/go-server-comon/pkg/breaker"
+	"github.com/ZampoRen/go-server-comon/pkg/retry"
+)
+
+type user struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+}
+
+func newTestClient(srv *httptest.Server, opts ...Option) *Client {
+	return New(append([]Option{
+		WithBaseURL(srv.URL),
+		WithMetricsRegisterer(prometheus.NewRegistry()),
+		WithRetry(retry.WithBackoff(time.Millisecond, time.Millisecond)),
+	}, opts...)...)
+}
+
+func TestJSON(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Token") != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path {
+		case "/users/1":
+			_, _ = io.WriteString(w, `{"id":1,"name":"tom"}`)
+		case "/users":
+			body, _ := io.ReadAll(r.Body)
+			if r.Method != http.MethodPost || r.Header.Get("Content-Type") != "application/json" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			_, _ = w.Write(body)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = io.WriteString(w, "no such user")
+		}
+	}))
+	defer srv.Close()
+	c := newTestClient(srv, WithHeader("X-Token", "secret"))
+	ctx := context.Background()
+
+	got, err := GetJSON[user](ctx, c, "/users/1")
+	if err != nil || got != (user{ID: 1, Name: "tom"}) {
+		t.Fatalf("GetJSON = %+v, %v", got, err)
+	}
+	got, err = PostJSON[user](ctx, c, "users", user{ID: 2, Name: "jerry"})
+	if err != nil || got != (user{ID: 2, Name: "jerry"}) {
+		t.Fatalf("PostJSON = %+v, %v", got, err)
+	}
+
+	_, err = GetJSON[user](ctx, c, "/users/3")
+	var re *ResponseError
+	if !errors.As(err, &re) || re.StatusCode != http.StatusNotFound || string(re.Body) != "no such user" {
+		t.Fatalf("err = %v, want 404 ResponseError", err)
+	}
+}
+
+func TestRetry(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if calls.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write(body)
+	}))
+	defer srv.Close()
+	c := newTestClient(srv)
+	ctx := context.Background()
+
+	t.Run("幂等请求重试到成功", func(t *testing.T) {
+		calls.Store(0)
+		got, err := PutJSON[user](ctx, c, "/users/1", user{ID: 1})
+		if err != nil || got.ID != 1 || calls.Load() != 3 {
+			t.Fatalf("PutJSON = %+v, %v, calls = %d", got, err, calls.Load())
+		}
+	})
+
+	t.Run("POST 不重试", func(t *testing.T) {
+		calls.Store(0)
+		_, err := PostJSON[user](ctx, c, "/users", user{ID: 1})
+		var re *ResponseError
+		if !errors.As(err, &re) || re.StatusCode != http.StatusServiceUnavailable || calls.Load() != 1 {
+			t.Fatalf("err = %v, calls = %d", err, calls.Load())
+		}
+	})
+
+	t.Run("带 Idempotency-Key 的 POST 重试", func(t *testing.T) {
+		calls.Store(0)
+		req, _ := c.NewRequest(ctx, http.MethodPost, "/users", strings.NewReader(`{"id":2}`))
+		req.Header.Set(IdempotencyKeyHeader, "k1")
+		resp, err := c.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		if resp.StatusCode != http.StatusOK || string(body) != `{"id":2}` || calls.Load() != 3 {
+			t.Fatalf("status = %d, body = %s, calls = %d", resp.StatusCode, body, calls.Load())
+		}
+	})
+
+	t.Run("重试用尽返回最后的响应", func(t *testing.T) {
+		calls.Store(-10)
+		resp, err := c.HTTPClient().Get(srv.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusServiceUnavailable || calls.Load() != -7 {
+			t.Fatalf("status = %d, calls = %d", resp.StatusCode, calls.Load())
+		}
+	})
+}
+
+func TestTimeout(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			select {
+			case <-r.Context().Done():
+			case <-time.After(time.Second):
+			}
+			return
+		}
+		_, _ = io.WriteString(w, `{"id":1}`)
+	}))
+	defer srv.Close()
+	c := newTestClient(srv, WithTimeout(50*time.Millisecond))
+
+	got, err := GetJSON[user](context.Background(), c, "/users/1")
+	if err != nil || got.ID != 1 || calls.Load() != 2 {
+		t.Fatalf("GetJSON = %+v, %v, calls = %d", got, err, calls.Load())
+	}
+}
+
+func TestBreaker(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+	c := newTestClient(srv, WithBreaker(breaker.WithMinRequests(2)))
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		if _, err := GetJSON[user](ctx, c, "/"); err == nil {
+			t.Fatal("want error")
+		}
+	}
+	if _, err := GetJSON[user](ctx, c, "/"); !errors.Is(err, breaker.ErrOpen) {
+		t.Fatalf("err = %v, want ErrOpen", err)
+	}
+	if calls.Load() != 2 {
+		t.Fatalf("calls = %d, want 2", calls.Load())
+	}
+}
@@ -0,0 +1,93 @@
+package httpclient
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/ZampoRen/go-server-comon/pkg/sonic"
+)
+
+// maxErrorBody ResponseError 保留的响应体长度
+const maxErrorBody = 4 << 10
+
+// ResponseError JSON 方法收到非 2xx 响应时返回的错误
+type ResponseError struct {
+	Method     string
+	URL        string
+	StatusCode int
+	// Body 响应体，最多保留前 4KB
+	Body []byte
+}
+
+func (e *ResponseError) Error() string {
+	return fmt.Sprintf("httpclient: %s %s: status %d: %s", e.Method, e.URL, e.StatusCode, e.Body)
+}
+
+// DoJSON 将 in 编码为 JSON 请求体发送请求，2xx 响应解码到 out。in 为 nil 时不发送请求体，
+// out 为 nil 或响应为 204 时不解码；非 2xx 响应返回 *ResponseError
+func (c *Client) DoJSON(ctx context.Context, method, path string, in, out any) error {
+	var body io.Reader
+	if in != nil {
+		data, err := sonic.Marshal(in)
+		if err != nil {
+			return fmt.Errorf("httpclient: marshal request failed: %w", err)
+		}
+		body = bytes.NewReader(data)
+	}
+	req, err := c.NewRequest(ctx, method, path, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+		return &ResponseError{Method: method, URL: redactedURL(req), StatusCode: resp.StatusCode, Body: data}
+	}
+	if out == nil || resp.StatusCode == http.StatusNoContent {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return nil
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("httpclient: read response failed: %w", err)
+	}
+	if err := sonic.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("httpclient: unmarshal response failed: %w", err)
+	}
+	return nil
+}
+
+// GetJSON 发送 GET 请求并将响应解码为 T
+func GetJSON[T any](ctx context.Context, c *Client, path string) (T, error) {
+	var out T
+	err := c.DoJSON(ctx, http.MethodGet, path, nil, &out)
+	return out, err
+}
+
+// PostJSON 发送 JSON 请求体的 POST 请求并将响应解码为 T。POST 请求不会重试，
+// 需要重试时使用 NewRequest 创建请求并设置 Idempotency-Key 请求头
+func PostJSON[T any](ctx context.Context, c *Client, path string, in any) (T, error) {
+	var out T
+	err := c.DoJSON(ctx, http.MethodPost, path, in, &out)
+	return out, err
+}
+
+// PutJSON 发送 JSON 请求体的 PUT 请求并将响应解码为 T
+func PutJSON[T any](ctx context.Context, c *Client, path string, in any) (T, error) {
+	var out T
+	err := c.DoJSON(ctx, http.MethodPut, path, in, &out)
+	return out, err
+}
@@ -0,0 +1,160 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/common/hlog"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	otelcodes "go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+
+	logger "github.com/ZampoRen/go-server-comon/pkg/logs"
+)
+
+const tracerName = "github.com/ZampoRen/go-server-comon/pkg/httpclient"
+
+// RequestIDHeader 向下游传递请求 ID 的请求头，与 httpmw.RequestIDHeader 相同
+const RequestIDHeader = "X-Request-ID"
+
+// Middleware 包装每次尝试的 http.RoundTripper
+type Middleware func(next http.RoundTripper) http.RoundTripper
+
+// RoundTripperFunc 将函数适配为 http.RoundTripper
+type RoundTripperFunc func(req *http.Request) (*http.Response, error)
+
+// RoundTrip 实现 http.RoundTripper
+func (f RoundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// Tracing 为每次尝试创建客户端 span，并通过全局 TextMapPropagator 将 trace 上下文写入请求头，
+// 网络错误与 5xx 响应标记为错误
+func Tracing() Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			ctx, span := otel.GetTracerProvider().Tracer(tracerName).Start(req.Context(), req.Method,
+				trace.WithSpanKind(trace.SpanKindClient),
+				trace.WithAttributes(
+					attribute.String("http.request.method", req.Method),
+					attribute.String("server.address", req.URL.Host),
+					attribute.String("url.full", redactedURL(req)),
+				),
+			)
+			defer span.End()
+
+			req = req.Clone(ctx)
+			otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
+			resp, err := next.RoundTrip(req)
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(otelcodes.Error, err.Error())
+				return resp, err
+			}
+			span.SetAttributes(attribute.Int("http.response.status_code", resp.StatusCode))
+			if resp.StatusCode >= http.StatusInternalServerError {
+				span.SetStatus(otelcodes.Error, resp.Status)
+			}
+			return resp, nil
+		})
+	}
+}
+
+// Logging 记录每次尝试的方法、地址（不含查询参数）、状态码与耗时，并将 ctx 中的请求 ID 写入 X-Request-ID 请求头。
+// 成功的请求记录 Debug，网络错误与 5xx 响应记录 Warn
+func Logging() Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			ctx := req.Context()
+			requestID := logger.RequestIDFromContext(ctx)
+			if requestID != "" && req.Header.Get(RequestIDHeader) == "" {
+				req = req.Clone(ctx)
+				req.Header.Set(RequestIDHeader, requestID)
+			}
+
+			start := time.Now()
+			resp, err := next.RoundTrip(req)
+
+			const format = "[HTTP] call method=%s url=%s status=%d latency=%v request_id=%s"
+			var statusCode int
+			if resp != nil {
+				statusCode = resp.StatusCode
+			}
+			args := []interface{}{req.Method, redactedURL(req), statusCode, time.Since(start), requestID}
+			switch {
+			case err != nil:
+				hlog.CtxWarnf(ctx, format+" err=%v", append(args, err)...)
+			case statusCode >= http.StatusInternalServerError:
+				hlog.CtxWarnf(ctx, format, args...)
+			default:
+				hlog.CtxDebugf(ctx, format, args...)
+			}
+			return resp, err
+		})
+	}
+}
+
+// Metrics 记录每次尝试的次数与耗时，reg 为 nil 时使用 prometheus.DefaultRegisterer
+// 导出的指标：
+//   - http_client_requests_total{method, host, code}: 按状态码统计的请求次数，网络错误的 code 为 error
+//   - http_client_request_duration_seconds{method, host}: 请求耗时（到收到响应头为止）
+func Metrics(reg prometheus.Registerer) Middleware {
+	if reg == nil {
+		reg = prometheus.DefaultRegisterer
+	}
+	requests := registerCollector(reg, prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "http",
+		Subsystem: "client",
+		Name:      "requests_total",
+		Help:      "Total number of HTTP requests sent by the client.",
+	}, []string{"method", "host", "code"}))
+	duration := registerCollector(reg, prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "http",
+		Subsystem: "client",
+		Name:      "request_duration_seconds",
+		Help:      "Duration of HTTP requests sent by the client in seconds.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "host"}))
+
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			start := time.Now()
+			resp, err := next.RoundTrip(req)
+
+			code := "error"
+			if err == nil {
+				code = strconv.Itoa(resp.StatusCode)
+			}
+			duration.WithLabelValues(req.Method, req.URL.Host).Observe(time.Since(start).Seconds())
+			requests.WithLabelValues(req.Method, req.URL.Host, code).Inc()
+			return resp, err
+		})
+	}
+}
+
+// registerCollector 注册指标，已注册过相同指标时返回已注册的实例，多个客户端共用同一组指标
+func registerCollector[C prometheus.Collector](reg prometheus.Registerer, c C) C {
+	err := reg.Register(c)
+	if err == nil {
+		return c
+	}
+	var are prometheus.AlreadyRegisteredError
+	if errors.As(err, &are) {
+		if existing, ok := are.ExistingCollector.(C); ok {
+			return existing
+		}
+	}
+	hlog.CtxWarnf(context.Background(), "[Metrics] register metrics failed: %v", err)
+	return c
+}
+
+// redactedURL 返回不含查询参数与用户信息的地址，避免在日志与 span 中泄露凭证
+func redactedURL(req *http.Request) string {
+	return req.URL.Scheme + "://" + req.URL.Host + req.URL.Path
+}
@@ -0,0 +1,135 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/ZampoRen/go-server-comon/pkg/breaker"
+	"github.com/ZampoRen/go-server-comon/pkg/retry"
+)
+
+// IdempotencyKeyHeader 声明请求可安全重试的请求头，带有该请求头的非幂等请求同样会被重试
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// retryTransport 为每次尝试设置超时，并按 retry.Do 重试可重试的请求
+type retryTransport struct {
+	next         http.RoundTripper
+	timeout      time.Duration
+	retryOptions []retry.Option
+}
+
+// statusError 可重试的响应状态码，用于触发重试，不会返回给调用方
+type statusError struct {
+	statusCode int
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("httpclient: retryable status %d", e.statusCode)
+}
+
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	opts := append([]retry.Option{retry.WithRetryIf(shouldRetry)}, t.retryOptions...)
+	if !canRetry(req) {
+		opts = append(opts, retry.WithMaxAttempts(1))
+	}
+
+	var resp *http.Response
+	attempts := 0
+	err := retry.Do(req.Context(), func(ctx context.Context) error {
+		if resp != nil {
+			drain(resp)
+			resp = nil
+		}
+		r := req
+		if attempts > 0 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return retry.Permanent(err)
+			}
+			r = req.Clone(ctx)
+			r.Body = body
+		}
+		attempts++
+
+		var err error
+		resp, err = t.attempt(r)
+		if err != nil {
+			return err
+		}
+		if retryableStatus(resp.StatusCode) {
+			return &statusError{statusCode: resp.StatusCode}
+		}
+		return nil
+	}, opts...)
+
+	var se *statusError
+	if errors.As(err, &se) {
+		// 重试用尽时返回最后一次的响应，由调用方按状态码处理
+		return resp, nil
+	}
+	return resp, err
+}
+
+// attempt 发送一次请求，ctx 没有 deadline 时设置单次超时，超时在响应体关闭时释放
+func (t *retryTransport) attempt(req *http.Request) (*http.Response, error) {
+	if _, ok := req.Context().Deadline(); ok || t.timeout <= 0 {
+		return t.next.RoundTrip(req)
+	}
+	ctx, cancel := context.WithTimeout(req.Context(), t.timeout)
+	resp, err := t.next.RoundTrip(req.WithContext(ctx))
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	resp.Body = &cancelBody{ReadCloser: resp.Body, cancel: cancel}
+	return resp, nil
+}
+
+// canRetry 幂等方法或带 Idempotency-Key 的请求，且请求体可以重新读取时才能重试
+func canRetry(req *http.Request) bool {
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return false
+	}
+	switch req.Method {
+	case "", http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete, http.MethodTrace:
+		return true
+	}
+	return req.Header.Get(IdempotencyKeyHeader) != ""
+}
+
+func shouldRetry(err error) bool {
+	if errors.Is(err, breaker.ErrOpen) || errors.Is(err, breaker.ErrTooManyProbes) {
+		return false
+	}
+	var se *statusError
+	return errors.As(err, &se) || retry.IsRetryable(err)
+}
+
+func retryableStatus(code int) bool {
+	switch code {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// drain 读取并丢弃重试前的响应体，使连接可以复用
+func drain(resp *http.Response) {
+	_, _ = io.CopyN(io.Discard, resp.Body, 64<<10)
+	_ = resp.Body.Close()
+}
+
+type cancelBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
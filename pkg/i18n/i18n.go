@@ -0,0 +1,209 @@
+// Package i18n 按语言加载消息包并渲染本地化消息，支持 errorx 错误消息的本地化
+//
+// 消息包为 YAML 或 JSON 文件，文件名（不含扩展名）即语言标签，嵌套的键以 . 连接，占位符与 errorx 相同为 {name}：
+//
+//	# locales/en.yaml
+//	user:
+//	  welcome: "Welcome, {name}"
+//	error:
+//	  "100001": "Invalid parameter"
+//
+//	bundle := i18n.NewBundle("zh-CN")
+//	if err := bundle.LoadDir("locales"); err != nil {
+//		log.Fatal(err)
+//	}
+//	l := bundle.Localizer(bundle.Match(acceptLanguage))
+//	msg := l.T("user.welcome", "name", "Tom")
+//
+// errorx 错误的消息键为 error.<错误码>，见 Localizer.Error；Hertz 服务注册 httpmw.Locale 后，
+// response.Error 会按请求的语言返回错误消息
+package i18n
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// Bundle 各语言的消息包，加载完成后可并发使用
+type Bundle struct {
+	defaultLocale string
+
+	mu       sync.RWMutex
+	messages map[string]map[string]string // locale -> key -> message
+}
+
+// NewBundle 创建消息包，请求的语言都不支持时使用 defaultLocale
+func NewBundle(defaultLocale string) *Bundle {
+	return &Bundle{
+		defaultLocale: Canonical(defaultLocale),
+		messages:      make(map[string]map[string]string),
+	}
+}
+
+// DefaultLocale 返回默认语言
+func (b *Bundle) DefaultLocale() string {
+	return b.defaultLocale
+}
+
+// AddMessages 添加 locale 的消息，已存在的键被覆盖
+func (b *Bundle) AddMessages(locale string, messages map[string]string) {
+	locale = Canonical(locale)
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	m, ok := b.messages[locale]
+	if !ok {
+		m = make(map[string]string, len(messages))
+		b.messages[locale] = m
+	}
+	for k, v := range messages {
+		m[k] = v
+	}
+}
+
+// Parse 按 format（yaml、yml 或 json）解析消息并添加到 locale
+func (b *Bundle) Parse(locale, format string, data []byte) error {
+	var raw map[string]any
+	switch strings.ToLower(format) {
+	case "yaml", "yml":
+		if err := yaml.Unmarshal(data, &raw); err != nil {
+			return fmt.Errorf("i18n: parse %s messages failed: %w", locale, err)
+		}
+	case "json":
+		if err := json.Unmarshal(data, &raw); err != nil {
+			return fmt.Errorf("i18n: parse %s messages failed: %w", locale, err)
+		}
+	default:
+		return fmt.Errorf("i18n: unsupported format %q", format)
+	}
+
+	messages := make(map[string]string)
+	if err := flatten("", raw, messages); err != nil {
+		return fmt.Errorf("i18n: parse %s messages failed: %w", locale, err)
+	}
+	b.AddMessages(locale, messages)
+	return nil
+}
+
+// LoadFile 加载消息文件，语言为文件名去掉扩展名，如 zh-CN.yaml、en.json
+func (b *Bundle) LoadFile(name string) error {
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return fmt.Errorf("i18n: %w", err)
+	}
+	locale, format := splitName(filepath.Base(name))
+	return b.Parse(locale, format, data)
+}
+
+// LoadDir 加载目录下所有 .yaml、.yml 与 .json 消息文件
+func (b *Bundle) LoadDir(dir string) error {
+	return b.LoadFS(os.DirFS(dir), ".")
+}
+
+// LoadFS 加载 fsys 中 dir 目录下所有 .yaml、.yml 与 .json 消息文件，可配合 embed.FS 使用
+func (b *Bundle) LoadFS(fsys fs.FS, dir string) error {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return fmt.Errorf("i18n: %w", err)
+	}
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		locale, format := splitName(e.Name())
+		if format != "yaml" && format != "yml" && format != "json" {
+			continue
+		}
+		data, err := fs.ReadFile(fsys, path.Join(dir, e.Name()))
+		if err != nil {
+			return fmt.Errorf("i18n: %w", err)
+		}
+		if err := b.Parse(locale, format, data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Locales 返回已加载的语言，按字母序排列
+func (b *Bundle) Locales() []string {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	locales := make([]string, 0, len(b.messages))
+	for l := range b.messages {
+		locales = append(locales, l)
+	}
+	sort.Strings(locales)
+	return locales
+}
+
+// message 按语言回退链查找消息：locale、locale 的基础语言（zh-CN -> zh）、默认语言及其基础语言
+func (b *Bundle) message(locale, key string) (string, bool) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	for _, l := range fallbacks(locale, b.defaultLocale) {
+		if msg, ok := b.messages[l][key]; ok {
+			return msg, true
+		}
+	}
+	return "", false
+}
+
+// Localizer 返回使用 locale 的本地化器
+func (b *Bundle) Localizer(locale string) *Localizer {
+	if locale == "" {
+		locale = b.defaultLocale
+	}
+	return &Localizer{bundle: b, locale: Canonical(locale)}
+}
+
+func splitName(name string) (locale, format string) {
+	ext := path.Ext(name)
+	return strings.TrimSuffix(name, ext), strings.ToLower(strings.TrimPrefix(ext, "."))
+}
+
+// flatten 将嵌套的消息展开为以 . 连接的键
+func flatten(prefix string, raw map[string]any, out map[string]string) error {
+	for k, v := range raw {
+		key := k
+		if prefix != "" {
+			key = prefix + "." + k
+		}
+		switch v := v.(type) {
+		case string:
+			out[key] = v
+		case map[string]any:
+			if err := flatten(key, v, out); err != nil {
+				return err
+			}
+		case map[any]any:
+			// YAML 中以数字为键的映射，如 error 下的错误码
+			m := make(map[string]any, len(v))
+			for mk, mv := range v {
+				m[fmt.Sprint(mk)] = mv
+			}
+			if err := flatten(key, m, out); err != nil {
+				return err
+			}
+		case int, int64, float64, bool:
+			out[key] = fmt.Sprint(v)
+		default:
+			return fmt.Errorf("unsupported value of key %q: %T", key, v)
+		}
+	}
+	return nil
+}
+
+// ErrorKey 返回 errorx 错误码对应的消息键
+func ErrorKey(code int32) string {
+	return "error." + strconv.FormatInt(int64(code), 10)
+}
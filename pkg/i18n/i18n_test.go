@@ -0,0 +1,111 @@
+package i18n
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/ZampoRen/go-server-comon/pkg/errorx"
+	"github.com/ZampoRen/go-server-comon/pkg/errorx/code"
+)
+
+const codeUserNotFound int32 = 990201
+
+func init() {
+	code.Register(codeUserNotFound, "user {user_id} missing")
+}
+
+func newTestBundle(t *testing.T) *Bundle {
+	t.Helper()
+	b := NewBundle("en")
+	if err := b.LoadDir("testdata"); err != nil {
+		t.Fatalf("LoadDir: %v", err)
+	}
+	return b
+}
+
+func TestLocalize(t *testing.T) {
+	b := newTestBundle(t)
+	if got := b.Locales(); !reflect.DeepEqual(got, []string{"en", "zh-CN"}) {
+		t.Fatalf("Locales = %v", got)
+	}
+
+	tests := []struct {
+		locale, want string
+	}{
+		{"zh-CN", "欢迎，Tom"},
+		{"en", "Welcome, Tom"},
+		{"en-GB", "Welcome, Tom"}, // 回退到基础语言
+		{"fr", "Welcome, Tom"},    // 回退到默认语言
+	}
+	for _, tt := range tests {
+		if got := b.Localizer(tt.locale).T("user.welcome", "name", "Tom"); got != tt.want {
+			t.Errorf("T(%s) = %q, want %q", tt.locale, got, tt.want)
+		}
+	}
+	if got := b.Localizer("en").T("missing.key"); got != "missing.key" {
+		t.Errorf("T(missing) = %q, want key", got)
+	}
+}
+
+func TestLocalizeError(t *testing.T) {
+	b := newTestBundle(t)
+	err := errorx.New(codeUserNotFound, errorx.KV("user_id", "42"))
+
+	msg, ok := b.Localizer("zh-CN").Error(err)
+	if !ok || msg != "用户 42 不存在" {
+		t.Fatalf("Error = %q, %v", msg, ok)
+	}
+	msg, ok = b.Localizer("en").Error(errorx.WrapByCode(context.Canceled, codeUserNotFound, errorx.KV("user_id", "7")))
+	if !ok || msg != "User 7 not found" {
+		t.Fatalf("Error = %q, %v", msg, ok)
+	}
+	if _, ok := b.Localizer("en").Error(context.Canceled); ok {
+		t.Fatal("non errorx error localized")
+	}
+}
+
+func TestContext(t *testing.T) {
+	b := newTestBundle(t)
+	if got := T(context.Background(), "user.welcome"); got != "user.welcome" {
+		t.Fatalf("T without localizer = %q", got)
+	}
+	ctx := NewContext(context.Background(), b.Localizer("zh-CN"))
+	if got := T(ctx, "user.welcome", "name", "Tom"); got != "欢迎，Tom" {
+		t.Fatalf("T = %q", got)
+	}
+}
+
+func TestParseAcceptLanguage(t *testing.T) {
+	got := ParseAcceptLanguage("en;q=0.8, zh-cn, zh;q=0.9, *;q=0.1, fr;q=0")
+	want := []string{"zh-CN", "zh", "en"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("ParseAcceptLanguage = %v, want %v", got, want)
+	}
+}
+
+func TestMatch(t *testing.T) {
+	b := newTestBundle(t)
+	tests := []struct {
+		header, want string
+	}{
+		{"zh-CN,zh;q=0.9", "zh-CN"},
+		{"zh", "zh-CN"}, // 基础语言匹配到已加载的地区语言
+		{"en-US,en;q=0.9", "en"},
+		{"fr, de", "en"},
+		{"", "en"},
+	}
+	for _, tt := range tests {
+		if got := b.Match(tt.header); got != tt.want {
+			t.Errorf("Match(%q) = %q, want %q", tt.header, got, tt.want)
+		}
+	}
+}
+
+func TestCanonical(t *testing.T) {
+	for in, want := range map[string]string{"zh_cn": "zh-CN", "EN": "en", "zh-hans-cn": "zh-Hans-CN"} {
+		if got := Canonical(in); got != want {
+			t.Errorf("Canonical(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package i18n
+
+import (
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Canonical 规范化语言标签：下划线换为连字符，语言小写、地区大写，如 zh_cn -> zh-CN
+func Canonical(tag string) string {
+	parts := strings.Split(strings.ReplaceAll(strings.TrimSpace(tag), "_", "-"), "-")
+	for i, p := range parts {
+		switch {
+		case i == 0:
+			parts[i] = strings.ToLower(p)
+		case len(p) == 2:
+			parts[i] = strings.ToUpper(p)
+		case len(p) == 4:
+			// 文字代码，如 Hans
+			parts[i] = strings.ToUpper(p[:1]) + strings.ToLower(p[1:])
+		default:
+			parts[i] = strings.ToLower(p)
+		}
+	}
+	return strings.Join(parts, "-")
+}
+
+// base 返回语言标签的基础语言，如 zh-CN -> zh
+func base(tag string) string {
+	if i := strings.IndexByte(tag, '-'); i > 0 {
+		return tag[:i]
+	}
+	return tag
+}
+
+// fallbacks 返回查找消息的语言顺序
+func fallbacks(locale, defaultLocale string) []string {
+	chain := make([]string, 0, 4)
+	for _, l := range []string{locale, base(locale), defaultLocale, base(defaultLocale)} {
+		if l != "" && !slices.Contains(chain, l) {
+			chain = append(chain, l)
+		}
+	}
+	return chain
+}
+
+// ParseAcceptLanguage 解析 Accept-Language 请求头，按权重从高到低返回规范化的语言标签，忽略 * 与 q=0 的语言
+//
+//	ParseAcceptLanguage("zh-CN,zh;q=0.9,en;q=0.8") // [zh-CN zh en]
+func ParseAcceptLanguage(header string) []string {
+	type weighted struct {
+		tag string
+		q   float64
+	}
+	var tags []weighted
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		tag = strings.TrimSpace(tag)
+		if tag == "" || tag == "*" {
+			continue
+		}
+		q := 1.0
+		for _, p := range strings.Split(params, ";") {
+			if v, ok := strings.CutPrefix(strings.TrimSpace(p), "q="); ok {
+				if f, err := strconv.ParseFloat(v, 64); err == nil {
+					q = f
+				}
+			}
+		}
+		if q > 0 {
+			tags = append(tags, weighted{tag: Canonical(tag), q: q})
+		}
+	}
+	sort.SliceStable(tags, func(i, j int) bool { return tags[i].q > tags[j].q })
+
+	locales := make([]string, len(tags))
+	for i, t := range tags {
+		locales[i] = t.tag
+	}
+	return locales
+}
+
+// Match 按 Accept-Language 请求头选择已加载的语言：依次尝试各语言标签及其基础语言，
+// 以及与请求的基础语言相同的已加载语言（请求 zh 时匹配 zh-CN），都不支持时返回默认语言
+func (b *Bundle) Match(acceptLanguage string) string {
+	return b.MatchLocales(ParseAcceptLanguage(acceptLanguage)...)
+}
+
+// MatchLocales 同 Match，locales 为按优先级排列的语言标签
+func (b *Bundle) MatchLocales(locales ...string) string {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	for _, l := range locales {
+		l = Canonical(l)
+		if _, ok := b.messages[l]; ok {
+			return l
+		}
+		if _, ok := b.messages[base(l)]; ok {
+			return base(l)
+		}
+		var candidates []string
+		for loaded := range b.messages {
+			if base(loaded) == base(l) {
+				candidates = append(candidates, loaded)
+			}
+		}
+		if len(candidates) > 0 {
+			sort.Strings(candidates)
+			return candidates[0]
+		}
+	}
+	return b.defaultLocale
+}
@@ -0,0 +1,91 @@
+package i18n
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/ZampoRen/go-server-comon/pkg/errorx"
+)
+
+// Localizer 按指定语言渲染消息，nil 时所有方法都返回未本地化的结果
+type Localizer struct {
+	bundle *Bundle
+	locale string
+}
+
+// Locale 返回本地化器的语言，nil 时返回空字符串
+func (l *Localizer) Locale() string {
+	if l == nil {
+		return ""
+	}
+	return l.locale
+}
+
+// Localize 渲染 key 对应的消息，params 替换消息中的 {name} 占位符，找不到消息时返回 false
+func (l *Localizer) Localize(key string, params map[string]string) (string, bool) {
+	if l == nil {
+		return "", false
+	}
+	msg, ok := l.bundle.message(l.locale, key)
+	if !ok {
+		return "", false
+	}
+	return render(msg, params), true
+}
+
+// T 渲染 key 对应的消息，kv 为成对的占位符名与值，找不到消息时返回 key
+//
+//	l.T("user.welcome", "name", "Tom")
+func (l *Localizer) T(key string, kv ...string) string {
+	var params map[string]string
+	if len(kv) > 0 {
+		params = make(map[string]string, len(kv)/2)
+		for i := 0; i+1 < len(kv); i += 2 {
+			params[kv[i]] = kv[i+1]
+		}
+	}
+	if msg, ok := l.Localize(key, params); ok {
+		return msg
+	}
+	return key
+}
+
+// Error 返回 errorx 错误的本地化消息，消息键为 ErrorKey(错误码)，占位符使用创建错误时通过 errorx.KV 传入的参数
+// err 不是 errorx 错误或找不到消息时返回 false，调用方应继续使用 StatusError.Msg()
+func (l *Localizer) Error(err error) (string, bool) {
+	var se errorx.StatusError
+	if l == nil || !errors.As(err, &se) {
+		return "", false
+	}
+	return l.Localize(ErrorKey(se.Code()), errorx.Params(err))
+}
+
+func render(msg string, params map[string]string) string {
+	if len(params) == 0 || !strings.Contains(msg, "{") {
+		return msg
+	}
+	pairs := make([]string, 0, len(params)*2)
+	for k, v := range params {
+		pairs = append(pairs, "{"+k+"}", v)
+	}
+	return strings.NewReplacer(pairs...).Replace(msg)
+}
+
+type localizerKey struct{}
+
+// NewContext 返回携带 l 的 ctx
+func NewContext(ctx context.Context, l *Localizer) context.Context {
+	return context.WithValue(ctx, localizerKey{}, l)
+}
+
+// FromContext 返回 ctx 中的本地化器，没有时返回 nil
+func FromContext(ctx context.Context) *Localizer {
+	l, _ := ctx.Value(localizerKey{}).(*Localizer)
+	return l
+}
+
+// T 使用 ctx 中的本地化器渲染消息，没有本地化器或找不到消息时返回 key
+func T(ctx context.Context, key string, kv ...string) string {
+	return FromContext(ctx).T(key, kv...)
+}
@@ -0,0 +1,271 @@
+// Package lifecycle 统一管理服务组件的启动与优雅退出
+//
+//	lc := lifecycle.New()
+//	lc.Append(lifecycle.Hook{Name: "logger", OnStop: func(context.Context) error { return logger.Default().Sync() }})
+//	lc.Append(lifecycle.Hook{Name: "di", OnStart: container.Start, OnStop: container.Stop})
+//	lc.Append(lifecycle.Hook{Name: "localcache", OnStop: func(context.Context) error { cache.Stop(); return nil }})
+//	lc.AppendServer("http", func() error { return h.Run() }, h.Shutdown)
+//	if err := lc.Run(context.Background()); err != nil {
+//		hlog.Fatalf("server exited: %v", err)
+//	}
+//
+// 钩子按注册顺序启动、按相反顺序停止，先注册基础组件（日志、连接池），再注册依赖它们的服务。
+// Run 在收到 SIGINT/SIGTERM、ctx 结束或某个服务异常退出时开始停止，停止期间再次收到信号时立即退出进程
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/common/hlog"
+
+	"github.com/ZampoRen/go-server-comon/pkg/envkey"
+)
+
+// Hook 组件的启动与停止函数
+type Hook struct {
+	Name    string
+	OnStart func(ctx context.Context) error
+	OnStop  func(ctx context.Context) error
+	// Timeout 单次 OnStart 与 OnStop 的超时时间，<= 0 时只受整体超时限制
+	Timeout time.Duration
+}
+
+// Option 生命周期选项
+type Option func(o *option)
+
+type option struct {
+	startTimeout time.Duration
+	stopTimeout  time.Duration
+	signals      []os.Signal
+}
+
+// WithStartTimeout 设置启动全部组件的超时时间，默认 30s
+func WithStartTimeout(d time.Duration) Option {
+	return func(o *option) {
+		if d > 0 {
+			o.startTimeout = d
+		}
+	}
+}
+
+// WithStopTimeout 设置停止全部组件的超时时间，默认读取环境变量 SHUTDOWN_TIMEOUT，未设置时为 30s
+// 应小于 Kubernetes 的 terminationGracePeriodSeconds
+func WithStopTimeout(d time.Duration) Option {
+	return func(o *option) {
+		if d > 0 {
+			o.stopTimeout = d
+		}
+	}
+}
+
+// WithSignals 设置触发退出的信号，默认 SIGINT 与 SIGTERM
+func WithSignals(signals ...os.Signal) Option {
+	return func(o *option) {
+		o.signals = signals
+	}
+}
+
+// Lifecycle 组件生命周期管理
+type Lifecycle struct {
+	o *option
+
+	mu      sync.Mutex
+	hooks   []Hook
+	started int
+
+	failOnce sync.Once
+	failed   chan error
+}
+
+// New 创建生命周期管理
+func New(opts ...Option) *Lifecycle {
+	o := &option{
+		startTimeout: 30 * time.Second,
+		stopTimeout:  envkey.GetDurationD("SHUTDOWN_TIMEOUT", 30*time.Second),
+		signals:      []os.Signal{syscall.SIGINT, syscall.SIGTERM},
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return &Lifecycle{o: o, failed: make(chan error, 1)}
+}
+
+// Append 追加钩子
+func (l *Lifecycle) Append(h Hook) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.hooks = append(l.hooks, h)
+}
+
+// AppendServer 追加阻塞运行的服务，如 Hertz、gRPC、管理端口
+// serve 在后台运行，在 Stop 之前返回（包括返回 nil）视为异常退出，Run 随即停止所有组件；
+// shutdown 应使 serve 返回并等待进行中的请求完成
+func (l *Lifecycle) AppendServer(name string, serve func() error, shutdown func(ctx context.Context) error) {
+	var stopping sync.Once
+	stopped := make(chan struct{})
+	l.Append(Hook{
+		Name: name,
+		OnStart: func(context.Context) error {
+			go func() {
+				err := serve()
+				select {
+				case <-stopped:
+					return
+				default:
+				}
+				if err == nil {
+					err = errors.New("exited unexpectedly")
+				}
+				l.fail(fmt.Errorf("lifecycle: server %s: %w", name, err))
+			}()
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			stopping.Do(func() { close(stopped) })
+			return shutdown(ctx)
+		},
+	})
+}
+
+// fail 报告服务异常退出，只保留第一个错误
+func (l *Lifecycle) fail(err error) {
+	l.failOnce.Do(func() {
+		l.failed <- err
+	})
+}
+
+// Start 按注册顺序执行 OnStart，某个钩子失败时已启动的钩子按相反顺序停止后返回错误
+func (l *Lifecycle) Start(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, l.o.startTimeout)
+	defer cancel()
+
+	l.mu.Lock()
+	hooks := append([]Hook(nil), l.hooks...)
+	l.mu.Unlock()
+
+	for i, h := range hooks {
+		if h.OnStart != nil {
+			if err := run(ctx, h, h.OnStart); err != nil {
+				l.setStarted(i)
+				stopCtx, stopCancel := context.WithTimeout(context.WithoutCancel(ctx), l.o.stopTimeout)
+				defer stopCancel()
+				return errors.Join(fmt.Errorf("lifecycle: start %s: %w", h.Name, err), l.Stop(stopCtx))
+			}
+		}
+		l.setStarted(i + 1)
+	}
+	return nil
+}
+
+func (l *Lifecycle) setStarted(n int) {
+	l.mu.Lock()
+	l.started = n
+	l.mu.Unlock()
+}
+
+// Stop 按相反顺序执行已启动钩子的 OnStop，某个钩子失败或超时不影响其余钩子，返回所有失败钩子的错误
+func (l *Lifecycle) Stop(ctx context.Context) error {
+	l.mu.Lock()
+	hooks := append([]Hook(nil), l.hooks[:l.started]...)
+	l.started = 0
+	l.mu.Unlock()
+
+	var errs []error
+	for i := len(hooks) - 1; i >= 0; i-- {
+		h := hooks[i]
+		if h.OnStop == nil {
+			continue
+		}
+		start := time.Now()
+		if err := run(ctx, h, h.OnStop); err != nil {
+			hlog.CtxErrorf(ctx, "[Lifecycle] stop %s failed after %s: %v", h.Name, time.Since(start), err)
+			errs = append(errs, fmt.Errorf("lifecycle: stop %s: %w", h.Name, err))
+			continue
+		}
+		hlog.CtxInfof(ctx, "[Lifecycle] stopped %s in %s", h.Name, time.Since(start))
+	}
+	return errors.Join(errs...)
+}
+
+// Run 启动所有组件并阻塞，直到收到退出信号、ctx 结束或某个服务异常退出，随后在停止超时内停止所有组件
+// 服务异常退出时返回其错误与停止过程中的错误
+func (l *Lifecycle) Run(ctx context.Context) error {
+	if err := l.Start(ctx); err != nil {
+		return err
+	}
+
+	sig := make(chan os.Signal, 2)
+	signal.Notify(sig, l.o.signals...)
+	defer signal.Stop(sig)
+
+	var cause error
+	select {
+	case s := <-sig:
+		hlog.CtxInfof(ctx, "[Lifecycle] received signal %s, shutting down", s)
+	case <-ctx.Done():
+		hlog.CtxInfof(ctx, "[Lifecycle] context done, shutting down")
+	case cause = <-l.failed:
+		hlog.CtxErrorf(ctx, "[Lifecycle] %v, shutting down", cause)
+	}
+
+	stopCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), l.o.stopTimeout)
+	defer cancel()
+	go func() {
+		select {
+		case s := <-sig:
+			hlog.CtxErrorf(ctx, "[Lifecycle] received signal %s again, exit immediately", s)
+			os.Exit(1)
+		case <-stopCtx.Done():
+		}
+	}()
+	return errors.Join(cause, l.Stop(stopCtx))
+}
+
+// run 在钩子的超时时间内执行 fn，fn 未在超时前返回时不再等待
+func run(ctx context.Context, h Hook, fn func(ctx context.Context) error) error {
+	if h.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, h.Timeout)
+		defer cancel()
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- fn(ctx)
+	}()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+var defaultLifecycle = New()
+
+// Default 返回默认的生命周期管理
+func Default() *Lifecycle {
+	return defaultLifecycle
+}
+
+// Append 向默认的生命周期管理追加钩子
+func Append(h Hook) {
+	defaultLifecycle.Append(h)
+}
+
+// AppendServer 向默认的生命周期管理追加阻塞运行的服务
+func AppendServer(name string, serve func() error, shutdown func(ctx context.Context) error) {
+	defaultLifecycle.AppendServer(name, serve, shutdown)
+}
+
+// Run 使用默认的生命周期管理启动并等待退出
+func Run(ctx context.Context) error {
+	return defaultLifecycle.Run(ctx)
+}
@@ -0,0 +1,86 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestLifecycle(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("按注册顺序启动并按相反顺序停止", func(t *testing.T) {
+		var events []string
+		l := New()
+		for _, name := range []string{"db", "cache", "http"} {
+			l.Append(Hook{
+				Name:    name,
+				OnStart: func(context.Context) error { events = append(events, "start "+name); return nil },
+				OnStop:  func(context.Context) error { events = append(events, "stop "+name); return nil },
+			})
+		}
+		if err := l.Start(ctx); err != nil {
+			t.Fatal(err)
+		}
+		if err := l.Stop(ctx); err != nil {
+			t.Fatal(err)
+		}
+		want := []string{"start db", "start cache", "start http", "stop http", "stop cache", "stop db"}
+		if len(events) != len(want) {
+			t.Fatalf("events = %v", events)
+		}
+		for i := range want {
+			if events[i] != want[i] {
+				t.Fatalf("events = %v, want %v", events, want)
+			}
+		}
+	})
+
+	t.Run("启动失败时停止已启动的组件", func(t *testing.T) {
+		stopped := false
+		l := New()
+		l.Append(Hook{Name: "db", OnStop: func(context.Context) error { stopped = true; return nil }})
+		l.Append(Hook{Name: "cache", OnStart: func(context.Context) error { return errors.New("refused") }})
+		if err := l.Start(ctx); err == nil || !stopped {
+			t.Errorf("err = %v, stopped = %v", err, stopped)
+		}
+	})
+
+	t.Run("停止超时不影响其余组件", func(t *testing.T) {
+		stopped := false
+		l := New()
+		l.Append(Hook{Name: "db", OnStop: func(context.Context) error { stopped = true; return nil }})
+		l.Append(Hook{Name: "slow", Timeout: 10 * time.Millisecond, OnStop: func(ctx context.Context) error {
+			time.Sleep(time.Second)
+			return nil
+		}})
+		_ = l.Start(ctx)
+		if err := l.Stop(ctx); !errors.Is(err, context.DeadlineExceeded) || !stopped {
+			t.Errorf("err = %v, stopped = %v", err, stopped)
+		}
+	})
+
+	t.Run("服务异常退出时停止所有组件", func(t *testing.T) {
+		stopped := false
+		l := New()
+		l.Append(Hook{Name: "db", OnStop: func(context.Context) error { stopped = true; return nil }})
+		l.AppendServer("http", func() error { return errors.New("address in use") },
+			func(context.Context) error { return nil })
+		if err := l.Run(ctx); err == nil || !stopped {
+			t.Errorf("err = %v, stopped = %v", err, stopped)
+		}
+	})
+
+	t.Run("ctx 结束时正常退出", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(ctx)
+		done := make(chan struct{})
+		l := New()
+		l.AppendServer("http", func() error { <-done; return nil },
+			func(context.Context) error { close(done); return nil })
+		time.AfterFunc(10*time.Millisecond, cancel)
+		if err := l.Run(ctx); err != nil {
+			t.Errorf("err = %v", err)
+		}
+	})
+}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/ZampoRen/go-server-comon/pkg/localcache/link"
 	"github.com/ZampoRen/go-server-comon/pkg/localcache/lru"
+	"github.com/ZampoRen/go-server-comon/pkg/singleflight"
 )
 
 type Cache[V any] interface {
@@ -28,7 +29,7 @@ func New[V any](opts ...Option) Cache[V] {
 		o(opt)
 	}
 
-	c := cache[V]{opt: opt}
+	c := cache[V]{opt: opt, sf: singleflight.New[V]()}
 	if opt.localSlotNum > 0 && opt.localSlotSize > 0 {
 		createSimpleLRU := func() lru.LRU[string, V] {
 			if opt.expirationEvict {
@@ -53,6 +54,8 @@ type cache[V any] struct {
 	opt   *option
 	link  link.Link
 	local lru.LRU[string, V]
+	// sf 本地缓存禁用时合并相同 key 的并发 fetch
+	sf *singleflight.Group[V]
 }
 
 func (c *cache[V]) onEvict(key string, value V) {
@@ -96,7 +99,7 @@ func (c *cache[V]) GetLink(ctx context.Context, key string, fetch func(ctx conte
 			return fetch(ctx)
 		})
 	} else {
-		return fetch(ctx)
+		return c.sf.Do(ctx, key, fetch)
 	}
 }
 
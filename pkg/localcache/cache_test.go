@@ -505,6 +505,41 @@ func TestCache_LocalDisable(t *testing.T) {
 	}
 }
 
+// TestCache_LocalDisable_Coalesce 测试禁用本地缓存时合并相同 key 的并发 fetch
+func TestCache_LocalDisable_Coalesce(t *testing.T) {
+	cache := New[string](
+		WithLocalDisable(),
+	)
+	defer cache.Stop()
+
+	var (
+		fetchCount atomic.Int32
+		wg         sync.WaitGroup
+	)
+	release := make(chan struct{})
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			value, err := cache.Get(context.Background(), "key1", func(ctx context.Context) (string, error) {
+				fetchCount.Add(1)
+				<-release
+				return "value1", nil
+			})
+			if err != nil || value != "value1" {
+				t.Errorf("Get() = %v, %v, want value1", value, err)
+			}
+		}()
+	}
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if n := fetchCount.Load(); n != 1 {
+		t.Errorf("fetch called %d times, want 1", n)
+	}
+}
+
 // TestCache_Expiration 测试过期策略
 func TestCache_Expiration(t *testing.T) {
 	cache := New[string](
@@ -44,7 +44,7 @@
 //	WithLocalFailedTTL(d)    - 设置获取失败的数据的 TTL（默认：5秒）
 //	WithExpirationEvict()    - 使用主动过期策略
 //	WithLazy()               - 使用懒删除策略（默认）
-//	WithLocalDisable()       - 禁用本地缓存
+//	WithLocalDisable()       - 禁用本地缓存，相同 key 的并发 fetch 仍通过 singleflight 合并
 //	WithLinkDisable()        - 禁用键关联功能
 //	WithTarget(target)       - 设置统计目标
 //	WithDeleteKeyBefore(fn)  - 设置删除前的回调函数
@@ -0,0 +1,73 @@
+package localcache
+
+import (
+	"sort"
+	"sync"
+	"sync/atomic"
+)
+
+// Stats 缓存统计
+type Stats struct {
+	Name        string  `json:"name"`
+	GetHit      int64   `json:"get_hit"`       // 命中次数
+	GetSuccess  int64   `json:"get_success"`   // 未命中且回源成功的次数
+	GetFailed   int64   `json:"get_failed"`    // 未命中且回源失败的次数
+	DelHit      int64   `json:"del_hit"`       // 删除时键存在的次数
+	DelNotFound int64   `json:"del_not_found"` // 删除时键不存在的次数
+	HitRatio    float64 `json:"hit_ratio"`     // 命中率
+}
+
+// StatsTarget 计数的 lru.Target，通过 WithStats 创建的实例会登记到 AllStats
+type StatsTarget struct {
+	name        string
+	getHit      atomic.Int64
+	getSuccess  atomic.Int64
+	getFailed   atomic.Int64
+	delHit      atomic.Int64
+	delNotFound atomic.Int64
+}
+
+func (t *StatsTarget) IncrGetHit() { t.getHit.Add(1) }
+
+func (t *StatsTarget) IncrGetSuccess() { t.getSuccess.Add(1) }
+
+func (t *StatsTarget) IncrGetFailed() { t.getFailed.Add(1) }
+
+func (t *StatsTarget) IncrDelHit() { t.delHit.Add(1) }
+
+func (t *StatsTarget) IncrDelNotFound() { t.delNotFound.Add(1) }
+
+// Stats 返回当前统计
+func (t *StatsTarget) Stats() Stats {
+	s := Stats{
+		Name:        t.name,
+		GetHit:      t.getHit.Load(),
+		GetSuccess:  t.getSuccess.Load(),
+		GetFailed:   t.getFailed.Load(),
+		DelHit:      t.delHit.Load(),
+		DelNotFound: t.delNotFound.Load(),
+	}
+	if total := s.GetHit + s.GetSuccess + s.GetFailed; total > 0 {
+		s.HitRatio = float64(s.GetHit) / float64(total)
+	}
+	return s
+}
+
+var statsTargets sync.Map // name -> *StatsTarget
+
+// WithStats 开启命中率统计，统计结果以 name 登记，可通过 AllStats 查看，同名缓存共享统计
+func WithStats(name string) Option {
+	v, _ := statsTargets.LoadOrStore(name, &StatsTarget{name: name})
+	return WithTarget(v.(*StatsTarget))
+}
+
+// AllStats 返回通过 WithStats 开启统计的所有缓存的统计，按名称排序
+func AllStats() []Stats {
+	var all []Stats
+	statsTargets.Range(func(_, v any) bool {
+		all = append(all, v.(*StatsTarget).Stats())
+		return true
+	})
+	sort.Slice(all, func(i, j int) bool { return all[i].Name < all[j].Name })
+	return all
+}
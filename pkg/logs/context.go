@@ -0,0 +1,29 @@
+package logger
+
+import (
+	"context"
+
+	hertzzap "github.com/hertz-contrib/logger/zap"
+)
+
+// RequestIDKey 请求 ID 在日志字段与 errorx Extra 中的键
+const RequestIDKey = "request_id"
+
+// requestIDCtxKey 请求 ID 的 ctx 键，Init 创建的日志会自动从 ctx 中取出并输出为 request_id 字段
+var requestIDCtxKey = hertzzap.ExtraKey(RequestIDKey)
+
+// ContextWithRequestID 返回携带请求 ID 的 ctx，之后使用该 ctx 的 hlog.CtxXxx 日志与 ErrorX 记录的错误都会带上请求 ID
+func ContextWithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDCtxKey, requestID)
+}
+
+// RequestIDFromContext 返回 ctx 中的请求 ID，没有时返回空字符串
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDCtxKey).(string)
+	return id
+}
+
+// contextKeysOption 从 ctx 中提取日志字段的选项
+func contextKeysOption() hertzzap.Option {
+	return hertzzap.WithExtraKeys([]hertzzap.ExtraKey{requestIDCtxKey})
+}
@@ -0,0 +1,102 @@
+package logger
+
+import (
+	"context"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/common/hlog"
+)
+
+// ESLogger Elasticsearch 日志记录器，使用 hlog 记录
+type ESLogger struct {
+	// LogLevel 日志级别
+	// 0: Silent (不记录)
+	// 1: Error (只记录错误)
+	// 2: Warn (记录慢请求和错误)
+	// 3: Info (记录所有日志)
+	LogLevel int
+	// SlowThreshold 慢请求阈值，默认 500ms
+	SlowThreshold time.Duration
+	// MaxBodyLen 日志中请求体的最大长度，超出部分截断，默认 1024
+	MaxBodyLen int
+}
+
+// NewESLogger 创建新的 Elasticsearch logger
+// level: 日志级别，0=Silent, 1=Error, 2=Warn, 3=Info
+// slowThreshold: 慢请求阈值，默认 500ms
+func NewESLogger(level int, slowThreshold time.Duration) *ESLogger {
+	if slowThreshold == 0 {
+		slowThreshold = 500 * time.Millisecond
+	}
+	return &ESLogger{
+		LogLevel:      level,
+		SlowThreshold: slowThreshold,
+		MaxBodyLen:    1024,
+	}
+}
+
+// LogLevel constants
+const (
+	ESLogLevelSilent = 0
+	ESLogLevelError  = 1
+	ESLogLevelWarn   = 2
+	ESLogLevelInfo   = 3
+)
+
+// LogRequest 记录 Elasticsearch 请求
+// method、path: 请求方法与路径
+// body: 请求体，只有搜索类请求会传入，其余为空
+// status: 响应状态码，请求失败时为 0
+// duration: 执行耗时
+// err: 错误信息（如果有）
+// 状态码 5xx 视为错误，4xx（如索引不存在）由调用方处理，按普通请求记录
+func (l *ESLogger) LogRequest(ctx context.Context, method, path string, body []byte, status int, duration time.Duration, err error) {
+	if l.LogLevel <= ESLogLevelSilent {
+		return
+	}
+
+	msg := method + " " + path
+	if len(body) > 0 {
+		msg += " " + l.formatBody(body)
+	}
+
+	switch {
+	case (err != nil || status >= 500) && l.LogLevel >= ESLogLevelError:
+		// 记录错误日志
+		hlog.CtxErrorf(ctx, "[ES] %s | Status: %d | Error: %v | Elapsed: %v", msg, status, err, duration)
+	case duration > l.SlowThreshold && l.SlowThreshold != 0 && l.LogLevel >= ESLogLevelWarn:
+		// 记录慢请求警告
+		hlog.CtxWarnf(ctx, "[ES] Slow %s | Status: %d | Elapsed: %v", msg, status, duration)
+	case l.LogLevel >= ESLogLevelInfo:
+		// 记录普通请求日志
+		hlog.CtxInfof(ctx, "[ES] %s | Status: %d | Elapsed: %v", msg, status, duration)
+	}
+}
+
+// NeedBody 本次请求是否可能输出请求体，用于避免无谓地读取请求体
+func (l *ESLogger) NeedBody() bool {
+	return l.LogLevel >= ESLogLevelWarn
+}
+
+// formatBody 截断过长的请求体，避免日志过长
+func (l *ESLogger) formatBody(body []byte) string {
+	if l.MaxBodyLen > 0 && len(body) > l.MaxBodyLen {
+		return string(body[:l.MaxBodyLen]) + "..."
+	}
+	return string(body)
+}
+
+// DefaultESLogger 返回默认的 Elasticsearch logger（Warn 级别）
+func DefaultESLogger() *ESLogger {
+	return NewESLogger(ESLogLevelWarn, 500*time.Millisecond)
+}
+
+// SilentESLogger 返回静默的 Elasticsearch logger（不记录日志）
+func SilentESLogger() *ESLogger {
+	return NewESLogger(ESLogLevelSilent, 0)
+}
+
+// InfoESLogger 返回记录所有日志的 Elasticsearch logger
+func InfoESLogger() *ESLogger {
+	return NewESLogger(ESLogLevelInfo, 500*time.Millisecond)
+}
@@ -0,0 +1,57 @@
+package logger
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base32"
+	"errors"
+
+	"github.com/cloudwego/hertz/pkg/common/hlog"
+
+	"github.com/ZampoRen/go-server-comon/pkg/errorx"
+)
+
+// IncidentIDKey 事故 ID 在 errorx Extra 中的键，响应中会透出给客户端
+const IncidentIDKey = "incident_id"
+
+// incidentEncoding 事故 ID 编码，去掉填充便于用户口头或截图反馈
+var incidentEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// ErrorX 记录错误日志并返回事故 ID
+// 如果 err 是 errorx.StatusError，会生成短事故 ID 写入错误的 Extra 和日志行，
+// 客户端通过响应中的事故 ID 反馈问题时，可以直接定位到对应日志。
+// ctx 中有请求 ID 时同时写入错误的 Extra，便于按请求 ID 关联响应与日志。
+// 同一个错误多次记录时复用已有的事故 ID；错误可能在多个 goroutine 中共享，
+// Extra 以复制后替换的方式写入，不修改已取出的 map。非 StatusError 只记录日志并返回空字符串。
+func (l *Logger) ErrorX(ctx context.Context, msg string, err error) string {
+	if err == nil {
+		hlog.CtxErrorf(ctx, "%s", msg)
+		return ""
+	}
+
+	var se errorx.StatusError
+	if !errors.As(err, &se) {
+		hlog.CtxErrorf(ctx, "%s: %v", msg, err)
+		return ""
+	}
+
+	incidentID := se.Extra()[IncidentIDKey]
+	if incidentID == "" {
+		incidentID, _ = errorx.SetExtraIfAbsent(err, IncidentIDKey, NewIncidentID())
+	}
+	if requestID := RequestIDFromContext(ctx); requestID != "" {
+		errorx.SetExtraIfAbsent(err, RequestIDKey, requestID)
+	}
+
+	hlog.CtxErrorf(ctx, "%s | incident_id=%s code=%d | %v", msg, incidentID, se.Code(), err)
+	return incidentID
+}
+
+// NewIncidentID 生成 8 位短事故 ID
+func NewIncidentID() string {
+	b := make([]byte, 5)
+	if _, err := rand.Read(b); err != nil {
+		return "00000000"
+	}
+	return incidentEncoding.EncodeToString(b)
+}
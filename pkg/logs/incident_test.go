@@ -0,0 +1,62 @@
+package logger
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/cloudwego/hertz/pkg/common/hlog"
+
+	"github.com/ZampoRen/go-server-comon/pkg/errorx"
+)
+
+func TestErrorX(t *testing.T) {
+	hlog.SetSilentMode(true)
+	defer hlog.SetSilentMode(false)
+	l := NewLogger()
+
+	t.Run("写入事故 ID 与请求 ID", func(t *testing.T) {
+		err := errorx.New(1)
+		ctx := ContextWithRequestID(context.Background(), "req-1")
+		id := l.ErrorX(ctx, "failed", err)
+		var se errorx.StatusError
+		if !errors.As(err, &se) || id == "" || se.Extra()[IncidentIDKey] != id || se.Extra()[RequestIDKey] != "req-1" {
+			t.Fatalf("incident id = %q, extra = %v", id, se.Extra())
+		}
+	})
+
+	t.Run("共享错误并发记录时复用同一个事故 ID", func(t *testing.T) {
+		err := errorx.New(1)
+		var se errorx.StatusError
+		errors.As(err, &se)
+		before := se.Extra()
+
+		ids := make([]string, 8)
+		var wg sync.WaitGroup
+		for i := range ids {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				ids[i] = l.ErrorX(context.Background(), "failed", err)
+				// 与写入并发读取，-race 下检查数据竞争
+				_ = len(se.Extra()[IncidentIDKey])
+			}(i)
+		}
+		wg.Wait()
+		for _, id := range ids {
+			if id != ids[0] {
+				t.Fatalf("incident ids = %v, want the same", ids)
+			}
+		}
+		if len(before) != 0 {
+			t.Fatalf("previously returned extra modified: %v", before)
+		}
+	})
+
+	t.Run("非 StatusError 不生成事故 ID", func(t *testing.T) {
+		if id := l.ErrorX(context.Background(), "failed", errors.New("boom")); id != "" {
+			t.Fatalf("incident id = %q, want empty", id)
+		}
+	})
+}
@@ -0,0 +1,97 @@
+package logger
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync/atomic"
+
+	"github.com/cloudwego/hertz/pkg/common/hlog"
+)
+
+// currentLevel 当前日志级别，由 Init 系列函数与 SetLevel 设置
+var currentLevel atomic.Int32
+
+func init() {
+	currentLevel.Store(int32(hlog.LevelInfo))
+}
+
+var levelNames = map[hlog.Level]string{
+	hlog.LevelTrace:  "trace",
+	hlog.LevelDebug:  "debug",
+	hlog.LevelInfo:   "info",
+	hlog.LevelNotice: "notice",
+	hlog.LevelWarn:   "warn",
+	hlog.LevelError:  "error",
+	hlog.LevelFatal:  "fatal",
+}
+
+// ParseLevel 解析日志级别，可选值: trace, debug, info, notice, warn, error, fatal
+func ParseLevel(level string) (hlog.Level, error) {
+	level = strings.ToLower(strings.TrimSpace(level))
+	for lv, name := range levelNames {
+		if name == level {
+			return lv, nil
+		}
+	}
+	return hlog.LevelInfo, fmt.Errorf("unknown log level %q", level)
+}
+
+// SetLevel 运行时修改全局日志级别，用于临时开启 debug 日志排查问题
+func SetLevel(level string) error {
+	lv, err := ParseLevel(level)
+	if err != nil {
+		return err
+	}
+	hlog.SetLevel(lv)
+	currentLevel.Store(int32(lv))
+	return nil
+}
+
+// GetLevel 返回当前日志级别
+func GetLevel() string {
+	return levelNames[hlog.Level(currentLevel.Load())]
+}
+
+// levelPayload 日志级别接口的请求与响应体
+type levelPayload struct {
+	Level string `json:"level"`
+	Error string `json:"error,omitempty"`
+}
+
+// LevelHandler 返回查询与修改日志级别的 HTTP 接口，只应挂载在内部管理端口上
+//
+//	GET  返回 {"level":"info"}
+//	PUT  请求体 {"level":"debug"}，也可以使用 ?level=debug，返回修改后的级别
+func LevelHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		enc := json.NewEncoder(w)
+
+		switch r.Method {
+		case http.MethodGet:
+		case http.MethodPut, http.MethodPost:
+			req := levelPayload{Level: r.URL.Query().Get("level")}
+			if req.Level == "" {
+				if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+					w.WriteHeader(http.StatusBadRequest)
+					_ = enc.Encode(levelPayload{Level: GetLevel(), Error: "invalid request body: " + err.Error()})
+					return
+				}
+			}
+			if err := SetLevel(req.Level); err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				_ = enc.Encode(levelPayload{Level: GetLevel(), Error: err.Error()})
+				return
+			}
+			hlog.Warnf("[Logger] log level changed to %s by %s", GetLevel(), r.RemoteAddr)
+		default:
+			w.Header().Set("Allow", "GET, PUT")
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			_ = enc.Encode(levelPayload{Level: GetLevel(), Error: "method not allowed"})
+			return
+		}
+		_ = enc.Encode(levelPayload{Level: GetLevel()})
+	})
+}
@@ -98,6 +98,7 @@ func Init(level string, outputPaths []string) error {
 	// 使用 hertz-contrib/logger/zap 创建 logger
 	// 参考示例代码，添加 caller skip 以正确显示调用位置
 	hertzLogger := hertzzap.NewLogger(
+		contextKeysOption(),
 		hertzzap.WithZapOptions(
 			zap.AddCaller(),
 			zap.AddCallerSkip(3),
@@ -105,6 +106,7 @@ func Init(level string, outputPaths []string) error {
 		),
 	)
 	hertzLogger.SetLevel(hlogLevel)
+	currentLevel.Store(int32(hlogLevel))
 
 	// 使用 hlog 设置 zap logger
 	hlog.SetLogger(hertzLogger)
@@ -121,6 +123,7 @@ func Init(level string, outputPaths []string) error {
 // InitWithZap 使用自定义的 zap logger 初始化
 func InitWithZap(zapLogger *zap.Logger) {
 	hertzLogger := hertzzap.NewLogger(
+		contextKeysOption(),
 		hertzzap.WithZapOptions(
 			zap.AddCaller(),
 			zap.AddCallerSkip(3),
@@ -128,6 +131,7 @@ func InitWithZap(zapLogger *zap.Logger) {
 		),
 	)
 	hertzLogger.SetLevel(hlog.LevelDebug)
+	currentLevel.Store(int32(hlog.LevelDebug))
 	hlog.SetLogger(hertzLogger)
 	defaultLogger = &Logger{
 		zapLogger: zapLogger,
@@ -156,6 +160,7 @@ func InitWithOptions(level string, output io.Writer) error {
 	// 使用 hertz-contrib/logger/zap 创建 logger
 	// 参考示例代码，添加 caller skip 以正确显示调用位置
 	hertzLogger := hertzzap.NewLogger(
+		contextKeysOption(),
 		hertzzap.WithZapOptions(
 			zap.AddCaller(),
 			zap.AddCallerSkip(3),
@@ -163,6 +168,7 @@ func InitWithOptions(level string, output io.Writer) error {
 		),
 	)
 	hertzLogger.SetLevel(hlogLevel)
+	currentLevel.Store(int32(hlogLevel))
 	hertzLogger.SetOutput(output)
 
 	// 使用 hlog 设置 zap logger
@@ -246,6 +252,7 @@ func InitWithRotate(level string, config *RotateConfig) error {
 	// 使用 hertz-contrib/logger/zap 创建 logger
 	// 参考示例代码，添加 caller skip 以正确显示调用位置
 	hertzLogger := hertzzap.NewLogger(
+		contextKeysOption(),
 		hertzzap.WithZapOptions(
 			zap.AddCaller(),
 			zap.AddCallerSkip(3),
@@ -253,6 +260,7 @@ func InitWithRotate(level string, config *RotateConfig) error {
 		),
 	)
 	hertzLogger.SetLevel(hlogLevel)
+	currentLevel.Store(int32(hlogLevel))
 	hertzLogger.SetOutput(output)
 
 	// 使用 hlog 设置 zap logger
@@ -272,6 +280,7 @@ func NewLogger() *Logger {
 	if defaultLogger == nil {
 		// 如果没有初始化，使用默认配置
 		hertzLogger := hertzzap.NewLogger(
+			contextKeysOption(),
 			hertzzap.WithZapOptions(
 				zap.AddCaller(),
 				zap.AddCallerSkip(3),
@@ -2,12 +2,173 @@ package logger
 
 import (
 	"context"
+	"database/sql"
+	"errors"
+	"slices"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/cloudwego/hertz/pkg/common/hlog"
+	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
 )
 
+// ErrExplainSkipped 同时执行的 EXPLAIN 过多、语句不是 SELECT 或没有取得绑定参数，未执行 EXPLAIN
+var ErrExplainSkipped = errors.New("explain skipped")
+
+// explainTimeout 单次 EXPLAIN 的超时时间
+const explainTimeout = 5 * time.Second
+
+// slowQueueSize 等待处理的慢查询上限，超出时丢弃
+const slowQueueSize = 1024
+
+// slowQueue 慢查询处理队列，由一个后台协程依次执行 EXPLAIN 并交给 SlowQuerySink，
+// 慢查询集中出现时不会为每条慢查询启动协程
+var slowQueue = sync.OnceValue(func() chan func() {
+	ch := make(chan func(), slowQueueSize)
+	go func() {
+		for f := range ch {
+			f()
+		}
+	}()
+	return ch
+})
+
+// SlowQuery 慢查询记录
+type SlowQuery struct {
+	// Begin 开始执行时间
+	Begin time.Time
+	// Elapsed 执行耗时
+	Elapsed time.Duration
+	// SQL 已插值的 SQL 语句
+	SQL string
+	// Rows 影响或返回的行数
+	Rows int64
+	// Err 执行错误
+	Err error
+	// Explain EXPLAIN 结果，未配置 Explainer 时为空
+	Explain []map[string]interface{}
+	// ExplainErr 执行 EXPLAIN 的错误
+	ExplainErr error
+
+	// query、vars 未插值的语句与参数，用于执行 EXPLAIN
+	query string
+	vars  []interface{}
+}
+
+// SlowQuerySink 慢查询接收器，用于将慢查询汇总到看板等外部系统
+// HandleSlowQuery 由后台协程依次调用，不会阻塞业务请求，处理过慢时排队的慢查询会被丢弃
+type SlowQuerySink interface {
+	HandleSlowQuery(ctx context.Context, q *SlowQuery)
+}
+
+// SlowQuerySinkFunc 函数形式的 SlowQuerySink
+type SlowQuerySinkFunc func(ctx context.Context, q *SlowQuery)
+
+// HandleSlowQuery 实现 SlowQuerySink
+func (f SlowQuerySinkFunc) HandleSlowQuery(ctx context.Context, q *SlowQuery) {
+	f(ctx, q)
+}
+
+// ChanSink 返回将慢查询写入 ch 的 SlowQuerySink，ch 已满时丢弃
+func ChanSink(ch chan<- *SlowQuery) SlowQuerySink {
+	return SlowQuerySinkFunc(func(ctx context.Context, q *SlowQuery) {
+		select {
+		case ch <- q:
+		default:
+			hlog.CtxWarnf(ctx, "[GORM] slow query sink is full, dropped: %s", q.SQL)
+		}
+	})
+}
+
+// Explainer 对慢查询执行 EXPLAIN，sql 为未插值的语句，vars 为绑定参数
+type Explainer func(ctx context.Context, sql string, vars ...interface{}) ([]map[string]interface{}, error)
+
+// statementKey 查询执行期间在 context 中保存 *gorm.Statement 的键
+type statementKey struct{}
+
+// GormExplainer 返回使用 db 执行 EXPLAIN 的 Explainer，只处理 SELECT 语句
+// EXPLAIN 通过 database/sql 以绑定参数执行，不拼接插值后的 SQL，也不会被记录为慢查询；
+// 同时最多执行 maxConcurrent 个 EXPLAIN，超出时跳过，避免慢查询集中出现时进一步压垮数据库
+// 会在 db 上注册查询回调，使 GormLogger 能取得未插值的语句与参数
+func GormExplainer(db *gorm.DB, maxConcurrent int) Explainer {
+	if maxConcurrent <= 0 {
+		maxConcurrent = 1
+	}
+	sem := make(chan struct{}, maxConcurrent)
+	prefix := "EXPLAIN "
+	if db.Dialector.Name() == "sqlite" {
+		prefix = "EXPLAIN QUERY PLAN "
+	}
+	withStatement := func(db *gorm.DB) {
+		if db.Statement.Context != nil && db.Statement.Context.Value(statementKey{}) != db.Statement {
+			db.Statement.Context = context.WithValue(db.Statement.Context, statementKey{}, db.Statement)
+		}
+	}
+	if err := db.Callback().Query().Before("gorm:query").Register("logs:explain_statement", withStatement); err != nil {
+		hlog.Warnf("[GORM] register explain callback failed: %v", err)
+	}
+	if err := db.Callback().Row().Before("gorm:row").Register("logs:explain_statement", withStatement); err != nil {
+		hlog.Warnf("[GORM] register explain callback failed: %v", err)
+	}
+
+	return func(ctx context.Context, sql string, vars ...interface{}) ([]map[string]interface{}, error) {
+		trimmed := strings.TrimSpace(sql)
+		if len(trimmed) < 6 || !strings.EqualFold(trimmed[:6], "select") {
+			return nil, ErrExplainSkipped
+		}
+		select {
+		case sem <- struct{}{}:
+			defer func() { <-sem }()
+		default:
+			return nil, ErrExplainSkipped
+		}
+
+		sqlDB, err := db.DB()
+		if err != nil {
+			return nil, err
+		}
+		rows, err := sqlDB.QueryContext(ctx, prefix+trimmed, vars...)
+		if err != nil {
+			return nil, err
+		}
+		defer rows.Close()
+		return scanMaps(rows)
+	}
+}
+
+// scanMaps 将结果集逐行读取为列名到值的映射，[]byte 转换为 string 便于序列化
+func scanMaps(rows *sql.Rows) ([]map[string]interface{}, error) {
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+
+	var result []map[string]interface{}
+	for rows.Next() {
+		values := make([]interface{}, len(columns))
+		ptrs := make([]interface{}, len(columns))
+		for i := range values {
+			ptrs[i] = &values[i]
+		}
+		if err := rows.Scan(ptrs...); err != nil {
+			return nil, err
+		}
+
+		row := make(map[string]interface{}, len(columns))
+		for i, column := range columns {
+			if b, ok := values[i].([]byte); ok {
+				row[column] = string(b)
+			} else {
+				row[column] = values[i]
+			}
+		}
+		result = append(result, row)
+	}
+	return result, rows.Err()
+}
+
 // GormLogger GORM SQL 日志记录器，使用 hlog 记录
 type GormLogger struct {
 	// LogLevel 日志级别
@@ -16,6 +177,10 @@ type GormLogger struct {
 	SlowThreshold time.Duration
 	// IgnoreRecordNotFoundError 是否忽略记录未找到错误，默认 true
 	IgnoreRecordNotFoundError bool
+	// SlowQuerySink 慢查询接收器，不受 LogLevel 影响
+	SlowQuerySink SlowQuerySink
+	// Explain 慢查询的 EXPLAIN 执行器，配置 SlowQuerySink 时才会使用
+	Explain Explainer
 }
 
 // NewGormLogger 创建新的 GORM logger
@@ -74,18 +239,29 @@ func (l *GormLogger) Error(ctx context.Context, msg string, data ...interface{})
 
 // Trace 记录 SQL 执行日志
 func (l *GormLogger) Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
+	elapsed := time.Since(begin)
+	slow := elapsed > l.SlowThreshold && l.SlowThreshold != 0
+	if slow && l.SlowQuerySink != nil {
+		sql, rows := fc()
+		q := &SlowQuery{Begin: begin, Elapsed: elapsed, SQL: sql, Rows: rows, Err: err}
+		// 语句与参数在 Trace 返回后会被重置，因此在这里复制
+		if stmt, ok := ctx.Value(statementKey{}).(*gorm.Statement); ok {
+			q.query, q.vars = stmt.SQL.String(), slices.Clone(stmt.Vars)
+		}
+		l.sinkSlowQuery(ctx, q)
+	}
+
 	if l.LogLevel <= logger.Silent {
 		return
 	}
 
-	elapsed := time.Since(begin)
 	sql, rows := fc()
 
 	switch {
 	case err != nil && l.LogLevel >= logger.Error && (!l.IgnoreRecordNotFoundError || err != logger.ErrRecordNotFound):
 		// 记录错误日志
 		hlog.CtxErrorf(ctx, "[GORM] SQL: %s | Rows: %d | Error: %v | Elapsed: %v", sql, rows, err, elapsed)
-	case elapsed > l.SlowThreshold && l.SlowThreshold != 0 && l.LogLevel >= logger.Warn:
+	case slow && l.LogLevel >= logger.Warn:
 		// 记录慢查询日志
 		hlog.CtxWarnf(ctx, "[GORM] Slow SQL: %s | Rows: %d | Elapsed: %v", sql, rows, elapsed)
 	case l.LogLevel >= logger.Info:
@@ -94,6 +270,31 @@ func (l *GormLogger) Trace(ctx context.Context, begin time.Time, fc func() (stri
 	}
 }
 
+// sinkSlowQuery 将慢查询放入 slowQueue，由后台协程执行 EXPLAIN 并交给 SlowQuerySink，队列已满时丢弃
+// 请求结束后 ctx 可能被取消，因此只保留其中的值
+// 没有取得未插值的语句时（如 Explain 不是由 GormExplainer 创建的 db 执行的查询）不执行 EXPLAIN
+func (l *GormLogger) sinkSlowQuery(ctx context.Context, q *SlowQuery) {
+	ctx = context.WithoutCancel(ctx)
+	explain, sink := l.Explain, l.SlowQuerySink
+	task := func() {
+		if explain != nil {
+			if q.query == "" {
+				q.ExplainErr = ErrExplainSkipped
+			} else {
+				ectx, cancel := context.WithTimeout(ctx, explainTimeout)
+				q.Explain, q.ExplainErr = explain(ectx, q.query, q.vars...)
+				cancel()
+			}
+		}
+		sink.HandleSlowQuery(ctx, q)
+	}
+	select {
+	case slowQueue() <- task:
+	default:
+		hlog.CtxWarnf(ctx, "[GORM] slow query queue is full, dropped: %s", q.SQL)
+	}
+}
+
 // DefaultGormLogger 返回默认的 GORM logger（Info 级别）
 func DefaultGormLogger() *GormLogger {
 	return NewGormLogger(logger.Info, 200*time.Millisecond)
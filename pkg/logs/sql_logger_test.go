@@ -0,0 +1,113 @@
+package logger
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+type slowUser struct {
+	ID   int64
+	Name string
+}
+
+// newSlowDB 创建所有查询都记为慢查询的 sqlite 数据库
+func newSlowDB(t *testing.T) (*gorm.DB, *GormLogger, chan *SlowQuery) {
+	t.Helper()
+	ch := make(chan *SlowQuery, 16)
+	l := NewGormLogger(gormlogger.Silent, time.Nanosecond)
+	l.SlowQuerySink = ChanSink(ch)
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{Logger: l})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 内存数据库每个连接相互独立
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB.SetMaxOpenConns(1)
+	if err := db.AutoMigrate(&slowUser{}); err != nil {
+		t.Fatal(err)
+	}
+	return db, l, ch
+}
+
+// receive 读取下一条 SQL 以 prefix 开头的慢查询
+func receive(t *testing.T, ch chan *SlowQuery, prefix string) *SlowQuery {
+	t.Helper()
+	timeout := time.After(3 * time.Second)
+	for {
+		select {
+		case q := <-ch:
+			if strings.HasPrefix(q.SQL, prefix) {
+				return q
+			}
+		case <-timeout:
+			t.Fatalf("slow query %q not received", prefix)
+		}
+	}
+}
+
+func TestSlowQueryExplain(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("以绑定参数执行 EXPLAIN", func(t *testing.T) {
+		db, l, ch := newSlowDB(t)
+		explain := GormExplainer(db, 1)
+		var gotSQL string
+		var gotVars []interface{}
+		l.Explain = func(ctx context.Context, sql string, vars ...interface{}) ([]map[string]interface{}, error) {
+			gotSQL, gotVars = sql, vars
+			return explain(ctx, sql, vars...)
+		}
+
+		var users []slowUser
+		if err := db.WithContext(ctx).Where("name = ?", "o'brien").Find(&users).Error; err != nil {
+			t.Fatal(err)
+		}
+		q := receive(t, ch, "SELECT * FROM `slow_users`")
+		if q.ExplainErr != nil || len(q.Explain) == 0 {
+			t.Fatalf("explain = %v, %v", q.Explain, q.ExplainErr)
+		}
+		if !strings.Contains(q.SQL, "o'brien") {
+			t.Fatalf("slow query sql = %s, want interpolated", q.SQL)
+		}
+		if strings.Contains(gotSQL, "brien") || len(gotVars) != 1 || gotVars[0] != "o'brien" {
+			t.Fatalf("explain sql = %s, vars = %v, want bound parameters", gotSQL, gotVars)
+		}
+	})
+
+	t.Run("跳过非 SELECT 语句", func(t *testing.T) {
+		db, l, ch := newSlowDB(t)
+		l.Explain = GormExplainer(db, 1)
+		if err := db.WithContext(ctx).Create(&slowUser{Name: "alice"}).Error; err != nil {
+			t.Fatal(err)
+		}
+		if q := receive(t, ch, "INSERT"); !errors.Is(q.ExplainErr, ErrExplainSkipped) || q.Explain != nil {
+			t.Fatalf("explain = %v, %v, want ErrExplainSkipped", q.Explain, q.ExplainErr)
+		}
+	})
+
+	t.Run("没有取得绑定参数时不执行 EXPLAIN", func(t *testing.T) {
+		db, l, ch := newSlowDB(t)
+		called := false
+		l.Explain = func(context.Context, string, ...interface{}) ([]map[string]interface{}, error) {
+			called = true
+			return nil, nil
+		}
+		var users []slowUser
+		if err := db.WithContext(ctx).Find(&users).Error; err != nil {
+			t.Fatal(err)
+		}
+		if q := receive(t, ch, "SELECT * FROM `slow_users`"); called || !errors.Is(q.ExplainErr, ErrExplainSkipped) {
+			t.Fatalf("explain called = %v, err = %v", called, q.ExplainErr)
+		}
+	})
+}
@@ -0,0 +1,209 @@
+// Package retry 通用的重试与指数退避，供 redis、对象存储、RPC、HTTP 等客户端装饰器共用
+//
+//	err := retry.Do(ctx, func(ctx context.Context) error {
+//		return client.Call(ctx, req)
+//	}, retry.WithMaxAttempts(5), retry.WithMaxElapsed(10*time.Second))
+//
+// 默认最多执行 3 次，按 100ms 起、5s 封顶的指数退避（带抖动）等待，
+// 错误是否重试由 IsRetryable 决定，可通过 WithRetryIf 自定义
+package retry
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand"
+	"net"
+	"syscall"
+	"time"
+
+	"github.com/ZampoRen/go-server-comon/pkg/errorx"
+)
+
+// Option 重试选项
+type Option func(o *option)
+
+type option struct {
+	maxAttempts    int
+	maxElapsed     time.Duration
+	minBackoff     time.Duration
+	maxBackoff     time.Duration
+	attemptTimeout time.Duration
+	retryIf        func(err error) bool
+	onRetry        func(attempt int, err error, wait time.Duration)
+}
+
+// WithMaxAttempts 设置最大执行次数（含首次执行），默认 3，为 1 时不重试
+func WithMaxAttempts(n int) Option {
+	return func(o *option) {
+		if n > 0 {
+			o.maxAttempts = n
+		}
+	}
+}
+
+// WithMaxElapsed 设置从首次执行开始允许重试的最长时间，等待后会超过该时间时不再重试，默认不限制
+func WithMaxElapsed(d time.Duration) Option {
+	return func(o *option) {
+		o.maxElapsed = d
+	}
+}
+
+// WithBackoff 设置指数退避的初始间隔与最大间隔，默认 100ms 与 5s
+func WithBackoff(min, max time.Duration) Option {
+	return func(o *option) {
+		if min > 0 {
+			o.minBackoff = min
+		}
+		if max > 0 {
+			o.maxBackoff = max
+		}
+	}
+}
+
+// WithAttemptTimeout 设置单次执行的超时时间（每次重试单独计时），为 0 时不额外限制
+func WithAttemptTimeout(d time.Duration) Option {
+	return func(o *option) {
+		o.attemptTimeout = d
+	}
+}
+
+// WithRetryIf 自定义可重试错误的判断，默认为 IsRetryable
+func WithRetryIf(fn func(err error) bool) Option {
+	return func(o *option) {
+		if fn != nil {
+			o.retryIf = fn
+		}
+	}
+}
+
+// WithOnRetry 设置每次重试前的回调，attempt 为即将进行的重试序号（从 1 开始），用于日志与指标
+func WithOnRetry(fn func(attempt int, err error, wait time.Duration)) Option {
+	return func(o *option) {
+		o.onRetry = fn
+	}
+}
+
+// permanentError 不再重试的错误
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string {
+	return e.err.Error()
+}
+
+func (e *permanentError) Unwrap() error {
+	return e.err
+}
+
+// Permanent 标记 err 不可重试，Do 立即返回 err 本身
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
+
+// IsRetryable 默认的重试判断，只重试能确定是暂时性的错误：
+//   - Permanent 标记的错误与调用方 ctx 的取消不重试
+//   - errorx 错误按错误码注册时的 code.WithRetryable 决定
+//   - 超时（含 WithAttemptTimeout 的单次超时）、连接被拒绝或重置、响应意外中断、临时的 DNS 错误可以重试
+//
+// 其它错误（如 JSON 解析错误、参数校验错误）重试也不会成功，默认不重试，需要时通过 WithRetryIf 自定义
+func IsRetryable(err error) bool {
+	var pe *permanentError
+	if err == nil || errors.As(err, &pe) || errors.Is(err, context.Canceled) {
+		return false
+	}
+	var se errorx.StatusError
+	if errors.As(err, &se) {
+		return errorx.IsRetryable(err)
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, syscall.EPIPE) {
+		return true
+	}
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return dnsErr.IsTimeout || dnsErr.IsTemporary
+	}
+	// *url.Error 等包装类型也实现了 net.Error，只按 Timeout 判断，连接错误以 *net.OpError 为准
+	var opErr *net.OpError
+	var netErr net.Error
+	return errors.As(err, &opErr) || (errors.As(err, &netErr) && netErr.Timeout())
+}
+
+// Backoff 返回第 attempt 次重试（从 0 开始）前的等待时间：min << attempt 并以 max 封顶，
+// 在 [d/2, d] 内随机抖动，避免大量客户端同时重试
+func Backoff(min, max time.Duration, attempt int) time.Duration {
+	d := min << attempt
+	if d <= 0 || d > max {
+		d = max
+	}
+	return d/2 + time.Duration(rand.Int63n(int64(d/2)+1))
+}
+
+// Do 执行 fn，失败且错误可重试时按指数退避重试，直到成功、达到次数或时间上限、或 ctx 结束，
+// 返回最后一次执行的错误；Permanent 标记的错误会被解开后返回
+func Do(ctx context.Context, fn func(ctx context.Context) error, opts ...Option) error {
+	o := &option{
+		maxAttempts: 3,
+		minBackoff:  100 * time.Millisecond,
+		maxBackoff:  5 * time.Second,
+		retryIf:     IsRetryable,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	start := time.Now()
+	for attempt := 1; ; attempt++ {
+		err := o.attempt(ctx, fn)
+		if err == nil {
+			return nil
+		}
+		var pe *permanentError
+		if errors.As(err, &pe) {
+			return pe.err
+		}
+		if attempt >= o.maxAttempts || ctx.Err() != nil || !o.retryIf(err) {
+			return err
+		}
+
+		wait := Backoff(o.minBackoff, o.maxBackoff, attempt-1)
+		if o.maxElapsed > 0 && time.Since(start)+wait > o.maxElapsed {
+			return err
+		}
+		if o.onRetry != nil {
+			o.onRetry(attempt, err, wait)
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+	}
+}
+
+// DoValue 与 Do 相同，返回最后一次执行的结果
+func DoValue[T any](ctx context.Context, fn func(ctx context.Context) (T, error), opts ...Option) (T, error) {
+	var v T
+	err := Do(ctx, func(ctx context.Context) (err error) {
+		v, err = fn(ctx)
+		return err
+	}, opts...)
+	return v, err
+}
+
+func (o *option) attempt(ctx context.Context, fn func(ctx context.Context) error) error {
+	if o.attemptTimeout <= 0 {
+		return fn(ctx)
+	}
+	ctx, cancel := context.WithTimeout(ctx, o.attemptTimeout)
+	defer cancel()
+	return fn(ctx)
+}
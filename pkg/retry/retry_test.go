@@ -0,0 +1,164 @@
+package retry
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/ZampoRen/go-server-comon/pkg/errorx"
+	"github.com/ZampoRen/go-server-comon/pkg/errorx/code"
+)
+
+const (
+	codeBusy     int32 = 990001
+	codeNotFound int32 = 990002
+)
+
+func init() {
+	code.Register(codeBusy, "busy", code.WithRetryable(true))
+	code.Register(codeNotFound, "not found")
+}
+
+func fast() Option {
+	return WithBackoff(time.Millisecond, time.Millisecond)
+}
+
+// errTemp 连接被拒绝，默认可以重试
+var errTemp error = &net.OpError{Op: "dial", Net: "tcp", Err: syscall.ECONNREFUSED}
+
+func TestDo(t *testing.T) {
+	tests := []struct {
+		name  string
+		err   error
+		opts  []Option
+		calls int
+	}{
+		{name: "成功不重试", err: nil, calls: 1},
+		{name: "网络错误重试到上限", err: errTemp, calls: 3},
+		{name: "未知错误不重试", err: errors.New("unknown"), calls: 1},
+		{name: "自定义次数", err: errTemp, opts: []Option{WithMaxAttempts(5)}, calls: 5},
+		{name: "可重试的 errorx 错误", err: errorx.New(codeBusy), calls: 3},
+		{name: "不可重试的 errorx 错误", err: errorx.New(codeNotFound), calls: 1},
+		{name: "包装后的 errorx 错误", err: errorx.WrapByCode(errTemp, codeNotFound), calls: 1},
+		{name: "Permanent", err: Permanent(errTemp), calls: 1},
+		{name: "自定义判断", err: errTemp, opts: []Option{WithRetryIf(func(error) bool { return false })}, calls: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			err := Do(context.Background(), func(ctx context.Context) error {
+				calls++
+				return tt.err
+			}, append([]Option{fast()}, tt.opts...)...)
+			if calls != tt.calls {
+				t.Fatalf("calls = %d, want %d", calls, tt.calls)
+			}
+			if tt.err != nil && !errors.Is(err, tt.err) && !errors.Is(tt.err, err) {
+				t.Fatalf("err = %v, want %v", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestDoPermanentUnwrap(t *testing.T) {
+	errBad := errors.New("bad")
+	err := Do(context.Background(), func(ctx context.Context) error {
+		return Permanent(errBad)
+	})
+	if err != errBad {
+		t.Fatalf("err = %v, want %v", err, errBad)
+	}
+}
+
+func TestDoValue(t *testing.T) {
+	calls := 0
+	v, err := DoValue(context.Background(), func(ctx context.Context) (int, error) {
+		calls++
+		if calls < 2 {
+			return 0, errTemp
+		}
+		return 42, nil
+	}, fast())
+	if err != nil || v != 42 || calls != 2 {
+		t.Fatalf("DoValue = %d, %v after %d calls", v, err, calls)
+	}
+}
+
+func TestMaxElapsed(t *testing.T) {
+	calls := 0
+	start := time.Now()
+	_ = Do(context.Background(), func(ctx context.Context) error {
+		calls++
+		return errTemp
+	}, WithMaxAttempts(100), WithBackoff(20*time.Millisecond, 20*time.Millisecond), WithMaxElapsed(50*time.Millisecond))
+	if calls >= 5 {
+		t.Fatalf("calls = %d, max elapsed not honored", calls)
+	}
+	if d := time.Since(start); d > 100*time.Millisecond {
+		t.Fatalf("elapsed = %s", d)
+	}
+}
+
+func TestContextCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	calls := 0
+	err := Do(ctx, func(ctx context.Context) error {
+		calls++
+		cancel()
+		return ctx.Err()
+	}, WithMaxAttempts(5), fast())
+	if calls != 1 || !errors.Is(err, context.Canceled) {
+		t.Fatalf("calls = %d, err = %v", calls, err)
+	}
+}
+
+func TestAttemptTimeoutAndOnRetry(t *testing.T) {
+	var retries []int
+	err := Do(context.Background(), func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}, WithAttemptTimeout(5*time.Millisecond), fast(), WithOnRetry(func(attempt int, err error, wait time.Duration) {
+		retries = append(retries, attempt)
+	}))
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("err = %v, want DeadlineExceeded", err)
+	}
+	if len(retries) != 2 || retries[0] != 1 || retries[1] != 2 {
+		t.Fatalf("retries = %v", retries)
+	}
+}
+
+func TestIsRetryable(t *testing.T) {
+	tests := map[string]struct {
+		err  error
+		want bool
+	}{
+		"连接被拒绝":     {errTemp, true},
+		"连接被重置":     {syscall.ECONNRESET, true},
+		"单次执行超时":    {context.DeadlineExceeded, true},
+		"临时 DNS 错误": {&net.DNSError{Err: "server misbehaving", IsTemporary: true}, true},
+		"域名不存在":     {&net.DNSError{Err: "no such host", IsNotFound: true}, false},
+		"JSON 解析错误": {json.Unmarshal([]byte("x"), &struct{}{}), false},
+		"未知错误":      {errors.New("invalid argument"), false},
+		"取消":        {context.Canceled, false},
+	}
+	for name, tt := range tests {
+		if got := IsRetryable(tt.err); got != tt.want {
+			t.Errorf("%s: IsRetryable(%v) = %v, want %v", name, tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	for attempt := 0; attempt < 10; attempt++ {
+		d := Backoff(100*time.Millisecond, time.Second, attempt)
+		want := min(100*time.Millisecond<<attempt, time.Second)
+		if d < want/2 || d > want {
+			t.Fatalf("Backoff(%d) = %s, want in [%s, %s]", attempt, d, want/2, want)
+		}
+	}
+}
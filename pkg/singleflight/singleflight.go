@@ -0,0 +1,125 @@
+// Package singleflight 合并相同 key 的并发请求，只执行一次 fn 并将结果共享给所有调用方，
+// 用于保护数据库等昂贵的下游免受缓存击穿时的并发查询
+//
+//	var users = singleflight.New[*User](singleflight.WithTimeout(3 * time.Second))
+//
+//	func (r *repo) Get(ctx context.Context, id int64) (*User, error) {
+//		return users.Do(ctx, strconv.FormatInt(id, 10), func(ctx context.Context) (*User, error) {
+//			return r.query(ctx, id)
+//		})
+//	}
+//
+// 与 golang.org/x/sync/singleflight 的区别：
+//   - 泛型结果，无需类型断言
+//   - fn 在独立的协程中以不随调用方取消的 ctx 执行，某个调用方取消或超时只影响它自己，不会让其它调用方一起失败
+//   - fn 的 panic 转换为错误返回给所有调用方，而不是让每个调用方都 panic
+//   - 设置 WithTimeout 时，fn 超时后立即遗忘 key，后续调用重新执行而不是继续等待卡住的 fn
+package singleflight
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/ZampoRen/go-server-comon/pkg/errorx"
+)
+
+// PanicError fn panic 时返回给调用方的错误，即 errorx.PanicError
+type PanicError = errorx.PanicError
+
+// Option 选项
+type Option func(o *option)
+
+type option struct {
+	timeout time.Duration
+}
+
+// WithTimeout 设置 fn 的执行时限，为 0 时不限制（默认）
+func WithTimeout(d time.Duration) Option {
+	return func(o *option) {
+		o.timeout = d
+	}
+}
+
+// call 一次进行中的执行
+type call[T any] struct {
+	done chan struct{}
+	val  T
+	err  error
+}
+
+// Group 请求合并组，零值可用，可并发使用
+type Group[T any] struct {
+	o option
+
+	mu    sync.Mutex
+	calls map[string]*call[T]
+}
+
+// New 创建请求合并组
+func New[T any](opts ...Option) *Group[T] {
+	g := &Group[T]{}
+	for _, opt := range opts {
+		opt(&g.o)
+	}
+	return g
+}
+
+// Do 执行 fn 并返回结果，相同 key 已有进行中的执行时等待并共享其结果
+// fn 完成后立即遗忘 key，失败的结果不会被后续调用复用；ctx 结束时返回 ctx 的错误，不影响进行中的 fn
+func (g *Group[T]) Do(ctx context.Context, key string, fn func(ctx context.Context) (T, error)) (T, error) {
+	g.mu.Lock()
+	if g.calls == nil {
+		g.calls = make(map[string]*call[T])
+	}
+	c, ok := g.calls[key]
+	if !ok {
+		c = &call[T]{done: make(chan struct{})}
+		g.calls[key] = c
+		go g.run(context.WithoutCancel(ctx), key, c, fn)
+	}
+	g.mu.Unlock()
+
+	select {
+	case <-c.done:
+		return c.val, c.err
+	case <-ctx.Done():
+		var zero T
+		return zero, ctx.Err()
+	}
+}
+
+func (g *Group[T]) run(ctx context.Context, key string, c *call[T], fn func(ctx context.Context) (T, error)) {
+	if g.o.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, g.o.timeout)
+		defer cancel()
+		timer := time.AfterFunc(g.o.timeout, func() { g.forget(key, c) })
+		defer timer.Stop()
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			c.err = errorx.NewPanicError(r)
+		}
+		g.forget(key, c)
+		close(c.done)
+	}()
+	c.val, c.err = fn(ctx)
+}
+
+// Forget 遗忘 key，之后的调用重新执行 fn，已在等待的调用方仍会得到进行中执行的结果
+func (g *Group[T]) Forget(key string) {
+	g.mu.Lock()
+	delete(g.calls, key)
+	g.mu.Unlock()
+}
+
+// forget 仅当 key 仍对应 c 时遗忘，避免误删之后新发起的执行
+func (g *Group[T]) forget(key string, c *call[T]) {
+	g.mu.Lock()
+	if g.calls[key] == c {
+		delete(g.calls, key)
+	}
+	g.mu.Unlock()
+}
@@ -0,0 +1,122 @@
+package singleflight
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestDo(t *testing.T) {
+	g := New[int]()
+	var calls atomic.Int32
+	release := make(chan struct{})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			v, err := g.Do(context.Background(), "key", func(ctx context.Context) (int, error) {
+				calls.Add(1)
+				<-release
+				return 42, nil
+			})
+			if err != nil || v != 42 {
+				t.Errorf("Do = %d, %v, want 42", v, err)
+			}
+		}()
+	}
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if n := calls.Load(); n != 1 {
+		t.Fatalf("calls = %d, want 1", n)
+	}
+}
+
+func TestForgetOnError(t *testing.T) {
+	g := New[int]()
+	errBoom := errors.New("boom")
+	if _, err := g.Do(context.Background(), "key", func(ctx context.Context) (int, error) {
+		return 0, errBoom
+	}); !errors.Is(err, errBoom) {
+		t.Fatalf("err = %v, want %v", err, errBoom)
+	}
+	v, err := g.Do(context.Background(), "key", func(ctx context.Context) (int, error) {
+		return 1, nil
+	})
+	if err != nil || v != 1 {
+		t.Fatalf("Do after error = %d, %v, want 1", v, err)
+	}
+}
+
+func TestCallerCanceled(t *testing.T) {
+	var g Group[int]
+	release := make(chan struct{})
+	fn := func(ctx context.Context) (int, error) {
+		<-release
+		return 42, ctx.Err()
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		_, err := g.Do(ctx, "key", fn)
+		done <- err
+	}()
+	time.Sleep(10 * time.Millisecond)
+
+	other := make(chan int, 1)
+	go func() {
+		v, _ := g.Do(context.Background(), "key", fn)
+		other <- v
+	}()
+	time.Sleep(10 * time.Millisecond)
+
+	cancel()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Fatalf("canceled caller err = %v", err)
+	}
+	close(release)
+	if v := <-other; v != 42 {
+		t.Fatalf("other caller got %d, want 42", v)
+	}
+}
+
+func TestTimeout(t *testing.T) {
+	g := New[int](WithTimeout(20 * time.Millisecond))
+	stuck := make(chan struct{})
+	defer close(stuck)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	// 模拟不响应 ctx 的 fn，调用方只能等到自己的 ctx 结束
+	if _, err := g.Do(ctx, "key", func(ctx context.Context) (int, error) {
+		<-stuck
+		return 0, nil
+	}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("err = %v, want DeadlineExceeded", err)
+	}
+
+	v, err := g.Do(context.Background(), "key", func(ctx context.Context) (int, error) {
+		return 1, nil
+	})
+	if err != nil || v != 1 {
+		t.Fatalf("Do after timeout = %d, %v, want 1", v, err)
+	}
+}
+
+func TestPanic(t *testing.T) {
+	g := New[int]()
+	_, err := g.Do(context.Background(), "key", func(ctx context.Context) (int, error) {
+		panic("oops")
+	})
+	var pe *PanicError
+	if !errors.As(err, &pe) || pe.Value != "oops" {
+		t.Fatalf("err = %v, want PanicError", err)
+	}
+}
@@ -0,0 +1,52 @@
+package sonic
+
+import "github.com/bytedance/sonic"
+
+// API JSON 编解码实例，由 NewConfig 创建
+type API = sonic.API
+
+// ConfigOptFn NewConfig 选项函数
+type ConfigOptFn func(c *sonic.Config)
+
+// WithSortKeys 编码 map 时按键排序，输出稳定，适用于签名、缓存键等场景
+func WithSortKeys() ConfigOptFn {
+	return func(c *sonic.Config) {
+		c.SortMapKeys = true
+	}
+}
+
+// WithEscapeHTML 编码时转义字符串中的 <、>、&，适用于嵌入 HTML 的场景
+func WithEscapeHTML() ConfigOptFn {
+	return func(c *sonic.Config) {
+		c.EscapeHTML = true
+	}
+}
+
+// WithDisallowUnknownFields 解码时遇到结构体中不存在的字段返回错误，适用于严格校验请求参数
+func WithDisallowUnknownFields() ConfigOptFn {
+	return func(c *sonic.Config) {
+		c.DisallowUnknownFields = true
+	}
+}
+
+// WithCopyString 解码时复制字符串，而不是引用输入缓冲区，避免结果长期持有大的输入缓冲区
+func WithCopyString() ConfigOptFn {
+	return func(c *sonic.Config) {
+		c.CopyString = true
+	}
+}
+
+// NewConfig 创建独立配置的编解码实例，默认与包级函数一致（整数解码为 int64）
+// 返回的实例是并发安全的，应在初始化时创建并复用
+//
+//	strict := sonic.NewConfig(sonic.WithDisallowUnknownFields())
+//	err := strict.Unmarshal(body, &req)
+func NewConfig(opts ...ConfigOptFn) API {
+	c := sonic.Config{
+		UseInt64: true,
+	}
+	for _, opt := range opts {
+		opt(&c)
+	}
+	return c.Froze()
+}
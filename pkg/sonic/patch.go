@@ -0,0 +1,132 @@
+package sonic
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/bytedance/sonic"
+	"github.com/bytedance/sonic/ast"
+)
+
+var (
+	// ErrPathNotFound GetByPath 指定的路径不存在
+	ErrPathNotFound = errors.New("json path not found")
+	// ErrInvalidPath GetByPath 的路径格式错误
+	ErrInvalidPath = errors.New("invalid json path")
+)
+
+// patchConfig 合并时保留数字的原始文本，避免大整数与小数精度丢失；按键排序使输出稳定
+var patchConfig = sonic.Config{
+	UseNumber:   true,
+	SortMapKeys: true,
+}.Froze()
+
+// MergePatch 按 RFC 7386 JSON Merge Patch 将 patch 合并到 original 并返回结果
+// patch 中值为 null 的字段会被删除，对象递归合并，其余值（包括数组）整体替换
+// original 为空时视为 null
+func MergePatch(original, patch []byte) ([]byte, error) {
+	var target any
+	if len(original) > 0 {
+		if err := patchConfig.Unmarshal(original, &target); err != nil {
+			return nil, fmt.Errorf("parse original failed: %w", err)
+		}
+	}
+
+	var p any
+	if err := patchConfig.Unmarshal(patch, &p); err != nil {
+		return nil, fmt.Errorf("parse patch failed: %w", err)
+	}
+
+	return patchConfig.Marshal(mergePatch(target, p))
+}
+
+func mergePatch(target, patch any) any {
+	p, ok := patch.(map[string]any)
+	if !ok {
+		return patch
+	}
+	t, ok := target.(map[string]any)
+	if !ok {
+		t = make(map[string]any, len(p))
+	}
+
+	for k, v := range p {
+		if v == nil {
+			delete(t, k)
+			continue
+		}
+		t[k] = mergePatch(t[k], v)
+	}
+	return t
+}
+
+// GetByPath 返回 data 中 path 位置的原始 JSON，path 格式如 a.b[0].c、[1].name
+// 路径不存在时返回 ErrPathNotFound
+func GetByPath(data []byte, path string) ([]byte, error) {
+	keys, err := parsePath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	node, err := sonic.GetCopyFromString(string(data), keys...)
+	if errors.Is(err, ast.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %s", ErrPathNotFound, path)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	raw, err := node.Raw()
+	if err != nil {
+		return nil, err
+	}
+	return []byte(raw), nil
+}
+
+// GetByPathAs 解析 data 中 path 位置的值为 T 类型，见 GetByPath
+func GetByPathAs[T any](data []byte, path string) (T, error) {
+	var v T
+	raw, err := GetByPath(data, path)
+	if err != nil {
+		return v, err
+	}
+	err = config.Unmarshal(raw, &v)
+	return v, err
+}
+
+// parsePath 将 a.b[0].c 解析为 sonic.Get 使用的路径 ["a", "b", 0, "c"]
+func parsePath(path string) ([]any, error) {
+	var keys []any
+	for _, part := range strings.Split(path, ".") {
+		name, rest, indexed := strings.Cut(part, "[")
+		if name == "" && !indexed || indexed && rest == "" {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidPath, path)
+		}
+		if name != "" {
+			keys = append(keys, name)
+		}
+
+		for rest != "" {
+			idx, after, ok := strings.Cut(rest, "]")
+			if !ok {
+				return nil, fmt.Errorf("%w: %s", ErrInvalidPath, path)
+			}
+			i, err := strconv.Atoi(idx)
+			if err != nil || i < 0 {
+				return nil, fmt.Errorf("%w: %s", ErrInvalidPath, path)
+			}
+			keys = append(keys, i)
+
+			if after == "" {
+				break
+			}
+			if !strings.HasPrefix(after, "[") {
+				return nil, fmt.Errorf("%w: %s", ErrInvalidPath, path)
+			}
+			rest = after[1:]
+		}
+	}
+	return keys, nil
+}
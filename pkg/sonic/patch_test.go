@@ -0,0 +1,50 @@
+package sonic
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestMergePatch(t *testing.T) {
+	original := `{"a":"b","c":{"d":"e","f":"g"},"id":9007199254740993}`
+	patch := `{"a":"z","c":{"f":null,"n":{"x":1}}}`
+
+	got, err := MergePatch([]byte(original), []byte(patch))
+	if err != nil {
+		t.Fatalf("MergePatch() error = %v", err)
+	}
+	want := `{"a":"z","c":{"d":"e","n":{"x":1}},"id":9007199254740993}`
+	if string(got) != want {
+		t.Errorf("MergePatch() = %s, want %s", got, want)
+	}
+}
+
+func TestGetByPath(t *testing.T) {
+	data := []byte(`{"a":{"b":[{"c":"x"},{"c":"y"}]}}`)
+
+	tests := []struct {
+		name    string
+		path    string
+		want    string
+		wantErr error
+	}{
+		{name: "对象与数组", path: "a.b[1].c", want: `"y"`},
+		{name: "子树", path: "a.b[0]", want: `{"c":"x"}`},
+		{name: "不存在", path: "a.b[2].c", wantErr: ErrPathNotFound},
+		{name: "格式错误", path: "a..b", wantErr: ErrInvalidPath},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := GetByPath(data, tt.path)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("GetByPath() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil || string(got) != tt.want {
+				t.Errorf("GetByPath() = %s, %v, want %s", got, err, tt.want)
+			}
+		})
+	}
+}
@@ -1,6 +1,10 @@
 package sonic
 
-import "github.com/bytedance/sonic"
+import (
+	"io"
+
+	"github.com/bytedance/sonic"
+)
 
 var config = sonic.Config{
 	UseInt64: true,
@@ -34,3 +38,29 @@ func Unmarshal(buf []byte, val interface{}) error {
 func UnmarshalString(buf string, val interface{}) error {
 	return config.UnmarshalFromString(buf, val)
 }
+
+// UnmarshalAs 解析 JSON 编码的数据并返回 T 类型的结果，避免调用方声明临时变量
+//
+//	user, err := sonic.UnmarshalAs[User](data)
+func UnmarshalAs[T any](buf []byte) (T, error) {
+	var v T
+	err := config.Unmarshal(buf, &v)
+	return v, err
+}
+
+// UnmarshalStringAs 类似于 UnmarshalAs，但 buf 是字符串
+func UnmarshalStringAs[T any](buf string) (T, error) {
+	var v T
+	err := config.UnmarshalFromString(buf, &v)
+	return v, err
+}
+
+// MarshalToWriter 将 v 的 JSON 编码写入 w，末尾带换行符，与 json.Encoder 行为一致
+func MarshalToWriter(w io.Writer, val interface{}) error {
+	return config.NewEncoder(w).Encode(val)
+}
+
+// Valid 判断 data 是否为合法的 JSON
+func Valid(data []byte) bool {
+	return config.Valid(data)
+}
@@ -0,0 +1,180 @@
+// Package taskgroup 限制并发数的任务组，用法与 errgroup 相同，另外支持收集全部错误与 panic 恢复
+//
+//	g, ctx := taskgroup.New(ctx, taskgroup.WithLimit(16))
+//	for _, f := range files {
+//		g.Go(func() error {
+//			url, err := s.GetObjectUrl(ctx, f.Key)
+//			f.URL = url
+//			return err
+//		})
+//	}
+//	err := g.Wait()
+//
+// 默认第一个错误取消 ctx 并由 Wait 返回；WithCollectErrors 时不取消，Wait 返回所有错误的 errors.Join
+package taskgroup
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/ZampoRen/go-server-comon/pkg/errorx"
+)
+
+// PanicError 任务 panic 时转换成的错误，即 errorx.PanicError
+type PanicError = errorx.PanicError
+
+// Option 任务组选项
+type Option func(o *option)
+
+type option struct {
+	limit   int
+	collect bool
+}
+
+// WithLimit 设置同时运行的最大任务数，<= 0 表示不限制，达到上限时 Go 阻塞
+func WithLimit(n int) Option {
+	return func(o *option) {
+		o.limit = n
+	}
+}
+
+// WithCollectErrors 任务失败时不取消 ctx，其余任务继续执行，Wait 返回全部错误
+func WithCollectErrors() Option {
+	return func(o *option) {
+		o.collect = true
+	}
+}
+
+// Group 任务组，零值不可用，须通过 New 创建
+type Group struct {
+	ctx    context.Context
+	cancel context.CancelCauseFunc
+	o      *option
+	sem    chan struct{}
+	wg     sync.WaitGroup
+
+	mu      sync.Mutex
+	errs    []error
+	skipped bool // 有任务因 ctx 取消未运行
+}
+
+// New 创建任务组，返回的 ctx 在第一个任务失败（未开启 WithCollectErrors 时）或 Wait 返回后取消
+func New(ctx context.Context, opts ...Option) (*Group, context.Context) {
+	o := &option{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	ctx, cancel := context.WithCancelCause(ctx)
+	g := &Group{ctx: ctx, cancel: cancel, o: o}
+	if o.limit > 0 {
+		g.sem = make(chan struct{}, o.limit)
+	}
+	return g, ctx
+}
+
+// Go 在新的协程中运行 fn，达到并发上限时阻塞直到有任务完成。ctx 已取消时不再运行 fn
+func (g *Group) Go(fn func() error) {
+	if g.sem != nil {
+		select {
+		case g.sem <- struct{}{}:
+		case <-g.ctx.Done():
+			g.skip()
+			return
+		}
+	}
+	if g.ctx.Err() != nil {
+		g.release()
+		g.skip()
+		return
+	}
+	g.start(fn)
+}
+
+// TryGo 未达到并发上限时运行 fn 并返回 true，否则返回 false
+func (g *Group) TryGo(fn func() error) bool {
+	if g.sem != nil {
+		select {
+		case g.sem <- struct{}{}:
+		default:
+			return false
+		}
+	}
+	if g.ctx.Err() != nil {
+		g.release()
+		g.skip()
+		return false
+	}
+	g.start(fn)
+	return true
+}
+
+func (g *Group) start(fn func() error) {
+	g.wg.Go(func() {
+		defer g.release()
+		if err := run(fn); err != nil {
+			g.fail(err)
+		}
+	})
+}
+
+func (g *Group) release() {
+	if g.sem != nil {
+		<-g.sem
+	}
+}
+
+func (g *Group) skip() {
+	g.mu.Lock()
+	g.skipped = true
+	g.mu.Unlock()
+}
+
+func (g *Group) fail(err error) {
+	g.mu.Lock()
+	g.errs = append(g.errs, err)
+	g.mu.Unlock()
+	if !g.o.collect {
+		g.cancel(err)
+	}
+}
+
+// run 运行 fn 并将 panic 转换为 *PanicError
+func run(fn func() error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = errorx.NewPanicError(r)
+		}
+	}()
+	return fn()
+}
+
+// Wait 等待所有任务结束并取消 ctx。默认返回第一个错误，开启 WithCollectErrors 时返回所有错误的 errors.Join，
+// 没有任务失败但父 ctx 已取消导致任务被跳过时返回父 ctx 的错误
+func (g *Group) Wait() error {
+	g.wg.Wait()
+	defer g.cancel(nil)
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	switch {
+	case len(g.errs) == 0:
+		if g.skipped {
+			return context.Cause(g.ctx)
+		}
+		return nil
+	case g.o.collect:
+		return errors.Join(g.errs...)
+	default:
+		return g.errs[0]
+	}
+}
+
+// ForEach 以至多 limit 的并发对 items 的每个元素执行 fn，错误处理同 Group
+func ForEach[T any](ctx context.Context, items []T, limit int, fn func(ctx context.Context, i int, item T) error, opts ...Option) error {
+	g, ctx := New(ctx, append([]Option{WithLimit(limit)}, opts...)...)
+	for i, item := range items {
+		g.Go(func() error { return fn(ctx, i, item) })
+	}
+	return g.Wait()
+}
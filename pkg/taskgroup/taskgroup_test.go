@@ -0,0 +1,118 @@
+package taskgroup
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestLimit(t *testing.T) {
+	var running, peak atomic.Int32
+	err := ForEach(context.Background(), make([]struct{}, 20), 3, func(ctx context.Context, _ int, _ struct{}) error {
+		n := running.Add(1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+		running.Add(-1)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("ForEach: %v", err)
+	}
+	if p := peak.Load(); p > 3 {
+		t.Fatalf("peak concurrency = %d, want <= 3", p)
+	}
+}
+
+func TestFailFast(t *testing.T) {
+	errBoom := errors.New("boom")
+	g, ctx := New(context.Background(), WithLimit(1))
+	var ran atomic.Int32
+	g.Go(func() error { return errBoom })
+	for range 5 {
+		g.Go(func() error {
+			ran.Add(1)
+			return nil
+		})
+	}
+	if err := g.Wait(); !errors.Is(err, errBoom) {
+		t.Fatalf("Wait = %v, want %v", err, errBoom)
+	}
+	if ctx.Err() == nil {
+		t.Fatal("ctx not canceled")
+	}
+	if n := ran.Load(); n != 0 {
+		t.Fatalf("%d tasks ran after failure", n)
+	}
+}
+
+func TestCollectErrors(t *testing.T) {
+	err1, err2 := errors.New("err1"), errors.New("err2")
+	g, ctx := New(context.Background(), WithLimit(2), WithCollectErrors())
+	var ran atomic.Int32
+	g.Go(func() error { return err1 })
+	g.Go(func() error { return err2 })
+	for range 3 {
+		g.Go(func() error {
+			ran.Add(1)
+			return ctx.Err()
+		})
+	}
+	err := g.Wait()
+	if !errors.Is(err, err1) || !errors.Is(err, err2) {
+		t.Fatalf("Wait = %v, want both errors", err)
+	}
+	if n := ran.Load(); n != 3 {
+		t.Fatalf("ran = %d, want 3", n)
+	}
+}
+
+func TestPanic(t *testing.T) {
+	g, _ := New(context.Background())
+	g.Go(func() error { panic("oops") })
+	var pe *PanicError
+	if err := g.Wait(); !errors.As(err, &pe) || pe.Value != "oops" {
+		t.Fatalf("Wait = %v, want PanicError", err)
+	}
+}
+
+func TestParentCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	g, _ := New(ctx, WithLimit(1))
+	ran := false
+	g.Go(func() error {
+		ran = true
+		return nil
+	})
+	if err := g.Wait(); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Wait = %v, want context.Canceled", err)
+	}
+	if ran {
+		t.Fatal("task ran with canceled ctx")
+	}
+}
+
+func TestTryGo(t *testing.T) {
+	g, _ := New(context.Background(), WithLimit(1))
+	release := make(chan struct{})
+	if !g.TryGo(func() error {
+		<-release
+		return nil
+	}) {
+		t.Fatal("first TryGo = false")
+	}
+	if g.TryGo(func() error { return nil }) {
+		t.Fatal("TryGo over limit = true")
+	}
+	close(release)
+	if err := g.Wait(); err != nil {
+		t.Fatalf("Wait: %v", err)
+	}
+}
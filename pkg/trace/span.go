@@ -0,0 +1,33 @@
+package trace
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	otelcodes "go.opentelemetry.io/otel/codes"
+	oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "github.com/ZampoRen/go-server-comon/pkg/trace"
+
+// Start 使用全局 TracerProvider 创建 span，ctx 中有 span 时作为其子 span
+func Start(ctx context.Context, name string, opts ...oteltrace.SpanStartOption) (context.Context, oteltrace.Span) {
+	return otel.Tracer(tracerName).Start(ctx, name, opts...)
+}
+
+// End 结束 span，err 不为 nil 时记录错误并将 span 标记为失败
+func End(span oteltrace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(otelcodes.Error, err.Error())
+	}
+	span.End()
+}
+
+// TraceID 返回 ctx 中 span 的 trace ID，没有时返回空字符串
+func TraceID(ctx context.Context) string {
+	if sc := oteltrace.SpanContextFromContext(ctx); sc.HasTraceID() {
+		return sc.TraceID().String()
+	}
+	return ""
+}
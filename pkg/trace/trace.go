@@ -0,0 +1,269 @@
+// Package trace 初始化 OpenTelemetry 的 TracerProvider 与 MeterProvider，配置 OTLP 导出器、采样器、resource 属性
+// 与 W3C tracecontext 传播，并提供创建 span 的辅助函数
+//
+//	shutdown, err := trace.Init(ctx, trace.WithServiceName("user"))
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	defer shutdown(context.Background()) // 导出剩余的 span 与指标
+//
+//	ctx, span := trace.Start(ctx, "user.Register")
+//	defer func() { trace.End(span, err) }()
+//
+// Init 设置全局 TracerProvider、MeterProvider 与 TextMapPropagator，redis.WithTracing、es.WithTracing、
+// orm/tracing 插件、httpclient 以及 middleware、httpmw 中的追踪拦截器默认使用全局实例，
+// 因此只需在进程启动时调用一次，HTTP 入口的 span 即可经 gRPC 传递到下游的数据库、缓存与搜索调用。
+// 未设置的选项从 OpenTelemetry SDK 的标准环境变量读取，resource 属性还可以通过 OTEL_RESOURCE_ATTRIBUTES 设置，
+// 指标的导出间隔通过 OTEL_METRIC_EXPORT_INTERVAL（毫秒）设置，默认 60s
+package trace
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/cloudwego/hertz/pkg/common/hlog"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetricgrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/propagation"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+
+	"github.com/ZampoRen/go-server-comon/pkg/envkey"
+)
+
+// 环境变量，与 OpenTelemetry SDK 的标准变量名保持一致
+const (
+	// EnvEndpoint OTLP gRPC 接收端地址，如 otel-collector:4317 或 http://otel-collector:4317，未设置时不导出 span 与指标
+	EnvEndpoint = "OTEL_EXPORTER_OTLP_ENDPOINT"
+	// EnvInsecure 为 true 时不使用 TLS 连接接收端，地址以 http:// 开头时同样不使用 TLS
+	EnvInsecure = "OTEL_EXPORTER_OTLP_INSECURE"
+	// EnvServiceName 服务名，写入 resource 的 service.name
+	EnvServiceName = "OTEL_SERVICE_NAME"
+	// EnvSampler 采样器，可选 always_on、always_off、traceidratio、parentbased_always_on、
+	// parentbased_always_off、parentbased_traceidratio，默认 parentbased_traceidratio
+	EnvSampler = "OTEL_TRACES_SAMPLER"
+	// EnvSampleRatio traceidratio 采样器的采样比例，取值 0 到 1，默认 1
+	EnvSampleRatio = "OTEL_TRACES_SAMPLER_ARG"
+	// EnvTracesExporter 为 none 时不导出 span
+	EnvTracesExporter = "OTEL_TRACES_EXPORTER"
+	// EnvMetricsExporter 为 none 时不导出指标
+	EnvMetricsExporter = "OTEL_METRICS_EXPORTER"
+)
+
+// Option 初始化选项，未设置的选项从对应的环境变量读取
+type Option func(o *option)
+
+type option struct {
+	endpoint       string
+	insecure       bool
+	serviceName    string
+	sampler        string
+	sampleRatio    float64
+	exporter       sdktrace.SpanExporter
+	metricReader   sdkmetric.Reader
+	disableTraces  bool
+	disableMetrics bool
+	attrs          []attribute.KeyValue
+}
+
+// WithEndpoint 设置 OTLP gRPC 接收端地址
+func WithEndpoint(endpoint string, insecure bool) Option {
+	return func(o *option) {
+		o.endpoint = endpoint
+		o.insecure = insecure
+	}
+}
+
+// WithServiceName 设置服务名
+func WithServiceName(name string) Option {
+	return func(o *option) {
+		o.serviceName = name
+	}
+}
+
+// WithSampleRatio 设置根 span 的采样比例，有父 span 时沿用父 span 的采样结果
+func WithSampleRatio(ratio float64) Option {
+	return func(o *option) {
+		o.sampler = "parentbased_traceidratio"
+		o.sampleRatio = ratio
+	}
+}
+
+// WithExporter 使用自定义导出器代替 OTLP 导出器，如测试中使用 tracetest.NewInMemoryExporter
+func WithExporter(exporter sdktrace.SpanExporter) Option {
+	return func(o *option) {
+		o.exporter = exporter
+	}
+}
+
+// WithMetricReader 使用自定义指标 Reader 代替定期导出到 OTLP 的 Reader，如测试中使用 sdkmetric.NewManualReader
+func WithMetricReader(reader sdkmetric.Reader) Option {
+	return func(o *option) {
+		o.metricReader = reader
+	}
+}
+
+// WithoutMetrics 只初始化链路追踪，不设置 MeterProvider
+func WithoutMetrics() Option {
+	return func(o *option) {
+		o.disableMetrics = true
+	}
+}
+
+// WithAttributes 追加 resource 属性，如 deployment.environment
+func WithAttributes(attrs ...attribute.KeyValue) Option {
+	return func(o *option) {
+		o.attrs = append(o.attrs, attrs...)
+	}
+}
+
+func newOption(opts ...Option) *option {
+	o := &option{
+		endpoint:       envkey.GetString(EnvEndpoint),
+		insecure:       envkey.GetBoolD(EnvInsecure, false),
+		serviceName:    envkey.GetString(EnvServiceName),
+		sampler:        envkey.GetStringD(EnvSampler, "parentbased_traceidratio"),
+		sampleRatio:    envkey.GetFloatD(EnvSampleRatio, 1),
+		disableTraces:  envkey.GetString(EnvTracesExporter) == "none",
+		disableMetrics: envkey.GetString(EnvMetricsExporter) == "none",
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// Init 设置全局 TextMapPropagator 为 W3C tracecontext 与 baggage，并在配置了接收端、导出器或 Reader 时
+// 设置全局 TracerProvider 与 MeterProvider。返回的 shutdown 导出剩余的 span 与指标并关闭导出器，未导出时为空操作，
+// 应在停止服务之后调用
+func Init(ctx context.Context, opts ...Option) (shutdown func(ctx context.Context) error, err error) {
+	o := newOption(opts...)
+	otel.SetTextMapPropagator(Propagator())
+
+	sampler, err := newSampler(o.sampler, o.sampleRatio)
+	if err != nil {
+		return nil, err
+	}
+	attrs := append([]attribute.KeyValue{}, o.attrs...)
+	if o.serviceName != "" {
+		attrs = append(attrs, attribute.String("service.name", o.serviceName))
+	}
+	res, err := resource.Merge(resource.Default(), resource.NewSchemaless(attrs...))
+	if err != nil {
+		return nil, fmt.Errorf("create resource failed: %w", err)
+	}
+
+	var shutdowns []func(context.Context) error
+	shutdown = func(ctx context.Context) error {
+		var errs []error
+		for i := len(shutdowns) - 1; i >= 0; i-- {
+			errs = append(errs, shutdowns[i](ctx))
+		}
+		return errors.Join(errs...)
+	}
+
+	exporter, err := o.spanExporter(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("create otlp trace exporter failed: %w", err)
+	}
+	if exporter != nil {
+		tp := sdktrace.NewTracerProvider(
+			sdktrace.WithBatcher(exporter),
+			sdktrace.WithResource(res),
+			sdktrace.WithSampler(sampler),
+		)
+		otel.SetTracerProvider(tp)
+		shutdowns = append(shutdowns, tp.Shutdown)
+	}
+
+	reader, err := o.reader(ctx)
+	if err != nil {
+		_ = shutdown(ctx)
+		return nil, fmt.Errorf("create otlp metric exporter failed: %w", err)
+	}
+	if reader != nil {
+		mp := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader), sdkmetric.WithResource(res))
+		otel.SetMeterProvider(mp)
+		shutdowns = append(shutdowns, mp.Shutdown)
+	}
+
+	if o.endpoint == "" && len(shutdowns) == 0 {
+		hlog.CtxInfof(ctx, "[Tracing] %s not set, spans and metrics will not be exported", EnvEndpoint)
+	} else {
+		hlog.CtxInfof(ctx, "[Tracing] exporting to %s, service=%s sampler=%s traces=%v metrics=%v",
+			o.endpoint, o.serviceName, o.sampler, exporter != nil, reader != nil)
+	}
+	return shutdown, nil
+}
+
+// Propagator 返回 W3C tracecontext 与 baggage 组合的传播器
+func Propagator() propagation.TextMapPropagator {
+	return propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{})
+}
+
+func newSampler(name string, ratio float64) (sdktrace.Sampler, error) {
+	switch strings.ToLower(name) {
+	case "always_on":
+		return sdktrace.AlwaysSample(), nil
+	case "always_off":
+		return sdktrace.NeverSample(), nil
+	case "traceidratio":
+		return sdktrace.TraceIDRatioBased(ratio), nil
+	case "parentbased_always_on":
+		return sdktrace.ParentBased(sdktrace.AlwaysSample()), nil
+	case "parentbased_always_off":
+		return sdktrace.ParentBased(sdktrace.NeverSample()), nil
+	case "parentbased_traceidratio", "":
+		return sdktrace.ParentBased(sdktrace.TraceIDRatioBased(ratio)), nil
+	}
+	return nil, fmt.Errorf("unsupported %s: %q", EnvSampler, name)
+}
+
+// spanExporter 返回 span 导出器，未配置接收端与导出器时返回 nil
+func (o *option) spanExporter(ctx context.Context) (sdktrace.SpanExporter, error) {
+	if o.exporter != nil {
+		return o.exporter, nil
+	}
+	if o.disableTraces || o.endpoint == "" {
+		return nil, nil
+	}
+	var clientOpts []otlptracegrpc.Option
+	if strings.Contains(o.endpoint, "://") {
+		clientOpts = append(clientOpts, otlptracegrpc.WithEndpointURL(o.endpoint))
+	} else {
+		clientOpts = append(clientOpts, otlptracegrpc.WithEndpoint(o.endpoint))
+	}
+	if o.insecure {
+		clientOpts = append(clientOpts, otlptracegrpc.WithInsecure())
+	}
+	return otlptracegrpc.New(ctx, clientOpts...)
+}
+
+// reader 返回指标 Reader，未配置接收端与 Reader 时返回 nil
+func (o *option) reader(ctx context.Context) (sdkmetric.Reader, error) {
+	if o.metricReader != nil {
+		return o.metricReader, nil
+	}
+	if o.disableMetrics || o.endpoint == "" {
+		return nil, nil
+	}
+	var clientOpts []otlpmetricgrpc.Option
+	if strings.Contains(o.endpoint, "://") {
+		clientOpts = append(clientOpts, otlpmetricgrpc.WithEndpointURL(o.endpoint))
+	} else {
+		clientOpts = append(clientOpts, otlpmetricgrpc.WithEndpoint(o.endpoint))
+	}
+	if o.insecure {
+		clientOpts = append(clientOpts, otlpmetricgrpc.WithInsecure())
+	}
+	exporter, err := otlpmetricgrpc.New(ctx, clientOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return sdkmetric.NewPeriodicReader(exporter), nil
+}
@@ -0,0 +1,81 @@
+package trace
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	otelcodes "go.opentelemetry.io/otel/codes"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// keepExporter 关闭时保留已导出的 span，InMemoryExporter.Shutdown 会清空 span
+type keepExporter struct {
+	*tracetest.InMemoryExporter
+}
+
+func (keepExporter) Shutdown(context.Context) error { return nil }
+
+func TestInit(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	reader := sdkmetric.NewManualReader()
+	ctx := context.Background()
+	shutdown, err := Init(ctx, WithServiceName("test"), WithExporter(keepExporter{exporter}), WithMetricReader(reader))
+	if err != nil {
+		t.Fatalf("Init: %v", err)
+	}
+
+	ctx, parent := Start(ctx, "parent")
+	if TraceID(ctx) == "" {
+		t.Fatal("TraceID is empty")
+	}
+	_, child := Start(ctx, "child")
+	End(child, errors.New("boom"))
+	End(parent, nil)
+
+	counter, _ := otel.Meter("test").Int64Counter("requests")
+	counter.Add(ctx, 2)
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(ctx, &rm); err != nil || len(rm.ScopeMetrics) != 1 {
+		t.Fatalf("Collect = %+v, %v", rm.ScopeMetrics, err)
+	}
+
+	if err := shutdown(ctx); err != nil {
+		t.Fatalf("shutdown: %v", err)
+	}
+	spans := exporter.GetSpans()
+	if len(spans) != 2 {
+		t.Fatalf("got %d spans, want 2", len(spans))
+	}
+	if spans[0].Name != "child" || spans[0].Status.Code != otelcodes.Error || spans[0].Parent.SpanID() != spans[1].SpanContext.SpanID() {
+		t.Errorf("child span = %+v", spans[0])
+	}
+	if got := spans[1].Resource.Set(); !got.HasValue("service.name") {
+		t.Errorf("resource = %v, want service.name", got)
+	}
+}
+
+func TestInitWithoutEndpoint(t *testing.T) {
+	t.Setenv(EnvEndpoint, "")
+	shutdown, err := Init(context.Background())
+	if err != nil {
+		t.Fatalf("Init: %v", err)
+	}
+	if err := shutdown(context.Background()); err != nil {
+		t.Fatalf("shutdown: %v", err)
+	}
+}
+
+func TestNewSampler(t *testing.T) {
+	for _, name := range []string{"always_on", "always_off", "traceidratio", "parentbased_always_on", "parentbased_always_off", "parentbased_traceidratio"} {
+		if _, err := newSampler(name, 0.5); err != nil {
+			t.Errorf("newSampler(%q): %v", name, err)
+		}
+	}
+	if _, err := newSampler("jaeger_remote", 1); err == nil {
+		t.Error("unsupported sampler should fail")
+	}
+}